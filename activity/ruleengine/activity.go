@@ -23,6 +23,7 @@ type Input struct {
 	ParserOverride string   `md:"parserOverride"`
 	DisabledRules  []string `md:"disabledRules"`
 	Tags           []string `md:"tags"`
+	OutputFormats  []string `md:"outputFormats"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
@@ -33,6 +34,7 @@ func (i *Input) ToMap() map[string]interface{} {
 		"parserOverride": i.ParserOverride,
 		"disabledRules":  i.DisabledRules,
 		"tags":           i.Tags,
+		"outputFormats":  i.OutputFormats,
 	}
 }
 
@@ -72,6 +74,17 @@ func (i *Input) FromMap(values map[string]interface{}) error {
 			}
 		}
 	}
+	if v := values["outputFormats"]; v != nil {
+		if arr, ok := v.([]interface{}); ok {
+			for _, item := range arr {
+				s, err := coerce.ToString(item)
+				if err != nil {
+					return err
+				}
+				i.OutputFormats = append(i.OutputFormats, s)
+			}
+		}
+	}
 	return nil
 }
 
@@ -84,6 +97,8 @@ type Output struct {
 	WarningCount int                    `md:"warningCount"`
 	InfoCount    int                    `md:"infoCount"`
 	Markdown     string                 `md:"markdown"`
+	Sarif        string                 `md:"sarif"`
+	Junit        string                 `md:"junit"`
 	Overview     map[string]interface{} `md:"overview"`
 	Success      bool                   `md:"success"`
 	Error        string                 `md:"error"`
@@ -97,6 +112,8 @@ func (o *Output) ToMap() map[string]interface{} {
 		"warningCount": o.WarningCount,
 		"infoCount":    o.InfoCount,
 		"markdown":     o.Markdown,
+		"sarif":        o.Sarif,
+		"junit":        o.Junit,
 		"overview":     o.Overview,
 		"success":      o.Success,
 		"error":        o.Error,
@@ -117,6 +134,12 @@ func (o *Output) FromMap(values map[string]interface{}) error {
 	if o.Markdown, err = coerce.ToString(values["markdown"]); err != nil {
 		return err
 	}
+	if o.Sarif, err = coerce.ToString(values["sarif"]); err != nil {
+		return err
+	}
+	if o.Junit, err = coerce.ToString(values["junit"]); err != nil {
+		return err
+	}
 	if o.Success, err = coerce.ToBool(values["success"]); err != nil {
 		return err
 	}
@@ -179,6 +202,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		ParserOverride: in.ParserOverride,
 		DisabledRules:  in.DisabledRules,
 		Tags:           in.Tags,
+		OutputFormats:  in.OutputFormats,
 	})
 
 	// Finish span after evaluation — record result attributes and any error.
@@ -202,6 +226,14 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		return true, ctx.SetOutputObject(out)
 	}
 
+	// Load warnings are only present on the first evaluation after the rules
+	// directory is (re)loaded, so logging them here reports each once.
+	if warnings, ok := result.Overview["warnings"].([]string); ok {
+		for _, w := range warnings {
+			a.logger.Warnf("RuleEngine: rule load warning: %s", w)
+		}
+	}
+
 	out.Success = true
 	out.Findings = result.FindingsAsInterface()
 	out.Positives = result.PositivesAsInterface()
@@ -209,6 +241,8 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	out.WarningCount = result.WarningCount
 	out.InfoCount = result.InfoCount
 	out.Markdown = result.Markdown
+	out.Sarif = result.SARIF
+	out.Junit = result.JUnit
 	out.Overview = result.Overview
 
 	return true, ctx.SetOutputObject(out)
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/model"
+)
+
+// RulesCheckInterval is how often a cached rule set is checked for changes on
+// disk. Within the interval Evaluate reuses the cached rules without touching
+// the filesystem; after it, the rules directory is re-scanned (a stat walk —
+// no file is read) and the set is reloaded only if a .yaml file was added,
+// removed, resized or modified. Set to 0 to check on every evaluation.
+var RulesCheckInterval = 2 * time.Second
+
+// ruleSet is one loaded and precompiled rules directory.
+type ruleSet struct {
+	mu          sync.Mutex
+	rules       []*model.RuleDef
+	warnings    []string
+	reported    bool   // warnings already returned to a caller
+	fingerprint uint64 // of the .yaml files the rules were loaded from
+	checkedAt   time.Time
+	loaded      bool
+}
+
+var (
+	ruleSetsMu sync.Mutex
+	ruleSets   = make(map[string]*ruleSet)
+)
+
+// cachedRules returns the rules for rulesPath, loading them on first use and
+// reloading them when the files change. Load warnings are returned only by
+// the first call after each (re)load, so a broken rule file is reported once
+// rather than on every evaluation.
+func cachedRules(rulesPath string) ([]*model.RuleDef, []string) {
+	key := rulesPath
+	if abs, err := filepath.Abs(rulesPath); err == nil {
+		key = abs
+	}
+
+	ruleSetsMu.Lock()
+	rs, ok := ruleSets[key]
+	if !ok {
+		rs = &ruleSet{}
+		ruleSets[key] = rs
+	}
+	ruleSetsMu.Unlock()
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	if !rs.loaded || now.Sub(rs.checkedAt) >= RulesCheckInterval {
+		fp := fingerprintRules(rulesPath)
+		if !rs.loaded || fp != rs.fingerprint {
+			rs.rules, rs.warnings = loadRules(rulesPath)
+			rs.fingerprint = fp
+			rs.reported = false
+			rs.loaded = true
+		}
+		rs.checkedAt = now
+	}
+
+	var warnings []string
+	if !rs.reported {
+		warnings = rs.warnings
+		rs.reported = true
+	}
+	return rs.rules, warnings
+}
+
+// InvalidateRules drops the cached rule set for rulesPath so the next
+// evaluation reloads it regardless of RulesCheckInterval. An empty path drops
+// every cached rule set.
+func InvalidateRules(rulesPath string) {
+	ruleSetsMu.Lock()
+	defer ruleSetsMu.Unlock()
+	if rulesPath == "" {
+		ruleSets = make(map[string]*ruleSet)
+		return
+	}
+	if abs, err := filepath.Abs(rulesPath); err == nil {
+		rulesPath = abs
+	}
+	delete(ruleSets, rulesPath)
+}
+
+// fingerprintRules hashes the path, size and modification time of every .yaml
+// file under rulesPath, plus any walk errors, so adding, removing or editing a
+// rule file changes the result.
+func fingerprintRules(rulesPath string) uint64 {
+	h := fnv.New64a()
+	_ = filepath.WalkDir(rulesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Fprintf(h, "err|%s|%v\n", path, err)
+			return nil
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".yaml") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			fmt.Fprintf(h, "err|%s|%v\n", path, err)
+			return nil
+		}
+		fmt.Fprintf(h, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return h.Sum64()
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/model"
+)
+
+// withCheckInterval sets RulesCheckInterval for the duration of a test.
+func withCheckInterval(t *testing.T, d time.Duration) {
+	t.Helper()
+	prev := RulesCheckInterval
+	RulesCheckInterval = d
+	t.Cleanup(func() { RulesCheckInterval = prev })
+}
+
+// touch bumps a file's modification time so the change is visible even on
+// filesystems with coarse timestamps.
+func touch(t *testing.T, path string, offset time.Duration) {
+	t.Helper()
+	ts := time.Now().Add(offset)
+	if err := os.Chtimes(path, ts, ts); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+const ruleBadRegex = `
+rule:
+  id: TEST-RE
+  severity: WARNING
+  title: Bad Regex
+  match:
+    type: regex
+    path: image
+    pattern: "[unclosed"
+`
+
+func TestCachedRules_ReusesLoadedRules(t *testing.T) {
+	withCheckInterval(t, 0)
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "a.yaml", ruleValid)
+
+	first, _ := cachedRules(dir)
+	second, _ := cachedRules(dir)
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("expected 1 rule, got %d and %d", len(first), len(second))
+	}
+	if first[0] != second[0] {
+		t.Error("expected the unchanged rule set to be served from cache")
+	}
+}
+
+func TestCachedRules_WarningsReportedOnce(t *testing.T) {
+	withCheckInterval(t, 0)
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "bad.yaml", ruleInvalidYAML)
+	writeRuleFile(t, dir, "good.yaml", ruleValid)
+
+	if _, warnings := cachedRules(dir); len(warnings) == 0 {
+		t.Fatal("expected load warnings on first evaluation")
+	}
+	if _, warnings := cachedRules(dir); len(warnings) != 0 {
+		t.Fatalf("expected no warnings on second evaluation, got %v", warnings)
+	}
+
+	// A reload reports the warnings again.
+	touch(t, filepath.Join(dir, "bad.yaml"), time.Minute)
+	if _, warnings := cachedRules(dir); len(warnings) == 0 {
+		t.Fatal("expected load warnings again after reload")
+	}
+}
+
+func TestCachedRules_ReloadsOnChange(t *testing.T) {
+	withCheckInterval(t, 0)
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "a.yaml", ruleValid)
+	if rules, _ := cachedRules(dir); len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+
+	// Added file.
+	writeRuleFile(t, dir, "b.yaml", ruleValidWarning)
+	if rules, _ := cachedRules(dir); len(rules) != 2 {
+		t.Fatalf("expected 2 rules after adding a file, got %d", len(rules))
+	}
+
+	// Modified file.
+	writeRuleFile(t, dir, "b.yaml", strings.Replace(ruleValidWarning, "TEST-002", "TEST-009", 1))
+	touch(t, filepath.Join(dir, "b.yaml"), time.Minute)
+	rules, _ := cachedRules(dir)
+	if len(rules) != 2 || rules[1].ID != "TEST-009" {
+		t.Fatalf("expected edited rule TEST-009 to be loaded, got %v", ruleIDs(rules))
+	}
+
+	// Removed file.
+	if err := os.Remove(filepath.Join(dir, "b.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if rules, _ := cachedRules(dir); len(rules) != 1 {
+		t.Fatalf("expected 1 rule after removing a file, got %d", len(rules))
+	}
+}
+
+func TestCachedRules_CheckIntervalThrottlesRescan(t *testing.T) {
+	withCheckInterval(t, time.Hour)
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "a.yaml", ruleValid)
+	cachedRules(dir)
+
+	writeRuleFile(t, dir, "b.yaml", ruleValidWarning)
+	if rules, _ := cachedRules(dir); len(rules) != 1 {
+		t.Fatalf("expected cached rule set within the check interval, got %d rules", len(rules))
+	}
+
+	InvalidateRules(dir)
+	if rules, _ := cachedRules(dir); len(rules) != 2 {
+		t.Fatalf("expected reload after InvalidateRules, got %d rules", len(rules))
+	}
+}
+
+func TestLoadRules_PrecompileErrorsWarnedRuleKept(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "re.yaml", ruleBadRegex)
+
+	rules, warnings := loadRules(dir)
+	if len(rules) != 1 {
+		t.Fatalf("expected the rule to stay loaded, got %d rules", len(rules))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "TEST-RE") || !strings.Contains(warnings[0], "invalid regex") {
+		t.Fatalf("expected one invalid regex warning, got %v", warnings)
+	}
+}
+
+func ruleIDs(rules []*model.RuleDef) []string {
+	ids := make([]string, len(rules))
+	for i, r := range rules {
+		ids[i] = r.ID
+	}
+	return ids
+}
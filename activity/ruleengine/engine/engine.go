@@ -22,20 +22,26 @@ type Request struct {
 	ParserOverride string   // "json"|"xml"|"yaml"|"kv"|"lines" — overrides extension detection
 	DisabledRules  []string // rule IDs to skip
 	Tags           []string // only evaluate rules with at least one of these tags
+	OutputFormats  []string // "markdown"|"sarif"|"junit" — reports to render; empty = markdown
 }
 
 // Evaluate runs all applicable rules against the provided document content.
 // This is the single public entry point — both the Flogo activity and the
 // future HTTP microservice call this function.
 func Evaluate(req Request) (*model.Result, error) {
+	formats, err := resolveFormats(req.OutputFormats)
+	if err != nil {
+		return nil, err
+	}
+
 	// 1. Resolve parser
 	doc, parserName, err := resolveParser(parser.DefaultRegistry(), req)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Load + filter rules
-	allRules, warnings := loadRules(req.RulesPath)
+	// 2. Load (cached per rules path, reloaded on change) + filter rules
+	allRules, warnings := cachedRules(req.RulesPath)
 	ext := strings.ToLower(filepath.Ext(req.FileName))
 	rules := filterRules(allRules, ext, req.DisabledRules, req.Tags)
 	rules = filterByParser(rules, parserName)
@@ -59,7 +65,20 @@ func Evaluate(req Request) (*model.Result, error) {
 			result.InfoCount++
 		}
 	}
-	result.Markdown = buildMarkdown(result, req.FileName)
+	// 5. Render the requested reports
+	if formats[FormatMarkdown] {
+		result.Markdown = buildMarkdown(result, req.FileName)
+	}
+	if formats[FormatSARIF] {
+		if result.SARIF, err = buildSARIF(result, rules, req.FileName); err != nil {
+			return nil, fmt.Errorf("render SARIF: %w", err)
+		}
+	}
+	if formats[FormatJUnit] {
+		if result.JUnit, err = buildJUnit(result, rules, req.FileName); err != nil {
+			return nil, fmt.Errorf("render JUnit: %w", err)
+		}
+	}
 
 	return result, nil
 }
@@ -0,0 +1,59 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/model"
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/parser"
+)
+
+// Precompile compiles everything in a rule that would otherwise be compiled on
+// first evaluation — the scope expression, regex patterns in the when/match
+// conditions, and the location/description/recommendation templates — into
+// the shared caches. It returns one error per construct that does not compile.
+// A rule with errors can still be evaluated: the broken part surfaces as a
+// diagnostic finding exactly as it would without precompilation.
+func Precompile(rule *model.RuleDef) []error {
+	var errs []error
+	if err := parser.CompileScope(rule.Scope); err != nil {
+		errs = append(errs, fmt.Errorf("scope: %w", err))
+	}
+	if rule.When != nil {
+		errs = append(errs, precompileCondition(*rule.When, "when")...)
+	}
+	errs = append(errs, precompileCondition(rule.Match, "match")...)
+
+	templates := []struct{ field, tmpl string }{
+		{"location", rule.Location},
+		{"description", rule.Description},
+		{"recommendation", rule.Recommendation},
+	}
+	for _, t := range templates {
+		if !strings.Contains(t.tmpl, "{{") {
+			continue
+		}
+		if _, err := compileTemplate(t.tmpl); err != nil {
+			errs = append(errs, fmt.Errorf("%s template: %w", t.field, err))
+		}
+	}
+	return errs
+}
+
+func precompileCondition(cond model.Condition, where string) []error {
+	var errs []error
+	switch cond.Type {
+	case "regex", "regex_match":
+		if _, err := compileRegex(flaggedPattern(cond), cond.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+		}
+	case "regex_not_match":
+		if _, err := compileRegex(cond.Pattern, cond.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+		}
+	}
+	for i, sub := range cond.Conditions {
+		errs = append(errs, precompileCondition(sub, fmt.Sprintf("%s.conditions[%d]", where, i))...)
+	}
+	return errs
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/model"
+)
+
+func TestPrecompile_ValidRule_NoErrors(t *testing.T) {
+	when := model.Condition{Type: "regex", Path: "ref", Pattern: "^#rest", Flags: []string{"i"}}
+	r := &model.RuleDef{
+		ID:       "OK-001",
+		Scope:    "$.resources[*].data",
+		When:     &when,
+		Match:    model.Condition{Type: "any_of", Conditions: []model.Condition{{Type: "regex_not_match", Path: "x", Pattern: `^\d+$`}}},
+		Location: "flow:{{.Scope.name}}",
+	}
+	if errs := Precompile(r); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if _, ok := compiledRegexps.Load("(?i)^#rest"); !ok {
+		t.Error("expected the flagged when-pattern to be cached")
+	}
+	if _, ok := compiledTemplates.Load("flow:{{.Scope.name}}"); !ok {
+		t.Error("expected the location template to be cached")
+	}
+}
+
+func TestPrecompile_ReportsEachBrokenConstruct(t *testing.T) {
+	r := &model.RuleDef{
+		ID:    "BAD-010",
+		Scope: "//process[",
+		Match: model.Condition{Type: "all_of", Conditions: []model.Condition{
+			{Type: "exists", Path: "a"},
+			{Type: "regex", Path: "b", Pattern: "[unclosed"},
+		}},
+		Recommendation: "Fix {{.Scope.name",
+	}
+	errs := Precompile(r)
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors (scope, regex, template), got %d: %v", len(errs), errs)
+	}
+	want := []string{"scope: invalid XPath", "match.conditions[1]: invalid regex", "recommendation template"}
+	for i, w := range want {
+		if !strings.HasPrefix(errs[i].Error(), w) {
+			t.Errorf("error %d = %q, want prefix %q", i, errs[i], w)
+		}
+	}
+}
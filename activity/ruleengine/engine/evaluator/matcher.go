@@ -174,23 +174,33 @@ func evalRegex(cond model.Condition, doc parser.Document, scope interface{}) (Ma
 		s = stringify(scope)
 	}
 
-	pattern := cond.Pattern
-	if len(cond.Flags) > 0 {
-		pattern = "(?" + strings.Join(cond.Flags, "") + ")" + pattern
+	re, err := compileRegex(flaggedPattern(cond), cond.Pattern)
+	if err != nil {
+		return MatchResult{}, err
 	}
+	return MatchResult{Matched: re.MatchString(s), Value: s}, nil
+}
 
-	var re *regexp.Regexp
+// flaggedPattern prefixes the pattern with its inline flags, e.g. (?i).
+func flaggedPattern(cond model.Condition) string {
+	if len(cond.Flags) == 0 {
+		return cond.Pattern
+	}
+	return "(?" + strings.Join(cond.Flags, "") + ")" + cond.Pattern
+}
+
+// compileRegex returns the cached compiled form of pattern, compiling it on
+// first use. display is the pattern as written in the rule, for error messages.
+func compileRegex(pattern, display string) (*regexp.Regexp, error) {
 	if cached, ok := compiledRegexps.Load(pattern); ok {
-		re = cached.(*regexp.Regexp)
-	} else {
-		var err error
-		re, err = regexp.Compile(pattern)
-		if err != nil {
-			return MatchResult{}, fmt.Errorf("invalid regex %q: %w", cond.Pattern, err)
-		}
-		compiledRegexps.Store(pattern, re)
+		return cached.(*regexp.Regexp), nil
 	}
-	return MatchResult{Matched: re.MatchString(s), Value: s}, nil
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", display, err)
+	}
+	compiledRegexps.Store(pattern, re)
+	return re, nil
 }
 
 // ── Numeric match types ───────────────────────────────────────────────────────
@@ -389,16 +399,9 @@ func evalRegexNotMatch(cond model.Condition, doc parser.Document, scope interfac
 		return MatchResult{Matched: false}, nil
 	}
 
-	var re *regexp.Regexp
-	if cached, ok := compiledRegexps.Load(cond.Pattern); ok {
-		re = cached.(*regexp.Regexp)
-	} else {
-		var err error
-		re, err = regexp.Compile(cond.Pattern)
-		if err != nil {
-			return MatchResult{}, fmt.Errorf("invalid regex %q: %w", cond.Pattern, err)
-		}
-		compiledRegexps.Store(cond.Pattern, re)
+	re, err := compileRegex(cond.Pattern, cond.Pattern)
+	if err != nil {
+		return MatchResult{}, err
 	}
 	return MatchResult{Matched: !re.MatchString(s), Value: s}, nil
 }
@@ -46,16 +46,9 @@ func Interpolate(tmpl string, ctx TemplateContext) string {
 		"Match": fmt.Sprintf("%v", ctx.Match),
 	}
 
-	var t *template.Template
-	if cached, ok := compiledTemplates.Load(tmpl); ok {
-		t = cached.(*template.Template)
-	} else {
-		var err error
-		t, err = template.New("").Option("missingkey=zero").Parse(tmpl)
-		if err != nil {
-			return tmpl // return raw template on parse error
-		}
-		compiledTemplates.Store(tmpl, t)
+	t, err := compileTemplate(tmpl)
+	if err != nil {
+		return tmpl // return raw template on parse error
 	}
 
 	var buf bytes.Buffer
@@ -66,6 +59,19 @@ func Interpolate(tmpl string, ctx TemplateContext) string {
 	return strings.ReplaceAll(buf.String(), "<no value>", "")
 }
 
+// compileTemplate returns the cached parsed form of tmpl, parsing it on first use.
+func compileTemplate(tmpl string) (*template.Template, error) {
+	if cached, ok := compiledTemplates.Load(tmpl); ok {
+		return cached.(*template.Template), nil
+	}
+	t, err := template.New("").Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	compiledTemplates.Store(tmpl, t)
+	return t, nil
+}
+
 // toMap converts an interface{} to map[string]interface{} for template access.
 // Passes through existing maps; wraps primitives under "value".
 func toMap(v interface{}) interface{} {
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/evaluator"
 	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/model"
 	"gopkg.in/yaml.v3"
 )
@@ -57,6 +58,9 @@ func loadRules(rulesPath string) ([]*model.RuleDef, []string) {
 			continue
 		}
 		seen[rule.ID] = path
+		for _, err := range evaluator.Precompile(rule) {
+			warnings = append(warnings, fmt.Sprintf("rule %s in %s: %v", rule.ID, path, err))
+		}
 		rules = append(rules, rule)
 	}
 
@@ -22,6 +22,8 @@ type Result struct {
 	WarningCount int                    `json:"warning_count"`
 	InfoCount    int                    `json:"info_count"`
 	Markdown     string                 `json:"markdown"`
+	SARIF        string                 `json:"sarif,omitempty"` // SARIF 2.1.0 log, when requested
+	JUnit        string                 `json:"junit,omitempty"` // JUnit XML report, when requested
 	Overview     map[string]interface{} `json:"overview"`
 }
 
@@ -100,3 +100,21 @@ func DefaultRegistry() *Registry {
 	})
 	return defaultRegistry
 }
+
+// CompileScope parses a rule scope ahead of evaluation so syntax errors are
+// caught at load time and the first evaluation finds the expression already
+// cached. The language is inferred from the syntax: "$…" is JSONPath and
+// "/…" is XPath; dot-paths need no compilation.
+func CompileScope(scope string) error {
+	switch {
+	case strings.HasPrefix(scope, "$"):
+		if _, err := parsePath(scope); err != nil {
+			return fmt.Errorf("invalid JSONPath %q: %w", scope, err)
+		}
+	case strings.HasPrefix(scope, "/"):
+		if _, err := parseXPath(scope); err != nil {
+			return fmt.Errorf("invalid XPath %q: %w", scope, err)
+		}
+	}
+	return nil
+}
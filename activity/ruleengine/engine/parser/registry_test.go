@@ -157,3 +157,23 @@ func TestRegistry_NewRegistry_IsEmpty(t *testing.T) {
 		t.Fatal("new registry should detect nothing")
 	}
 }
+
+func TestCompileScope(t *testing.T) {
+	tests := []struct {
+		scope   string
+		wantErr bool
+	}{
+		{"", false},
+		{"spec.containers", false},
+		{"$.resources[*].data", false},
+		{"$.resources[", true},
+		{"//process", false},
+		{"//process[", true},
+	}
+	for _, tc := range tests {
+		err := CompileScope(tc.scope)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("CompileScope(%q) error = %v, wantErr %v", tc.scope, err, tc.wantErr)
+		}
+	}
+}
@@ -3,10 +3,27 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
 )
 
+// compiledXPaths caches compiled XPath expressions keyed by expression string.
+var compiledXPaths sync.Map
+
+func parseXPath(path string) (*xpath.Expr, error) {
+	if cached, ok := compiledXPaths.Load(path); ok {
+		return cached.(*xpath.Expr), nil
+	}
+	x, err := xpath.Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	compiledXPaths.Store(path, x)
+	return x, nil
+}
+
 // XMLParser parses XML/BWP documents and resolves paths using XPath via antchfx/xmlquery
 // (the same library used in the xmlfilter activity).
 type XMLParser struct{}
@@ -30,10 +47,11 @@ func (d *xmlDocument) ResolveScope(path string) ([]interface{}, error) {
 	if path == "" {
 		return []interface{}{d.root}, nil
 	}
-	nodes, err := xmlquery.QueryAll(d.root, path)
+	x, err := parseXPath(path)
 	if err != nil {
 		return nil, fmt.Errorf("XPath error %q: %w", path, err)
 	}
+	nodes := xmlquery.QuerySelectorAll(d.root, x)
 	result := make([]interface{}, len(nodes))
 	for i, n := range nodes {
 		result[i] = n
@@ -51,8 +69,11 @@ func (d *xmlDocument) ResolvePath(obj interface{}, path string) (interface{}, bo
 	if path == "" {
 		return node.InnerText(), true
 	}
-	found, err := xmlquery.Query(node, path)
-	if err != nil || found == nil {
+	var found *xmlquery.Node
+	if x, err := parseXPath(path); err == nil {
+		found = xmlquery.QuerySelector(node, x)
+	}
+	if found == nil {
 		// Try attribute lookup (e.g. "@enabled")
 		if strings.HasPrefix(path, "@") {
 			attrName := strings.TrimPrefix(path, "@")
@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine/model"
+)
+
+// Output formats accepted in Request.OutputFormats.
+const (
+	FormatMarkdown = "markdown"
+	FormatSARIF    = "sarif"
+	FormatJUnit    = "junit"
+)
+
+// Tool identity reported in SARIF output.
+const (
+	toolName           = "flogo-rule-engine"
+	toolVersion        = "1.0.0"
+	toolInformationURI = "https://github.com/mpandav-tibco/flogo-custom-extensions/tree/main/activity/ruleengine"
+	sarifSchemaURI     = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// resolveFormats validates the requested output formats. An empty list means
+// markdown only, the behaviour before formats were selectable.
+func resolveFormats(formats []string) (map[string]bool, error) {
+	if len(formats) == 0 {
+		return map[string]bool{FormatMarkdown: true}, nil
+	}
+	out := make(map[string]bool, len(formats))
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch f {
+		case FormatMarkdown, FormatSARIF, FormatJUnit:
+			out[f] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown output format %q (accepted: markdown, sarif, junit)", f)
+		}
+	}
+	return out, nil
+}
+
+// ── SARIF 2.1.0 ───────────────────────────────────────────────────────────────
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     sarifText              `json:"shortDescription"`
+	FullDescription      *sarifText             `json:"fullDescription,omitempty"`
+	Help                 *sarifText             `json:"help,omitempty"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           *int                   `json:"ruleIndex,omitempty"`
+	Level               string                 `json:"level"`
+	Message             sarifText              `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifLevel maps rule severities to SARIF result levels.
+func sarifLevel(severity string) string {
+	switch severity {
+	case model.SeverityError:
+		return "error"
+	case model.SeverityWarning:
+		return "warning"
+	case model.SeverityInfo:
+		return "note"
+	}
+	return "none"
+}
+
+// lineLocation matches locations such as "line:42" produced by rules over the
+// lines parser; these become a SARIF region instead of a logical location.
+var lineLocation = regexp.MustCompile(`^(?i)line\s*[:#]?\s*(\d+)$`)
+
+// buildSARIF renders the findings as a SARIF 2.1.0 log. rules are the rules
+// that ran; GOOD rules are omitted since they never produce findings.
+func buildSARIF(result *model.Result, rules []*model.RuleDef, fileName string) (string, error) {
+	sorted := make([]*model.RuleDef, 0, len(rules))
+	for _, r := range rules {
+		if r.Severity != model.SeverityGood {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	driver := sarifDriver{
+		Name:           toolName,
+		Version:        toolVersion,
+		InformationURI: toolInformationURI,
+		Rules:          make([]sarifRule, 0, len(sorted)),
+	}
+	index := make(map[string]int, len(sorted))
+	for i, r := range sorted {
+		index[r.ID] = i
+		driver.Rules = append(driver.Rules, sarifRuleFor(r))
+	}
+
+	uri := filepath.ToSlash(fileName)
+	results := make([]sarifResult, 0, len(result.Findings))
+	for _, f := range result.Findings {
+		msg := f.Message
+		if msg == "" {
+			msg = f.Title
+		}
+		res := sarifResult{
+			RuleID:    f.RuleID,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifText{Text: msg},
+			Locations: []sarifLocation{sarifLocationFor(uri, f.Location)},
+			PartialFingerprints: map[string]string{
+				"ruleLocation/v1": fingerprint(f.RuleID, f.Location, f.Title),
+			},
+		}
+		if i, ok := index[f.RuleID]; ok {
+			res.RuleIndex = &i
+		}
+		props := map[string]interface{}{}
+		if f.Category != "" {
+			props["category"] = f.Category
+		}
+		if f.Recommendation != "" {
+			props["recommendation"] = f.Recommendation
+		}
+		if len(f.RootCauses) > 0 {
+			props["rootCauses"] = f.RootCauses
+		}
+		if len(f.Fixes) > 0 {
+			props["fixes"] = f.Fixes
+		}
+		if len(props) > 0 {
+			res.Properties = props
+		}
+		results = append(results, res)
+	}
+
+	out, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func sarifRuleFor(r *model.RuleDef) sarifRule {
+	sr := sarifRule{
+		ID:                   r.ID,
+		ShortDescription:     sarifText{Text: r.Title},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+	}
+	// Descriptions and recommendations are per-finding templates; only
+	// template-free text is meaningful as static rule metadata.
+	if r.Description != "" && !strings.Contains(r.Description, "{{") {
+		sr.FullDescription = &sarifText{Text: r.Description}
+	}
+	if r.Recommendation != "" && !strings.Contains(r.Recommendation, "{{") {
+		sr.Help = &sarifText{Text: r.Recommendation}
+	}
+	props := map[string]interface{}{}
+	if len(r.Tags) > 0 {
+		props["tags"] = r.Tags
+	}
+	if r.Category != "" {
+		props["category"] = r.Category
+	}
+	if len(props) > 0 {
+		sr.Properties = props
+	}
+	return sr
+}
+
+// sarifLocationFor maps a finding location onto the analysed file: "line:N"
+// becomes a region, anything else (e.g. "flow:main") a logical location
+// named after the part following the first colon.
+func sarifLocationFor(uri, location string) sarifLocation {
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}}}
+	if location == "" {
+		return loc
+	}
+	if m := lineLocation.FindStringSubmatch(strings.TrimSpace(location)); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: n}
+			return loc
+		}
+	}
+	ll := sarifLogicalLocation{FullyQualifiedName: location}
+	if i := strings.Index(location, ":"); i >= 0 && i < len(location)-1 {
+		ll.Name = location[i+1:]
+	}
+	loc.LogicalLocations = []sarifLogicalLocation{ll}
+	return loc
+}
+
+// fingerprint gives dashboards a stable identity for a finding across runs
+// even when it has no line number.
+func fingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ── JUnit XML ─────────────────────────────────────────────────────────────────
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// buildJUnit renders one test case per rule that ran (GOOD rules excluded).
+// A rule with ERROR or WARNING findings fails, with every finding listed in
+// the failure body; INFO findings are reported as system-out on a passing
+// case so they show up in CI reports without breaking the build.
+func buildJUnit(result *model.Result, rules []*model.RuleDef, fileName string) (string, error) {
+	byRule := make(map[string][]model.Finding)
+	for _, f := range result.Findings {
+		byRule[f.RuleID] = append(byRule[f.RuleID], f)
+	}
+
+	sorted := make([]*model.RuleDef, 0, len(rules))
+	for _, r := range rules {
+		if r.Severity != model.SeverityGood {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	base := filepath.Base(fileName)
+	suite := junitTestSuite{Name: base}
+	for _, r := range sorted {
+		className := r.Category
+		if className == "" {
+			className = base
+		}
+		tc := junitTestCase{ClassName: className, Name: r.ID + ": " + r.Title}
+
+		var failing, info []string
+		worst := ""
+		for _, f := range byRule[r.ID] {
+			line := f.Title
+			if f.Message != "" {
+				line = f.Message
+			}
+			if f.Location != "" {
+				line = f.Location + " — " + line
+			}
+			switch f.Severity {
+			case model.SeverityError, model.SeverityWarning:
+				failing = append(failing, "["+f.Severity+"] "+line)
+				if worst != model.SeverityError {
+					worst = f.Severity
+				}
+			default:
+				info = append(info, "["+f.Severity+"] "+line)
+			}
+		}
+		if len(failing) > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d finding(s) for rule %s", len(failing), r.ID),
+				Type:    worst,
+				Body:    strings.Join(failing, "\n"),
+			}
+			suite.Failures++
+		}
+		if len(info) > 0 {
+			tc.SystemOut = strings.Join(info, "\n")
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	out, err := xml.MarshalIndent(junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
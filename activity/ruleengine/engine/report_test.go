@@ -0,0 +1,213 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-custom-extensions/activity/ruleengine/engine"
+)
+
+func TestEvaluate_OutputFormats_DefaultMarkdownOnly(t *testing.T) {
+	rulesDir := mkRulesDir(t)
+	addRule(t, rulesDir, "", "flogo-001.yaml", missingErrorHandlerRule)
+
+	result, err := engine.Evaluate(engine.Request{
+		Content:   flogoAppWithIssues,
+		FileName:  "app.flogo",
+		RulesPath: rulesDir,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Markdown == "" {
+		t.Error("expected markdown by default")
+	}
+	if result.SARIF != "" || result.JUnit != "" {
+		t.Error("expected no SARIF/JUnit unless requested")
+	}
+}
+
+func TestEvaluate_OutputFormats_Unknown_Error(t *testing.T) {
+	_, err := engine.Evaluate(engine.Request{
+		Content:       flogoAppWithIssues,
+		FileName:      "app.flogo",
+		RulesPath:     mkRulesDir(t),
+		OutputFormats: []string{"sarif", "html"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "html") {
+		t.Fatalf("expected unknown format error, got %v", err)
+	}
+}
+
+func TestEvaluate_OutputFormats_SARIF(t *testing.T) {
+	rulesDir := mkRulesDir(t)
+	addRule(t, rulesDir, "", "flogo-001.yaml", missingErrorHandlerRule)
+	addRule(t, rulesDir, "", "flogo-002.yaml", httpTimeoutRule)
+	addRule(t, rulesDir, "", "good.yaml", goodRule)
+
+	result, err := engine.Evaluate(engine.Request{
+		Content:       flogoAppWithIssues,
+		FileName:      "apps/app.flogo",
+		RulesPath:     rulesDir,
+		OutputFormats: []string{"SARIF"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Markdown != "" {
+		t.Error("expected no markdown when only sarif is requested")
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID                   string `json:"id"`
+						DefaultConfiguration struct {
+							Level string `json:"level"`
+						} `json:"defaultConfiguration"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex *int   `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+					LogicalLocations []struct {
+						Name               string `json:"name"`
+						FullyQualifiedName string `json:"fullyQualifiedName"`
+					} `json:"logicalLocations"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(result.SARIF), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF envelope: version=%q runs=%d", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	// GOOD rules carry no findings and are left out of the rule metadata.
+	if got := len(run.Tool.Driver.Rules); got != 2 {
+		t.Fatalf("expected 2 rules in driver metadata, got %d", got)
+	}
+	if run.Tool.Driver.Rules[0].ID != "FLOGO-001" || run.Tool.Driver.Rules[0].DefaultConfiguration.Level != "error" {
+		t.Errorf("unexpected first rule: %+v", run.Tool.Driver.Rules[0])
+	}
+	if run.Tool.Driver.Rules[1].DefaultConfiguration.Level != "warning" {
+		t.Errorf("expected WARNING rule to map to level warning, got %q", run.Tool.Driver.Rules[1].DefaultConfiguration.Level)
+	}
+
+	if len(run.Results) != len(result.Findings) {
+		t.Fatalf("expected %d results, got %d", len(result.Findings), len(run.Results))
+	}
+	for _, r := range run.Results {
+		if r.RuleIndex == nil || run.Tool.Driver.Rules[*r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %s has wrong ruleIndex", r.RuleID)
+		}
+		if r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "apps/app.flogo" {
+			t.Errorf("unexpected artifact uri %q", r.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		}
+	}
+	first := run.Results[0]
+	if first.RuleID != "FLOGO-001" || first.Level != "error" || first.Message.Text == "" {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+	if ll := first.Locations[0].LogicalLocations; len(ll) != 1 || !strings.HasPrefix(ll[0].FullyQualifiedName, "flow:") || ll[0].Name == "" {
+		t.Errorf("expected flow:<name> logical location, got %+v", ll)
+	}
+}
+
+func TestEvaluate_OutputFormats_SARIF_LineRegion(t *testing.T) {
+	rulesDir := mkRulesDir(t)
+	addRule(t, rulesDir, "", "log-001.yaml", logErrorRule)
+
+	result, err := engine.Evaluate(engine.Request{
+		Content:       logWithErrors,
+		FileName:      "app.log",
+		RulesPath:     rulesDir,
+		OutputFormats: []string{"sarif"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.SARIF, `"startLine"`) {
+		t.Errorf("expected line:N locations to map to a SARIF region:\n%s", result.SARIF)
+	}
+}
+
+type junitReport struct {
+	Tests    int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Suites   []struct {
+		Name      string `xml:"name,attr"`
+		TestCases []struct {
+			Name    string    `xml:"name,attr"`
+			Failure *struct{} `xml:"failure"`
+		} `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+func TestEvaluate_OutputFormats_JUnit(t *testing.T) {
+	rulesDir := mkRulesDir(t)
+	addRule(t, rulesDir, "", "flogo-001.yaml", missingErrorHandlerRule)
+	addRule(t, rulesDir, "", "kv-001.yaml", kvSslDisabledRule) // .conf only — not run
+	addRule(t, rulesDir, "", "good.yaml", goodRule)
+
+	result, err := engine.Evaluate(engine.Request{
+		Content:       flogoAppClean,
+		FileName:      "app.flogo",
+		RulesPath:     rulesDir,
+		OutputFormats: []string{"markdown", "junit"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Markdown == "" {
+		t.Error("expected markdown when requested alongside junit")
+	}
+
+	var suites junitReport
+	if err := xml.Unmarshal([]byte(result.JUnit), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	if suites.Tests != 1 || suites.Failures != 0 {
+		t.Fatalf("clean app: expected 1 passing test, got tests=%d failures=%d", suites.Tests, suites.Failures)
+	}
+	if suites.Suites[0].Name != "app.flogo" || !strings.HasPrefix(suites.Suites[0].TestCases[0].Name, "FLOGO-001: ") {
+		t.Errorf("unexpected suite: %+v", suites.Suites[0])
+	}
+
+	result, err = engine.Evaluate(engine.Request{
+		Content:       flogoAppWithIssues,
+		FileName:      "app.flogo",
+		RulesPath:     rulesDir,
+		OutputFormats: []string{"junit"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	suites = junitReport{}
+	if err := xml.Unmarshal([]byte(result.JUnit), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	if suites.Failures != 1 || suites.Suites[0].TestCases[0].Failure == nil {
+		t.Fatalf("app with issues: expected FLOGO-001 to fail, got failures=%d", suites.Failures)
+	}
+}
@@ -4,13 +4,13 @@ go 1.23.0
 
 require (
 	github.com/antchfx/xmlquery v1.4.4
+	github.com/antchfx/xpath v1.3.4
 	github.com/ohler55/ojg v1.28.1
 	github.com/project-flogo/core v1.6.13
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/antchfx/xpath v1.3.4 // indirect
 	github.com/araddon/dateparse v0.0.0-20210429162001-6b43995a97de // indirect
 	github.com/golang/groupcache v0.0.0-20241129210726-2c02b8208cf8 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
@@ -48,6 +48,7 @@ func main() {
 			ParserOverride string   `json:"parserOverride"`
 			DisabledRules  []string `json:"disabledRules"`
 			Tags           []string `json:"tags"`
+			OutputFormats  []string `json:"outputFormats"` // markdown (default), sarif, junit
 		}
 
 		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
@@ -68,6 +69,7 @@ func main() {
 			ParserOverride: req.ParserOverride,
 			DisabledRules:  req.DisabledRules,
 			Tags:           req.Tags,
+			OutputFormats:  req.OutputFormats,
 		})
 
 		w.Header().Set("Content-Type", "application/json")
@@ -89,6 +91,8 @@ func main() {
 			"warningCount": result.WarningCount,
 			"infoCount":    result.InfoCount,
 			"markdown":     result.Markdown,
+			"sarif":        result.SARIF,
+			"junit":        result.JUnit,
 			"overview":     result.Overview,
 			"error":        "",
 		})
@@ -180,6 +180,7 @@ type analyzeRequest struct {
 	ParserOverride string   `json:"parserOverride,omitempty"`
 	DisabledRules  []string `json:"disabledRules,omitempty"`
 	Tags           []string `json:"tags,omitempty"`
+	OutputFormats  []string `json:"outputFormats,omitempty"`
 }
 
 func postAnalyze(t *testing.T, req analyzeRequest) (int, map[string]interface{}) {
@@ -511,6 +512,30 @@ func TestIntegration_Result_MarkdownContainsReport(t *testing.T) {
 	}
 }
 
+func TestIntegration_Result_SARIFAndJUnit(t *testing.T) {
+	_, data := postAnalyze(t, analyzeRequest{
+		Content:       flogoWithIssues,
+		FileName:      "app.flogo",
+		OutputFormats: []string{"sarif", "junit"},
+	})
+
+	sarif, _ := data["sarif"].(string)
+	var log map[string]interface{}
+	if err := json.Unmarshal([]byte(sarif), &log); err != nil {
+		t.Fatalf("expected a SARIF JSON document, got %q: %v", sarif, err)
+	}
+	if log["version"] != "2.1.0" {
+		t.Errorf("expected SARIF version 2.1.0, got %v", log["version"])
+	}
+	junit, _ := data["junit"].(string)
+	if !strings.Contains(junit, "<testsuites") || !strings.Contains(junit, "<failure") {
+		t.Errorf("expected a failing JUnit report, got: %s", junit)
+	}
+	if markdown, _ := data["markdown"].(string); markdown != "" {
+		t.Errorf("expected no markdown when not requested, got: %s", markdown)
+	}
+}
+
 func TestIntegration_Result_OverviewContainsFile(t *testing.T) {
 	_, data := postAnalyze(t, analyzeRequest{
 		Content:  flogoWithIssues,
@@ -7,6 +7,8 @@
 //   - WSDLOperation: select the operation; soapAction and body skeleton are
 //     derived automatically
 //   - AutoUseWSDLEndpoint: override the endpoint from the WSDL <service>
+//   - WS-Security: UsernameToken, Timestamp, X.509 request signing and
+//     response signature verification
 package soapclient
 
 import (
@@ -18,6 +20,7 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -146,10 +149,16 @@ func New(ctx activity.InitContext) (activity.Activity, error) {
 		},
 	}
 
+	wss, err := newWSSecurity(s)
+	if err != nil {
+		return nil, err
+	}
+
 	act := &Activity{
 		settings:   s,
 		httpClient: httpClient,
 		endpoint:   s.SoapServiceEndpoint,
+		wss:        wss,
 	}
 
 	// Load the auth connection separately: metadata.MapToStruct cannot safely
@@ -232,6 +241,7 @@ type Activity struct {
 	wsdlOp       *wsdl.Operation    // nil when no operation selected
 	wsdlTargetNS string             // WSDL targetNamespace — used to wrap body elements correctly
 	authConn     connection.Manager // nil when authentication is not configured
+	wss          *wsSecurity        // nil when WS-Security is disabled
 }
 
 func (a *Activity) Metadata() *activity.Metadata { return activityMd }
@@ -539,6 +549,9 @@ func (a *Activity) buildEnvelope(ctx activity.Context, input *Input) ([]byte, er
 	buf.WriteString("</soap:Body>")
 	buf.WriteString(soapEnvEnd)
 
+	if a.wss != nil {
+		return a.wss.secure(buf.Bytes(), time.Now())
+	}
 	return buf.Bytes(), nil
 }
 
@@ -558,14 +571,31 @@ type soapPart struct {
 }
 
 func (a *Activity) parseResponse(ctx activity.Context, raw []byte, status int, out *Output) error {
+	verify := a.wss != nil && a.wss.verifyCerts != nil
 	var env soapEnvelope
 	if err := xml.Unmarshal(raw, &env); err != nil {
+		if verify {
+			return fmt.Errorf("SOAP Client: response signature verification failed: HTTP %d — response is not a SOAP envelope (%s)", status, err)
+		}
 		// If we can't parse as XML, just return the raw body as a string.
 		ctx.Logger().Warnf("SOAP Client: HTTP %d — response is not a SOAP envelope (%s)", status, err)
 		out.SOAPResponsePayload = string(raw)
 		return nil
 	}
 
+	// Many services do not sign SOAP Faults, so an unsigned fault is passed
+	// through with a warning; a fault that does carry a signature must verify.
+	if verify {
+		if err := a.wss.verify(raw, time.Now()); err != nil {
+			if !errors.Is(err, errNoSecurityHeader) || !isFaultBody(env.Body.Inner) {
+				return fmt.Errorf("SOAP Client: response signature verification failed: %w", err)
+			}
+			ctx.Logger().Warnf("SOAP Client: unsigned SOAP Fault accepted without signature verification")
+		} else {
+			ctx.Logger().Debugf("SOAP Client: response signature verified")
+		}
+	}
+
 	// Warn when the response envelope namespace doesn't match the configured SOAP version.
 	// Not fatal — many services respond with SOAP 1.1 regardless — but aids debugging.
 	wantNS := "http://schemas.xmlsoap.org/soap/envelope/"
@@ -586,14 +616,7 @@ func (a *Activity) parseResponse(ctx activity.Context, raw []byte, status int, o
 		return nil
 	}
 
-	// Detect fault by unmarshalling to a typed probe — avoids false positives from
-	// element names or text content that merely contain the word "fault".
-	// Go's xml package matches on local name regardless of namespace prefix,
-	// so this handles both SOAP 1.1 <soap:Fault> and SOAP 1.2 <env:Fault>.
-	var faultProbe struct {
-		XMLName xml.Name `xml:"Fault"`
-	}
-	if xml.Unmarshal(bodyInner, &faultProbe) == nil && faultProbe.XMLName.Local == "Fault" {
+	if isFaultBody(bodyInner) {
 		out.IsFault = true
 		out.SOAPResponseFault = a.fromXMLBytes(ctx, bodyInner)
 		return nil
@@ -603,6 +626,18 @@ func (a *Activity) parseResponse(ctx activity.Context, raw []byte, status int, o
 	return nil
 }
 
+// isFaultBody reports whether the Body contents are a SOAP Fault. It
+// unmarshals into a typed probe — avoids false positives from element names
+// or text content that merely contain the word "fault". Go's xml package
+// matches on local name regardless of namespace prefix, so this handles both
+// SOAP 1.1 <soap:Fault> and SOAP 1.2 <env:Fault>.
+func isFaultBody(bodyInner []byte) bool {
+	var faultProbe struct {
+		XMLName xml.Name `xml:"Fault"`
+	}
+	return xml.Unmarshal(bodyInner, &faultProbe) == nil && faultProbe.XMLName.Local == "Fault"
+}
+
 // ---------------------------------------------------------------------------
 // Conversion helpers
 // ---------------------------------------------------------------------------
@@ -617,6 +617,29 @@ var SoapClientActivityHandler = function (base) {
                 return wi_contrib_1.ValidationResult.newValidationResult().setVisible(!!tlsOn);
             }
 
+            // WS-Security fields: visible only when wsSecurity is true; the
+            // signing and verification certificates follow their own toggles.
+            if (fieldName === "wsUsername" ||
+                fieldName === "wsPassword" ||
+                fieldName === "wsPasswordType" ||
+                fieldName === "wsTimestamp" ||
+                fieldName === "wsSignRequest" ||
+                fieldName === "wsVerifyResponse") {
+                return wi_contrib_1.ValidationResult.newValidationResult().setVisible(self._isOn(ctx, "wsSecurity"));
+            }
+            if (fieldName === "wsTimestampTTL") {
+                return wi_contrib_1.ValidationResult.newValidationResult()
+                    .setVisible(self._isOn(ctx, "wsSecurity") && self._isOn(ctx, "wsTimestamp"));
+            }
+            if (fieldName === "wsSigningCertificate" || fieldName === "wsSigningKey") {
+                return wi_contrib_1.ValidationResult.newValidationResult()
+                    .setVisible(self._isOn(ctx, "wsSecurity") && self._isOn(ctx, "wsSignRequest"));
+            }
+            if (fieldName === "wsVerifyCertificate") {
+                return wi_contrib_1.ValidationResult.newValidationResult()
+                    .setVisible(self._isOn(ctx, "wsSecurity") && self._isOn(ctx, "wsVerifyResponse"));
+            }
+
             // WSDL-dependent fields: visible once a WSDL source is configured
             if (fieldName === "wsdlOperation" || fieldName === "autoUseWsdlEndpoint") {
                 return wi_contrib_1.ValidationResult.newValidationResult().setVisible(self._hasWsdl(ctx));
@@ -646,6 +669,12 @@ var SoapClientActivityHandler = function (base) {
         return (f && f.value !== undefined) ? f.value : null;
     };
 
+    // ── True when a boolean field is switched on ──────────────────────────────
+    SoapClientHandler.prototype._isOn = function (ctx, name) {
+        var v = this._get(ctx, name);
+        return v === true || v === "true";
+    };
+
     // ── True when any WSDL source has meaningful content ────────────────────────
     SoapClientHandler.prototype._hasWsdl = function (ctx) {
         var src = this._get(ctx, "wsdlSourceType");
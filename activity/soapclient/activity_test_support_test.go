@@ -29,9 +29,10 @@ type activityConfig struct {
 	wsdlHttpUrl         string // sets wsdlSourceType="WSDL URL" automatically
 	wsdlOp              string
 	autoUseWsdlEndpoint bool
-	skipTlsVerify       bool               // set true when hitting httptest TLS servers
-	authorization       bool               // set true to enable HTTP auth header injection
-	authConn            connection.Manager // mock connection; passed directly to New() via settings
+	skipTlsVerify       bool                   // set true when hitting httptest TLS servers
+	authorization       bool                   // set true to enable HTTP auth header injection
+	authConn            connection.Manager     // mock connection; passed directly to New() via settings
+	extraSettings       map[string]interface{} // merged over the settings above (e.g. WS-Security)
 }
 
 // evalInput holds the runtime inputs for a single Eval call in tests.
@@ -77,6 +78,9 @@ func newTestActivity(cfg activityConfig) (activity.Activity, error) {
 		// When nil, New() skips auth loading via its nil guard.
 		"authorizationConn": cfg.authConn,
 	}
+	for k, v := range cfg.extraSettings {
+		settings[k] = v
+	}
 
 	ctx := &testInitContext{settings: settings}
 	return soapclient.New(ctx)
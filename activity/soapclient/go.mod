@@ -3,8 +3,10 @@ module github.com/mpandav-tibco/flogo-custom-extensions/activity/soapclient
 go 1.24.3
 
 require (
+	github.com/beevik/etree v1.1.0
 	github.com/clbanning/mxj/v2 v2.7.0
 	github.com/project-flogo/core v1.6.13
+	github.com/russellhaering/goxmldsig v1.4.0
 	github.com/stretchr/testify v1.10.0
 )
 
@@ -12,6 +14,7 @@ require (
 	github.com/araddon/dateparse v0.0.0-20190622164848-0fb0a474d195 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/jonboulle/clockwork v0.2.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	go.uber.org/atomic v1.6.0 // indirect
 	go.uber.org/multierr v1.5.0 // indirect
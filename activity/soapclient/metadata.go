@@ -69,6 +69,37 @@ type Settings struct {
 	// Authorization enables HTTP authentication via the selected HTTP auth connector.
 	Authorization bool `md:"authorization"`
 
+	// ── WS-Security ───────────────────────────────────────────────────────
+	// WSSecurity enables the wsse:Security SOAP header configured below.
+	WSSecurity bool `md:"wsSecurity"`
+
+	// WSUsername / WSPassword add a UsernameToken when WSUsername is set.
+	// WSPasswordType is "PasswordText" (default) or "PasswordDigest"; the
+	// digest form sends Base64(SHA-1(nonce + created + password)) with a fresh
+	// Nonce and Created on every request.
+	WSUsername     string `md:"wsUsername"`
+	WSPassword     string `md:"wsPassword"`
+	WSPasswordType string `md:"wsPasswordType"`
+
+	// WSTimestamp adds a wsu:Timestamp valid for WSTimestampTTL seconds
+	// (default 300 when zero).
+	WSTimestamp    bool `md:"wsTimestamp"`
+	WSTimestampTTL int  `md:"wsTimestampTTL"`
+
+	// WSSignRequest signs the Body (and the Timestamp when enabled) with the
+	// RSA key pair below using XML-DSig, exclusive C14N and rsa-sha256. The
+	// certificate is sent as a BinarySecurityToken. Accepts the same formats
+	// as the TLS certificate settings.
+	WSSignRequest        bool   `md:"wsSignRequest"`
+	WSSigningCertificate string `md:"wsSigningCertificate"`
+	WSSigningKey         string `md:"wsSigningKey"`
+
+	// WSVerifyResponse requires responses to carry an XML-DSig signature over
+	// the Body that verifies against one of the PEM certificates in
+	// WSVerifyCertificate.
+	WSVerifyResponse    bool   `md:"wsVerifyResponse"`
+	WSVerifyCertificate string `md:"wsVerifyCertificate"`
+
 	// Note: AuthorizationConn (connection.Manager) is NOT in this struct.
 	// metadata.MapToStruct cannot safely coerce a nil settings value to
 	// connection.Manager — it falls into coerce.ToConnection's default case
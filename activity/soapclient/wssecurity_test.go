@@ -0,0 +1,344 @@
+package soapclient_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// WS-Security helpers
+// ---------------------------------------------------------------------------
+
+// testKeyPair generates a self-signed RSA certificate and returns the PEM
+// encoded certificate and PKCS#8 private key.
+func testKeyPair(t *testing.T, cn string) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+}
+
+// echoServer returns every request envelope as the response, passing it
+// through rewrite first. A signed request therefore comes back as a signed
+// response, which lets one activity exercise both signing and verification.
+func echoServer(t *testing.T, captured *[]byte, rewrite func(string) string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if captured != nil {
+			*captured = body
+		}
+		out := string(body)
+		if rewrite != nil {
+			out = rewrite(out)
+		}
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		_, _ = w.Write([]byte(out))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func parseEnvelope(t *testing.T, raw []byte) *etree.Element {
+	t.Helper()
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromBytes(raw))
+	return doc.Root()
+}
+
+const paymentBody = `<Transfer xmlns="http://bank.example.com/"><Account>DE001</Account><Amount>100</Amount></Transfer>`
+
+// ---------------------------------------------------------------------------
+// UsernameToken / Timestamp
+// ---------------------------------------------------------------------------
+
+func TestWSSecurity_UsernameTokenDigestAndTimestamp(t *testing.T) {
+	var captured []byte
+	srv := echoServer(t, &captured, nil)
+
+	act, err := newTestActivity(activityConfig{
+		endpoint: srv.URL,
+		xmlMode:  true,
+		extraSettings: map[string]interface{}{
+			"wsSecurity":     true,
+			"wsUsername":     "svc-user",
+			"wsPassword":     "s3cret",
+			"wsPasswordType": "PasswordDigest",
+			"wsTimestamp":    true,
+			"wsTimestampTTL": 120,
+		},
+	})
+	require.NoError(t, err)
+	_, err = evalActivity(act, evalInput{requestBody: paymentBody})
+	require.NoError(t, err)
+
+	env := parseEnvelope(t, captured)
+	sec := env.FindElement("./soap:Header/wsse:Security")
+	require.NotNil(t, sec, "Security header missing:\n%s", captured)
+	assert.Equal(t, "1", sec.SelectAttrValue("soap:mustUnderstand", ""))
+	require.Equal(t, "Timestamp", sec.ChildElements()[0].Tag, "Timestamp must be the first header element")
+
+	created, err := time.Parse(time.RFC3339, sec.FindElement("./wsu:Timestamp/wsu:Created").Text())
+	require.NoError(t, err)
+	expires, err := time.Parse(time.RFC3339, sec.FindElement("./wsu:Timestamp/wsu:Expires").Text())
+	require.NoError(t, err)
+	assert.Equal(t, 120*time.Second, expires.Sub(created))
+
+	tok := sec.FindElement("./wsse:UsernameToken")
+	require.NotNil(t, tok)
+	assert.Equal(t, "svc-user", tok.FindElement("./wsse:Username").Text())
+	pwd := tok.FindElement("./wsse:Password")
+	assert.True(t, strings.HasSuffix(pwd.SelectAttrValue("Type", ""), "#PasswordDigest"))
+	assert.NotContains(t, string(captured), "s3cret", "digest mode must not send the password")
+
+	nonce, err := base64.StdEncoding.DecodeString(tok.FindElement("./wsse:Nonce").Text())
+	require.NoError(t, err)
+	h := sha1.New()
+	h.Write(nonce)
+	h.Write([]byte(tok.FindElement("./wsu:Created").Text()))
+	h.Write([]byte("s3cret"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(h.Sum(nil)), pwd.Text())
+}
+
+func TestWSSecurity_ExtendsExistingSecurityHeader(t *testing.T) {
+	var captured []byte
+	srv := echoServer(t, &captured, nil)
+
+	act, err := newTestActivity(activityConfig{
+		endpoint: srv.URL,
+		xmlMode:  true,
+		extraSettings: map[string]interface{}{
+			"wsSecurity": true,
+			"wsUsername": "svc-user",
+			"wsPassword": "s3cret",
+		},
+	})
+	require.NoError(t, err)
+	_, err = evalActivity(act, evalInput{
+		requestBody: paymentBody,
+		headers:     `<sec:Security xmlns:sec="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"><sec:Custom>x</sec:Custom></sec:Security><Trace xmlns="urn:t">1</Trace>`,
+	})
+	require.NoError(t, err)
+
+	env := parseEnvelope(t, captured)
+	header := env.SelectElement("soap:Header")
+	require.Len(t, header.SelectElements("sec:Security"), 1, "existing Security header must be reused")
+	assert.Empty(t, header.SelectElements("wsse:Security"))
+	assert.NotNil(t, header.FindElement("./sec:Security/sec:Custom"))
+	assert.Equal(t, "s3cret", header.FindElement("./sec:Security/wsse:UsernameToken/wsse:Password").Text())
+	assert.NotNil(t, header.SelectElement("Trace"), "other headers are kept")
+}
+
+// ---------------------------------------------------------------------------
+// Signing and verification
+// ---------------------------------------------------------------------------
+
+func signingSettings(certPEM, keyPEM, verifyPEM string) map[string]interface{} {
+	return map[string]interface{}{
+		"wsSecurity":           true,
+		"wsTimestamp":          true,
+		"wsSignRequest":        true,
+		"wsSigningCertificate": certPEM,
+		"wsSigningKey":         keyPEM,
+		"wsVerifyResponse":     verifyPEM != "",
+		"wsVerifyCertificate":  verifyPEM,
+	}
+}
+
+func TestWSSecurity_SignedRequestVerifiesAsResponse(t *testing.T) {
+	certPEM, keyPEM := testKeyPair(t, "client")
+	var captured []byte
+	srv := echoServer(t, &captured, nil)
+
+	for _, version := range []string{"1.1", "1.2"} {
+		t.Run("SOAP "+version, func(t *testing.T) {
+			act, err := newTestActivity(activityConfig{
+				endpoint:      srv.URL,
+				soapVersion:   version,
+				xmlMode:       true,
+				extraSettings: signingSettings(certPEM, keyPEM, certPEM),
+			})
+			require.NoError(t, err)
+			out, err := evalActivity(act, evalInput{requestBody: paymentBody})
+			require.NoError(t, err)
+			assert.False(t, out.IsFault)
+			assert.Contains(t, out.SOAPResponsePayload, "<Amount>100</Amount>")
+
+			env := parseEnvelope(t, captured)
+			body := env.SelectElement("soap:Body")
+			bodyID := body.SelectAttrValue("wsu:Id", "")
+			require.NotEmpty(t, bodyID)
+			sec := env.FindElement("./soap:Header/wsse:Security")
+			tsID := sec.FindElement("./wsu:Timestamp").SelectAttrValue("wsu:Id", "")
+
+			var uris []string
+			for _, ref := range sec.FindElements("./ds:Signature/ds:SignedInfo/ds:Reference") {
+				uris = append(uris, ref.SelectAttrValue("URI", ""))
+			}
+			assert.ElementsMatch(t, []string{"#" + tsID, "#" + bodyID}, uris)
+
+			bst := sec.FindElement("./wsse:BinarySecurityToken")
+			require.NotNil(t, bst)
+			assert.Equal(t, "#"+bst.SelectAttrValue("wsu:Id", ""),
+				sec.FindElement("./ds:Signature/ds:KeyInfo/wsse:SecurityTokenReference/wsse:Reference").SelectAttrValue("URI", ""))
+			der, err := base64.StdEncoding.DecodeString(bst.Text())
+			require.NoError(t, err)
+			blk, _ := pem.Decode([]byte(certPEM))
+			assert.Equal(t, blk.Bytes, der)
+		})
+	}
+}
+
+func TestWSSecurity_VerifyRejectsInvalidResponses(t *testing.T) {
+	certPEM, keyPEM := testKeyPair(t, "client")
+	otherPEM, _ := testKeyPair(t, "other")
+
+	tests := []struct {
+		name    string
+		verify  string
+		rewrite func(string) string
+		wantErr string
+	}{
+		{
+			name:    "tampered body",
+			verify:  certPEM,
+			rewrite: func(s string) string { return strings.Replace(s, "<Amount>100</Amount>", "<Amount>999</Amount>", 1) },
+			wantErr: "digest mismatch",
+		},
+		{
+			name:    "untrusted signer",
+			verify:  otherPEM,
+			wantErr: "does not verify against any trusted certificate",
+		},
+		{
+			name: "body not signed",
+			rewrite: func(s string) string {
+				// Drop the Body reference so only the Timestamp is covered.
+				i := strings.LastIndex(s, "<ds:Reference")
+				j := strings.Index(s[i:], "</ds:Reference>") + i + len("</ds:Reference>")
+				return s[:i] + s[j:]
+			},
+			verify:  certPEM,
+			wantErr: "does not cover the SOAP Body",
+		},
+		{
+			name: "duplicate body ID",
+			rewrite: func(s string) string {
+				i := strings.Index(s, "<soap:Body")
+				j := strings.Index(s, "</soap:Body>") + len("</soap:Body>")
+				wrapped := strings.Replace(s[i:j], "soap:Body", "Wrapper", -1)
+				return strings.Replace(s, "</soap:Header>", wrapped+"</soap:Header>", 1)
+			},
+			verify:  certPEM,
+			wantErr: "duplicate element ID",
+		},
+		{
+			name:   "unsigned response",
+			verify: certPEM,
+			rewrite: func(string) string {
+				return `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` + paymentBody + `</soap:Body></soap:Envelope>`
+			},
+			wantErr: "no wsse:Security header",
+		},
+		{
+			name:    "not a SOAP envelope",
+			verify:  certPEM,
+			rewrite: func(string) string { return "gateway error" },
+			wantErr: "not a SOAP envelope",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := echoServer(t, nil, tc.rewrite)
+			act, err := newTestActivity(activityConfig{
+				endpoint:      srv.URL,
+				xmlMode:       true,
+				extraSettings: signingSettings(certPEM, keyPEM, tc.verify),
+			})
+			require.NoError(t, err)
+			_, err = evalActivity(act, evalInput{requestBody: paymentBody})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "signature verification failed")
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestWSSecurity_UnsignedFaultPassesThrough(t *testing.T) {
+	certPEM, _ := testKeyPair(t, "service")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>down</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+	}))
+	defer srv.Close()
+
+	act, err := newTestActivity(activityConfig{
+		endpoint: srv.URL,
+		xmlMode:  true,
+		extraSettings: map[string]interface{}{
+			"wsSecurity":          true,
+			"wsVerifyResponse":    true,
+			"wsVerifyCertificate": certPEM,
+		},
+	})
+	require.NoError(t, err)
+	out, err := evalActivity(act, evalInput{requestBody: paymentBody})
+	require.NoError(t, err)
+	assert.True(t, out.IsFault)
+}
+
+func TestWSSecurity_ConfigErrors(t *testing.T) {
+	certPEM, keyPEM := testKeyPair(t, "client")
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantErr  string
+	}{
+		{"nothing configured", map[string]interface{}{"wsSecurity": true}, "no UsernameToken"},
+		{"bad password type", map[string]interface{}{"wsSecurity": true, "wsUsername": "u", "wsPasswordType": "Plain"}, "unsupported wsPasswordType"},
+		{"password without user", map[string]interface{}{"wsSecurity": true, "wsPassword": "p"}, "wsUsername is empty"},
+		{"sign without key", map[string]interface{}{"wsSecurity": true, "wsSignRequest": true, "wsSigningCertificate": certPEM}, "requires wsSigningCertificate and wsSigningKey"},
+		{"mismatched key pair", map[string]interface{}{"wsSecurity": true, "wsSignRequest": true, "wsSigningCertificate": certPEM, "wsSigningKey": func() string { _, k := testKeyPair(t, "x"); return k }()}, "invalid WS-Security signing key pair"},
+		{"verify without cert", map[string]interface{}{"wsSecurity": true, "wsVerifyResponse": true}, "requires wsVerifyCertificate"},
+		{"verify with key only", map[string]interface{}{"wsSecurity": true, "wsVerifyResponse": true, "wsVerifyCertificate": keyPEM}, "has no PEM certificate"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newTestActivity(activityConfig{endpoint: "http://localhost:1", extraSettings: tc.settings})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+
+	// Disabled WS-Security ignores the other settings.
+	_, err := newTestActivity(activityConfig{endpoint: "http://localhost:1", extraSettings: map[string]interface{}{"wsSignRequest": true}})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,217 @@
+package serde
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+)
+
+// avroDecoder decodes Confluent-framed Avro records. Parsed schemas are cached
+// by ID; each ID gets its own schema cache so two versions of the same named
+// record never collide.
+type avroDecoder struct {
+	registry *registryClient
+
+	mu      sync.RWMutex
+	schemas map[int]avro.Schema
+}
+
+func (d *avroDecoder) Decode(ctx context.Context, value []byte) (map[string]interface{}, error) {
+	id, body, err := splitWireFormat(value)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := d.schema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := avro.Unmarshal(schema, body, &decoded); err != nil {
+		return nil, fmt.Errorf("schema %d: invalid Avro body: %w", id, err)
+	}
+	normalized := normalizeAvro(schema, decoded)
+	if payload, ok := normalized.(map[string]interface{}); ok {
+		return payload, nil
+	}
+	// Non-record top-level schemas (e.g. a plain string value) are wrapped so
+	// the triggers still receive an object.
+	return map[string]interface{}{StringValueField: normalized}, nil
+}
+
+func (d *avroDecoder) schema(ctx context.Context, id int) (avro.Schema, error) {
+	d.mu.RLock()
+	s, ok := d.schemas[id]
+	d.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	rs, err := d.registry.schemaByID(ctx, id, schemaTypeAvro)
+	if err != nil {
+		return nil, err
+	}
+	cache := &avro.SchemaCache{}
+	if err := d.parseReferences(ctx, rs.References, cache, map[string]bool{}); err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+	s, err = avro.ParseWithCache(rs.Schema, "", cache)
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: invalid Avro schema: %w", id, err)
+	}
+
+	d.mu.Lock()
+	if d.schemas == nil {
+		d.schemas = make(map[int]avro.Schema)
+	}
+	d.schemas[id] = s
+	d.mu.Unlock()
+	return s, nil
+}
+
+// parseReferences registers referenced named types in cache, dependencies
+// first, so the referencing schema can use them by name.
+func (d *avroDecoder) parseReferences(ctx context.Context, refs []schemaReference, cache *avro.SchemaCache, seen map[string]bool) error {
+	for _, ref := range refs {
+		key := fmt.Sprintf("%s/%d", ref.Subject, ref.Version)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		rs, err := d.registry.schemaByReference(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if err := d.parseReferences(ctx, rs.References, cache, seen); err != nil {
+			return err
+		}
+		if _, err := avro.ParseWithCache(rs.Schema, "", cache); err != nil {
+			return fmt.Errorf("reference %q: invalid Avro schema: %w", ref.Name, err)
+		}
+	}
+	return nil
+}
+
+// normalizeAvro converts the generic Avro decoding into the plain JSON-like
+// values the triggers work with: unions are unwrapped to their value (the
+// generic decoder returns {"<type>": value}), timestamps and dates become
+// strings, decimals become float64 and bytes become base64 strings.
+func normalizeAvro(schema avro.Schema, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		schema = ref.Schema()
+	}
+
+	switch s := schema.(type) {
+	case *avro.UnionSchema:
+		wrapped, ok := v.(map[string]interface{})
+		if !ok || len(wrapped) != 1 {
+			return v
+		}
+		for name, inner := range wrapped {
+			for _, member := range s.Types() {
+				if avroTypeName(member) == name {
+					return normalizeAvro(member, inner)
+				}
+			}
+			return inner
+		}
+	case *avro.RecordSchema:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for _, f := range s.Fields() {
+			if fv, present := m[f.Name()]; present {
+				m[f.Name()] = normalizeAvro(f.Type(), fv)
+			}
+		}
+		return m
+	case *avro.ArraySchema:
+		items, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		for i := range items {
+			items[i] = normalizeAvro(s.Items(), items[i])
+		}
+		return items
+	case *avro.MapSchema:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for k := range m {
+			m[k] = normalizeAvro(s.Values(), m[k])
+		}
+		return m
+	}
+	return normalizeAvroScalar(schema, v)
+}
+
+func normalizeAvroScalar(schema avro.Schema, v interface{}) interface{} {
+	var logical avro.LogicalType
+	if lts, ok := schema.(avro.LogicalTypeSchema); ok && lts.Logical() != nil {
+		logical = lts.Logical().Type()
+	}
+
+	switch t := v.(type) {
+	case time.Time:
+		if logical == avro.Date {
+			return t.UTC().Format("2006-01-02")
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		// time-millis / time-micros: time of day since midnight.
+		if logical == avro.TimeMicros {
+			return t.Microseconds()
+		}
+		return t.Milliseconds()
+	case *big.Rat:
+		f, _ := t.Float64()
+		return f
+	case float32:
+		return float64(t)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(t)
+	case avro.LogicalDuration:
+		return map[string]interface{}{
+			"months":       int64(t.Months),
+			"days":         int64(t.Days),
+			"milliseconds": int64(t.Milliseconds),
+		}
+	}
+
+	// fixed without a logical type decodes to a [N]byte array.
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return v
+}
+
+// avroTypeName mirrors the key the generic decoder uses for union members:
+// the full name of named types, otherwise the type plus any logical type.
+func avroTypeName(schema avro.Schema) string {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		schema = ref.Schema()
+	}
+	if named, ok := schema.(avro.NamedSchema); ok {
+		return named.FullName()
+	}
+	name := string(schema.Type())
+	if lts, ok := schema.(avro.LogicalTypeSchema); ok && lts.Logical() != nil {
+		name += "." + string(lts.Logical().Type())
+	}
+	return name
+}
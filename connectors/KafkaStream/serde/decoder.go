@@ -0,0 +1,218 @@
+// Package serde decodes Kafka record values into the map[string]interface{}
+// payload consumed by the Kafka Stream triggers. Besides plain JSON it
+// understands the Confluent Schema Registry wire format (magic byte 0x00, a
+// 4-byte big-endian schema ID, then the serialised body) for Avro, Protobuf
+// and JSON Schema, fetching and caching schemas from the registry by ID.
+package serde
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Value formats accepted by the valueFormat trigger setting.
+const (
+	FormatJSON       = "json"        // plain JSON object (default)
+	FormatAvro       = "avro"        // Confluent wire format + Avro binary
+	FormatProtobuf   = "protobuf"    // Confluent wire format + message indexes + Protobuf binary
+	FormatJSONSchema = "json-schema" // Confluent wire format + JSON object
+	FormatString     = "string"      // raw UTF-8 value, emitted as {"value": "..."}
+)
+
+// StringValueField is the payload field holding the record value when
+// valueFormat is "string".
+const StringValueField = "value"
+
+// magicByte is the first byte of every Confluent-framed record.
+const magicByte = 0x00
+
+// wireHeaderLen is the magic byte plus the 4-byte schema ID.
+const wireHeaderLen = 5
+
+// ErrRegistryUnavailable wraps failures to reach the Schema Registry (network
+// errors, 5xx responses, timeouts, rejected credentials). These are transient:
+// the record itself may be perfectly valid, so callers should not treat it as
+// a poison pill.
+var ErrRegistryUnavailable = errors.New("schema registry unavailable")
+
+// Config configures a Decoder.
+type Config struct {
+	// Format is one of the Format* constants. Empty means FormatJSON.
+	Format string
+	// RegistryURL is the Schema Registry base URL. Required for avro,
+	// protobuf and json-schema.
+	RegistryURL string
+	// Username / Password enable HTTP basic auth against the registry
+	// (Confluent Cloud API key / secret).
+	Username string
+	Password string
+	// Timeout bounds a single registry request. 0 = 10s.
+	Timeout time.Duration
+}
+
+// NormalizedFormat returns the lower-cased format, defaulting to json.
+func (c Config) NormalizedFormat() string {
+	f := strings.ToLower(strings.TrimSpace(c.Format))
+	if f == "" {
+		return FormatJSON
+	}
+	return f
+}
+
+// Validate checks that the format is known and that registry-backed formats
+// have a registry URL.
+func (c Config) Validate() error {
+	switch c.NormalizedFormat() {
+	case FormatJSON, FormatString:
+		return nil
+	case FormatAvro, FormatProtobuf, FormatJSONSchema:
+		if strings.TrimSpace(c.RegistryURL) == "" {
+			return fmt.Errorf("schemaRegistryUrl is required when valueFormat is %q", c.NormalizedFormat())
+		}
+		return nil
+	default:
+		return fmt.Errorf("valueFormat %q is invalid (accepted: json, avro, protobuf, json-schema, string)", c.Format)
+	}
+}
+
+// Decoder turns a raw Kafka record value into a trigger payload.
+// Implementations are safe for concurrent use.
+type Decoder interface {
+	Decode(ctx context.Context, value []byte) (map[string]interface{}, error)
+}
+
+// NewDecoder validates cfg and returns the decoder for its format.
+func NewDecoder(cfg Config) (Decoder, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.NormalizedFormat() {
+	case FormatString:
+		return stringDecoder{}, nil
+	case FormatAvro:
+		return &avroDecoder{registry: newRegistryClient(cfg)}, nil
+	case FormatProtobuf:
+		return &protobufDecoder{registry: newRegistryClient(cfg)}, nil
+	case FormatJSONSchema:
+		return &jsonSchemaDecoder{registry: newRegistryClient(cfg)}, nil
+	default:
+		return jsonDecoder{}, nil
+	}
+}
+
+// IsTransient reports whether err came from an unreachable or unauthorized
+// registry rather than from the record itself.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrRegistryUnavailable)
+}
+
+// Backoff bounds for DecodeBlocking.
+const (
+	blockingRetryInitial = time.Second
+	blockingRetryMax     = 30 * time.Second
+)
+
+// DecodeBlocking decodes value and waits out registry outages: transient
+// errors are retried with capped exponential backoff until ctx is done, so a
+// registry restart pauses consumption instead of dropping valid records.
+// onRetry, when non-nil, is called before each wait. Non-transient errors are
+// returned immediately; if ctx ends first the last transient error is returned.
+func DecodeBlocking(ctx context.Context, d Decoder, value []byte, onRetry func(err error, wait time.Duration)) (map[string]interface{}, error) {
+	wait := blockingRetryInitial
+	for {
+		payload, err := d.Decode(ctx, value)
+		if err == nil || !IsTransient(err) {
+			return payload, err
+		}
+		if onRetry != nil {
+			onRetry(err, wait)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, err
+		}
+		if wait *= 2; wait > blockingRetryMax {
+			wait = blockingRetryMax
+		}
+	}
+}
+
+// splitWireFormat validates the Confluent header and returns the schema ID and
+// the remaining body.
+func splitWireFormat(value []byte) (int, []byte, error) {
+	if len(value) < wireHeaderLen {
+		return 0, nil, fmt.Errorf("record is %d bytes, too short for the Schema Registry wire format", len(value))
+	}
+	if value[0] != magicByte {
+		return 0, nil, fmt.Errorf("unknown magic byte 0x%02x (expected 0x00): record was not produced with a Schema Registry serializer", value[0])
+	}
+	id := int(binary.BigEndian.Uint32(value[1:wireHeaderLen]))
+	return id, value[wireHeaderLen:], nil
+}
+
+// unmarshalObject decodes a JSON object, rejecting other JSON values so every
+// format yields the same payload shape.
+func unmarshalObject(data []byte) (map[string]interface{}, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, errors.New("record value is JSON null, expected an object")
+	}
+	return payload, nil
+}
+
+// ── json ─────────────────────────────────────────────────────────────────────
+
+type jsonDecoder struct{}
+
+func (jsonDecoder) Decode(_ context.Context, value []byte) (map[string]interface{}, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(value, &payload); err != nil {
+		if len(value) > 0 && value[0] == magicByte {
+			return nil, fmt.Errorf("%w (record starts with the Schema Registry magic byte — set valueFormat to avro, protobuf or json-schema)", err)
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// ── string ───────────────────────────────────────────────────────────────────
+
+type stringDecoder struct{}
+
+func (stringDecoder) Decode(_ context.Context, value []byte) (map[string]interface{}, error) {
+	return map[string]interface{}{StringValueField: string(value)}, nil
+}
+
+// ── json-schema ──────────────────────────────────────────────────────────────
+
+// jsonSchemaDecoder strips the Confluent header and decodes the JSON body. The
+// schema is resolved so unknown IDs and mismatched schema types are reported;
+// validation against the schema is the producer serializer's job.
+type jsonSchemaDecoder struct {
+	registry *registryClient
+}
+
+func (d *jsonSchemaDecoder) Decode(ctx context.Context, value []byte) (map[string]interface{}, error) {
+	id, body, err := splitWireFormat(value)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.registry.schemaByID(ctx, id, schemaTypeJSON); err != nil {
+		return nil, err
+	}
+	payload, err := unmarshalObject(bytes.TrimSpace(body))
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: invalid JSON body: %w", id, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,159 @@
+package serde
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoJSON renders decoded messages with the field names used in the .proto
+// file and with unset fields present, so flows see a stable payload shape.
+var protoJSON = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// protobufDecoder decodes Confluent-framed Protobuf records. The registered
+// .proto source (plus any referenced files) is compiled once per schema ID.
+type protobufDecoder struct {
+	registry *registryClient
+
+	mu    sync.RWMutex
+	files map[int]protoreflect.FileDescriptor
+}
+
+func (d *protobufDecoder) Decode(ctx context.Context, value []byte) (map[string]interface{}, error) {
+	id, rest, err := splitWireFormat(value)
+	if err != nil {
+		return nil, err
+	}
+	indexes, body, err := readMessageIndexes(rest)
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+	fd, err := d.file(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	md, err := messageByIndexes(fd, indexes)
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("schema %d: invalid Protobuf body for %s: %w", id, md.FullName(), err)
+	}
+	data, err := protoJSON.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+	return unmarshalObject(data)
+}
+
+func (d *protobufDecoder) file(ctx context.Context, id int) (protoreflect.FileDescriptor, error) {
+	d.mu.RLock()
+	fd, ok := d.files[id]
+	d.mu.RUnlock()
+	if ok {
+		return fd, nil
+	}
+
+	rs, err := d.registry.schemaByID(ctx, id, schemaTypeProtobuf)
+	if err != nil {
+		return nil, err
+	}
+	mainName := fmt.Sprintf("schema-registry-%d.proto", id)
+	sources := map[string]string{mainName: rs.Schema}
+	if err := d.collectReferences(ctx, rs.References, sources); err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}),
+	}
+	compiled, err := compiler.Compile(ctx, mainName)
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: invalid Protobuf schema: %w", id, err)
+	}
+	fd = compiled[0]
+
+	d.mu.Lock()
+	if d.files == nil {
+		d.files = make(map[int]protoreflect.FileDescriptor)
+	}
+	d.files[id] = fd
+	d.mu.Unlock()
+	return fd, nil
+}
+
+// collectReferences adds the source of every (transitively) imported file,
+// keyed by the import path the referencing schema uses.
+func (d *protobufDecoder) collectReferences(ctx context.Context, refs []schemaReference, sources map[string]string) error {
+	for _, ref := range refs {
+		if _, done := sources[ref.Name]; done {
+			continue
+		}
+		rs, err := d.registry.schemaByReference(ctx, ref)
+		if err != nil {
+			return err
+		}
+		sources[ref.Name] = rs.Schema
+		if err := d.collectReferences(ctx, rs.References, sources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readMessageIndexes parses the message-index array that follows the schema
+// ID: a zig-zag varint count followed by that many zig-zag varint indexes,
+// with a single 0 byte as shorthand for [0] (the first top-level message).
+func readMessageIndexes(b []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(b)
+	if n <= 0 {
+		return nil, nil, errors.New("truncated Protobuf message indexes")
+	}
+	b = b[n:]
+	if count == 0 {
+		return []int{0}, b, nil
+	}
+	if count < 0 || count > int64(len(b)) {
+		return nil, nil, fmt.Errorf("invalid Protobuf message index count %d", count)
+	}
+	indexes := make([]int, count)
+	for i := range indexes {
+		idx, n := binary.Varint(b)
+		if n <= 0 || idx < 0 {
+			return nil, nil, errors.New("invalid Protobuf message indexes")
+		}
+		indexes[i] = int(idx)
+		b = b[n:]
+	}
+	return indexes, b, nil
+}
+
+// messageByIndexes walks the message-index path: the first index selects a
+// top-level message of the file, each following one a nested message.
+func messageByIndexes(fd protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	msgs := fd.Messages()
+	var md protoreflect.MessageDescriptor
+	for depth, idx := range indexes {
+		if idx >= msgs.Len() {
+			return nil, fmt.Errorf("message index %v out of range at depth %d", indexes, depth)
+		}
+		md = msgs.Get(idx)
+		msgs = md.Messages()
+	}
+	if md == nil {
+		return nil, errors.New("schema defines no messages")
+	}
+	return md, nil
+}
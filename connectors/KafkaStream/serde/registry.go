@@ -0,0 +1,195 @@
+package serde
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schema types as reported by the registry. An absent schemaType means Avro.
+const (
+	schemaTypeAvro     = "AVRO"
+	schemaTypeProtobuf = "PROTOBUF"
+	schemaTypeJSON     = "JSON"
+)
+
+const (
+	defaultRegistryTimeout = 10 * time.Second
+	registryMaxAttempts    = 3
+	registryRetryBackoff   = 200 * time.Millisecond
+	registryContentType    = "application/vnd.schemaregistry.v1+json"
+)
+
+// registrySchema is the registry's representation of a schema, as returned by
+// GET /schemas/ids/{id} and GET /subjects/{subject}/versions/{version}.
+type registrySchema struct {
+	Schema     string            `json:"schema"`
+	SchemaType string            `json:"schemaType"`
+	References []schemaReference `json:"references"`
+}
+
+// schemaReference points at another registered schema this one depends on
+// (an imported .proto file or a named Avro type).
+type schemaReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+func (s *registrySchema) normalizedType() string {
+	if s.SchemaType == "" {
+		return schemaTypeAvro
+	}
+	return strings.ToUpper(s.SchemaType)
+}
+
+// registryClient fetches schemas from a Confluent-compatible Schema Registry.
+// Schema IDs and subject versions are immutable, so every successful lookup
+// is cached for the life of the client.
+type registryClient struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+
+	mu        sync.RWMutex
+	byID      map[int]*registrySchema
+	byVersion map[string]*registrySchema
+}
+
+func newRegistryClient(cfg Config) *registryClient {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultRegistryTimeout
+	}
+	return &registryClient{
+		baseURL:   strings.TrimRight(strings.TrimSpace(cfg.RegistryURL), "/"),
+		username:  cfg.Username,
+		password:  cfg.Password,
+		http:      &http.Client{Timeout: timeout},
+		byID:      make(map[int]*registrySchema),
+		byVersion: make(map[string]*registrySchema),
+	}
+}
+
+// schemaByID returns the schema registered under id and checks that it is of
+// the expected type, so a topic configured as avro fails clearly when it
+// carries Protobuf records.
+func (c *registryClient) schemaByID(ctx context.Context, id int, wantType string) (*registrySchema, error) {
+	c.mu.RLock()
+	s, ok := c.byID[id]
+	c.mu.RUnlock()
+	if !ok {
+		s = &registrySchema{}
+		if err := c.get(ctx, "/schemas/ids/"+strconv.Itoa(id), s); err != nil {
+			return nil, fmt.Errorf("schema %d: %w", id, err)
+		}
+		c.mu.Lock()
+		c.byID[id] = s
+		c.mu.Unlock()
+	}
+	if got := s.normalizedType(); got != wantType {
+		return nil, fmt.Errorf("schema %d is a %s schema, expected %s", id, got, wantType)
+	}
+	return s, nil
+}
+
+// schemaByReference resolves a reference to the exact subject version it names.
+func (c *registryClient) schemaByReference(ctx context.Context, ref schemaReference) (*registrySchema, error) {
+	key := ref.Subject + "/" + strconv.Itoa(ref.Version)
+	c.mu.RLock()
+	s, ok := c.byVersion[key]
+	c.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+	s = &registrySchema{}
+	path := "/subjects/" + url.PathEscape(ref.Subject) + "/versions/" + strconv.Itoa(ref.Version)
+	if err := c.get(ctx, path, s); err != nil {
+		return nil, fmt.Errorf("reference %q (subject %q version %d): %w", ref.Name, ref.Subject, ref.Version, err)
+	}
+	c.mu.Lock()
+	c.byVersion[key] = s
+	c.mu.Unlock()
+	return s, nil
+}
+
+// registryError is the error body returned by the registry on 4xx/5xx.
+type registryError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// get performs a GET against the registry, retrying network errors and 5xx
+// responses. Those failures are wrapped in ErrRegistryUnavailable, as are
+// 401/403 responses: a rejected or rotated credential says nothing about the
+// record, so it must not turn every record into a poison pill. Other 4xx
+// responses (unknown schema ID or subject) are returned as-is.
+func (c *registryClient) get(ctx context.Context, path string, out interface{}) error {
+	var lastErr error
+	for attempt := 1; attempt <= registryMaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(time.Duration(attempt-1) * registryRetryBackoff):
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %v", ErrRegistryUnavailable, ctx.Err())
+			}
+		}
+		retry, err := c.getOnce(ctx, path, out)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("%w: %v", ErrRegistryUnavailable, lastErr)
+}
+
+func (c *registryClient) getOnce(ctx context.Context, path string, out interface{}) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", registryContentType)
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return true, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(body))
+		var re registryError
+		if json.Unmarshal(body, &re) == nil && re.Message != "" {
+			msg = re.Message
+		}
+		err := fmt.Errorf("registry returned HTTP %d: %s", resp.StatusCode, msg)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			// Retrying within this call cannot fix a credential; report it as
+			// unavailable so callers wait instead of skipping the record.
+			return false, fmt.Errorf("%w: %v", ErrRegistryUnavailable, err)
+		}
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return false, fmt.Errorf("invalid registry response: %w", err)
+	}
+	return false, nil
+}
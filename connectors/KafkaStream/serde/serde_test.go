@@ -0,0 +1,402 @@
+package serde_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/hamba/avro/v2"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// fakeRegistry is an httptest stand-in for the Confluent Schema Registry REST API.
+type fakeRegistry struct {
+	ids      map[int]map[string]interface{}
+	subjects map[string]map[string]interface{} // "subject/version" → schema
+	hits     int64
+	down     atomic.Bool
+	user     string
+	pass     string
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *httptest.Server) {
+	r := &fakeRegistry{
+		ids:      make(map[int]map[string]interface{}),
+		subjects: make(map[string]map[string]interface{}),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&r.hits, 1)
+		if r.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.user != "" {
+			u, p, ok := req.BasicAuth()
+			if !ok || u != r.user || p != r.pass {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error_code":401,"message":"Unauthorized"}`))
+				return
+			}
+		}
+		var body map[string]interface{}
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/schemas/ids/"):
+			id, _ := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/schemas/ids/"))
+			body = r.ids[id]
+		case strings.HasPrefix(req.URL.Path, "/subjects/"):
+			key := strings.Replace(strings.TrimPrefix(req.URL.Path, "/subjects/"), "/versions/", "/", 1)
+			body = r.subjects[key]
+		}
+		if body == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+// frame prepends the Confluent wire-format header.
+func frame(id int, body ...[]byte) []byte {
+	out := []byte{0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(out[1:], uint32(id))
+	for _, b := range body {
+		out = append(out, b...)
+	}
+	return out
+}
+
+func newDecoder(t *testing.T, cfg serde.Config) serde.Decoder {
+	t.Helper()
+	d, err := serde.NewDecoder(cfg)
+	require.NoError(t, err)
+	return d
+}
+
+// ─── Config ──────────────────────────────────────────────────────────────────
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, serde.Config{}.Validate(), "empty format defaults to json")
+	assert.NoError(t, serde.Config{Format: "STRING"}.Validate())
+	assert.NoError(t, serde.Config{Format: "avro", RegistryURL: "http://sr:8081"}.Validate())
+	assert.Error(t, serde.Config{Format: "avro"}.Validate(), "avro needs a registry")
+	assert.Error(t, serde.Config{Format: "protobuf"}.Validate())
+	assert.Error(t, serde.Config{Format: "json-schema"}.Validate())
+	assert.Error(t, serde.Config{Format: "xml"}.Validate())
+}
+
+// ─── json / string ───────────────────────────────────────────────────────────
+
+func TestJSONDecoder(t *testing.T) {
+	d := newDecoder(t, serde.Config{})
+	payload, err := d.Decode(context.Background(), []byte(`{"id":7,"name":"a"}`))
+	require.NoError(t, err)
+	assert.Equal(t, float64(7), payload["id"])
+
+	_, err = d.Decode(context.Background(), frame(1, []byte(`{}`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "magic byte", "framed records get a hint about valueFormat")
+}
+
+func TestStringDecoder(t *testing.T) {
+	d := newDecoder(t, serde.Config{Format: serde.FormatString})
+	payload, err := d.Decode(context.Background(), []byte("plain text"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"value": "plain text"}, payload)
+}
+
+// ─── avro ────────────────────────────────────────────────────────────────────
+
+const orderSchema = `{
+  "type": "record", "name": "Order", "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "customer", "type": "com.example.Customer"},
+    {"name": "note", "type": ["null", "string"], "default": null},
+    {"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 9, "scale": 2}},
+    {"name": "placedAt", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "tags", "type": {"type": "array", "items": "string"}},
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "SHIPPED"]}}
+  ]
+}`
+
+const customerSchema = `{
+  "type": "record", "name": "Customer", "namespace": "com.example",
+  "fields": [{"name": "name", "type": "string"}, {"name": "vip", "type": ["null", "boolean"]}]
+}`
+
+func TestAvroDecoder_WithReferenceAndCache(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	reg.subjects["customer-value/1"] = map[string]interface{}{"schema": customerSchema}
+	reg.ids[42] = map[string]interface{}{
+		"schema":     orderSchema,
+		"references": []map[string]interface{}{{"name": "com.example.Customer", "subject": "customer-value", "version": 1}},
+	}
+
+	cache := &avro.SchemaCache{}
+	_, err := avro.ParseWithCache(customerSchema, "", cache)
+	require.NoError(t, err)
+	schema, err := avro.ParseWithCache(orderSchema, "", cache)
+	require.NoError(t, err)
+
+	placed := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	body, err := avro.Marshal(schema, map[string]interface{}{
+		"id":       int64(1001),
+		"customer": map[string]interface{}{"name": "Ada", "vip": true},
+		"note":     "leave at door",
+		"amount":   mustRat(t, "12.50"),
+		"placedAt": placed,
+		"tags":     []interface{}{"a", "b"},
+		"status":   "SHIPPED",
+	})
+	require.NoError(t, err)
+
+	d := newDecoder(t, serde.Config{Format: serde.FormatAvro, RegistryURL: srv.URL})
+	payload, err := d.Decode(context.Background(), frame(42, body))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1001), payload["id"])
+	assert.Equal(t, "leave at door", payload["note"], "nullable unions are unwrapped")
+	assert.Equal(t, 12.5, payload["amount"])
+	assert.Equal(t, "2024-03-01T12:30:00Z", payload["placedAt"])
+	assert.Equal(t, []interface{}{"a", "b"}, payload["tags"])
+	assert.Equal(t, "SHIPPED", payload["status"])
+	customer, ok := payload["customer"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "Ada", customer["name"])
+	assert.Equal(t, true, customer["vip"])
+
+	// Second record with the same ID is served from the cache.
+	hits := atomic.LoadInt64(&reg.hits)
+	_, err = d.Decode(context.Background(), frame(42, body))
+	require.NoError(t, err)
+	assert.Equal(t, hits, atomic.LoadInt64(&reg.hits), "schema must be cached by ID")
+}
+
+func TestAvroDecoder_NullUnionAndPrimitiveSchema(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	reg.ids[1] = map[string]interface{}{"schema": `{"type":"record","name":"R","fields":[{"name":"n","type":["null","int"]}]}`}
+	reg.ids[2] = map[string]interface{}{"schema": `"string"`}
+	d := newDecoder(t, serde.Config{Format: serde.FormatAvro, RegistryURL: srv.URL})
+
+	payload, err := d.Decode(context.Background(), frame(1, []byte{0x00}))
+	require.NoError(t, err)
+	assert.Contains(t, payload, "n")
+	assert.Nil(t, payload["n"])
+
+	body, err := avro.Marshal(avro.MustParse(`"string"`), "hello")
+	require.NoError(t, err)
+	payload, err = d.Decode(context.Background(), frame(2, body))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", payload["value"], "non-record schemas are wrapped")
+}
+
+func mustRat(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(s)
+	require.True(t, ok)
+	return r
+}
+
+// ─── protobuf ────────────────────────────────────────────────────────────────
+
+const commonProto = `syntax = "proto3";
+package shop;
+message Money { string currency = 1; int64 units = 2; }
+`
+
+const orderProto = `syntax = "proto3";
+package shop;
+import "shop/common.proto";
+message Ignored { string x = 1; }
+message Order {
+  string order_id = 1;
+  Money total = 2;
+  repeated string items = 3;
+  message Line { string sku = 1; int32 qty = 2; }
+}
+`
+
+func compileProto(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	c := protocompile.Compiler{Resolver: &protocompile.SourceResolver{
+		Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+			"order.proto":       orderProto,
+			"shop/common.proto": commonProto,
+		}),
+	}}
+	files, err := c.Compile(context.Background(), "order.proto")
+	require.NoError(t, err)
+	return files[0]
+}
+
+// zigzag encodes message indexes the way Confluent serializers do.
+func zigzag(vals ...int64) []byte {
+	var out []byte
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, v := range vals {
+		n := binary.PutVarint(buf, v)
+		out = append(out, buf[:n]...)
+	}
+	return out
+}
+
+func TestProtobufDecoder_MessageIndexes(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	reg.subjects["common/3"] = map[string]interface{}{"schema": commonProto, "schemaType": "PROTOBUF"}
+	reg.ids[7] = map[string]interface{}{
+		"schema":     orderProto,
+		"schemaType": "PROTOBUF",
+		"references": []map[string]interface{}{{"name": "shop/common.proto", "subject": "common", "version": 3}},
+	}
+	fd := compileProto(t)
+	d := newDecoder(t, serde.Config{Format: serde.FormatProtobuf, RegistryURL: srv.URL})
+
+	// Order is the second top-level message → indexes [1].
+	orderMD := fd.Messages().ByName("Order")
+	order := dynamicpb.NewMessage(orderMD)
+	order.Set(orderMD.Fields().ByName("order_id"), protoreflect.ValueOfString("o-1"))
+	total := dynamicpb.NewMessage(orderMD.Fields().ByName("total").Message())
+	total.Set(total.Descriptor().Fields().ByName("currency"), protoreflect.ValueOfString("EUR"))
+	total.Set(total.Descriptor().Fields().ByName("units"), protoreflect.ValueOfInt64(12))
+	order.Set(orderMD.Fields().ByName("total"), protoreflect.ValueOfMessage(total))
+	body, err := proto.Marshal(order)
+	require.NoError(t, err)
+
+	payload, err := d.Decode(context.Background(), frame(7, zigzag(1, 1), body))
+	require.NoError(t, err)
+	assert.Equal(t, "o-1", payload["order_id"], "proto field names are kept")
+	assert.Equal(t, []interface{}{}, payload["items"], "unset fields are emitted")
+	money := payload["total"].(map[string]interface{})
+	assert.Equal(t, "EUR", money["currency"])
+	assert.Equal(t, "12", money["units"], "int64 follows the protobuf JSON mapping")
+
+	// Nested message Order.Line → indexes [1, 0].
+	lineMD := orderMD.Messages().ByName("Line")
+	line := dynamicpb.NewMessage(lineMD)
+	line.Set(lineMD.Fields().ByName("sku"), protoreflect.ValueOfString("sku-9"))
+	line.Set(lineMD.Fields().ByName("qty"), protoreflect.ValueOfInt32(3))
+	body, err = proto.Marshal(line)
+	require.NoError(t, err)
+	payload, err = d.Decode(context.Background(), frame(7, zigzag(2, 1, 0), body))
+	require.NoError(t, err)
+	assert.Equal(t, "sku-9", payload["sku"])
+	assert.Equal(t, float64(3), payload["qty"])
+
+	// A single 0 byte is shorthand for the first message.
+	ignoredMD := fd.Messages().ByName("Ignored")
+	ignored := dynamicpb.NewMessage(ignoredMD)
+	ignored.Set(ignoredMD.Fields().ByName("x"), protoreflect.ValueOfString("first"))
+	body, err = proto.Marshal(ignored)
+	require.NoError(t, err)
+	payload, err = d.Decode(context.Background(), frame(7, []byte{0}, body))
+	require.NoError(t, err)
+	assert.Equal(t, "first", payload["x"])
+
+	_, err = d.Decode(context.Background(), frame(7, zigzag(1, 5), body))
+	assert.Error(t, err, "out-of-range message index")
+}
+
+// ─── json-schema ─────────────────────────────────────────────────────────────
+
+func TestJSONSchemaDecoder(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	reg.ids[3] = map[string]interface{}{"schema": `{"type":"object"}`, "schemaType": "JSON"}
+	reg.ids[4] = map[string]interface{}{"schema": `"string"`}
+	d := newDecoder(t, serde.Config{Format: serde.FormatJSONSchema, RegistryURL: srv.URL})
+
+	payload, err := d.Decode(context.Background(), frame(3, []byte(`{"temp":21.5}`)))
+	require.NoError(t, err)
+	assert.Equal(t, 21.5, payload["temp"])
+
+	_, err = d.Decode(context.Background(), frame(4, []byte(`{}`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AVRO schema", "schema type mismatch is reported")
+
+	_, err = d.Decode(context.Background(), []byte(`{"temp":21.5}`))
+	assert.Error(t, err, "unframed records are rejected")
+}
+
+// ─── registry errors ─────────────────────────────────────────────────────────
+
+func TestRegistry_ErrorsAndAuth(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	reg.user, reg.pass = "key", "secret"
+	reg.ids[3] = map[string]interface{}{"schema": `{}`, "schemaType": "JSON"}
+
+	unauth := newDecoder(t, serde.Config{Format: serde.FormatJSONSchema, RegistryURL: srv.URL})
+	_, err := unauth.Decode(context.Background(), frame(3, []byte(`{}`)))
+	require.Error(t, err)
+	assert.True(t, serde.IsTransient(err), "a rejected credential must not make the record a poison pill")
+	assert.Contains(t, err.Error(), "Unauthorized")
+
+	d := newDecoder(t, serde.Config{Format: serde.FormatJSONSchema, RegistryURL: srv.URL + "/", Username: "key", Password: "secret"})
+	_, err = d.Decode(context.Background(), frame(3, []byte(`{}`)))
+	require.NoError(t, err)
+
+	_, err = d.Decode(context.Background(), frame(99, []byte(`{}`)))
+	require.Error(t, err)
+	assert.False(t, serde.IsTransient(err), "unknown schema ID is a poison pill")
+	assert.Contains(t, err.Error(), "Schema not found")
+
+	reg.down.Store(true)
+	_, err = d.Decode(context.Background(), frame(5, []byte(`{}`)))
+	require.Error(t, err)
+	assert.True(t, serde.IsTransient(err), "5xx is transient")
+
+	// Cached schemas keep working while the registry is down.
+	_, err = d.Decode(context.Background(), frame(3, []byte(`{}`)))
+	assert.NoError(t, err)
+
+	_, err = d.Decode(context.Background(), []byte{1, 0, 0, 0, 3, '{', '}'})
+	assert.Error(t, err, "bad magic byte")
+	_, err = d.Decode(context.Background(), []byte{0, 0})
+	assert.Error(t, err, "short record")
+}
+
+func TestDecodeBlocking_WaitsForRegistry(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	reg.ids[3] = map[string]interface{}{"schema": `{}`, "schemaType": "JSON"}
+	reg.down.Store(true)
+	d := newDecoder(t, serde.Config{Format: serde.FormatJSONSchema, RegistryURL: srv.URL})
+
+	retries := 0
+	payload, err := serde.DecodeBlocking(context.Background(), d, frame(3, []byte(`{"ok":true}`)), func(err error, _ time.Duration) {
+		assert.True(t, serde.IsTransient(err))
+		retries++
+		reg.down.Store(false) // registry comes back before the next attempt
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, retries)
+	assert.Equal(t, true, payload["ok"])
+
+	// Poison pills are not retried.
+	_, err = serde.DecodeBlocking(context.Background(), d, []byte("junk"), func(error, time.Duration) {
+		t.Fatal("non-transient errors must not be retried")
+	})
+	assert.Error(t, err)
+
+	// A cancelled context ends the wait with the transient error.
+	reg.down.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = serde.DecodeBlocking(ctx, d, frame(8, []byte(`{}`)), func(error, time.Duration) { cancel() })
+	require.Error(t, err)
+	assert.True(t, serde.IsTransient(err))
+}
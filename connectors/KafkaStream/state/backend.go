@@ -0,0 +1,76 @@
+// Package state provides pluggable backends that persist keyed window state
+// outside the process, partitioned by the Kafka source partition that owns
+// each window. After a consumer group rebalance, the member that takes over a
+// partition loads exactly the windows that partition owns. It also loads the
+// partition checkpoint, so it can skip records already folded into that state.
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/window"
+)
+
+// Backend names accepted by the aggregate trigger's stateBackend setting.
+const (
+	// BackendMemory keeps state in the process only (optionally snapshotted to
+	// persistPath). It is the default and is not a Backend implementation.
+	BackendMemory = "memory"
+	// BackendFile stores state in a local directory, one file per partition.
+	BackendFile = "file"
+	// BackendKafka stores state in a compacted Kafka changelog topic.
+	BackendKafka = "kafka"
+)
+
+// NoCheckpoint is the checkpoint of a partition that has never been saved.
+const NoCheckpoint int64 = -1
+
+// Batch is one atomic update of a partition's state.
+type Batch struct {
+	// States are upserted by window name.
+	States []window.PersistedWindowState
+	// Deleted lists windows that no longer exist (e.g. idle-closed).
+	Deleted []string
+	// Offset is the source offset up to and including which the partition's
+	// state is complete. It becomes the partition checkpoint.
+	Offset int64
+}
+
+// Snapshot is the restored state of one partition.
+type Snapshot struct {
+	States []window.PersistedWindowState
+	// Offset is the checkpoint recorded by the last Save, or NoCheckpoint.
+	Offset int64
+}
+
+// Backend persists window state keyed by (source partition, window name).
+//
+// Implementations must make a Save durable before returning, and must write
+// the checkpoint no earlier than the states it covers. A crash part-way
+// through a Save can then only leave newer states behind an older checkpoint,
+// so the records in between are applied twice — the at-least-once behaviour
+// of a consumer without a state backend — but never lost.
+type Backend interface {
+	// Save applies batch to the state owned by partition.
+	Save(ctx context.Context, partition int32, batch Batch) error
+	// Load returns every window owned by partition together with its checkpoint.
+	Load(ctx context.Context, partition int32) (Snapshot, error)
+	// Close releases the backend's resources.
+	Close() error
+}
+
+// NormalizeBackend lower-cases name and maps "" to BackendMemory. It returns an
+// error for names that are not a known backend.
+func NormalizeBackend(name string) (string, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	switch n {
+	case "":
+		return BackendMemory, nil
+	case BackendMemory, BackendFile, BackendKafka:
+		return n, nil
+	default:
+		return "", fmt.Errorf("unsupported stateBackend %q (accepted: %q, %q, %q)", name, BackendMemory, BackendFile, BackendKafka)
+	}
+}
@@ -0,0 +1,309 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/window"
+)
+
+// checkpointKey is the changelog record key that carries a partition's
+// checkpoint. Window names of keyed sub-windows always contain ":", so the key
+// cannot collide with a window.
+const checkpointKey = "__checkpoint__"
+
+// checkpointRecord is the value of a checkpointKey record.
+type checkpointRecord struct {
+	Offset int64 `json:"offset"`
+}
+
+// ChangelogConfig configures a ChangelogBackend.
+type ChangelogConfig struct {
+	Brokers []string
+	// Sarama is the client configuration (auth, TLS, version). The producer
+	// and consumer settings the changelog depends on are overridden.
+	Sarama *sarama.Config
+	// Topic is the changelog topic.
+	Topic string
+	// SourceTopic is the topic whose partitions the changelog mirrors.
+	SourceTopic string
+	// ReplicationFactor is used when the changelog topic is created.
+	// 0 uses the broker default.
+	ReplicationFactor int16
+}
+
+// ChangelogBackend stores window state in a compacted Kafka topic, the way
+// Kafka Streams backs its state stores. Changelog partition N holds the
+// windows owned by source partition N. Each window is one record keyed by
+// window name, and a window deletion is a tombstone. Log compaction keeps
+// only the latest record per key, so restoring a partition reads just the
+// current windows.
+type ChangelogBackend struct {
+	topic    string
+	producer sarama.SyncProducer
+	consumer sarama.Consumer
+	// offsets returns the oldest and next (high-water) offset of a changelog partition.
+	offsets func(partition int32) (oldest, newest int64, err error)
+	close   func() error
+}
+
+// NewChangelogBackend connects to the cluster, makes sure the changelog topic
+// exists with compaction and at least as many partitions as the source topic,
+// and returns a backend that writes to it.
+func NewChangelogBackend(cfg ChangelogConfig) (*ChangelogBackend, error) {
+	if strings.TrimSpace(cfg.Topic) == "" {
+		return nil, fmt.Errorf("kafka-stream/state: changelog topic must not be empty")
+	}
+	sc := cfg.Sarama
+	if sc == nil {
+		sc = sarama.NewConfig()
+	}
+	// Each record is written to the changelog partition matching its source
+	// partition, and must be fully replicated before the checkpoint covering it.
+	sc.Producer.Partitioner = sarama.NewManualPartitioner
+	sc.Producer.RequiredAcks = sarama.WaitForAll
+	sc.Producer.Return.Successes = true
+	sc.Producer.Return.Errors = true
+	sc.Consumer.Return.Errors = true
+
+	client, err := sarama.NewClient(cfg.Brokers, sc)
+	if err != nil {
+		return nil, fmt.Errorf("kafka-stream/state: failed to connect [brokers=%v]: %w", cfg.Brokers, err)
+	}
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("kafka-stream/state: failed to create cluster admin: %w", err)
+	}
+	// Closing the admin would close the shared client, so it is left to the
+	// garbage collector; it holds no resources of its own.
+	if err := ensureChangelogTopic(admin, cfg.Topic, cfg.SourceTopic, cfg.ReplicationFactor); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("kafka-stream/state: failed to create changelog producer: %w", err)
+	}
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		_ = producer.Close()
+		_ = client.Close()
+		return nil, fmt.Errorf("kafka-stream/state: failed to create changelog consumer: %w", err)
+	}
+
+	b := newChangelogBackend(cfg.Topic, producer, consumer, func(partition int32) (int64, int64, error) {
+		oldest, err := client.GetOffset(cfg.Topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return 0, 0, err
+		}
+		newest, err := client.GetOffset(cfg.Topic, partition, sarama.OffsetNewest)
+		return oldest, newest, err
+	})
+	b.close = func() error {
+		return errors.Join(producer.Close(), consumer.Close(), client.Close())
+	}
+	return b, nil
+}
+
+func newChangelogBackend(
+	topic string,
+	producer sarama.SyncProducer,
+	consumer sarama.Consumer,
+	offsets func(partition int32) (int64, int64, error),
+) *ChangelogBackend {
+	return &ChangelogBackend{
+		topic:    topic,
+		producer: producer,
+		consumer: consumer,
+		offsets:  offsets,
+		close: func() error {
+			return errors.Join(producer.Close(), consumer.Close())
+		},
+	}
+}
+
+// Save writes one record per state, one tombstone per deleted window, and
+// finally the checkpoint, all to the changelog partition matching partition.
+// Records of one partition are ordered, so the checkpoint never lands ahead
+// of the states it covers.
+func (b *ChangelogBackend) Save(_ context.Context, partition int32, batch Batch) error {
+	msgs := make([]*sarama.ProducerMessage, 0, len(batch.States)+len(batch.Deleted)+1)
+	for _, s := range batch.States {
+		value, err := json.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("kafka-stream/state: encode error for window %q: %w", s.Name, err)
+		}
+		msgs = append(msgs, b.record(partition, s.Name, value))
+	}
+	for _, name := range batch.Deleted {
+		msgs = append(msgs, b.record(partition, name, nil))
+	}
+	cp, err := json.Marshal(checkpointRecord{Offset: batch.Offset})
+	if err != nil {
+		return fmt.Errorf("kafka-stream/state: encode error for checkpoint: %w", err)
+	}
+	msgs = append(msgs, b.record(partition, checkpointKey, cp))
+
+	if err := b.producer.SendMessages(msgs); err != nil {
+		return fmt.Errorf("kafka-stream/state: changelog write to %s/%d failed: %w", b.topic, partition, err)
+	}
+	return nil
+}
+
+func (b *ChangelogBackend) record(partition int32, key string, value []byte) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{
+		Topic:     b.topic,
+		Partition: partition,
+		Key:       sarama.StringEncoder(key),
+	}
+	if value != nil {
+		msg.Value = sarama.ByteEncoder(value)
+	}
+	return msg
+}
+
+// Load replays the changelog partition from its oldest retained record up to
+// the high-water mark read at the start of the call, keeping the latest
+// record per key.
+func (b *ChangelogBackend) Load(ctx context.Context, partition int32) (Snapshot, error) {
+	snap := Snapshot{Offset: NoCheckpoint}
+	oldest, newest, err := b.offsets(partition)
+	if err != nil {
+		return snap, fmt.Errorf("kafka-stream/state: cannot read offsets of %s/%d: %w", b.topic, partition, err)
+	}
+	if newest <= oldest {
+		return snap, nil // empty partition
+	}
+
+	pc, err := b.consumer.ConsumePartition(b.topic, partition, oldest)
+	if err != nil {
+		return snap, fmt.Errorf("kafka-stream/state: cannot consume %s/%d: %w", b.topic, partition, err)
+	}
+	defer pc.AsyncClose()
+
+	states := make(map[string]window.PersistedWindowState)
+	errs := pc.Errors()
+	for {
+		select {
+		case msg, ok := <-pc.Messages():
+			if !ok {
+				return snap, fmt.Errorf("kafka-stream/state: %s/%d closed before offset %d", b.topic, partition, newest-1)
+			}
+			if err := applyRecord(states, &snap, msg); err != nil {
+				return snap, err
+			}
+			if msg.Offset+1 >= newest {
+				snap.States = make([]window.PersistedWindowState, 0, len(states))
+				for _, s := range states {
+					snap.States = append(snap.States, s)
+				}
+				return snap, nil
+			}
+		case cerr, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			return snap, fmt.Errorf("kafka-stream/state: restore of %s/%d failed: %w", b.topic, partition, cerr)
+		case <-ctx.Done():
+			return snap, fmt.Errorf("kafka-stream/state: restore of %s/%d interrupted: %w", b.topic, partition, ctx.Err())
+		}
+	}
+}
+
+// applyRecord folds one changelog record into the restored state.
+func applyRecord(states map[string]window.PersistedWindowState, snap *Snapshot, msg *sarama.ConsumerMessage) error {
+	key := string(msg.Key)
+	if key == checkpointKey {
+		var cp checkpointRecord
+		if err := json.Unmarshal(msg.Value, &cp); err != nil {
+			return fmt.Errorf("kafka-stream/state: decode error for checkpoint at offset %d: %w", msg.Offset, err)
+		}
+		snap.Offset = cp.Offset
+		return nil
+	}
+	if msg.Value == nil {
+		delete(states, key) // tombstone
+		return nil
+	}
+	var s window.PersistedWindowState
+	if err := json.Unmarshal(msg.Value, &s); err != nil {
+		return fmt.Errorf("kafka-stream/state: decode error for window %q at offset %d: %w", key, msg.Offset, err)
+	}
+	states[key] = s
+	return nil
+}
+
+// Close closes the producer, consumer and client.
+func (b *ChangelogBackend) Close() error { return b.close() }
+
+// ensureChangelogTopic creates topic as a compacted topic with the source
+// topic's partition count, or checks that an existing topic can serve as its
+// changelog.
+func ensureChangelogTopic(admin sarama.ClusterAdmin, topic, sourceTopic string, replication int16) error {
+	metas, err := admin.DescribeTopics([]string{sourceTopic, topic})
+	if err != nil {
+		return fmt.Errorf("kafka-stream/state: cannot describe topics %q and %q: %w", sourceTopic, topic, err)
+	}
+	var source, changelog *sarama.TopicMetadata
+	for _, m := range metas {
+		switch m.Name {
+		case sourceTopic:
+			source = m
+		case topic:
+			changelog = m
+		}
+	}
+	if source == nil {
+		return fmt.Errorf("kafka-stream/state: cannot describe source topic %q: %w", sourceTopic, sarama.ErrUnknownTopicOrPartition)
+	}
+	if source.Err != sarama.ErrNoError {
+		return fmt.Errorf("kafka-stream/state: cannot describe source topic %q: %w", sourceTopic, source.Err)
+	}
+	want := len(source.Partitions)
+
+	if changelog == nil || changelog.Err == sarama.ErrUnknownTopicOrPartition {
+		compact := "compact"
+		if replication <= 0 {
+			replication = -1 // broker default
+		}
+		err := admin.CreateTopic(topic, &sarama.TopicDetail{
+			NumPartitions:     int32(want),
+			ReplicationFactor: replication,
+			ConfigEntries:     map[string]*string{"cleanup.policy": &compact},
+		}, false)
+		if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+			return fmt.Errorf("kafka-stream/state: cannot create changelog topic %q: %w", topic, err)
+		}
+		return nil
+	}
+	if changelog.Err != sarama.ErrNoError {
+		return fmt.Errorf("kafka-stream/state: cannot describe changelog topic %q: %w", topic, changelog.Err)
+	}
+	if len(changelog.Partitions) < want {
+		return fmt.Errorf("kafka-stream/state: changelog topic %q has %d partitions but source topic %q has %d — it needs at least as many",
+			topic, len(changelog.Partitions), sourceTopic, want)
+	}
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.TopicResource,
+		Name:        topic,
+		ConfigNames: []string{"cleanup.policy"},
+	})
+	if err != nil {
+		return fmt.Errorf("kafka-stream/state: cannot read config of changelog topic %q: %w", topic, err)
+	}
+	for _, e := range entries {
+		if e.Name == "cleanup.policy" && !strings.Contains(e.Value, "compact") {
+			return fmt.Errorf("kafka-stream/state: changelog topic %q has cleanup.policy=%s — it must include \"compact\" or old window state is deleted by retention",
+				topic, e.Value)
+		}
+	}
+	return nil
+}
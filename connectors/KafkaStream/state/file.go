@@ -0,0 +1,155 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/window"
+)
+
+// partitionFile is the gob-encoded content of one partition's state file.
+type partitionFile struct {
+	Offset int64
+	States []window.PersistedWindowState
+}
+
+// FileBackend is an embedded store that keeps each partition's windows in its
+// own gob file under a directory. Files are replaced atomically and fsync'd,
+// so a crash leaves either the previous or the new state of a partition.
+//
+// A local directory survives restarts of one instance. For state to follow a
+// partition to another instance, every instance must mount the same directory
+// (e.g. a shared volume); otherwise use the Kafka changelog backend.
+type FileBackend struct {
+	dir string
+
+	mu    sync.Mutex
+	cache map[int32]*partitionFile // partitions read or written by this process
+}
+
+// NewFileBackend returns a FileBackend rooted at dir, creating it if needed.
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("kafka-stream/state: cannot create state directory %q: %w", dir, err)
+	}
+	return &FileBackend{dir: dir, cache: make(map[int32]*partitionFile)}, nil
+}
+
+// Save merges batch into the partition's state and rewrites its file.
+func (b *FileBackend) Save(_ context.Context, partition int32, batch Batch) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current, err := b.read(partition)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]window.PersistedWindowState, len(current.States)+len(batch.States))
+	for _, s := range current.States {
+		byName[s.Name] = s
+	}
+	for _, s := range batch.States {
+		byName[s.Name] = s
+	}
+	for _, name := range batch.Deleted {
+		delete(byName, name)
+	}
+	next := &partitionFile{Offset: batch.Offset, States: make([]window.PersistedWindowState, 0, len(byName))}
+	for _, s := range byName {
+		next.States = append(next.States, s)
+	}
+	sort.Slice(next.States, func(i, j int) bool { return next.States[i].Name < next.States[j].Name })
+
+	if err := b.write(partition, next); err != nil {
+		return err
+	}
+	b.cache[partition] = next
+	return nil
+}
+
+// Load reads the partition's state file. A missing file is an empty partition.
+func (b *FileBackend) Load(_ context.Context, partition int32) (Snapshot, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Always re-read: another instance sharing the directory may have owned
+	// the partition since this process last saw it.
+	delete(b.cache, partition)
+	pf, err := b.read(partition)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	states := make([]window.PersistedWindowState, len(pf.States))
+	copy(states, pf.States)
+	return Snapshot{States: states, Offset: pf.Offset}, nil
+}
+
+// Close is a no-op; every Save is already on disk.
+func (b *FileBackend) Close() error { return nil }
+
+func (b *FileBackend) path(partition int32) string {
+	return filepath.Join(b.dir, fmt.Sprintf("partition-%d.gob", partition))
+}
+
+// read returns the cached partition state, loading it from disk on a miss.
+// Callers must hold b.mu.
+func (b *FileBackend) read(partition int32) (*partitionFile, error) {
+	if pf, ok := b.cache[partition]; ok {
+		return pf, nil
+	}
+	data, err := os.ReadFile(b.path(partition))
+	if os.IsNotExist(err) {
+		pf := &partitionFile{Offset: NoCheckpoint}
+		b.cache[partition] = pf
+		return pf, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kafka-stream/state: read error for partition %d: %w", partition, err)
+	}
+	pf := &partitionFile{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(pf); err != nil {
+		return nil, fmt.Errorf("kafka-stream/state: decode error for partition %d: %w", partition, err)
+	}
+	b.cache[partition] = pf
+	return pf, nil
+}
+
+// write atomically replaces the partition's file: temp file, fsync, rename,
+// then fsync of the directory so the rename itself survives a crash.
+func (b *FileBackend) write(partition int32, pf *partitionFile) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(pf); err != nil {
+		return fmt.Errorf("kafka-stream/state: encode error for partition %d: %w", partition, err)
+	}
+
+	target := b.path(partition)
+	tmp, err := os.CreateTemp(b.dir, filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("kafka-stream/state: write error for partition %d: %w", partition, err)
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), target)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("kafka-stream/state: write error for partition %d: %w", partition, err)
+	}
+	if d, err := os.Open(b.dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
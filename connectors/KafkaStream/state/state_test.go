@@ -0,0 +1,258 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/window"
+)
+
+func winState(name string, values ...float64) window.PersistedWindowState {
+	return window.PersistedWindowState{
+		Name:        name,
+		Type:        string(window.WindowTumblingCount),
+		Values:      values,
+		EventCount:  int64(len(values)),
+		WindowStart: time.UnixMilli(1_700_000_000_000).UTC(),
+	}
+}
+
+// ─── NormalizeBackend ────────────────────────────────────────────────────────
+
+func TestNormalizeBackend(t *testing.T) {
+	for in, want := range map[string]string{"": BackendMemory, "Memory": BackendMemory, " file ": BackendFile, "KAFKA": BackendKafka} {
+		got, err := NormalizeBackend(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := NormalizeBackend("redis")
+	assert.ErrorContains(t, err, "redis")
+}
+
+// ─── FileBackend ─────────────────────────────────────────────────────────────
+
+func TestFileBackend_RoundTripPerPartition(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewFileBackend(dir)
+	require.NoError(t, err)
+
+	snap, err := b.Load(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, NoCheckpoint, snap.Offset)
+	assert.Empty(t, snap.States)
+
+	require.NoError(t, b.Save(context.Background(), 0, Batch{States: []window.PersistedWindowState{winState("w:a", 1), winState("w:b", 2)}, Offset: 10}))
+	require.NoError(t, b.Save(context.Background(), 0, Batch{States: []window.PersistedWindowState{winState("w:a", 1, 5)}, Deleted: []string{"w:b"}, Offset: 12}))
+	require.NoError(t, b.Save(context.Background(), 1, Batch{States: []window.PersistedWindowState{winState("w:c", 7)}, Offset: 3}))
+
+	// A second process sharing the directory sees the same state.
+	other, err := NewFileBackend(dir)
+	require.NoError(t, err)
+	snap, err = other.Load(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), snap.Offset)
+	require.Len(t, snap.States, 1)
+	assert.Equal(t, "w:a", snap.States[0].Name)
+	assert.Equal(t, []float64{1, 5}, snap.States[0].Values)
+
+	snap, err = other.Load(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), snap.Offset)
+	require.Len(t, snap.States, 1)
+	assert.Equal(t, "w:c", snap.States[0].Name)
+}
+
+func TestFileBackend_LoadSeesWritesFromAnotherProcess(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewFileBackend(dir)
+	require.NoError(t, err)
+	b, err := NewFileBackend(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Save(context.Background(), 0, Batch{States: []window.PersistedWindowState{winState("w:a", 1)}, Offset: 1}))
+	_, err = b.Load(context.Background(), 0) // b caches partition 0
+	require.NoError(t, err)
+	require.NoError(t, a.Save(context.Background(), 0, Batch{States: []window.PersistedWindowState{winState("w:a", 1, 2)}, Offset: 2}))
+
+	snap, err := b.Load(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), snap.Offset)
+	assert.Equal(t, []float64{1, 2}, snap.States[0].Values)
+}
+
+// ─── ChangelogBackend ────────────────────────────────────────────────────────
+
+func TestChangelogBackend_SaveWritesStatesTombstonesThenCheckpoint(t *testing.T) {
+	cfg := mocks.NewTestConfig()
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.Partitioner = sarama.NewManualPartitioner
+	producer := mocks.NewSyncProducer(t, cfg)
+	consumer := mocks.NewConsumer(t, nil)
+
+	var got []*sarama.ProducerMessage
+	record := func(msg *sarama.ProducerMessage) error {
+		got = append(got, msg)
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(record)
+	}
+
+	b := newChangelogBackend("cl", producer, consumer, nil)
+	require.NoError(t, b.Save(context.Background(), 4, Batch{
+		States:  []window.PersistedWindowState{winState("w:a", 1)},
+		Deleted: []string{"w:b"},
+		Offset:  99,
+	}))
+	require.NoError(t, b.Close())
+
+	require.Len(t, got, 3)
+	for _, m := range got {
+		assert.Equal(t, "cl", m.Topic)
+		assert.Equal(t, int32(4), m.Partition)
+	}
+	key := func(m *sarama.ProducerMessage) string { k, _ := m.Key.Encode(); return string(k) }
+	assert.Equal(t, "w:a", key(got[0]))
+	assert.Equal(t, "w:b", key(got[1]))
+	assert.Nil(t, got[1].Value, "deleted window is a tombstone")
+	assert.Equal(t, checkpointKey, key(got[2]))
+	v, _ := got[2].Value.Encode()
+	assert.JSONEq(t, `{"offset":99}`, string(v))
+}
+
+func TestChangelogBackend_SaveError(t *testing.T) {
+	cfg := mocks.NewTestConfig()
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.Partitioner = sarama.NewManualPartitioner
+	producer := mocks.NewSyncProducer(t, cfg)
+	producer.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+
+	b := newChangelogBackend("cl", producer, mocks.NewConsumer(t, nil), nil)
+	err := b.Save(context.Background(), 0, Batch{Offset: 1})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, sarama.ErrNotEnoughReplicas)
+	_ = b.Close()
+}
+
+func TestChangelogBackend_LoadKeepsLatestRecordPerKey(t *testing.T) {
+	consumer := mocks.NewConsumer(t, nil)
+	encode := func(v interface{}) []byte { b, _ := json.Marshal(v); return b }
+	pc := consumer.ExpectConsumePartition("cl", 2, 0)
+	pc.YieldMessage(&sarama.ConsumerMessage{Key: []byte("w:a"), Value: encode(winState("w:a", 1))})
+	pc.YieldMessage(&sarama.ConsumerMessage{Key: []byte("w:b"), Value: encode(winState("w:b", 2))})
+	pc.YieldMessage(&sarama.ConsumerMessage{Key: []byte(checkpointKey), Value: encode(checkpointRecord{Offset: 40})})
+	pc.YieldMessage(&sarama.ConsumerMessage{Key: []byte("w:a"), Value: encode(winState("w:a", 1, 3))})
+	pc.YieldMessage(&sarama.ConsumerMessage{Key: []byte("w:b"), Value: nil})
+	pc.YieldMessage(&sarama.ConsumerMessage{Key: []byte(checkpointKey), Value: encode(checkpointRecord{Offset: 41})})
+
+	b := newChangelogBackend("cl", mocks.NewSyncProducer(t, nil), consumer, func(int32) (int64, int64, error) {
+		return 0, 6, nil
+	})
+	snap, err := b.Load(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(41), snap.Offset)
+	require.Len(t, snap.States, 1)
+	assert.Equal(t, "w:a", snap.States[0].Name)
+	assert.Equal(t, []float64{1, 3}, snap.States[0].Values)
+	assert.True(t, snap.States[0].WindowStart.Equal(winState("w:a").WindowStart))
+	_ = b.Close()
+}
+
+func TestChangelogBackend_LoadEmptyPartition(t *testing.T) {
+	b := newChangelogBackend("cl", mocks.NewSyncProducer(t, nil), mocks.NewConsumer(t, nil), func(int32) (int64, int64, error) {
+		return 5, 5, nil // fully compacted away / never written
+	})
+	snap, err := b.Load(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, NoCheckpoint, snap.Offset)
+	assert.Empty(t, snap.States)
+	_ = b.Close()
+}
+
+func TestChangelogBackend_LoadConsumerError(t *testing.T) {
+	consumer := mocks.NewConsumer(t, nil)
+	consumer.ExpectConsumePartition("cl", 0, 0).YieldError(sarama.ErrOffsetOutOfRange)
+	b := newChangelogBackend("cl", mocks.NewSyncProducer(t, nil), consumer, func(int32) (int64, int64, error) {
+		return 0, 3, nil
+	})
+	_, err := b.Load(context.Background(), 0)
+	assert.ErrorContains(t, err, "restore of cl/0 failed")
+	_ = b.Close()
+}
+
+// ─── ensureChangelogTopic ────────────────────────────────────────────────────
+
+// fakeAdmin implements the ClusterAdmin calls used by ensureChangelogTopic.
+type fakeAdmin struct {
+	sarama.ClusterAdmin
+	topics  map[string]*sarama.TopicMetadata
+	policy  string
+	created *sarama.TopicDetail
+}
+
+func (a *fakeAdmin) DescribeTopics(names []string) ([]*sarama.TopicMetadata, error) {
+	var out []*sarama.TopicMetadata
+	for _, n := range names {
+		if m, ok := a.topics[n]; ok {
+			out = append(out, m)
+		} else {
+			out = append(out, &sarama.TopicMetadata{Name: n, Err: sarama.ErrUnknownTopicOrPartition})
+		}
+	}
+	return out, nil
+}
+
+func (a *fakeAdmin) CreateTopic(_ string, d *sarama.TopicDetail, _ bool) error {
+	a.created = d
+	return nil
+}
+
+func (a *fakeAdmin) DescribeConfig(sarama.ConfigResource) ([]sarama.ConfigEntry, error) {
+	return []sarama.ConfigEntry{{Name: "cleanup.policy", Value: a.policy}}, nil
+}
+
+func topicMeta(name string, partitions int) *sarama.TopicMetadata {
+	m := &sarama.TopicMetadata{Name: name}
+	for i := 0; i < partitions; i++ {
+		m.Partitions = append(m.Partitions, &sarama.PartitionMetadata{ID: int32(i)})
+	}
+	return m
+}
+
+func TestEnsureChangelogTopic_CreatesCompactedTopic(t *testing.T) {
+	admin := &fakeAdmin{topics: map[string]*sarama.TopicMetadata{"src": topicMeta("src", 6)}}
+	require.NoError(t, ensureChangelogTopic(admin, "cl", "src", 0))
+	require.NotNil(t, admin.created)
+	assert.Equal(t, int32(6), admin.created.NumPartitions)
+	assert.Equal(t, int16(-1), admin.created.ReplicationFactor)
+	assert.Equal(t, "compact", *admin.created.ConfigEntries["cleanup.policy"])
+}
+
+func TestEnsureChangelogTopic_ValidatesExistingTopic(t *testing.T) {
+	admin := &fakeAdmin{
+		topics: map[string]*sarama.TopicMetadata{"src": topicMeta("src", 6), "cl": topicMeta("cl", 6)},
+		policy: "compact,delete",
+	}
+	require.NoError(t, ensureChangelogTopic(admin, "cl", "src", 3))
+	assert.Nil(t, admin.created)
+
+	admin.policy = "delete"
+	assert.ErrorContains(t, ensureChangelogTopic(admin, "cl", "src", 3), "cleanup.policy=delete")
+
+	admin.policy = "compact"
+	admin.topics["cl"] = topicMeta("cl", 4)
+	assert.ErrorContains(t, ensureChangelogTopic(admin, "cl", "src", 3), "has 4 partitions")
+}
+
+func TestEnsureChangelogTopic_MissingSourceTopic(t *testing.T) {
+	err := ensureChangelogTopic(&fakeAdmin{}, "cl", "src", 0)
+	assert.True(t, errors.Is(err, sarama.ErrUnknownTopicOrPartition), err)
+}
@@ -55,6 +55,28 @@ var aggregateHandler = function (e) {
             return null;
         };
         n.validate = function (e, t) {
+            if (e === "schemaRegistryUrl" || e === "schemaRegistryUsername" || e === "schemaRegistryPassword") {
+                // Registry fields only apply to Schema Registry wire-format values.
+                var valueFormat = t.getField("valueFormat");
+                var format = valueFormat && valueFormat.value ? valueFormat.value : "json";
+                return wi_contrib_1.ValidationResult.newValidationResult()
+                    .setVisible(format === "avro" || format === "protobuf" || format === "json-schema");
+            }
+            if (e === "persistPath" || e === "persistEveryN" || e === "stateDir" || e === "changelogTopic" ||
+                e === "changelogReplicationFactor" || e === "stateFlushIntervalMs") {
+                // Snapshot settings apply to the memory backend, the rest to the backend they configure.
+                var stateBackend = t.getField("stateBackend");
+                var backend = stateBackend && stateBackend.value ? stateBackend.value : "memory";
+                var visible = backend !== "memory";
+                if (e === "persistPath" || e === "persistEveryN") {
+                    visible = backend === "memory";
+                } else if (e === "stateDir") {
+                    visible = backend === "file";
+                } else if (e === "changelogTopic" || e === "changelogReplicationFactor") {
+                    visible = backend === "kafka";
+                }
+                return wi_contrib_1.ValidationResult.newValidationResult().setVisible(visible);
+            }
             return null;
         };
         n.action = function (e, t) {
@@ -1,6 +1,7 @@
 package aggregate
 
 import (
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/support/connection"
 )
@@ -28,6 +29,19 @@ type Settings struct {
 	// InitialOffset: "newest" (default) | "oldest"
 	InitialOffset string `md:"initialOffset"`
 
+	// ── Value decoding ───────────────────────────────────────────────────────
+	// ValueFormat selects how record values are decoded: "json" (default),
+	// "avro", "protobuf" or "json-schema" (Confluent Schema Registry wire
+	// format), or "string" (raw value emitted as {"value": "..."}).
+	ValueFormat string `md:"valueFormat"`
+	// SchemaRegistryURL is the Schema Registry base URL. Required for avro,
+	// protobuf and json-schema; schemas are fetched by ID and cached.
+	SchemaRegistryURL string `md:"schemaRegistryUrl"`
+	// SchemaRegistryUsername / SchemaRegistryPassword enable basic auth
+	// (e.g. a Confluent Cloud API key and secret).
+	SchemaRegistryUsername string `md:"schemaRegistryUsername"`
+	SchemaRegistryPassword string `md:"schemaRegistryPassword"`
+
 	// ── Window configuration (required) ──────────────────────────────────────
 	WindowName string `md:"windowName,required"`
 	WindowType string `md:"windowType,required"` // TumblingTime|TumblingCount|SlidingTime|SlidingCount
@@ -51,6 +65,21 @@ type Settings struct {
 	PersistPath   string `md:"persistPath"`   // file path for gob state snapshots; "" = disabled
 	PersistEveryN int64  `md:"persistEveryN"` // snapshot every N messages; 0 = shutdown-only
 
+	// ── Partition-aware state backend ─────────────────────────────────────────
+	// StateBackend selects where keyed window state is kept: "memory" (default;
+	// process-local, optionally snapshotted to persistPath), "file" (one file
+	// per source partition under stateDir) or "kafka" (compacted changelog
+	// topic). With "file" or "kafka", each consumer restores and owns only the
+	// windows of the partitions it is assigned, so several instances can share
+	// one consumer group.
+	StateBackend string `md:"stateBackend"`
+	StateDir     string `md:"stateDir"` // root directory for stateBackend=file
+	// ChangelogTopic defaults to "<consumerGroup>-<windowName>-changelog". It is
+	// created (compacted, one partition per source partition) when missing.
+	ChangelogTopic             string `md:"changelogTopic"`
+	ChangelogReplicationFactor int64  `md:"changelogReplicationFactor"` // 0 = broker default
+	StateFlushIntervalMs       int64  `md:"stateFlushIntervalMs"`       // 0 = 1000
+
 	// ── Consumer group rebalance ──────────────────────────────────────────────
 	// BalanceStrategy sets the Kafka consumer group rebalance strategy.
 	// "roundrobin" (default) | "sticky" (recommended for aggregate — minimises
@@ -80,6 +109,16 @@ type Settings struct {
 	OnSchemaError string `md:"onSchemaError"`
 }
 
+// SerdeConfig returns the record value decoding configuration.
+func (s *Settings) SerdeConfig() serde.Config {
+	return serde.Config{
+		Format:      s.ValueFormat,
+		RegistryURL: s.SchemaRegistryURL,
+		Username:    s.SchemaRegistryUsername,
+		Password:    s.SchemaRegistryPassword,
+	}
+}
+
 // HandlerSettings define which event type a particular handler (flow) should
 // receive from this trigger.
 type HandlerSettings struct {
@@ -0,0 +1,310 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/project-flogo/core/support/log"
+
+	kafkastream "github.com/mpandav-tibco/flogo-extensions/kafkastream"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/state"
+)
+
+// defaultStateFlushIntervalMs applies when stateFlushIntervalMs is 0.
+const defaultStateFlushIntervalMs = 1000
+
+// stateManager ties the keyed sub-windows in the process registry to the
+// source partition that feeds them, and persists them through a state.Backend.
+// Each consumer group session restores the windows of the partitions it
+// claims and, when it ends, flushes and drops them. So after a rebalance, only
+// the new owner of a partition holds that partition's windows.
+//
+// Lock order: a partitionState's mu may be held while taking m.mu, never the
+// reverse.
+type stateManager struct {
+	backend  state.Backend
+	settings *Settings
+	logger   log.Logger
+
+	mu         sync.Mutex
+	partitions map[int32]*partitionState
+	owners     map[string]int32 // window name → source partition
+	misrouted  map[string]bool  // windows already warned about
+}
+
+// partitionState is the window bookkeeping of one claimed source partition.
+// mu is held for the whole of processing one record, so a flush always sees
+// window state and offset that agree.
+type partitionState struct {
+	mu         sync.Mutex
+	windows    map[string]struct{}
+	dirty      map[string]struct{} // changed since the last flush
+	deleted    map[string]struct{} // removed since the last flush
+	offset     int64               // last source offset applied to the windows
+	checkpoint int64               // offset the backend holds state up to
+	first      int64               // first offset applied in this session; -1 = none
+}
+
+func newPartitionState(offset int64) *partitionState {
+	return &partitionState{
+		windows:    make(map[string]struct{}),
+		dirty:      make(map[string]struct{}),
+		deleted:    make(map[string]struct{}),
+		offset:     offset,
+		checkpoint: offset,
+		first:      -1,
+	}
+}
+
+func newStateManager(backend state.Backend, s *Settings, logger log.Logger) *stateManager {
+	return &stateManager{
+		backend:    backend,
+		settings:   s,
+		logger:     logger,
+		partitions: make(map[int32]*partitionState),
+		owners:     make(map[string]int32),
+		misrouted:  make(map[string]bool),
+	}
+}
+
+// partition returns the bookkeeping for p, creating it for a partition that
+// was not restored (e.g. when called outside a session in tests).
+func (m *stateManager) partition(p int32) *partitionState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ps, ok := m.partitions[p]
+	if !ok {
+		ps = newPartitionState(state.NoCheckpoint)
+		m.partitions[p] = ps
+	}
+	return ps
+}
+
+// restore loads the windows of the given partitions into the registry and
+// records each partition's checkpoint.
+func (m *stateManager) restore(ctx context.Context, partitions []int32) error {
+	for _, p := range partitions {
+		snap, err := m.backend.Load(ctx, p)
+		if err != nil {
+			return fmt.Errorf("restore of partition %d failed: %w", p, err)
+		}
+		ps := newPartitionState(snap.Offset)
+		for _, st := range snap.States {
+			store, err := kafkastream.GetOrCreateWindowStore(buildWindowConfig(m.settings, st.Name))
+			if err != nil {
+				return fmt.Errorf("restore of window %q (partition %d) failed: %w", st.Name, p, err)
+			}
+			store.LoadState(st)
+			ps.windows[st.Name] = struct{}{}
+		}
+
+		m.mu.Lock()
+		m.partitions[p] = ps
+		for name := range ps.windows {
+			m.owners[name] = p
+		}
+		m.mu.Unlock()
+		m.logger.Infof("kafka-stream/aggregate-trigger: partition %d — restored %d window(s) up to offset %d",
+			p, len(snap.States), snap.Offset)
+	}
+	return nil
+}
+
+// release flushes every partition of the ending session and removes its
+// windows from the registry, so a partition that moves to another instance
+// is not also aggregated here. For each partition whose flush failed it
+// returns the offset to rewind the session to: the first record the backend
+// does not hold. The next owner then applies those records again instead of
+// losing them.
+func (m *stateManager) release(ctx context.Context) (map[int32]int64, error) {
+	var (
+		rewind   map[int32]int64
+		firstErr error
+	)
+
+	m.mu.Lock()
+	partitions := m.partitions
+	m.partitions = make(map[int32]*partitionState)
+	m.owners = make(map[string]int32)
+	m.mu.Unlock()
+
+	for p, ps := range partitions {
+		if err := m.save(ctx, p, ps); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			ps.mu.Lock()
+			if ps.first >= 0 { // records were applied in this session
+				if rewind == nil {
+					rewind = make(map[int32]int64)
+				}
+				if ps.checkpoint >= ps.first {
+					rewind[p] = ps.checkpoint + 1
+				} else {
+					rewind[p] = ps.first
+				}
+			}
+			ps.mu.Unlock()
+		}
+		ps.mu.Lock()
+		for name := range ps.windows {
+			kafkastream.UnregisterWindowStore(name)
+		}
+		ps.mu.Unlock()
+	}
+	return rewind, firstErr
+}
+
+// skip reports whether the record at offset is already part of the restored
+// or accumulated state of partition p (a redelivery after a crash or rebalance).
+func (m *stateManager) skip(p int32, offset int64) bool {
+	ps := m.partition(p)
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return offset <= ps.offset
+}
+
+// apply runs fn — the processing of the record at offset — with partition p
+// locked, then advances the partition offset.
+func (m *stateManager) apply(p int32, offset int64, fn func()) {
+	ps := m.partition(p)
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	fn()
+	if ps.first < 0 {
+		ps.first = offset
+	}
+	if offset > ps.offset {
+		ps.offset = offset
+	}
+}
+
+// touch marks windowName as changed by partition p. It is called from within
+// apply, with the partition already locked.
+func (m *stateManager) touch(p int32, windowName string) {
+	ps := m.partition(p)
+	ps.windows[windowName] = struct{}{}
+	ps.dirty[windowName] = struct{}{}
+	delete(ps.deleted, windowName)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	owner, owned := m.owners[windowName]
+	if !owned {
+		m.owners[windowName] = p
+		return
+	}
+	if owner != p && !m.misrouted[windowName] {
+		m.misrouted[windowName] = true
+		m.logger.Warnf("kafka-stream/aggregate-trigger: window %q receives records from partitions %d and %d — produce records keyed by %q so that each key stays on one partition, or its state cannot follow a rebalance",
+			windowName, owner, p, m.settings.KeyField)
+	}
+}
+
+// forget records that windowName was removed from the registry (idle sweep).
+func (m *stateManager) forget(windowName string) {
+	m.mu.Lock()
+	p, owned := m.owners[windowName]
+	delete(m.owners, windowName)
+	ps := m.partitions[p]
+	m.mu.Unlock()
+	if !owned || ps == nil {
+		return
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.windows, windowName)
+	delete(ps.dirty, windowName)
+	ps.deleted[windowName] = struct{}{}
+}
+
+// flush saves the changed windows and offset of every partition that moved
+// since its last checkpoint. It attempts every partition and returns the
+// first error.
+func (m *stateManager) flush(ctx context.Context) error {
+	m.mu.Lock()
+	ids := make([]int32, 0, len(m.partitions))
+	for p := range m.partitions {
+		ids = append(ids, p)
+	}
+	m.mu.Unlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var firstErr error
+	for _, p := range ids {
+		if err := m.flushPartition(ctx, p); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *stateManager) flushPartition(ctx context.Context, p int32) error {
+	m.mu.Lock()
+	ps := m.partitions[p]
+	m.mu.Unlock()
+	if ps == nil {
+		return nil
+	}
+	return m.save(ctx, p, ps)
+}
+
+// save writes the changes of one partition to the backend.
+func (m *stateManager) save(ctx context.Context, p int32, ps *partitionState) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if len(ps.dirty) == 0 && len(ps.deleted) == 0 && ps.offset == ps.checkpoint {
+		return nil
+	}
+	batch := state.Batch{Offset: ps.offset}
+	for name := range ps.dirty {
+		if store, ok := kafkastream.GetWindowStore(name); ok {
+			batch.States = append(batch.States, store.SaveState())
+		} else {
+			// Swept between processing and this flush.
+			batch.Deleted = append(batch.Deleted, name)
+		}
+	}
+	for name := range ps.deleted {
+		batch.Deleted = append(batch.Deleted, name)
+	}
+	if err := m.backend.Save(ctx, p, batch); err != nil {
+		return fmt.Errorf("state flush of partition %d failed: %w", p, err)
+	}
+	ps.dirty = make(map[string]struct{})
+	ps.deleted = make(map[string]struct{})
+	ps.checkpoint = ps.offset
+	return nil
+}
+
+// close releases the backend.
+func (m *stateManager) close() error {
+	return m.backend.Close()
+}
+
+// unsafeNameChars matches characters replaced when a consumer group or window
+// name is used in a directory or topic name.
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// stateDirFor returns the file backend directory of one trigger: a directory
+// per consumer group and window under stateDir, so triggers sharing a
+// stateDir never write each other's files.
+func stateDirFor(s *Settings) string {
+	return filepath.Join(s.StateDir,
+		unsafeNameChars.ReplaceAllString(s.ConsumerGroup, "_"),
+		unsafeNameChars.ReplaceAllString(s.WindowName, "_"))
+}
+
+// changelogTopicFor returns the configured changelog topic or the default
+// "<consumerGroup>-<windowName>-changelog".
+func changelogTopicFor(s *Settings) string {
+	if s.ChangelogTopic != "" {
+		return s.ChangelogTopic
+	}
+	return unsafeNameChars.ReplaceAllString(s.ConsumerGroup+"-"+s.WindowName, "_") + "-changelog"
+}
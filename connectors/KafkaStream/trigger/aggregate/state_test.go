@@ -0,0 +1,233 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kafkastream "github.com/mpandav-tibco/flogo-extensions/kafkastream"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/state"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/window"
+)
+
+// ─── helpers ─────────────────────────────────────────────────────────────────
+
+// memBackend is an in-memory state.Backend shared by the "instances" of a test.
+type memBackend struct {
+	mu         sync.Mutex
+	partitions map[int32]*memPartition
+	saves      []state.Batch
+	failSave   error
+}
+
+type memPartition struct {
+	offset int64
+	states map[string]window.PersistedWindowState
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{partitions: make(map[int32]*memPartition)}
+}
+
+func (b *memBackend) Save(_ context.Context, p int32, batch state.Batch) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failSave != nil {
+		return b.failSave
+	}
+	mp, ok := b.partitions[p]
+	if !ok {
+		mp = &memPartition{states: make(map[string]window.PersistedWindowState)}
+		b.partitions[p] = mp
+	}
+	for _, s := range batch.States {
+		mp.states[s.Name] = s
+	}
+	for _, n := range batch.Deleted {
+		delete(mp.states, n)
+	}
+	mp.offset = batch.Offset
+	b.saves = append(b.saves, batch)
+	return nil
+}
+
+func (b *memBackend) Load(_ context.Context, p int32) (state.Snapshot, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	mp, ok := b.partitions[p]
+	if !ok {
+		return state.Snapshot{Offset: state.NoCheckpoint}, nil
+	}
+	snap := state.Snapshot{Offset: mp.offset}
+	for _, s := range mp.states {
+		snap.States = append(snap.States, s)
+	}
+	return snap, nil
+}
+
+func (b *memBackend) Close() error { return nil }
+
+func stateTrigger(wn string, backend state.Backend) *Trigger {
+	trig := newAggregateTrigger(&Settings{
+		Topic: "t", ConsumerGroup: "g",
+		WindowName: wn, WindowType: "TumblingCount", WindowSize: 3,
+		Function: "sum", ValueField: "value", KeyField: "device",
+		StateBackend: state.BackendKafka,
+	})
+	trig.state = newStateManager(backend, trig.settings, log.RootLogger())
+	return trig
+}
+
+// feed runs one record through the trigger the way handleMessage does.
+func feed(t *testing.T, trig *Trigger, partition int32, offset int64, device string, value float64) (*Output, string) {
+	t.Helper()
+	if trig.state.skip(partition, offset) {
+		return nil, "skipped"
+	}
+	var (
+		out *Output
+		et  string
+		err error
+	)
+	trig.state.apply(partition, offset, func() {
+		out, et, err = trig.processPayload(context.Background(), map[string]interface{}{
+			"value": value, "device": device,
+		}, "t", partition, offset)
+	})
+	require.NoError(t, err)
+	return out, et
+}
+
+// ─── rebalance handoff ───────────────────────────────────────────────────────
+
+func TestState_WindowFollowsPartitionToNewOwner(t *testing.T) {
+	wn := "st-handoff"
+	clearWindow(wn+":dev-A", wn+":dev-B")
+	backend := newMemBackend()
+
+	// Instance A owns partitions 0 and 1 and sees two events per device.
+	a := stateTrigger(wn, backend)
+	require.NoError(t, a.state.restore(context.Background(), []int32{0, 1}))
+	feed(t, a, 0, 10, "dev-A", 1)
+	feed(t, a, 1, 20, "dev-B", 10)
+	feed(t, a, 0, 11, "dev-A", 2)
+	feed(t, a, 1, 21, "dev-B", 20)
+	rewind, err := a.state.release(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, rewind)
+
+	_, exists := kafkastream.GetWindowStore(wn + ":dev-A")
+	assert.False(t, exists, "released windows must leave the registry")
+
+	// Instance B is assigned partition 0 only.
+	b := stateTrigger(wn, backend)
+	require.NoError(t, b.state.restore(context.Background(), []int32{0}))
+	_, exists = kafkastream.GetWindowStore(wn + ":dev-B")
+	assert.False(t, exists, "partition 1's window must not be restored")
+
+	// Redelivery of records already in the restored state is skipped.
+	_, et := feed(t, b, 0, 11, "dev-A", 2)
+	assert.Equal(t, "skipped", et)
+
+	out, et := feed(t, b, 0, 12, "dev-A", 3)
+	assert.Equal(t, EventTypeWindowClose, et)
+	require.NotNil(t, out)
+	assert.Equal(t, 6.0, out.WindowResult.Result, "1+2 from instance A plus 3 from instance B")
+	assert.Equal(t, int64(3), out.WindowResult.Count)
+
+	_, err = b.state.release(context.Background())
+	require.NoError(t, err)
+}
+
+func TestState_FlushSavesOnlyChangedPartitions(t *testing.T) {
+	wn := "st-flush"
+	clearWindow(wn + ":dev-A")
+	backend := newMemBackend()
+	trig := stateTrigger(wn, backend)
+	require.NoError(t, trig.state.restore(context.Background(), []int32{0, 1}))
+	defer trig.state.release(context.Background()) //nolint:errcheck
+
+	feed(t, trig, 0, 5, "dev-A", 1)
+	require.NoError(t, trig.state.flush(context.Background()))
+	require.Len(t, backend.saves, 1)
+	assert.Equal(t, int64(5), backend.saves[0].Offset)
+	require.Len(t, backend.saves[0].States, 1)
+	assert.Equal(t, wn+":dev-A", backend.saves[0].States[0].Name)
+
+	// Nothing changed since — no further write.
+	require.NoError(t, trig.state.flush(context.Background()))
+	assert.Len(t, backend.saves, 1)
+}
+
+func TestState_IdleSweptWindowIsDeleted(t *testing.T) {
+	wn := "st-forget"
+	clearWindow(wn + ":dev-A")
+	backend := newMemBackend()
+	trig := stateTrigger(wn, backend)
+	require.NoError(t, trig.state.restore(context.Background(), []int32{0}))
+
+	feed(t, trig, 0, 1, "dev-A", 1)
+	require.NoError(t, trig.state.flush(context.Background()))
+
+	kafkastream.UnregisterWindowStore(wn + ":dev-A")
+	trig.state.forget(wn + ":dev-A")
+	require.NoError(t, trig.state.flush(context.Background()))
+
+	snap, err := backend.Load(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, snap.States)
+	assert.Equal(t, int64(1), snap.Offset)
+}
+
+func TestState_FailedReleaseRewindsToFirstUnsavedRecord(t *testing.T) {
+	wn := "st-rewind"
+	clearWindow(wn+":dev-A", wn+":dev-B")
+	backend := newMemBackend()
+	trig := stateTrigger(wn, backend)
+	require.NoError(t, trig.state.restore(context.Background(), []int32{0, 1, 2}))
+
+	feed(t, trig, 0, 40, "dev-A", 1)
+	require.NoError(t, trig.state.flush(context.Background())) // checkpoint 40
+	feed(t, trig, 0, 41, "dev-A", 1)
+	feed(t, trig, 1, 7, "dev-B", 1) // never flushed
+
+	backend.failSave = errors.New("broker down")
+	rewind, err := trig.state.release(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, map[int32]int64{0: 41, 1: 7}, rewind, "partition 2 applied nothing and needs no rewind")
+}
+
+func TestState_RestoreFailureAbortsSetup(t *testing.T) {
+	trig := stateTrigger("st-restore-fail", failingLoadBackend{newMemBackend()})
+	err := trig.state.restore(context.Background(), []int32{3})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "partition 3")
+}
+
+type failingLoadBackend struct{ *memBackend }
+
+func (failingLoadBackend) Load(context.Context, int32) (state.Snapshot, error) {
+	return state.Snapshot{}, errors.New("unreachable")
+}
+
+// ─── naming ──────────────────────────────────────────────────────────────────
+
+func TestChangelogTopicFor(t *testing.T) {
+	s := &Settings{ConsumerGroup: "agg cg", WindowName: "temp/5s"}
+	assert.Equal(t, "agg_cg-temp_5s-changelog", changelogTopicFor(s))
+	s.ChangelogTopic = "custom"
+	assert.Equal(t, "custom", changelogTopicFor(s))
+}
+
+func TestStateDirFor_SeparatesTriggers(t *testing.T) {
+	a := stateDirFor(&Settings{StateDir: "/data", ConsumerGroup: "g", WindowName: "w1"})
+	b := stateDirFor(&Settings{StateDir: "/data", ConsumerGroup: "g", WindowName: "w2"})
+	assert.Equal(t, filepath.Join("/data", "g", "w1"), a)
+	assert.Equal(t, filepath.Join("/data", "g", "w2"), b)
+}
@@ -7,8 +7,8 @@ package aggregate
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
@@ -24,6 +25,7 @@ import (
 	kafkaconn "github.com/tibco/wi-plugins/contributions/kafka/src/app/Kafka/connector/kafka"
 
 	kafkastream "github.com/mpandav-tibco/flogo-extensions/kafkastream"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/state"
 	"github.com/mpandav-tibco/flogo-extensions/kafkastream/window"
 )
 
@@ -52,13 +54,15 @@ func init() {
 type Trigger struct {
 	settings   *Settings
 	logger     log.Logger
+	decoder    serde.Decoder // record value decoder selected by valueFormat
 	handlers   []*handler
 	client     sarama.ConsumerGroup
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
 	stopOnce   sync.Once
-	msgCounter atomic.Int64 // per-trigger; replaces the global IncrPersistCounter
+	msgCounter atomic.Int64  // per-trigger; replaces the global IncrPersistCounter
+	state      *stateManager // partition-aware state backend; nil for stateBackend=memory
 }
 
 type handler struct {
@@ -85,7 +89,11 @@ func (*Factory) New(config *trigger.Config) (trigger.Trigger, error) {
 	if err := validateSettings(s); err != nil {
 		return nil, fmt.Errorf("kafka-stream/aggregate-trigger: invalid settings: %w", err)
 	}
-	return &Trigger{settings: s}, nil
+	decoder, err := serde.NewDecoder(s.SerdeConfig())
+	if err != nil {
+		return nil, fmt.Errorf("kafka-stream/aggregate-trigger: invalid settings: %w", err)
+	}
+	return &Trigger{settings: s, decoder: decoder}, nil
 }
 
 func (t *Trigger) Metadata() *trigger.Metadata { return triggerMd }
@@ -149,6 +157,17 @@ func (t *Trigger) Initialize(ctx trigger.InitContext) error {
 		}
 	}
 
+	// Open the partition-aware state backend. Window state is restored per
+	// partition in consumerGroupHandler.Setup, once the claims are known.
+	if backend, _ := state.NormalizeBackend(t.settings.StateBackend); backend != state.BackendMemory {
+		b, err := t.openStateBackend(backend, brokers, clientCfg.CreateConsumerConfig())
+		if err != nil {
+			_ = t.client.Close()
+			return fmt.Errorf("kafka-stream/aggregate-trigger: failed to open %s state backend: %w", backend, err)
+		}
+		t.state = newStateManager(b, t.settings, t.logger)
+	}
+
 	// Restore persisted state (if configured).
 	if t.settings.PersistPath != "" {
 		// Safety reminder: each trigger instance must use a unique PersistPath.
@@ -186,6 +205,11 @@ func (t *Trigger) Start() error {
 		go t.idleSweepLoop()
 	}
 
+	if t.state != nil {
+		t.wg.Add(1)
+		go t.stateFlushLoop()
+	}
+
 	// Drain the Sarama consumer-group errors channel. Without a reader the
 	// channel fills (default buffer: 256) under sustained broker errors and
 	// then blocks the Sarama broker reader goroutine, stalling consumption.
@@ -212,7 +236,20 @@ func (t *Trigger) Stop() error {
 		}
 	}
 
+	// The session's Cleanup has already flushed and released its partitions;
+	// this covers a Stop before any session was established.
+	if t.state != nil {
+		if err := t.state.flush(context.Background()); err != nil {
+			t.logger.Warnf("kafka-stream/aggregate-trigger: state flush on stop failed: %v", err)
+		}
+	}
+
 	t.stopOnce.Do(func() {
+		if t.state != nil {
+			if err := t.state.close(); err != nil {
+				t.logger.Warnf("kafka-stream/aggregate-trigger: state backend close error: %v", err)
+			}
+		}
 		if err := t.client.Close(); err != nil {
 			t.logger.Warnf("kafka-stream/aggregate-trigger: consumer group close error: %v", err)
 		}
@@ -259,6 +296,9 @@ func (t *Trigger) idleSweepLoop() {
 		case <-ticker.C:
 			results := kafkastream.SweepIdleFor(t.settings.WindowName)
 			for _, r := range results {
+				if t.state != nil {
+					t.state.forget(r.WindowName)
+				}
 				t.logger.Infof("kafka-stream/aggregate-trigger: window %q closed: %s=%.4f count=%d droppedCount=%d lateCount=%d key=%q (idle sweep)",
 					r.WindowName, t.settings.Function, r.Value, r.Count, r.DroppedCount, r.LateEventCount, r.Key)
 				out := &Output{
@@ -347,6 +387,9 @@ func (t *Trigger) processPayload(
 			return nil, "", fmt.Errorf("failed to create keyed window %q: %w", windowName, err)
 		}
 	}
+	if t.state != nil {
+		t.state.touch(kafkaPartition, windowName)
+	}
 
 	// ── Idle-timeout: emit partial result before adding the new event ─────────
 	if idleResult, idleClosed := store.CheckIdle(); idleClosed {
@@ -451,6 +494,15 @@ func (t *Trigger) handleMessage(session sarama.ConsumerGroupSession, msg *sarama
 			msg.Topic, msg.Partition, msg.Offset, string(msg.Key), len(msg.Value))
 	}
 
+	// A record at or below the partition's restored checkpoint is already part
+	// of the window state — applying it again would count it twice.
+	if t.state != nil && t.state.skip(msg.Partition, msg.Offset) {
+		t.logger.Debugf("kafka-stream/aggregate-trigger: offset=%d partition=%d already in restored state — skipping",
+			msg.Offset, msg.Partition)
+		session.MarkMessage(msg, "")
+		return
+	}
+
 	// Build context: extract OTel trace propagation headers from the Kafka message (OOTB pattern).
 	eventId := fmt.Sprintf("%s#%d#%d", msg.Topic, msg.Partition, msg.Offset)
 	ctx := context.Background()
@@ -464,19 +516,40 @@ func (t *Trigger) handleMessage(session sarama.ConsumerGroupSession, msg *sarama
 		}
 	}
 
-	var payload map[string]interface{}
-	if err := json.Unmarshal(msg.Value, &payload); err != nil {
-		// Hard decode failure — this is a poison-pill message (malformed JSON that
+	payload, decodeErr := serde.DecodeBlocking(session.Context(), t.decoder, msg.Value, func(err error, wait time.Duration) {
+		t.logger.Warnf("kafka-stream/aggregate-trigger: schema registry unavailable offset=%d partition=%d — retrying in %s: %v",
+			msg.Offset, msg.Partition, wait, err)
+	})
+	if decodeErr != nil {
+		if serde.IsTransient(decodeErr) {
+			// The session ended while the registry was still down. The record
+			// itself may be valid, so leave the offset unmarked for redelivery.
+			t.logger.Warnf("kafka-stream/aggregate-trigger: session ended while schema registry unavailable offset=%d partition=%d — offset NOT marked",
+				msg.Offset, msg.Partition)
+			return
+		}
+		// Hard decode failure — this is a poison-pill message (a malformed value that
 		// can never be successfully processed). We always mark the offset regardless
 		// of commitOnSuccess so the consumer does not get stuck retrying a message
 		// it can never parse. Route via a DLQ or schema-enforcement upstream.
-		t.logger.Errorf("kafka-stream/aggregate-trigger: cannot decode JSON offset=%d partition=%d — skipping (poison-pill): %v",
-			msg.Offset, msg.Partition, err)
+		t.logger.Errorf("kafka-stream/aggregate-trigger: cannot decode %s value offset=%d partition=%d — skipping (poison-pill): %v",
+			t.settings.SerdeConfig().NormalizedFormat(), msg.Offset, msg.Partition, decodeErr)
 		session.MarkMessage(msg, "")
 		return
 	}
 
-	out, eventType, err := t.processPayload(ctx, payload, msg.Topic, msg.Partition, msg.Offset)
+	var (
+		out       *Output
+		eventType string
+		err       error
+	)
+	if t.state != nil {
+		t.state.apply(msg.Partition, msg.Offset, func() {
+			out, eventType, err = t.processPayload(ctx, payload, msg.Topic, msg.Partition, msg.Offset)
+		})
+	} else {
+		out, eventType, err = t.processPayload(ctx, payload, msg.Topic, msg.Partition, msg.Offset)
+	}
 	if err != nil {
 		if t.settings.OnSchemaError == "retry" {
 			// Do not mark the offset — the message will be redelivered after a
@@ -565,6 +638,52 @@ func (t *Trigger) maybePersist() {
 	}
 }
 
+// openStateBackend creates the state.Backend selected by stateBackend.
+func (t *Trigger) openStateBackend(backend string, brokers []string, cfg *sarama.Config) (state.Backend, error) {
+	switch backend {
+	case state.BackendFile:
+		dir := stateDirFor(t.settings)
+		t.logger.Infof("kafka-stream/aggregate-trigger: window state stored per partition under %q", dir)
+		return state.NewFileBackend(dir)
+	case state.BackendKafka:
+		topic := changelogTopicFor(t.settings)
+		t.logger.Infof("kafka-stream/aggregate-trigger: window state stored in changelog topic %q", topic)
+		return state.NewChangelogBackend(state.ChangelogConfig{
+			Brokers:           brokers,
+			Sarama:            cfg,
+			Topic:             topic,
+			SourceTopic:       t.settings.Topic,
+			ReplicationFactor: int16(t.settings.ChangelogReplicationFactor),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported stateBackend %q", backend)
+	}
+}
+
+// stateFlushLoop periodically saves changed window state to the state backend.
+// Offsets are committed only at the end of a session, after Cleanup flushes,
+// so a crash replays at most the records since the last flush; those at or
+// below the flushed checkpoint are skipped on redelivery.
+func (t *Trigger) stateFlushLoop() {
+	defer t.wg.Done()
+	interval := t.settings.StateFlushIntervalMs
+	if interval <= 0 {
+		interval = defaultStateFlushIntervalMs
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.state.flush(t.ctx); err != nil {
+				t.logger.Warnf("kafka-stream/aggregate-trigger: periodic %v (will retry)", err)
+			}
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Window helpers
 // ---------------------------------------------------------------------------
@@ -629,6 +748,9 @@ func extractEventTime(msg map[string]interface{}, fieldName string) time.Time {
 // ---------------------------------------------------------------------------
 
 func validateSettings(s *Settings) error {
+	if err := s.SerdeConfig().Validate(); err != nil {
+		return err
+	}
 	if strings.TrimSpace(s.Topic) == "" {
 		return fmt.Errorf("topic must not be empty")
 	}
@@ -672,6 +794,29 @@ func validateSettings(s *Settings) error {
 	if s.IdleTimeoutMs < 0 {
 		return fmt.Errorf("idleTimeoutMs must be >= 0, got %d", s.IdleTimeoutMs)
 	}
+	backend, err := state.NormalizeBackend(s.StateBackend)
+	if err != nil {
+		return err
+	}
+	if backend != state.BackendMemory {
+		// An unkeyed window aggregates every partition, so it cannot be split
+		// between the consumers that own those partitions.
+		if strings.TrimSpace(s.KeyField) == "" {
+			return fmt.Errorf("stateBackend %q requires keyField", backend)
+		}
+		if s.PersistPath != "" {
+			return fmt.Errorf("persistPath cannot be combined with stateBackend %q", backend)
+		}
+	}
+	if backend == state.BackendFile && strings.TrimSpace(s.StateDir) == "" {
+		return fmt.Errorf("stateDir must not be empty when stateBackend is %q", backend)
+	}
+	if s.ChangelogReplicationFactor < 0 || s.ChangelogReplicationFactor > math.MaxInt16 {
+		return fmt.Errorf("changelogReplicationFactor must be between 0 and %d, got %d", math.MaxInt16, s.ChangelogReplicationFactor)
+	}
+	if s.StateFlushIntervalMs < 0 {
+		return fmt.Errorf("stateFlushIntervalMs must be >= 0, got %d", s.StateFlushIntervalMs)
+	}
 	return nil
 }
 
@@ -687,6 +832,15 @@ type consumerGroupHandler struct{ t *Trigger }
 func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
 	h.t.logger.Debugf("kafka-stream/aggregate-trigger: rebalance setup — topic=%q claims=%v",
 		h.t.settings.Topic, session.Claims()[h.t.settings.Topic])
+	if h.t.state != nil {
+		// Restore only the windows of the partitions this member now owns. A
+		// failed restore aborts the session: consuming without the state
+		// would emit wrong aggregates.
+		if err := h.t.state.restore(session.Context(), session.Claims()[h.t.settings.Topic]); err != nil {
+			return fmt.Errorf("kafka-stream/aggregate-trigger: rebalance %w", err)
+		}
+		return nil
+	}
 	if h.t.settings.PersistPath != "" {
 		if err := kafkastream.RestoreStateFrom(h.t.settings.PersistPath); err != nil {
 			h.t.logger.Warnf("kafka-stream/aggregate-trigger: rebalance restore from %q failed (ignored): %v",
@@ -703,6 +857,22 @@ func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error
 // can restore it in its subsequent Setup call.
 func (h *consumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
 	h.t.logger.Debugf("kafka-stream/aggregate-trigger: rebalance cleanup — topic=%q", h.t.settings.Topic)
+	if h.t.state != nil {
+		// Offsets marked in this session are committed after Cleanup returns,
+		// so the state must reach the backend first.
+		rewind, err := h.t.state.release(context.Background())
+		if err != nil {
+			h.t.logger.Errorf("kafka-stream/aggregate-trigger: rebalance %v", err)
+		}
+		for partition, offset := range rewind {
+			// Roll the committed offset back to the first record the backend
+			// does not hold, so the next owner applies it again.
+			h.t.logger.Warnf("kafka-stream/aggregate-trigger: partition %d — rewinding committed offset to %d after failed state flush",
+				partition, offset)
+			session.ResetOffset(h.t.settings.Topic, partition, offset, "")
+		}
+		return nil
+	}
 	if h.t.settings.PersistPath != "" {
 		if err := kafkastream.SaveStateTo(h.t.settings.PersistPath); err != nil {
 			h.t.logger.Warnf("kafka-stream/aggregate-trigger: rebalance save to %q failed: %v",
@@ -52,6 +52,15 @@ func TestValidateSettings_Valid(t *testing.T) {
 	require.NoError(t, validateSettings(s))
 }
 
+func TestValidateSettings_ValueFormat(t *testing.T) {
+	s := &Settings{Topic: "t", ConsumerGroup: "g", WindowName: "w", WindowType: "TumblingCount", WindowSize: 5, Function: "sum", ValueField: "v", ValueFormat: "avro"}
+	assert.ErrorContains(t, validateSettings(s), "schemaRegistryUrl")
+	s.SchemaRegistryURL = "http://localhost:8081"
+	require.NoError(t, validateSettings(s))
+	s.ValueFormat = "xml"
+	assert.ErrorContains(t, validateSettings(s), "valueFormat")
+}
+
 func TestValidateSettings_MissingTopic(t *testing.T) {
 	s := &Settings{ConsumerGroup: "g", WindowName: "w", WindowType: "TumblingCount", WindowSize: 5, Function: "sum", ValueField: "v"}
 	assert.ErrorContains(t, validateSettings(s), "topic")
@@ -102,6 +111,29 @@ func TestValidateSettings_NegativeMaxBufferSize(t *testing.T) {
 	assert.ErrorContains(t, validateSettings(s), "maxBufferSize")
 }
 
+func TestValidateSettings_StateBackend(t *testing.T) {
+	s := &Settings{Topic: "t", ConsumerGroup: "g", WindowName: "w", WindowType: "TumblingCount", WindowSize: 5, Function: "sum", ValueField: "v", StateBackend: "kafka"}
+	assert.ErrorContains(t, validateSettings(s), "requires keyField")
+	s.KeyField = "device"
+	require.NoError(t, validateSettings(s))
+	s.PersistPath = "/tmp/state.gob"
+	assert.ErrorContains(t, validateSettings(s), "persistPath")
+	s.PersistPath = ""
+	s.StateBackend = "file"
+	assert.ErrorContains(t, validateSettings(s), "stateDir")
+	s.StateDir = "/var/lib/agg"
+	require.NoError(t, validateSettings(s))
+	s.StateBackend = "redis"
+	assert.ErrorContains(t, validateSettings(s), "stateBackend")
+	s.StateBackend = "Memory"
+	require.NoError(t, validateSettings(s))
+	s.StateFlushIntervalMs = -1
+	assert.ErrorContains(t, validateSettings(s), "stateFlushIntervalMs")
+	s.StateFlushIntervalMs = 0
+	s.ChangelogReplicationFactor = 40000
+	assert.ErrorContains(t, validateSettings(s), "changelogReplicationFactor")
+}
+
 // ─── extractEventTime ────────────────────────────────────────────────────────
 
 func TestExtractEventTime_UnixMs_Int64(t *testing.T) {
@@ -3,6 +3,7 @@ package filter
 import (
 	"encoding/json"
 
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/support/connection"
 )
@@ -38,6 +39,19 @@ type Settings struct {
 	// Accepted values: "newest" (default), "oldest".
 	InitialOffset string `md:"initialOffset"`
 
+	// ── Value decoding ───────────────────────────────────────────────────────
+	// ValueFormat selects how record values are decoded: "json" (default),
+	// "avro", "protobuf" or "json-schema" (Confluent Schema Registry wire
+	// format), or "string" (raw value emitted as {"value": "..."}).
+	ValueFormat string `md:"valueFormat"`
+	// SchemaRegistryURL is the Schema Registry base URL. Required for avro,
+	// protobuf and json-schema; schemas are fetched by ID and cached.
+	SchemaRegistryURL string `md:"schemaRegistryUrl"`
+	// SchemaRegistryUsername / SchemaRegistryPassword enable basic auth
+	// (e.g. a Confluent Cloud API key and secret).
+	SchemaRegistryUsername string `md:"schemaRegistryUsername"`
+	SchemaRegistryPassword string `md:"schemaRegistryPassword"`
+
 	// ── Filter defaults (overridable per-handler) ─────────────────────────────
 	Operator             string `md:"operator"`
 	PredicateMode        string `md:"predicateMode"`
@@ -78,6 +92,16 @@ type Settings struct {
 	HandlerTimeoutMs int64 `md:"handlerTimeoutMs"`
 }
 
+// SerdeConfig returns the record value decoding configuration.
+func (s *Settings) SerdeConfig() serde.Config {
+	return serde.Config{
+		Format:      s.ValueFormat,
+		RegistryURL: s.SchemaRegistryURL,
+		Username:    s.SchemaRegistryUsername,
+		Password:    s.SchemaRegistryPassword,
+	}
+}
+
 // HandlerSettings define the filter predicate for a specific handler (flow).
 // Any non-empty field overrides the corresponding trigger-level Setting default.
 type HandlerSettings struct {
@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,6 +19,7 @@ import (
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
@@ -41,6 +41,7 @@ func init() {
 type Trigger struct {
 	settings *Settings
 	logger   log.Logger
+	decoder  serde.Decoder // record value decoder selected by valueFormat
 	handlers []*handler
 	client   sarama.ConsumerGroup
 	ctx      context.Context
@@ -84,7 +85,11 @@ func (*Factory) New(config *trigger.Config) (trigger.Trigger, error) {
 	if err := validateSettings(s); err != nil {
 		return nil, fmt.Errorf("kafka-stream/filter-trigger: invalid settings: %w", err)
 	}
-	return &Trigger{settings: s}, nil
+	decoder, err := serde.NewDecoder(s.SerdeConfig())
+	if err != nil {
+		return nil, fmt.Errorf("kafka-stream/filter-trigger: invalid settings: %w", err)
+	}
+	return &Trigger{settings: s, decoder: decoder}, nil
 }
 
 // Metadata returns the trigger metadata.
@@ -314,14 +319,24 @@ func (t *Trigger) handleMessage(session sarama.ConsumerGroupSession, msg *sarama
 		}
 	}
 
-	var payload map[string]interface{}
-	if err := json.Unmarshal(msg.Value, &payload); err != nil {
-		// Hard decode failure — this is a poison-pill message (malformed JSON that
+	payload, decodeErr := serde.DecodeBlocking(session.Context(), t.decoder, msg.Value, func(err error, wait time.Duration) {
+		t.logger.Warnf("kafka-stream/filter-trigger: schema registry unavailable offset=%d partition=%d — retrying in %s: %v",
+			msg.Offset, msg.Partition, wait, err)
+	})
+	if decodeErr != nil {
+		if serde.IsTransient(decodeErr) {
+			// The session ended while the registry was still down. The record
+			// itself may be valid, so leave the offset unmarked for redelivery.
+			t.logger.Warnf("kafka-stream/filter-trigger: session ended while schema registry unavailable offset=%d partition=%d — offset NOT marked",
+				msg.Offset, msg.Partition)
+			return
+		}
+		// Hard decode failure — this is a poison-pill message (a malformed value that
 		// can never be successfully processed). We always mark the offset regardless
 		// of commitOnSuccess so the consumer does not get stuck retrying a message
 		// it can never parse. Route via a DLQ or schema-enforcement upstream.
-		t.logger.Errorf("kafka-stream/filter-trigger: cannot decode JSON offset=%d partition=%d — skipping (poison-pill): %v",
-			msg.Offset, msg.Partition, err)
+		t.logger.Errorf("kafka-stream/filter-trigger: cannot decode %s value offset=%d partition=%d — skipping (poison-pill): %v",
+			t.settings.SerdeConfig().NormalizedFormat(), msg.Offset, msg.Partition, decodeErr)
 		session.MarkMessage(msg, "")
 		return
 	}
@@ -764,6 +779,9 @@ func (ds *dedupStore) evictOldestLocked() {
 // ---------------------------------------------------------------------------
 
 func validateSettings(s *Settings) error {
+	if err := s.SerdeConfig().Validate(); err != nil {
+		return err
+	}
 	if strings.TrimSpace(s.Topic) == "" {
 		return fmt.Errorf("topic must not be empty")
 	}
@@ -1,11 +1,17 @@
 package filter
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 	"time"
 
+	"github.com/IBM/sarama"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
+	"github.com/project-flogo/core/support/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -37,6 +43,15 @@ func TestValidateSettings_Valid(t *testing.T) {
 	require.NoError(t, validateSettings(&Settings{Topic: "t", ConsumerGroup: "g"}))
 }
 
+func TestValidateSettings_ValueFormat(t *testing.T) {
+	s := &Settings{Topic: "t", ConsumerGroup: "g", ValueFormat: "avro"}
+	assert.ErrorContains(t, validateSettings(s), "schemaRegistryUrl")
+	s.SchemaRegistryURL = "http://localhost:8081"
+	require.NoError(t, validateSettings(s))
+	s.ValueFormat = "xml"
+	assert.ErrorContains(t, validateSettings(s), "valueFormat")
+}
+
 func TestValidateSettings_MissingTopic(t *testing.T) {
 	err := validateSettings(&Settings{ConsumerGroup: "g"})
 	require.Error(t, err)
@@ -750,3 +765,56 @@ func TestCheckRateLimit_DropMode_Allow(t *testing.T) {
 	// Just verify the trigger struct is well-formed.
 	assert.Nil(t, trig.limiter)
 }
+
+// ─── handleMessage: schema registry failures ─────────────────────────────────
+
+// markSession records MarkMessage calls; every other session method is unused.
+type markSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *markSession) Context() context.Context { return s.ctx }
+
+func (s *markSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+// handleWithRegistryStatus runs one json-schema record through handleMessage
+// against a registry that answers every request with status, ending the
+// session shortly after so a blocking decode returns.
+func handleWithRegistryStatus(t *testing.T, status int) *markSession {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"error_code":%d,"message":"%s"}`, status, http.StatusText(status))))
+	}))
+	t.Cleanup(srv.Close)
+
+	s := &Settings{Topic: "t", ConsumerGroup: "g", ValueFormat: serde.FormatJSONSchema, SchemaRegistryURL: srv.URL}
+	decoder, err := serde.NewDecoder(s.SerdeConfig())
+	require.NoError(t, err)
+	trig := &Trigger{settings: s, decoder: decoder, logger: log.RootLogger()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	t.Cleanup(cancel)
+	session := &markSession{ctx: ctx}
+	trig.handleMessage(session, &sarama.ConsumerMessage{
+		Topic: "t", Partition: 0, Offset: 7,
+		Value: []byte{0, 0, 0, 0, 3, '{', '}'},
+	})
+	return session
+}
+
+func TestHandleMessage_RegistryAuthFailureNotMarked(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		session := handleWithRegistryStatus(t, status)
+		assert.Empty(t, session.marked, "HTTP %d must leave the offset unmarked for redelivery", status)
+	}
+}
+
+func TestHandleMessage_UnknownSchemaMarked(t *testing.T) {
+	session := handleWithRegistryStatus(t, http.StatusNotFound)
+	assert.Equal(t, []int64{7}, session.marked, "an unknown schema ID is a poison pill")
+}
@@ -55,6 +55,13 @@ var joinHandler = function (e) {
             return null;
         };
         n.validate = function (e, t) {
+            if (e === "schemaRegistryUrl" || e === "schemaRegistryUsername" || e === "schemaRegistryPassword") {
+                // Registry fields only apply to Schema Registry wire-format values.
+                var valueFormat = t.getField("valueFormat");
+                var format = valueFormat && valueFormat.value ? valueFormat.value : "json";
+                return wi_contrib_1.ValidationResult.newValidationResult()
+                    .setVisible(format === "avro" || format === "protobuf" || format === "json-schema");
+            }
             return null;
         };
         n.action = function (e, t) {
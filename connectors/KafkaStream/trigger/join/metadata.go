@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/support/connection"
 )
@@ -46,6 +47,19 @@ type Settings struct {
 	// exists for the consumer group. "newest" (default) | "oldest".
 	InitialOffset string `md:"initialOffset"`
 
+	// ── Value decoding ───────────────────────────────────────────────────────
+	// ValueFormat selects how record values are decoded: "json" (default),
+	// "avro", "protobuf" or "json-schema" (Confluent Schema Registry wire
+	// format), or "string" (raw value emitted as {"value": "..."}).
+	ValueFormat string `md:"valueFormat"`
+	// SchemaRegistryURL is the Schema Registry base URL. Required for avro,
+	// protobuf and json-schema; schemas are fetched by ID and cached.
+	SchemaRegistryURL string `md:"schemaRegistryUrl"`
+	// SchemaRegistryUsername / SchemaRegistryPassword enable basic auth
+	// (e.g. a Confluent Cloud API key and secret).
+	SchemaRegistryUsername string `md:"schemaRegistryUsername"`
+	SchemaRegistryPassword string `md:"schemaRegistryPassword"`
+
 	// ── Join configuration ────────────────────────────────────────────────────
 	// JoinKeyField is the message field whose value is used to correlate messages
 	// across topics (e.g. "order_id", "device_id").
@@ -102,6 +116,16 @@ type Settings struct {
 	MaxKeys int64 `md:"maxKeys"`
 }
 
+// SerdeConfig returns the record value decoding configuration.
+func (s *Settings) SerdeConfig() serde.Config {
+	return serde.Config{
+		Format:      s.ValueFormat,
+		RegistryURL: s.SchemaRegistryURL,
+		Username:    s.SchemaRegistryUsername,
+		Password:    s.SchemaRegistryPassword,
+	}
+}
+
 // TopicList parses and returns the trimmed, non-empty topic names from Topics.
 func (s *Settings) TopicList() []string {
 	var out []string
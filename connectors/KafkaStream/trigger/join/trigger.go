@@ -11,13 +11,13 @@ package join
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
@@ -56,6 +56,7 @@ type handler struct {
 type Trigger struct {
 	settings *Settings
 	logger   log.Logger
+	decoder  serde.Decoder // record value decoder selected by valueFormat
 	handlers []*handler
 	topics   []string
 	clients  []sarama.ConsumerGroup // one ConsumerGroup client per topic
@@ -85,7 +86,11 @@ func (*Factory) New(config *trigger.Config) (trigger.Trigger, error) {
 	if err := validateSettings(s); err != nil {
 		return nil, fmt.Errorf("kafka-stream/join-trigger: invalid settings: %w", err)
 	}
-	return &Trigger{settings: s}, nil
+	decoder, err := serde.NewDecoder(s.SerdeConfig())
+	if err != nil {
+		return nil, fmt.Errorf("kafka-stream/join-trigger: invalid settings: %w", err)
+	}
+	return &Trigger{settings: s, decoder: decoder}, nil
 }
 
 func (t *Trigger) Metadata() *trigger.Metadata { return triggerMd }
@@ -384,11 +389,21 @@ func (t *Trigger) handleMessage(session sarama.ConsumerGroupSession, msg *sarama
 		}
 	}
 
-	var payload map[string]interface{}
-	if err := json.Unmarshal(msg.Value, &payload); err != nil {
+	payload, decodeErr := serde.DecodeBlocking(session.Context(), t.decoder, msg.Value, func(err error, wait time.Duration) {
+		t.logger.Warnf("kafka-stream/join-trigger: schema registry unavailable topic=%q offset=%d partition=%d — retrying in %s: %v",
+			topic, msg.Offset, msg.Partition, wait, err)
+	})
+	if decodeErr != nil {
+		if serde.IsTransient(decodeErr) {
+			// The session ended while the registry was still down. The record
+			// itself may be valid, so leave the offset unmarked for redelivery.
+			t.logger.Warnf("kafka-stream/join-trigger: session ended while schema registry unavailable topic=%q offset=%d partition=%d — offset NOT marked",
+				topic, msg.Offset, msg.Partition)
+			return
+		}
 		// Poison-pill — mark offset so the consumer does not stall.
-		t.logger.Errorf("kafka-stream/join-trigger: cannot decode JSON topic=%q partition=%d offset=%d — skipping (poison-pill): %v",
-			topic, msg.Partition, msg.Offset, err)
+		t.logger.Errorf("kafka-stream/join-trigger: cannot decode %s value topic=%q partition=%d offset=%d — skipping (poison-pill): %v",
+			t.settings.SerdeConfig().NormalizedFormat(), topic, msg.Partition, msg.Offset, decodeErr)
 		session.MarkMessage(msg, "")
 		return
 	}
@@ -484,6 +499,9 @@ func (t *Trigger) missingTopics(contributions map[string]map[string]interface{})
 // ---------------------------------------------------------------------------
 
 func validateSettings(s *Settings) error {
+	if err := s.SerdeConfig().Validate(); err != nil {
+		return err
+	}
 	if strings.TrimSpace(s.Topics) == "" {
 		return fmt.Errorf("topics must not be empty")
 	}
@@ -36,6 +36,15 @@ func TestValidateSettings_Valid(t *testing.T) {
 	require.NoError(t, validateSettings(s))
 }
 
+func TestValidateSettings_ValueFormat(t *testing.T) {
+	s := &Settings{Topics: "orders,payments", ConsumerGroup: "test-cg", JoinKeyField: "order_id", JoinWindowMs: 5000, ValueFormat: "avro"}
+	assert.ErrorContains(t, validateSettings(s), "schemaRegistryUrl")
+	s.SchemaRegistryURL = "http://localhost:8081"
+	require.NoError(t, validateSettings(s))
+	s.ValueFormat = "xml"
+	assert.ErrorContains(t, validateSettings(s), "valueFormat")
+}
+
 func TestValidateSettings_ThreeTopics(t *testing.T) {
 	s := &Settings{
 		Topics:        "a,b,c",
@@ -8,6 +8,7 @@ package split
 import (
 	"encoding/json"
 
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/support/connection"
 )
@@ -64,6 +65,19 @@ type Settings struct {
 	// exists for the consumer group. "newest" (default) | "oldest".
 	InitialOffset string `md:"initialOffset"`
 
+	// ── Value decoding ───────────────────────────────────────────────────────
+	// ValueFormat selects how record values are decoded: "json" (default),
+	// "avro", "protobuf" or "json-schema" (Confluent Schema Registry wire
+	// format), or "string" (raw value emitted as {"value": "..."}).
+	ValueFormat string `md:"valueFormat"`
+	// SchemaRegistryURL is the Schema Registry base URL. Required for avro,
+	// protobuf and json-schema; schemas are fetched by ID and cached.
+	SchemaRegistryURL string `md:"schemaRegistryUrl"`
+	// SchemaRegistryUsername / SchemaRegistryPassword enable basic auth
+	// (e.g. a Confluent Cloud API key and secret).
+	SchemaRegistryUsername string `md:"schemaRegistryUsername"`
+	SchemaRegistryPassword string `md:"schemaRegistryPassword"`
+
 	// ── Routing ───────────────────────────────────────────────────────────────
 	// RoutingMode controls whether the trigger routes to the first matching
 	// handler ("first-match", default) or to all matching handlers ("all-match").
@@ -95,6 +109,16 @@ type Settings struct {
 	MessageTimeoutMs int64 `md:"messageTimeoutMs"`
 }
 
+// SerdeConfig returns the record value decoding configuration.
+func (s *Settings) SerdeConfig() serde.Config {
+	return serde.Config{
+		Format:      s.ValueFormat,
+		RegistryURL: s.SchemaRegistryURL,
+		Username:    s.SchemaRegistryUsername,
+		Password:    s.SchemaRegistryPassword,
+	}
+}
+
 // HandlerSettings define the routing predicate for a specific branch handler (flow).
 type HandlerSettings struct {
 	// EventType controls when this handler fires.
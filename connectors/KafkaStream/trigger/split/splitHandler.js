@@ -77,6 +77,12 @@ var splitHandler = function (e) {
                 // Priority is always visible — it controls evaluation order for first-match.
                 result.setVisible(true);
             }
+            if (fieldName === "schemaRegistryUrl" || fieldName === "schemaRegistryUsername" || fieldName === "schemaRegistryPassword") {
+                // Registry fields only apply to Schema Registry wire-format values.
+                var valueFormat = context.getField("valueFormat");
+                var format = valueFormat && valueFormat.value ? valueFormat.value : "json";
+                result.setVisible(format === "avro" || format === "protobuf" || format === "json-schema");
+            }
             return result;
         };
 
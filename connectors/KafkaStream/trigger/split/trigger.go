@@ -18,7 +18,6 @@ package split
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"regexp"
 	"sort"
@@ -28,6 +27,7 @@ import (
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/mpandav-tibco/flogo-extensions/kafkastream/serde"
 	"github.com/project-flogo/core/data/coerce"
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
@@ -49,6 +49,7 @@ func init() {
 type Trigger struct {
 	settings *Settings
 	logger   log.Logger
+	decoder  serde.Decoder // record value decoder selected by valueFormat
 
 	// Pre-categorized handler slices for efficient per-message routing.
 	// matchedHandlers is sorted by HandlerSettings.Priority (ascending) in Initialize().
@@ -95,7 +96,11 @@ func (*Factory) New(config *trigger.Config) (trigger.Trigger, error) {
 	if err := validateSettings(s); err != nil {
 		return nil, fmt.Errorf("kafka-stream/split-trigger: invalid settings: %w", err)
 	}
-	return &Trigger{settings: s}, nil
+	decoder, err := serde.NewDecoder(s.SerdeConfig())
+	if err != nil {
+		return nil, fmt.Errorf("kafka-stream/split-trigger: invalid settings: %w", err)
+	}
+	return &Trigger{settings: s, decoder: decoder}, nil
 }
 
 // Metadata returns the trigger metadata.
@@ -384,13 +389,23 @@ func (t *Trigger) handleMessage(session sarama.ConsumerGroupSession, msg *sarama
 		}
 	}
 
-	// ── Decode JSON payload ───────────────────────────────────────────────────
-	var payload map[string]interface{}
-	if err := json.Unmarshal(msg.Value, &payload); err != nil {
-		// Poison-pill — malformed JSON that can never be processed.  Always mark
+	// ── Decode record value ───────────────────────────────────────────────────
+	payload, decodeErr := serde.DecodeBlocking(session.Context(), t.decoder, msg.Value, func(err error, wait time.Duration) {
+		t.logger.Warnf("kafka-stream/split-trigger: schema registry unavailable offset=%d partition=%d — retrying in %s: %v",
+			msg.Offset, msg.Partition, wait, err)
+	})
+	if decodeErr != nil {
+		if serde.IsTransient(decodeErr) {
+			// The session ended while the registry was still down. The record
+			// itself may be valid, so leave the offset unmarked for redelivery.
+			t.logger.Warnf("kafka-stream/split-trigger: session ended while schema registry unavailable offset=%d partition=%d — offset NOT marked",
+				msg.Offset, msg.Partition)
+			return
+		}
+		// Poison-pill — a malformed value that can never be processed.  Always mark
 		// the offset so the consumer does not stall on an undecodable message.
-		t.logger.Errorf("kafka-stream/split-trigger: cannot decode JSON offset=%d partition=%d — skipping (poison-pill): %v",
-			msg.Offset, msg.Partition, err)
+		t.logger.Errorf("kafka-stream/split-trigger: cannot decode %s value offset=%d partition=%d — skipping (poison-pill): %v",
+			t.settings.SerdeConfig().NormalizedFormat(), msg.Offset, msg.Partition, decodeErr)
 		session.MarkMessage(msg, "")
 		return
 	}
@@ -761,6 +776,9 @@ var supportedOps = map[string]bool{
 // ---------------------------------------------------------------------------
 
 func validateSettings(s *Settings) error {
+	if err := s.SerdeConfig().Validate(); err != nil {
+		return err
+	}
 	if strings.TrimSpace(s.Topic) == "" {
 		return fmt.Errorf("topic must not be empty")
 	}
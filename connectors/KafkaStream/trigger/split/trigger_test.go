@@ -71,6 +71,15 @@ func TestValidateSettings_Valid(t *testing.T) {
 	require.NoError(t, validateSettings(&Settings{Topic: "t", ConsumerGroup: "g"}))
 }
 
+func TestValidateSettings_ValueFormat(t *testing.T) {
+	s := &Settings{Topic: "t", ConsumerGroup: "g", ValueFormat: "avro"}
+	assert.ErrorContains(t, validateSettings(s), "schemaRegistryUrl")
+	s.SchemaRegistryURL = "http://localhost:8081"
+	require.NoError(t, validateSettings(s))
+	s.ValueFormat = "xml"
+	assert.ErrorContains(t, validateSettings(s), "valueFormat")
+}
+
 func TestValidateSettings_ValidWithRoutingModes(t *testing.T) {
 	require.NoError(t, validateSettings(&Settings{Topic: "t", ConsumerGroup: "g", RoutingMode: RoutingModeFirstMatch}))
 	require.NoError(t, validateSettings(&Settings{Topic: "t", ConsumerGroup: "g", RoutingMode: RoutingModeAllMatch}))
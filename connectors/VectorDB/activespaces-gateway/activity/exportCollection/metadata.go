@@ -0,0 +1,111 @@
+package exportCollection
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/support/connection"
+)
+
+type Settings struct {
+	Connection        connection.Manager `md:"connection,required"`
+	DefaultCollection string             `md:"defaultCollection"`
+	// BatchSize is the number of documents per scroll page. Default: 256.
+	BatchSize int `md:"batchSize"`
+	// Format is "auto" (from the file extension), "jsonl" or "parquet".
+	Format         string `md:"format"`
+	TimeoutSeconds int    `md:"timeoutSeconds"`
+}
+
+type Input struct {
+	CollectionName string                 `md:"collectionName"`
+	FilePath       string                 `md:"filePath"`
+	Filters        map[string]interface{} `md:"filters"`
+	// DistanceMetric is recorded in the manifest so importCollection can
+	// check it against the target collection.
+	DistanceMetric string `md:"distanceMetric"`
+	// Restart discards the manifest of an interrupted export and starts over.
+	Restart bool `md:"restart"`
+}
+
+func (i *Input) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"collectionName": i.CollectionName,
+		"filePath":       i.FilePath,
+		"filters":        i.Filters,
+		"distanceMetric": i.DistanceMetric,
+		"restart":        i.Restart,
+	}
+}
+
+func (i *Input) FromMap(v map[string]interface{}) error {
+	if val, ok := v["collectionName"]; ok && val != nil {
+		i.CollectionName = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["filePath"]; ok && val != nil {
+		i.FilePath = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["filters"]; ok {
+		if m, ok := val.(map[string]interface{}); ok {
+			i.Filters = m
+		}
+	}
+	if val, ok := v["distanceMetric"]; ok && val != nil {
+		i.DistanceMetric = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["restart"]; ok {
+		i.Restart, _ = val.(bool)
+	}
+	return nil
+}
+
+type Output struct {
+	Success bool `md:"success"`
+	// ExportedCount is the number of documents written by this run;
+	// DocumentCount also includes those written before a resume.
+	ExportedCount int64  `md:"exportedCount"`
+	DocumentCount int64  `md:"documentCount"`
+	Dimensions    int    `md:"dimensions"`
+	Format        string `md:"format"`
+	// Cursor is the scroll cursor of the last checkpoint when the export
+	// was interrupted.
+	Cursor       string `md:"cursor"`
+	Complete     bool   `md:"complete"`
+	Resumed      bool   `md:"resumed"`
+	ManifestPath string `md:"manifestPath"`
+	Duration     string `md:"duration"`
+	Error        string `md:"error"`
+}
+
+func (o *Output) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"success":       o.Success,
+		"exportedCount": o.ExportedCount,
+		"documentCount": o.DocumentCount,
+		"dimensions":    o.Dimensions,
+		"format":        o.Format,
+		"cursor":        o.Cursor,
+		"complete":      o.Complete,
+		"resumed":       o.Resumed,
+		"manifestPath":  o.ManifestPath,
+		"duration":      o.Duration,
+		"error":         o.Error,
+	}
+}
+
+func (o *Output) FromMap(v map[string]interface{}) error {
+	if val, ok := v["success"]; ok {
+		o.Success, _ = val.(bool)
+	}
+	if val, ok := v["documentCount"]; ok {
+		switch n := val.(type) {
+		case int64:
+			o.DocumentCount = n
+		case float64:
+			o.DocumentCount = int64(n)
+		}
+	}
+	if val, ok := v["complete"]; ok {
+		o.Complete, _ = val.(bool)
+	}
+	return nil
+}
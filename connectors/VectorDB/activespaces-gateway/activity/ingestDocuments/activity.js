@@ -12,6 +12,8 @@ var core_1 = require("@angular/core"),
     CONNECTOR_INHERITED_FIELDS = ["embeddingProvider", "embeddingAPIKey", "embeddingBaseURL"],
     // All chunking sub-fields: hidden when enableChunking=false
     CHUNKING_SUB_FIELDS = ["chunkStrategy", "chunkSize", "chunkOverlap"],
+    // Embedding cache sub-fields: shown only for the cache type that uses them
+    CACHE_SUB_FIELDS = { embeddingCacheSize: "memory", embeddingCacheDir: "disk" },
 
     IngestDocumentsActivityHandler = function (t) {
         function e(e, i) {
@@ -72,6 +74,12 @@ var core_1 = require("@angular/core"),
                     return wi_contrib_1.ValidationResult.newValidationResult().setVisible(chunkingOn);
                 }
 
+                // --- Embedding cache sub-fields: only visible for their cache type ---
+                if (CACHE_SUB_FIELDS.hasOwnProperty(fieldName)) {
+                    var cacheType = n.getContextVar(ctx, "embeddingCache");
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible(cacheType === CACHE_SUB_FIELDS[fieldName]);
+                }
+
                 return null;
             };
             n.action = function (t, e) { return null };
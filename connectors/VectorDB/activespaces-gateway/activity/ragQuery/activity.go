@@ -1,11 +1,9 @@
 package ragQuery
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"strings"
@@ -14,6 +12,7 @@ import (
 	vectordb "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo"
 	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/connector"
 	vdbembed "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/embeddings"
+	vdbsemcache "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/semcache"
 	"github.com/project-flogo/core/activity"
 	"github.com/project-flogo/core/data/metadata"
 )
@@ -102,8 +101,8 @@ func New(ctx activity.InitContext) (activity.Activity, error) {
 	if s.LLMModel == "" {
 		s.LLMModel = "llama3.1:8b"
 	}
-	if s.LLMBaseURL == "" && s.LLMProvider == "Ollama" {
-		s.LLMBaseURL = "http://localhost:11434"
+	if s.LLMBaseURL == "" {
+		s.LLMBaseURL = defaultLLMBaseURLs[s.LLMProvider]
 	}
 	if s.MaxTokens <= 0 {
 		s.MaxTokens = 1024
@@ -111,8 +110,56 @@ func New(ctx activity.InitContext) (activity.Activity, error) {
 	if s.SystemPrompt == "" {
 		s.SystemPrompt = "You are a helpful assistant. Answer the question using only the provided context. If the context does not contain enough information, say so."
 	}
-	ctx.Logger().Infof("RAGQuery initialised: connection=%s provider=%s embeddingModel=%s defaultTopK=%d llmGenerate=%v",
-		conn.GetName(), s.EmbeddingProvider, s.EmbeddingModel, s.DefaultTopK, s.EnableLLMGenerate)
+	switch s.RetrievalMode {
+	case "":
+		s.RetrievalMode = retrievalStandard
+	case retrievalStandard, retrievalMultiQuery, retrievalHyDE:
+	default:
+		return nil, fmt.Errorf("vectordb-rag: retrievalMode must be one of standard, multiQuery, hyde, got %q", s.RetrievalMode)
+	}
+	if s.QueryVariants <= 0 {
+		s.QueryVariants = defaultQueryVariants
+	}
+	if s.QueryVariants > maxQueryVariants {
+		return nil, fmt.Errorf("vectordb-rag: queryVariants must be at most %d, got %d", maxQueryVariants, s.QueryVariants)
+	}
+	if s.EnableMMR && (s.MMRLambda < 0 || s.MMRLambda > 1) {
+		return nil, fmt.Errorf("vectordb-rag: mmrLambda must be between 0.0 and 1.0, got %.4f", s.MMRLambda)
+	}
+	switch s.ContextExpansion {
+	case "":
+		s.ContextExpansion = expansionNone
+	case expansionNone, expansionNeighbors, expansionParent:
+	default:
+		return nil, fmt.Errorf("vectordb-rag: contextExpansion must be one of none, neighbors, parent, got %q", s.ContextExpansion)
+	}
+	if s.NeighborChunks <= 0 {
+		s.NeighborChunks = defaultNeighborChunks
+	}
+	if s.ContextTokenBudget < 0 {
+		return nil, fmt.Errorf("vectordb-rag: contextTokenBudget must be >= 0, got %d", s.ContextTokenBudget)
+	}
+	if s.CacheCollection == "" {
+		s.CacheCollection = vdbsemcache.DefaultCollection
+	}
+	if s.CacheSimilarityThreshold == 0 {
+		s.CacheSimilarityThreshold = vdbsemcache.DefaultThreshold
+	}
+	if s.EnableSemanticCache && (s.CacheSimilarityThreshold < 0 || s.CacheSimilarityThreshold > 1) {
+		return nil, fmt.Errorf("vectordb-rag: cacheSimilarityThreshold must be between 0.0 and 1.0, got %.4f", s.CacheSimilarityThreshold)
+	}
+	if s.CacheTTLSeconds < 0 {
+		return nil, fmt.Errorf("vectordb-rag: cacheTTLSeconds must be >= 0, got %d", s.CacheTTLSeconds)
+	}
+	usesLLM := s.EnableLLMGenerate || s.EnableQueryRewrite || s.RetrievalMode != retrievalStandard
+	if usesLLM && s.LLMProvider == "Azure OpenAI" && s.LLMBaseURL == "" {
+		return nil, fmt.Errorf("vectordb-rag: llmBaseURL is required for Azure OpenAI")
+	}
+	if s.SSEServerRef == "" {
+		s.SSEServerRef = "default"
+	}
+	ctx.Logger().Infof("RAGQuery initialised: connection=%s provider=%s embeddingModel=%s defaultTopK=%d retrievalMode=%s mmr=%v contextExpansion=%s queryRewrite=%v llmGenerate=%v llmProvider=%s streaming=%v citations=%v semanticCache=%v",
+		conn.GetName(), s.EmbeddingProvider, s.EmbeddingModel, s.DefaultTopK, s.RetrievalMode, s.EnableMMR, s.ContextExpansion, s.EnableQueryRewrite, s.EnableLLMGenerate, s.LLMProvider, s.EnableStreaming, s.EnableCitations, s.EnableSemanticCache && s.EnableLLMGenerate)
 	return &Activity{settings: s, conn: conn}, nil
 }
 
@@ -153,6 +200,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		tc.SetTag("db.vectordb.provider", "activespaces")
 		tc.SetTag("db.vectordb.collection", collectionName)
 		tc.SetTag("db.vectordb.top_k", topK)
+		tc.SetTag("ai.retrieval_mode", a.settings.RetrievalMode)
 	}
 
 	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
@@ -160,14 +208,17 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 
 	start := time.Now()
 
-	// Step 1: Embed the query text
-	l.Debugf("RAGQuery: embedding query with provider=%s model=%s", a.settings.EmbeddingProvider, a.settings.EmbeddingModel)
+	// Step 0 (optional): query rewriting and multi-query / HyDE expansion
+	plan := a.planQueries(opCtx, l, input)
+
+	// Step 1: Embed the query text (one text per search; the HyDE passage in hyde mode)
+	l.Debugf("RAGQuery: embedding %d text(s) with provider=%s model=%s", len(plan.EmbedTexts), a.settings.EmbeddingProvider, a.settings.EmbeddingModel)
 	embResult, embErr := vdbembed.CreateEmbeddings(opCtx, vdbembed.EmbeddingRequest{
 		Provider:   vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
 		APIKey:     a.settings.EmbeddingAPIKey,
 		BaseURL:    a.settings.EmbeddingBaseURL,
 		Model:      a.settings.EmbeddingModel,
-		Texts:      []string{input.QueryText},
+		Texts:      plan.EmbedTexts,
 		Dimensions: a.settings.EmbeddingDimensions,
 	})
 	if embErr != nil {
@@ -199,42 +250,37 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	queryVector := embResult.Embeddings[0]
 	l.Debugf("RAGQuery: query embedded: dims=%d tokens=%d", len(queryVector), embResult.TokensUsed)
 
-	// Step 2: Vector search (or hybrid search if configured)
-	fqv := make([]float64, len(queryVector))
-	copy(fqv, queryVector)
-
-	var searchResults []vectordb.SearchResult
-	var searchErr error
-
+	// Step 2: Vector search (or hybrid search if configured), once per query
 	if a.settings.UseHybridSearch {
-		alpha := a.settings.HybridAlpha
 		// Descriptor default is 0.5 (balanced). Users who want pure BM25 should set
 		// alpha=0.0; users who want pure dense vector should set alpha=1.0.
 		// Negative values or values >1 are rejected as invalid configuration.
-		if alpha < 0 || alpha > 1 {
+		if alpha := a.settings.HybridAlpha; alpha < 0 || alpha > 1 {
 			return false, fmt.Errorf("vectordb-rag: hybridAlpha must be between 0.0 and 1.0, got %.4f", alpha)
 		}
-		l.Debugf("RAGQuery: hybrid search collection=%s topK=%d alpha=%.2f", collectionName, topK, alpha)
-		searchResults, searchErr = a.conn.GetClient().HybridSearch(opCtx, vectordb.HybridSearchRequest{
-			CollectionName: collectionName,
-			QueryText:      input.QueryText,
-			QueryVector:    fqv,
-			TopK:           topK,
-			ScoreThreshold: a.settings.ScoreThreshold,
-			Filters:        input.Filters,
-			Alpha:          alpha,
-			// SkipPayload defaults to false (zero value) = include payload.
-		})
-	} else {
-		searchResults, searchErr = a.conn.GetClient().VectorSearch(opCtx, vectordb.SearchRequest{
-			CollectionName: collectionName,
-			QueryVector:    fqv,
-			TopK:           topK,
-			ScoreThreshold: a.settings.ScoreThreshold,
-			Filters:        input.Filters,
-			// SkipPayload defaults to false (zero value) = include payload.
-			WithVectors: false,
-		})
+	}
+
+	// With MMR every search over-fetches candidates; the diverse topK is
+	// selected after fusion.
+	searchTopK := topK
+	if a.settings.EnableMMR {
+		searchTopK = vectordb.MMRFetchK(topK, a.settings.MMRFetchK)
+	}
+
+	var searchResults []vectordb.SearchResult
+	var searchErr error
+	lists := make([][]vectordb.SearchResult, 0, len(plan.Searches))
+	for i, q := range plan.Searches {
+		if i >= len(embResult.Embeddings) {
+			break
+		}
+		l.Debugf("RAGQuery: search collection=%s topK=%d hybrid=%v query=%q", collectionName, searchTopK, a.settings.UseHybridSearch, q)
+		var results []vectordb.SearchResult
+		results, searchErr = a.search(opCtx, collectionName, q, embResult.Embeddings[i], searchTopK, input.Filters)
+		if searchErr != nil {
+			break
+		}
+		lists = append(lists, results)
 	}
 	if searchErr != nil {
 		searchMode := "vector search"
@@ -256,10 +302,43 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		return true, nil
 	}
 
+	if len(lists) == 1 {
+		searchResults = lists[0]
+	} else {
+		searchResults = fuseRRF(lists, searchTopK)
+		l.Debugf("RAGQuery: fused %d result lists with RRF", len(lists))
+	}
+	if a.settings.EnableMMR {
+		candidates := len(searchResults)
+		var mmrErr error
+		searchResults, mmrErr = vectordb.DiversifyMMR(opCtx, a.conn.GetClient(), collectionName, queryVector, searchResults, topK, a.settings.MMRLambda, false)
+		if mmrErr != nil {
+			l.Warnf("RAGQuery: MMR could not read every candidate vector: %v", mmrErr)
+		}
+		l.Debugf("RAGQuery: MMR selected %d of %d candidates lambda=%.2f", len(searchResults), candidates, a.settings.MMRLambda)
+	}
+	if a.settings.ContextExpansion != expansionNone || a.settings.ContextTokenBudget > 0 {
+		retrieved := len(searchResults)
+		var expandErr error
+		searchResults, expandErr = expandContext(opCtx, a.conn.GetClient(), collectionName, searchResults, expansionConfig{
+			Mode:         a.settings.ContextExpansion,
+			Neighbors:    a.settings.NeighborChunks,
+			TokenBudget:  a.settings.ContextTokenBudget,
+			ContentField: a.settings.ContentField,
+		})
+		if expandErr != nil {
+			l.Warnf("RAGQuery: context expansion could not read every chunk window: %v", expandErr)
+		}
+		l.Debugf("RAGQuery: context expansion=%s built %d context documents from %d chunks", a.settings.ContextExpansion, len(searchResults), retrieved)
+	}
+
 	duration := time.Since(start)
 	l.Debugf("RAGQuery: retrieved %d documents duration=%s", len(searchResults), duration)
 	if tc != nil {
 		tc.SetTag("db.vectordb.result_count", len(searchResults))
+		if plan.Rewritten != "" {
+			tc.SetTag("ai.rewritten_query", plan.Rewritten)
+		}
 	}
 
 	// Step 3: Format context string for LLM
@@ -276,28 +355,110 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 
 	// Step 4 (optional): LLM answer generation
 	answer := ""
+	var citations []interface{}
+	streamedTokens := 0
+	cacheHit := false
 	if a.settings.EnableLLMGenerate {
 		systemPrompt := a.settings.SystemPrompt
 		if input.SystemPrompt != "" {
 			systemPrompt = input.SystemPrompt
 		}
-		l.Debugf("RAGQuery: generating answer with llmProvider=%s llmModel=%s", a.settings.LLMProvider, a.settings.LLMModel)
+		// Citation markers refer to document numbers, which the plain format
+		// does not show; the prompt then uses the numbered format.
+		promptContext := formattedContext
+		if a.settings.EnableCitations {
+			systemPrompt = strings.TrimSpace(systemPrompt + "\n\n" + citationInstruction)
+			if a.settings.ContextFormat == "plain" {
+				promptContext = formatContext(searchResults, a.settings.ContentField, "numbered")
+			}
+		}
+
+		var stream *tokenStream
+		var onToken func(string)
+		if a.settings.EnableStreaming {
+			var streamErr error
+			stream, streamErr = newTokenStream(a.settings.SSEServerRef, input.StreamConnectionID, input.StreamTopic)
+			if streamErr != nil {
+				l.Warnf("RAGQuery: streaming disabled for this request: %v", streamErr)
+			} else {
+				onToken = stream.token
+			}
+		}
+
+		// Step 4a (optional): answer from the semantic cache
+		var cacheVec []float64
+		var cached *vdbsemcache.Entry
+		var namespace string
+		if a.settings.EnableSemanticCache {
+			namespace = cacheNamespace(collectionName, input.Filters, a.settings.LLMModel, systemPrompt)
+			var vecErr error
+			cacheVec, vecErr = a.cacheVector(opCtx, plan, embResult.Embeddings)
+			if vecErr != nil {
+				l.Warnf("RAGQuery: semantic cache skipped, embedding the question failed: %v", vecErr)
+			} else {
+				cached = a.lookupAnswer(opCtx, l, cacheVec, namespace)
+			}
+		}
+
 		var llmErr error
-		answer, llmErr = a.generate(opCtx, input.QueryText, formattedContext, systemPrompt)
+		if cached != nil {
+			l.Debugf("RAGQuery: semantic cache hit id=%s score=%.4f cachedQuery=%q", cached.ID, cached.Score, cached.Query)
+			cacheHit = true
+			answer = cached.Answer
+			if a.settings.EnableCitations {
+				citations = cachedCitations(cached)
+			}
+			if stream != nil {
+				stream.token(answer)
+			}
+		} else {
+			l.Debugf("RAGQuery: generating answer with llmProvider=%s llmModel=%s streaming=%v", a.settings.LLMProvider, a.settings.LLMModel, onToken != nil)
+			answer, llmErr = a.generate(opCtx, plan.Query, promptContext, systemPrompt, onToken)
+		}
 		if llmErr != nil {
 			l.Warnf("RAGQuery: LLM generation failed (%v) — returning context only", llmErr)
 			answer = fmt.Sprintf("[LLM generation failed: %s]\n\nRetrieved context:\n%s", llmErr.Error(), formattedContext)
+			if stream != nil {
+				stream.send(streamEventError, map[string]interface{}{"error": llmErr.Error()})
+			}
+		} else {
+			if a.settings.EnableCitations && !cacheHit {
+				citations = citationsToInterface(extractCitations(answer, searchResults, a.settings.ContentField))
+			}
+			if cacheVec != nil && !cacheHit && answer != "" {
+				a.storeAnswer(opCtx, l, cacheVec, plan.Query, namespace, answer, citations, searchResults)
+			}
+			if stream != nil {
+				stream.send(streamEventDone, map[string]interface{}{
+					"answer":    answer,
+					"citations": citations,
+					"sources":   streamSources(searchResults),
+				})
+			}
+		}
+		if stream != nil {
+			streamedTokens = stream.tokens
+			if stream.err != nil {
+				l.Warnf("RAGQuery: SSE streaming stopped: %v", stream.err)
+			}
 		}
+		duration = time.Since(start)
 	}
 
 	if err := ctx.SetOutputObject(&Output{
-		Success:          true,
-		Answer:           answer,
-		FormattedContext: formattedContext,
-		SourceDocuments:  sourceDocs,
-		QueryEmbedding:   qEmbOut,
-		TotalFound:       len(searchResults),
-		Duration:         duration.String(),
+		Success:              true,
+		Answer:               answer,
+		FormattedContext:     formattedContext,
+		SourceDocuments:      sourceDocs,
+		QueryEmbedding:       qEmbOut,
+		TotalFound:           len(searchResults),
+		Duration:             duration.String(),
+		Citations:            citations,
+		StreamedTokens:       streamedTokens,
+		RewrittenQuery:       plan.Rewritten,
+		QueryVariants:        queryVariantsOut(plan),
+		HypotheticalDocument: plan.Hypothetical,
+		CacheHit:             cacheHit,
 	}); err != nil {
 		l.Errorf("SetOutputObject: %v", err)
 	}
@@ -400,150 +561,48 @@ func searchResultsToInterface(results []vectordb.SearchResult) []interface{} {
 	return out
 }
 
-// ── LLM generation ──────────────────────────────────────────────────────────
-
-// generate calls the configured LLM to produce an answer grounded in context.
-func (a *Activity) generate(ctx context.Context, query, context_, systemPrompt string) (string, error) {
-	prompt := buildPrompt(systemPrompt, context_, query)
-	switch a.settings.LLMProvider {
-	case "Ollama":
-		return a.generateOllama(ctx, prompt)
-	default: // OpenAI, Azure OpenAI, Custom
-		return a.generateOpenAICompat(ctx, prompt)
+// search runs one vector or hybrid search for a query and its embedding.
+func (a *Activity) search(ctx context.Context, collection, queryText string, vector []float64, topK int, filters map[string]interface{}) ([]vectordb.SearchResult, error) {
+	fqv := make([]float64, len(vector))
+	copy(fqv, vector)
+	if a.settings.UseHybridSearch {
+		return a.conn.GetClient().HybridSearch(ctx, vectordb.HybridSearchRequest{
+			CollectionName: collection,
+			QueryText:      queryText,
+			QueryVector:    fqv,
+			TopK:           topK,
+			ScoreThreshold: a.settings.ScoreThreshold,
+			Filters:        filters,
+			Alpha:          a.settings.HybridAlpha,
+			// SkipPayload defaults to false (zero value) = include payload.
+		})
 	}
-}
-
-// buildPrompt constructs the full prompt from system prompt, context, and query.
-func buildPrompt(systemPrompt, context_, query string) string {
-	var sb strings.Builder
-	if systemPrompt != "" {
-		sb.WriteString(systemPrompt)
-		sb.WriteString("\n\n")
-	}
-	sb.WriteString("Context:\n")
-	sb.WriteString(context_)
-	sb.WriteString("\n\nQuestion: ")
-	sb.WriteString(query)
-	sb.WriteString("\n\nAnswer:")
-	return sb.String()
-}
-
-// ollamaGenerateRequest is the Ollama /api/generate request body.
-type ollamaGenerateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
-}
-
-// ollamaGenerateResponse is the non-streaming Ollama response.
-type ollamaGenerateResponse struct {
-	Response string `json:"response"`
-	Error    string `json:"error,omitempty"`
-}
-
-func (a *Activity) generateOllama(ctx context.Context, prompt string) (string, error) {
-	baseURL := strings.TrimRight(a.settings.LLMBaseURL, "/")
-	url := baseURL + "/api/generate"
-
-	reqBody, _ := json.Marshal(ollamaGenerateRequest{
-		Model:  a.settings.LLMModel,
-		Prompt: prompt,
-		Stream: false,
+	return a.conn.GetClient().VectorSearch(ctx, vectordb.SearchRequest{
+		CollectionName: collection,
+		QueryVector:    fqv,
+		TopK:           topK,
+		ScoreThreshold: a.settings.ScoreThreshold,
+		Filters:        filters,
+		// SkipPayload defaults to false (zero value) = include payload.
+		WithVectors: a.settings.EnableMMR,
 	})
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
-	if err != nil {
-		return "", fmt.Errorf("ollama: create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := ragLLMHTTPClient.Do(httpReq)
-	if err != nil {
-		return "", fmt.Errorf("ollama: http: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Limit to 10 MB to prevent unbounded memory allocation from a large response.
-	body, _ := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("ollama: status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var result ollamaGenerateResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("ollama: parse response: %w", err)
-	}
-	if result.Error != "" {
-		return "", fmt.Errorf("ollama: %s", result.Error)
-	}
-	return strings.TrimSpace(result.Response), nil
 }
 
-// openAIChatRequest is a minimal OpenAI /v1/chat/completions request body.
-type openAIChatRequest struct {
-	Model       string              `json:"model"`
-	Messages    []openAIChatMessage `json:"messages"`
-	MaxTokens   int                 `json:"max_tokens,omitempty"`
-	Temperature float64             `json:"temperature,omitempty"`
-}
-
-type openAIChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type openAIChatResponse struct {
-	Choices []struct {
-		Message openAIChatMessage `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
-}
-
-func (a *Activity) generateOpenAICompat(ctx context.Context, prompt string) (string, error) {
-	baseURL := strings.TrimRight(a.settings.LLMBaseURL, "/")
-	url := baseURL + "/v1/chat/completions"
-
-	reqBody, _ := json.Marshal(openAIChatRequest{
-		Model: a.settings.LLMModel,
-		Messages: []openAIChatMessage{
-			{Role: "user", Content: prompt},
-		},
-		MaxTokens:   a.settings.MaxTokens,
-		Temperature: a.settings.Temperature,
-	})
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
-	if err != nil {
-		return "", fmt.Errorf("openai-compat: create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if a.settings.LLMAPIKey != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+a.settings.LLMAPIKey)
-	}
-
-	resp, err := ragLLMHTTPClient.Do(httpReq)
-	if err != nil {
-		return "", fmt.Errorf("openai-compat: http: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Limit to 10 MB to prevent unbounded memory allocation from a large response.
-	body, _ := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("openai-compat: status %d: %s", resp.StatusCode, string(body))
+// queryVariantsOut returns the searched queries for the queryVariants output,
+// or nil when only the query itself was searched.
+func queryVariantsOut(plan queryPlan) []interface{} {
+	if len(plan.Searches) < 2 {
+		return nil
 	}
+	return stringsToInterface(plan.Searches)
+}
 
-	var result openAIChatResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("openai-compat: parse response: %w", err)
-	}
-	if result.Error != nil {
-		return "", fmt.Errorf("openai-compat: %s", result.Error.Message)
-	}
-	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("openai-compat: no choices in response")
+// streamSources lists the retrieved documents for the final stream event so a
+// client can resolve citation numbers: [{"index":1,"id":"..."}].
+func streamSources(results []vectordb.SearchResult) []interface{} {
+	out := make([]interface{}, len(results))
+	for i, r := range results {
+		out[i] = map[string]interface{}{"index": i + 1, "id": r.ID}
 	}
-	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+	return out
 }
@@ -0,0 +1,177 @@
+package semanticCacheLookup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/connector"
+	vdbembed "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/embeddings"
+	vdbsemcache "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/semcache"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data/metadata"
+)
+
+var activityMd = activity.ToMetadata(&Settings{}, &Input{}, &Output{})
+
+func init() { _ = activity.Register(&Activity{}, New) }
+
+type Activity struct {
+	settings *Settings
+	conn     *vectordbconnector.ActiveSpacesConnection
+}
+
+func (a *Activity) Metadata() *activity.Metadata { return activityMd }
+
+func New(ctx activity.InitContext) (activity.Activity, error) {
+	s := &Settings{}
+	if err := metadata.MapToStruct(ctx.Settings(), s, true); err != nil {
+		return nil, fmt.Errorf("vectordb-cache-lookup: %w", err)
+	}
+	if s.Connection == nil {
+		return nil, fmt.Errorf("vectordb-cache-lookup: connection is required")
+	}
+	conn, ok := s.Connection.GetConnection().(*vectordbconnector.ActiveSpacesConnection)
+	if !ok {
+		return nil, fmt.Errorf("vectordb-cache-lookup: invalid connection type, expected *ActiveSpacesConnection")
+	}
+
+	// Resolve embedding credentials: inherit from connector when opted in.
+	// Activity-level values (if set) always take precedence as an override.
+	if s.UseConnectorEmbedding {
+		connSettings := conn.GetSettings()
+		if !connSettings.EnableEmbedding {
+			ctx.Logger().Warnf("SemanticCacheLookup: useConnectorEmbedding=true but connector does not have enableEmbedding set — falling back to activity-level settings")
+		} else {
+			if s.EmbeddingProvider == "" {
+				s.EmbeddingProvider = connSettings.EmbeddingProvider
+			}
+			if s.EmbeddingAPIKey == "" {
+				s.EmbeddingAPIKey = connSettings.EmbeddingAPIKey
+			}
+			if s.EmbeddingBaseURL == "" {
+				s.EmbeddingBaseURL = connSettings.EmbeddingBaseURL
+			}
+		}
+	}
+
+	if s.EmbeddingProvider == "" {
+		s.EmbeddingProvider = string(vdbembed.ProviderOpenAI)
+	}
+	if s.EmbeddingModel == "" {
+		return nil, fmt.Errorf("vectordb-cache-lookup: embeddingModel is required")
+	}
+	if s.CacheCollection == "" {
+		s.CacheCollection = vdbsemcache.DefaultCollection
+	}
+	if s.SimilarityThreshold == 0 {
+		s.SimilarityThreshold = vdbsemcache.DefaultThreshold
+	}
+	if s.SimilarityThreshold < 0 || s.SimilarityThreshold > 1 {
+		return nil, fmt.Errorf("vectordb-cache-lookup: similarityThreshold must be between 0.0 and 1.0, got %.4f", s.SimilarityThreshold)
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 30
+	}
+	ctx.Logger().Infof("SemanticCacheLookup initialised: connection=%s provider=%s embeddingModel=%s cacheCollection=%s threshold=%.2f",
+		conn.GetName(), s.EmbeddingProvider, s.EmbeddingModel, s.CacheCollection, s.SimilarityThreshold)
+	return &Activity{settings: s, conn: conn}, nil
+}
+
+func (a *Activity) Eval(ctx activity.Context) (bool, error) {
+	l := ctx.Logger()
+	l.Debugf("SemanticCacheLookup: starting eval")
+
+	input := &Input{}
+	if err := ctx.GetInputObject(input); err != nil {
+		return false, fmt.Errorf("vectordb-cache-lookup: %w", err)
+	}
+	if input.QueryText == "" && len(input.QueryVector) == 0 {
+		return false, fmt.Errorf("vectordb-cache-lookup: queryText or queryVector is required")
+	}
+
+	// OTel trace tags
+	tc := ctx.GetTracingContext()
+	if tc != nil {
+		tc.SetTag("db.system", "vectordb")
+		tc.SetTag("db.operation", "semanticCacheLookup")
+		tc.SetTag("db.vectordb.provider", "activespaces")
+		tc.SetTag("db.vectordb.collection", a.settings.CacheCollection)
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	fail := func(err error) (bool, error) {
+		l.Errorf("SemanticCacheLookup: collection=%s error=%v", a.settings.CacheCollection, err)
+		if tc != nil {
+			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": err.Error()})
+		}
+		if err := ctx.SetOutputObject(&Output{Success: false, Error: err.Error(), Duration: time.Since(start).String()}); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
+		}
+		return true, nil
+	}
+
+	queryVector := input.QueryVector
+	if len(queryVector) == 0 {
+		embResult, embErr := vdbembed.CreateEmbeddings(opCtx, vdbembed.EmbeddingRequest{
+			Provider:   vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
+			APIKey:     a.settings.EmbeddingAPIKey,
+			BaseURL:    a.settings.EmbeddingBaseURL,
+			Model:      a.settings.EmbeddingModel,
+			Texts:      []string{input.QueryText},
+			Dimensions: a.settings.EmbeddingDimensions,
+		})
+		if embErr != nil {
+			return fail(fmt.Errorf("embedding failed: %w", embErr))
+		}
+		queryVector = embResult.Embeddings[0]
+	}
+
+	entry, err := vdbsemcache.Lookup(opCtx, a.conn.GetClient(), a.settings.CacheCollection, queryVector, vdbsemcache.LookupOptions{
+		Namespace: input.Namespace,
+		Threshold: a.settings.SimilarityThreshold,
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	qEmbOut := make([]interface{}, len(queryVector))
+	for i, f := range queryVector {
+		qEmbOut[i] = f
+	}
+	out := &Output{Success: true, QueryEmbedding: qEmbOut}
+	if entry != nil {
+		out.Hit = true
+		out.Answer = entry.Answer
+		out.CachedQuery = entry.Query
+		out.Score = entry.Score
+		out.CacheID = entry.ID
+		out.SourceIDs = make([]interface{}, len(entry.SourceIDs))
+		for i, id := range entry.SourceIDs {
+			out.SourceIDs[i] = id
+		}
+		out.Metadata = entry.Metadata
+		if !entry.CreatedAt.IsZero() {
+			out.CreatedAt = entry.CreatedAt.UTC().Format(time.RFC3339)
+		}
+		if !entry.ExpiresAt.IsZero() {
+			out.ExpiresAt = entry.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+
+	duration := time.Since(start)
+	out.Duration = duration.String()
+	l.Debugf("SemanticCacheLookup: collection=%s namespace=%q hit=%v score=%.4f duration=%s",
+		a.settings.CacheCollection, input.Namespace, out.Hit, out.Score, duration)
+	if tc != nil {
+		tc.SetTag("db.vectordb.cache_hit", out.Hit)
+	}
+	if err := ctx.SetOutputObject(out); err != nil {
+		l.Errorf("SetOutputObject: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,201 @@
+package semanticCacheStore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/connector"
+	vdbembed "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/embeddings"
+	vdbsemcache "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo/semcache"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data/metadata"
+)
+
+var activityMd = activity.ToMetadata(&Settings{}, &Input{}, &Output{})
+
+func init() { _ = activity.Register(&Activity{}, New) }
+
+type Activity struct {
+	settings *Settings
+	conn     *vectordbconnector.ActiveSpacesConnection
+}
+
+func (a *Activity) Metadata() *activity.Metadata { return activityMd }
+
+func New(ctx activity.InitContext) (activity.Activity, error) {
+	s := &Settings{}
+	if err := metadata.MapToStruct(ctx.Settings(), s, true); err != nil {
+		return nil, fmt.Errorf("vectordb-cache-store: %w", err)
+	}
+	if s.Connection == nil {
+		return nil, fmt.Errorf("vectordb-cache-store: connection is required")
+	}
+	conn, ok := s.Connection.GetConnection().(*vectordbconnector.ActiveSpacesConnection)
+	if !ok {
+		return nil, fmt.Errorf("vectordb-cache-store: invalid connection type, expected *ActiveSpacesConnection")
+	}
+
+	// Resolve embedding credentials: inherit from connector when opted in.
+	// Activity-level values (if set) always take precedence as an override.
+	if s.UseConnectorEmbedding {
+		connSettings := conn.GetSettings()
+		if !connSettings.EnableEmbedding {
+			ctx.Logger().Warnf("SemanticCacheStore: useConnectorEmbedding=true but connector does not have enableEmbedding set — falling back to activity-level settings")
+		} else {
+			if s.EmbeddingProvider == "" {
+				s.EmbeddingProvider = connSettings.EmbeddingProvider
+			}
+			if s.EmbeddingAPIKey == "" {
+				s.EmbeddingAPIKey = connSettings.EmbeddingAPIKey
+			}
+			if s.EmbeddingBaseURL == "" {
+				s.EmbeddingBaseURL = connSettings.EmbeddingBaseURL
+			}
+		}
+	}
+
+	if s.EmbeddingProvider == "" {
+		s.EmbeddingProvider = string(vdbembed.ProviderOpenAI)
+	}
+	if s.EmbeddingModel == "" {
+		return nil, fmt.Errorf("vectordb-cache-store: embeddingModel is required")
+	}
+	if s.CacheCollection == "" {
+		s.CacheCollection = vdbsemcache.DefaultCollection
+	}
+	if s.DefaultTTLSeconds < 0 {
+		return nil, fmt.Errorf("vectordb-cache-store: defaultTTLSeconds must be >= 0, got %d", s.DefaultTTLSeconds)
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 30
+	}
+	ctx.Logger().Infof("SemanticCacheStore initialised: connection=%s provider=%s embeddingModel=%s cacheCollection=%s defaultTTL=%ds",
+		conn.GetName(), s.EmbeddingProvider, s.EmbeddingModel, s.CacheCollection, s.DefaultTTLSeconds)
+	return &Activity{settings: s, conn: conn}, nil
+}
+
+func (a *Activity) Eval(ctx activity.Context) (bool, error) {
+	l := ctx.Logger()
+	l.Debugf("SemanticCacheStore: starting eval")
+
+	input := &Input{}
+	if err := ctx.GetInputObject(input); err != nil {
+		return false, fmt.Errorf("vectordb-cache-store: %w", err)
+	}
+	invalidate := stringIDs(input.InvalidateSourceIDs)
+	if input.QueryText == "" && len(invalidate) == 0 && !input.PurgeExpired {
+		return false, fmt.Errorf("vectordb-cache-store: queryText is required unless invalidateSourceIds or purgeExpired is set")
+	}
+	if input.QueryText != "" && input.Answer == "" {
+		return false, fmt.Errorf("vectordb-cache-store: answer is required")
+	}
+
+	// OTel trace tags
+	tc := ctx.GetTracingContext()
+	if tc != nil {
+		tc.SetTag("db.system", "vectordb")
+		tc.SetTag("db.operation", "semanticCacheStore")
+		tc.SetTag("db.vectordb.provider", "activespaces")
+		tc.SetTag("db.vectordb.collection", a.settings.CacheCollection)
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	out := &Output{}
+	fail := func(err error) (bool, error) {
+		l.Errorf("SemanticCacheStore: collection=%s error=%v", a.settings.CacheCollection, err)
+		if tc != nil {
+			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": err.Error()})
+		}
+		out.Success = false
+		out.Error = err.Error()
+		out.Duration = time.Since(start).String()
+		if err := ctx.SetOutputObject(out); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
+		}
+		return true, nil
+	}
+
+	client := a.conn.GetClient()
+	if len(invalidate) > 0 {
+		n, err := vdbsemcache.Invalidate(opCtx, client, a.settings.CacheCollection, invalidate)
+		if err != nil {
+			return fail(fmt.Errorf("invalidate: %w", err))
+		}
+		out.InvalidatedCount = n
+		l.Debugf("SemanticCacheStore: invalidated %d entries for %d source documents", n, len(invalidate))
+	}
+	if input.PurgeExpired {
+		n, err := vdbsemcache.PurgeExpired(opCtx, client, a.settings.CacheCollection)
+		if err != nil {
+			return fail(fmt.Errorf("purge: %w", err))
+		}
+		out.PurgedCount = n
+		l.Debugf("SemanticCacheStore: purged %d expired entries", n)
+	}
+
+	if input.QueryText != "" {
+		queryVector := input.QueryVector
+		if len(queryVector) == 0 {
+			embResult, embErr := vdbembed.CreateEmbeddings(opCtx, vdbembed.EmbeddingRequest{
+				Provider:   vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
+				APIKey:     a.settings.EmbeddingAPIKey,
+				BaseURL:    a.settings.EmbeddingBaseURL,
+				Model:      a.settings.EmbeddingModel,
+				Texts:      []string{input.QueryText},
+				Dimensions: a.settings.EmbeddingDimensions,
+			})
+			if embErr != nil {
+				return fail(fmt.Errorf("embedding failed: %w", embErr))
+			}
+			queryVector = embResult.Embeddings[0]
+		}
+
+		ttl := input.TTLSeconds
+		if ttl == 0 {
+			ttl = a.settings.DefaultTTLSeconds
+		}
+		entry, err := vdbsemcache.Store(opCtx, client, a.settings.CacheCollection, queryVector, vdbsemcache.Entry{
+			Query:     input.QueryText,
+			Answer:    input.Answer,
+			Namespace: input.Namespace,
+			SourceIDs: stringIDs(input.SourceIDs),
+			Metadata:  input.Metadata,
+		}, time.Duration(ttl)*time.Second)
+		if err != nil {
+			return fail(err)
+		}
+		out.CacheID = entry.ID
+		if !entry.ExpiresAt.IsZero() {
+			out.ExpiresAt = entry.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+
+	duration := time.Since(start)
+	l.Debugf("SemanticCacheStore: collection=%s namespace=%q id=%s invalidated=%d purged=%d duration=%s",
+		a.settings.CacheCollection, input.Namespace, out.CacheID, out.InvalidatedCount, out.PurgedCount, duration)
+	out.Success = true
+	out.Duration = duration.String()
+	if err := ctx.SetOutputObject(out); err != nil {
+		l.Errorf("SetOutputObject: %v", err)
+	}
+	return true, nil
+}
+
+// stringIDs converts the IDs of an array input to strings, skipping empty ones.
+func stringIDs(ids []interface{}) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == nil {
+			continue
+		}
+		if s := fmt.Sprintf("%v", id); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,661 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ── Filter expressions ───────────────────────────────────────────────────────
+//
+// Metadata filters are maps. Every key is a payload path or a logical
+// operator, and all keys of a map are ANDed:
+//
+//	{"category": "news"}                                    equality
+//	{"year": {"$gte": 2020, "$lt": 2025}}                   comparisons
+//	{"tags": ["a", "b"]}                                    shorthand for $in
+//	{"meta.author.team": "search"}                          nested payload path
+//	{"meta": {"author": {"team": "search"}}}                same, as an object
+//	{"$or": [{"lang": "en"}, {"lang": {"$exists": false}}]}
+//	{"$not": {"status": {"$in": ["draft", "archived"]}}}
+//	{"tags": {"$contains": "faq"}}                          array membership
+//	{"title": {"$like": "%vector%"}}                        SQL LIKE pattern
+//
+// ParseFilter validates a filter map into a FilterExpr tree. Providers
+// translate the parts they support natively and evaluate the remainder
+// client-side with FilterExpr.Match.
+
+// Logical filter operators.
+const (
+	FilterAnd = "$and"
+	FilterOr  = "$or"
+	FilterNot = "$not"
+)
+
+// Field filter operators.
+const (
+	FilterEq       = "$eq"
+	FilterNe       = "$ne"
+	FilterGt       = "$gt"
+	FilterGte      = "$gte"
+	FilterLt       = "$lt"
+	FilterLte      = "$lte"
+	FilterIn       = "$in"
+	FilterNin      = "$nin"
+	FilterExists   = "$exists"
+	FilterContains = "$contains"
+
+	// FilterLike matches strings against a SQL LIKE pattern (% and _
+	// wildcards). It is specific to the ActiveSpaces connectors, whose
+	// filters are compiled to ActiveSpaces SQL.
+	FilterLike = "$like"
+)
+
+// FilterExpr is one node of a parsed metadata filter.
+type FilterExpr struct {
+	// Op is FilterAnd, FilterOr or FilterNot for a logical node, otherwise
+	// the field operator.
+	Op string
+
+	// Field is the payload path of a field condition. Nested keys are
+	// separated by dots.
+	Field string
+
+	// Value is the operand of a field condition: a scalar, a []interface{}
+	// of scalars for $in / $nin, or a bool for $exists.
+	Value interface{}
+
+	// Children are the operands of a logical node. $not has exactly one.
+	Children []*FilterExpr
+}
+
+// IsLogical reports whether e is an $and, $or or $not node.
+func (e *FilterExpr) IsLogical() bool {
+	return e.Op == FilterAnd || e.Op == FilterOr || e.Op == FilterNot
+}
+
+// Path returns the segments of the field path.
+func (e *FilterExpr) Path() []string { return strings.Split(e.Field, ".") }
+
+// Nested reports whether the field path has more than one segment.
+func (e *FilterExpr) Nested() bool { return strings.Contains(e.Field, ".") }
+
+// Values returns the operand of $in / $nin.
+func (e *FilterExpr) Values() []interface{} {
+	items, _ := e.Value.([]interface{})
+	return items
+}
+
+// All reports whether fn holds for e and every node below it.
+func (e *FilterExpr) All(fn func(*FilterExpr) bool) bool {
+	if !fn(e) {
+		return false
+	}
+	for _, c := range e.Children {
+		if !c.All(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Conjuncts returns the operands of a top-level $and, or e itself.
+func (e *FilterExpr) Conjuncts() []*FilterExpr {
+	if e == nil {
+		return nil
+	}
+	if e.Op == FilterAnd {
+		return e.Children
+	}
+	return []*FilterExpr{e}
+}
+
+// ParseFilter validates a filter map and returns its expression tree, or nil
+// for an empty filter. Unknown operators, malformed operands and empty paths
+// are rejected with ErrCodeInvalidFilter.
+func ParseFilter(filters map[string]interface{}) (*FilterExpr, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	expr, err := parseFilterObject(filters, "")
+	if err != nil {
+		return nil, newError(ErrCodeInvalidFilter, "invalid filter", err)
+	}
+	return expr, nil
+}
+
+// parseFilterObject parses a map of paths and logical operators. prefix is
+// the path of the enclosing object for nested-object filters.
+func parseFilterObject(m map[string]interface{}, prefix string) (*FilterExpr, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var conds []*FilterExpr
+	for _, key := range keys {
+		val := m[key]
+		switch {
+		case key == FilterAnd || key == FilterOr:
+			if prefix != "" {
+				return nil, fmt.Errorf("%s must be at the top level of a filter, not inside %q", key, prefix)
+			}
+			items, ok := filterObjects(val)
+			if !ok || len(items) == 0 {
+				return nil, fmt.Errorf("%s requires a non-empty array of filters", key)
+			}
+			children := make([]*FilterExpr, 0, len(items))
+			for _, item := range items {
+				if len(item) == 0 {
+					return nil, fmt.Errorf("%s contains an empty filter", key)
+				}
+				child, err := parseFilterObject(item, "")
+				if err != nil {
+					return nil, err
+				}
+				children = append(children, child)
+			}
+			conds = append(conds, joinFilters(key, children))
+		case key == FilterNot:
+			if prefix != "" {
+				return nil, fmt.Errorf("%s must be at the top level of a filter, not inside %q", key, prefix)
+			}
+			inner, ok := val.(map[string]interface{})
+			if !ok || len(inner) == 0 {
+				return nil, fmt.Errorf("%s requires a filter object", key)
+			}
+			child, err := parseFilterObject(inner, "")
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, &FilterExpr{Op: FilterNot, Children: []*FilterExpr{child}})
+		case strings.HasPrefix(key, "$"):
+			return nil, fmt.Errorf("unsupported logical operator %q", key)
+		default:
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			if err := checkFilterPath(path); err != nil {
+				return nil, err
+			}
+			cond, err := parseFilterField(path, val)
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, cond)
+		}
+	}
+	return joinFilters(FilterAnd, conds), nil
+}
+
+// parseFilterField parses the condition on one payload path: a scalar
+// (equality), an array ($in), an operator map or a nested object.
+func parseFilterField(path string, val interface{}) (*FilterExpr, error) {
+	if items, ok := filterScalars(val); ok {
+		return &FilterExpr{Op: FilterIn, Field: path, Value: items}, nil
+	}
+	ops, isMap := val.(map[string]interface{})
+	if !isMap {
+		if err := checkFilterScalar(val); err != nil {
+			return nil, fmt.Errorf("filter on %q: %v", path, err)
+		}
+		return &FilterExpr{Op: FilterEq, Field: path, Value: val}, nil
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("filter on %q: empty condition", path)
+	}
+
+	operators := 0
+	for k := range ops {
+		if strings.HasPrefix(k, "$") {
+			operators++
+		}
+	}
+	if operators == 0 {
+		return parseFilterObject(ops, path)
+	}
+	if operators != len(ops) {
+		return nil, fmt.Errorf("filter on %q mixes operators and nested keys", path)
+	}
+
+	names := make([]string, 0, len(ops))
+	for op := range ops {
+		names = append(names, op)
+	}
+	sort.Strings(names)
+
+	conds := make([]*FilterExpr, 0, len(ops))
+	for _, op := range names {
+		cond, err := parseFilterOperator(path, op, ops[op])
+		if err != nil {
+			return nil, fmt.Errorf("filter on %q: %v", path, err)
+		}
+		conds = append(conds, cond)
+	}
+	return joinFilters(FilterAnd, conds), nil
+}
+
+// parseFilterOperator validates the operand of one field operator.
+func parseFilterOperator(path, op string, operand interface{}) (*FilterExpr, error) {
+	switch op {
+	case FilterEq, FilterNe, FilterContains:
+		if err := checkFilterScalar(operand); err != nil {
+			return nil, fmt.Errorf("%s: %v", op, err)
+		}
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		if _, isNum := filterNumber(operand); !isNum {
+			if _, isStr := operand.(string); !isStr {
+				return nil, fmt.Errorf("%s requires a number or a string, got %T", op, operand)
+			}
+		}
+	case FilterIn, FilterNin:
+		items, ok := filterScalars(operand)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an array of scalars, got %T", op, operand)
+		}
+		operand = items
+	case FilterExists:
+		if _, ok := operand.(bool); !ok {
+			return nil, fmt.Errorf("%s requires true or false, got %T", op, operand)
+		}
+	case FilterLike:
+		if _, ok := operand.(string); !ok {
+			return nil, fmt.Errorf("%s requires a string pattern, got %T", op, operand)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", op)
+	}
+	return &FilterExpr{Op: op, Field: path, Value: operand}, nil
+}
+
+// checkFilterPath rejects empty paths and paths with empty segments.
+func checkFilterPath(path string) error {
+	for _, seg := range strings.Split(path, ".") {
+		if seg == "" {
+			return fmt.Errorf("invalid filter path %q", path)
+		}
+	}
+	return nil
+}
+
+// checkFilterScalar accepts strings, numbers and booleans.
+func checkFilterScalar(v interface{}) error {
+	switch v.(type) {
+	case string, bool:
+		return nil
+	case nil:
+		return fmt.Errorf("null is not a filter value; use $exists")
+	}
+	if _, ok := filterNumber(v); ok {
+		return nil
+	}
+	return fmt.Errorf("%T is not a filter value", v)
+}
+
+// filterObjects converts the operand of $and / $or to a slice of maps.
+func filterObjects(v interface{}) ([]map[string]interface{}, bool) {
+	switch items := v.(type) {
+	case []map[string]interface{}:
+		return items, true
+	case []interface{}:
+		out := make([]map[string]interface{}, len(items))
+		for i, item := range items {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			out[i] = m
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// filterScalars converts an array operand ([]interface{}, []string, []int,
+// ...) to []interface{}. It returns false for non-arrays and for arrays with
+// elements that are not scalars.
+func filterScalars(v interface{}) ([]interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if v == nil || rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+		if checkFilterScalar(out[i]) != nil {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// joinFilters combines conditions with a logical operator, flattening
+// nested nodes of the same operator. A single condition is returned as is.
+func joinFilters(op string, conds []*FilterExpr) *FilterExpr {
+	var flat []*FilterExpr
+	for _, c := range conds {
+		if c == nil {
+			continue
+		}
+		if c.Op == op {
+			flat = append(flat, c.Children...)
+		} else {
+			flat = append(flat, c)
+		}
+	}
+	switch len(flat) {
+	case 0:
+		return nil
+	case 1:
+		return flat[0]
+	}
+	return &FilterExpr{Op: op, Children: flat}
+}
+
+// splitFilter divides a filter into the conjuncts a provider evaluates
+// natively and the residual that is matched client-side. native reports
+// whether the provider translates a whole conjunct exactly.
+func splitFilter(expr *FilterExpr, native func(*FilterExpr) bool) (pushed, residual *FilterExpr) {
+	var p, r []*FilterExpr
+	for _, c := range expr.Conjuncts() {
+		if native(c) {
+			p = append(p, c)
+		} else {
+			r = append(r, c)
+		}
+	}
+	if len(r) == 0 {
+		return expr, nil
+	}
+	return joinFilters(FilterAnd, p), joinFilters(FilterAnd, r)
+}
+
+// ── Client-side evaluation ───────────────────────────────────────────────────
+
+// Match evaluates the filter against a document payload. A nil filter
+// matches everything.
+//
+// A missing path never satisfies $eq, $in, $contains or a comparison, and
+// always satisfies $ne and $nin. On an array value, $eq and $in match when
+// any element matches.
+func (e *FilterExpr) Match(payload map[string]interface{}) bool {
+	if e == nil {
+		return true
+	}
+	switch e.Op {
+	case FilterAnd:
+		for _, c := range e.Children {
+			if !c.Match(payload) {
+				return false
+			}
+		}
+		return true
+	case FilterOr:
+		for _, c := range e.Children {
+			if c.Match(payload) {
+				return true
+			}
+		}
+		return false
+	case FilterNot:
+		return !e.Children[0].Match(payload)
+	}
+
+	val, found := payloadPath(payload, e.Field)
+	switch e.Op {
+	case FilterExists:
+		want, _ := e.Value.(bool)
+		return found == want
+	case FilterNe:
+		return !found || !anyElement(val, func(v interface{}) bool { return filterEqual(v, e.Value) })
+	case FilterNin:
+		return !found || !anyElement(val, func(v interface{}) bool { return filterMember(v, e.Values()) })
+	}
+	if !found {
+		return false
+	}
+	switch e.Op {
+	case FilterEq:
+		return anyElement(val, func(v interface{}) bool { return filterEqual(v, e.Value) })
+	case FilterIn:
+		return anyElement(val, func(v interface{}) bool { return filterMember(v, e.Values()) })
+	case FilterContains:
+		items, isArray := filterScalars(val)
+		return isArray && filterMember(e.Value, items)
+	case FilterLike:
+		pattern, _ := e.Value.(string)
+		return anyElement(val, func(v interface{}) bool {
+			s, isStr := v.(string)
+			return isStr && likeMatch(pattern, s)
+		})
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		cmp, ok := filterCompare(val, e.Value)
+		if !ok {
+			return false
+		}
+		switch e.Op {
+		case FilterGt:
+			return cmp > 0
+		case FilterGte:
+			return cmp >= 0
+		case FilterLt:
+			return cmp < 0
+		}
+		return cmp <= 0
+	}
+	return false
+}
+
+// likeMatch reports whether s matches the SQL LIKE pattern: % matches any
+// run of characters and _ any single character.
+func likeMatch(pattern, s string) bool {
+	var re strings.Builder
+	re.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			re.WriteString(".*")
+		case '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	re.WriteString("$")
+	ok, _ := regexp.MatchString(re.String(), s)
+	return ok
+}
+
+// payloadPath looks up a dotted path in a payload. A key that itself
+// contains dots is matched before the path is split, so flat payloads with
+// dotted keys and nested objects are both found.
+func payloadPath(payload map[string]interface{}, path string) (interface{}, bool) {
+	if v, ok := payload[path]; ok {
+		return v, v != nil
+	}
+	for i := strings.Index(path, "."); i >= 0; i = nextDot(path, i) {
+		inner, ok := payload[path[:i]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, found := payloadPath(inner, path[i+1:]); found {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func nextDot(s string, i int) int {
+	j := strings.Index(s[i+1:], ".")
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// anyElement applies fn to v, or to each element when v is an array.
+func anyElement(v interface{}, fn func(interface{}) bool) bool {
+	if items, isArray := filterScalars(v); isArray {
+		for _, item := range items {
+			if fn(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return fn(v)
+}
+
+func filterMember(v interface{}, items []interface{}) bool {
+	for _, item := range items {
+		if filterEqual(v, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterEqual compares numbers numerically and other values by type and value.
+func filterEqual(a, b interface{}) bool {
+	fa, aNum := filterNumber(a)
+	fb, bNum := filterNumber(b)
+	if aNum || bNum {
+		return aNum && bNum && fa == fb
+	}
+	return a == b
+}
+
+// filterCompare orders two numbers or two strings.
+func filterCompare(a, b interface{}) (int, bool) {
+	fa, aNum := filterNumber(a)
+	fb, bNum := filterNumber(b)
+	if aNum && bNum {
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	sa, aStr := a.(string)
+	sb, bStr := b.(string)
+	if aStr && bStr {
+		return strings.Compare(sa, sb), true
+	}
+	return 0, false
+}
+
+// filterNumber converts Go and JSON numeric types to float64.
+func filterNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// residualFetchFactor multiplies TopK when part of a search filter is
+// matched client-side, so that enough candidates remain after matching.
+const residualFetchFactor = 4
+
+// searchLimit returns the number of candidates to request from the provider.
+func searchLimit(topK int, residual *FilterExpr) int {
+	if residual == nil {
+		return topK
+	}
+	return topK * residualFetchFactor
+}
+
+// matchResults keeps the search results whose payload matches residual, up
+// to topK. Providers fetch payloads for residual matching even when the
+// request skips them; they are dropped here.
+func matchResults(results []SearchResult, residual *FilterExpr, topK int, skipPayload bool) []SearchResult {
+	if residual == nil {
+		return results
+	}
+	out := results[:0]
+	for _, r := range results {
+		if !residual.Match(r.Payload) {
+			continue
+		}
+		if skipPayload {
+			r.Payload, r.Content = nil, ""
+		}
+		out = append(out, r)
+		if len(out) == topK {
+			break
+		}
+	}
+	return out
+}
+
+// matchDocuments keeps the documents whose payload matches residual.
+func matchDocuments(docs []Document, residual *FilterExpr) []Document {
+	if residual == nil {
+		return docs
+	}
+	out := docs[:0]
+	for _, d := range docs {
+		if residual.Match(d.Payload) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// scanPageSize is the page size used when a collection is scanned to apply
+// a residual filter to counts and deletes.
+const scanPageSize = 256
+
+// scrollMatchingIDs pages through a collection with scroll — which applies
+// the whole filter, natively and client-side — and returns the IDs of the
+// matching documents.
+func scrollMatchingIDs(ctx context.Context, scroll func(context.Context, ScrollRequest) (*ScrollResult, error), collection string, filters map[string]interface{}) ([]string, error) {
+	req := ScrollRequest{CollectionName: collection, Limit: scanPageSize, Filters: filters}
+	var ids []string
+	for {
+		page, err := scroll(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range page.Documents {
+			ids = append(ids, d.ID)
+		}
+		if page.NextOffset == "" || page.NextOffset == req.Offset {
+			return ids, nil
+		}
+		req.Offset = page.NextOffset
+	}
+}
@@ -25,6 +25,7 @@ type activeSpacesClient struct {
 
 // Compile-time proof that activeSpacesClient satisfies the full interface.
 var _ VectorDBClient = (*activeSpacesClient)(nil)
+var _ MetricReader = (*activeSpacesClient)(nil)
 
 func newActiveSpacesClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	scheme := "http"
@@ -114,6 +115,32 @@ func (c *activeSpacesClient) CollectionExists(ctx context.Context, name string)
 	return false, nil
 }
 
+// CollectionMetric returns the similarity the gateway lists for the
+// collection, or "" when the gateway does not report one.
+func (c *activeSpacesClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	var out struct {
+		Collections []struct {
+			Name       string `json:"name"`
+			Similarity string `json:"similarity"`
+		} `json:"collections"`
+	}
+	if err := c.retry(ctx, func() error {
+		return c.do(ctx, http.MethodGet, "/v1/collections", nil, &out)
+	}); err != nil {
+		return "", err
+	}
+	for _, col := range out.Collections {
+		if !strings.EqualFold(col.Name, name) {
+			continue
+		}
+		if col.Similarity == "l2" {
+			return "euclidean", nil
+		}
+		return col.Similarity, nil
+	}
+	return "", newError(ErrCodeCollectionNotFound, fmt.Sprintf("collection %q not found", name), nil)
+}
+
 // --- Document operations ---
 
 func (c *activeSpacesClient) UpsertDocuments(ctx context.Context, collectionName string, docs []Document) error {
@@ -145,13 +172,32 @@ func (c *activeSpacesClient) DeleteDocuments(ctx context.Context, collectionName
 	})
 }
 
+// DeleteByFilter deletes server-side when the gateway compiles the whole
+// filter; otherwise the matching IDs are collected by a scan and deleted by ID.
 func (c *activeSpacesClient) DeleteByFilter(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
+	f, residual, err := translateFilters(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	var out struct {
 		Deleted int64 `json:"deleted"`
 	}
 	if err := c.retry(ctx, func() error {
 		return c.do(ctx, http.MethodPost, c.docPath(collectionName, "delete"),
-			map[string]any{"filter": translateFilters(filters)}, &out)
+			map[string]any{"filter": f}, &out)
 	}); err != nil {
 		return 0, err
 	}
@@ -168,7 +214,11 @@ func (c *activeSpacesClient) ScrollDocuments(ctx context.Context, req ScrollRequ
 		"offset":        offset,
 		"includeVector": req.WithVectors,
 	}
-	if f := translateFilters(req.Filters); f != nil {
+	f, residual, err := translateFilters(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if f != nil {
 		body["filter"] = f
 	}
 	var out struct {
@@ -184,6 +234,7 @@ func (c *activeSpacesClient) ScrollDocuments(ctx context.Context, req ScrollRequ
 	for i, gd := range out.Documents {
 		res.Documents[i] = gd.toDocument()
 	}
+	res.Documents = matchDocuments(res.Documents, residual)
 	if out.NextOffset > 0 {
 		res.NextOffset = strconv.Itoa(out.NextOffset)
 	}
@@ -191,8 +242,19 @@ func (c *activeSpacesClient) ScrollDocuments(ctx context.Context, req ScrollRequ
 }
 
 func (c *activeSpacesClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
+	f, residual, err := translateFilters(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	body := map[string]any{}
-	if f := translateFilters(filters); f != nil {
+	if f != nil {
 		body["filter"] = f
 	}
 	var out struct {
@@ -212,14 +274,18 @@ func (c *activeSpacesClient) VectorSearch(ctx context.Context, req SearchRequest
 	if len(req.QueryVector) == 0 {
 		return nil, newError(ErrCodeInvalidQueryVector, "", nil)
 	}
+	f, residual, err := translateFilters(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	body := map[string]any{
 		"vector":          req.QueryVector,
-		"topK":            req.TopK,
+		"topK":            searchLimit(req.TopK, residual),
 		"minScore":        req.ScoreThreshold,
 		"includeVector":   req.WithVectors,
-		"includeMetadata": !req.SkipPayload,
+		"includeMetadata": !req.SkipPayload || residual != nil,
 	}
-	if f := translateFilters(req.Filters); f != nil {
+	if f != nil {
 		body["filter"] = f
 	}
 	var out struct {
@@ -230,7 +296,7 @@ func (c *activeSpacesClient) VectorSearch(ctx context.Context, req SearchRequest
 	}); err != nil {
 		return nil, err
 	}
-	return toSearchResults(out.Results), nil
+	return matchResults(toSearchResults(out.Results), residual, req.TopK, req.SkipPayload), nil
 }
 
 // HybridSearch falls back to dense VectorSearch: ActiveSpaces does not provide a
@@ -372,39 +438,42 @@ type gwFilter struct {
 	Filters []gwFilter `json:"filters,omitempty"`
 }
 
-// translateFilters converts the connector's map-based filter into the gateway's
-// structured filter. Supported per-key forms:
-//
-//	"field": scalar                       -> field == scalar
-//	"field": [a, b]                        -> field IN (a, b)
-//	"field": {"$gte": 1, "$lt": 10}        -> field >= 1 AND field < 10
-func translateFilters(m map[string]interface{}) *gwFilter {
-	if len(m) == 0 {
-		return nil
-	}
-	var conds []gwFilter
-	for field, v := range m {
-		conds = append(conds, translateField(field, v)...)
-	}
-	if len(conds) == 1 {
-		return &conds[0]
-	}
-	return &gwFilter{Op: "and", Filters: conds}
+// translateFilters parses the connector's filter map and converts the parts
+// the gateway compiles into its structured filter: $eq, $ne, $gt, $gte, $lt,
+// $lte, $in and $like on top-level metadata fields, ANDed. $or, $not, $nin,
+// $exists, $contains and nested paths are returned as the residual filter,
+// which the caller matches client-side.
+func translateFilters(m map[string]interface{}) (*gwFilter, *FilterExpr, error) {
+	expr, err := ParseFilter(m)
+	if err != nil || expr == nil {
+		return nil, nil, err
+	}
+	pushed, residual := splitFilter(expr, gatewayNative)
+	if pushed == nil {
+		return nil, residual, nil
+	}
+	conds := pushed.Conjuncts()
+	out := make([]gwFilter, len(conds))
+	for i, c := range conds {
+		out[i] = gwFilter{Op: mapFilterOp(c.Op), Field: c.Field, Value: c.Value}
+	}
+	if len(out) == 1 {
+		return &out[0], residual, nil
+	}
+	return &gwFilter{Op: "and", Filters: out}, residual, nil
 }
 
-func translateField(field string, v any) []gwFilter {
-	switch val := v.(type) {
-	case map[string]interface{}:
-		out := make([]gwFilter, 0, len(val))
-		for op, ov := range val {
-			out = append(out, gwFilter{Op: mapFilterOp(op), Field: field, Value: ov})
-		}
-		return out
-	case []interface{}:
-		return []gwFilter{{Op: "in", Field: field, Value: val}}
-	default:
-		return []gwFilter{{Op: "eq", Field: field, Value: v}}
+// gatewayNative reports whether e is a condition the gateway's filter
+// supports.
+func gatewayNative(e *FilterExpr) bool {
+	if e.IsLogical() || e.Nested() {
+		return false
+	}
+	switch e.Op {
+	case FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterIn, FilterLike:
+		return true
 	}
+	return false
 }
 
 func mapFilterOp(op string) string {
@@ -3,39 +3,87 @@ package vectordb
 import "testing"
 
 func TestTranslateFilters_Eq(t *testing.T) {
-	f := translateFilters(map[string]interface{}{"category": "news"})
+	f, _, _ := translateFilters(map[string]interface{}{"category": "news"})
 	if f == nil || f.Op != "eq" || f.Field != "category" || f.Value != "news" {
 		t.Fatalf("unexpected filter: %+v", f)
 	}
 }
 
 func TestTranslateFilters_Operators(t *testing.T) {
-	f := translateFilters(map[string]interface{}{"year": map[string]interface{}{"$gte": 2020}})
+	f, _, _ := translateFilters(map[string]interface{}{"year": map[string]interface{}{"$gte": 2020}})
 	if f == nil || f.Op != "gte" || f.Field != "year" || f.Value != 2020 {
 		t.Fatalf("unexpected filter: %+v", f)
 	}
 }
 
 func TestTranslateFilters_In(t *testing.T) {
-	f := translateFilters(map[string]interface{}{"tags": []interface{}{"a", "b"}})
+	f, _, _ := translateFilters(map[string]interface{}{"tags": []interface{}{"a", "b"}})
 	if f == nil || f.Op != "in" || f.Field != "tags" {
 		t.Fatalf("unexpected filter: %+v", f)
 	}
 }
 
 func TestTranslateFilters_Multiple(t *testing.T) {
-	f := translateFilters(map[string]interface{}{"a": "1", "b": "2"})
+	f, _, _ := translateFilters(map[string]interface{}{"a": "1", "b": "2"})
 	if f == nil || f.Op != "and" || len(f.Filters) != 2 {
 		t.Fatalf("expected AND of 2 conditions, got: %+v", f)
 	}
 }
 
 func TestTranslateFilters_Empty(t *testing.T) {
-	if translateFilters(nil) != nil {
+	if f, residual, err := translateFilters(nil); f != nil || residual != nil || err != nil {
 		t.Fatal("expected nil for empty filter")
 	}
 }
 
+func TestTranslateFilters_Residual(t *testing.T) {
+	f, residual, err := translateFilters(map[string]interface{}{
+		"category":  "news",
+		"title":     map[string]interface{}{"$like": "%vector%"},
+		"meta.team": "search",
+		"$or": []interface{}{
+			map[string]interface{}{"year": 2024},
+			map[string]interface{}{"pinned": true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("translateFilters: %v", err)
+	}
+	if f == nil || f.Op != "and" || len(f.Filters) != 2 {
+		t.Fatalf("expected AND of category and title, got: %+v", f)
+	}
+	if residual == nil || len(residual.Conjuncts()) != 2 {
+		t.Fatalf("expected the $or and the nested path as residual, got: %+v", residual)
+	}
+	if !residual.Match(map[string]interface{}{"meta": map[string]interface{}{"team": "search"}, "pinned": true}) {
+		t.Error("residual should match")
+	}
+}
+
+func TestTranslateFilters_Invalid(t *testing.T) {
+	_, _, err := translateFilters(map[string]interface{}{"a": map[string]interface{}{"$regex": "x"}})
+	if vdbErr, ok := err.(*VDBError); !ok || vdbErr.Code != ErrCodeInvalidFilter {
+		t.Fatalf("expected %s, got %v", ErrCodeInvalidFilter, err)
+	}
+}
+
+func TestLikeMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"%vector%", "a vector db", true},
+		{"vec_or", "vector", true},
+		{"vec_or", "vectors", false},
+		{"a.c", "abc", false},
+	}
+	for _, c := range cases {
+		if got := likeMatch(c.pattern, c.s); got != c.want {
+			t.Errorf("likeMatch(%q, %q) = %v, want %v", c.pattern, c.s, got, c.want)
+		}
+	}
+}
+
 func TestToSimilarity(t *testing.T) {
 	cases := map[string]string{
 		"cosine": "cosine", "": "cosine", "COSINE": "cosine",
@@ -1,10 +1,15 @@
 module github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo
 
-go 1.24.1
+go 1.24.9
+
+// pure-onnx is the real module: the Local embedding provider runs models with
+// pure-onnx/ort in binaries built with -tags onnx. It loads the onnxruntime
+// shared library with ebitengine/purego, which builds with CGO_ENABLED=0.
 
 require (
 	github.com/google/uuid v1.6.0
 	github.com/project-flogo/core v1.6.18
+	golang.org/x/net v0.50.0
 )
 
 require (
@@ -13,8 +18,26 @@ require (
 )
 
 require (
+	github.com/andybalholm/brotli v1.1.1 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/ebitengine/purego v0.10.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/parquet-go/bitpack v1.0.0 // indirect
+	github.com/parquet-go/jsonlite v1.0.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/twpayne/go-geom v1.6.1 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
 )
+
+require (
+	github.com/amikos-tech/pure-onnx v0.0.1
+	github.com/mpandav-tibco/flogo-custom-extensions/sse v0.0.0-00010101000000-000000000000
+	github.com/parquet-go/parquet-go v0.32.0
+	golang.org/x/text v0.34.0
+)
+
+replace github.com/mpandav-tibco/flogo-custom-extensions/sse => ../../sse
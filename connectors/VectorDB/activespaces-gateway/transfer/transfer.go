@@ -0,0 +1,295 @@
+// Package vdbtransfer copies collections between VectorDB clients and
+// export files. Documents are streamed page by page with
+// ScrollDocuments(WithVectors: true), so memory use is bounded by the batch
+// size, and every batch can be checkpointed so an interrupted copy resumes
+// from its last cursor.
+//
+// The target of a copy can be a client from any connector module (see
+// ClientFromConnection), a JSONL file or a Parquet file.
+package vdbtransfer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/pureGo"
+)
+
+// DefaultBatchSize is the number of documents per scroll page and upsert.
+const DefaultBatchSize = 256
+
+// Sink receives the documents copied from a collection.
+type Sink interface {
+	WriteBatch(ctx context.Context, docs []vectordb.Document) error
+}
+
+// ClientSink upserts documents into a collection.
+type ClientSink struct {
+	Client     vectordb.VectorDBClient
+	Collection string
+}
+
+func (s *ClientSink) WriteBatch(ctx context.Context, docs []vectordb.Document) error {
+	return s.Client.UpsertDocuments(ctx, s.Collection, docs)
+}
+
+// CopyOptions controls Copy.
+type CopyOptions struct {
+	Collection string
+	Filters    map[string]interface{}
+	BatchSize  int
+	// Cursor resumes a previous copy from its last checkpointed cursor.
+	Cursor string
+	// Dimensions, when positive, is the vector size every document must
+	// have. Otherwise the size of the first document is used.
+	Dimensions int
+	// Checkpoint, if set, is called after every written batch. A returned
+	// error stops the copy.
+	Checkpoint func(Progress) error
+}
+
+// Progress describes a copy after its latest batch.
+type Progress struct {
+	// Cursor is the scroll cursor of the next page. It is empty once the
+	// collection has been read to the end.
+	Cursor     string
+	Documents  int64
+	Batches    int
+	Dimensions int
+	Complete   bool
+}
+
+// Copy streams the documents of a collection, with their vectors, into
+// sink. On error the returned Progress holds the cursor of the first batch
+// that was not written, so the copy can be resumed from there.
+func Copy(ctx context.Context, src vectordb.VectorDBClient, sink Sink, opt CopyOptions) (Progress, error) {
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	p := Progress{Cursor: opt.Cursor, Dimensions: opt.Dimensions}
+	visited := map[string]bool{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return p, err
+		}
+		visited[p.Cursor] = true
+		page, err := src.ScrollDocuments(ctx, vectordb.ScrollRequest{
+			CollectionName: opt.Collection,
+			Limit:          batchSize,
+			Offset:         p.Cursor,
+			Filters:        opt.Filters,
+			WithVectors:    true,
+		})
+		if err != nil {
+			return p, fmt.Errorf("scroll %s: %w", opt.Collection, err)
+		}
+		if len(page.Documents) > 0 {
+			if err := checkVectors(page.Documents, &p.Dimensions); err != nil {
+				return p, err
+			}
+			if err := sink.WriteBatch(ctx, page.Documents); err != nil {
+				return p, fmt.Errorf("write batch: %w", err)
+			}
+			p.Documents += int64(len(page.Documents))
+			p.Batches++
+		}
+		// A provider that matches part of the filter client-side can return an
+		// empty page before the end, so only an empty cursor completes the copy.
+		if page.NextOffset != "" && visited[page.NextOffset] {
+			return p, fmt.Errorf("scroll %s: cursor %q repeated", opt.Collection, page.NextOffset)
+		}
+		p.Cursor = page.NextOffset
+		p.Complete = p.Cursor == ""
+		if opt.Checkpoint != nil && (len(page.Documents) > 0 || p.Complete) {
+			if err := opt.Checkpoint(p); err != nil {
+				return p, fmt.Errorf("checkpoint: %w", err)
+			}
+		}
+		if p.Complete {
+			return p, nil
+		}
+	}
+}
+
+// checkVectors makes sure every document has a vector of *dims elements,
+// setting *dims from the first document when it is not positive.
+func checkVectors(docs []vectordb.Document, dims *int) error {
+	for _, d := range docs {
+		if len(d.Vector) == 0 {
+			return fmt.Errorf("document %q has no vector: the source did not return vectors", d.ID)
+		}
+		if *dims <= 0 {
+			*dims = len(d.Vector)
+		}
+		if len(d.Vector) != *dims {
+			return vectordb.NewError(vectordb.ErrCodeInvalidDimensions,
+				fmt.Sprintf("document %q has %d dimensions, expected %d", d.ID, len(d.Vector), *dims), nil)
+		}
+	}
+	return nil
+}
+
+// probePageSize is the scroll page size used by ProbeDimensions.
+const probePageSize = 16
+
+// ProbeDimensions returns the vector size of the first document with a
+// vector in a collection, or 0 when no document has one. It scrolls past
+// empty pages, which providers matching part of a filter client-side return
+// before the end of the scroll.
+func ProbeDimensions(ctx context.Context, client vectordb.VectorDBClient, collection string, filters map[string]interface{}) (int, error) {
+	visited := map[string]bool{}
+	offset := ""
+	for {
+		visited[offset] = true
+		page, err := client.ScrollDocuments(ctx, vectordb.ScrollRequest{
+			CollectionName: collection,
+			Limit:          probePageSize,
+			Offset:         offset,
+			Filters:        filters,
+			WithVectors:    true,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("scroll %s: %w", collection, err)
+		}
+		for _, d := range page.Documents {
+			if len(d.Vector) > 0 {
+				return len(d.Vector), nil
+			}
+		}
+		if page.NextOffset == "" || visited[page.NextOffset] {
+			return 0, nil
+		}
+		offset = page.NextOffset
+	}
+}
+
+// PrepareTarget makes sure a collection can receive vectors of the given
+// size. A missing collection is created when create is true; an existing
+// non-empty one must already hold vectors of the same size. It reports
+// whether the collection was created.
+func PrepareTarget(ctx context.Context, client vectordb.VectorDBClient, collection string, dims int, metric string, create bool) (bool, error) {
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil {
+		return false, fmt.Errorf("check collection %s: %w", collection, err)
+	}
+	if exists {
+		existing, err := ProbeDimensions(ctx, client, collection, nil)
+		if err != nil {
+			return false, err
+		}
+		if existing > 0 && dims > 0 && existing != dims {
+			return false, vectordb.NewError(vectordb.ErrCodeInvalidDimensions,
+				fmt.Sprintf("collection %s holds %d-dimensional vectors, source has %d", collection, existing, dims), nil)
+		}
+		return false, nil
+	}
+	if !create {
+		return false, vectordb.NewError(vectordb.ErrCodeCollectionNotFound,
+			fmt.Sprintf("collection %s does not exist and createCollection is false", collection), nil)
+	}
+	if dims <= 0 {
+		return false, fmt.Errorf("cannot create collection %s: vector dimensions are unknown", collection)
+	}
+	if err := client.CreateCollection(ctx, vectordb.CollectionConfig{
+		Name:           collection,
+		Dimensions:     dims,
+		DistanceMetric: metric,
+	}); err != nil {
+		return false, fmt.Errorf("create collection %s: %w", collection, err)
+	}
+	return true, nil
+}
+
+// metricAliases maps provider spellings to the names used by
+// CollectionConfig.DistanceMetric.
+var metricAliases = map[string]string{
+	"cosine":     "cosine",
+	"cos":        "cosine",
+	"dot":        "dot",
+	"dotproduct": "dot",
+	"ip":         "dot",
+	"euclidean":  "euclidean",
+	"euclid":     "euclidean",
+	"l2":         "euclidean",
+}
+
+// NormalizeMetric returns the canonical name of a distance metric, or the
+// lower-cased input when it is not a known alias.
+func NormalizeMetric(metric string) string {
+	m := strings.ToLower(strings.TrimSpace(metric))
+	m = strings.NewReplacer("_", "", "-", "", " ", "").Replace(m)
+	if canonical, ok := metricAliases[m]; ok {
+		return canonical
+	}
+	return m
+}
+
+// CheckMetric returns an error when both metrics are known and differ:
+// vectors indexed for one metric rank differently under another.
+func CheckMetric(source, target string) error {
+	s, t := NormalizeMetric(source), NormalizeMetric(target)
+	if s == "" || t == "" || s == t {
+		return nil
+	}
+	return fmt.Errorf("distance metric mismatch: source uses %s, target uses %s", s, t)
+}
+
+// CollectionMetric returns the normalised distance metric of an existing
+// collection, or "" when the client cannot read it.
+func CollectionMetric(ctx context.Context, client vectordb.VectorDBClient, collection string) (string, error) {
+	r, ok := client.(vectordb.MetricReader)
+	if !ok {
+		return "", nil
+	}
+	metric, err := r.CollectionMetric(ctx, collection)
+	if err != nil {
+		return "", fmt.Errorf("read distance metric of %s: %w", collection, err)
+	}
+	return NormalizeMetric(metric), nil
+}
+
+// TargetMetric returns the distance metric documents copied into collection
+// will be ranked by: that of the collection when it exists, otherwise
+// metric, the one PrepareTarget creates it with. It is "" for an existing
+// collection whose metric the client cannot read.
+func TargetMetric(ctx context.Context, client vectordb.VectorDBClient, collection, metric string) (string, error) {
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil {
+		return "", fmt.Errorf("check collection %s: %w", collection, err)
+	}
+	if !exists {
+		return NormalizeMetric(metric), nil
+	}
+	return CollectionMetric(ctx, client, collection)
+}
+
+// Verification is the result of comparing document counts after a copy.
+type Verification struct {
+	Expected int64
+	Actual   int64
+	Verified bool
+	// Error explains a failed verification, including a target count that
+	// could not be read, e.g. on providers without CountDocuments support.
+	Error string
+}
+
+// Verify counts the documents in the target collection and checks that it
+// holds at least expected documents. A target that already held other
+// documents can only be checked this way; for an empty target the counts
+// are equal after a complete copy.
+func Verify(ctx context.Context, client vectordb.VectorDBClient, collection string, expected int64) Verification {
+	v := Verification{Expected: expected}
+	n, err := client.CountDocuments(ctx, collection, nil)
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+	v.Actual = n
+	v.Verified = n >= expected
+	if !v.Verified {
+		v.Error = fmt.Sprintf("collection %s holds %d documents, expected at least %d", collection, n, expected)
+	}
+	return v
+}
@@ -0,0 +1,244 @@
+package evaluateRetrieval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/nativeAS/connector"
+	vdbembed "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/nativeAS/embeddings"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data/metadata"
+)
+
+// embedBatchSize is the number of golden questions embedded per request.
+const embedBatchSize = 64
+
+var activityMd = activity.ToMetadata(&Settings{}, &Input{}, &Output{})
+
+func init() { _ = activity.Register(&Activity{}, New) }
+
+type Activity struct {
+	settings *Settings
+	conn     *vectordbconnector.ActiveSpacesConnection
+}
+
+func (a *Activity) Metadata() *activity.Metadata { return activityMd }
+
+func New(ctx activity.InitContext) (activity.Activity, error) {
+	s := &Settings{}
+	if err := metadata.MapToStruct(ctx.Settings(), s, true); err != nil {
+		return nil, fmt.Errorf("vectordb-evaluate: %w", err)
+	}
+	if s.Connection == nil {
+		return nil, fmt.Errorf("vectordb-evaluate: connection is required")
+	}
+	conn, ok := s.Connection.GetConnection().(*vectordbconnector.ActiveSpacesConnection)
+	if !ok {
+		return nil, fmt.Errorf("vectordb-evaluate: invalid connection type, expected *ActiveSpacesConnection")
+	}
+
+	// Resolve embedding credentials: inherit from connector when opted in.
+	if s.UseConnectorEmbedding {
+		connSettings := conn.GetSettings()
+		if !connSettings.EnableEmbedding {
+			ctx.Logger().Warnf("EvaluateRetrieval: useConnectorEmbedding=true but connector does not have enableEmbedding set — falling back to activity-level settings")
+		} else {
+			if s.EmbeddingProvider == "" {
+				s.EmbeddingProvider = connSettings.EmbeddingProvider
+			}
+			if s.EmbeddingAPIKey == "" {
+				s.EmbeddingAPIKey = connSettings.EmbeddingAPIKey
+			}
+			if s.EmbeddingBaseURL == "" {
+				s.EmbeddingBaseURL = connSettings.EmbeddingBaseURL
+			}
+		}
+	}
+	if s.EmbeddingProvider == "" {
+		s.EmbeddingProvider = string(vdbembed.ProviderOpenAI)
+	}
+	if s.SearchMode == "" {
+		s.SearchMode = searchModeVector
+	}
+	if s.DefaultTopK <= 0 {
+		s.DefaultTopK = 10
+	}
+	if s.ContentField == "" {
+		s.ContentField = "text"
+	}
+	if s.PrimaryMetric == "" {
+		s.PrimaryMetric = "mrr"
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 300
+	}
+	if err := baseConfig(s).validate(s.RerankEndpoint != ""); err != nil {
+		return nil, fmt.Errorf("vectordb-evaluate: %w", err)
+	}
+	ctx.Logger().Infof("EvaluateRetrieval initialised: connection=%s provider=%s searchMode=%s defaultTopK=%d rerank=%v primaryMetric=%s",
+		conn.GetName(), "activespaces", s.SearchMode, s.DefaultTopK, s.EnableRerank, s.PrimaryMetric)
+	return &Activity{settings: s, conn: conn}, nil
+}
+
+// baseConfig is the configuration described by the settings alone.
+func baseConfig(s *Settings) runConfig {
+	return runConfig{
+		SearchMode:       s.SearchMode,
+		TopK:             s.DefaultTopK,
+		ScoreThreshold:   s.ScoreThreshold,
+		Alpha:            s.HybridAlpha,
+		Rerank:           s.EnableRerank,
+		RerankCandidates: s.DefaultTopK,
+	}
+}
+
+func (a *Activity) Eval(ctx activity.Context) (bool, error) {
+	l := ctx.Logger()
+	l.Debugf("EvaluateRetrieval: starting eval")
+
+	input := &Input{}
+	if err := ctx.GetInputObject(input); err != nil {
+		return false, fmt.Errorf("vectordb-evaluate: %w", err)
+	}
+
+	collectionName := input.CollectionName
+	if collectionName == "" {
+		collectionName = a.settings.DefaultCollection
+	}
+	if collectionName == "" {
+		return false, fmt.Errorf("vectordb-evaluate: collectionName is required")
+	}
+	queries, err := parseGoldenSet(input.GoldenSet)
+	if err != nil {
+		return false, fmt.Errorf("vectordb-evaluate: %w", err)
+	}
+	kValues := input.KValues
+	if len(kValues) == 0 {
+		kValues = defaultKValues
+	}
+	for _, k := range kValues {
+		if k <= 0 {
+			return false, fmt.Errorf("vectordb-evaluate: kValues must be positive, got %d", k)
+		}
+	}
+	if err := validatePrimaryMetric(a.settings.PrimaryMetric, kValues); err != nil {
+		return false, fmt.Errorf("vectordb-evaluate: %w", err)
+	}
+	configs, err := parseGrid(input.ParameterGrid, baseConfig(a.settings), a.settings.RerankEndpoint != "")
+	if err != nil {
+		return false, fmt.Errorf("vectordb-evaluate: %w", err)
+	}
+
+	l.Debugf("EvaluateRetrieval: collection=%s queries=%d runs=%d kValues=%v", collectionName, len(queries), len(configs), kValues)
+
+	// OTel trace tags
+	tc := ctx.GetTracingContext()
+	if tc != nil {
+		tc.SetTag("db.system", "vectordb")
+		tc.SetTag("db.operation", "evaluateRetrieval")
+		tc.SetTag("db.vectordb.provider", "activespaces")
+		tc.SetTag("db.vectordb.collection", collectionName)
+		tc.SetTag("db.vectordb.eval.query_count", len(queries))
+		tc.SetTag("db.vectordb.eval.run_count", len(configs))
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := a.embedQueries(opCtx, queries); err != nil {
+		l.Errorf("EvaluateRetrieval: embedding failed: %v", err)
+		if tc != nil {
+			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": err.Error()})
+		}
+		if err := ctx.SetOutputObject(&Output{
+			Success:    false,
+			QueryCount: len(queries),
+			Error:      fmt.Sprintf("embedding failed: %s", err.Error()),
+			Duration:   time.Since(start).String(),
+		}); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
+		}
+		return true, nil
+	}
+
+	e := &evaluator{
+		client:       a.conn.GetClient(),
+		collection:   collectionName,
+		idField:      a.settings.IDField,
+		contentField: a.settings.ContentField,
+		kValues:      kValues,
+		rerank:       newAPIReranker(a.settings.RerankEndpoint, a.settings.RerankAPIKey, a.settings.RerankModel),
+	}
+	runs := make([]interface{}, 0, len(configs))
+	for _, cfg := range configs {
+		l.Debugf("EvaluateRetrieval: run %q", cfg.Name)
+		report := e.run(opCtx, cfg, queries, input.IncludePerQuery)
+		l.Debugf("EvaluateRetrieval: run %q metrics=%v failed=%v", cfg.Name, report["metrics"], report["failedQueries"])
+		runs = append(runs, report)
+	}
+	best := bestRun(runs, a.settings.PrimaryMetric)
+
+	duration := time.Since(start)
+	l.Infof("EvaluateRetrieval: collection=%s queries=%d runs=%d best=%q duration=%s", collectionName, len(queries), len(runs), best, duration)
+	if tc != nil {
+		tc.SetTag("db.vectordb.eval.best_run", best)
+	}
+	if err := ctx.SetOutputObject(&Output{
+		Success:    true,
+		Runs:       runs,
+		BestRun:    best,
+		QueryCount: len(queries),
+		Duration:   duration.String(),
+	}); err != nil {
+		l.Errorf("SetOutputObject: %v", err)
+	}
+	return true, nil
+}
+
+// embedQueries fills in the vector of every golden question that has none.
+// Each question is embedded once and reused by every run.
+func (a *Activity) embedQueries(ctx context.Context, queries []goldenQuery) error {
+	var pending []int
+	for i, q := range queries {
+		if len(q.Vector) == 0 {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if a.settings.EmbeddingModel == "" {
+		return fmt.Errorf("embeddingModel is required for golden questions without a queryVector")
+	}
+	for start := 0; start < len(pending); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		texts := make([]string, 0, end-start)
+		for _, i := range pending[start:end] {
+			texts = append(texts, queries[i].Query)
+		}
+		resp, err := vdbembed.CreateEmbeddings(ctx, vdbembed.EmbeddingRequest{
+			Provider:   vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
+			APIKey:     a.settings.EmbeddingAPIKey,
+			BaseURL:    a.settings.EmbeddingBaseURL,
+			Model:      a.settings.EmbeddingModel,
+			Texts:      texts,
+			Dimensions: a.settings.EmbeddingDimensions,
+		})
+		if err != nil {
+			return err
+		}
+		if len(resp.Embeddings) != len(texts) {
+			return fmt.Errorf("provider returned %d embeddings for %d queries", len(resp.Embeddings), len(texts))
+		}
+		for j, i := range pending[start:end] {
+			queries[i].Vector = resp.Embeddings[j]
+		}
+	}
+	return nil
+}
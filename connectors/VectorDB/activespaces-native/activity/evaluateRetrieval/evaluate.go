@@ -0,0 +1,427 @@
+package evaluateRetrieval
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/nativeAS"
+)
+
+// Search modes for the searchMode setting and grid key.
+const (
+	searchModeVector = "vector"
+	searchModeHybrid = "hybrid"
+)
+
+// defaultKValues are the recall@k / nDCG@k cut-offs when kValues is empty.
+var defaultKValues = []int{1, 3, 5, 10}
+
+// goldenQuery is one parsed golden-set question.
+type goldenQuery struct {
+	Query     string
+	Vector    []float64
+	Relevance map[string]float64
+	Filters   map[string]interface{}
+}
+
+// parseGoldenSet validates the goldenSet input. Every question needs a query
+// or a queryVector, and at least one expected document in expectedIds or
+// relevance (a map of document ID to graded relevance).
+func parseGoldenSet(items []interface{}) ([]goldenQuery, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("goldenSet is required")
+	}
+	out := make([]goldenQuery, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("goldenSet[%d]: expected an object, got %T", i, item)
+		}
+		q := goldenQuery{Relevance: map[string]float64{}}
+		if v, ok := m["query"].(string); ok {
+			q.Query = v
+		}
+		if arr, ok := m["queryVector"].([]interface{}); ok {
+			q.Vector = make([]float64, 0, len(arr))
+			for _, f := range arr {
+				if fv, ok := toFloat(f); ok {
+					q.Vector = append(q.Vector, fv)
+				}
+			}
+		}
+		if f, ok := m["filters"].(map[string]interface{}); ok {
+			q.Filters = f
+		}
+		if ids, ok := m["expectedIds"].([]interface{}); ok {
+			for _, id := range ids {
+				if s := fmt.Sprintf("%v", id); s != "" {
+					q.Relevance[s] = 1
+				}
+			}
+		}
+		if rel, ok := m["relevance"].(map[string]interface{}); ok {
+			for id, g := range rel {
+				if grade, ok := toFloat(g); ok && grade > 0 {
+					q.Relevance[id] = grade
+				}
+			}
+		}
+		if q.Query == "" && len(q.Vector) == 0 {
+			return nil, fmt.Errorf("goldenSet[%d]: query or queryVector is required", i)
+		}
+		if len(q.Relevance) == 0 {
+			return nil, fmt.Errorf("goldenSet[%d]: expectedIds or relevance is required", i)
+		}
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+// runConfig is one retrieval configuration under evaluation.
+type runConfig struct {
+	Name           string
+	SearchMode     string
+	TopK           int
+	ScoreThreshold float64
+	Alpha          float64
+	// Rerank reranks RerankCandidates search results and keeps the top TopK.
+	Rerank           bool
+	RerankCandidates int
+}
+
+func (c runConfig) params() map[string]interface{} {
+	p := map[string]interface{}{
+		"searchMode":     c.SearchMode,
+		"topK":           c.TopK,
+		"scoreThreshold": c.ScoreThreshold,
+		"rerank":         c.Rerank,
+	}
+	if c.SearchMode == searchModeHybrid {
+		p["alpha"] = c.Alpha
+	}
+	if c.Rerank {
+		p["rerankCandidates"] = c.RerankCandidates
+	}
+	return p
+}
+
+// defaultName describes the configuration, e.g. "hybrid topK=5 alpha=0.7 rerank".
+func (c runConfig) defaultName() string {
+	parts := []string{c.SearchMode, fmt.Sprintf("topK=%d", c.TopK)}
+	if c.ScoreThreshold > 0 {
+		parts = append(parts, fmt.Sprintf("threshold=%g", c.ScoreThreshold))
+	}
+	if c.SearchMode == searchModeHybrid {
+		parts = append(parts, fmt.Sprintf("alpha=%g", c.Alpha))
+	}
+	if c.Rerank {
+		parts = append(parts, fmt.Sprintf("rerank=%d", c.RerankCandidates))
+	}
+	return strings.Join(parts, " ")
+}
+
+// validate checks the configuration; canRerank reports whether a rerank
+// endpoint is configured.
+func (c runConfig) validate(canRerank bool) error {
+	if c.SearchMode != searchModeVector && c.SearchMode != searchModeHybrid {
+		return fmt.Errorf("searchMode must be vector or hybrid, got %q", c.SearchMode)
+	}
+	if c.TopK <= 0 {
+		return fmt.Errorf("topK must be positive, got %d", c.TopK)
+	}
+	if c.Alpha < 0 || c.Alpha > 1 {
+		return fmt.Errorf("alpha %.4f is out of range [0, 1]", c.Alpha)
+	}
+	if c.Rerank && !canRerank {
+		return fmt.Errorf("rerank requires the rerankEndpoint setting")
+	}
+	return nil
+}
+
+// parseGrid returns the configurations to evaluate: the baseline alone
+// when the grid is empty, otherwise one per grid entry with the entry's
+// keys (name, searchMode, topK, scoreThreshold, alpha, rerank,
+// rerankCandidates) overriding the baseline.
+func parseGrid(grid []interface{}, base runConfig, canRerank bool) ([]runConfig, error) {
+	if len(grid) == 0 {
+		grid = []interface{}{map[string]interface{}{}}
+	}
+	out := make([]runConfig, 0, len(grid))
+	names := map[string]bool{}
+	for i, item := range grid {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("parameterGrid[%d]: expected an object, got %T", i, item)
+		}
+		c := base
+		c.Name = ""
+		if v, ok := m["name"].(string); ok {
+			c.Name = v
+		}
+		if v, ok := m["searchMode"].(string); ok {
+			c.SearchMode = v
+		}
+		if v, ok := toInt(m["topK"]); ok {
+			c.TopK = v
+		}
+		if v, ok := toFloat(m["scoreThreshold"]); ok {
+			c.ScoreThreshold = v
+		}
+		if v, ok := toFloat(m["alpha"]); ok {
+			c.Alpha = v
+		}
+		if v, ok := m["rerank"].(bool); ok {
+			c.Rerank = v
+		}
+		if v, ok := toInt(m["rerankCandidates"]); ok {
+			c.RerankCandidates = v
+		}
+		if c.RerankCandidates < c.TopK {
+			c.RerankCandidates = c.TopK
+		}
+		if err := c.validate(canRerank); err != nil {
+			return nil, fmt.Errorf("parameterGrid[%d]: %w", i, err)
+		}
+		if c.Name == "" {
+			c.Name = c.defaultName()
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("parameterGrid[%d]: duplicate run name %q", i, c.Name)
+		}
+		names[c.Name] = true
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// validatePrimaryMetric checks that metric is "mrr" or a recall@k / ndcg@k
+// computed for one of kValues.
+func validatePrimaryMetric(metric string, kValues []int) error {
+	if metric == "mrr" {
+		return nil
+	}
+	for _, k := range kValues {
+		if metric == fmt.Sprintf("recall@%d", k) || metric == fmt.Sprintf("ndcg@%d", k) {
+			return nil
+		}
+	}
+	return fmt.Errorf("primaryMetric must be mrr or recall@k / ndcg@k for k in kValues %v, got %q", kValues, metric)
+}
+
+// reranker reorders documents for a query and returns their indices, best
+// first, at most topN of them.
+type reranker func(ctx context.Context, query string, documents []string, topN int) ([]int, error)
+
+// evaluator runs golden-set queries against any VectorDBClient.
+type evaluator struct {
+	client       vectordb.VectorDBClient
+	collection   string
+	idField      string
+	contentField string
+	kValues      []int
+	rerank       reranker
+}
+
+// run evaluates one configuration. Queries that fail are counted in
+// failedQueries and left out of the averages and latency figures.
+func (e *evaluator) run(ctx context.Context, cfg runConfig, queries []goldenQuery, perQuery bool) map[string]interface{} {
+	sums := map[string]float64{}
+	latencies := make([]time.Duration, 0, len(queries))
+	failed := 0
+	firstErr := ""
+	var details []interface{}
+
+	for _, q := range queries {
+		start := time.Now()
+		ranked, err := e.retrieve(ctx, cfg, q)
+		elapsed := time.Since(start)
+		if err != nil {
+			failed++
+			if firstErr == "" {
+				firstErr = err.Error()
+			}
+			if perQuery {
+				details = append(details, map[string]interface{}{"query": q.Query, "error": err.Error()})
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		latencies = append(latencies, elapsed)
+		m := queryMetrics(ranked, q.Relevance, e.kValues)
+		for k, v := range m {
+			sums[k] += v
+		}
+		if perQuery {
+			details = append(details, map[string]interface{}{
+				"query":       q.Query,
+				"retrieved":   stringsToInterface(ranked),
+				"metrics":     floatsToInterface(m),
+				"latencyMs":   float64(elapsed) / float64(time.Millisecond),
+				"expectedIds": stringsToInterface(sortedKeys(q.Relevance)),
+			})
+		}
+	}
+
+	metrics := map[string]interface{}{}
+	succeeded := len(latencies)
+	for k, v := range sums {
+		metrics[k] = v / float64(succeeded)
+	}
+	if succeeded == 0 {
+		for k := range queryMetrics(nil, nil, e.kValues) {
+			metrics[k] = 0.0
+		}
+	}
+	report := map[string]interface{}{
+		"name":          cfg.Name,
+		"params":        cfg.params(),
+		"metrics":       metrics,
+		"latency":       latencyStats(latencies),
+		"queryCount":    len(queries),
+		"failedQueries": failed,
+	}
+	if firstErr != "" {
+		report["error"] = firstErr
+	}
+	if perQuery {
+		report["queries"] = details
+	}
+	return report
+}
+
+// retrieve runs one query through search and the optional rerank stage and
+// returns the ranked document IDs.
+func (e *evaluator) retrieve(ctx context.Context, cfg runConfig, q goldenQuery) ([]string, error) {
+	limit := cfg.TopK
+	if cfg.Rerank {
+		limit = cfg.RerankCandidates
+	}
+	// Payload is only needed to map chunks to documents or to rerank.
+	skipPayload := e.idField == "" && !cfg.Rerank
+
+	var results []vectordb.SearchResult
+	var err error
+	if cfg.SearchMode == searchModeHybrid {
+		results, err = e.client.HybridSearch(ctx, vectordb.HybridSearchRequest{
+			CollectionName: e.collection,
+			QueryText:      q.Query,
+			QueryVector:    q.Vector,
+			TopK:           limit,
+			ScoreThreshold: cfg.ScoreThreshold,
+			Filters:        q.Filters,
+			Alpha:          cfg.Alpha,
+			SkipPayload:    skipPayload,
+		})
+	} else {
+		results, err = e.client.VectorSearch(ctx, vectordb.SearchRequest{
+			CollectionName: e.collection,
+			QueryVector:    q.Vector,
+			TopK:           limit,
+			ScoreThreshold: cfg.ScoreThreshold,
+			Filters:        q.Filters,
+			SkipPayload:    skipPayload,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	if cfg.Rerank && len(results) > 0 {
+		texts := make([]string, len(results))
+		for i, r := range results {
+			texts[i] = e.content(r)
+		}
+		order, err := e.rerank(ctx, q.Query, texts, len(results))
+		if err != nil {
+			return nil, fmt.Errorf("rerank: %w", err)
+		}
+		reranked := make([]vectordb.SearchResult, 0, len(order))
+		for _, idx := range order {
+			if idx >= 0 && idx < len(results) {
+				reranked = append(reranked, results[idx])
+			}
+		}
+		results = reranked
+	}
+	return e.rankedIDs(results, cfg.TopK), nil
+}
+
+// rankedIDs returns up to topK distinct document IDs in result order. With
+// idField set, each chunk is mapped to its document and only its first
+// occurrence counts.
+func (e *evaluator) rankedIDs(results []vectordb.SearchResult, topK int) []string {
+	ids := make([]string, 0, len(results))
+	seen := map[string]bool{}
+	for _, r := range results {
+		id := r.ID
+		if e.idField != "" {
+			if v, ok := r.Payload[e.idField]; ok && v != nil {
+				id = fmt.Sprintf("%v", v)
+			}
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		if len(ids) == topK {
+			break
+		}
+	}
+	return ids
+}
+
+// content returns the text of a result for reranking.
+func (e *evaluator) content(r vectordb.SearchResult) string {
+	if r.Content != "" {
+		return r.Content
+	}
+	if v, ok := r.Payload[e.contentField]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+// bestRun returns the name of the run with the highest value of metric;
+// the first run wins ties.
+func bestRun(runs []interface{}, metric string) string {
+	best, bestVal := "", -1.0
+	for _, r := range runs {
+		report := r.(map[string]interface{})
+		v, _ := report["metrics"].(map[string]interface{})[metric].(float64)
+		if v > bestVal {
+			best, bestVal = report["name"].(string), v
+		}
+	}
+	return best
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringsToInterface(ss []string) []interface{} {
+	out := make([]interface{}, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
+
+func floatsToInterface(m map[string]float64) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+package ingestDocuments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	vectordb "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/nativeAS"
+)
+
+// Ingest modes.
+const (
+	ingestModeFull        = "full"
+	ingestModeIncremental = "incremental"
+)
+
+// Payload keys written on every chunk in incremental mode. They use the
+// reserved _ prefix like the chunk provenance fields.
+const (
+	ingestSourceKey = "_ingest_source" // source the chunk belongs to
+	chunkHashKey    = "_chunk_hash"    // SHA-256 of the chunk text
+	payloadHashKey  = "_payload_hash"  // SHA-256 of the chunk metadata
+)
+
+// incrementalScrollPage is the page size used to list a source's stored chunks.
+const incrementalScrollPage = 256
+
+// chunkIDNamespace is the UUID v5 namespace of deterministic chunk IDs.
+var chunkIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("urn:flogo:vectordb:ingestDocuments"))
+
+// stampSources records each document's source under _ingest_source so every
+// chunk inherits it. The source is the "source" metadata value (the file
+// name for uploads) or, failing that, the document id.
+func stampSources(docs []RawDocument) error {
+	for i := range docs {
+		src := ""
+		if v, ok := docs[i].Metadata["source"]; ok && v != nil {
+			src = fmt.Sprintf("%v", v)
+		}
+		if src == "" {
+			src = docs[i].ID
+		}
+		if src == "" {
+			return fmt.Errorf("document[%d]: incremental mode needs an id or a metadata.source to identify the document", i)
+		}
+		meta := make(map[string]interface{}, len(docs[i].Metadata)+1)
+		for k, v := range docs[i].Metadata {
+			meta[k] = v
+		}
+		meta[ingestSourceKey] = src
+		docs[i].Metadata = meta
+	}
+	return nil
+}
+
+// incrementalPlan is the outcome of comparing a run's chunks with the chunks
+// already stored for the same sources.
+type incrementalPlan struct {
+	// IDs holds the deterministic ID of every distinct chunk, in input order.
+	IDs []string
+	// Changed are the new or modified chunks that must be embedded and upserted.
+	Changed []RawDocument
+	// Skipped counts chunks already stored with identical text and metadata.
+	Skipped int
+	// Duplicates counts chunks dropped because the same source already had a
+	// chunk with identical text in this run.
+	Duplicates int
+	// stale lists, per source, the chunk hashes stored but no longer present.
+	stale map[string][]string
+	// staleCount is the total number of stale chunks.
+	staleCount int
+	sources    []string
+}
+
+// planIncremental assigns each chunk the ID uuid5(source, SHA-256(text)),
+// drops repeated chunks within a source, and lists the source's stored chunks
+// to find which chunks are unchanged and which stored chunks are stale.
+// A stored chunk is unchanged when its _payload_hash matches, so metadata
+// edits (including shifted _chunk_index values) are re-upserted.
+func planIncremental(ctx context.Context, client vectordb.VectorDBClient, collection string, chunks []RawDocument) (*incrementalPlan, error) {
+	plan := &incrementalPlan{stale: make(map[string][]string)}
+	type entry struct {
+		doc         RawDocument
+		payloadHash string
+	}
+	bySource := make(map[string][]entry)
+	seen := make(map[string]bool, len(chunks))
+
+	for _, c := range chunks {
+		src, _ := c.Metadata[ingestSourceKey].(string)
+		textHash := sha256Hex([]byte(c.Text))
+		id := uuid.NewSHA1(chunkIDNamespace, []byte(src+"\x00"+textHash)).String()
+		if seen[id] {
+			plan.Duplicates++
+			continue
+		}
+		seen[id] = true
+		plan.IDs = append(plan.IDs, id)
+
+		meta := make(map[string]interface{}, len(c.Metadata)+2)
+		for k, v := range c.Metadata {
+			meta[k] = v
+		}
+		meta[chunkHashKey] = textHash
+		metaJSON, err := json.Marshal(meta)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %s: cannot hash metadata: %w", id, err)
+		}
+		payloadHash := sha256Hex(metaJSON)
+		meta[payloadHashKey] = payloadHash
+
+		if _, ok := bySource[src]; !ok {
+			plan.sources = append(plan.sources, src)
+		}
+		bySource[src] = append(bySource[src], entry{
+			doc:         RawDocument{ID: id, Text: c.Text, Metadata: meta},
+			payloadHash: payloadHash,
+		})
+	}
+
+	for _, src := range plan.sources {
+		stored, err := storedChunks(ctx, client, collection, src)
+		if err != nil {
+			return nil, fmt.Errorf("listing stored chunks of source %q: %w", src, err)
+		}
+		for _, e := range bySource[src] {
+			if h, ok := stored[e.doc.ID]; ok && h.payloadHash == e.payloadHash {
+				plan.Skipped++
+			} else {
+				plan.Changed = append(plan.Changed, e.doc)
+			}
+		}
+		for id, h := range stored {
+			if !seen[id] && h.chunkHash != "" {
+				plan.stale[src] = append(plan.stale[src], h.chunkHash)
+				plan.staleCount++
+			}
+		}
+	}
+	return plan, nil
+}
+
+// storedHashes are the hashes recorded on a stored chunk.
+type storedHashes struct {
+	chunkHash   string
+	payloadHash string
+}
+
+// storedChunks lists the chunks stored for one source, keyed by ID.
+func storedChunks(ctx context.Context, client vectordb.VectorDBClient, collection, source string) (map[string]storedHashes, error) {
+	out := make(map[string]storedHashes)
+	offset := ""
+	// Pages can be empty before the last one when part of the filter is
+	// matched client-side, so only the end of the scroll stops the loop; a
+	// repeated offset guards against a provider that never advances.
+	seen := map[string]bool{}
+	for {
+		seen[offset] = true
+		page, err := client.ScrollDocuments(ctx, vectordb.ScrollRequest{
+			CollectionName: collection,
+			Limit:          incrementalScrollPage,
+			Offset:         offset,
+			Filters:        map[string]interface{}{ingestSourceKey: source},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range page.Documents {
+			ch, _ := d.Payload[chunkHashKey].(string)
+			ph, _ := d.Payload[payloadHashKey].(string)
+			out[d.ID] = storedHashes{chunkHash: ch, payloadHash: ph}
+		}
+		if page.NextOffset == "" || seen[page.NextOffset] {
+			return out, nil
+		}
+		offset = page.NextOffset
+	}
+}
+
+// deleteStale removes the stored chunks of the run's sources that are no
+// longer produced, with one DeleteByFilter per stale chunk on its source and
+// text hash. It returns the number of stale chunks deleted.
+func (p *incrementalPlan) deleteStale(ctx context.Context, client vectordb.VectorDBClient, collection string) (int, error) {
+	deleted := 0
+	for _, src := range p.sources {
+		for _, hash := range p.stale[src] {
+			if _, err := client.DeleteByFilter(ctx, collection, map[string]interface{}{
+				ingestSourceKey: src,
+				chunkHashKey:    hash,
+			}); err != nil {
+				return deleted, fmt.Errorf("deleting stale chunk %s of source %q: %w", hash, src, err)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
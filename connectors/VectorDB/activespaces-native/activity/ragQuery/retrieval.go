@@ -0,0 +1,226 @@
+package ragQuery
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	vectordb "github.com/mpandav-tibco/flogo-extensions/VectorDB/activespaces/nativeAS"
+	"github.com/project-flogo/core/support/log"
+)
+
+// Retrieval modes for the retrievalMode setting.
+const (
+	retrievalStandard   = "standard"   // embed and search the query once
+	retrievalMultiQuery = "multiQuery" // search LLM-generated variants, fuse with RRF
+	retrievalHyDE       = "hyde"       // embed an LLM-written hypothetical answer
+)
+
+const (
+	// defaultQueryVariants is the number of LLM-generated variants searched in
+	// multiQuery mode, in addition to the query itself.
+	defaultQueryVariants = 3
+	maxQueryVariants     = 10
+	// rrfK is the reciprocal rank fusion constant: score = Σ 1/(rrfK + rank).
+	rrfK = 60
+	// maxRewriteHistory is the number of most recent chat messages given to
+	// the LLM when rewriting a follow-up question.
+	maxRewriteHistory = 10
+)
+
+const (
+	rewriteSystemPrompt    = "Rewrite the last question of the conversation as a standalone search query that can be understood without the conversation. Resolve pronouns and references such as \"it\", \"that\" or \"v2\" using the conversation. Return only the rewritten question."
+	multiQuerySystemPrompt = "Generate %d different search queries that could retrieve documents answering the user's question. Vary the wording, use synonyms and different perspectives. Return one query per line, without numbering or explanations."
+	hydeSystemPrompt       = "Write a short passage that answers the question, as it would appear in a reference document. Be specific and factual in tone. Do not mention that the passage is hypothetical."
+)
+
+// variantPrefixRe matches list markers an LLM may put before a query variant
+// despite the instructions: "1.", "2)", "-", "*", "•".
+var variantPrefixRe = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s*`)
+
+// queryPlan is the outcome of query transformation: the texts to embed and
+// the queries searched with them.
+type queryPlan struct {
+	// Query is the question used for retrieval and generation: the rewritten
+	// standalone question, or the input queryText.
+	Query string
+	// Rewritten is the standalone question produced from the chat history;
+	// empty when no rewrite took place.
+	Rewritten string
+	// Searches holds the query text of each search; Query comes first.
+	Searches []string
+	// EmbedTexts holds the text embedded for each search. It equals Searches
+	// except in HyDE mode, where the hypothetical document is embedded.
+	EmbedTexts []string
+	// Hypothetical is the HyDE passage; empty in other modes.
+	Hypothetical string
+}
+
+// planQueries rewrites the query from the chat history and expands it for
+// the configured retrieval mode. LLM failures are logged and the affected
+// step falls back to the original query, so retrieval always proceeds.
+func (a *Activity) planQueries(ctx context.Context, l log.Logger, input *Input) queryPlan {
+	plan := queryPlan{Query: input.QueryText}
+
+	if a.settings.EnableQueryRewrite && len(input.ChatHistory) > 0 {
+		rewritten, err := a.rewriteQuery(ctx, input.QueryText, input.ChatHistory)
+		switch {
+		case err != nil:
+			l.Warnf("RAGQuery: query rewrite failed (%v) — using the original query", err)
+		case rewritten != "":
+			l.Debugf("RAGQuery: rewrote %q to %q", input.QueryText, rewritten)
+			plan.Query, plan.Rewritten = rewritten, rewritten
+		}
+	}
+	plan.Searches = []string{plan.Query}
+	plan.EmbedTexts = []string{plan.Query}
+
+	switch a.settings.RetrievalMode {
+	case retrievalMultiQuery:
+		variants, err := a.queryVariants(ctx, plan.Query)
+		if err != nil {
+			l.Warnf("RAGQuery: query variant generation failed (%v) — searching the original query only", err)
+			break
+		}
+		plan.Searches = append(plan.Searches, variants...)
+		plan.EmbedTexts = plan.Searches
+	case retrievalHyDE:
+		passage, err := a.callLLM(ctx, hydeSystemPrompt, "Question: "+plan.Query+"\n\nPassage:", nil)
+		if err != nil || passage == "" {
+			l.Warnf("RAGQuery: hypothetical document generation failed (%v) — embedding the query", err)
+			break
+		}
+		plan.Hypothetical = passage
+		plan.EmbedTexts = []string{passage}
+	}
+	return plan
+}
+
+// rewriteQuery asks the LLM for a standalone version of query given the most
+// recent maxRewriteHistory messages of history.
+func (a *Activity) rewriteQuery(ctx context.Context, query string, history []interface{}) (string, error) {
+	if len(history) > maxRewriteHistory {
+		history = history[len(history)-maxRewriteHistory:]
+	}
+	var sb strings.Builder
+	sb.WriteString("Conversation:\n")
+	for _, m := range history {
+		role, content := chatMessage(m)
+		if content == "" {
+			continue
+		}
+		sb.WriteString(role)
+		sb.WriteString(": ")
+		sb.WriteString(content)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nLast question: ")
+	sb.WriteString(query)
+	sb.WriteString("\n\nStandalone question:")
+	rewritten, err := a.callLLM(ctx, rewriteSystemPrompt, sb.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(strings.TrimSpace(rewritten), `"`), nil
+}
+
+// chatMessage reads one chatHistory entry: an object with role and content
+// (or text), or a plain string treated as a user message.
+func chatMessage(m interface{}) (role, content string) {
+	switch v := m.(type) {
+	case string:
+		return "user", strings.TrimSpace(v)
+	case map[string]interface{}:
+		role = "user"
+		if r, ok := v["role"].(string); ok && r != "" {
+			role = r
+		}
+		for _, key := range []string{"content", "text", "message"} {
+			if c, ok := v[key]; ok && c != nil {
+				return role, strings.TrimSpace(fmt.Sprintf("%v", c))
+			}
+		}
+	}
+	return "", ""
+}
+
+// queryVariants asks the LLM for alternative phrasings of query. Variants
+// that repeat the query or each other are dropped.
+func (a *Activity) queryVariants(ctx context.Context, query string) ([]string, error) {
+	n := a.settings.QueryVariants
+	out, err := a.callLLM(ctx, fmt.Sprintf(multiQuerySystemPrompt, n), "Question: "+query+"\n\nQueries:", nil)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{strings.ToLower(query): true}
+	var variants []string
+	for _, line := range strings.Split(out, "\n") {
+		v := strings.TrimSpace(variantPrefixRe.ReplaceAllString(line, ""))
+		v = strings.Trim(v, `"`)
+		key := strings.ToLower(v)
+		if v == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		variants = append(variants, v)
+		if len(variants) == n {
+			break
+		}
+	}
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("no query variants in LLM response")
+	}
+	return variants, nil
+}
+
+// fuseRRF merges ranked result lists with reciprocal rank fusion and returns
+// the topK documents by fused score. A document found by several searches
+// keeps the payload of its first occurrence and its highest similarity
+// score, so scores stay comparable with single-query retrieval.
+func fuseRRF(lists [][]vectordb.SearchResult, topK int) []vectordb.SearchResult {
+	type fused struct {
+		result vectordb.SearchResult
+		rrf    float64
+		first  int // order of first appearance, for stable ties
+	}
+	byID := make(map[string]*fused)
+	var order []*fused
+	for _, list := range lists {
+		for rank, r := range list {
+			f, ok := byID[r.ID]
+			if !ok {
+				f = &fused{result: r, first: len(order)}
+				byID[r.ID] = f
+				order = append(order, f)
+			} else if r.Score > f.result.Score {
+				f.result.Score = r.Score
+			}
+			f.rrf += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].rrf != order[j].rrf {
+			return order[i].rrf > order[j].rrf
+		}
+		return order[i].first < order[j].first
+	})
+	if topK > 0 && len(order) > topK {
+		order = order[:topK]
+	}
+	out := make([]vectordb.SearchResult, len(order))
+	for i, f := range order {
+		out[i] = f.result
+	}
+	return out
+}
+
+// stringsToInterface converts a string slice to []interface{} for Flogo output.
+func stringsToInterface(ss []string) []interface{} {
+	out := make([]interface{}, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
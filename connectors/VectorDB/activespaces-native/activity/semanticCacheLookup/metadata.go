@@ -0,0 +1,134 @@
+package semanticCacheLookup
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/support/connection"
+)
+
+// Settings holds design-time activity configuration.
+type Settings struct {
+	Connection connection.Manager `md:"connection,required"`
+	// UseConnectorEmbedding inherits the embedding provider, API key, and base
+	// URL from the VectorDB connector. Requires 'Configure Embedding Provider'
+	// on the connection.
+	UseConnectorEmbedding bool   `md:"useConnectorEmbedding"`
+	EmbeddingProvider     string `md:"embeddingProvider"`
+	EmbeddingAPIKey       string `md:"embeddingAPIKey"`
+	EmbeddingBaseURL      string `md:"embeddingBaseURL"`
+	EmbeddingModel        string `md:"embeddingModel,required"`
+	EmbeddingDimensions   int    `md:"embeddingDimensions"`
+	// CacheCollection is the collection holding the cached answers.
+	// Default: semantic_cache.
+	CacheCollection string `md:"cacheCollection"`
+	// SimilarityThreshold is the minimum similarity between the question and
+	// a cached question for a hit. Default: 0.92.
+	SimilarityThreshold float64 `md:"similarityThreshold"`
+	TimeoutSeconds      int     `md:"timeoutSeconds"`
+}
+
+// String returns a human-readable representation of Settings with the
+// embedding API key replaced by "[redacted]".
+func (s Settings) String() string {
+	apiKey := ""
+	if s.EmbeddingAPIKey != "" {
+		apiKey = "[redacted]"
+	}
+	return fmt.Sprintf(
+		"semanticCacheLookup.Settings{provider:%q model:%q dims:%d collection:%q threshold:%.2f apiKey:%s}",
+		s.EmbeddingProvider, s.EmbeddingModel, s.EmbeddingDimensions, s.CacheCollection, s.SimilarityThreshold, apiKey,
+	)
+}
+
+// Input holds runtime data for the activity.
+type Input struct {
+	QueryText string `md:"queryText"`
+	// QueryVector, when set, is used instead of embedding QueryText.
+	QueryVector []float64 `md:"queryVector"`
+	// Namespace partitions the cache, e.g. per collection or tenant. Only
+	// entries stored with the same namespace are returned.
+	Namespace string `md:"namespace"`
+}
+
+func (i *Input) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"queryText":   i.QueryText,
+		"queryVector": i.QueryVector,
+		"namespace":   i.Namespace,
+	}
+}
+
+func (i *Input) FromMap(v map[string]interface{}) error {
+	if val, ok := v["queryText"]; ok && val != nil {
+		i.QueryText = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["queryVector"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.QueryVector = make([]float64, len(arr))
+			for j, f := range arr {
+				if fv, ok := f.(float64); ok {
+					i.QueryVector[j] = fv
+				}
+			}
+		}
+	}
+	if val, ok := v["namespace"]; ok && val != nil {
+		i.Namespace = fmt.Sprintf("%v", val)
+	}
+	return nil
+}
+
+// Output holds the activity result.
+type Output struct {
+	Success bool `md:"success"`
+	// Hit is true when a cached answer was found.
+	Hit         bool    `md:"hit"`
+	Answer      string  `md:"answer"`
+	CachedQuery string  `md:"cachedQuery"`
+	Score       float64 `md:"score"`
+	CacheID     string  `md:"cacheId"`
+	// SourceIDs are the source document IDs the cached answer was
+	// generated from.
+	SourceIDs []interface{}          `md:"sourceIds"`
+	Metadata  map[string]interface{} `md:"metadata"`
+	// CreatedAt and ExpiresAt are RFC 3339 timestamps; ExpiresAt is empty
+	// for an entry without TTL.
+	CreatedAt string `md:"createdAt"`
+	ExpiresAt string `md:"expiresAt"`
+	// QueryEmbedding is the embedding of the question, for semanticCacheStore
+	// after a miss.
+	QueryEmbedding []interface{} `md:"queryEmbedding"`
+	Duration       string        `md:"duration"`
+	Error          string        `md:"error"`
+}
+
+func (o *Output) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"success":        o.Success,
+		"hit":            o.Hit,
+		"answer":         o.Answer,
+		"cachedQuery":    o.CachedQuery,
+		"score":          o.Score,
+		"cacheId":        o.CacheID,
+		"sourceIds":      o.SourceIDs,
+		"metadata":       o.Metadata,
+		"createdAt":      o.CreatedAt,
+		"expiresAt":      o.ExpiresAt,
+		"queryEmbedding": o.QueryEmbedding,
+		"duration":       o.Duration,
+		"error":          o.Error,
+	}
+}
+
+func (o *Output) FromMap(v map[string]interface{}) error {
+	if val, ok := v["success"]; ok {
+		o.Success, _ = val.(bool)
+	}
+	if val, ok := v["hit"]; ok {
+		o.Hit, _ = val.(bool)
+	}
+	if val, ok := v["answer"]; ok && val != nil {
+		o.Answer = fmt.Sprintf("%v", val)
+	}
+	return nil
+}
@@ -303,14 +303,14 @@ func (c *activeSpacesNativeClient) DeleteByFilter(_ context.Context, collectionN
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	where, err := buildFilterSQL(filters)
+	where, residual, err := buildFilterSQL(filters)
 	if err != nil {
 		return 0, err
 	}
-	if where == "" {
+	if where == "" && residual == nil {
 		return 0, nil
 	}
-	ids, err := c.selectIDs(collectionName, where)
+	ids, err := c.selectMatchingIDs(collectionName, where, residual)
 	if err != nil {
 		return 0, err
 	}
@@ -332,7 +332,7 @@ func (c *activeSpacesNativeClient) ScrollDocuments(_ context.Context, req Scroll
 		offset, _ = strconv.Atoi(req.Offset)
 	}
 	sql := fmt.Sprintf("SELECT id, content, metadata%s FROM %s", vecSelect(req.WithVectors), req.CollectionName)
-	where, err := buildFilterSQL(req.Filters)
+	where, residual, err := buildFilterSQL(req.Filters)
 	if err != nil {
 		return nil, err
 	}
@@ -347,7 +347,9 @@ func (c *activeSpacesNativeClient) ScrollDocuments(_ context.Context, req Scroll
 	if err != nil {
 		return nil, err
 	}
-	res := &ScrollResult{Documents: docs, Total: -1}
+	// The offset counts the rows of the SQL page, so a page matched
+	// client-side may hold fewer than limit documents.
+	res := &ScrollResult{Documents: matchDocuments(docs, residual), Total: -1}
 	if len(docs) == limit {
 		res.NextOffset = strconv.Itoa(offset + limit)
 	}
@@ -360,11 +362,18 @@ func (c *activeSpacesNativeClient) CountDocuments(_ context.Context, collectionN
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	sql := "SELECT COUNT(*) AS cnt FROM " + collectionName
-	where, err := buildFilterSQL(filters)
+	where, residual, err := buildFilterSQL(filters)
 	if err != nil {
 		return 0, err
 	}
+	if residual != nil {
+		ids, err := c.selectMatchingIDs(collectionName, where, residual)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
+	sql := "SELECT COUNT(*) AS cnt FROM " + collectionName
 	if where != "" {
 		sql += " WHERE " + where
 	}
@@ -409,10 +418,12 @@ func (c *activeSpacesNativeClient) VectorSearch(_ context.Context, req SearchReq
 	sql := fmt.Sprintf("SELECT id, content, %s(%s, ?) AS score, metadata%s FROM %s",
 		fn, embeddingCol, vecSelect(req.WithVectors), req.CollectionName)
 
-	var conds []string
-	if where, err := buildFilterSQL(req.Filters); err != nil {
+	where, residual, err := buildFilterSQL(req.Filters)
+	if err != nil {
 		return nil, err
-	} else if where != "" {
+	}
+	var conds []string
+	if where != "" {
 		conds = append(conds, where)
 	}
 	if req.ScoreThreshold != 0 {
@@ -426,7 +437,7 @@ func (c *activeSpacesNativeClient) VectorSearch(_ context.Context, req SearchReq
 	if topK <= 0 {
 		topK = 10
 	}
-	sql += fmt.Sprintf(" LIMIT %d", topK)
+	sql += fmt.Sprintf(" LIMIT %d", searchLimit(topK, residual))
 
 	stmt, err := c.session.NewStatement(sql, nil)
 	if err != nil {
@@ -462,13 +473,13 @@ func (c *activeSpacesNativeClient) VectorSearch(_ context.Context, req SearchReq
 		}
 		d := rowToDocument(rc, req.WithVectors)
 		res := SearchResult{ID: d.ID, Content: d.Content, Score: asFloat64(rc["score"]), Vector: d.Vector}
-		if !req.SkipPayload {
+		if !req.SkipPayload || residual != nil {
 			res.Payload = d.Payload
 		}
 		out = append(out, res)
 		row.Destroy()
 	}
-	return out, nil
+	return matchResults(out, residual, topK, req.SkipPayload), nil
 }
 
 // HybridSearch falls back to dense VectorSearch (ActiveSpaces has no native BM25 index).
@@ -502,6 +513,27 @@ func (c *activeSpacesNativeClient) openTable(name string) (*tibdg.Table, error)
 	return t, nil
 }
 
+// selectMatchingIDs returns the IDs of the documents matching where and the
+// residual filter, which is matched against the metadata of each row.
+func (c *activeSpacesNativeClient) selectMatchingIDs(collection, where string, residual *FilterExpr) ([]string, error) {
+	if residual == nil {
+		return c.selectIDs(collection, where)
+	}
+	sql := "SELECT id, content, metadata FROM " + collection
+	if where != "" {
+		sql += " WHERE " + where
+	}
+	docs, err := c.queryDocs(sql, false)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(docs))
+	for _, d := range matchDocuments(docs, residual) {
+		ids = append(ids, d.ID)
+	}
+	return ids, nil
+}
+
 func (c *activeSpacesNativeClient) selectIDs(collection, where string) ([]string, error) {
 	stmt, err := c.session.NewStatement(fmt.Sprintf("SELECT id FROM %s WHERE %s", collection, where), nil)
 	if err != nil {
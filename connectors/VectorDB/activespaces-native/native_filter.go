@@ -44,62 +44,92 @@ func sqlLiteral(v any) string {
 	}
 }
 
-// buildFilterSQL converts the connector's provider-agnostic filter map into a SQL
-// WHERE clause over the JSON metadata column. Supported per-key forms:
+// buildFilterSQL parses the connector's filter map and converts the parts
+// ActiveSpaces SQL evaluates into a WHERE clause over the JSON metadata
+// column:
 //
 //	"field": scalar                  -> json_extract(metadata,'$.field') = scalar
 //	"field": [a, b]                   -> ... IN (a, b)
 //	"field": {"$gte": 1, "$lt": 10}   -> ... >= 1 AND ... < 10
-//	"field": {"$in": [a, b]}          -> ... IN (a, b)
+//	"field": {"$like": "a%"}          -> ... LIKE 'a%'
+//	"field": {"$exists": true}        -> ... IS NOT NULL
+//	"$or": [{...}, {...}]             -> (... OR ...)
+//
+// $ne, $nin and $not, whose SQL forms do not match documents without the
+// field, $contains and paths that are not made of identifiers are returned
+// as the residual filter, which the caller matches client-side.
 //
 // All identifiers are validated and all values are escaped (no SQL injection).
-func buildFilterSQL(filters map[string]interface{}) (string, error) {
-	if len(filters) == 0 {
-		return "", nil
+func buildFilterSQL(filters map[string]interface{}) (string, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil || expr == nil {
+		return "", nil, err
+	}
+	pushed, residual := splitFilter(expr, sqlNative)
+	if pushed == nil {
+		return "", residual, nil
 	}
-	var parts []string
-	for field, v := range filters {
-		if err := validateIdentifier(field); err != nil {
-			return "", err
+	return sqlClause(pushed), residual, nil
+}
+
+// sqlNative reports whether e only uses conditions buildFilterSQL compiles.
+func sqlNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterAnd, FilterOr:
+			return true
+		case FilterEq, FilterGt, FilterGte, FilterLt, FilterLte, FilterLike, FilterExists:
+		case FilterIn:
+			if len(n.Values()) == 0 {
+				return false
+			}
+		default:
+			return false
 		}
-		key := fmt.Sprintf("json_extract(metadata, '$.%s')", field)
-		switch val := v.(type) {
-		case map[string]interface{}:
-			for op, ov := range val {
-				lop := strings.ToLower(op)
-				if lop == "$in" || lop == "in" {
-					s, err := inClause(key, ov)
-					if err != nil {
-						return "", err
-					}
-					parts = append(parts, s)
-					continue
-				}
-				parts = append(parts, fmt.Sprintf("%s %s %s", key, sqlOp(op), sqlLiteral(ov)))
+		for _, seg := range n.Path() {
+			if !identifierRe.MatchString(seg) {
+				return false
 			}
-		case []interface{}:
-			s, err := inClause(key, val)
-			if err != nil {
-				return "", err
+		}
+		return true
+	})
+}
+
+// sqlClause builds the SQL condition for a filter accepted by sqlNative.
+func sqlClause(e *FilterExpr) string {
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		parts := make([]string, len(e.Children))
+		for i, c := range e.Children {
+			parts[i] = sqlClause(c)
+			if e.Op == FilterOr && c.Op == FilterAnd {
+				parts[i] = "(" + parts[i] + ")"
 			}
-			parts = append(parts, s)
-		default:
-			parts = append(parts, fmt.Sprintf("%s = %s", key, sqlLiteral(v)))
 		}
+		if e.Op == FilterOr {
+			return "(" + strings.Join(parts, " OR ") + ")"
+		}
+		return strings.Join(parts, " AND ")
 	}
-	return strings.Join(parts, " AND "), nil
+	key := fmt.Sprintf("json_extract(metadata, '$.%s')", e.Field)
+	switch e.Op {
+	case FilterIn:
+		return inClause(key, e.Values())
+	case FilterExists:
+		if e.Value == true {
+			return key + " IS NOT NULL"
+		}
+		return key + " IS NULL"
+	}
+	return fmt.Sprintf("%s %s %s", key, sqlOp(e.Op), sqlLiteral(e.Value))
 }
 
-func inClause(key string, v any) (string, error) {
-	arr, ok := v.([]interface{})
-	if !ok {
-		return "", newError(ErrCodeProviderError, "'in' filter requires an array value", nil)
-	}
-	items := make([]string, len(arr))
-	for i, iv := range arr {
+func inClause(key string, values []interface{}) string {
+	items := make([]string, len(values))
+	for i, iv := range values {
 		items[i] = sqlLiteral(iv)
 	}
-	return fmt.Sprintf("%s IN (%s)", key, strings.Join(items, ", ")), nil
+	return fmt.Sprintf("%s IN (%s)", key, strings.Join(items, ", "))
 }
 
 func sqlOp(op string) string {
@@ -57,6 +57,10 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	if input.ReplicationFactor <= 0 {
 		input.ReplicationFactor = 1
 	}
+	metadataFields, err := input.ToMetadataFields()
+	if err != nil {
+		return false, fmt.Errorf("vectordb-create-col: %w", err)
+	}
 
 	tc := ctx.GetTracingContext()
 	if tc != nil {
@@ -80,6 +84,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		DistanceMetric:    input.DistanceMetric,
 		OnDisk:            input.OnDisk,
 		ReplicationFactor: input.ReplicationFactor,
+		MetadataFields:    metadataFields,
 	}
 	if createErr := a.conn.GetClient().CreateCollection(opCtx, cfg); createErr != nil {
 		errMsg := createErr.Error()
@@ -98,7 +103,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	}
 
 	duration := time.Since(start)
-	l.Infof("CreateCollection: created collection=%s dims=%d duration=%s", input.CollectionName, input.Dimensions, duration)
+	l.Infof("CreateCollection: created collection=%s dims=%d metadataFields=%d duration=%s", input.CollectionName, input.Dimensions, len(metadataFields), duration)
 	if err := ctx.SetOutputObject(&Output{Success: true, Duration: duration.String()}); err != nil {
 		l.Errorf("SetOutputObject: %v", err)
 	}
@@ -0,0 +1,242 @@
+package exportCollection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-azureaisearch/connector"
+	vdbtransfer "github.com/mpandav-tibco/flogo-extensions/vectordb-azureaisearch/transfer"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data/metadata"
+	"github.com/project-flogo/core/support/log"
+)
+
+var activityMd = activity.ToMetadata(&Settings{}, &Input{}, &Output{})
+
+func init() { _ = activity.Register(&Activity{}, New) }
+
+type Activity struct {
+	settings *Settings
+	conn     *vectordbconnector.AzureAISearchConnection
+}
+
+func (a *Activity) Metadata() *activity.Metadata { return activityMd }
+
+func New(ctx activity.InitContext) (activity.Activity, error) {
+	s := &Settings{}
+	if err := metadata.MapToStruct(ctx.Settings(), s, true); err != nil {
+		return nil, fmt.Errorf("vectordb-export: %w", err)
+	}
+	if s.Connection == nil {
+		return nil, fmt.Errorf("vectordb-export: connection is required")
+	}
+	conn, ok := s.Connection.GetConnection().(*vectordbconnector.AzureAISearchConnection)
+	if !ok {
+		return nil, fmt.Errorf("vectordb-export: invalid connection type, expected *AzureAISearchConnection")
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = vdbtransfer.DefaultBatchSize
+	}
+	if s.Format == "" {
+		s.Format = "auto"
+	}
+	if _, err := vdbtransfer.ResolveFormat(".jsonl", s.Format); err != nil {
+		return nil, fmt.Errorf("vectordb-export: %w", err)
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 3600
+	}
+	ctx.Logger().Infof("ExportCollection initialised: connection=%s provider=%s batchSize=%d format=%s",
+		conn.GetName(), "azureaisearch", s.BatchSize, s.Format)
+	return &Activity{settings: s, conn: conn}, nil
+}
+
+func (a *Activity) Eval(ctx activity.Context) (bool, error) {
+	l := ctx.Logger()
+	l.Debugf("ExportCollection: starting eval")
+
+	input := &Input{}
+	if err := ctx.GetInputObject(input); err != nil {
+		return false, fmt.Errorf("vectordb-export: %w", err)
+	}
+
+	collectionName := input.CollectionName
+	if collectionName == "" {
+		collectionName = a.settings.DefaultCollection
+	}
+	if collectionName == "" {
+		return false, fmt.Errorf("vectordb-export: collectionName is required")
+	}
+	if input.FilePath == "" {
+		return false, fmt.Errorf("vectordb-export: filePath is required")
+	}
+	format, err := vdbtransfer.ResolveFormat(input.FilePath, a.settings.Format)
+	if err != nil {
+		return false, fmt.Errorf("vectordb-export: %w", err)
+	}
+	manifestPath := vdbtransfer.ManifestPath(input.FilePath)
+
+	// OTel trace tags
+	tc := ctx.GetTracingContext()
+	if tc != nil {
+		tc.SetTag("db.system", "vectordb")
+		tc.SetTag("db.operation", "exportCollection")
+		tc.SetTag("db.vectordb.provider", "azureaisearch")
+		tc.SetTag("db.vectordb.collection", collectionName)
+		tc.SetTag("db.vectordb.transfer.format", format)
+	}
+
+	start := time.Now()
+	out := &Output{Format: format, ManifestPath: manifestPath}
+	fail := func(err error) (bool, error) {
+		l.Errorf("ExportCollection: collection=%s file=%s error=%v", collectionName, input.FilePath, err)
+		if tc != nil {
+			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": err.Error()})
+		}
+		out.Success = false
+		out.Error = err.Error()
+		out.Duration = time.Since(start).String()
+		if err := ctx.SetOutputObject(out); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
+		}
+		return true, nil
+	}
+
+	manifest := &vdbtransfer.Manifest{
+		Format:         format,
+		Provider:       "azureaisearch",
+		Collection:     collectionName,
+		Filters:        input.Filters,
+		DistanceMetric: vdbtransfer.NormalizeMetric(input.DistanceMetric),
+	}
+	var prev *vdbtransfer.Manifest
+	if !input.Restart {
+		prev = resumable(l, manifestPath, input, collectionName, format)
+	}
+	var w *vdbtransfer.FileWriter
+	var cursor string
+	if prev != nil {
+		w, err = vdbtransfer.ResumeFile(input.FilePath, prev.Bytes)
+		if err != nil {
+			return fail(err)
+		}
+		manifest.Dimensions = prev.Dimensions
+		manifest.Documents = prev.Documents
+		manifest.Bytes = prev.Bytes
+		cursor = prev.Cursor
+		out.Resumed = true
+		l.Infof("ExportCollection: resuming %s after %d documents", input.FilePath, prev.Documents)
+	} else {
+		w, err = vdbtransfer.CreateFile(input.FilePath, format)
+		if err != nil {
+			return fail(err)
+		}
+		if err := vdbtransfer.WriteManifest(manifestPath, manifest); err != nil {
+			w.Close()
+			return fail(fmt.Errorf("write manifest: %w", err))
+		}
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	base := manifest.Documents
+	p, copyErr := vdbtransfer.Copy(opCtx, a.conn.GetClient(), w, vdbtransfer.CopyOptions{
+		Collection: collectionName,
+		Filters:    input.Filters,
+		BatchSize:  a.settings.BatchSize,
+		Cursor:     cursor,
+		Dimensions: manifest.Dimensions,
+		Checkpoint: func(p vdbtransfer.Progress) error {
+			if p.Complete {
+				return nil // the manifest is completed once the file is closed
+			}
+			if format == vdbtransfer.FormatJSONL {
+				size, err := w.Flush()
+				if err != nil {
+					return err
+				}
+				manifest.Bytes = size
+			}
+			manifest.Documents = base + p.Documents
+			manifest.Dimensions = p.Dimensions
+			manifest.Cursor = p.Cursor
+			l.Debugf("ExportCollection: collection=%s documents=%d cursor=%s", collectionName, manifest.Documents, p.Cursor)
+			return vdbtransfer.WriteManifest(manifestPath, manifest)
+		},
+	})
+	size, closeErr := w.Close()
+	out.ExportedCount = p.Documents
+	out.DocumentCount = base + p.Documents
+	out.Dimensions = p.Dimensions
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		out.Cursor = manifest.Cursor
+		return fail(copyErr)
+	}
+
+	manifest.Documents = base + p.Documents
+	manifest.Dimensions = p.Dimensions
+	manifest.Cursor = ""
+	manifest.Bytes = size
+	manifest.Complete = true
+	if err := vdbtransfer.WriteManifest(manifestPath, manifest); err != nil {
+		return fail(fmt.Errorf("write manifest: %w", err))
+	}
+
+	duration := time.Since(start)
+	l.Infof("ExportCollection: collection=%s file=%s documents=%d dimensions=%d duration=%s",
+		collectionName, input.FilePath, out.DocumentCount, out.Dimensions, duration)
+	if tc != nil {
+		tc.SetTag("db.vectordb.transfer.documents", out.DocumentCount)
+	}
+	out.Success = true
+	out.Complete = true
+	out.Duration = duration.String()
+	if err := ctx.SetOutputObject(out); err != nil {
+		l.Errorf("SetOutputObject: %v", err)
+	}
+	return true, nil
+}
+
+// resumable returns the manifest of an interrupted JSONL export of the same
+// collection and filters, or nil when the export has to start over.
+func resumable(l log.Logger, manifestPath string, input *Input, collection, format string) *vdbtransfer.Manifest {
+	prev, err := vdbtransfer.ReadManifest(manifestPath)
+	if err != nil {
+		l.Warnf("ExportCollection: ignoring unreadable manifest %s: %v", manifestPath, err)
+		return nil
+	}
+	if prev == nil || prev.Complete || prev.Cursor == "" {
+		return nil
+	}
+	if prev.Collection != collection || prev.Format != format || !sameFilters(prev.Filters, input.Filters) {
+		return nil
+	}
+	if format != vdbtransfer.FormatJSONL {
+		l.Warnf("ExportCollection: a Parquet export cannot be resumed, restarting %s", input.FilePath)
+		return nil
+	}
+	if fi, err := os.Stat(input.FilePath); err != nil || fi.Size() < prev.Bytes {
+		l.Warnf("ExportCollection: %s is missing or shorter than its manifest, restarting", input.FilePath)
+		return nil
+	}
+	return prev
+}
+
+// sameFilters compares two filter maps by their JSON encoding, so numbers
+// read back from a manifest (float64) match the flow's values.
+func sameFilters(a, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
@@ -0,0 +1,217 @@
+package importCollection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-azureaisearch/connector"
+	vdbtransfer "github.com/mpandav-tibco/flogo-extensions/vectordb-azureaisearch/transfer"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data/metadata"
+)
+
+var activityMd = activity.ToMetadata(&Settings{}, &Input{}, &Output{})
+
+func init() { _ = activity.Register(&Activity{}, New) }
+
+type Activity struct {
+	settings *Settings
+	conn     *vectordbconnector.AzureAISearchConnection
+}
+
+func (a *Activity) Metadata() *activity.Metadata { return activityMd }
+
+func New(ctx activity.InitContext) (activity.Activity, error) {
+	s := &Settings{}
+	if err := metadata.MapToStruct(ctx.Settings(), s, true); err != nil {
+		return nil, fmt.Errorf("vectordb-import: %w", err)
+	}
+	if s.Connection == nil {
+		return nil, fmt.Errorf("vectordb-import: connection is required")
+	}
+	conn, ok := s.Connection.GetConnection().(*vectordbconnector.AzureAISearchConnection)
+	if !ok {
+		return nil, fmt.Errorf("vectordb-import: invalid connection type, expected *AzureAISearchConnection")
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = vdbtransfer.DefaultBatchSize
+	}
+	if s.Format == "" {
+		s.Format = "auto"
+	}
+	if _, err := vdbtransfer.ResolveFormat(".jsonl", s.Format); err != nil {
+		return nil, fmt.Errorf("vectordb-import: %w", err)
+	}
+	if s.DistanceMetric == "" {
+		s.DistanceMetric = "cosine"
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 3600
+	}
+	ctx.Logger().Infof("ImportCollection initialised: connection=%s provider=%s batchSize=%d format=%s createCollection=%v verify=%v",
+		conn.GetName(), "azureaisearch", s.BatchSize, s.Format, s.CreateCollection, s.Verify)
+	return &Activity{settings: s, conn: conn}, nil
+}
+
+// checkpointPath returns the file recording the progress of importing
+// file into collection.
+func checkpointPath(file, collection string) string {
+	return file + "." + collection + ".import.json"
+}
+
+func (a *Activity) Eval(ctx activity.Context) (bool, error) {
+	l := ctx.Logger()
+	l.Debugf("ImportCollection: starting eval")
+
+	input := &Input{}
+	if err := ctx.GetInputObject(input); err != nil {
+		return false, fmt.Errorf("vectordb-import: %w", err)
+	}
+
+	collectionName := input.CollectionName
+	if collectionName == "" {
+		collectionName = a.settings.DefaultCollection
+	}
+	if collectionName == "" {
+		return false, fmt.Errorf("vectordb-import: collectionName is required")
+	}
+	if input.FilePath == "" {
+		return false, fmt.Errorf("vectordb-import: filePath is required")
+	}
+	format, err := vdbtransfer.ResolveFormat(input.FilePath, a.settings.Format)
+	if err != nil {
+		return false, fmt.Errorf("vectordb-import: %w", err)
+	}
+
+	// OTel trace tags
+	tc := ctx.GetTracingContext()
+	if tc != nil {
+		tc.SetTag("db.system", "vectordb")
+		tc.SetTag("db.operation", "importCollection")
+		tc.SetTag("db.vectordb.provider", "azureaisearch")
+		tc.SetTag("db.vectordb.collection", collectionName)
+		tc.SetTag("db.vectordb.transfer.format", format)
+	}
+
+	start := time.Now()
+	out := &Output{}
+	fail := func(err error) (bool, error) {
+		l.Errorf("ImportCollection: collection=%s file=%s error=%v", collectionName, input.FilePath, err)
+		if tc != nil {
+			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": err.Error()})
+		}
+		out.Success = false
+		out.Error = err.Error()
+		out.Duration = time.Since(start).String()
+		if err := ctx.SetOutputObject(out); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
+		}
+		return true, nil
+	}
+
+	// The manifest is optional: files produced elsewhere can be imported
+	// as long as every line / row carries a vector.
+	manifest, err := vdbtransfer.ReadManifest(vdbtransfer.ManifestPath(input.FilePath))
+	if err != nil {
+		return fail(err)
+	}
+	dims := 0
+	if manifest != nil {
+		if !manifest.Complete {
+			return fail(fmt.Errorf("export %s is incomplete (%d documents): resume the export before importing it", input.FilePath, manifest.Documents))
+		}
+		dims = manifest.Dimensions
+	}
+	if dims <= 0 {
+		if dims, err = vdbtransfer.FileDimensions(input.FilePath, format); err != nil {
+			return fail(err)
+		}
+	}
+
+	cpPath := checkpointPath(input.FilePath, collectionName)
+	var skip int64
+	if !input.Restart {
+		cp, err := vdbtransfer.ReadCheckpoint(cpPath)
+		if err != nil {
+			l.Warnf("ImportCollection: ignoring unreadable checkpoint %s: %v", cpPath, err)
+		} else if cp != nil && !cp.Complete && cp.Source == input.FilePath && cp.Target == collectionName {
+			skip = cp.Documents
+			out.Resumed = skip > 0
+			l.Infof("ImportCollection: resuming %s after %d documents", input.FilePath, skip)
+		}
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	client := a.conn.GetClient()
+	if manifest != nil && manifest.DistanceMetric != "" {
+		// An existing collection keeps its own metric, whatever the
+		// Distance Metric setting says.
+		targetMetric, err := vdbtransfer.TargetMetric(opCtx, client, collectionName, a.settings.DistanceMetric)
+		if err != nil {
+			return fail(err)
+		}
+		if err := vdbtransfer.CheckMetric(manifest.DistanceMetric, targetMetric); err != nil {
+			return fail(err)
+		}
+	}
+	if dims > 0 {
+		out.Created, err = vdbtransfer.PrepareTarget(opCtx, client, collectionName, dims, a.settings.DistanceMetric, a.settings.CreateCollection)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	r, err := vdbtransfer.OpenFile(input.FilePath, format)
+	if err != nil {
+		return fail(err)
+	}
+	defer r.Close()
+	if err := r.Skip(skip); err != nil {
+		return fail(fmt.Errorf("skip %d imported documents: %w", skip, err))
+	}
+	cp := &vdbtransfer.Checkpoint{Source: input.FilePath, Target: collectionName, Documents: skip, Dimensions: dims}
+	p, copyErr := vdbtransfer.CopyFile(opCtx, r, &vdbtransfer.ClientSink{Client: client, Collection: collectionName}, a.settings.BatchSize, dims,
+		func(p vdbtransfer.Progress) error {
+			cp.Documents = skip + p.Documents
+			cp.Dimensions = p.Dimensions
+			cp.Complete = p.Complete
+			l.Debugf("ImportCollection: collection=%s documents=%d", collectionName, cp.Documents)
+			return vdbtransfer.WriteCheckpoint(cpPath, cp)
+		})
+	out.ImportedCount = p.Documents
+	out.DocumentCount = skip + p.Documents
+	out.Dimensions = p.Dimensions
+	if copyErr != nil {
+		return fail(copyErr)
+	}
+
+	if a.settings.Verify {
+		out.ExpectedCount = out.DocumentCount
+		if manifest != nil {
+			out.ExpectedCount = manifest.Documents
+		}
+		v := vdbtransfer.Verify(opCtx, client, collectionName, out.ExpectedCount)
+		out.Verified, out.TargetCount, out.VerificationError = v.Verified, v.Actual, v.Error
+		if !v.Verified {
+			l.Warnf("ImportCollection: verification failed: %s", v.Error)
+		}
+	}
+
+	duration := time.Since(start)
+	l.Infof("ImportCollection: collection=%s file=%s documents=%d created=%v verified=%v duration=%s",
+		collectionName, input.FilePath, out.DocumentCount, out.Created, out.Verified, duration)
+	if tc != nil {
+		tc.SetTag("db.vectordb.transfer.documents", out.DocumentCount)
+		tc.SetTag("db.vectordb.transfer.verified", out.Verified)
+	}
+	out.Success = true
+	out.Duration = duration.String()
+	if err := ctx.SetOutputObject(out); err != nil {
+		l.Errorf("SetOutputObject: %v", err)
+	}
+	return true, nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 // ChunkStrategy selects the text-splitting algorithm.
@@ -16,6 +18,13 @@ const (
 	ChunkStrategyHeading   ChunkStrategy = "heading"
 )
 
+// Chunk linkage fields, read by ragQuery context expansion.
+const (
+	parentIDKey   = "parentId"
+	chunkIndexKey = "chunkIndex"
+	chunkCountKey = "chunkCount"
+)
+
 // ChunkConfig holds the resolved chunking parameters derived from Settings.
 type ChunkConfig struct {
 	Strategy ChunkStrategy
@@ -49,36 +58,64 @@ const maxEmbeddingInputChars = 3500
 func expandChunks(docs []RawDocument, cfg ChunkConfig) []RawDocument {
 	var result []RawDocument
 	for _, doc := range docs {
-		chunks := chunkText(doc.Text, cfg)
-
-		var capped []string
-		for _, c := range chunks {
-			if len([]rune(c)) > maxEmbeddingInputChars {
-				capped = append(capped, chunkFixed(c, maxEmbeddingInputChars, 0)...)
-			} else {
-				capped = append(capped, c)
+		sections := doc.Sections
+		if len(sections) == 0 {
+			sections = []DocumentSection{{Text: doc.Text}}
+		}
+
+		type sectionChunk struct {
+			text string
+			meta map[string]interface{}
+		}
+		var chunks []sectionChunk
+		for _, sec := range sections {
+			for _, c := range chunkText(sec.Text, cfg) {
+				if len([]rune(c)) > maxEmbeddingInputChars {
+					for _, sub := range chunkFixed(c, maxEmbeddingInputChars, 0) {
+						chunks = append(chunks, sectionChunk{sub, sec.Metadata})
+					}
+				} else {
+					chunks = append(chunks, sectionChunk{c, sec.Metadata})
+				}
 			}
 		}
-		chunks = capped
+
+		var trail headingTrail
 		total := len(chunks)
+		parentID := doc.ID
+		if parentID == "" {
+			parentID, _ = doc.Metadata[ingestSourceKey].(string)
+		}
+		if parentID == "" {
+			parentID = uuid.NewString()
+		}
 		for i, chunk := range chunks {
 			chunkID := ""
 			if doc.ID != "" {
 				chunkID = fmt.Sprintf("%s-chunk-%d", doc.ID, i)
 			}
 
-			meta := make(map[string]interface{}, len(doc.Metadata)+4)
+			meta := make(map[string]interface{}, len(doc.Metadata)+len(chunk.meta)+8)
 			for k, v := range doc.Metadata {
 				meta[k] = v
 			}
+			for k, v := range chunk.meta {
+				meta[k] = v
+			}
+			if path := trail.advance(chunk.text); path != "" {
+				meta["section_path"] = path
+			}
 			meta["_source_id"] = doc.ID
 			meta["_chunk_index"] = i
 			meta["_chunk_total"] = total
 			meta["_chunk_strategy"] = string(cfg.Strategy)
+			meta[parentIDKey] = parentID
+			meta[chunkIndexKey] = i
+			meta[chunkCountKey] = total
 
 			result = append(result, RawDocument{
 				ID:       chunkID,
-				Text:     chunk,
+				Text:     chunk.text,
 				Metadata: meta,
 			})
 		}
@@ -86,6 +123,49 @@ func expandChunks(docs []RawDocument, cfg ChunkConfig) []RawDocument {
 	return result
 }
 
+var headingLineRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+type headingTrail struct {
+	titles [6]string
+}
+
+func (t *headingTrail) advance(chunk string) string {
+	var path string
+	leading := true
+	for _, line := range strings.Split(chunk, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		m := headingLineRe.FindStringSubmatch(line)
+		if m == nil {
+			if leading {
+				path, leading = t.path(), false
+			}
+			continue
+		}
+		level := len(m[1])
+		t.titles[level-1] = m[2]
+		for j := level; j < len(t.titles); j++ {
+			t.titles[j] = ""
+		}
+	}
+	if leading {
+		path = t.path()
+	}
+	return path
+}
+
+func (t *headingTrail) path() string {
+	var parts []string
+	for _, title := range t.titles {
+		if title != "" {
+			parts = append(parts, title)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
 func chunkText(text string, cfg ChunkConfig) []string {
 	text = strings.TrimSpace(text)
 	if text == "" {
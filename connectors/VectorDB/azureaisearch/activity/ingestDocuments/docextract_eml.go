@@ -0,0 +1,187 @@
+package ingestDocuments
+
+// docextract_eml.go — E-mail (.eml, RFC 5322 / MIME) extraction using stdlib
+// net/mail, mime and mime/multipart.
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const emlMaxDepth = 10
+
+func extractEML(data []byte) (*ExtractedDocument, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("eml: cannot parse message: %w", err)
+	}
+
+	meta := make(map[string]interface{})
+	var lines []string
+	subject := decodeMIMEHeader(msg.Header.Get("Subject"))
+	if subject != "" {
+		lines = append(lines, "# "+subject)
+		meta["email_subject"] = subject
+	}
+	for _, h := range []struct{ name, key string }{
+		{"From", "email_from"}, {"To", "email_to"}, {"Cc", "email_cc"},
+	} {
+		if v := decodeMIMEHeader(msg.Header.Get(h.name)); v != "" {
+			lines = append(lines, h.name+": "+v)
+			meta[h.key] = v
+		}
+	}
+	if d := msg.Header.Get("Date"); d != "" {
+		if t, err := mail.ParseDate(d); err == nil {
+			d = t.UTC().Format(time.RFC3339)
+		}
+		lines = append(lines, "Date: "+d)
+		meta["email_date"] = d
+	}
+
+	var body emlBody
+	if err := body.walk(textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		return nil, fmt.Errorf("eml: %w", err)
+	}
+	if len(body.attachments) > 0 {
+		lines = append(lines, "Attachments: "+strings.Join(body.attachments, ", "))
+	}
+
+	text := body.plain
+	if strings.TrimSpace(text) == "" && body.html != "" {
+		h, err := extractHTML([]byte(body.html))
+		if err != nil {
+			return nil, fmt.Errorf("eml: html body: %w", err)
+		}
+		text = h.Text()
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	doc := singleSection(strings.Join(lines, "\n") + "\n\n" + strings.TrimSpace(text))
+	doc.Metadata = meta
+	return doc, nil
+}
+
+type emlBody struct {
+	plain       string
+	html        string
+	attachments []string
+}
+
+func (b *emlBody) walk(header textproto.MIMEHeader, r io.Reader, depth int) error {
+	ctype := header.Get("Content-Type")
+	if ctype == "" {
+		ctype = "text/plain; charset=us-ascii"
+	}
+	mediaType, params, err := mime.ParseMediaType(ctype)
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= emlMaxDepth || params["boundary"] == "" {
+			return nil
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("malformed multipart body: %w", err)
+			}
+			if err := b.walk(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := decodeMIMEHeader(dparams["filename"])
+	if filename == "" {
+		filename = decodeMIMEHeader(params["name"])
+	}
+	if disposition == "attachment" || (filename != "" && !strings.HasPrefix(mediaType, "text/")) {
+		if filename == "" {
+			filename = mediaType
+		}
+		b.attachments = append(b.attachments, filename)
+		return nil
+	}
+	if mediaType == "message/rfc822" && depth < emlMaxDepth {
+		inner, err := mail.ReadMessage(r)
+		if err != nil {
+			return nil
+		}
+		return b.walk(textproto.MIMEHeader(inner.Header), inner.Body, depth+1)
+	}
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return nil
+	}
+
+	raw, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), r))
+	if err != nil {
+		return fmt.Errorf("cannot read %s part: %w", mediaType, err)
+	}
+	text := decodeCharset(raw, params["charset"])
+	if mediaType == "text/plain" && b.plain == "" {
+		b.plain = text
+	} else if mediaType == "text/html" && b.html == "" {
+		b.html = text
+	}
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+func decodeCharset(raw []byte, charset string) string {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "iso-8859-1", "iso8859-1", "latin1", "windows-1252", "cp1252":
+	default:
+		if utf8.Valid(raw) {
+			return string(raw)
+		}
+	}
+	runes := make([]rune, len(raw))
+	for i, c := range raw {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+var emlWordDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		raw, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(decodeCharset(raw, charset)), nil
+	},
+}
+
+func decodeMIMEHeader(v string) string {
+	if d, err := emlWordDecoder.DecodeHeader(v); err == nil {
+		v = d
+	}
+	return strings.Join(strings.Fields(v), " ")
+}
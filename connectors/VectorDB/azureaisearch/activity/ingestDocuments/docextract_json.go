@@ -0,0 +1,151 @@
+package ingestDocuments
+
+// docextract_json.go — JSON (.json) and JSON Lines (.jsonl / .ndjson)
+// extraction using stdlib encoding/json.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var jsonTitleKeys = []string{"title", "name", "subject", "id"}
+
+func extractJSON(data []byte) (*ExtractedDocument, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	first, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("json: parse error: %w", err)
+	}
+	if d, ok := first.(json.Delim); ok && d == '[' {
+		doc := &ExtractedDocument{}
+		for n := 1; dec.More(); n++ {
+			var lines []string
+			if err := flattenJSON(dec, "", &lines); err != nil {
+				return nil, fmt.Errorf("json: record %d: %w", n, err)
+			}
+			doc.Sections = appendRecord(doc.Sections, n, lines)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("json: parse error: %w", err)
+		}
+		return doc, nil
+	}
+
+	var lines []string
+	if err := flattenJSONValue(dec, first, "", &lines); err != nil {
+		return nil, fmt.Errorf("json: parse error: %w", err)
+	}
+	return singleSection(strings.Join(lines, "\n")), nil
+}
+
+func extractJSONLines(data []byte) (*ExtractedDocument, error) {
+	doc := &ExtractedDocument{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), len(data)+1)
+	n := 0
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n++
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var lines []string
+		if err := flattenJSON(dec, "", &lines); err != nil {
+			return nil, fmt.Errorf("jsonl: line %d: %w", lineNo, err)
+		}
+		doc.Sections = appendRecord(doc.Sections, n, lines)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("jsonl: read error: %w", err)
+	}
+	return doc, nil
+}
+
+func appendRecord(sections []DocumentSection, n int, lines []string) []DocumentSection {
+	if len(lines) == 0 {
+		return sections
+	}
+	heading := fmt.Sprintf("## Record %d", n)
+	for _, key := range jsonTitleKeys {
+		if v, ok := lookupLine(lines, key); ok && v != "" {
+			heading += ": " + v
+			break
+		}
+	}
+	return append(sections, DocumentSection{
+		Text:     heading + "\n" + strings.Join(lines, "\n"),
+		Metadata: map[string]interface{}{"record": n},
+	})
+}
+
+func lookupLine(lines []string, key string) (string, bool) {
+	prefix := key + ": "
+	for _, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			return strings.TrimPrefix(l, prefix), true
+		}
+	}
+	return "", false
+}
+
+func flattenJSON(dec *json.Decoder, path string, lines *[]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	return flattenJSONValue(dec, tok, path, lines)
+}
+
+func flattenJSONValue(dec *json.Decoder, tok json.Token, path string, lines *[]string) error {
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := kt.(string)
+				child := key
+				if path != "" {
+					child = path + "." + key
+				}
+				if err := flattenJSON(dec, child, lines); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; dec.More(); i++ {
+				if err := flattenJSON(dec, fmt.Sprintf("%s[%d]", path, i), lines); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("unexpected %q", v)
+		}
+		_, err := dec.Token() // closing delimiter
+		return err
+	case nil:
+		return nil // null carries no text
+	default:
+		s := strings.Join(strings.Fields(fmt.Sprint(v)), " ")
+		if s == "" {
+			return nil
+		}
+		if path == "" {
+			*lines = append(*lines, s)
+		} else {
+			*lines = append(*lines, path+": "+s)
+		}
+		return nil
+	}
+}
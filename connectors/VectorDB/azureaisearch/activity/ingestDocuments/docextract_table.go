@@ -0,0 +1,76 @@
+package ingestDocuments
+
+// docextract_table.go — Delimited text (.csv / .tsv) extraction and the row
+// rendering shared with .xlsx worksheets.
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func extractDelimited(data []byte, sep rune) (*ExtractedDocument, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.Comma = sep
+	r.FieldsPerRecord = -1 // ragged rows are common in exports
+	r.LazyQuotes = true
+
+	var (
+		rows    [][]string
+		rowNums []int
+	)
+	for n := 1; ; n++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: parse error: %w", err)
+		}
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		rows = append(rows, rec)
+		rowNums = append(rowNums, n)
+	}
+	return singleSection(renderTable(rows, rowNums)), nil
+}
+
+func renderTable(rows [][]string, rowNums []int) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	header := make([]string, len(rows[0]))
+	var cols []string
+	for i, h := range rows[0] {
+		header[i] = strings.Join(strings.Fields(h), " ")
+		if header[i] != "" {
+			cols = append(cols, header[i])
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	if len(cols) > 0 {
+		lines = append(lines, "Columns: "+strings.Join(cols, " | "))
+	}
+	for i, row := range rows[1:] {
+		var fields []string
+		for c, v := range row {
+			v = strings.Join(strings.Fields(v), " ")
+			if v == "" {
+				continue
+			}
+			name := columnName(c)
+			if c < len(header) && header[c] != "" {
+				name = header[c]
+			}
+			fields = append(fields, name+": "+v)
+		}
+		if len(fields) > 0 {
+			lines = append(lines, fmt.Sprintf("Row %d: %s", rowNums[i+1], strings.Join(fields, " | ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -24,6 +24,12 @@ type Settings struct {
 	ChunkStrategy         string             `md:"chunkStrategy"`
 	ChunkSize             int                `md:"chunkSize"`
 	ChunkOverlap          int                `md:"chunkOverlap"`
+	// EmbeddingCache is "none" (default), "memory" or "disk".
+	EmbeddingCache     string `md:"embeddingCache"`
+	EmbeddingCacheSize int    `md:"embeddingCacheSize"`
+	EmbeddingCacheDir  string `md:"embeddingCacheDir"`
+	// IngestMode is "full" (default) or "incremental"; see incremental.go.
+	IngestMode string `md:"ingestMode"`
 }
 
 // Input holds the runtime inputs for an ingest operation.
@@ -68,6 +74,7 @@ type RawDocument struct {
 	ID       string
 	Text     string
 	Metadata map[string]interface{}
+	Sections []DocumentSection
 }
 
 // parseDocuments converts []interface{} input into typed RawDocument slice.
@@ -126,6 +133,9 @@ type Output struct {
 	Error               string   `md:"error"`
 	SourceDocumentCount int      `md:"sourceDocumentCount"`
 	ChunksCreated       int      `md:"chunksCreated"`
+	SkippedCount        int      `md:"skippedCount"`
+	DeletedCount        int      `md:"deletedCount"`
+	CacheHits           int      `md:"cacheHits"`
 }
 
 func (o *Output) ToMap() map[string]interface{} {
@@ -138,6 +148,9 @@ func (o *Output) ToMap() map[string]interface{} {
 		"error":               o.Error,
 		"sourceDocumentCount": o.SourceDocumentCount,
 		"chunksCreated":       o.ChunksCreated,
+		"skippedCount":        o.SkippedCount,
+		"deletedCount":        o.DeletedCount,
+		"cacheHits":           o.CacheHits,
 	}
 }
 
@@ -166,5 +179,14 @@ func (o *Output) FromMap(v map[string]interface{}) error {
 	if val, ok := v["chunksCreated"].(int); ok {
 		o.ChunksCreated = val
 	}
+	if val, ok := v["skippedCount"].(int); ok {
+		o.SkippedCount = val
+	}
+	if val, ok := v["deletedCount"].(int); ok {
+		o.DeletedCount = val
+	}
+	if val, ok := v["cacheHits"].(int); ok {
+		o.CacheHits = val
+	}
 	return nil
 }
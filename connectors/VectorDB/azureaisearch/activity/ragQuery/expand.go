@@ -0,0 +1,332 @@
+package ragQuery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	vectordb "github.com/mpandav-tibco/flogo-extensions/vectordb-azureaisearch"
+)
+
+// Context expansion modes for the contextExpansion setting.
+const (
+	expansionNone      = "none"      // use the retrieved chunks as they are
+	expansionNeighbors = "neighbors" // add the chunks around each retrieved chunk
+	expansionParent    = "parent"    // use the retrieved chunk's whole section
+)
+
+// Chunk linkage payload keys written by ingestDocuments.
+const (
+	parentIDKey    = "parentId"
+	chunkIndexKey  = "chunkIndex"
+	chunkCountKey  = "chunkCount"
+	sectionPathKey = "section_path"
+)
+
+const (
+	defaultNeighborChunks = 1
+	// expansionScrollPage is the page size used to read a window's chunks.
+	expansionScrollPage = 100
+	// maxExpansionChunks caps the chunks read for one window, so parent
+	// expansion stays bounded on very long documents.
+	maxExpansionChunks = 500
+	// minChunkOverlap is the shortest repeated text between consecutive
+	// chunks that is removed when they are joined; shorter matches are
+	// treated as coincidence.
+	minChunkOverlap = 16
+	// charsPerToken is the rough characters-per-token ratio used to estimate
+	// the size of the context against contextTokenBudget.
+	charsPerToken = 4
+)
+
+// expansionConfig holds the context expansion settings.
+type expansionConfig struct {
+	Mode         string
+	Neighbors    int
+	TokenBudget  int // 0 = unlimited
+	ContentField string
+}
+
+// chunkWindow is a run of chunks of one parent document built from one or
+// more retrieved chunks.
+type chunkWindow struct {
+	best   vectordb.SearchResult // highest-scoring retrieved chunk
+	rank   int                   // position of the first retrieved chunk
+	hits   int                   // number of retrieved chunks merged
+	linked bool                  // false when the result has no chunk linkage
+
+	parentID string
+	whole    bool   // parent mode: every chunk of the section or document
+	section  string // section_path of the window (parent mode)
+	lo, hi   int    // chunk index range (neighbors mode)
+}
+
+// expandContext implements "small-to-big" retrieval: each retrieved chunk is
+// replaced with a larger window of its parent document, read with
+// ScrollDocuments on the parentId / chunkIndex payload keys. In neighbors
+// mode the window holds cfg.Neighbors chunks on each side, in parent mode
+// every chunk of the same section_path (the whole document when the chunk
+// has none). Windows of one parent that overlap or touch are merged; the
+// merged result keeps the ID, score and payload of its best chunk and the
+// position of its first. Results without linkage are kept as they are.
+//
+// With a token budget, results are taken in order while their estimated
+// size fits: a window that does not fit falls back to its best chunk alone,
+// and the first result that does not fit even so ends the context.
+// A window that cannot be read also falls back to its best chunk; the first
+// such error is returned with the results.
+func expandContext(ctx context.Context, client vectordb.VectorDBClient, collection string, results []vectordb.SearchResult, cfg expansionConfig) ([]vectordb.SearchResult, error) {
+	var firstErr error
+	out := make([]vectordb.SearchResult, 0, len(results))
+	used := 0
+	for _, w := range buildWindows(results, cfg) {
+		r := w.best
+		if w.linked {
+			expanded, err := fetchWindow(ctx, client, collection, w, cfg.ContentField)
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("parent %s: %w", w.parentID, err)
+			}
+			if expanded != nil {
+				r = *expanded
+			}
+		}
+		if cfg.TokenBudget > 0 {
+			tokens := estimateTokens(extractContent(r, cfg.ContentField))
+			if used+tokens > cfg.TokenBudget {
+				r = w.best
+				tokens = estimateTokens(extractContent(r, cfg.ContentField))
+				if used+tokens > cfg.TokenBudget {
+					break
+				}
+			}
+			used += tokens
+		}
+		out = append(out, r)
+	}
+	return out, firstErr
+}
+
+// buildWindows turns the ranked results into chunk windows, merging the
+// windows of one parent (and section) that overlap or touch, ordered by the
+// rank of their first retrieved chunk.
+func buildWindows(results []vectordb.SearchResult, cfg expansionConfig) []*chunkWindow {
+	var windows []*chunkWindow
+	groups := make(map[string][]*chunkWindow)
+	var keys []string
+	for rank, r := range results {
+		w := &chunkWindow{best: r, rank: rank, hits: 1}
+		parentID, index, count, ok := chunkLinkage(r)
+		if !ok || cfg.Mode == expansionNone {
+			windows = append(windows, w)
+			continue
+		}
+		w.linked = true
+		w.parentID = parentID
+		if cfg.Mode == expansionParent {
+			w.whole = true
+			w.section, _ = r.Payload[sectionPathKey].(string)
+		} else {
+			w.lo, w.hi = index-cfg.Neighbors, index+cfg.Neighbors
+			if w.lo < 0 {
+				w.lo = 0
+			}
+			if count > 0 && w.hi > count-1 {
+				w.hi = count - 1
+			}
+		}
+		key := w.parentID + "\x00" + w.section
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], w)
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].lo < group[j].lo })
+		cur := group[0]
+		for _, w := range group[1:] {
+			if cur.whole || w.lo <= cur.hi+1 {
+				cur.merge(w)
+				continue
+			}
+			windows = append(windows, cur)
+			cur = w
+		}
+		windows = append(windows, cur)
+	}
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].rank < windows[j].rank })
+	return windows
+}
+
+// merge widens w to cover o.
+func (w *chunkWindow) merge(o *chunkWindow) {
+	if o.hi > w.hi {
+		w.hi = o.hi
+	}
+	if o.best.Score > w.best.Score || (o.best.Score == w.best.Score && o.rank < w.rank) {
+		w.best = o.best
+	}
+	if o.rank < w.rank {
+		w.rank = o.rank
+	}
+	w.hits += o.hits
+}
+
+// fetchWindow reads the chunks of w and joins them in chunk order. It
+// returns nil when no chunk of the window was found.
+func fetchWindow(ctx context.Context, client vectordb.VectorDBClient, collection string, w *chunkWindow, contentField string) (*vectordb.SearchResult, error) {
+	filters := map[string]interface{}{parentIDKey: w.parentID}
+	if w.section != "" {
+		filters[sectionPathKey] = w.section
+	}
+	if !w.whole {
+		filters[chunkIndexKey] = map[string]interface{}{"$gte": w.lo, "$lte": w.hi}
+	}
+
+	type chunk struct {
+		index int
+		text  string
+	}
+	var chunks []chunk
+	seen := make(map[int]bool)
+	visited := make(map[string]bool)
+	offset := ""
+	for len(chunks) < maxExpansionChunks {
+		visited[offset] = true
+		page, err := client.ScrollDocuments(ctx, vectordb.ScrollRequest{
+			CollectionName: collection,
+			Limit:          expansionScrollPage,
+			Offset:         offset,
+			Filters:        filters,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range page.Documents {
+			index, ok := payloadInt(d.Payload[chunkIndexKey])
+			if !ok || seen[index] {
+				continue
+			}
+			seen[index] = true
+			chunks = append(chunks, chunk{index, extractContent(vectordb.SearchResult{Content: d.Content, Payload: d.Payload}, contentField)})
+		}
+		// A provider that matches part of the filter client-side can return an
+		// empty page mid-scroll; stop at the end of the scroll or when the
+		// offset repeats.
+		if page.NextOffset == "" || visited[page.NextOffset] {
+			break
+		}
+		offset = page.NextOffset
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].index < chunks[j].index })
+
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.text
+	}
+	r := w.best
+	r.Content = joinChunks(texts)
+	r.Payload = make(map[string]interface{}, len(w.best.Payload)+3)
+	for k, v := range w.best.Payload {
+		r.Payload[k] = v
+	}
+	r.Payload["expandedChunkStart"] = chunks[0].index
+	r.Payload["expandedChunkEnd"] = chunks[len(chunks)-1].index
+	r.Payload["expandedHits"] = w.hits
+	return &r, nil
+}
+
+// chunkLinkage reads the parentId, chunkIndex and (optional) chunkCount
+// payload keys of a result.
+func chunkLinkage(r vectordb.SearchResult) (parentID string, index, count int, ok bool) {
+	parentID, _ = r.Payload[parentIDKey].(string)
+	if parentID == "" {
+		return "", 0, 0, false
+	}
+	index, ok = payloadInt(r.Payload[chunkIndexKey])
+	if !ok || index < 0 {
+		return "", 0, 0, false
+	}
+	count, _ = payloadInt(r.Payload[chunkCountKey])
+	return parentID, index, count, true
+}
+
+// payloadInt converts a numeric payload value, which providers may return as
+// any JSON number type, to an int.
+func payloadInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float32:
+		return int(n), float32(int(n)) == n
+	case float64:
+		return int(n), float64(int(n)) == n
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	}
+	return 0, false
+}
+
+// joinChunks concatenates consecutive chunks, dropping the text a chunk
+// repeats from the previous one (fixed-size chunking with overlap) and
+// separating chunks without overlap by a blank line.
+func joinChunks(texts []string) string {
+	var sb strings.Builder
+	for i, t := range texts {
+		if i > 0 {
+			if n := chunkOverlap(texts[i-1], t); n > 0 {
+				t = t[n:]
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(t)
+	}
+	return sb.String()
+}
+
+// chunkOverlap returns the length in bytes of the longest prefix of next that
+// ends prev, or 0 when it is shorter than minChunkOverlap. It runs the KMP
+// prefix function over next + "\x00" + the tail of prev.
+func chunkOverlap(prev, next string) int {
+	m := len(next)
+	if len(prev) < m {
+		m = len(prev)
+	}
+	if m < minChunkOverlap {
+		return 0
+	}
+	s := next[:m] + "\x00" + prev[len(prev)-m:]
+	pi := make([]int, len(s))
+	for i := 1; i < len(s); i++ {
+		k := pi[i-1]
+		for k > 0 && s[i] != s[k] {
+			k = pi[k-1]
+		}
+		if s[i] == s[k] {
+			k++
+		}
+		pi[i] = k
+	}
+	if n := pi[len(s)-1]; n >= minChunkOverlap {
+		return n
+	}
+	return 0
+}
+
+// estimateTokens approximates the token count of text.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
@@ -31,6 +31,24 @@ type Settings struct {
 	SystemPrompt          string             `md:"systemPrompt"`
 	MaxTokens             int                `md:"maxTokens"`
 	Temperature           float64            `md:"temperature"`
+	LLMAPIVersion         string             `md:"llmAPIVersion"`
+	EnableCitations       bool               `md:"enableCitations"`
+	EnableStreaming       bool               `md:"enableStreaming"`
+	SSEServerRef          string             `md:"sseServerRef"`
+	RetrievalMode         string             `md:"retrievalMode"`
+	QueryVariants         int                `md:"queryVariants"`
+	EnableQueryRewrite    bool               `md:"enableQueryRewrite"`
+	EnableMMR             bool               `md:"enableMMR"`
+	MMRLambda             float64            `md:"mmrLambda"`
+	MMRFetchK             int                `md:"mmrFetchK"`
+	ContextExpansion      string             `md:"contextExpansion"`
+	NeighborChunks        int                `md:"neighborChunks"`
+	ContextTokenBudget    int                `md:"contextTokenBudget"`
+
+	EnableSemanticCache      bool    `md:"enableSemanticCache"`
+	CacheCollection          string  `md:"cacheCollection"`
+	CacheSimilarityThreshold float64 `md:"cacheSimilarityThreshold"`
+	CacheTTLSeconds          int     `md:"cacheTTLSeconds"`
 }
 
 func (s Settings) String() string {
@@ -52,20 +70,26 @@ func (s Settings) String() string {
 
 // Input holds runtime data for the activity.
 type Input struct {
-	QueryText      string                 `md:"queryText"`
-	CollectionName string                 `md:"collectionName"`
-	TopK           int                    `md:"topK"`
-	Filters        map[string]interface{} `md:"filters"`
-	SystemPrompt   string                 `md:"systemPrompt"`
+	QueryText          string                 `md:"queryText"`
+	CollectionName     string                 `md:"collectionName"`
+	TopK               int                    `md:"topK"`
+	Filters            map[string]interface{} `md:"filters"`
+	SystemPrompt       string                 `md:"systemPrompt"`
+	StreamConnectionID string                 `md:"streamConnectionId"`
+	StreamTopic        string                 `md:"streamTopic"`
+	ChatHistory        []interface{}          `md:"chatHistory"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"queryText":      i.QueryText,
-		"collectionName": i.CollectionName,
-		"topK":           i.TopK,
-		"filters":        i.Filters,
-		"systemPrompt":   i.SystemPrompt,
+		"queryText":          i.QueryText,
+		"collectionName":     i.CollectionName,
+		"topK":               i.TopK,
+		"filters":            i.Filters,
+		"systemPrompt":       i.SystemPrompt,
+		"streamConnectionId": i.StreamConnectionID,
+		"streamTopic":        i.StreamTopic,
+		"chatHistory":        i.ChatHistory,
 	}
 }
 
@@ -92,31 +116,54 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 	if val, ok := v["systemPrompt"]; ok && val != nil {
 		i.SystemPrompt = fmt.Sprintf("%v", val)
 	}
+	if val, ok := v["streamConnectionId"]; ok && val != nil {
+		i.StreamConnectionID = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["streamTopic"]; ok && val != nil {
+		i.StreamTopic = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["chatHistory"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.ChatHistory = arr
+		}
+	}
 	return nil
 }
 
 // Output holds the activity result.
 type Output struct {
-	Success          bool          `md:"success"`
-	Answer           string        `md:"answer"`
-	FormattedContext string        `md:"formattedContext"`
-	SourceDocuments  []interface{} `md:"sourceDocuments"`
-	QueryEmbedding   []interface{} `md:"queryEmbedding"`
-	TotalFound       int           `md:"totalFound"`
-	Duration         string        `md:"duration"`
-	Error            string        `md:"error"`
+	Success              bool          `md:"success"`
+	Answer               string        `md:"answer"`
+	FormattedContext     string        `md:"formattedContext"`
+	SourceDocuments      []interface{} `md:"sourceDocuments"`
+	QueryEmbedding       []interface{} `md:"queryEmbedding"`
+	TotalFound           int           `md:"totalFound"`
+	Duration             string        `md:"duration"`
+	Error                string        `md:"error"`
+	Citations            []interface{} `md:"citations"`
+	StreamedTokens       int           `md:"streamedTokens"`
+	RewrittenQuery       string        `md:"rewrittenQuery"`
+	QueryVariants        []interface{} `md:"queryVariants"`
+	HypotheticalDocument string        `md:"hypotheticalDocument"`
+	CacheHit             bool          `md:"cacheHit"`
 }
 
 func (o *Output) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"success":          o.Success,
-		"answer":           o.Answer,
-		"formattedContext": o.FormattedContext,
-		"sourceDocuments":  o.SourceDocuments,
-		"queryEmbedding":   o.QueryEmbedding,
-		"totalFound":       o.TotalFound,
-		"duration":         o.Duration,
-		"error":            o.Error,
+		"success":              o.Success,
+		"answer":               o.Answer,
+		"formattedContext":     o.FormattedContext,
+		"sourceDocuments":      o.SourceDocuments,
+		"queryEmbedding":       o.QueryEmbedding,
+		"totalFound":           o.TotalFound,
+		"duration":             o.Duration,
+		"error":                o.Error,
+		"citations":            o.Citations,
+		"streamedTokens":       o.StreamedTokens,
+		"rewrittenQuery":       o.RewrittenQuery,
+		"queryVariants":        o.QueryVariants,
+		"hypotheticalDocument": o.HypotheticalDocument,
+		"cacheHit":             o.CacheHit,
 	}
 }
 
@@ -67,8 +67,17 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		topK = 10
 	}
 
-	l.Debugf("VectorSearch: collection=%s dims=%d topK=%d scoreThreshold=%.3f hasFilters=%v",
-		collectionName, len(input.QueryVector), topK, input.ScoreThreshold, len(input.Filters) > 0)
+	// MMR over-fetches candidates with their vectors and re-selects a diverse topK.
+	searchTopK := topK
+	if input.MMR {
+		if input.MMRLambda < 0 || input.MMRLambda > 1 {
+			return false, fmt.Errorf("vectordb-search: mmrLambda must be between 0.0 and 1.0, got %.4f", input.MMRLambda)
+		}
+		searchTopK = vectordb.MMRFetchK(topK, input.MMRFetchK)
+	}
+
+	l.Debugf("VectorSearch: collection=%s dims=%d topK=%d scoreThreshold=%.3f hasFilters=%v mmr=%v",
+		collectionName, len(input.QueryVector), topK, input.ScoreThreshold, len(input.Filters) > 0, input.MMR)
 
 	tc := ctx.GetTracingContext()
 	if tc != nil {
@@ -91,10 +100,10 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	results, searchErr := a.conn.GetClient().VectorSearch(opCtx, vectordb.SearchRequest{
 		CollectionName: collectionName,
 		QueryVector:    input.QueryVector,
-		TopK:           topK,
+		TopK:           searchTopK,
 		ScoreThreshold: input.ScoreThreshold,
 		Filters:        input.Filters,
-		WithVectors:    input.WithVectors,
+		WithVectors:    input.WithVectors || input.MMR,
 		SkipPayload:    input.SkipPayload,
 	})
 	if searchErr != nil {
@@ -109,6 +118,16 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		return true, nil
 	}
 
+	if input.MMR {
+		candidates := len(results)
+		var mmrErr error
+		results, mmrErr = vectordb.DiversifyMMR(opCtx, a.conn.GetClient(), collectionName, input.QueryVector, results, topK, input.MMRLambda, input.WithVectors)
+		if mmrErr != nil {
+			l.Warnf("VectorSearch: MMR could not read every candidate vector: %v", mmrErr)
+		}
+		l.Debugf("VectorSearch: MMR selected %d of %d candidates lambda=%.2f", len(results), candidates, input.MMRLambda)
+	}
+
 	duration := time.Since(start)
 	l.Debugf("VectorSearch: collection=%s results=%d duration=%s", collectionName, len(results), duration)
 	if tc != nil {
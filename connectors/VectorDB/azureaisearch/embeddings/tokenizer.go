@@ -0,0 +1,531 @@
+package vdbembed
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tokenizer is a pure-Go reader for the Hugging Face tokenizer.json shipped
+// with sentence-transformer and cross-encoder models. It covers WordPiece
+// (BERT, MiniLM, MPNet, BGE, ms-marco cross-encoders) and Unigram/SentencePiece
+// (XLM-RoBERTa, multilingual E5, BGE rerankers) vocabularies. Unsupported
+// normalizers and pre-tokenizers are ignored rather than rejected.
+type Tokenizer struct {
+	lowercase    bool
+	stripAccents bool
+	nfkc         bool // approximation of the SentencePiece "Precompiled" normalizer
+
+	preSplit  string // "bert", "whitespace" or "metaspace"
+	metaspace string // replacement character for metaspace pre-tokenization
+
+	model string // "WordPiece" or "Unigram"
+	vocab map[string]int
+	// WordPiece
+	unkID         int
+	subwordPrefix string
+	maxWordChars  int
+	// Unigram
+	scores   map[string]float64
+	maxPiece int // longest piece in runes
+	minScore float64
+
+	// Single-sequence template: special token ids around the text.
+	prefix, suffix []int
+	// Pair template: ids around and between the two sequences. typeIDB is
+	// the token type of the second sequence and its special tokens; 0 for
+	// RoBERTa-style models that do not use token types.
+	hasPair                            bool
+	pairPrefix, pairMiddle, pairSuffix []int
+	typeIDB                            int
+	padID                              int
+}
+
+type tokenizerFile struct {
+	AddedTokens []struct {
+		ID      int    `json:"id"`
+		Content string `json:"content"`
+	} `json:"added_tokens"`
+	Normalizer    *tokenizerComponent `json:"normalizer"`
+	PreTokenizer  *tokenizerComponent `json:"pre_tokenizer"`
+	PostProcessor *tokenizerComponent `json:"post_processor"`
+	Padding       *struct {
+		PadID int `json:"pad_id"`
+	} `json:"padding"`
+	Model struct {
+		Type                    string          `json:"type"`
+		UnkToken                string          `json:"unk_token"`
+		UnkID                   *int            `json:"unk_id"`
+		ContinuingSubwordPrefix *string         `json:"continuing_subword_prefix"`
+		MaxInputCharsPerWord    int             `json:"max_input_chars_per_word"`
+		Vocab                   json.RawMessage `json:"vocab"`
+	} `json:"model"`
+}
+
+// tokenizerComponent is a normalizer, pre-tokenizer or post-processor
+// entry. Only the fields read by this package are declared.
+type tokenizerComponent struct {
+	Type          string                `json:"type"`
+	Lowercase     *bool                 `json:"lowercase"`
+	StripAccents  *bool                 `json:"strip_accents"`
+	Replacement   string                `json:"replacement"`
+	Normalizers   []*tokenizerComponent `json:"normalizers"`
+	PreTokenizers []*tokenizerComponent `json:"pretokenizers"`
+	Processors    []*tokenizerComponent `json:"processors"`
+	// BertProcessing / RobertaProcessing
+	Sep []interface{} `json:"sep"`
+	Cls []interface{} `json:"cls"`
+	// TemplateProcessing
+	Single        []map[string]templatePiece `json:"single"`
+	Pair          []map[string]templatePiece `json:"pair"`
+	SpecialTokens map[string]struct {
+		IDs []int `json:"ids"`
+	} `json:"special_tokens"`
+}
+
+type templatePiece struct {
+	ID     string `json:"id"`
+	TypeID int    `json:"type_id"`
+}
+
+// LoadTokenizer reads a tokenizer.json file.
+func LoadTokenizer(path string) (*Tokenizer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tokenizer: %w", err)
+	}
+	var f tokenizerFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parse tokenizer %s: %w", path, err)
+	}
+	t := &Tokenizer{model: f.Model.Type, vocab: make(map[string]int), preSplit: "whitespace"}
+
+	switch f.Model.Type {
+	case "WordPiece":
+		if err := json.Unmarshal(f.Model.Vocab, &t.vocab); err != nil {
+			return nil, fmt.Errorf("parse WordPiece vocab: %w", err)
+		}
+		unk, ok := t.vocab[f.Model.UnkToken]
+		if !ok {
+			return nil, fmt.Errorf("unk_token %q is not in the vocabulary", f.Model.UnkToken)
+		}
+		t.unkID = unk
+		t.subwordPrefix = "##"
+		if f.Model.ContinuingSubwordPrefix != nil {
+			t.subwordPrefix = *f.Model.ContinuingSubwordPrefix
+		}
+		t.maxWordChars = f.Model.MaxInputCharsPerWord
+		if t.maxWordChars <= 0 {
+			t.maxWordChars = 100
+		}
+	case "Unigram":
+		var pieces [][2]interface{}
+		if err := json.Unmarshal(f.Model.Vocab, &pieces); err != nil {
+			return nil, fmt.Errorf("parse Unigram vocab: %w", err)
+		}
+		t.scores = make(map[string]float64, len(pieces))
+		t.minScore = math.Inf(1)
+		for id, p := range pieces {
+			piece, _ := p[0].(string)
+			score, _ := p[1].(float64)
+			t.vocab[piece] = id
+			t.scores[piece] = score
+			if score < t.minScore {
+				t.minScore = score
+			}
+			if n := len([]rune(piece)); n > t.maxPiece {
+				t.maxPiece = n
+			}
+		}
+		if f.Model.UnkID != nil {
+			t.unkID = *f.Model.UnkID
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tokenizer model %q (WordPiece and Unigram are supported)", f.Model.Type)
+	}
+	for _, at := range f.AddedTokens {
+		t.vocab[at.Content] = at.ID
+	}
+
+	t.readNormalizer(f.Normalizer)
+	t.readPreTokenizer(f.PreTokenizer)
+	if err := t.readPostProcessor(f.PostProcessor); err != nil {
+		return nil, err
+	}
+	if f.Padding != nil {
+		t.padID = f.Padding.PadID
+	} else if id, ok := t.vocab["[PAD]"]; ok {
+		t.padID = id
+	} else if id, ok := t.vocab["<pad>"]; ok {
+		t.padID = id
+	}
+	return t, nil
+}
+
+func (t *Tokenizer) readNormalizer(c *tokenizerComponent) {
+	if c == nil {
+		return
+	}
+	switch c.Type {
+	case "Sequence":
+		for _, n := range c.Normalizers {
+			t.readNormalizer(n)
+		}
+	case "BertNormalizer":
+		t.lowercase = c.Lowercase == nil || *c.Lowercase
+		// strip_accents defaults to the lowercase setting, as in BERT.
+		t.stripAccents = t.lowercase
+		if c.StripAccents != nil {
+			t.stripAccents = *c.StripAccents
+		}
+	case "Lowercase":
+		t.lowercase = true
+	case "StripAccents":
+		t.stripAccents = true
+	case "NFKC", "Precompiled":
+		t.nfkc = true
+	}
+}
+
+func (t *Tokenizer) readPreTokenizer(c *tokenizerComponent) {
+	if c == nil {
+		return
+	}
+	switch c.Type {
+	case "Sequence":
+		for _, p := range c.PreTokenizers {
+			t.readPreTokenizer(p)
+		}
+	case "BertPreTokenizer", "Whitespace":
+		t.preSplit = "bert"
+	case "Metaspace":
+		t.preSplit = "metaspace"
+		t.metaspace = c.Replacement
+		if t.metaspace == "" {
+			t.metaspace = "▁"
+		}
+	}
+}
+
+// readPostProcessor reads the special tokens placed around a single
+// sequence and around a pair. A tokenizer without a post-processor encodes
+// bare text and has no pair template.
+func (t *Tokenizer) readPostProcessor(c *tokenizerComponent) error {
+	if c == nil {
+		return nil
+	}
+	special := func(pair []interface{}) (int, error) {
+		if len(pair) == 2 {
+			if id, ok := pair[1].(float64); ok {
+				return int(id), nil
+			}
+		}
+		return 0, fmt.Errorf("invalid special token %v in %s", pair, c.Type)
+	}
+	switch c.Type {
+	case "Sequence":
+		for _, p := range c.Processors {
+			if p.Type == "BertProcessing" || p.Type == "RobertaProcessing" || p.Type == "TemplateProcessing" {
+				return t.readPostProcessor(p)
+			}
+		}
+	case "BertProcessing", "RobertaProcessing":
+		cls, err := special(c.Cls)
+		if err != nil {
+			return err
+		}
+		sep, err := special(c.Sep)
+		if err != nil {
+			return err
+		}
+		t.prefix, t.suffix = []int{cls}, []int{sep}
+		t.hasPair = true
+		if c.Type == "BertProcessing" {
+			// [CLS] A [SEP] B [SEP]
+			t.pairPrefix, t.pairMiddle, t.pairSuffix, t.typeIDB = []int{cls}, []int{sep}, []int{sep}, 1
+		} else {
+			// <s> A </s></s> B </s>
+			t.pairPrefix, t.pairMiddle, t.pairSuffix, t.typeIDB = []int{cls}, []int{sep, sep}, []int{sep}, 0
+		}
+	case "TemplateProcessing":
+		part := &t.prefix
+		for _, p := range c.Single {
+			if _, ok := p["Sequence"]; ok {
+				part = &t.suffix
+				continue
+			}
+			if err := t.appendSpecial(c, part, p); err != nil {
+				return err
+			}
+		}
+		part = &t.pairPrefix
+		for _, p := range c.Pair {
+			if seq, ok := p["Sequence"]; ok {
+				t.hasPair = true
+				if seq.ID == "B" {
+					t.typeIDB = seq.TypeID
+					part = &t.pairSuffix
+				} else {
+					part = &t.pairMiddle
+				}
+				continue
+			}
+			if err := t.appendSpecial(c, part, p); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported post_processor %q", c.Type)
+	}
+	return nil
+}
+
+// appendSpecial appends the ids of a template special token to part.
+func (t *Tokenizer) appendSpecial(c *tokenizerComponent, part *[]int, p map[string]templatePiece) error {
+	sp, ok := p["SpecialToken"]
+	if !ok {
+		return nil
+	}
+	st, ok := c.SpecialTokens[sp.ID]
+	if !ok {
+		return fmt.Errorf("template special token %q is not defined", sp.ID)
+	}
+	*part = append(*part, st.IDs...)
+	return nil
+}
+
+// PadID returns the id used to pad inputs to the sequence length.
+func (t *Tokenizer) PadID() int { return t.padID }
+
+// HasPairTemplate reports whether the tokenizer defines how two sequences
+// are joined, as cross-encoders need.
+func (t *Tokenizer) HasPairTemplate() bool { return t.hasPair }
+
+// UsesTypeIDs reports whether the second sequence of a pair has its own
+// token type, so the model takes token_type_ids.
+func (t *Tokenizer) UsesTypeIDs() bool { return t.typeIDB != 0 }
+
+// EncodeFixed tokenizes text into model inputs of exactly seqLen tokens:
+// ids and attention mask. Text beyond the budget is truncated; the
+// special tokens are always kept.
+func (t *Tokenizer) EncodeFixed(text string, seqLen int) (ids, mask []int64) {
+	toks := t.encode(text)
+	budget := seqLen - len(t.prefix) - len(t.suffix)
+	if budget < 1 {
+		budget = 1
+	}
+	if len(toks) > budget {
+		toks = toks[:budget]
+	}
+
+	ids = make([]int64, seqLen)
+	mask = make([]int64, seqLen)
+	n := 0
+	for _, part := range [][]int{t.prefix, toks, t.suffix} {
+		for _, id := range part {
+			if n == seqLen {
+				break
+			}
+			ids[n], mask[n] = int64(id), 1
+			n++
+		}
+	}
+	for i := n; i < seqLen; i++ {
+		ids[i] = int64(t.padID)
+	}
+	return ids, mask
+}
+
+// EncodePair tokenizes a query/document pair into model inputs of exactly
+// seqLen tokens: ids, attention mask and token type ids. The document is
+// truncated first; the query only when it alone exceeds the budget. Callers
+// check HasPairTemplate first.
+func (t *Tokenizer) EncodePair(query, doc string, seqLen int) (ids, mask, types []int64) {
+	a := t.encode(query)
+	b := t.encode(doc)
+	budget := seqLen - len(t.pairPrefix) - len(t.pairMiddle) - len(t.pairSuffix)
+	if budget < 2 {
+		budget = 2
+	}
+	if len(a) > budget/2 && len(a)+len(b) > budget {
+		keep := budget - len(b)
+		if keep < budget/2 {
+			keep = budget / 2
+		}
+		if keep < len(a) {
+			a = a[:keep]
+		}
+	}
+	if len(a)+len(b) > budget {
+		b = b[:budget-len(a)]
+	}
+
+	ids = make([]int64, seqLen)
+	mask = make([]int64, seqLen)
+	types = make([]int64, seqLen)
+	n := 0
+	put := func(toks []int, typeID int) {
+		for _, id := range toks {
+			if n == seqLen {
+				return
+			}
+			ids[n], mask[n], types[n] = int64(id), 1, int64(typeID)
+			n++
+		}
+	}
+	put(t.pairPrefix, 0)
+	put(a, 0)
+	put(t.pairMiddle, 0)
+	put(b, t.typeIDB)
+	put(t.pairSuffix, t.typeIDB)
+	for i := n; i < seqLen; i++ {
+		ids[i] = int64(t.padID)
+	}
+	return ids, mask, types
+}
+
+// encode normalizes, pre-tokenizes and splits text into vocabulary ids,
+// without special tokens.
+func (t *Tokenizer) encode(text string) []int {
+	text = t.normalize(text)
+	var out []int
+	for _, word := range t.preTokenize(text) {
+		if t.model == "WordPiece" {
+			out = append(out, t.wordPiece(word)...)
+		} else {
+			out = append(out, t.unigram(word)...)
+		}
+	}
+	return out
+}
+
+func (t *Tokenizer) normalize(text string) string {
+	if t.nfkc {
+		text = norm.NFKC.String(text)
+	}
+	if t.lowercase {
+		text = strings.ToLower(text)
+	}
+	if t.stripAccents {
+		var sb strings.Builder
+		for _, r := range norm.NFD.String(text) {
+			if !unicode.Is(unicode.Mn, r) {
+				sb.WriteRune(r)
+			}
+		}
+		text = sb.String()
+	}
+	return text
+}
+
+func (t *Tokenizer) preTokenize(text string) []string {
+	switch t.preSplit {
+	case "metaspace":
+		words := strings.Fields(text)
+		for i, w := range words {
+			words[i] = t.metaspace + w
+		}
+		return words
+	case "bert":
+		// Split on whitespace and isolate every punctuation character and
+		// CJK ideograph, as BertPreTokenizer does.
+		var words []string
+		var cur []rune
+		flush := func() {
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = cur[:0]
+			}
+		}
+		for _, r := range text {
+			switch {
+			case unicode.IsSpace(r) || unicode.IsControl(r):
+				flush()
+			case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.Is(unicode.Han, r):
+				flush()
+				words = append(words, string(r))
+			default:
+				cur = append(cur, r)
+			}
+		}
+		flush()
+		return words
+	default:
+		return strings.Fields(text)
+	}
+}
+
+// wordPiece splits one word greedily into the longest vocabulary pieces.
+func (t *Tokenizer) wordPiece(word string) []int {
+	runes := []rune(word)
+	if len(runes) > t.maxWordChars {
+		return []int{t.unkID}
+	}
+	var ids []int
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		found := -1
+		for ; end > start; end-- {
+			piece := string(runes[start:end])
+			if start > 0 {
+				piece = t.subwordPrefix + piece
+			}
+			if id, ok := t.vocab[piece]; ok {
+				found = id
+				break
+			}
+		}
+		if found < 0 {
+			return []int{t.unkID}
+		}
+		ids = append(ids, found)
+		start = end
+	}
+	return ids
+}
+
+// unigram segments one word into the pieces with the highest total
+// log-probability (Viterbi). Characters that no piece covers become unk.
+func (t *Tokenizer) unigram(word string) []int {
+	runes := []rune(word)
+	n := len(runes)
+	best := make([]float64, n+1)
+	from := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = math.Inf(-1)
+	}
+	unkPenalty := t.minScore - 10
+	for end := 1; end <= n; end++ {
+		for start := end - 1; start >= 0 && end-start <= t.maxPiece; start-- {
+			if math.IsInf(best[start], -1) {
+				continue
+			}
+			if score, ok := t.scores[string(runes[start:end])]; ok {
+				if s := best[start] + score; s > best[end] {
+					best[end], from[end] = s, start
+				}
+			}
+		}
+		if math.IsInf(best[end], -1) {
+			best[end], from[end] = best[end-1]+unkPenalty, end-1
+		}
+	}
+	var ids []int
+	for end := n; end > 0; end = from[end] {
+		start := from[end]
+		id, ok := t.vocab[string(runes[start:end])]
+		if !ok {
+			id = t.unkID
+		}
+		ids = append(ids, id)
+	}
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+	return ids
+}
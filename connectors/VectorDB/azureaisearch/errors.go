@@ -13,6 +13,7 @@ const (
 	ErrCodeInvalidDimensions     = "VDB-COL-2003"
 	ErrCodeInvalidMetric         = "VDB-COL-2004"
 	ErrCodeInvalidCollectionName = "VDB-COL-2005"
+	ErrCodeInvalidMetadataField  = "VDB-COL-2006"
 
 	ErrCodeDocumentNotFound  = "VDB-DOC-3001"
 	ErrCodeInvalidVector     = "VDB-DOC-3002"
@@ -24,6 +25,7 @@ const (
 	ErrCodeInvalidTopK        = "VDB-SRH-4002"
 	ErrCodeInvalidAlpha       = "VDB-SRH-4003"
 	ErrCodeHybridNotSupported = "VDB-SRH-4004"
+	ErrCodeInvalidFilter      = "VDB-SRH-4005"
 
 	ErrCodeConnectionFailed  = "VDB-CON-5001"
 	ErrCodeConnectionTimeout = "VDB-CON-5002"
@@ -46,6 +48,7 @@ var ErrorMessages = map[string]string{
 	ErrCodeInvalidDimensions:     "Dimensions must be greater than 0",
 	ErrCodeInvalidMetric:         "DistanceMetric must be one of: cosine, dot, euclidean",
 	ErrCodeInvalidCollectionName: "Collection name must not be empty",
+	ErrCodeInvalidMetadataField:  "Metadata field declaration is invalid",
 	ErrCodeDocumentNotFound:      "Document not found",
 	ErrCodeInvalidVector:         "Vector is nil or empty",
 	ErrCodeEmptyDocumentList:     "Document list must not be empty",
@@ -55,6 +58,7 @@ var ErrorMessages = map[string]string{
 	ErrCodeInvalidTopK:           "TopK must be greater than 0",
 	ErrCodeInvalidAlpha:          "Alpha must be between 0.0 and 1.0",
 	ErrCodeHybridNotSupported:    "This provider does not support native hybrid search",
+	ErrCodeInvalidFilter:         "Filter cannot be applied to the index fields",
 	ErrCodeConnectionFailed:      "Failed to establish connection to Azure AI Search",
 	ErrCodeConnectionTimeout:     "Connection to Azure AI Search timed out",
 	ErrCodeAuthFailed:            "Authentication failed — check API key",
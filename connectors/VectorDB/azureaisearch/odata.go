@@ -0,0 +1,527 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reservedFieldNames are the fixed fields of every index created by CreateCollection.
+var reservedFieldNames = map[string]bool{"id": true, "content": true, "metadata": true, "embedding": true}
+
+// edmTypes maps the metadata field types accepted by CreateCollection to EDM types.
+var edmTypes = map[string]string{
+	"string":   "Edm.String",
+	"int":      "Edm.Int32",
+	"int32":    "Edm.Int32",
+	"int64":    "Edm.Int64",
+	"long":     "Edm.Int64",
+	"double":   "Edm.Double",
+	"float":    "Edm.Double",
+	"number":   "Edm.Double",
+	"boolean":  "Edm.Boolean",
+	"bool":     "Edm.Boolean",
+	"datetime": "Edm.DateTimeOffset",
+	"date":     "Edm.DateTimeOffset",
+	"string[]": "Collection(Edm.String)",
+}
+
+const edmStringCollection = "Collection(Edm.String)"
+
+// edmType resolves a metadata field type (a short name or an EDM type name).
+func edmType(t string) (string, bool) {
+	t = strings.TrimSpace(t)
+	if t == "" {
+		return "Edm.String", true
+	}
+	if e, ok := edmTypes[strings.ToLower(t)]; ok {
+		return e, true
+	}
+	for _, e := range edmTypes {
+		if strings.EqualFold(e, t) {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// metadataIndexFields returns the index field definitions for declared metadata fields.
+func metadataIndexFields(fields []MetadataField) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(fields))
+	for _, f := range fields {
+		t, _ := edmType(f.Type)
+		out = append(out, map[string]interface{}{
+			"name": f.Name, "type": t,
+			"searchable": false, "retrievable": true,
+			"filterable": f.Filterable, "facetable": f.Facetable,
+			"sortable": f.Filterable && t != edmStringCollection,
+		})
+	}
+	return out
+}
+
+// ── Index schema ─────────────────────────────────────────────────────────────
+
+// indexField is a filterable or typed field of an existing index.
+type indexField struct {
+	Type       string
+	Filterable bool
+}
+
+// indexSchema describes the fields of an index beyond content, metadata and
+// embedding: the key field and any declared metadata fields.
+type indexSchema struct {
+	Fields     map[string]indexField
+	IDSortable bool
+}
+
+// metadataFields returns the declared metadata fields, excluding the key field.
+func (s *indexSchema) metadataFields() []string {
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		if name != "id" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseIndexSchema(body []byte) (*indexSchema, error) {
+	var def struct {
+		Fields []struct {
+			Name       string `json:"name"`
+			Type       string `json:"type"`
+			Filterable bool   `json:"filterable"`
+			Sortable   bool   `json:"sortable"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(body, &def); err != nil {
+		return nil, fmt.Errorf("azureaisearch: parse index definition: %w", err)
+	}
+	schema := &indexSchema{Fields: make(map[string]indexField)}
+	for _, f := range def.Fields {
+		if f.Name == "content" || f.Name == "metadata" || f.Name == "embedding" {
+			continue
+		}
+		if f.Name == "id" {
+			schema.IDSortable = f.Sortable
+		}
+		schema.Fields[f.Name] = indexField{Type: f.Type, Filterable: f.Filterable}
+	}
+	return schema, nil
+}
+
+// indexSchema returns the cached field schema of an index, fetching the index
+// definition on first use.
+func (c *azureAISearchClient) indexSchema(ctx context.Context, name string) (*indexSchema, error) {
+	c.schemaMu.RLock()
+	schema, ok := c.schemas[name]
+	c.schemaMu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	url := c.apiURL("/indexes/" + name)
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		respBody, statusCode, err := c.doRequest(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		if statusCode != http.StatusOK {
+			return c.handleHTTPError(statusCode, respBody, false)
+		}
+		schema, err = parseIndexSchema(respBody)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	c.schemaMu.Lock()
+	c.schemas[name] = schema
+	c.schemaMu.Unlock()
+	return schema, nil
+}
+
+// forgetIndexSchema drops a cached schema after the index was created or deleted.
+func (c *azureAISearchClient) forgetIndexSchema(name string) {
+	c.schemaMu.Lock()
+	delete(c.schemas, name)
+	c.schemaMu.Unlock()
+}
+
+// indexDocumentFields returns the declared metadata field values of a payload,
+// converted to the field types.
+func (s *indexSchema) indexDocumentFields(payload map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	for _, name := range s.metadataFields() {
+		v, ok := payload[name]
+		if !ok || v == nil {
+			// mergeOrUpload keeps omitted fields, so clear the value
+			// explicitly when the key is no longer in the payload.
+			out[name] = nil
+			continue
+		}
+		fv, err := fieldValue(s.Fields[name].Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("metadata field %q: %w", name, err)
+		}
+		out[name] = fv
+	}
+	return out, nil
+}
+
+// fieldValue converts a payload value to the JSON representation of an EDM type.
+func fieldValue(edm string, v interface{}) (interface{}, error) {
+	switch edm {
+	case "Edm.String":
+		return fmt.Sprintf("%v", v), nil
+	case "Edm.Int32", "Edm.Int64":
+		n, ok := toInt64(v)
+		if !ok {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		return n, nil
+	case "Edm.Double":
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a number", v)
+		}
+		return f, nil
+	case "Edm.Boolean":
+		b, ok := toBool(v)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a boolean", v)
+		}
+		return b, nil
+	case "Edm.DateTimeOffset":
+		t, ok := toTime(v)
+		if !ok {
+			return nil, fmt.Errorf("%v is not an RFC 3339 date-time", v)
+		}
+		return t.UTC().Format(time.RFC3339Nano), nil
+	case edmStringCollection:
+		switch items := v.(type) {
+		case []interface{}:
+			out := make([]string, len(items))
+			for i, item := range items {
+				out[i] = fmt.Sprintf("%v", item)
+			}
+			return out, nil
+		case []string:
+			return items, nil
+		default:
+			return []string{fmt.Sprintf("%v", v)}, nil
+		}
+	}
+	return v, nil
+}
+
+// ── OData $filter translation ────────────────────────────────────────────────
+
+// filterOps maps filter operators to OData comparison operators.
+var filterOps = map[string]string{
+	FilterEq: "eq", FilterNe: "ne", FilterGt: "gt", FilterGte: "ge", FilterLt: "lt", FilterLte: "le",
+}
+
+// splitFilters parses filters and translates the conditions on filterable
+// index fields into an OData $filter expression. Conditions on keys that are
+// not filterable fields (payload keys only stored in the JSON metadata
+// string, nested paths) are returned as the residual filter, to be matched
+// client-side with FilterExpr.Match.
+//
+// $and, $or and $not map to and, or and not; $in and $nin use search.in,
+// $exists compares with null and $contains tests membership of a string
+// collection.
+func (s *indexSchema) splitFilters(filters map[string]interface{}) (string, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil || expr == nil {
+		return "", nil, err
+	}
+	pushed, residual := splitFilter(expr, s.odataNative)
+	if pushed == nil {
+		return "", residual, nil
+	}
+	odata, err := s.odataExpr(pushed)
+	if err != nil {
+		return "", nil, err
+	}
+	return odata, residual, nil
+}
+
+// odataNative reports whether e only references filterable index fields with
+// operators that have an OData form.
+func (s *indexSchema) odataNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		if n.IsLogical() {
+			return true
+		}
+		field, ok := s.Fields[n.Field]
+		if !ok || !field.Filterable {
+			return false
+		}
+		switch n.Op {
+		case FilterContains:
+			return field.Type == edmStringCollection
+		case FilterExists:
+			return field.Type != edmStringCollection
+		}
+		return true
+	})
+}
+
+// odataExpr builds the OData expression for a parsed filter on filterable
+// index fields.
+func (s *indexSchema) odataExpr(e *FilterExpr) (string, error) {
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		parts := make([]string, len(e.Children))
+		for i, c := range e.Children {
+			part, err := s.odataExpr(c)
+			if err != nil {
+				return "", err
+			}
+			if c.Op == FilterAnd || c.Op == FilterOr {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		if e.Op == FilterOr {
+			return strings.Join(parts, " or "), nil
+		}
+		return strings.Join(parts, " and "), nil
+	case FilterNot:
+		inner, err := s.odataExpr(e.Children[0])
+		if err != nil {
+			return "", err
+		}
+		return "not (" + inner + ")", nil
+	}
+	clause, err := odataClause(e.Field, s.Fields[e.Field].Type, e)
+	if err != nil {
+		return "", newError(ErrCodeInvalidFilter, fmt.Sprintf("filter on %q", e.Field), err)
+	}
+	return clause, nil
+}
+
+// odataClause builds the expression for one condition.
+func odataClause(field, edm string, e *FilterExpr) (string, error) {
+	switch e.Op {
+	case FilterIn:
+		return odataIn(field, edm, e.Values())
+	case FilterNin:
+		in, err := odataIn(field, edm, e.Values())
+		if err != nil {
+			return "", err
+		}
+		return "not " + in, nil
+	case FilterExists:
+		if e.Value == true {
+			return field + " ne null", nil
+		}
+		return field + " eq null", nil
+	case FilterContains:
+		return odataComparison(field, edm, "eq", e.Value)
+	}
+	odataOp, ok := filterOps[e.Op]
+	if !ok {
+		return "", fmt.Errorf("unsupported operator %q", e.Op)
+	}
+	return odataComparison(field, edm, odataOp, e.Value)
+}
+
+// odataComparison builds "field op literal". For string collections eq and ne
+// test membership: tags/any(t: t eq 'x') and tags/all(t: t ne 'x').
+func odataComparison(field, edm, op string, v interface{}) (string, error) {
+	if edm == edmStringCollection {
+		lit, err := odataLiteral("Edm.String", v)
+		if err != nil {
+			return "", err
+		}
+		switch op {
+		case "eq":
+			return fmt.Sprintf("%s/any(t: t eq %s)", field, lit), nil
+		case "ne":
+			return fmt.Sprintf("%s/all(t: t ne %s)", field, lit), nil
+		}
+		return "", fmt.Errorf("operator %q is not supported on a string collection", op)
+	}
+	if edm == "Edm.Boolean" && op != "eq" && op != "ne" {
+		return "", fmt.Errorf("operator %q is not supported on a boolean", op)
+	}
+	lit, err := odataLiteral(edm, v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", field, op, lit), nil
+}
+
+// odataIn builds a membership test. String values use search.in, which is
+// faster than a chain of eq comparisons on large value lists.
+func odataIn(field, edm string, values []interface{}) (string, error) {
+	if len(values) == 0 {
+		return "false", nil
+	}
+
+	if edm == "Edm.String" || edm == edmStringCollection {
+		strs := make([]string, len(values))
+		usable := true
+		for i, item := range values {
+			strs[i] = fmt.Sprintf("%v", item)
+			if strings.Contains(strs[i], "|") {
+				usable = false
+			}
+		}
+		if usable {
+			list := odataString(strings.Join(strs, "|"))
+			if edm == edmStringCollection {
+				return fmt.Sprintf("%s/any(t: search.in(t, %s, '|'))", field, list), nil
+			}
+			return fmt.Sprintf("search.in(%s, %s, '|')", field, list), nil
+		}
+	}
+
+	parts := make([]string, len(values))
+	for i, item := range values {
+		part, err := odataComparison(field, edm, "eq", item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	return "(" + strings.Join(parts, " or ") + ")", nil
+}
+
+// odataLiteral formats a value as an OData literal of the given EDM type.
+func odataLiteral(edm string, v interface{}) (string, error) {
+	if v == nil {
+		return "null", nil
+	}
+	switch edm {
+	case "Edm.String":
+		return odataString(fmt.Sprintf("%v", v)), nil
+	case "Edm.Int32", "Edm.Int64":
+		n, ok := toInt64(v)
+		if !ok {
+			return "", fmt.Errorf("%v is not an integer", v)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "Edm.Double":
+		f, ok := toFloat64(v)
+		if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("%v is not a number", v)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case "Edm.Boolean":
+		b, ok := toBool(v)
+		if !ok {
+			return "", fmt.Errorf("%v is not a boolean", v)
+		}
+		return strconv.FormatBool(b), nil
+	case "Edm.DateTimeOffset":
+		t, ok := toTime(v)
+		if !ok {
+			return "", fmt.Errorf("%v is not an RFC 3339 date-time", v)
+		}
+		return t.UTC().Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("field type %s is not filterable", edm)
+}
+
+// odataString quotes a string literal, doubling embedded single quotes.
+func odataString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// andFilters joins non-empty OData expressions with "and".
+func andFilters(exprs ...string) string {
+	var parts []string
+	for _, e := range exprs {
+		if e != "" {
+			parts = append(parts, "("+e+")")
+		}
+	}
+	if len(parts) == 1 {
+		return strings.TrimSuffix(strings.TrimPrefix(parts[0], "("), ")")
+	}
+	return strings.Join(parts, " and ")
+}
+
+// ── Value conversion ─────────────────────────────────────────────────────────
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+		return i, err == nil
+	}
+	f, ok := toFloat64(v)
+	if !ok || f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func toBool(v interface{}) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+		return parsed, err == nil
+	}
+	return false, false
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		s := strings.TrimSpace(t)
+		if parsed, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return parsed, true
+		}
+		if parsed, err := time.Parse("2006-01-02", s); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,404 @@
+//go:build !integration
+
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testIndexDefinition is an index created with declared metadata fields.
+var testIndexDefinition = map[string]interface{}{
+	"name": "myindex",
+	"fields": []map[string]interface{}{
+		{"name": "id", "type": "Edm.String", "key": true, "filterable": true, "sortable": true},
+		{"name": "content", "type": "Edm.String", "searchable": true},
+		{"name": "metadata", "type": "Edm.String"},
+		{"name": "embedding", "type": "Collection(Edm.Single)"},
+		{"name": "category", "type": "Edm.String", "filterable": true},
+		{"name": "year", "type": "Edm.Int32", "filterable": true},
+		{"name": "score", "type": "Edm.Double", "filterable": true},
+		{"name": "published", "type": "Edm.Boolean", "filterable": true},
+		{"name": "updated", "type": "Edm.DateTimeOffset", "filterable": true},
+		{"name": "tags", "type": "Collection(Edm.String)", "filterable": true},
+		{"name": "notes", "type": "Edm.String", "filterable": false},
+	},
+}
+
+func testSchema(t *testing.T) *indexSchema {
+	t.Helper()
+	body, err := json.Marshal(testIndexDefinition)
+	require.NoError(t, err)
+	schema, err := parseIndexSchema(body)
+	require.NoError(t, err)
+	return schema
+}
+
+// ─── OData translation ────────────────────────────────────────────────────────
+
+func TestSplitFilters_OData(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    string
+	}{
+		{"equality", map[string]interface{}{"category": "tech"}, "category eq 'tech'"},
+		{"quote escaped", map[string]interface{}{"category": "O'Reilly"}, "category eq 'O''Reilly'"},
+		{"range", map[string]interface{}{"year": map[string]interface{}{"$gte": 2020, "$lt": 2024.0}}, "year ge 2020 and year lt 2024"},
+		{"ne double", map[string]interface{}{"score": map[string]interface{}{"$ne": 0.5}}, "score ne 0.5"},
+		{"boolean", map[string]interface{}{"published": true}, "published eq true"},
+		{"datetime", map[string]interface{}{"updated": map[string]interface{}{"$gt": "2024-05-01T10:00:00+02:00"}}, "updated gt 2024-05-01T08:00:00Z"},
+		{"in strings", map[string]interface{}{"category": map[string]interface{}{"$in": []interface{}{"a", "b"}}}, "search.in(category, 'a|b', '|')"},
+		{"in with separator", map[string]interface{}{"category": map[string]interface{}{"$in": []interface{}{"a|b", "c"}}}, "(category eq 'a|b' or category eq 'c')"},
+		{"in numbers", map[string]interface{}{"year": map[string]interface{}{"$in": []interface{}{2023, 2024}}}, "(year eq 2023 or year eq 2024)"},
+		{"in empty", map[string]interface{}{"year": map[string]interface{}{"$in": []interface{}{}}}, "false"},
+		{"collection eq", map[string]interface{}{"tags": "ai"}, "tags/any(t: t eq 'ai')"},
+		{"collection ne", map[string]interface{}{"tags": map[string]interface{}{"$ne": "ai"}}, "tags/all(t: t ne 'ai')"},
+		{"collection in", map[string]interface{}{"tags": map[string]interface{}{"$in": []interface{}{"ai", "ml"}}}, "tags/any(t: search.in(t, 'ai|ml', '|'))"},
+		{"keys sorted and joined", map[string]interface{}{"year": 2024, "category": "tech"}, "category eq 'tech' and year eq 2024"},
+		{"nin", map[string]interface{}{"category": map[string]interface{}{"$nin": []interface{}{"a", "b"}}}, "not search.in(category, 'a|b', '|')"},
+		{"exists", map[string]interface{}{"updated": map[string]interface{}{"$exists": false}}, "updated eq null"},
+		{"collection contains", map[string]interface{}{"tags": map[string]interface{}{"$contains": "ai"}}, "tags/any(t: t eq 'ai')"},
+		{"or", map[string]interface{}{"$or": []interface{}{
+			map[string]interface{}{"category": "tech", "year": 2024},
+			map[string]interface{}{"published": true},
+		}}, "(category eq 'tech' and year eq 2024) or published eq true"},
+		{"not within and", map[string]interface{}{
+			"category": "tech",
+			"$not":     map[string]interface{}{"$or": []interface{}{map[string]interface{}{"year": 2020}, map[string]interface{}{"year": 2021}}},
+		}, "not (year eq 2020 or year eq 2021) and category eq 'tech'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, residual, err := schema.splitFilters(tt.filters)
+			require.NoError(t, err)
+			assert.Empty(t, residual)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSplitFilters_Residual(t *testing.T) {
+	schema := testSchema(t)
+	got, residual, err := schema.splitFilters(map[string]interface{}{
+		"category": "tech",
+		"notes":    "draft",
+		"source":   map[string]interface{}{"$ne": "web"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "category eq 'tech'", got)
+	require.NotNil(t, residual)
+	fields := map[string]string{}
+	for _, c := range residual.Conjuncts() {
+		fields[c.Field] = c.Op
+	}
+	assert.Equal(t, map[string]string{"notes": FilterEq, "source": FilterNe}, fields)
+}
+
+func TestSplitFilters_MixedOrIsResidual(t *testing.T) {
+	schema := testSchema(t)
+	got, residual, err := schema.splitFilters(map[string]interface{}{
+		"year": map[string]interface{}{"$gte": 2020},
+		"$or": []interface{}{
+			map[string]interface{}{"category": "tech"},
+			map[string]interface{}{"meta.team": "search"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "year ge 2020", got)
+	require.NotNil(t, residual)
+	assert.Equal(t, FilterOr, residual.Op)
+	assert.True(t, residual.Match(map[string]interface{}{"meta": map[string]interface{}{"team": "search"}}))
+}
+
+func TestSplitFilters_Invalid(t *testing.T) {
+	schema := testSchema(t)
+	for name, filters := range map[string]map[string]interface{}{
+		"unknown operator":  {"year": map[string]interface{}{"$regex": "x"}},
+		"not an integer":    {"year": "recent"},
+		"bad datetime":      {"updated": "yesterday"},
+		"boolean range":     {"published": map[string]interface{}{"$gt": false}},
+		"collection range":  {"tags": map[string]interface{}{"$gt": "a"}},
+		"in without array":  {"category": map[string]interface{}{"$in": "tech"}},
+		"fractional int32s": {"year": 2024.5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := schema.splitFilters(filters)
+			var vdbErr *VDBError
+			require.ErrorAs(t, err, &vdbErr)
+			assert.Equal(t, ErrCodeInvalidFilter, vdbErr.Code)
+		})
+	}
+}
+
+func TestResidualFilter_Match(t *testing.T) {
+	payload := map[string]interface{}{"category": "tech", "year": float64(2023), "tags": []interface{}{"ai", "ml"}}
+	match := func(filters map[string]interface{}) bool {
+		t.Helper()
+		expr, err := ParseFilter(filters)
+		require.NoError(t, err)
+		return expr.Match(payload)
+	}
+	assert.True(t, match(nil))
+	assert.True(t, match(map[string]interface{}{"category": "tech", "year": 2023}))
+	assert.True(t, match(map[string]interface{}{"year": map[string]interface{}{"$gte": 2020, "$lt": 2024}}))
+	assert.True(t, match(map[string]interface{}{"category": map[string]interface{}{"$in": []interface{}{"news", "tech"}}}))
+	assert.True(t, match(map[string]interface{}{"tags": map[string]interface{}{"$contains": "ml"}}))
+	assert.False(t, match(map[string]interface{}{"category": map[string]interface{}{"$ne": "tech"}}))
+	assert.False(t, match(map[string]interface{}{"year": map[string]interface{}{"$gt": 2023}}))
+	assert.False(t, match(map[string]interface{}{"missing": "x"}))
+}
+
+// ─── Declared fields ──────────────────────────────────────────────────────────
+
+func TestValidateMetadataFields(t *testing.T) {
+	assert.NoError(t, validateMetadataFields([]MetadataField{{Name: "category", Type: "string"}, {Name: "tags", Type: "string[]"}}))
+	for name, fields := range map[string][]MetadataField{
+		"reserved":     {{Name: "content", Type: "string"}},
+		"duplicate":    {{Name: "a", Type: "string"}, {Name: "a", Type: "int32"}},
+		"bad name":     {{Name: "1st", Type: "string"}},
+		"service name": {{Name: "azureSearchScore", Type: "double"}},
+		"bad type":     {{Name: "a", Type: "geography"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var vdbErr *VDBError
+			require.ErrorAs(t, validateMetadataFields(fields), &vdbErr)
+			assert.Equal(t, ErrCodeInvalidMetadataField, vdbErr.Code)
+		})
+	}
+}
+
+func TestCreateCollection_MetadataFields(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		respondJSON(w, http.StatusCreated, map[string]interface{}{"name": "myindex"})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	err := c.CreateCollection(context.Background(), CollectionConfig{
+		Name: "myindex", Dimensions: 4, DistanceMetric: "cosine",
+		MetadataFields: []MetadataField{
+			{Name: "category", Type: "string", Filterable: true, Facetable: true},
+			{Name: "tags", Type: "string[]", Filterable: true},
+		},
+	})
+	require.NoError(t, err)
+
+	fields := map[string]map[string]interface{}{}
+	for _, f := range body["fields"].([]interface{}) {
+		m := f.(map[string]interface{})
+		fields[m["name"].(string)] = m
+	}
+	assert.Equal(t, true, fields["id"]["sortable"])
+	assert.Equal(t, "Edm.String", fields["category"]["type"])
+	assert.Equal(t, true, fields["category"]["filterable"])
+	assert.Equal(t, true, fields["category"]["facetable"])
+	assert.Equal(t, "Collection(Edm.String)", fields["tags"]["type"])
+	assert.Equal(t, false, fields["tags"]["sortable"])
+}
+
+func TestUpsertDocuments_TypedFields(t *testing.T) {
+	var docs []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			respondJSON(w, http.StatusOK, testIndexDefinition)
+			return
+		}
+		var body struct {
+			Value []map[string]interface{} `json:"value"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		docs = body.Value
+		respondJSON(w, http.StatusOK, map[string]interface{}{"value": []interface{}{}})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	err := c.UpsertDocuments(context.Background(), "myindex", []Document{{
+		ID: "doc1", Vector: make([]float64, 4), Content: "hello",
+		Payload: map[string]interface{}{"category": "tech", "year": float64(2024), "tags": []interface{}{"ai"}, "source": "web"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "tech", docs[0]["category"])
+	assert.Equal(t, float64(2024), docs[0]["year"])
+	assert.Equal(t, []interface{}{"ai"}, docs[0]["tags"])
+	assert.Nil(t, docs[0]["score"])
+	assert.Contains(t, docs[0], "score", "declared fields missing from the payload are cleared")
+	assert.NotContains(t, docs[0], "source")
+	assert.Contains(t, docs[0]["metadata"], `"source":"web"`)
+
+	err = c.UpsertDocuments(context.Background(), "myindex", []Document{{
+		ID: "doc2", Vector: make([]float64, 4), Payload: map[string]interface{}{"year": "recent"},
+	}})
+	assert.Error(t, err)
+}
+
+// ─── Server-side filters ──────────────────────────────────────────────────────
+
+// filterServer serves the test index definition and records docs/search and
+// docs/index request bodies.
+type filterServer struct {
+	mu       sync.Mutex
+	searches []map[string]interface{}
+	indexed  []map[string]interface{}
+	respond  func(body map[string]interface{}) map[string]interface{}
+}
+
+func (f *filterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/indexes/myindex"):
+		respondJSON(w, http.StatusOK, testIndexDefinition)
+	case strings.HasSuffix(r.URL.Path, "/docs/search"):
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.searches = append(f.searches, body)
+		respondJSON(w, http.StatusOK, f.respond(body))
+	case strings.HasSuffix(r.URL.Path, "/docs/index"):
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.indexed = append(f.indexed, body)
+		respondJSON(w, http.StatusOK, map[string]interface{}{"value": []interface{}{}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestCountDocuments_ServerSideFilter(t *testing.T) {
+	fs := &filterServer{respond: func(map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"@odata.count": 1234, "value": []interface{}{}}
+	}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	count, err := c.CountDocuments(context.Background(), "myindex", map[string]interface{}{
+		"category": "tech", "year": map[string]interface{}{"$gte": 2023},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1234), count)
+	require.Len(t, fs.searches, 1)
+	assert.Equal(t, "category eq 'tech' and year ge 2023", fs.searches[0]["filter"])
+	assert.Equal(t, float64(0), fs.searches[0]["top"])
+	assert.Equal(t, true, fs.searches[0]["count"])
+}
+
+func TestCountDocuments_ResidualFilter(t *testing.T) {
+	fs := &filterServer{respond: func(map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"@odata.count": 2,
+			"value": []map[string]interface{}{
+				{"id": "a", "metadata": `{"category":"tech","source":"web"}`},
+				{"id": "b", "metadata": `{"category":"tech","source":"pdf"}`},
+			},
+		}
+	}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	count, err := c.CountDocuments(context.Background(), "myindex", map[string]interface{}{
+		"category": "tech", "source": "web",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, "category eq 'tech'", fs.searches[0]["filter"])
+}
+
+func TestDeleteByFilter_KeysetPaging(t *testing.T) {
+	const total = 1500
+	fs := &filterServer{}
+	fs.respond = func(body map[string]interface{}) map[string]interface{} {
+		// First page: ids 0000–0999; second page (id gt '0999'): 1000–1499.
+		start, end := 0, 1000
+		if f, _ := body["filter"].(string); strings.Contains(f, "id gt '0999'") {
+			start, end = 1000, total
+		}
+		var value []map[string]interface{}
+		for i := start; i < end; i++ {
+			value = append(value, map[string]interface{}{"id": fmt.Sprintf("%04d", i)})
+		}
+		return map[string]interface{}{"value": value}
+	}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	n, err := c.DeleteByFilter(context.Background(), "myindex", map[string]interface{}{"category": "tech"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(total), n)
+
+	require.Len(t, fs.searches, 2)
+	assert.Equal(t, "category eq 'tech'", fs.searches[0]["filter"])
+	assert.Equal(t, "(category eq 'tech') and (id gt '0999')", fs.searches[1]["filter"])
+	assert.Equal(t, "id asc", fs.searches[0]["orderby"])
+	assert.Equal(t, "id", fs.searches[0]["select"])
+	assert.NotContains(t, fs.searches[0], "skip")
+
+	// Deletes are sent in batches of at most 1000 actions.
+	require.Len(t, fs.indexed, 2)
+	assert.Len(t, fs.indexed[0]["value"], 1000)
+	assert.Len(t, fs.indexed[1]["value"], 500)
+}
+
+func TestVectorSearch_Filters(t *testing.T) {
+	fs := &filterServer{respond: func(map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"value": []map[string]interface{}{
+				{"id": "a", "metadata": `{"category":"tech","source":"web"}`, "@search.score": 0.9},
+				{"id": "b", "metadata": `{"category":"tech","source":"pdf"}`, "@search.score": 0.8},
+			},
+		}
+	}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	results, err := c.VectorSearch(context.Background(), SearchRequest{
+		CollectionName: "myindex",
+		QueryVector:    []float64{0.1, 0.2, 0.3, 0.4},
+		TopK:           5,
+		Filters:        map[string]interface{}{"tags": "ai", "source": "web"},
+		SkipPayload:    true,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "a", results[0].ID)
+	assert.Nil(t, results[0].Payload)
+	assert.Equal(t, "tags/any(t: t eq 'ai')", fs.searches[0]["filter"])
+}
+
+func TestHybridSearch_InvalidFilter(t *testing.T) {
+	fs := &filterServer{respond: func(map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"value": []interface{}{}}
+	}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	_, err := c.HybridSearch(context.Background(), HybridSearchRequest{
+		CollectionName: "myindex",
+		QueryText:      "q",
+		QueryVector:    []float64{0.1, 0.2, 0.3, 0.4},
+		TopK:           3,
+		Filters:        map[string]interface{}{"year": map[string]interface{}{"$like": "20%"}},
+	})
+	var vdbErr *VDBError
+	require.ErrorAs(t, err, &vdbErr)
+	assert.Empty(t, fs.searches)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,15 +17,20 @@ import (
 type azureAISearchClient struct {
 	cfg        ConnectionConfig
 	httpClient *http.Client
+
+	schemaMu sync.RWMutex
+	schemas  map[string]*indexSchema // index name → declared fields, see indexSchema
 }
 
 // Compile-time check.
 var _ VectorDBClient = (*azureAISearchClient)(nil)
+var _ MetricReader = (*azureAISearchClient)(nil)
 
 func newAzureAISearchClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	return &azureAISearchClient{
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		schemas:    make(map[string]*indexSchema),
 	}, nil
 }
 
@@ -171,6 +177,73 @@ func (c *azureAISearchClient) CollectionExists(ctx context.Context, name string)
 	return exists, nil
 }
 
+// CollectionMetric returns the metric of the algorithm behind the vector
+// search profile of the index's embedding field.
+func (c *azureAISearchClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	var index struct {
+		Fields []struct {
+			Name                string `json:"name"`
+			VectorSearchProfile string `json:"vectorSearchProfile"`
+		} `json:"fields"`
+		VectorSearch struct {
+			Profiles []struct {
+				Name      string `json:"name"`
+				Algorithm string `json:"algorithm"`
+			} `json:"profiles"`
+			Algorithms []struct {
+				Name           string `json:"name"`
+				HNSWParameters *struct {
+					Metric string `json:"metric"`
+				} `json:"hnswParameters"`
+				ExhaustiveKnnParameters *struct {
+					Metric string `json:"metric"`
+				} `json:"exhaustiveKnnParameters"`
+			} `json:"algorithms"`
+		} `json:"vectorSearch"`
+	}
+	url := c.apiURL("/indexes/" + name)
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		respBody, statusCode, err := c.doRequest(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		if statusCode != http.StatusOK {
+			return c.handleHTTPError(statusCode, respBody, false)
+		}
+		return json.Unmarshal(respBody, &index)
+	}); err != nil {
+		return "", err
+	}
+
+	var profile, algorithm string
+	for _, f := range index.Fields {
+		if f.Name == "embedding" {
+			profile = f.VectorSearchProfile
+		}
+	}
+	for _, p := range index.VectorSearch.Profiles {
+		if p.Name == profile {
+			algorithm = p.Algorithm
+		}
+	}
+	for _, a := range index.VectorSearch.Algorithms {
+		if a.Name != algorithm {
+			continue
+		}
+		metric := "cosine" // the service default
+		if a.HNSWParameters != nil && a.HNSWParameters.Metric != "" {
+			metric = a.HNSWParameters.Metric
+		} else if a.ExhaustiveKnnParameters != nil && a.ExhaustiveKnnParameters.Metric != "" {
+			metric = a.ExhaustiveKnnParameters.Metric
+		}
+		if metric == "dotProduct" {
+			return "dot", nil
+		}
+		return metric, nil
+	}
+	return "", nil
+}
+
 func (c *azureAISearchClient) CreateCollection(ctx context.Context, cfg CollectionConfig) error {
 	if err := validateCollectionConfig(cfg); err != nil {
 		return err
@@ -184,30 +257,35 @@ func (c *azureAISearchClient) CreateCollection(ctx context.Context, cfg Collecti
 		metric = "euclidean"
 	}
 
-	body := map[string]interface{}{
-		"name": cfg.Name,
-		"fields": []map[string]interface{}{
-			{
-				"name": "id", "type": "Edm.String", "key": true,
-				"retrievable": true, "filterable": true, "sortable": false,
-				"facetable": false, "searchable": false,
-			},
-			{
-				"name": "content", "type": "Edm.String",
-				"searchable": true, "retrievable": true,
-				"filterable": false, "sortable": false, "facetable": false,
-			},
-			{
-				"name": "metadata", "type": "Edm.String",
-				"searchable": false, "retrievable": true,
-				"filterable": false, "sortable": false, "facetable": false,
-			},
-			{
-				"name": "embedding", "type": "Collection(Edm.Single)",
-				"dimensions": cfg.Dimensions, "vectorSearchProfile": "hnsw-profile",
-				"retrievable": true, "stored": true, "searchable": true,
-			},
+	// The key is sortable so filtered deletes can page by key range instead
+	// of $skip, which the service caps at 100,000.
+	fields := []map[string]interface{}{
+		{
+			"name": "id", "type": "Edm.String", "key": true,
+			"retrievable": true, "filterable": true, "sortable": true,
+			"facetable": false, "searchable": false,
+		},
+		{
+			"name": "content", "type": "Edm.String",
+			"searchable": true, "retrievable": true,
+			"filterable": false, "sortable": false, "facetable": false,
+		},
+		{
+			"name": "metadata", "type": "Edm.String",
+			"searchable": false, "retrievable": true,
+			"filterable": false, "sortable": false, "facetable": false,
+		},
+		{
+			"name": "embedding", "type": "Collection(Edm.Single)",
+			"dimensions": cfg.Dimensions, "vectorSearchProfile": "hnsw-profile",
+			"retrievable": true, "stored": true, "searchable": true,
 		},
+	}
+	fields = append(fields, metadataIndexFields(cfg.MetadataFields)...)
+
+	body := map[string]interface{}{
+		"name":   cfg.Name,
+		"fields": fields,
 		"vectorSearch": map[string]interface{}{
 			"profiles": []map[string]interface{}{
 				{"name": "hnsw-profile", "algorithm": "hnsw-config", "vectorizer": nil},
@@ -243,6 +321,7 @@ func (c *azureAISearchClient) CreateCollection(ctx context.Context, cfg Collecti
 	}); err != nil {
 		return err
 	}
+	c.forgetIndexSchema(cfg.Name)
 	return nil
 }
 
@@ -263,6 +342,7 @@ func (c *azureAISearchClient) DeleteCollection(ctx context.Context, name string)
 	}); err != nil {
 		return err
 	}
+	c.forgetIndexSchema(name)
 	return nil
 }
 
@@ -306,27 +386,36 @@ func (c *azureAISearchClient) UpsertDocuments(ctx context.Context, collectionNam
 		return err
 	}
 
-	type azDoc struct {
-		SearchAction string    `json:"@search.action"`
-		ID           string    `json:"id"`
-		Content      string    `json:"content"`
-		Metadata     string    `json:"metadata"`
-		Embedding    []float32 `json:"embedding"`
+	// Declared metadata fields are populated from the payload next to the
+	// full JSON metadata string. The index definition is only needed when
+	// some document carries a payload.
+	schema := &indexSchema{}
+	for _, d := range docs {
+		if len(d.Payload) > 0 {
+			var err error
+			if schema, err = c.indexSchema(ctx, collectionName); err != nil {
+				return newError(ErrCodeProviderError, "UpsertDocuments failed", err)
+			}
+			break
+		}
 	}
 
-	azDocs := make([]azDoc, len(docs))
+	azDocs := make([]map[string]interface{}, len(docs))
 	for i, d := range docs {
 		emb := make([]float32, len(d.Vector))
 		for j, v := range d.Vector {
 			emb[j] = float32(v)
 		}
-		azDocs[i] = azDoc{
-			SearchAction: "mergeOrUpload",
-			ID:           d.ID,
-			Content:      d.Content,
-			Metadata:     payloadToJSON(d.Payload),
-			Embedding:    emb,
+		azDoc, err := schema.indexDocumentFields(d.Payload)
+		if err != nil {
+			return newError(ErrCodeProviderError, fmt.Sprintf("document[%d] %q", i, d.ID), err)
 		}
+		azDoc["@search.action"] = "mergeOrUpload"
+		azDoc["id"] = d.ID
+		azDoc["content"] = d.Content
+		azDoc["metadata"] = payloadToJSON(d.Payload)
+		azDoc["embedding"] = emb
+		azDocs[i] = azDoc
 	}
 
 	body := map[string]interface{}{"value": azDocs}
@@ -413,57 +502,44 @@ func (c *azureAISearchClient) DeleteDocuments(ctx context.Context, collectionNam
 		SearchAction string `json:"@search.action"`
 		ID           string `json:"id"`
 	}
-	azDocs := make([]azDoc, len(ids))
-	for i, id := range ids {
-		azDocs[i] = azDoc{SearchAction: "delete", ID: id}
-	}
-
-	body := map[string]interface{}{"value": azDocs}
 	url := c.apiURL("/indexes/" + collectionName + "/docs/index")
 
-	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
-		respBody, statusCode, err := c.doRequest(ctx, http.MethodPost, url, body)
-		if err != nil {
-			return err
+	// The service accepts at most 1000 actions per indexing request.
+	for start := 0; start < len(ids); start += maxUpsertBatch {
+		end := start + maxUpsertBatch
+		if end > len(ids) {
+			end = len(ids)
 		}
-		if statusCode == http.StatusOK || statusCode == http.StatusCreated {
-			return nil
+		azDocs := make([]azDoc, 0, end-start)
+		for _, id := range ids[start:end] {
+			azDocs = append(azDocs, azDoc{SearchAction: "delete", ID: id})
+		}
+		body := map[string]interface{}{"value": azDocs}
+
+		if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+			respBody, statusCode, err := c.doRequest(ctx, http.MethodPost, url, body)
+			if err != nil {
+				return err
+			}
+			if statusCode == http.StatusOK || statusCode == http.StatusCreated {
+				return nil
+			}
+			return c.handleHTTPError(statusCode, respBody, false)
+		}); err != nil {
+			return newError(ErrCodeProviderError, "DeleteDocuments failed", err)
 		}
-		return c.handleHTTPError(statusCode, respBody, false)
-	}); err != nil {
-		return newError(ErrCodeProviderError, "DeleteDocuments failed", err)
 	}
 	return nil
 }
 
-// DeleteByFilter scrolls all docs with pagination (client-side filter on payload), then deletes matching IDs.
-// Note: metadata is stored as a JSON string (not filterable via OData), so filtering is done client-side.
+// DeleteByFilter deletes every document matching filters. Filters on
+// filterable metadata fields run server-side as an OData $filter; any other
+// payload keys are matched client-side on the documents the $filter returns.
 func (c *azureAISearchClient) DeleteByFilter(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
-	var matchingIDs []string
-	offset := ""
-
-	for {
-		scrollResult, err := c.ScrollDocuments(ctx, ScrollRequest{
-			CollectionName: collectionName,
-			Limit:          1000,
-			Offset:         offset,
-		})
-		if err != nil {
-			return 0, err
-		}
-
-		for _, doc := range scrollResult.Documents {
-			if matchesFilters(doc.Payload, filters) {
-				matchingIDs = append(matchingIDs, doc.ID)
-			}
-		}
-
-		if scrollResult.NextOffset == "" {
-			break
-		}
-		offset = scrollResult.NextOffset
+	matchingIDs, err := c.filterIDs(ctx, collectionName, filters)
+	if err != nil {
+		return 0, err
 	}
-
 	if len(matchingIDs) == 0 {
 		return 0, nil
 	}
@@ -474,22 +550,55 @@ func (c *azureAISearchClient) DeleteByFilter(ctx context.Context, collectionName
 	return int64(len(matchingIDs)), nil
 }
 
-// matchesFilters checks if a payload matches all filters (simple equality).
-func matchesFilters(payload map[string]interface{}, filters map[string]interface{}) bool {
-	if len(filters) == 0 {
-		return true
+// filterIDs returns the IDs of all documents matching filters. Indexes with a
+// sortable key are paged by key range; older indexes fall back to $skip.
+func (c *azureAISearchClient) filterIDs(ctx context.Context, collectionName string, filters map[string]interface{}) ([]string, error) {
+	schema, err := c.indexSchema(ctx, collectionName)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "DeleteByFilter failed", err)
 	}
-	for k, v := range filters {
-		pv, ok := payload[k]
-		if !ok {
-			return false
+	odata, residual, err := schema.splitFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	selectFields := "id"
+	if residual != nil {
+		selectFields = "id,metadata"
+	}
+
+	const pageSize = 1000
+	var ids []string
+	lastID := ""
+	for skip := 0; ; {
+		body := map[string]interface{}{"search": "*", "top": pageSize, "select": selectFields}
+		filter := odata
+		if schema.IDSortable {
+			body["orderby"] = "id asc"
+			if lastID != "" {
+				filter = andFilters(odata, "id gt "+odataString(lastID))
+			}
+		} else {
+			body["skip"] = skip
 		}
-		// Simple equality check
-		if fmt.Sprintf("%v", pv) != fmt.Sprintf("%v", v) {
-			return false
+		if filter != "" {
+			body["filter"] = filter
 		}
+
+		page, _, err := c.search(ctx, collectionName, body, false)
+		if err != nil {
+			return nil, newError(ErrCodeProviderError, "DeleteByFilter failed", err)
+		}
+		for _, doc := range page {
+			if residual.Match(doc.Payload) {
+				ids = append(ids, doc.ID)
+			}
+		}
+		if len(page) < pageSize {
+			return ids, nil
+		}
+		lastID = page[len(page)-1].ID
+		skip += len(page)
 	}
-	return true
 }
 
 func (c *azureAISearchClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
@@ -518,23 +627,37 @@ func (c *azureAISearchClient) CountDocuments(ctx context.Context, collectionName
 		return count, nil
 	}
 
-	// Filtered count: metadata is stored as a JSON string (not OData-filterable),
-	// so we must scroll the full collection and count matching docs client-side.
+	schema, err := c.indexSchema(ctx, collectionName)
+	if err != nil {
+		return 0, newError(ErrCodeProviderError, "CountDocuments (filtered) failed", err)
+	}
+	odata, residual, err := schema.splitFilters(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual == nil {
+		// Every filter is an index field: let the service count the matches.
+		body := map[string]interface{}{"search": "*", "filter": odata, "top": 0, "count": true}
+		if _, count, err = c.search(ctx, collectionName, body, false); err != nil {
+			return 0, newError(ErrCodeProviderError, "CountDocuments (filtered) failed", err)
+		}
+		return count, nil
+	}
+
+	// Keys only stored in the JSON metadata string are matched client-side
+	// on the documents selected by the server-side part of the filter.
 	offset := ""
 	for {
 		scrollResult, err := c.ScrollDocuments(ctx, ScrollRequest{
 			CollectionName: collectionName,
 			Limit:          1000,
 			Offset:         offset,
+			Filters:        filters,
 		})
 		if err != nil {
 			return 0, newError(ErrCodeProviderError, "CountDocuments (filtered) failed", err)
 		}
-		for _, doc := range scrollResult.Documents {
-			if matchesFilters(doc.Payload, filters) {
-				count++
-			}
-		}
+		count += int64(len(scrollResult.Documents))
 		if scrollResult.NextOffset == "" {
 			break
 		}
@@ -556,16 +679,69 @@ func (c *azureAISearchClient) ScrollDocuments(ctx context.Context, req ScrollReq
 		}
 	}
 
-	url := c.apiURL("/indexes/" + req.CollectionName + "/docs/search")
+	selectFields := "id,content,metadata"
+	if req.WithVectors {
+		selectFields += ",embedding"
+	}
 	body := map[string]interface{}{
 		"search": "*",
 		"top":    limit,
 		"skip":   skip,
 		"count":  true,
+		"select": selectFields,
 	}
 
-	var result *ScrollResult
-	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+	// Filters on index fields narrow the page server-side; the rest are
+	// applied to the page, so a page may hold fewer than limit documents.
+	var residual *FilterExpr
+	if len(req.Filters) > 0 {
+		schema, err := c.indexSchema(ctx, req.CollectionName)
+		if err != nil {
+			return nil, newError(ErrCodeProviderError, "ScrollDocuments failed", err)
+		}
+		var odata string
+		if odata, residual, err = schema.splitFilters(req.Filters); err != nil {
+			return nil, err
+		}
+		if odata != "" {
+			body["filter"] = odata
+		}
+	}
+
+	page, total, err := c.search(ctx, req.CollectionName, body, req.WithVectors)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "ScrollDocuments failed", err)
+	}
+
+	docs := make([]Document, 0, len(page))
+	for _, doc := range page {
+		if residual.Match(doc.Payload) {
+			docs = append(docs, doc)
+		}
+	}
+
+	nextOffset := ""
+	newSkip := skip + len(page)
+	if int64(newSkip) < total && len(page) > 0 {
+		nextOffset = strconv.Itoa(newSkip)
+	}
+
+	return &ScrollResult{
+		Documents:  docs,
+		NextOffset: nextOffset,
+		Total:      total,
+	}, nil
+}
+
+// search runs a docs/search request and returns the documents and the
+// @odata.count (when requested).
+func (c *azureAISearchClient) search(ctx context.Context, collectionName string, body map[string]interface{}, withVectors bool) ([]Document, int64, error) {
+	url := c.apiURL("/indexes/" + collectionName + "/docs/search")
+	var (
+		docs  []Document
+		count int64
+	)
+	err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
 		respBody, statusCode, err := c.doRequest(ctx, http.MethodPost, url, body)
 		if err != nil {
 			return err
@@ -584,17 +760,17 @@ func (c *azureAISearchClient) ScrollDocuments(ctx context.Context, req ScrollReq
 			} `json:"value"`
 		}
 		if err := json.Unmarshal(respBody, &resp); err != nil {
-			return fmt.Errorf("azureaisearch: parse scroll response: %w", err)
+			return fmt.Errorf("azureaisearch: parse search response: %w", err)
 		}
 
-		docs := make([]Document, len(resp.Value))
+		docs = make([]Document, len(resp.Value))
 		for i, v := range resp.Value {
 			doc := Document{
 				ID:      v.ID,
 				Content: v.Content,
 				Payload: jsonToPayload(v.Metadata),
 			}
-			if req.WithVectors && len(v.Embedding) > 0 {
+			if withVectors && len(v.Embedding) > 0 {
 				doc.Vector = make([]float64, len(v.Embedding))
 				for j, f := range v.Embedding {
 					doc.Vector[j] = float64(f)
@@ -602,23 +778,30 @@ func (c *azureAISearchClient) ScrollDocuments(ctx context.Context, req ScrollReq
 			}
 			docs[i] = doc
 		}
-
-		nextOffset := ""
-		newSkip := skip + len(resp.Value)
-		if int64(newSkip) < resp.Count {
-			nextOffset = strconv.Itoa(newSkip)
-		}
-
-		result = &ScrollResult{
-			Documents:  docs,
-			NextOffset: nextOffset,
-			Total:      resp.Count,
-		}
+		count = resp.Count
 		return nil
-	}); err != nil {
-		return nil, newError(ErrCodeProviderError, "ScrollDocuments failed", err)
+	})
+	return docs, count, err
+}
+
+// searchFilter applies filters to a search request body: the OData part is
+// set as the request filter and the client-side remainder is returned.
+func (c *azureAISearchClient) searchFilter(ctx context.Context, collectionName string, filters map[string]interface{}, body map[string]interface{}) (*FilterExpr, error) {
+	if len(filters) == 0 {
+		return nil, nil
 	}
-	return result, nil
+	schema, err := c.indexSchema(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	odata, residual, err := schema.splitFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	if odata != "" {
+		body["filter"] = odata
+	}
+	return residual, nil
 }
 
 // ── Search ───────────────────────────────────────────────────────────────────
@@ -633,18 +816,22 @@ func (c *azureAISearchClient) VectorSearch(ctx context.Context, req SearchReques
 		emb[i] = float32(v)
 	}
 
-	body := map[string]interface{}{
-		"vectorQueries": []map[string]interface{}{
-			{
-				"kind":       "vector",
-				"vector":     emb,
-				"fields":     "embedding",
-				"k":          req.TopK,
-				"exhaustive": false,
-			},
+	body := map[string]interface{}{"select": "id,content,metadata"}
+	residual, err := c.searchFilter(ctx, req.CollectionName, req.Filters, body)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
+	}
+	// Over-fetch when part of the filter is matched client-side.
+	limit := searchLimit(req.TopK, residual)
+	body["top"] = limit
+	body["vectorQueries"] = []map[string]interface{}{
+		{
+			"kind":       "vector",
+			"vector":     emb,
+			"fields":     "embedding",
+			"k":          limit,
+			"exhaustive": false,
 		},
-		"top":    req.TopK,
-		"select": "id,content,metadata",
 	}
 
 	url := c.apiURL("/indexes/" + req.CollectionName + "/docs/search")
@@ -657,11 +844,14 @@ func (c *azureAISearchClient) VectorSearch(ctx context.Context, req SearchReques
 		if statusCode != http.StatusOK {
 			return c.handleHTTPError(statusCode, respBody, false)
 		}
-		results, err = parseSearchResponse(respBody, req.ScoreThreshold, req.SkipPayload)
+		results, err = parseSearchResponse(respBody, req.ScoreThreshold, req.SkipPayload, residual)
 		return err
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
 	}
+	if len(results) > req.TopK {
+		results = results[:req.TopK]
+	}
 	return results, nil
 }
 
@@ -675,9 +865,14 @@ func (c *azureAISearchClient) HybridSearch(ctx context.Context, req HybridSearch
 
 	body := map[string]interface{}{
 		"search": req.QueryText,
-		"top":    req.TopK,
 		"select": "id,content,metadata",
 	}
+	residual, err := c.searchFilter(ctx, req.CollectionName, req.Filters, body)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch failed", err)
+	}
+	limit := searchLimit(req.TopK, residual)
+	body["top"] = limit
 
 	if len(req.QueryVector) > 0 {
 		emb := make([]float32, len(req.QueryVector))
@@ -689,7 +884,7 @@ func (c *azureAISearchClient) HybridSearch(ctx context.Context, req HybridSearch
 				"kind":       "vector",
 				"vector":     emb,
 				"fields":     "embedding",
-				"k":          req.TopK,
+				"k":          limit,
 				"exhaustive": false,
 			},
 		}
@@ -706,16 +901,20 @@ func (c *azureAISearchClient) HybridSearch(ctx context.Context, req HybridSearch
 			return c.handleHTTPError(statusCode, respBody, false)
 		}
 		var parseErr error
-		results, parseErr = parseSearchResponse(respBody, req.ScoreThreshold, req.SkipPayload)
+		results, parseErr = parseSearchResponse(respBody, req.ScoreThreshold, req.SkipPayload, residual)
 		return parseErr
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "HybridSearch failed", err)
 	}
+	if len(results) > req.TopK {
+		results = results[:req.TopK]
+	}
 	return results, nil
 }
 
-// parseSearchResponse parses the Azure AI Search search response body.
-func parseSearchResponse(respBody []byte, scoreThreshold float64, skipPayload bool) ([]SearchResult, error) {
+// parseSearchResponse parses the Azure AI Search search response body. Results
+// whose payload does not match the residual (client-side) filters are dropped.
+func parseSearchResponse(respBody []byte, scoreThreshold float64, skipPayload bool, residual *FilterExpr) ([]SearchResult, error) {
 	var resp struct {
 		Value []struct {
 			ID       string  `json:"id"`
@@ -733,13 +932,17 @@ func parseSearchResponse(respBody []byte, scoreThreshold float64, skipPayload bo
 		if scoreThreshold > 0 && v.Score < scoreThreshold {
 			continue
 		}
+		payload := jsonToPayload(v.Metadata)
+		if !residual.Match(payload) {
+			continue
+		}
 		sr := SearchResult{
 			ID:      v.ID,
 			Score:   v.Score,
 			Content: v.Content,
 		}
 		if !skipPayload {
-			sr.Payload = jsonToPayload(v.Metadata)
+			sr.Payload = payload
 		}
 		results = append(results, sr)
 	}
@@ -0,0 +1,428 @@
+package vdbtransfer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-azureaisearch"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Export file formats.
+const (
+	// FormatJSONL writes one JSON document per line: {"id", "vector",
+	// "content", "payload"}. JSONL exports can be resumed.
+	FormatJSONL = "jsonl"
+	// FormatParquet writes one row per document with the columns id,
+	// vector (list of double), content and payload (a JSON string).
+	// A Parquet footer cannot be appended to, so an interrupted Parquet
+	// export starts over.
+	FormatParquet = "parquet"
+)
+
+// ResolveFormat returns format when set, otherwise the format implied by
+// the file extension (.jsonl / .ndjson / .json or .parquet).
+func ResolveFormat(path, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case FormatJSONL, FormatParquet:
+		return strings.ToLower(format), nil
+	case "", "auto":
+	default:
+		return "", fmt.Errorf("format must be jsonl or parquet, got %q", format)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL, nil
+	case ".parquet":
+		return FormatParquet, nil
+	}
+	return "", fmt.Errorf("cannot infer the format of %s: use a .jsonl or .parquet extension or set format", path)
+}
+
+// parquetRow is the Parquet schema of an export file.
+type parquetRow struct {
+	ID      string    `parquet:"id"`
+	Vector  []float64 `parquet:"vector,list"`
+	Content string    `parquet:"content,optional"`
+	Payload string    `parquet:"payload,optional"`
+}
+
+// FileWriter writes documents to an export file. It implements Sink.
+type FileWriter struct {
+	format string
+	f      *os.File
+	bw     *bufio.Writer
+	pw     *parquet.GenericWriter[parquetRow]
+}
+
+// CreateFile creates (or truncates) an export file.
+func CreateFile(path, format string) (*FileWriter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create directory %s: %w", dir, err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %w", path, err)
+	}
+	return newFileWriter(f, format), nil
+}
+
+// ResumeFile reopens a JSONL export to continue it. Bytes past size, the
+// file size at the last checkpoint, belong to a batch that was not
+// checkpointed and are discarded.
+func ResumeFile(path string, size int64) (*FileWriter, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate %s: %w", path, err)
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seek %s: %w", path, err)
+	}
+	return newFileWriter(f, FormatJSONL), nil
+}
+
+func newFileWriter(f *os.File, format string) *FileWriter {
+	w := &FileWriter{format: format, f: f}
+	if format == FormatParquet {
+		w.pw = parquet.NewGenericWriter[parquetRow](f)
+	} else {
+		w.bw = bufio.NewWriterSize(f, 1<<20)
+	}
+	return w
+}
+
+func (w *FileWriter) WriteBatch(_ context.Context, docs []vectordb.Document) error {
+	if w.pw != nil {
+		rows := make([]parquetRow, len(docs))
+		for i, d := range docs {
+			rows[i] = parquetRow{ID: d.ID, Vector: d.Vector, Content: d.Content}
+			if len(d.Payload) > 0 {
+				b, err := json.Marshal(d.Payload)
+				if err != nil {
+					return fmt.Errorf("marshal payload of %q: %w", d.ID, err)
+				}
+				rows[i].Payload = string(b)
+			}
+		}
+		_, err := w.pw.Write(rows)
+		return err
+	}
+	enc := json.NewEncoder(w.bw)
+	for _, d := range docs {
+		if err := enc.Encode(d); err != nil {
+			return fmt.Errorf("encode %q: %w", d.ID, err)
+		}
+	}
+	return nil
+}
+
+// Flush makes the written documents durable and returns the file size,
+// which a checkpoint records for ResumeFile. For Parquet it ends the
+// current row group.
+func (w *FileWriter) Flush() (int64, error) {
+	if w.pw != nil {
+		if err := w.pw.Flush(); err != nil {
+			return 0, err
+		}
+	} else if err := w.bw.Flush(); err != nil {
+		return 0, err
+	}
+	if err := w.f.Sync(); err != nil {
+		return 0, err
+	}
+	return w.f.Seek(0, io.SeekCurrent)
+}
+
+// Close flushes the remaining documents, writes the Parquet footer and
+// closes the file. It returns the final file size.
+func (w *FileWriter) Close() (int64, error) {
+	var err error
+	if w.pw != nil {
+		err = w.pw.Close()
+	} else {
+		err = w.bw.Flush()
+	}
+	if err == nil {
+		err = w.f.Sync()
+	}
+	var size int64
+	if err == nil {
+		size, err = w.f.Seek(0, io.SeekCurrent)
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return size, err
+}
+
+// FileReader reads documents from an export file.
+type FileReader struct {
+	f    *os.File
+	br   *bufio.Reader
+	pr   *parquet.GenericReader[parquetRow]
+	line int
+}
+
+// OpenFile opens an export file for reading.
+func OpenFile(path, format string) (*FileReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	r := &FileReader{f: f}
+	if format == FormatParquet {
+		r.pr = parquet.NewGenericReader[parquetRow](f)
+	} else {
+		r.br = bufio.NewReaderSize(f, 1<<20)
+	}
+	return r, nil
+}
+
+// ReadBatch returns up to n documents. An empty batch means the end of the
+// file.
+func (r *FileReader) ReadBatch(n int) ([]vectordb.Document, error) {
+	if r.pr != nil {
+		rows := make([]parquetRow, n)
+		read, err := r.pr.Read(rows)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("read parquet: %w", err)
+		}
+		docs := make([]vectordb.Document, read)
+		for i, row := range rows[:read] {
+			docs[i] = vectordb.Document{ID: row.ID, Vector: row.Vector, Content: row.Content}
+			if row.Payload != "" {
+				if err := json.Unmarshal([]byte(row.Payload), &docs[i].Payload); err != nil {
+					return nil, fmt.Errorf("payload of %q: %w", row.ID, err)
+				}
+			}
+		}
+		return docs, nil
+	}
+	docs := make([]vectordb.Document, 0, n)
+	for len(docs) < n {
+		line, err := r.br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			r.line++
+			var d vectordb.Document
+			if uerr := json.Unmarshal(line, &d); uerr != nil {
+				return nil, fmt.Errorf("line %d: %w", r.line, uerr)
+			}
+			docs = append(docs, d)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// Skip discards the next n documents, e.g. those a resumed import has
+// already written.
+func (r *FileReader) Skip(n int64) error {
+	if r.pr != nil {
+		return r.pr.SeekToRow(n)
+	}
+	for n > 0 {
+		batch := int64(DefaultBatchSize)
+		if n < batch {
+			batch = n
+		}
+		docs, err := r.ReadBatch(int(batch))
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		n -= int64(len(docs))
+	}
+	return nil
+}
+
+func (r *FileReader) Close() error {
+	if r.pr != nil {
+		r.pr.Close()
+	}
+	return r.f.Close()
+}
+
+// CopyFile streams the remaining documents of r into sink, checking their
+// vectors like Copy. To resume an import, Skip the documents written by the
+// previous run first; the returned Progress counts only this call's
+// documents and never has a cursor.
+func CopyFile(ctx context.Context, r *FileReader, sink Sink, batchSize, dims int, checkpoint func(Progress) error) (Progress, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	p := Progress{Dimensions: dims}
+	for {
+		if err := ctx.Err(); err != nil {
+			return p, err
+		}
+		docs, err := r.ReadBatch(batchSize)
+		if err != nil {
+			return p, err
+		}
+		if len(docs) == 0 {
+			p.Complete = true
+			if checkpoint != nil {
+				if err := checkpoint(p); err != nil {
+					return p, fmt.Errorf("checkpoint: %w", err)
+				}
+			}
+			return p, nil
+		}
+		if err := checkVectors(docs, &p.Dimensions); err != nil {
+			return p, err
+		}
+		if err := sink.WriteBatch(ctx, docs); err != nil {
+			return p, fmt.Errorf("write batch: %w", err)
+		}
+		p.Documents += int64(len(docs))
+		p.Batches++
+		if checkpoint != nil {
+			if err := checkpoint(p); err != nil {
+				return p, fmt.Errorf("checkpoint: %w", err)
+			}
+		}
+	}
+}
+
+// FileDimensions returns the vector size of the first document in an
+// export file, or 0 when the file is empty.
+func FileDimensions(path, format string) (int, error) {
+	r, err := OpenFile(path, format)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	docs, err := r.ReadBatch(1)
+	if err != nil || len(docs) == 0 {
+		return 0, err
+	}
+	return len(docs[0].Vector), nil
+}
+
+// Manifest describes an export file. It is written next to the file as
+// <file>.manifest.json after every batch, so an interrupted export can be
+// resumed, and it lets an import check dimensions and metric up front.
+type Manifest struct {
+	Version        int                    `json:"version"`
+	Format         string                 `json:"format"`
+	Provider       string                 `json:"provider"`
+	Collection     string                 `json:"collection"`
+	Filters        map[string]interface{} `json:"filters,omitempty"`
+	Dimensions     int                    `json:"dimensions"`
+	DistanceMetric string                 `json:"distanceMetric,omitempty"`
+	Documents      int64                  `json:"documents"`
+	// Cursor and Bytes are the scroll cursor and file size at the last
+	// checkpoint of an incomplete export.
+	Cursor    string `json:"cursor,omitempty"`
+	Bytes     int64  `json:"bytes"`
+	Complete  bool   `json:"complete"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// ManifestPath returns the manifest path of an export file.
+func ManifestPath(file string) string { return file + ".manifest.json" }
+
+// ReadManifest reads an export manifest. It returns nil and no error when
+// the file has no manifest.
+func ReadManifest(path string) (*Manifest, error) {
+	m := &Manifest{}
+	if err := readJSON(path, m); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteManifest writes an export manifest atomically.
+func WriteManifest(path string, m *Manifest) error {
+	m.Version = 1
+	m.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	return writeJSONAtomic(path, m)
+}
+
+// Checkpoint records the progress of an import or migration so that it
+// can be resumed. Source and Target identify the copy; a checkpoint for a
+// different copy is ignored.
+type Checkpoint struct {
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	Cursor     string `json:"cursor,omitempty"`
+	Documents  int64  `json:"documents"`
+	Dimensions int    `json:"dimensions"`
+	Complete   bool   `json:"complete"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+// ReadCheckpoint reads a checkpoint file. It returns nil and no error when
+// the file does not exist.
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	cp := &Checkpoint{}
+	if err := readJSON(path, cp); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cp, nil
+}
+
+// WriteCheckpoint writes a checkpoint file atomically.
+func WriteCheckpoint(path string, cp *Checkpoint) error {
+	cp.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	return writeJSONAtomic(path, cp)
+}
+
+func readJSON(path string, v interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSONAtomic writes v to a temporary file and renames it over path,
+// so a crash never leaves a truncated checkpoint behind.
+func writeJSONAtomic(path string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -26,6 +26,18 @@ type CollectionConfig struct {
 	DistanceMetric    string
 	OnDisk            bool
 	ReplicationFactor int
+	// MetadataFields declares payload keys that become real index fields.
+	// Filters on filterable fields run server-side as OData $filter expressions.
+	MetadataFields []MetadataField
+}
+
+// MetadataField declares a payload key that is stored as its own typed index
+// field in addition to the JSON metadata string.
+type MetadataField struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"` // string, int32, int64, double, boolean, datetime, string[]
+	Filterable bool   `json:"filterable"`
+	Facetable  bool   `json:"facetable"`
 }
 
 // SearchRequest encapsulates a vector similarity search.
@@ -1,9 +1,16 @@
 package vectordb
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 const maxUpsertBatch = 1000
 
+// metadataFieldNameRe matches the names Azure AI Search accepts for fields.
+var metadataFieldNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,127}$`)
+
 func validateCollectionConfig(cfg CollectionConfig) error {
 	if cfg.Name == "" {
 		return newError(ErrCodeInvalidCollectionName, "", nil)
@@ -11,6 +18,30 @@ func validateCollectionConfig(cfg CollectionConfig) error {
 	if cfg.Dimensions <= 0 {
 		return newError(ErrCodeInvalidDimensions, "", nil)
 	}
+	return validateMetadataFields(cfg.MetadataFields)
+}
+
+func validateMetadataFields(fields []MetadataField) error {
+	seen := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		if !metadataFieldNameRe.MatchString(f.Name) || strings.HasPrefix(strings.ToLower(f.Name), "azuresearch") {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: name %q must start with a letter and contain only letters, digits and underscores", i, f.Name), nil)
+		}
+		if reservedFieldNames[f.Name] {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: name %q is reserved", i, f.Name), nil)
+		}
+		if seen[f.Name] {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: duplicate name %q", i, f.Name), nil)
+		}
+		seen[f.Name] = true
+		if _, ok := edmType(f.Type); !ok {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: unsupported type %q (string, int32, int64, double, boolean, datetime, string[])", i, f.Type), nil)
+		}
+	}
 	return nil
 }
 
@@ -1,3 +0,0 @@
-module github.com/amikos-tech/pure-onnx
-
-go 1.21
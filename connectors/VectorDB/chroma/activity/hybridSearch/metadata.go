@@ -23,6 +23,9 @@ type Input struct {
 	Alpha          float64                `md:"alpha"`
 	Filters        map[string]interface{} `md:"filters"`
 	SkipPayload    bool                   `md:"skipPayload"`
+	MMR            bool                   `md:"mmr"`
+	MMRLambda      float64                `md:"mmrLambda"`
+	MMRFetchK      int                    `md:"mmrFetchK"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
@@ -35,6 +38,9 @@ func (i *Input) ToMap() map[string]interface{} {
 		"alpha":          i.Alpha,
 		"filters":        i.Filters,
 		"skipPayload":    i.SkipPayload,
+		"mmr":            i.MMR,
+		"mmrLambda":      i.MMRLambda,
+		"mmrFetchK":      i.MMRFetchK,
 	}
 }
 
@@ -81,6 +87,22 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 	if val, ok := v["skipPayload"]; ok {
 		i.SkipPayload, _ = val.(bool)
 	}
+	if val, ok := v["mmr"]; ok {
+		i.MMR, _ = val.(bool)
+	}
+	if val, ok := v["mmrLambda"]; ok {
+		if f, ok := val.(float64); ok {
+			i.MMRLambda = f
+		}
+	}
+	if val, ok := v["mmrFetchK"]; ok {
+		switch n := val.(type) {
+		case int:
+			i.MMRFetchK = n
+		case float64:
+			i.MMRFetchK = int(n)
+		}
+	}
 	return nil
 }
 
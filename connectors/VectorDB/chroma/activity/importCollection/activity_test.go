@@ -0,0 +1,189 @@
+package importCollection
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-chroma"
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/connector"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/testutil/mock"
+	vdbtransfer "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/transfer"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/core/support/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeActivityContext struct {
+	inputs  map[string]interface{}
+	outputs map[string]interface{}
+}
+
+func (f *fakeActivityContext) ActivityHost() activity.Host        { return nil }
+func (f *fakeActivityContext) Name() string                       { return "test" }
+func (f *fakeActivityContext) GetSetting(name string) interface{} { return nil }
+func (f *fakeActivityContext) GetInput(name string) interface{} {
+	if f.inputs == nil {
+		return nil
+	}
+	return f.inputs[name]
+}
+func (f *fakeActivityContext) SetOutput(name string, value interface{}) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	f.outputs[name] = value
+	return nil
+}
+func (f *fakeActivityContext) GetInputObject(obj data.StructValue) error {
+	return obj.FromMap(f.inputs)
+}
+func (f *fakeActivityContext) SetOutputObject(obj data.StructValue) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	for k, v := range obj.ToMap() {
+		f.outputs[k] = v
+	}
+	return nil
+}
+func (f *fakeActivityContext) GetSharedTempData() map[string]interface{} { return nil }
+func (f *fakeActivityContext) Logger() log.Logger                        { return log.RootLogger() }
+func (f *fakeActivityContext) GetTracingContext() trace.TracingContext   { return nil }
+func (f *fakeActivityContext) GoContext() context.Context                { return context.Background() }
+
+func newTestConn(client vectordb.VectorDBClient) *vectordbconnector.ChromaConnection {
+	s := &vectordbconnector.Settings{Host: "localhost", TimeoutSeconds: 5}
+	return vectordbconnector.NewConnectionForTest("test-conn", client, s)
+}
+
+var testDocs = []vectordb.Document{
+	{ID: "a", Vector: []float64{1, 0}, Content: "alpha", Payload: map[string]interface{}{"lang": "en"}},
+	{ID: "b", Vector: []float64{0, 1}, Content: "beta"},
+}
+
+// writeExport writes docs to a file with a manifest (unless m is nil).
+func writeExport(t *testing.T, name string, docs []vectordb.Document, m *vdbtransfer.Manifest) string {
+	path := filepath.Join(t.TempDir(), name)
+	format, err := vdbtransfer.ResolveFormat(path, "")
+	require.NoError(t, err)
+	w, err := vdbtransfer.CreateFile(path, format)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBatch(context.Background(), docs))
+	_, err = w.Close()
+	require.NoError(t, err)
+	if m != nil {
+		require.NoError(t, vdbtransfer.WriteManifest(vdbtransfer.ManifestPath(path), m))
+	}
+	return path
+}
+
+func testSettings() *Settings {
+	return &Settings{Format: "auto", CreateCollection: true, DistanceMetric: "cosine", Verify: true, TimeoutSeconds: 30}
+}
+
+func TestImportCollection_CreatesAndVerifies(t *testing.T) {
+	for _, name := range []string{"kb.jsonl", "kb.parquet"} {
+		t.Run(name, func(t *testing.T) {
+			path := writeExport(t, name, testDocs, &vdbtransfer.Manifest{Collection: "kb", Dimensions: 2, DistanceMetric: "cosine", Documents: 2, Complete: true})
+
+			mc := &mockclient.VectorDBClient{}
+			mc.On("CollectionExists", mock.Anything, "kb2").Return(false, nil)
+			mc.On("CreateCollection", mock.Anything, vectordb.CollectionConfig{Name: "kb2", Dimensions: 2, DistanceMetric: "cosine"}).Return(nil)
+			mc.On("UpsertDocuments", mock.Anything, "kb2", testDocs).Return(nil)
+			mc.On("CountDocuments", mock.Anything, "kb2", map[string]interface{}(nil)).Return(int64(2), nil)
+
+			a := &Activity{conn: newTestConn(mc), settings: testSettings()}
+			ctx := &fakeActivityContext{inputs: map[string]interface{}{"collectionName": "kb2", "filePath": path}}
+			ok, err := a.Eval(ctx)
+			require.True(t, ok)
+			require.NoError(t, err)
+			assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+			assert.Equal(t, true, ctx.outputs["created"])
+			assert.Equal(t, int64(2), ctx.outputs["importedCount"])
+			assert.Equal(t, true, ctx.outputs["verified"])
+			assert.Equal(t, int64(2), ctx.outputs["targetCount"])
+			mc.AssertExpectations(t)
+
+			cp, err := vdbtransfer.ReadCheckpoint(checkpointPath(path, "kb2"))
+			require.NoError(t, err)
+			assert.True(t, cp.Complete)
+		})
+	}
+}
+
+func TestImportCollection_ResumesFromCheckpoint(t *testing.T) {
+	path := writeExport(t, "kb.jsonl", testDocs, nil)
+	require.NoError(t, vdbtransfer.WriteCheckpoint(checkpointPath(path, "kb"), &vdbtransfer.Checkpoint{
+		Source: path, Target: "kb", Documents: 1, Dimensions: 2,
+	}))
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "kb").Return(true, nil)
+	mc.On("ScrollDocuments", mock.Anything, mock.Anything).Return(&vectordb.ScrollResult{Documents: testDocs[:1]}, nil)
+	mc.On("UpsertDocuments", mock.Anything, "kb", testDocs[1:]).Return(nil)
+	mc.On("CountDocuments", mock.Anything, "kb", map[string]interface{}(nil)).Return(int64(1), nil)
+
+	a := &Activity{conn: newTestConn(mc), settings: testSettings()}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"collectionName": "kb", "filePath": path}}
+	_, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, true, ctx.outputs["resumed"])
+	assert.Equal(t, int64(1), ctx.outputs["importedCount"])
+	assert.Equal(t, int64(2), ctx.outputs["documentCount"])
+	// The import succeeded but the target under-counts: reported, not fatal.
+	assert.Equal(t, false, ctx.outputs["verified"])
+	assert.Contains(t, ctx.outputs["verificationError"], "expected at least 2")
+	mc.AssertExpectations(t)
+}
+
+func TestImportCollection_RejectsMismatches(t *testing.T) {
+	cases := map[string]struct {
+		manifest *vdbtransfer.Manifest
+		exists   bool
+		existing []vectordb.Document
+		want     string
+	}{
+		"incomplete export": {
+			manifest: &vdbtransfer.Manifest{Dimensions: 2, Documents: 1},
+			want:     "incomplete",
+		},
+		"metric": {
+			manifest: &vdbtransfer.Manifest{Dimensions: 2, DistanceMetric: "euclidean", Documents: 2, Complete: true},
+			want:     "distance metric mismatch",
+		},
+		"dimensions": {
+			exists:   true,
+			existing: []vectordb.Document{{ID: "x", Vector: []float64{1, 2, 3}}},
+			want:     "3-dimensional",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := writeExport(t, "kb.jsonl", testDocs, tc.manifest)
+			mc := &mockclient.VectorDBClient{}
+			mc.On("CollectionExists", mock.Anything, "kb").Return(tc.exists, nil)
+			mc.On("ScrollDocuments", mock.Anything, mock.Anything).Return(&vectordb.ScrollResult{Documents: tc.existing}, nil)
+
+			a := &Activity{conn: newTestConn(mc), settings: testSettings()}
+			ctx := &fakeActivityContext{inputs: map[string]interface{}{"collectionName": "kb", "filePath": path}}
+			ok, err := a.Eval(ctx)
+			assert.True(t, ok)
+			assert.NoError(t, err)
+			assert.Equal(t, false, ctx.outputs["success"])
+			assert.Contains(t, ctx.outputs["error"], tc.want)
+			mc.AssertNotCalled(t, "UpsertDocuments", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestImportCollection_MissingCollection(t *testing.T) {
+	a := &Activity{conn: newTestConn(&mockclient.VectorDBClient{}), settings: testSettings()}
+	_, err := a.Eval(&fakeActivityContext{inputs: map[string]interface{}{"filePath": "/tmp/kb.jsonl"}})
+	assert.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 // ChunkStrategy selects the text-splitting algorithm.
@@ -31,6 +33,15 @@ const (
 	ChunkStrategyHeading ChunkStrategy = "heading"
 )
 
+// Chunk linkage fields written on every chunk. Unlike the _ provenance keys
+// they are part of the payload contract: ragQuery reads them to fetch a
+// chunk's neighbours or its whole parent section (context expansion).
+const (
+	parentIDKey   = "parentId"   // ID shared by all chunks of one document
+	chunkIndexKey = "chunkIndex" // 0-based position of the chunk in the document
+	chunkCountKey = "chunkCount" // number of chunks the document produced
+)
+
 // ChunkConfig holds the resolved chunking parameters derived from Settings.
 type ChunkConfig struct {
 	Strategy ChunkStrategy
@@ -74,29 +85,57 @@ const maxEmbeddingInputChars = 3500
 // expandChunks takes the parsed input documents and, for each document, splits
 // its Text field according to cfg. The returned slice replaces the input slice:
 // each chunk becomes an independent RawDocument inheriting the parent's metadata
-// plus provenance keys (_source_id, _chunk_index, _chunk_total, _chunk_strategy).
+// plus provenance keys (_source_id, _chunk_index, _chunk_total, _chunk_strategy)
+// and the linkage keys parentId, chunkIndex and chunkCount. parentId is the
+// document id, else its incremental-mode source, else a generated UUID.
+//
+// Documents extracted from files carry Sections (pages, slides, sheets…). Each
+// section is chunked on its own, so a chunk never spans two pages, and the
+// section's location metadata (page, slide, sheet…) is copied onto its chunks.
+// Every chunk of a document that contains Markdown headings also gets a
+// section_path key — the heading trail in effect at the chunk, e.g.
+// "Installation > Prerequisites".
 //
 // When EnableChunking is false this function is never called; callers pass
 // through rawDocs unchanged.
 func expandChunks(docs []RawDocument, cfg ChunkConfig) []RawDocument {
 	var result []RawDocument
 	for _, doc := range docs {
-		chunks := chunkText(doc.Text, cfg)
-
-		// Safety cap: sub-split any chunk that exceeds the embedding model's
-		// effective context length. This guards against strategies like
-		// paragraph/heading producing oversized segments (e.g. tables with no
-		// blank-line breaks, large PDF sections, binary-fallback content).
-		var capped []string
-		for _, c := range chunks {
-			if len([]rune(c)) > maxEmbeddingInputChars {
-				capped = append(capped, chunkFixed(c, maxEmbeddingInputChars, 0)...)
-			} else {
-				capped = append(capped, c)
+		sections := doc.Sections
+		if len(sections) == 0 {
+			sections = []DocumentSection{{Text: doc.Text}}
+		}
+
+		type sectionChunk struct {
+			text string
+			meta map[string]interface{}
+		}
+		var chunks []sectionChunk
+		for _, sec := range sections {
+			// Safety cap: sub-split any chunk that exceeds the embedding model's
+			// effective context length. This guards against strategies like
+			// paragraph/heading producing oversized segments (e.g. tables with no
+			// blank-line breaks, large PDF sections, binary-fallback content).
+			for _, c := range chunkText(sec.Text, cfg) {
+				if len([]rune(c)) > maxEmbeddingInputChars {
+					for _, sub := range chunkFixed(c, maxEmbeddingInputChars, 0) {
+						chunks = append(chunks, sectionChunk{sub, sec.Metadata})
+					}
+				} else {
+					chunks = append(chunks, sectionChunk{c, sec.Metadata})
+				}
 			}
 		}
-		chunks = capped
+
+		var trail headingTrail
 		total := len(chunks)
+		parentID := doc.ID
+		if parentID == "" {
+			parentID, _ = doc.Metadata[ingestSourceKey].(string)
+		}
+		if parentID == "" {
+			parentID = uuid.NewString()
+		}
 		for i, chunk := range chunks {
 			// Build chunk ID: "<parent-id>-chunk-<i>" or leave blank for UUID assignment.
 			chunkID := ""
@@ -105,19 +144,28 @@ func expandChunks(docs []RawDocument, cfg ChunkConfig) []RawDocument {
 			}
 
 			// Deep-copy parent metadata so each chunk has an independent map.
-			meta := make(map[string]interface{}, len(doc.Metadata)+4)
+			meta := make(map[string]interface{}, len(doc.Metadata)+len(chunk.meta)+8)
 			for k, v := range doc.Metadata {
 				meta[k] = v
 			}
+			for k, v := range chunk.meta {
+				meta[k] = v
+			}
+			if path := trail.advance(chunk.text); path != "" {
+				meta["section_path"] = path
+			}
 			// Provenance fields — written under reserved _ prefix to avoid clashes.
 			meta["_source_id"] = doc.ID
 			meta["_chunk_index"] = i
 			meta["_chunk_total"] = total
 			meta["_chunk_strategy"] = string(cfg.Strategy)
+			meta[parentIDKey] = parentID
+			meta[chunkIndexKey] = i
+			meta[chunkCountKey] = total
 
 			result = append(result, RawDocument{
 				ID:       chunkID,
-				Text:     chunk,
+				Text:     chunk.text,
 				Metadata: meta,
 			})
 		}
@@ -125,6 +173,56 @@ func expandChunks(docs []RawDocument, cfg ChunkConfig) []RawDocument {
 	return result
 }
 
+// headingLineRe captures the level marker and title of a Markdown ATX heading.
+var headingLineRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+// headingTrail tracks the open Markdown headings while a document's chunks are
+// visited in order.
+type headingTrail struct {
+	titles [6]string
+}
+
+// advance returns the section path for a chunk and then applies the chunk's
+// headings for the chunks that follow. Headings at the very start of the
+// chunk (before any body text) are part of its own path, so a chunk produced
+// by the heading strategy is labelled with the heading it begins with.
+func (t *headingTrail) advance(chunk string) string {
+	var path string
+	leading := true
+	for _, line := range strings.Split(chunk, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		m := headingLineRe.FindStringSubmatch(line)
+		if m == nil {
+			if leading {
+				path, leading = t.path(), false
+			}
+			continue
+		}
+		level := len(m[1])
+		t.titles[level-1] = m[2]
+		for j := level; j < len(t.titles); j++ {
+			t.titles[j] = ""
+		}
+	}
+	if leading {
+		path = t.path()
+	}
+	return path
+}
+
+func (t *headingTrail) path() string {
+	var parts []string
+	for _, title := range t.titles {
+		if title != "" {
+			parts = append(parts, title)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
 // chunkText dispatches to the appropriate splitting implementation.
 // Returns at least one element (the original text) even when no split occurs.
 func chunkText(text string, cfg ChunkConfig) []string {
@@ -0,0 +1,603 @@
+package ingestDocuments
+
+// docextract_ooxml.go — PowerPoint (.pptx) and Excel (.xlsx) extraction.
+//
+// Both formats are ZIP archives of XML parts, read with stdlib archive/zip and
+// encoding/xml like .docx. Part order comes from the relationship (.rels)
+// files, so slides and sheets appear in presentation/workbook order rather
+// than archive order.
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	drawingMLNS = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	relsNS      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// ooxmlPackage is an opened Office Open XML ZIP archive.
+type ooxmlPackage struct {
+	files map[string]*zip.File
+}
+
+func openOOXML(data []byte, kind string) (*ooxmlPackage, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: not a valid zip archive: %w", kind, err)
+	}
+	pkg := &ooxmlPackage{files: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		pkg.files[f.Name] = f
+	}
+	return pkg, nil
+}
+
+// open returns a reader for the named part, or nil when the part is absent.
+func (p *ooxmlPackage) open(name string) (io.ReadCloser, error) {
+	f, ok := p.files[name]
+	if !ok {
+		return nil, nil
+	}
+	return f.Open()
+}
+
+// rels reads the relationship part of the given part ("ppt/presentation.xml"
+// → "ppt/_rels/presentation.xml.rels") and returns relationship ID → target
+// part name, plus relationship ID → type.
+func (p *ooxmlPackage) rels(part string) (targets, types map[string]string, err error) {
+	dir, file := path.Split(part)
+	rc, err := p.open(dir + "_rels/" + file + ".rels")
+	if err != nil || rc == nil {
+		return nil, nil, err
+	}
+	defer rc.Close()
+	var doc struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Type   string `xml:"Type,attr"`
+			Target string `xml:"Target,attr"`
+			Mode   string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("cannot parse relationships of %s: %w", part, err)
+	}
+	targets = make(map[string]string, len(doc.Rels))
+	types = make(map[string]string, len(doc.Rels))
+	for _, r := range doc.Rels {
+		if r.Mode == "External" {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			targets[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			targets[r.ID] = path.Clean(path.Join(dir, r.Target))
+		}
+		types[r.ID] = r.Type
+	}
+	return targets, types, nil
+}
+
+// partsByNumber lists the parts matching re (whose first group is a number) in
+// numeric order — the fallback when the relationship parts are missing.
+func (p *ooxmlPackage) partsByNumber(re *regexp.Regexp) []string {
+	type numbered struct {
+		name string
+		n    int
+	}
+	var found []numbered
+	for name := range p.files {
+		if m := re.FindStringSubmatch(name); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			found = append(found, numbered{name, n})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].n < found[j].n })
+	names := make([]string, len(found))
+	for i, f := range found {
+		names[i] = f.name
+	}
+	return names
+}
+
+// ── PowerPoint ────────────────────────────────────────────────────────────────
+
+var pptxSlidePartRe = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+
+// extractPPTX extracts a .pptx presentation. Each slide becomes one section:
+//
+//	## Slide 3: Quarterly Results
+//	Revenue grew 12%
+//	| Region | Q1 | Q2 |
+//	Notes: speaker notes, when present
+//
+// Section metadata: "slide" (1-based position) and "slide_title".
+func extractPPTX(data []byte) (*ExtractedDocument, error) {
+	pkg, err := openOOXML(data, "pptx")
+	if err != nil {
+		return nil, err
+	}
+	slides, err := pptxSlideOrder(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("pptx: %w", err)
+	}
+	if len(slides) == 0 {
+		return nil, fmt.Errorf("pptx: no slides found — is this a valid .pptx file?")
+	}
+
+	doc := &ExtractedDocument{Metadata: map[string]interface{}{"slide_count": len(slides)}}
+	for i, part := range slides {
+		title, body, err := pptxReadShapes(pkg, part)
+		if err != nil {
+			return nil, fmt.Errorf("pptx: %s: %w", part, err)
+		}
+		if notes, err := pptxSlideNotes(pkg, part); err != nil {
+			return nil, fmt.Errorf("pptx: notes of %s: %w", part, err)
+		} else if notes != "" {
+			body = append(body, "Notes: "+notes)
+		}
+		if title == "" && len(body) == 0 {
+			continue
+		}
+		heading := fmt.Sprintf("## Slide %d", i+1)
+		meta := map[string]interface{}{"slide": i + 1}
+		if title != "" {
+			heading += ": " + title
+			meta["slide_title"] = title
+		}
+		doc.Sections = append(doc.Sections, DocumentSection{
+			Text:     strings.TrimSpace(heading + "\n" + strings.Join(body, "\n")),
+			Metadata: meta,
+		})
+	}
+	return doc, nil
+}
+
+// pptxSlideOrder returns the slide part names in presentation order, read
+// from the <p:sldIdLst> of ppt/presentation.xml.
+func pptxSlideOrder(pkg *ooxmlPackage) ([]string, error) {
+	const part = "ppt/presentation.xml"
+	targets, _, err := pkg.rels(part)
+	if err != nil {
+		return nil, err
+	}
+	if targets == nil {
+		return pkg.partsByNumber(pptxSlidePartRe), nil
+	}
+	rc, err := pkg.open(part)
+	if err != nil {
+		return nil, err
+	}
+	if rc == nil {
+		return pkg.partsByNumber(pptxSlidePartRe), nil
+	}
+	defer rc.Close()
+
+	var slides []string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", part, err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "sldId" {
+			for _, a := range se.Attr {
+				if a.Name.Space == relsNS && a.Name.Local == "id" {
+					if t, ok := targets[a.Value]; ok {
+						slides = append(slides, t)
+					}
+				}
+			}
+		}
+	}
+	if len(slides) == 0 {
+		return pkg.partsByNumber(pptxSlidePartRe), nil
+	}
+	return slides, nil
+}
+
+// pptxSkippedPlaceholders are placeholder types whose text is slide furniture
+// (numbers, dates, footers, the slide image on a notes page), not content.
+var pptxSkippedPlaceholders = map[string]bool{
+	"sldNum": true, "dt": true, "ftr": true, "hdr": true, "sldImg": true,
+}
+
+// pptxReadShapes returns the title and the body lines of a slide or notes
+// part. The title is the text of the title / centred-title placeholder; every
+// other shape contributes one line per paragraph and every table one
+// "| a | b |" line per row.
+func pptxReadShapes(pkg *ooxmlPackage, part string) (title string, body []string, err error) {
+	rc, err := pkg.open(part)
+	if err != nil {
+		return "", nil, err
+	}
+	if rc == nil {
+		return "", nil, fmt.Errorf("part not found")
+	}
+	defer rc.Close()
+
+	var (
+		para      strings.Builder
+		cell      strings.Builder
+		row       []string
+		shapeText []string
+		isTitle   bool
+		skipShape bool
+		inText    bool
+		inTable   bool
+	)
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("XML parse error: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "sp":
+				shapeText, isTitle, skipShape = nil, false, false
+			case "ph":
+				switch typ := xmlAttr(t, "type"); {
+				case typ == "title" || typ == "ctrTitle":
+					isTitle = true
+				case pptxSkippedPlaceholders[typ]:
+					skipShape = true
+				}
+			case "tbl":
+				inTable = true
+			case "tr":
+				row = row[:0]
+			case "tc":
+				cell.Reset()
+			case "p":
+				if t.Name.Space == drawingMLNS {
+					para.Reset()
+				}
+			case "t":
+				inText = t.Name.Space == drawingMLNS
+			case "br":
+				if t.Name.Space == drawingMLNS {
+					para.WriteByte(' ')
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if t.Name.Space != drawingMLNS {
+					continue
+				}
+				text := strings.TrimSpace(para.String())
+				if text == "" {
+					continue
+				}
+				if inTable {
+					if cell.Len() > 0 {
+						cell.WriteByte(' ')
+					}
+					cell.WriteString(text)
+				} else {
+					shapeText = append(shapeText, text)
+				}
+			case "tc":
+				row = append(row, cell.String())
+			case "tr":
+				if line := tableRow(row); line != "" {
+					body = append(body, line)
+				}
+			case "tbl":
+				inTable = false
+			case "sp":
+				switch {
+				case skipShape:
+				case isTitle && title == "":
+					title = strings.Join(shapeText, " ")
+				default:
+					body = append(body, shapeText...)
+				}
+				shapeText = nil
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+	return title, body, nil
+}
+
+// pptxSlideNotes returns the speaker notes linked from a slide, joined into
+// one line, or "" when the slide has none.
+func pptxSlideNotes(pkg *ooxmlPackage, slidePart string) (string, error) {
+	targets, types, err := pkg.rels(slidePart)
+	if err != nil {
+		return "", err
+	}
+	for id, typ := range types {
+		if strings.HasSuffix(typ, "/notesSlide") {
+			_, lines, err := pptxReadShapes(pkg, targets[id])
+			if err != nil {
+				return "", err
+			}
+			return strings.Join(lines, " "), nil
+		}
+	}
+	return "", nil
+}
+
+// ── Excel ─────────────────────────────────────────────────────────────────────
+
+var xlsxSheetPartRe = regexp.MustCompile(`^xl/worksheets/sheet(\d+)\.xml$`)
+
+// xlsxCellRefRe splits a cell reference such as "AB12" into column and row.
+var xlsxCellRefRe = regexp.MustCompile(`^([A-Z]+)(\d+)$`)
+
+// extractXLSX extracts an .xlsx workbook. Each worksheet becomes one section
+// headed "## Sheet: <name>", with one line per row that pairs every value with
+// its column header (see renderTable). Section metadata: "sheet" (name) and
+// "sheet_index" (1-based position in the workbook).
+//
+// Cells are read as stored: formulas contribute their cached result, and dates
+// appear as Excel serial numbers because number formats are not applied.
+func extractXLSX(data []byte) (*ExtractedDocument, error) {
+	pkg, err := openOOXML(data, "xlsx")
+	if err != nil {
+		return nil, err
+	}
+	shared, err := xlsxSharedStrings(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: %w", err)
+	}
+	sheets, err := xlsxSheets(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: %w", err)
+	}
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("xlsx: no worksheets found — is this a valid .xlsx file?")
+	}
+
+	doc := &ExtractedDocument{Metadata: map[string]interface{}{"sheet_count": len(sheets)}}
+	for i, sh := range sheets {
+		rows, rowNums, err := xlsxReadSheet(pkg, sh.part, shared)
+		if err != nil {
+			return nil, fmt.Errorf("xlsx: sheet %q: %w", sh.name, err)
+		}
+		table := renderTable(rows, rowNums)
+		if table == "" {
+			continue
+		}
+		doc.Sections = append(doc.Sections, DocumentSection{
+			Text:     "## Sheet: " + sh.name + "\n" + table,
+			Metadata: map[string]interface{}{"sheet": sh.name, "sheet_index": i + 1},
+		})
+	}
+	return doc, nil
+}
+
+type xlsxSheet struct {
+	name string
+	part string
+}
+
+// xlsxSheets returns the worksheets in workbook order.
+func xlsxSheets(pkg *ooxmlPackage) ([]xlsxSheet, error) {
+	const part = "xl/workbook.xml"
+	targets, _, err := pkg.rels(part)
+	if err != nil {
+		return nil, err
+	}
+	var sheets []xlsxSheet
+	if targets != nil {
+		rc, err := pkg.open(part)
+		if err != nil {
+			return nil, err
+		}
+		if rc == nil {
+			return nil, fmt.Errorf("%s not found — is this a valid .xlsx file?", part)
+		}
+		defer rc.Close()
+		var wb struct {
+			Sheets []struct {
+				Name  string     `xml:"name,attr"`
+				Attrs []xml.Attr `xml:",any,attr"`
+			} `xml:"sheets>sheet"`
+		}
+		if err := xml.NewDecoder(rc).Decode(&wb); err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", part, err)
+		}
+		for _, s := range wb.Sheets {
+			for _, a := range s.Attrs {
+				if a.Name.Space == relsNS && a.Name.Local == "id" {
+					// Chart sheets and macro sheets are not worksheets.
+					if t, ok := targets[a.Value]; ok && strings.HasPrefix(t, "xl/worksheets/") {
+						sheets = append(sheets, xlsxSheet{name: s.Name, part: t})
+					}
+				}
+			}
+		}
+	}
+	if len(sheets) == 0 {
+		for i, p := range pkg.partsByNumber(xlsxSheetPartRe) {
+			sheets = append(sheets, xlsxSheet{name: fmt.Sprintf("Sheet%d", i+1), part: p})
+		}
+	}
+	return sheets, nil
+}
+
+// xlsxSharedStrings reads xl/sharedStrings.xml, the string table that cells
+// of type "s" index into. Rich-text runs of one entry are concatenated;
+// phonetic guides (<rPh>) are skipped.
+func xlsxSharedStrings(pkg *ooxmlPackage) ([]string, error) {
+	rc, err := pkg.open("xl/sharedStrings.xml")
+	if err != nil || rc == nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var (
+		out      []string
+		sb       strings.Builder
+		inText   bool
+		inPhonet bool
+	)
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse xl/sharedStrings.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				sb.Reset()
+			case "rPh":
+				inPhonet = true
+			case "t":
+				inText = !inPhonet
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, sb.String())
+			case "rPh":
+				inPhonet = false
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return out, nil
+}
+
+// xlsxReadSheet returns the non-empty rows of a worksheet as cell values
+// indexed by column, together with each row's 1-based sheet row number.
+func xlsxReadSheet(pkg *ooxmlPackage, part string, shared []string) ([][]string, []int, error) {
+	rc, err := pkg.open(part)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rc == nil {
+		return nil, nil, fmt.Errorf("part %s not found", part)
+	}
+	defer rc.Close()
+
+	var (
+		rows    [][]string
+		rowNums []int
+		row     []string
+		rowNum  int
+		col     int // 0-based column of the current cell
+		typ     string
+		val     strings.Builder
+		inValue bool
+	)
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("XML parse error: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row = nil
+				if n, err := strconv.Atoi(xmlAttr(t, "r")); err == nil {
+					rowNum = n
+				} else {
+					rowNum++
+				}
+			case "c":
+				typ = xmlAttr(t, "t")
+				val.Reset()
+				if m := xlsxCellRefRe.FindStringSubmatch(xmlAttr(t, "r")); m != nil {
+					col = columnIndex(m[1])
+				} else {
+					col = len(row)
+				}
+			case "v", "t":
+				// <v> holds the value; <t> the text of an inline string (<is>).
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				v := val.String()
+				switch typ {
+				case "s":
+					if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && i >= 0 && i < len(shared) {
+						v = shared[i]
+					}
+				case "b":
+					v = map[string]string{"0": "FALSE", "1": "TRUE"}[strings.TrimSpace(v)]
+				}
+				if strings.TrimSpace(v) == "" {
+					continue
+				}
+				for len(row) <= col {
+					row = append(row, "")
+				}
+				row[col] = v
+			case "row":
+				if len(row) > 0 {
+					rows = append(rows, row)
+					rowNums = append(rowNums, rowNum)
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				val.Write(t)
+			}
+		}
+	}
+	return rows, rowNums, nil
+}
+
+// columnIndex converts a column name ("A", "Z", "AA") to a 0-based index.
+func columnIndex(name string) int {
+	n := 0
+	for _, r := range name {
+		n = n*26 + int(r-'A') + 1
+	}
+	return n - 1
+}
+
+// columnName converts a 0-based column index to its spreadsheet name.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -1,6 +1,8 @@
 package ingestDocuments
 
 import (
+	"archive/zip"
+	"bytes"
 	"strings"
 	"testing"
 
@@ -133,3 +135,224 @@ func TestExtractTextFromBytes_LegacyDoc(t *testing.T) {
 	assert.Error(t, err, ".doc should return an unsupported error")
 	assert.Contains(t, err.Error(), ".doc")
 }
+
+// ── Office Open XML fixtures ──────────────────────────────────────────────────
+
+// zipParts builds an in-memory ZIP archive from part name → content.
+func zipParts(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+const (
+	wordNS  = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	pmlNS   = `xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	sheetNS = `xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+)
+
+// ── DOCX ──────────────────────────────────────────────────────────────────────
+
+func TestParseWordXML_HeadingsAndTables(t *testing.T) {
+	xml := `<w:document ` + wordNS + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>Runbook</w:t></w:r></w:p>
+<w:p><w:r><w:t>Intro text.</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Ports</w:t></w:r></w:p>
+<w:tbl>
+ <w:tr><w:tc><w:p><w:r><w:t>Service</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Port</w:t></w:r></w:p></w:tc></w:tr>
+ <w:tr><w:tc><w:p><w:r><w:t>API</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>8080</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+<w:p><w:pPr><w:outlineLvl w:val="2"/></w:pPr><w:r><w:t>Notes</w:t></w:r></w:p>
+<w:p><w:r><w:t>Body.</w:t></w:r></w:p>
+</w:body></w:document>`
+	got, err := parseWordXML(strings.NewReader(xml))
+	require.NoError(t, err)
+	assert.Equal(t, "# Runbook\nIntro text.\n\n## Ports\n| Service | Port |\n| API | 8080 |\n\n### Notes\nBody.", got)
+}
+
+// ── PPTX ──────────────────────────────────────────────────────────────────────
+
+func pptxSlide(title, body string) string {
+	return `<p:sld ` + pmlNS + `><p:cSld><p:spTree>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + title + `</a:t></a:r></a:p></p:txBody></p:sp>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:txBody>` + body + `</p:txBody></p:sp>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldNum"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>7</a:t></a:r></a:p></p:txBody></p:sp>
+</p:spTree></p:cSld></p:sld>`
+}
+
+func TestExtractPPTX_SlidesInPresentationOrder(t *testing.T) {
+	table := `<p:graphicFrame><a:graphic><a:graphicData><a:tbl>
+<a:tr><a:tc><a:txBody><a:p><a:r><a:t>Region</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>Q1</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+<a:tr><a:tc><a:txBody><a:p><a:r><a:t>EMEA</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>120</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+</a:tbl></a:graphicData></a:graphic></p:graphicFrame>`
+	slide2 := strings.Replace(pptxSlide("Results", `<a:p><a:r><a:t>Revenue grew</a:t></a:r></a:p>`), "</p:spTree>", table+"</p:spTree>", 1)
+	data := zipParts(t, map[string]string{
+		// slide2.xml is shown first: order comes from presentation.xml.
+		"ppt/presentation.xml": `<p:presentation ` + pmlNS + `><p:sldIdLst><p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide2.xml"/>
+</Relationships>`,
+		"ppt/slides/slide1.xml": pptxSlide("Agenda", `<a:p><a:r><a:t>Goals</a:t></a:r></a:p><a:p><a:r><a:t>Risks</a:t></a:r></a:p>`),
+		"ppt/slides/slide2.xml": slide2,
+		"ppt/slides/_rels/slide2.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide1.xml"/>
+</Relationships>`,
+		"ppt/notesSlides/notesSlide1.xml": `<p:notes ` + pmlNS + `><p:cSld><p:spTree>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldImg"/></p:nvPr></p:nvSpPr></p:sp>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Mention churn.</a:t></a:r></a:p></p:txBody></p:sp>
+</p:spTree></p:cSld></p:notes>`,
+	})
+
+	doc, err := ExtractDocument(data, "deck.pptx")
+	require.NoError(t, err)
+	require.Len(t, doc.Sections, 2)
+	assert.Equal(t, "## Slide 1: Results\nRevenue grew\n| Region | Q1 |\n| EMEA | 120 |\nNotes: Mention churn.", doc.Sections[0].Text)
+	assert.Equal(t, map[string]interface{}{"slide": 1, "slide_title": "Results"}, doc.Sections[0].Metadata)
+	assert.Equal(t, "## Slide 2: Agenda\nGoals\nRisks", doc.Sections[1].Text)
+	assert.Equal(t, 2, doc.Metadata["slide_count"])
+}
+
+// ── XLSX ──────────────────────────────────────────────────────────────────────
+
+func TestExtractXLSX_SheetsAndRows(t *testing.T) {
+	data := zipParts(t, map[string]string{
+		"xl/workbook.xml": `<workbook ` + sheetNS + `><sheets><sheet name="Inventory" sheetId="1" r:id="rId1"/><sheet name="Empty" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>Item</t></si><si><t>Qty</t></si><si><r><t>Wid</t></r><r><t>get</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet ` + sheetNS + `><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3"><v>42</v></c><c r="D3" t="inlineStr"><is><t>fragile</t></is></c></row>
+<row r="4"><c r="B4" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet ` + sheetNS + `><sheetData/></worksheet>`,
+	})
+
+	doc, err := ExtractDocument(data, "stock.xlsx")
+	require.NoError(t, err)
+	require.Len(t, doc.Sections, 1, "empty sheets are skipped")
+	assert.Equal(t, "## Sheet: Inventory\nColumns: Item | Qty\nRow 3: Item: Widget | Qty: 42 | D: fragile\nRow 4: Qty: TRUE", doc.Sections[0].Text)
+	assert.Equal(t, map[string]interface{}{"sheet": "Inventory", "sheet_index": 1}, doc.Sections[0].Metadata)
+}
+
+func TestColumnNameAndIndex(t *testing.T) {
+	for i, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, name, columnName(i))
+		assert.Equal(t, i, columnIndex(name))
+	}
+}
+
+// ── CSV / TSV ─────────────────────────────────────────────────────────────────
+
+func TestExtractTextFromBytes_CSV(t *testing.T) {
+	csv := "\xef\xbb\xbfname,city\n\"Doe, Jane\",Paris\n,\nBob,Rome,extra\n"
+	got, err := ExtractTextFromBytes([]byte(csv), "people.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "Columns: name | city\nRow 2: name: Doe, Jane | city: Paris\nRow 4: name: Bob | city: Rome | C: extra", got)
+
+	got, err = ExtractTextFromBytes([]byte("a\tb\n1\t2\n"), "t.tsv")
+	require.NoError(t, err)
+	assert.Equal(t, "Columns: a | b\nRow 2: a: 1 | b: 2", got)
+}
+
+// ── HTML ──────────────────────────────────────────────────────────────────────
+
+func TestExtractHTML_StructurePreserved(t *testing.T) {
+	page := `<!DOCTYPE html><html><head><title> Ops Guide </title><style>p{}</style></head>
+<body><nav><a href="/">Home</a></nav>
+<h1>Deploy</h1><p>Run the <b>pipeline</b>.<br>Then verify.</p>
+<h2>Checks</h2><ul><li>Health</li><li>Logs</li></ul>
+<table><tr><th>Env</th><th>URL</th></tr><tr><td>prod</td><td>a|b</td></tr></table>
+<pre>line 1
+  line 2</pre><script>var x = "<p>";</script></body></html>`
+	doc, err := ExtractDocument([]byte(page), "guide.html")
+	require.NoError(t, err)
+	assert.Equal(t, "Ops Guide", doc.Metadata["title"])
+	assert.Equal(t, "# Deploy\nRun the pipeline.\nThen verify.\n\n## Checks\n- Health\n- Logs\n| Env | URL |\n| prod | a\\|b |\nline 1\n  line 2", doc.Text())
+}
+
+// ── EML ───────────────────────────────────────────────────────────────────────
+
+func TestExtractEML_MultipartPrefersPlainText(t *testing.T) {
+	msg := "From: Ops <ops@example.com>\r\n" +
+		"To: team@example.com\r\n" +
+		"Subject: =?UTF-8?B?T3V0YWdlIGZvbGxvdy11cCDinJM=?=\r\n" +
+		"Date: Thu, 02 May 2024 11:15:00 +0200\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n" +
+		"Root cause: expired cert=\r\nificate.\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html\r\n\r\n" +
+		"<p>HTML version</p>\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: application/pdf; name=timeline.pdf\r\n" +
+		"Content-Disposition: attachment; filename=timeline.pdf\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" +
+		"JVBERi0=\r\n" +
+		"--outer--\r\n"
+
+	doc, err := ExtractDocument([]byte(msg), "mail.eml")
+	require.NoError(t, err)
+	assert.Equal(t, "# Outage follow-up ✓\nFrom: Ops <ops@example.com>\nTo: team@example.com\nDate: 2024-05-02T09:15:00Z\nAttachments: timeline.pdf\n\nRoot cause: expired certificate.", doc.Text())
+	assert.Equal(t, "Outage follow-up ✓", doc.Metadata["email_subject"])
+	assert.Equal(t, "2024-05-02T09:15:00Z", doc.Metadata["email_date"])
+}
+
+func TestExtractEML_HTMLOnlyBody(t *testing.T) {
+	msg := "Subject: Notice\r\nContent-Type: text/html; charset=iso-8859-1\r\nContent-Transfer-Encoding: base64\r\n\r\n" +
+		"PGgyPkNhZukgbWVudTwvaDI+PHA+T3BlbiA5LTU8L3A+\r\n" // <h2>Café menu</h2><p>Open 9-5</p> in Latin-1
+	got, err := ExtractTextFromBytes([]byte(msg), "notice.eml")
+	require.NoError(t, err)
+	assert.Equal(t, "# Notice\n\n## Café menu\nOpen 9-5", got)
+}
+
+// ── JSON ──────────────────────────────────────────────────────────────────────
+
+func TestExtractJSON_ArrayOfRecords(t *testing.T) {
+	data := `[{"id": 7, "title": "Reset password", "steps": ["Open settings", "Click reset"], "meta": {"team": "IAM", "draft": false, "owner": null}},
+	          {"body": "no title"}]`
+	doc, err := ExtractDocument([]byte(data), "kb.json")
+	require.NoError(t, err)
+	require.Len(t, doc.Sections, 2)
+	assert.Equal(t, "## Record 1: Reset password\nid: 7\ntitle: Reset password\nsteps[0]: Open settings\nsteps[1]: Click reset\nmeta.team: IAM\nmeta.draft: false", doc.Sections[0].Text)
+	assert.Equal(t, map[string]interface{}{"record": 1}, doc.Sections[0].Metadata)
+	assert.Equal(t, "## Record 2\nbody: no title", doc.Sections[1].Text)
+}
+
+func TestExtractJSON_ObjectIsSingleSection(t *testing.T) {
+	doc, err := ExtractDocument([]byte(`{"service": {"name": "api", "port": 8080}}`), "config.json")
+	require.NoError(t, err)
+	require.Len(t, doc.Sections, 1)
+	assert.Equal(t, "service.name: api\nservice.port: 8080", doc.Sections[0].Text)
+
+	_, err = ExtractDocument([]byte(`{"broken": `), "bad.json")
+	assert.Error(t, err)
+}
+
+func TestExtractJSONLines(t *testing.T) {
+	doc, err := ExtractDocument([]byte("{\"name\": \"a\"}\n\n{\"name\": \"b\"}\n"), "rows.jsonl")
+	require.NoError(t, err)
+	require.Len(t, doc.Sections, 2)
+	assert.Equal(t, "## Record 2: b\nname: b", doc.Sections[1].Text)
+	assert.Equal(t, 2, doc.Sections[1].Metadata["record"])
+
+	_, err = ExtractDocument([]byte("{\"ok\": 1}\nnot json\n"), "rows.ndjson")
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,239 @@
+package migrateCollection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-chroma"
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/connector"
+	vdbtransfer "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/transfer"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data/metadata"
+)
+
+var activityMd = activity.ToMetadata(&Settings{}, &Input{}, &Output{})
+
+func init() { _ = activity.Register(&Activity{}, New) }
+
+type Activity struct {
+	settings *Settings
+	conn     *vectordbconnector.ChromaConnection
+	// peer is the client of PeerConnection, adapted when it belongs to
+	// another connector module.
+	peer     vectordb.VectorDBClient
+	sameConn bool
+}
+
+func (a *Activity) Metadata() *activity.Metadata { return activityMd }
+
+func New(ctx activity.InitContext) (activity.Activity, error) {
+	s := &Settings{}
+	if err := metadata.MapToStruct(ctx.Settings(), s, true); err != nil {
+		return nil, fmt.Errorf("vectordb-migrate: %w", err)
+	}
+	if s.Connection == nil {
+		return nil, fmt.Errorf("vectordb-migrate: connection is required")
+	}
+	conn, ok := s.Connection.GetConnection().(*vectordbconnector.ChromaConnection)
+	if !ok {
+		return nil, fmt.Errorf("vectordb-migrate: invalid connection type, expected *ChromaConnection")
+	}
+	if s.PeerConnection == nil {
+		return nil, fmt.Errorf("vectordb-migrate: peerConnection is required")
+	}
+	peerConn := s.PeerConnection.GetConnection()
+	peer, err := vdbtransfer.ClientFromConnection(peerConn)
+	if err != nil {
+		return nil, fmt.Errorf("vectordb-migrate: peerConnection: %w", err)
+	}
+	s.Direction = strings.ToLower(s.Direction)
+	if s.Direction == "" {
+		s.Direction = directionPush
+	}
+	if s.Direction != directionPush && s.Direction != directionPull {
+		return nil, fmt.Errorf("vectordb-migrate: direction must be push or pull, got %q", s.Direction)
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = vdbtransfer.DefaultBatchSize
+	}
+	if s.DistanceMetric == "" {
+		s.DistanceMetric = "cosine"
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 3600
+	}
+	ctx.Logger().Infof("MigrateCollection initialised: connection=%s provider=%s peer=%s direction=%s batchSize=%d createCollection=%v verify=%v",
+		conn.GetName(), "chroma", peer.DBType(), s.Direction, s.BatchSize, s.CreateCollection, s.Verify)
+	return &Activity{settings: s, conn: conn, peer: peer, sameConn: peerConn == interface{}(conn)}, nil
+}
+
+func (a *Activity) Eval(ctx activity.Context) (bool, error) {
+	l := ctx.Logger()
+	l.Debugf("MigrateCollection: starting eval")
+
+	input := &Input{}
+	if err := ctx.GetInputObject(input); err != nil {
+		return false, fmt.Errorf("vectordb-migrate: %w", err)
+	}
+	if input.SourceCollection == "" {
+		return false, fmt.Errorf("vectordb-migrate: sourceCollection is required")
+	}
+	targetCollection := input.TargetCollection
+	if targetCollection == "" {
+		targetCollection = input.SourceCollection
+	}
+	if a.sameConn && targetCollection == input.SourceCollection {
+		return false, fmt.Errorf("vectordb-migrate: source and target are the same collection")
+	}
+
+	src, dst := a.conn.GetClient(), a.peer
+	if a.settings.Direction == directionPull {
+		src, dst = dst, src
+	}
+	source := src.DBType() + ":" + input.SourceCollection
+	target := dst.DBType() + ":" + targetCollection
+
+	// OTel trace tags
+	tc := ctx.GetTracingContext()
+	if tc != nil {
+		tc.SetTag("db.system", "vectordb")
+		tc.SetTag("db.operation", "migrateCollection")
+		tc.SetTag("db.vectordb.provider", "chroma")
+		tc.SetTag("db.vectordb.collection", input.SourceCollection)
+		tc.SetTag("db.vectordb.transfer.source", source)
+		tc.SetTag("db.vectordb.transfer.target", target)
+	}
+
+	start := time.Now()
+	out := &Output{}
+	fail := func(err error) (bool, error) {
+		l.Errorf("MigrateCollection: %s -> %s error=%v", source, target, err)
+		if tc != nil {
+			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": err.Error()})
+		}
+		out.Success = false
+		out.Error = err.Error()
+		out.Duration = time.Since(start).String()
+		if err := ctx.SetOutputObject(out); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
+		}
+		return true, nil
+	}
+
+	cp := &vdbtransfer.Checkpoint{Source: source, Target: target}
+	if a.settings.CheckpointPath != "" && !input.Restart {
+		prev, err := vdbtransfer.ReadCheckpoint(a.settings.CheckpointPath)
+		if err != nil {
+			l.Warnf("MigrateCollection: ignoring unreadable checkpoint %s: %v", a.settings.CheckpointPath, err)
+		} else if prev != nil && !prev.Complete && prev.Source == source && prev.Target == target {
+			cp = prev
+			l.Infof("MigrateCollection: resuming %s -> %s after %d documents", source, target, prev.Documents)
+		}
+	}
+	if input.ResumeCursor != "" {
+		cp.Cursor = input.ResumeCursor
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	// A target that already exists keeps the metric it was created with,
+	// whatever the Distance Metric setting says, so the metrics are read
+	// from the collections where the clients can report them.
+	sourceMetric := vdbtransfer.NormalizeMetric(input.SourceDistanceMetric)
+	if sourceMetric == "" {
+		var err error
+		if sourceMetric, err = vdbtransfer.CollectionMetric(opCtx, src, input.SourceCollection); err != nil {
+			return fail(err)
+		}
+	}
+	targetMetric, err := vdbtransfer.TargetMetric(opCtx, dst, targetCollection, a.settings.DistanceMetric)
+	if err != nil {
+		return fail(err)
+	}
+	switch {
+	case sourceMetric == "" && targetMetric == "":
+		return fail(fmt.Errorf("cannot determine the distance metric of %s or %s: set sourceDistanceMetric", source, target))
+	case sourceMetric == "":
+		l.Warnf("MigrateCollection: distance metric of %s is unknown; set sourceDistanceMetric to check it against %s", source, targetMetric)
+	case targetMetric == "":
+		l.Warnf("MigrateCollection: distance metric of %s is unknown; it is not checked against %s", target, sourceMetric)
+	}
+	if err := vdbtransfer.CheckMetric(sourceMetric, targetMetric); err != nil {
+		return fail(err)
+	}
+
+	dims := cp.Dimensions
+	if dims <= 0 {
+		var err error
+		if dims, err = vdbtransfer.ProbeDimensions(opCtx, src, input.SourceCollection, input.Filters); err != nil {
+			return fail(err)
+		}
+	}
+	if dims > 0 {
+		created, err := vdbtransfer.PrepareTarget(opCtx, dst, targetCollection, dims, a.settings.DistanceMetric, a.settings.CreateCollection)
+		if err != nil {
+			return fail(err)
+		}
+		out.Created = created
+	}
+
+	base := cp.Documents
+	p, copyErr := vdbtransfer.Copy(opCtx, src, &vdbtransfer.ClientSink{Client: dst, Collection: targetCollection}, vdbtransfer.CopyOptions{
+		Collection: input.SourceCollection,
+		Filters:    input.Filters,
+		BatchSize:  a.settings.BatchSize,
+		Cursor:     cp.Cursor,
+		Dimensions: dims,
+		Checkpoint: func(p vdbtransfer.Progress) error {
+			l.Debugf("MigrateCollection: %s -> %s documents=%d cursor=%s", source, target, base+p.Documents, p.Cursor)
+			if a.settings.CheckpointPath == "" {
+				return nil
+			}
+			cp.Cursor = p.Cursor
+			cp.Documents = base + p.Documents
+			cp.Dimensions = p.Dimensions
+			cp.Complete = p.Complete
+			return vdbtransfer.WriteCheckpoint(a.settings.CheckpointPath, cp)
+		},
+	})
+	out.MigratedCount = p.Documents
+	out.DocumentCount = base + p.Documents
+	out.Dimensions = p.Dimensions
+	out.Cursor = p.Cursor
+	if copyErr != nil {
+		return fail(copyErr)
+	}
+	out.Complete = true
+
+	if a.settings.Verify {
+		sourceCount, err := src.CountDocuments(opCtx, input.SourceCollection, input.Filters)
+		if err != nil {
+			out.VerificationError = fmt.Sprintf("count source: %v", err)
+		} else {
+			v := vdbtransfer.Verify(opCtx, dst, targetCollection, sourceCount)
+			out.Verified, out.TargetCount, out.VerificationError = v.Verified, v.Actual, v.Error
+		}
+		out.SourceCount = sourceCount
+		if !out.Verified {
+			l.Warnf("MigrateCollection: verification failed: %s", out.VerificationError)
+		}
+	}
+
+	duration := time.Since(start)
+	l.Infof("MigrateCollection: %s -> %s documents=%d dimensions=%d created=%v verified=%v duration=%s",
+		source, target, out.DocumentCount, out.Dimensions, out.Created, out.Verified, duration)
+	if tc != nil {
+		tc.SetTag("db.vectordb.transfer.documents", out.DocumentCount)
+		tc.SetTag("db.vectordb.transfer.verified", out.Verified)
+	}
+	out.Success = true
+	out.Duration = duration.String()
+	if err := ctx.SetOutputObject(out); err != nil {
+		l.Errorf("SetOutputObject: %v", err)
+	}
+	return true, nil
+}
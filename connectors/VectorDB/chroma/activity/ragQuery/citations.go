@@ -0,0 +1,202 @@
+package ragQuery
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-chroma"
+)
+
+// citationInstruction is appended to the system prompt when citations are
+// enabled. The numbers refer to the 1-based document numbers of the context.
+const citationInstruction = "After each sentence, cite the numbers of the context documents that support it in square brackets, for example [1] or [2][3]. Do not cite documents that do not support the sentence."
+
+// minCitationOverlap is the share of a sentence's words that must appear in a
+// document for the sentence to be attributed to it when the model wrote no
+// citation markers.
+const minCitationOverlap = 0.6
+
+var (
+	// citationMarkerRe matches [1], [2, 3] and [2][3] style markers.
+	citationMarkerRe = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+	// sentenceEndRe matches the end of a sentence: terminal punctuation and
+	// any citation markers that follow it, then whitespace or the end of the
+	// text; or a line break.
+	sentenceEndRe = regexp.MustCompile(`[.!?]+(?:\s*\[\d+(?:\s*,\s*\d+)*\])*(?:\s+|$)|\n+`)
+	// spaceBeforePunctRe matches the gap a removed marker leaves before
+	// punctuation ("vectors [2]." becomes "vectors .").
+	spaceBeforePunctRe = regexp.MustCompile(`\s+([.!?,;:])`)
+)
+
+// Citation maps one sentence of the answer to the source documents that
+// support it.
+type Citation struct {
+	// Sentence is the sentence text with citation markers removed.
+	Sentence string
+	// Start and End are the character (rune) offsets of the sentence,
+	// markers included, in the answer.
+	Start, End int
+	// SourceIDs are the IDs of the supporting sourceDocuments.
+	SourceIDs []string
+	// SourceIndexes are the 1-based positions of those documents in
+	// sourceDocuments.
+	SourceIndexes []int
+	// Method is "marker" when the model cited the documents and "overlap"
+	// when they were matched by word overlap.
+	Method string
+}
+
+// toMap converts a citation to its Flogo output object.
+func (c Citation) toMap() map[string]interface{} {
+	ids := make([]interface{}, len(c.SourceIDs))
+	for i, id := range c.SourceIDs {
+		ids[i] = id
+	}
+	idx := make([]interface{}, len(c.SourceIndexes))
+	for i, n := range c.SourceIndexes {
+		idx[i] = n
+	}
+	return map[string]interface{}{
+		"sentence":      c.Sentence,
+		"start":         c.Start,
+		"end":           c.End,
+		"sourceIds":     ids,
+		"sourceIndexes": idx,
+		"method":        c.Method,
+	}
+}
+
+// extractCitations splits the answer into sentences and attributes each to
+// source documents: first by the [n] markers the model wrote, which refer to
+// the 1-based document numbers of the context, and otherwise by word overlap
+// with the document content. Sentences without a supporting document are
+// omitted.
+func extractCitations(answer string, results []vectordb.SearchResult, contentField string) []Citation {
+	if strings.TrimSpace(answer) == "" || len(results) == 0 {
+		return nil
+	}
+	docWords := make([]map[string]bool, len(results))
+	for i, r := range results {
+		docWords[i] = wordSet(extractContent(r, contentField))
+	}
+
+	var out []Citation
+	for _, span := range splitSentences(answer) {
+		raw := answer[span[0]:span[1]]
+		sentence := citationMarkerRe.ReplaceAllString(raw, "")
+		sentence = strings.TrimSpace(spaceBeforePunctRe.ReplaceAllString(sentence, "$1"))
+		if sentence == "" {
+			continue
+		}
+		c := Citation{
+			Sentence: sentence,
+			Start:    utf8.RuneCountInString(answer[:span[0]]),
+			End:      utf8.RuneCountInString(answer[:span[1]]),
+			Method:   "marker",
+		}
+		seen := make(map[int]bool)
+		for _, m := range citationMarkerRe.FindAllStringSubmatch(raw, -1) {
+			for _, part := range strings.Split(m[1], ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(part))
+				if err != nil || n < 1 || n > len(results) || seen[n] {
+					continue
+				}
+				seen[n] = true
+				c.SourceIndexes = append(c.SourceIndexes, n)
+				c.SourceIDs = append(c.SourceIDs, results[n-1].ID)
+			}
+		}
+		if len(c.SourceIndexes) == 0 {
+			if n := bestOverlap(wordSet(sentence), docWords); n > 0 {
+				c.Method = "overlap"
+				c.SourceIndexes = []int{n}
+				c.SourceIDs = []string{results[n-1].ID}
+			}
+		}
+		if len(c.SourceIndexes) > 0 {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// splitSentences returns the [start, end) byte spans of the sentences of
+// text, trimmed of surrounding whitespace. Citation markers after the
+// terminal punctuation belong to the sentence they follow.
+func splitSentences(text string) [][2]int {
+	var spans [][2]int
+	add := func(start, end int) {
+		for start < end && isSpaceByte(text[start]) {
+			start++
+		}
+		for end > start && isSpaceByte(text[end-1]) {
+			end--
+		}
+		if start < end {
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	start := 0
+	for _, m := range sentenceEndRe.FindAllStringIndex(text, -1) {
+		add(start, m[1])
+		start = m[1]
+	}
+	add(start, len(text))
+	return spans
+}
+
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// wordSet returns the lower-cased words of text that are at least three
+// characters long.
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(w) >= 3 {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// bestOverlap returns the 1-based index of the document containing the
+// largest share of the sentence's words, or 0 when no document reaches
+// minCitationOverlap.
+func bestOverlap(sentence map[string]bool, docs []map[string]bool) int {
+	if len(sentence) == 0 {
+		return 0
+	}
+	best, bestScore := 0, 0.0
+	for i, doc := range docs {
+		hits := 0
+		for w := range sentence {
+			if doc[w] {
+				hits++
+			}
+		}
+		score := float64(hits) / float64(len(sentence))
+		if score > bestScore {
+			best, bestScore = i+1, score
+		}
+	}
+	if bestScore < minCitationOverlap {
+		return 0
+	}
+	return best
+}
+
+// citationsToInterface converts citations to []interface{} for Flogo output.
+func citationsToInterface(citations []Citation) []interface{} {
+	out := make([]interface{}, len(citations))
+	for i, c := range citations {
+		out[i] = c.toMap()
+	}
+	return out
+}
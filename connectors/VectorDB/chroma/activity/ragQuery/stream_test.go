@@ -0,0 +1,202 @@
+package ragQuery
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	sse "github.com/mpandav-tibco/flogo-custom-extensions/sse/trigger"
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-chroma"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/testutil/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSEServer records the events sent through the SSE registry.
+type fakeSSEServer struct {
+	mu     sync.Mutex
+	events []sentEvent
+	err    error
+}
+
+type sentEvent struct {
+	target string // "all", "topic:<name>" or "conn:<id>"
+	event  sse.SSEEventData
+}
+
+func (f *fakeSSEServer) record(target string, e *sse.SSEEventData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, sentEvent{target: target, event: *e})
+	return nil
+}
+
+func (f *fakeSSEServer) BroadcastEvent(e *sse.SSEEventData) error { return f.record("all", e) }
+func (f *fakeSSEServer) BroadcastEventToTopic(topic string, e *sse.SSEEventData) error {
+	return f.record("topic:"+topic, e)
+}
+func (f *fakeSSEServer) SendEventToConnection(id string, e *sse.SSEEventData) error {
+	return f.record("conn:"+id, e)
+}
+func (f *fakeSSEServer) GetActiveConnections() []sse.ConnectionInfo { return nil }
+
+func registerFakeSSE(t *testing.T, name string) *fakeSSEServer {
+	t.Helper()
+	srv := &fakeSSEServer{}
+	sse.RegisterSSEServer(name, srv)
+	t.Cleanup(func() { sse.UnregisterSSEServer(name) })
+	return srv
+}
+
+func TestNewTokenStream_UnknownServer(t *testing.T) {
+	_, err := newTokenStream("no-such-server", "", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"no-such-server" not found`)
+}
+
+func TestTokenStream_Routing(t *testing.T) {
+	srv := registerFakeSSE(t, "rag-routing")
+
+	byConn, err := newTokenStream("rag-routing", "c1", "ignored")
+	require.NoError(t, err)
+	byConn.token("a")
+	byTopic, _ := newTokenStream("rag-routing", "", "answers")
+	byTopic.token("b")
+	all, _ := newTokenStream("rag-routing", "", "")
+	all.token("c")
+	all.token("d")
+
+	require.Len(t, srv.events, 4)
+	assert.Equal(t, "conn:c1", srv.events[0].target)
+	assert.Equal(t, "topic:answers", srv.events[1].target)
+	assert.Equal(t, "all", srv.events[2].target)
+	assert.Equal(t, streamEventToken, srv.events[3].event.Event)
+	assert.JSONEq(t, `{"index":1,"delta":"d"}`, srv.events[3].event.Data)
+	assert.Equal(t, 2, all.tokens)
+}
+
+func TestTokenStream_StopsAfterSendError(t *testing.T) {
+	srv := registerFakeSSE(t, "rag-failing")
+	srv.err = errors.New("connection closed")
+
+	ts, err := newTokenStream("rag-failing", "c1", "")
+	require.NoError(t, err)
+	ts.token("a")
+	srv.err = nil
+	ts.token("b")
+	assert.EqualError(t, ts.err, "connection closed")
+	assert.Empty(t, srv.events, "events after the first failure are dropped")
+}
+
+func TestRAGQuery_StreamingWithCitations(t *testing.T) {
+	sseSrv := registerFakeSSE(t, "rag-e2e")
+	embedServer := makeEmbedServer([]float64{0.1, 0.2})
+	defer embedServer.Close()
+	llmServer, _, llmBody := captureServer(t,
+		`data: {"choices":[{"delta":{"content":"Flogo is written in Go. [1]"}}]}`,
+		`data: {"choices":[{"delta":{"content":" Chroma stores vectors. [2]"}}]}`,
+		`data: [DONE]`,
+	)
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, mock.Anything).Return(citationDocs, nil)
+
+	a := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  embedServer.URL,
+			DefaultCollection: "docs",
+			DefaultTopK:       2,
+			ContentField:      "text",
+			ContextFormat:     "plain",
+			TimeoutSeconds:    10,
+			EnableLLMGenerate: true,
+			LLMProvider:       "OpenAI",
+			LLMBaseURL:        llmServer.URL,
+			SystemPrompt:      "Answer from context.",
+			EnableCitations:   true,
+			EnableStreaming:   true,
+			SSEServerRef:      "rag-e2e",
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"queryText":          "tell me",
+		"streamConnectionId": "conn-7",
+	}}
+
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, "Flogo is written in Go. [1] Chroma stores vectors. [2]", ctx.outputs["answer"])
+	assert.Equal(t, 2, ctx.outputs["streamedTokens"])
+
+	// The prompt asks for markers and numbers the documents even though the
+	// formattedContext output uses the plain format.
+	msgs := llmBody["messages"].([]interface{})
+	assert.Contains(t, msgs[0].(map[string]interface{})["content"], citationInstruction)
+	assert.Contains(t, msgs[1].(map[string]interface{})["content"], "1. Flogo")
+	assert.NotContains(t, ctx.outputs["formattedContext"], "1. Flogo")
+
+	citations := ctx.outputs["citations"].([]interface{})
+	require.Len(t, citations, 2)
+	first := citations[0].(map[string]interface{})
+	assert.Equal(t, "Flogo is written in Go.", first["sentence"])
+	assert.Equal(t, []interface{}{"doc-a"}, first["sourceIds"])
+	assert.Equal(t, "marker", citations[1].(map[string]interface{})["method"])
+
+	require.Len(t, sseSrv.events, 3)
+	for _, e := range sseSrv.events {
+		assert.Equal(t, "conn:conn-7", e.target)
+	}
+	done := sseSrv.events[2].event
+	assert.Equal(t, streamEventDone, done.Event)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(done.Data), &payload))
+	assert.Equal(t, ctx.outputs["answer"], payload["answer"])
+	assert.Len(t, payload["citations"], 2)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"index": float64(1), "id": "doc-a"},
+		map[string]interface{}{"index": float64(2), "id": "doc-b"},
+	}, payload["sources"])
+}
+
+func TestRAGQuery_StreamingServerMissing(t *testing.T) {
+	embedServer := makeEmbedServer([]float64{0.1, 0.2})
+	defer embedServer.Close()
+	llmServer, _, _ := captureServer(t, `{"choices":[{"message":{"role":"assistant","content":"fine"}}]}`)
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, mock.Anything).Return([]vectordb.SearchResult{citationDocs[0]}, nil)
+
+	a := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  embedServer.URL,
+			DefaultCollection: "docs",
+			DefaultTopK:       1,
+			ContentField:      "text",
+			TimeoutSeconds:    10,
+			EnableLLMGenerate: true,
+			LLMProvider:       "OpenAI",
+			LLMBaseURL:        llmServer.URL,
+			EnableStreaming:   true,
+			SSEServerRef:      "not-registered",
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "q"}}
+
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"], "a missing SSE server falls back to a blocking answer")
+	assert.Equal(t, "fine", ctx.outputs["answer"])
+	assert.Equal(t, 0, ctx.outputs["streamedTokens"])
+}
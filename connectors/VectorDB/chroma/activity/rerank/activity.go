@@ -11,6 +11,12 @@ import (
 
 var activityMd = activity.ToMetadata(&Settings{}, &Input{}, &Output{})
 
+// Rerank providers for the provider setting.
+const (
+	providerAPI   = "api"   // HTTP rerank endpoint (Cohere, Jina, compatible)
+	providerLocal = "local" // in-process ONNX cross-encoder
+)
+
 func init() { _ = activity.Register(&Activity{}, New) }
 
 type Activity struct {
@@ -24,11 +30,45 @@ func New(ctx activity.InitContext) (activity.Activity, error) {
 	if err := metadata.MapToStruct(ctx.Settings(), s, true); err != nil {
 		return nil, fmt.Errorf("vectordb-rerank: %w", err)
 	}
-	if s.RerankEndpoint == "" {
-		return nil, fmt.Errorf("vectordb-rerank: rerankEndpoint is required")
+	switch s.Provider {
+	case "", providerAPI:
+		s.Provider = providerAPI
+		if s.RerankEndpoint == "" {
+			return nil, fmt.Errorf("vectordb-rerank: rerankEndpoint is required")
+		}
+	case providerLocal:
+		if s.ModelPath == "" {
+			return nil, fmt.Errorf("vectordb-rerank: modelPath is required for the local provider")
+		}
+		if s.MaxSequenceLength <= 0 {
+			s.MaxSequenceLength = defaultMaxSequenceLength
+		}
+		if s.BatchSize <= 0 {
+			s.BatchSize = defaultLocalBatchSize
+		}
+	default:
+		return nil, fmt.Errorf("vectordb-rerank: provider must be api or local, got %q", s.Provider)
+	}
+	switch s.ScoreFusion {
+	case "":
+		s.ScoreFusion = fusionNone
+	case fusionNone, fusionWeighted, fusionRRF:
+	default:
+		return nil, fmt.Errorf("vectordb-rerank: scoreFusion must be one of none, weighted, rrf, got %q", s.ScoreFusion)
+	}
+	if s.RerankWeight == 0 {
+		s.RerankWeight = defaultRerankWeight
+	}
+	if s.ScoreFusion == fusionWeighted && (s.RerankWeight < 0 || s.RerankWeight > 1) {
+		return nil, fmt.Errorf("vectordb-rerank: rerankWeight must be between 0.0 and 1.0, got %.4f", s.RerankWeight)
+	}
+	if s.Provider == providerLocal {
+		ctx.Logger().Infof("Rerank initialised: provider=local model=%s batchSize=%d maxSequenceLength=%d scoreFusion=%s",
+			s.ModelPath, s.BatchSize, s.MaxSequenceLength, s.ScoreFusion)
+	} else {
+		ctx.Logger().Infof("Rerank initialised: endpoint=%s model=%s scoreFusion=%s",
+			s.RerankEndpoint, s.Model, s.ScoreFusion)
 	}
-	ctx.Logger().Infof("Rerank initialised: endpoint=%s model=%s",
-		s.RerankEndpoint, s.Model)
 	return &Activity{settings: s}, nil
 }
 
@@ -52,16 +92,30 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		topN = len(input.Documents)
 	}
 
-	l.Debugf("Rerank: query=%q doc_count=%d topN=%d model=%s endpoint=%s",
-		input.Query, len(input.Documents), topN, a.settings.Model, a.settings.RerankEndpoint)
+	model := a.settings.Model
+	if a.settings.Provider == providerLocal {
+		model = a.settings.ModelPath
+	}
+	l.Debugf("Rerank: query=%q doc_count=%d topN=%d provider=%s model=%s endpoint=%s",
+		input.Query, len(input.Documents), topN, a.settings.Provider, model, a.settings.RerankEndpoint)
 
 	// OTel trace tags
 	tc := ctx.GetTracingContext()
 	if tc != nil {
+		tc.SetTag("rerank.provider", a.settings.Provider)
 		tc.SetTag("rerank.endpoint", a.settings.RerankEndpoint)
-		tc.SetTag("rerank.model", a.settings.Model)
+		tc.SetTag("rerank.model", model)
 		tc.SetTag("rerank.doc_count", len(input.Documents))
 		tc.SetTag("rerank.top_n", topN)
+		tc.SetTag("rerank.score_fusion", a.settings.ScoreFusion)
+	}
+
+	// Fusion needs the rerank score of every document, so the cut to topN
+	// happens after fusing.
+	rerankTopN := topN
+	fuse := a.settings.ScoreFusion == fusionWeighted || a.settings.ScoreFusion == fusionRRF
+	if fuse {
+		rerankTopN = len(input.Documents)
 	}
 
 	timeout := a.settings.TimeoutSeconds
@@ -72,14 +126,23 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	defer cancel()
 
 	start := time.Now()
-	ranked, rerankErr := callRerankAPI(opCtx, rerankAPIRequest{
-		Endpoint:  a.settings.RerankEndpoint,
-		APIKey:    a.settings.APIKey,
-		Model:     a.settings.Model,
-		Query:     input.Query,
-		Documents: input.Documents,
-		TopN:      topN,
-	})
+	var ranked []interface{}
+	var rerankErr error
+	if a.settings.Provider == providerLocal {
+		ranked, rerankErr = callLocalRerank(opCtx, a.settings, input.Query, input.Documents, rerankTopN)
+	} else {
+		ranked, rerankErr = callRerankAPI(opCtx, rerankAPIRequest{
+			Endpoint:  a.settings.RerankEndpoint,
+			APIKey:    a.settings.APIKey,
+			Model:     a.settings.Model,
+			Query:     input.Query,
+			Documents: input.Documents,
+			TopN:      rerankTopN,
+		})
+	}
+	if rerankErr == nil && fuse {
+		ranked = fuseScores(ranked, input.Documents, a.settings.ScoreFusion, a.settings.RerankWeight, topN)
+	}
 	if rerankErr != nil {
 		l.Errorf("Rerank: error=%v", rerankErr)
 		if tc != nil {
@@ -0,0 +1,122 @@
+package rerank
+
+import (
+	"math"
+	"sort"
+)
+
+// Score fusion modes for the scoreFusion setting.
+const (
+	fusionNone     = "none"     // order by rerank score only
+	fusionWeighted = "weighted" // rerankWeight·rerank + (1−rerankWeight)·vector, min-max normalised
+	fusionRRF      = "rrf"      // reciprocal rank fusion of the two rankings
+)
+
+const (
+	defaultRerankWeight = 0.7
+	// fusionRRFK is the reciprocal rank fusion constant: score = Σ 1/(k + rank).
+	fusionRRFK = 60
+)
+
+// fuseScores combines the reranker's relevance_score with the vector search
+// score carried by each input document (its "score" field) and returns the
+// ranked entries reordered by the fused score, cut to topN. Each entry gains
+// "fused_score" and, when the document has one, "vector_score". ranked must
+// hold every document so both rankings are complete.
+func fuseScores(ranked []interface{}, documents []interface{}, mode string, weight float64, topN int) []interface{} {
+	type entry struct {
+		m      map[string]interface{}
+		rerank float64
+		vector float64
+		hasVec bool
+		fused  float64
+	}
+	entries := make([]*entry, 0, len(ranked))
+	for _, r := range ranked {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		e := &entry{m: m}
+		e.rerank, _ = toFloat(m["relevance_score"])
+		if idx, ok := m["index"].(int); ok && idx >= 0 && idx < len(documents) {
+			if doc, ok := documents[idx].(map[string]interface{}); ok {
+				e.vector, e.hasVec = toFloat(doc["score"])
+			}
+		}
+		if e.hasVec {
+			m["vector_score"] = e.vector
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].rerank > entries[j].rerank })
+
+	switch mode {
+	case fusionRRF:
+		// Documents without a vector score rank last, in rerank order.
+		byVector := make([]*entry, len(entries))
+		copy(byVector, entries)
+		sort.SliceStable(byVector, func(i, j int) bool {
+			if byVector[i].hasVec != byVector[j].hasVec {
+				return byVector[i].hasVec
+			}
+			return byVector[i].vector > byVector[j].vector
+		})
+		for rank, e := range entries {
+			e.fused += 1.0 / float64(fusionRRFK+rank+1)
+		}
+		for rank, e := range byVector {
+			e.fused += 1.0 / float64(fusionRRFK+rank+1)
+		}
+	default: // fusionWeighted
+		rerankLo, rerankHi := math.Inf(1), math.Inf(-1)
+		vecLo, vecHi := math.Inf(1), math.Inf(-1)
+		for _, e := range entries {
+			rerankLo, rerankHi = math.Min(rerankLo, e.rerank), math.Max(rerankHi, e.rerank)
+			if e.hasVec {
+				vecLo, vecHi = math.Min(vecLo, e.vector), math.Max(vecHi, e.vector)
+			}
+		}
+		for _, e := range entries {
+			v := 0.0
+			if e.hasVec {
+				v = minMax(e.vector, vecLo, vecHi)
+			}
+			e.fused = weight*minMax(e.rerank, rerankLo, rerankHi) + (1-weight)*v
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].fused > entries[j].fused })
+	if topN > 0 && topN < len(entries) {
+		entries = entries[:topN]
+	}
+	out := make([]interface{}, len(entries))
+	for i, e := range entries {
+		e.m["fused_score"] = e.fused
+		out[i] = e.m
+	}
+	return out
+}
+
+// minMax scales v from [lo, hi] to [0, 1]; a constant range maps to 1.
+func minMax(v, lo, hi float64) float64 {
+	if hi <= lo {
+		return 1
+	}
+	return (v - lo) / (hi - lo)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,191 @@
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	vdbembed "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/embeddings"
+)
+
+const (
+	// defaultMaxSequenceLength is the token budget of one query/document pair;
+	// MiniLM and BGE cross-encoders are trained with 512.
+	defaultMaxSequenceLength = 512
+	// defaultLocalBatchSize is the number of pairs scored per model run.
+	defaultLocalBatchSize = 16
+)
+
+// batchRunner runs a cross-encoder model over one batch of tokenized pairs.
+// Inputs are row-major [batchSize × seqLen]; the result holds one logit per
+// row. onnxRunner is the production implementation.
+type batchRunner interface {
+	run(ids, mask, types []int64) ([]float32, error)
+	close() error
+}
+
+// crossEncoder scores query/document pairs in-process. A model run binds
+// fixed-size buffers, so calls are serialised.
+type crossEncoder struct {
+	mu        sync.Mutex
+	tok       *vdbembed.Tokenizer
+	runner    batchRunner
+	batchSize int
+	seqLen    int
+}
+
+// localModelKey identifies a loaded model; activities configured with the
+// same model share one session.
+type localModelKey struct {
+	modelPath, tokenizerPath, runtimePath string
+	batchSize, seqLen                     int
+}
+
+var (
+	localModelsMu sync.Mutex
+	localModels   = map[localModelKey]*crossEncoder{}
+
+	// newBatchRunner creates the model session. Replaced in tests.
+	newBatchRunner = newONNXRunner
+)
+
+// getCrossEncoder returns the shared cross-encoder for the settings, loading
+// the tokenizer and model on first use. Load failures are not cached, so a
+// model copied into place later is picked up by the next call.
+func getCrossEncoder(s *Settings) (*crossEncoder, error) {
+	key := localModelKey{
+		modelPath:     s.ModelPath,
+		tokenizerPath: tokenizerPathFor(s),
+		runtimePath:   s.OnnxRuntimePath,
+		batchSize:     s.BatchSize,
+		seqLen:        s.MaxSequenceLength,
+	}
+	localModelsMu.Lock()
+	defer localModelsMu.Unlock()
+	if ce, ok := localModels[key]; ok {
+		return ce, nil
+	}
+
+	if _, err := os.Stat(key.modelPath); err != nil {
+		return nil, fmt.Errorf("rerank: model file: %w", err)
+	}
+	tok, err := vdbembed.LoadTokenizer(key.tokenizerPath)
+	if err != nil {
+		return nil, fmt.Errorf("rerank: %w", err)
+	}
+	if !tok.HasPairTemplate() {
+		return nil, fmt.Errorf("rerank: tokenizer %s has no pair template; cannot build query/document pairs", key.tokenizerPath)
+	}
+	runner, err := newBatchRunner(key.modelPath, key.runtimePath, key.batchSize, key.seqLen, tok.UsesTypeIDs())
+	if err != nil {
+		return nil, fmt.Errorf("rerank: load model %s: %w", key.modelPath, err)
+	}
+	ce := &crossEncoder{tok: tok, runner: runner, batchSize: key.batchSize, seqLen: key.seqLen}
+	localModels[key] = ce
+	return ce, nil
+}
+
+// tokenizerPathFor returns the configured tokenizer path, defaulting to
+// tokenizer.json next to the model file.
+func tokenizerPathFor(s *Settings) string {
+	if s.TokenizerPath != "" {
+		return s.TokenizerPath
+	}
+	return filepath.Join(filepath.Dir(s.ModelPath), "tokenizer.json")
+}
+
+// score returns the relevance of each text to the query: the sigmoid of the
+// model logit, in (0, 1). Texts are scored in batches of batchSize; the last
+// batch is padded with empty rows whose logits are discarded.
+func (ce *crossEncoder) score(ctx context.Context, query string, texts []string) ([]float64, error) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	n := ce.batchSize * ce.seqLen
+	ids, mask, types := make([]int64, n), make([]int64, n), make([]int64, n)
+	scores := make([]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += ce.batchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i := range ids {
+			ids[i], mask[i], types[i] = int64(ce.tok.PadID()), 0, 0
+		}
+		end := start + ce.batchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		for row, text := range texts[start:end] {
+			rIDs, rMask, rTypes := ce.tok.EncodePair(query, text, ce.seqLen)
+			off := row * ce.seqLen
+			copy(ids[off:], rIDs)
+			copy(mask[off:], rMask)
+			copy(types[off:], rTypes)
+		}
+		logits, err := ce.runner.run(ids, mask, types)
+		if err != nil {
+			return nil, fmt.Errorf("rerank: run model: %w", err)
+		}
+		if len(logits) < end-start {
+			return nil, fmt.Errorf("rerank: model returned %d scores for %d documents", len(logits), end-start)
+		}
+		for _, logit := range logits[:end-start] {
+			scores = append(scores, 1/(1+math.Exp(-float64(logit))))
+		}
+	}
+	return scores, nil
+}
+
+// callLocalRerank scores the documents with the local cross-encoder and
+// returns them in the same shape as callRerankAPI, best first. topN <= 0
+// returns every document.
+func callLocalRerank(ctx context.Context, s *Settings, query string, documents []interface{}, topN int) ([]interface{}, error) {
+	ce, err := getCrossEncoder(s)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, len(documents))
+	for i, d := range documents {
+		texts[i] = documentText(d)
+	}
+	scores, err := ce.score(ctx, query, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(documents))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+	if topN > 0 && topN < len(order) {
+		order = order[:topN]
+	}
+	out := make([]interface{}, len(order))
+	for i, idx := range order {
+		out[i] = map[string]interface{}{
+			"index":           idx,
+			"relevance_score": scores[idx],
+			"document":        documents[idx],
+		}
+	}
+	return out, nil
+}
+
+// documentText returns the text to score for one input document: the
+// document itself when it is a string, otherwise its "text" field.
+func documentText(d interface{}) string {
+	switch v := d.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if t, ok := v["text"]; ok && t != nil {
+			return fmt.Sprintf("%v", t)
+		}
+	}
+	return ""
+}
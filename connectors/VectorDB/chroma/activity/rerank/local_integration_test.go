@@ -0,0 +1,60 @@
+//go:build integration && onnx
+
+// Integration test for the local provider with a real cross-encoder and the
+// real ONNX Runtime. It needs an exported model and, on air-gapped machines,
+// a local onnxruntime library:
+//
+//	huggingface-cli download cross-encoder/ms-marco-MiniLM-L-6-v2 \
+//	    onnx/model.onnx tokenizer.json --local-dir /models/ms-marco
+//
+// Execute:
+//
+//	VDB_RERANK_MODEL=/models/ms-marco/onnx/model.onnx \
+//	VDB_RERANK_TOKENIZER=/models/ms-marco/tokenizer.json \
+//	VDB_ONNXRUNTIME_LIB=/opt/onnxruntime/lib/libonnxruntime.so \
+//	    go test -tags "integration onnx" -run TestLocalRerank_RealModel -v ./activity/rerank/
+//
+// Without VDB_ONNXRUNTIME_LIB the runtime is downloaded on first use.
+package rerank
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRerank_RealModel(t *testing.T) {
+	modelPath := os.Getenv("VDB_RERANK_MODEL")
+	if modelPath == "" {
+		t.Skip("VDB_RERANK_MODEL is not set")
+	}
+	s := &Settings{
+		Provider:          providerLocal,
+		ModelPath:         modelPath,
+		TokenizerPath:     os.Getenv("VDB_RERANK_TOKENIZER"),
+		OnnxRuntimePath:   os.Getenv("VDB_ONNXRUNTIME_LIB"),
+		MaxSequenceLength: 128,
+		BatchSize:         2,
+	}
+	docs := []interface{}{
+		map[string]interface{}{"text": "Bananas are rich in potassium.", "id": "fruit"},
+		map[string]interface{}{"text": "The Eiffel Tower is located in Paris, France.", "id": "paris"},
+		map[string]interface{}{"text": "Berlin is the capital of Germany.", "id": "berlin"},
+	}
+	ranked, err := callLocalRerank(context.Background(), s, "Where is the Eiffel Tower?", docs, 0)
+	require.NoError(t, err)
+	require.Len(t, ranked, 3)
+
+	first := ranked[0].(map[string]interface{})
+	assert.Equal(t, 1, first["index"], "the passage answering the query ranks first")
+	prev := 1.0
+	for _, r := range ranked {
+		score := r.(map[string]interface{})["relevance_score"].(float64)
+		assert.Greater(t, score, 0.0)
+		assert.LessOrEqual(t, score, prev, "results are ordered by score")
+		prev = score
+	}
+}
@@ -0,0 +1,185 @@
+package rerank
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data/mapper"
+	"github.com/project-flogo/core/support/connection"
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInitContext is a minimal activity.InitContext used to test New().
+type fakeInitContext struct {
+	settings map[string]interface{}
+}
+
+func (f *fakeInitContext) Settings() map[string]interface{} { return f.settings }
+func (f *fakeInitContext) Logger() log.Logger               { return log.RootLogger() }
+func (f *fakeInitContext) GetConnection(setting string) (connection.Manager, error) {
+	return nil, nil
+}
+func (f *fakeInitContext) Name() string                  { return "test-activity" }
+func (f *fakeInitContext) HostName() string              { return "test-flow" }
+func (f *fakeInitContext) MapperFactory() mapper.Factory { return nil }
+func (f *fakeInitContext) Host() activity.Host           { return nil }
+
+// testWordPieceTokenizer is a minimal BERT-style tokenizer.json.
+const testWordPieceTokenizer = `{
+  "added_tokens": [],
+  "normalizer": {"type": "BertNormalizer", "lowercase": true},
+  "pre_tokenizer": {"type": "BertPreTokenizer"},
+  "post_processor": {"type": "BertProcessing", "sep": ["[SEP]", 3], "cls": ["[CLS]", 2]},
+  "model": {
+    "type": "WordPiece",
+    "unk_token": "[UNK]",
+    "continuing_subword_prefix": "##",
+    "vocab": {"[PAD]": 0, "[UNK]": 1, "[CLS]": 2, "[SEP]": 3, "what": 4, "is": 5, "ai": 6, "cat": 7, "##s": 8, "?": 9, "dog": 10}
+  }
+}`
+
+func writeTokenizer(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tokenizer.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// fakeRunner scores each row by the number of "cat" tokens (id 7) in it.
+type fakeRunner struct {
+	seqLen int
+	runs   int
+}
+
+func (f *fakeRunner) run(ids, mask, types []int64) ([]float32, error) {
+	f.runs++
+	rows := len(ids) / f.seqLen
+	out := make([]float32, rows)
+	for r := 0; r < rows; r++ {
+		for _, id := range ids[r*f.seqLen : (r+1)*f.seqLen] {
+			if id == 7 {
+				out[r]++
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRunner) close() error { return nil }
+
+func TestCallLocalRerank_RanksAndBatches(t *testing.T) {
+	tokPath := writeTokenizer(t, testWordPieceTokenizer)
+	modelPath := filepath.Join(filepath.Dir(tokPath), "model.onnx")
+	require.NoError(t, os.WriteFile(modelPath, []byte("onnx"), 0o600))
+
+	runner := &fakeRunner{seqLen: 16}
+	orig := newBatchRunner
+	newBatchRunner = func(string, string, int, int, bool) (batchRunner, error) { return runner, nil }
+	defer func() { newBatchRunner = orig }()
+
+	s := &Settings{Provider: providerLocal, ModelPath: modelPath, MaxSequenceLength: 16, BatchSize: 2}
+	docs := []interface{}{
+		map[string]interface{}{"text": "dog", "id": "a"},
+		"cat cat",
+		map[string]interface{}{"text": "cat", "id": "c"},
+	}
+	ranked, err := callLocalRerank(context.Background(), s, "cat", docs, 2)
+	require.NoError(t, err)
+	require.Len(t, ranked, 2)
+	assert.Equal(t, 2, runner.runs, "3 documents in batches of 2")
+
+	first := ranked[0].(map[string]interface{})
+	second := ranked[1].(map[string]interface{})
+	assert.Equal(t, 1, first["index"])
+	assert.Equal(t, "cat cat", first["document"])
+	assert.Equal(t, 2, second["index"])
+	assert.Greater(t, first["relevance_score"].(float64), second["relevance_score"].(float64))
+
+	ce, err := getCrossEncoder(s)
+	require.NoError(t, err)
+	assert.Same(t, runner, ce.runner, "model is loaded once per settings")
+}
+
+func TestCallLocalRerank_MissingModel(t *testing.T) {
+	s := &Settings{Provider: providerLocal, ModelPath: filepath.Join(t.TempDir(), "missing.onnx"),
+		MaxSequenceLength: 16, BatchSize: 2}
+	_, err := callLocalRerank(context.Background(), s, "q", []interface{}{"d"}, 1)
+	assert.ErrorContains(t, err, "model file")
+}
+
+func TestCallLocalRerank_TokenizerWithoutPairTemplate(t *testing.T) {
+	tokPath := writeTokenizer(t, `{"model": {"type": "WordPiece", "unk_token": "[UNK]", "vocab": {"[UNK]": 0}}}`)
+	modelPath := filepath.Join(filepath.Dir(tokPath), "model.onnx")
+	require.NoError(t, os.WriteFile(modelPath, []byte("onnx"), 0o600))
+
+	s := &Settings{Provider: providerLocal, ModelPath: modelPath, MaxSequenceLength: 16, BatchSize: 2}
+	_, err := callLocalRerank(context.Background(), s, "q", []interface{}{"d"}, 1)
+	assert.ErrorContains(t, err, "no pair template")
+}
+
+func TestFuseScores_Weighted(t *testing.T) {
+	docs := []interface{}{
+		map[string]interface{}{"text": "a", "score": 0.9},
+		map[string]interface{}{"text": "b", "score": 0.1},
+		map[string]interface{}{"text": "c", "score": 0.5},
+	}
+	ranked := []interface{}{
+		map[string]interface{}{"index": 1, "relevance_score": 0.8, "document": docs[1]},
+		map[string]interface{}{"index": 0, "relevance_score": 0.7, "document": docs[0]},
+		map[string]interface{}{"index": 2, "relevance_score": 0.1, "document": docs[2]},
+	}
+	out := fuseScores(ranked, docs, fusionWeighted, 0.5, 2)
+	require.Len(t, out, 2)
+	first := out[0].(map[string]interface{})
+	assert.Equal(t, 0, first["index"], "strong vector score lifts doc a above b")
+	assert.Equal(t, 0.9, first["vector_score"])
+	assert.InDelta(t, 0.5*6.0/7.0+0.5, first["fused_score"].(float64), 1e-9)
+}
+
+func TestFuseScores_RRF(t *testing.T) {
+	docs := []interface{}{
+		map[string]interface{}{"text": "a", "score": 0.9},
+		map[string]interface{}{"text": "b"},
+	}
+	ranked := []interface{}{
+		map[string]interface{}{"index": 1, "relevance_score": 0.8},
+		map[string]interface{}{"index": 0, "relevance_score": 0.7},
+	}
+	out := fuseScores(ranked, docs, fusionRRF, 0, 0)
+	require.Len(t, out, 2)
+	// Both documents are first in one ranking and second in the other.
+	a := out[0].(map[string]interface{})
+	b := out[1].(map[string]interface{})
+	assert.InDelta(t, a["fused_score"].(float64), b["fused_score"].(float64), 1e-12)
+	for _, r := range out {
+		m := r.(map[string]interface{})
+		_, hasVec := m["vector_score"]
+		assert.Equal(t, m["index"] == 0, hasVec, "only documents with a score get vector_score")
+	}
+}
+
+func TestNew_LocalProviderValidation(t *testing.T) {
+	_, err := New(&fakeInitContext{settings: map[string]interface{}{"provider": "local"}})
+	assert.ErrorContains(t, err, "modelPath is required")
+
+	_, err = New(&fakeInitContext{settings: map[string]interface{}{"provider": "grpc"}})
+	assert.ErrorContains(t, err, "provider must be api or local")
+
+	_, err = New(&fakeInitContext{settings: map[string]interface{}{"rerankEndpoint": "http://x", "scoreFusion": "max"}})
+	assert.ErrorContains(t, err, "scoreFusion must be one of")
+
+	act, err := New(&fakeInitContext{settings: map[string]interface{}{"provider": "local", "modelPath": "/models/m.onnx", "scoreFusion": "weighted"}})
+	require.NoError(t, err)
+	s := act.(*Activity).settings
+	assert.Equal(t, defaultMaxSequenceLength, s.MaxSequenceLength)
+	assert.Equal(t, defaultLocalBatchSize, s.BatchSize)
+	assert.Equal(t, defaultRerankWeight, s.RerankWeight)
+	assert.True(t, strings.HasSuffix(tokenizerPathFor(s), "/models/tokenizer.json"))
+}
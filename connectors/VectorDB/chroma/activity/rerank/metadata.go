@@ -4,14 +4,27 @@ import (
 	"fmt"
 )
 
-// Settings for the rerank activity. Rerank is HTTP-based and standalone —
-// no VectorDB connection is required.
+// Settings for the rerank activity. Rerank is standalone — no VectorDB
+// connection is required. The "api" provider calls an HTTP rerank endpoint;
+// the "local" provider runs an ONNX cross-encoder model in-process.
 type Settings struct {
-	RerankEndpoint string `md:"rerankEndpoint,required"`
+	Provider       string `md:"provider"` // "api" (default) or "local"
+	RerankEndpoint string `md:"rerankEndpoint"`
 	APIKey         string `md:"apiKey"`
 	Model          string `md:"model"`
 	TopN           int    `md:"topN"`
 	TimeoutSeconds int    `md:"timeoutSeconds"`
+
+	// Local provider
+	ModelPath         string `md:"modelPath"`         // cross-encoder .onnx file
+	TokenizerPath     string `md:"tokenizerPath"`     // tokenizer.json; defaults to the model's directory
+	OnnxRuntimePath   string `md:"onnxRuntimePath"`   // onnxruntime shared library; disables download when set
+	MaxSequenceLength int    `md:"maxSequenceLength"` // tokens per query/document pair (default 512)
+	BatchSize         int    `md:"batchSize"`         // pairs scored per model run (default 16)
+
+	// Score fusion with the vector search score of each input document
+	ScoreFusion  string  `md:"scoreFusion"`  // "none" (default), "weighted" or "rrf"
+	RerankWeight float64 `md:"rerankWeight"` // weight of the rerank score in weighted fusion (default 0.7)
 }
 
 type Input struct {
@@ -0,0 +1,90 @@
+//go:build onnx
+
+package rerank
+
+import (
+	"fmt"
+
+	ort "github.com/amikos-tech/pure-onnx/ort"
+	vdbembed "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/embeddings"
+)
+
+// onnxRunner runs a cross-encoder ONNX model on CPU. Input and output
+// tensors are allocated once at the batch shape and refilled for every run.
+type onnxRunner struct {
+	session *ort.AdvancedSession
+	ids     *ort.Tensor[int64]
+	mask    *ort.Tensor[int64]
+	types   *ort.Tensor[int64] // nil when the model takes no token_type_ids
+	logits  *ort.Tensor[float32]
+}
+
+// newONNXRunner loads the model at modelPath. Cross-encoders take
+// input_ids and attention_mask (plus token_type_ids for BERT-style models)
+// and return one "logits" value per pair. The runtime library is shared
+// with the Local embedding provider; runtimePath selects it when set.
+func newONNXRunner(modelPath, runtimePath string, batchSize, seqLen int, useTypeIDs bool) (batchRunner, error) {
+	if err := vdbembed.InitONNXRuntime(runtimePath); err != nil {
+		return nil, fmt.Errorf("initialise ONNX runtime: %w", err)
+	}
+	r := &onnxRunner{}
+	shape := ort.Shape{int64(batchSize), int64(seqLen)}
+	n := batchSize * seqLen
+	var err error
+	if r.ids, err = ort.NewTensor(shape, make([]int64, n)); err != nil {
+		return nil, err
+	}
+	if r.mask, err = ort.NewTensor(shape, make([]int64, n)); err != nil {
+		_ = r.close()
+		return nil, err
+	}
+	inputNames := []string{"input_ids", "attention_mask"}
+	inputs := []ort.Value{r.ids, r.mask}
+	if useTypeIDs {
+		if r.types, err = ort.NewTensor(shape, make([]int64, n)); err != nil {
+			_ = r.close()
+			return nil, err
+		}
+		inputNames = append(inputNames, "token_type_ids")
+		inputs = append(inputs, r.types)
+	}
+	if r.logits, err = ort.NewEmptyTensor[float32](ort.Shape{int64(batchSize), 1}); err != nil {
+		_ = r.close()
+		return nil, err
+	}
+	r.session, err = ort.NewAdvancedSession(modelPath, inputNames, []string{"logits"},
+		inputs, []ort.Value{r.logits}, nil)
+	if err != nil {
+		_ = r.close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *onnxRunner) run(ids, mask, types []int64) ([]float32, error) {
+	copy(r.ids.GetData(), ids)
+	copy(r.mask.GetData(), mask)
+	if r.types != nil {
+		copy(r.types.GetData(), types)
+	}
+	if err := r.session.Run(); err != nil {
+		return nil, err
+	}
+	out := r.logits.GetData()
+	return append([]float32(nil), out...), nil
+}
+
+func (r *onnxRunner) close() error {
+	if r.session != nil {
+		_ = r.session.Destroy()
+	}
+	for _, t := range []*ort.Tensor[int64]{r.ids, r.mask, r.types} {
+		if t != nil {
+			_ = t.Destroy()
+		}
+	}
+	if r.logits != nil {
+		_ = r.logits.Destroy()
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !onnx
+
+package rerank
+
+import "errors"
+
+// errONNXNotBuilt is returned by the local provider in binaries built without
+// the onnx build tag, which links the ONNX Runtime bindings.
+var errONNXNotBuilt = errors.New("the local provider needs a binary built with -tags onnx")
+
+func newONNXRunner(modelPath, runtimePath string, batchSize, seqLen int, useTypeIDs bool) (batchRunner, error) {
+	return nil, errONNXNotBuilt
+}
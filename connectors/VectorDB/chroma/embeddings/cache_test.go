@@ -0,0 +1,107 @@
+package vdbembed
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKey(t *testing.T) {
+	k := CacheKey("m", 0, "", "hello")
+	assert.Len(t, k, 64)
+	assert.Equal(t, k, CacheKey("m", 0, "", "hello"))
+	assert.NotEqual(t, k, CacheKey("m2", 0, "", "hello"))
+	assert.NotEqual(t, k, CacheKey("m", 256, "", "hello"))
+	assert.NotEqual(t, k, CacheKey("m", 0, "search_query", "hello"))
+	assert.NotEqual(t, CacheKey("ab", 0, "", "c"), CacheKey("a", 0, "", "bc"))
+}
+
+func TestMemoryCache_LRU(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Put("a", []float64{1})
+	c.Put("b", []float64{2})
+	_, ok := c.Get("a") // a is now most recently used
+	require.True(t, ok)
+	c.Put("c", []float64{3})
+
+	_, ok = c.Get("b")
+	assert.False(t, ok, "least recently used entry is evicted")
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, []float64{1}, v)
+
+	v[0] = 99
+	v, _ = c.Get("a")
+	assert.Equal(t, []float64{1}, v, "callers get a copy")
+}
+
+func TestDiskCache_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	require.NoError(t, err)
+	key := CacheKey("m", 0, "", "text")
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+
+	c.Put(key, []float64{0.5, -1.25, 3})
+	reopened, err := NewDiskCache(dir)
+	require.NoError(t, err)
+	v, ok := reopened.Get(key)
+	require.True(t, ok)
+	assert.Equal(t, []float64{0.5, -1.25, 3}, v)
+
+	_, err = NewDiskCache("")
+	assert.Error(t, err)
+}
+
+func TestCreateEmbeddingsCached(t *testing.T) {
+	var requests [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req.Input)
+		data := make([]map[string]interface{}, len(req.Input))
+		for i, text := range req.Input {
+			data[i] = map[string]interface{}{"embedding": []float64{float64(len(text)), 1}, "index": i}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":  data,
+			"usage": map[string]interface{}{"total_tokens": 7},
+		})
+	}))
+	defer srv.Close()
+
+	req := EmbeddingRequest{Provider: ProviderOpenAI, BaseURL: srv.URL + "/v1", Model: "m", Texts: []string{"aa", "bbb", "aa"}}
+	cache := NewMemoryCache(0)
+
+	resp, err := CreateEmbeddingsCached(context.Background(), req, cache)
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{"aa", "bbb"}, requests[0])
+	assert.Equal(t, [][]float64{{2, 1}, {3, 1}, {2, 1}}, resp.Embeddings)
+	assert.Equal(t, 0, resp.CacheHits)
+	assert.Equal(t, 7, resp.TokensUsed)
+	assert.Equal(t, 2, resp.Dimensions)
+
+	req.Texts = []string{"bbb", "cccc"}
+	resp, err = CreateEmbeddingsCached(context.Background(), req, cache)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, []string{"cccc"}, requests[1])
+	assert.Equal(t, 1, resp.CacheHits)
+	assert.Equal(t, [][]float64{{3, 1}, {4, 1}}, resp.Embeddings)
+
+	resp, err = CreateEmbeddingsCached(context.Background(), req, cache)
+	require.NoError(t, err)
+	assert.Len(t, requests, 2, "fully cached request makes no API call")
+	assert.Equal(t, 2, resp.CacheHits)
+	assert.Equal(t, 0, resp.TokensUsed)
+	assert.Equal(t, 2, resp.Dimensions)
+}
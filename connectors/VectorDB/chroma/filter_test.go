@@ -0,0 +1,244 @@
+package vectordb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// ParseFilter
+// ---------------------------------------------------------------------------
+
+func TestParseFilter_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+	}{
+		{"unknown operator", map[string]interface{}{"a": map[string]interface{}{"$regex": "x"}}},
+		{"unknown logical operator", map[string]interface{}{"$nor": []interface{}{}}},
+		{"empty $or", map[string]interface{}{"$or": []interface{}{}}},
+		{"$or of scalars", map[string]interface{}{"$or": []interface{}{"a"}}},
+		{"empty filter in $and", map[string]interface{}{"$and": []interface{}{map[string]interface{}{}}}},
+		{"$not of scalar", map[string]interface{}{"$not": "a"}},
+		{"$in of scalar", map[string]interface{}{"a": map[string]interface{}{"$in": "x"}}},
+		{"$exists of string", map[string]interface{}{"a": map[string]interface{}{"$exists": "yes"}}},
+		{"$gt of bool", map[string]interface{}{"a": map[string]interface{}{"$gt": true}}},
+		{"null value", map[string]interface{}{"a": nil}},
+		{"empty path segment", map[string]interface{}{"a..b": 1}},
+		{"mixed operators and keys", map[string]interface{}{"a": map[string]interface{}{"$eq": 1, "b": 2}}},
+		{"$or inside a field", map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"$or": []interface{}{}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.filters)
+			if err == nil {
+				t.Fatalf("ParseFilter(%v) = nil error, want error", tt.filters)
+			}
+			vdbErr, ok := err.(*VDBError)
+			if !ok || vdbErr.Code != ErrCodeInvalidFilter {
+				t.Fatalf("error = %v, want code %s", err, ErrCodeInvalidFilter)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Empty(t *testing.T) {
+	expr, err := ParseFilter(nil)
+	if err != nil || expr != nil {
+		t.Fatalf("ParseFilter(nil) = %v, %v; want nil, nil", expr, err)
+	}
+	if !expr.Match(map[string]interface{}{"a": 1}) {
+		t.Error("nil filter must match everything")
+	}
+}
+
+func TestParseFilter_Shapes(t *testing.T) {
+	expr, err := ParseFilter(map[string]interface{}{
+		"meta": map[string]interface{}{"author": map[string]interface{}{"team": "search"}},
+		"tags": []string{"a", "b"},
+		"year": map[string]interface{}{"$gte": 2020, "$lt": 2025},
+		"$and": []interface{}{map[string]interface{}{"lang": "en"}},
+	})
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	if expr.Op != FilterAnd {
+		t.Fatalf("root op = %s, want $and", expr.Op)
+	}
+	// Nested $and and the two year operators are flattened into the root.
+	if len(expr.Children) != 5 {
+		t.Fatalf("root has %d children, want 5: %+v", len(expr.Children), expr.Children)
+	}
+	byField := map[string][]string{}
+	for _, c := range expr.Children {
+		byField[c.Field] = append(byField[c.Field], c.Op)
+	}
+	if ops := byField["meta.author.team"]; len(ops) != 1 || ops[0] != FilterEq {
+		t.Errorf("meta.author.team ops = %v, want [$eq]", ops)
+	}
+	if ops := byField["tags"]; len(ops) != 1 || ops[0] != FilterIn {
+		t.Errorf("tags ops = %v, want [$in]", ops)
+	}
+	if ops := byField["year"]; len(ops) != 2 {
+		t.Errorf("year ops = %v, want $gte and $lt", ops)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// FilterExpr.Match
+// ---------------------------------------------------------------------------
+
+func TestFilterMatch(t *testing.T) {
+	payload := map[string]interface{}{
+		"category": "news",
+		"year":     json.Number("2022"),
+		"score":    0.5,
+		"tags":     []interface{}{"faq", "billing"},
+		"meta": map[string]interface{}{
+			"author": map[string]interface{}{"team": "search", "level": 3},
+		},
+		"flat.key": "x",
+	}
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    bool
+	}{
+		{"eq", map[string]interface{}{"category": "news"}, true},
+		{"eq mismatch", map[string]interface{}{"category": "blog"}, false},
+		{"number across types", map[string]interface{}{"year": 2022}, true},
+		{"string is not a number", map[string]interface{}{"year": "2022"}, false},
+		{"range", map[string]interface{}{"year": map[string]interface{}{"$gte": 2020, "$lt": 2025}}, true},
+		{"range miss", map[string]interface{}{"score": map[string]interface{}{"$gt": 0.5}}, false},
+		{"string range", map[string]interface{}{"category": map[string]interface{}{"$lt": "sports"}}, true},
+		{"in", map[string]interface{}{"category": []interface{}{"blog", "news"}}, true},
+		{"nin", map[string]interface{}{"category": map[string]interface{}{"$nin": []interface{}{"blog"}}}, true},
+		{"ne missing field", map[string]interface{}{"missing": map[string]interface{}{"$ne": "x"}}, true},
+		{"eq missing field", map[string]interface{}{"missing": "x"}, false},
+		{"exists", map[string]interface{}{"meta.author": map[string]interface{}{"$exists": true}}, true},
+		{"not exists", map[string]interface{}{"missing": map[string]interface{}{"$exists": false}}, true},
+		{"contains", map[string]interface{}{"tags": map[string]interface{}{"$contains": "faq"}}, true},
+		{"contains miss", map[string]interface{}{"tags": map[string]interface{}{"$contains": "news"}}, false},
+		{"contains on scalar", map[string]interface{}{"category": map[string]interface{}{"$contains": "news"}}, false},
+		{"eq on array", map[string]interface{}{"tags": "billing"}, true},
+		{"nested path", map[string]interface{}{"meta.author.team": "search"}, true},
+		{"nested object", map[string]interface{}{"meta": map[string]interface{}{"author": map[string]interface{}{"level": map[string]interface{}{"$gt": 2}}}}, true},
+		{"dotted key", map[string]interface{}{"flat.key": "x"}, true},
+		{"or", map[string]interface{}{"$or": []interface{}{
+			map[string]interface{}{"category": "blog"},
+			map[string]interface{}{"meta.author.team": "search"},
+		}}, true},
+		{"or miss", map[string]interface{}{"$or": []interface{}{
+			map[string]interface{}{"category": "blog"},
+			map[string]interface{}{"year": map[string]interface{}{"$lt": 2000}},
+		}}, false},
+		{"not", map[string]interface{}{"$not": map[string]interface{}{"category": "news"}}, false},
+		{"and with not", map[string]interface{}{
+			"category": "news",
+			"$not":     map[string]interface{}{"tags": map[string]interface{}{"$contains": "spam"}},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filters)
+			if err != nil {
+				t.Fatalf("ParseFilter: %v", err)
+			}
+			if got := expr.Match(payload); got != tt.want {
+				t.Errorf("Match(%v) = %v, want %v", tt.filters, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFilter(t *testing.T) {
+	expr, err := ParseFilter(map[string]interface{}{
+		"category": "news",
+		"$not":     map[string]interface{}{"status": "draft"},
+	})
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	pushed, residual := splitFilter(expr, func(e *FilterExpr) bool { return e.Op != FilterNot })
+	if pushed == nil || pushed.Op != FilterEq || pushed.Field != "category" {
+		t.Errorf("pushed = %+v, want category $eq", pushed)
+	}
+	if residual == nil || residual.Op != FilterNot {
+		t.Errorf("residual = %+v, want $not", residual)
+	}
+
+	pushed, residual = splitFilter(expr, func(*FilterExpr) bool { return true })
+	if pushed != expr || residual != nil {
+		t.Errorf("fully native split = %+v, %+v", pushed, residual)
+	}
+}
+
+func TestMatchResults(t *testing.T) {
+	expr, _ := ParseFilter(map[string]interface{}{"keep": true})
+	results := []SearchResult{
+		{ID: "1", Payload: map[string]interface{}{"keep": true}},
+		{ID: "2", Payload: map[string]interface{}{"keep": false}},
+		{ID: "3", Payload: map[string]interface{}{"keep": true}},
+		{ID: "4", Payload: map[string]interface{}{"keep": true}},
+	}
+	got := matchResults(results, expr, 2, true)
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "3" {
+		t.Fatalf("matchResults = %+v, want IDs 1 and 3", got)
+	}
+	if got[0].Payload != nil {
+		t.Errorf("payload kept although skipPayload is set")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Chroma translation
+// ---------------------------------------------------------------------------
+
+func TestChromaFilter_Split(t *testing.T) {
+	where, residual, err := chromaFilter(map[string]interface{}{
+		"category": "news",
+		"tags":     map[string]interface{}{"$contains": "faq"},
+		"$or": []interface{}{
+			map[string]interface{}{"year": map[string]interface{}{"$gte": 2020}},
+			map[string]interface{}{"pinned": true},
+		},
+		"$not":   map[string]interface{}{"status": "draft"},
+		"author": map[string]interface{}{"$exists": true},
+	})
+	if err != nil {
+		t.Fatalf("chromaFilter: %v", err)
+	}
+	if where == nil {
+		t.Fatal("expected a native where clause")
+	}
+	if err := where.Validate(); err != nil {
+		t.Errorf("where clause invalid: %v", err)
+	}
+	// $not and $exists have no Chroma equivalent.
+	if residual == nil || len(residual.Conjuncts()) != 2 {
+		t.Fatalf("residual = %+v, want the $not and $exists conditions", residual)
+	}
+	for _, c := range residual.Conjuncts() {
+		if c.Op != FilterNot && c.Op != FilterExists {
+			t.Errorf("unexpected residual condition %+v", c)
+		}
+	}
+}
+
+func TestChromaFilter_MixedInIsResidual(t *testing.T) {
+	where, residual, err := chromaFilter(map[string]interface{}{
+		"code": []interface{}{"a", 1},
+	})
+	if err != nil {
+		t.Fatalf("chromaFilter: %v", err)
+	}
+	if where != nil || residual == nil {
+		t.Errorf("mixed-type $in should be matched client-side, got where=%v residual=%+v", where, residual)
+	}
+}
+
+func TestChromaFilter_Invalid(t *testing.T) {
+	if _, _, err := chromaFilter(map[string]interface{}{"a": map[string]interface{}{"$like": "x"}}); err == nil {
+		t.Fatal("expected an error for an unknown operator")
+	}
+}
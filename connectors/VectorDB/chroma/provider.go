@@ -19,6 +19,7 @@ type chromaClient struct {
 
 // Compile-time proof that chromaClient satisfies the full VectorDBClient interface.
 var _ VectorDBClient = (*chromaClient)(nil)
+var _ MetricReader = (*chromaClient)(nil)
 
 func newChromaClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	tlsCfg, err := buildTLSConfig(cfg)
@@ -155,6 +156,35 @@ func (c *chromaClient) CollectionExists(ctx context.Context, name string) (bool,
 	return exists, nil
 }
 
+// CollectionMetric returns the HNSW space of the collection, read from its
+// "hnsw:space" metadata or, on Chroma 1.x, its configuration.
+func (c *chromaClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	col, err := c.getCollection(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	var space string
+	if md := col.Metadata(); md != nil {
+		space, _ = md.GetString(chromago.HNSWSpace)
+	}
+	if cfg := col.Configuration(); space == "" && cfg != nil {
+		if hnsw, ok := cfg.GetRaw("hnsw"); ok {
+			if m, ok := hnsw.(map[string]interface{}); ok {
+				space, _ = m["space"].(string)
+			}
+		}
+	}
+	switch embeddings.DistanceMetric(space) {
+	case embeddings.COSINE:
+		return "cosine", nil
+	case embeddings.IP:
+		return "dot", nil
+	case embeddings.L2:
+		return "euclidean", nil
+	}
+	return space, nil
+}
+
 func (c *chromaClient) getCollection(ctx context.Context, name string) (chromago.Collection, error) {
 	var col chromago.Collection
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
@@ -280,12 +310,31 @@ func (c *chromaClient) DeleteByFilter(ctx context.Context, collectionName string
 		// Require at least one filter to prevent accidental data loss.
 		return 0, newError(ErrCodeProviderError, "DeleteByFilter requires at least one filter", nil)
 	}
+	where, residual, err := chromaFilter(filters)
+	if err != nil {
+		return 0, err
+	}
 	col, err := c.getCollection(ctx, collectionName)
 	if err != nil {
 		return 0, err
 	}
+	if residual != nil {
+		// Part of the filter has no Chroma equivalent: select the matching
+		// IDs client-side and delete them by ID.
+		ids, err := c.matchingIDs(ctx, col, where, residual)
+		if err != nil {
+			return 0, newError(ErrCodeProviderError, "DeleteByFilter failed", err)
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	opts := []chromago.CollectionDeleteOption{}
-	if where := chromaWhereFilter(filters); where != nil {
+	if where != nil {
 		opts = append(opts, chromago.WithWhere(where))
 	}
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
@@ -296,7 +345,40 @@ func (c *chromaClient) DeleteByFilter(ctx context.Context, collectionName string
 	return -1, nil
 }
 
+// matchingIDs returns the IDs of the documents selected by where whose
+// metadata matches residual.
+func (c *chromaClient) matchingIDs(ctx context.Context, col chromago.Collection, where chromago.WhereFilter, residual *FilterExpr) ([]string, error) {
+	getOpts := []chromago.CollectionGetOption{chromago.WithInclude(chromago.IncludeMetadatas)}
+	if where != nil {
+		getOpts = append(getOpts, chromago.WithWhere(where))
+	}
+	var result chromago.GetResult
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var retryErr error
+		result, retryErr = col.Get(ctx, getOpts...)
+		return retryErr
+	}); err != nil {
+		return nil, err
+	}
+	metas := result.GetMetadatas()
+	var ids []string
+	for i, id := range result.GetIDs() {
+		var payload map[string]interface{}
+		if i < len(metas) && metas[i] != nil {
+			payload = chromaMetadataToMap(metas[i])
+		}
+		if residual.Match(payload) {
+			ids = append(ids, string(id))
+		}
+	}
+	return ids, nil
+}
+
 func (c *chromaClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (*ScrollResult, error) {
+	where, residual, err := chromaFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	col, err := c.getCollection(ctx, req.CollectionName)
 	if err != nil {
 		return nil, err
@@ -339,7 +421,7 @@ func (c *chromaClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 		include = append(include, chromago.IncludeEmbeddings)
 	}
 	getOpts := []chromago.CollectionGetOption{chromago.WithInclude(include...)}
-	if where := chromaWhereFilter(req.Filters); where != nil {
+	if where != nil {
 		getOpts = append(getOpts, chromago.WithWhere(where))
 	}
 
@@ -353,13 +435,28 @@ func (c *chromaClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 		allDocs := result.GetDocuments()
 		allMetas := result.GetMetadatas()
 		allEmbs := result.GetEmbeddings()
-		total := len(allIDs)
+		// The residual filter is applied before slicing so that offsets
+		// stay stable across pages.
+		matched := make([]int, 0, len(allIDs))
+		for i := range allIDs {
+			if residual != nil {
+				var payload map[string]interface{}
+				if i < len(allMetas) && allMetas[i] != nil {
+					payload = chromaMetadataToMap(allMetas[i])
+				}
+				if !residual.Match(payload) {
+					continue
+				}
+			}
+			matched = append(matched, i)
+		}
+		total := len(matched)
 		end := offset + limit
 		if end > total {
 			end = total
 		}
-		docs := make([]Document, 0, end-offset)
-		for i := offset; i < end; i++ {
+		docs := make([]Document, 0, limit)
+		for _, i := range matched[min(offset, total):end] {
 			doc := Document{ID: string(allIDs[i]), Payload: make(map[string]interface{})}
 			if i < len(allDocs) && allDocs[i] != nil {
 				doc.Content = allDocs[i].ContentString()
@@ -385,6 +482,10 @@ func (c *chromaClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 }
 
 func (c *chromaClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
+	where, residual, err := chromaFilter(filters)
+	if err != nil {
+		return 0, err
+	}
 	col, err := c.getCollection(ctx, collectionName)
 	if err != nil {
 		return 0, err
@@ -400,12 +501,19 @@ func (c *chromaClient) CountDocuments(ctx context.Context, collectionName string
 		}
 		return int64(count), nil
 	}
+	if residual != nil {
+		ids, err := c.matchingIDs(ctx, col, where, residual)
+		if err != nil {
+			return 0, newError(ErrCodeProviderError, "CountDocuments (filtered) failed", err)
+		}
+		return int64(len(ids)), nil
+	}
 	// Filtered count: load only documents (IDs are always returned regardless
 	// of Include, but at least one include field is required by Chroma API).
 	getOpts := []chromago.CollectionGetOption{
 		chromago.WithInclude(chromago.IncludeDocuments),
 	}
-	if where := chromaWhereFilter(filters); where != nil {
+	if where != nil {
 		getOpts = append(getOpts, chromago.WithWhere(where))
 	}
 	var result chromago.GetResult
@@ -423,6 +531,10 @@ func (c *chromaClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 	if err := validateSearchRequest(req.CollectionName, req.QueryVector, req.TopK); err != nil {
 		return nil, err
 	}
+	where, residual, err := chromaFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	col, err := c.getCollection(ctx, req.CollectionName)
 	if err != nil {
 		return nil, err
@@ -431,7 +543,7 @@ func (c *chromaClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 	defer cancel()
 
 	include := []chromago.Include{chromago.IncludeDistances}
-	if !req.SkipPayload {
+	if !req.SkipPayload || residual != nil {
 		include = append(include, chromago.IncludeDocuments, chromago.IncludeMetadatas)
 	}
 	if req.WithVectors {
@@ -440,10 +552,10 @@ func (c *chromaClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 
 	queryOpts := []chromago.CollectionQueryOption{
 		chromago.WithQueryEmbeddings(embeddings.NewEmbeddingFromFloat32(toFloat32Slice(req.QueryVector))),
-		chromago.WithNResults(req.TopK),
+		chromago.WithNResults(searchLimit(req.TopK, residual)),
 		chromago.WithInclude(include...),
 	}
-	if where := chromaWhereFilter(req.Filters); where != nil {
+	if where != nil {
 		queryOpts = append(queryOpts, chromago.WithWhere(where))
 	}
 
@@ -455,7 +567,8 @@ func (c *chromaClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
 	}
-	return chromaQueryResultToSearchResults(qr, req.ScoreThreshold, req.WithVectors), nil
+	results := chromaQueryResultToSearchResults(qr, req.ScoreThreshold, req.WithVectors)
+	return matchResults(results, residual, req.TopK, req.SkipPayload), nil
 }
 
 func (c *chromaClient) HybridSearch(ctx context.Context, req HybridSearchRequest) ([]SearchResult, error) {
@@ -526,72 +639,130 @@ func chromaQueryResultToSearchResults(qr chromago.QueryResult, threshold float64
 	return out
 }
 
-// chromaWhereFilter converts our generic map[string]interface{} filter into a
-// chroma-go v0.4.x WhereClause.
-//
-// Supported formats per map entry:
-//
-//	Scalar value (equality):
-//	  {"field": "foo"}  → EqString
-//	  {"field": 42}     → EqInt
-//	  {"field": 3.14}   → EqFloat
-//	  {"field": true}   → EqBool
-//
-//	Operator map:
-//	  {"field": {"$eq":  val}}  → Eq*
-//	  {"field": {"$ne":  val}}  → NotEq*
-//	  {"field": {"$gt":  val}}  → Gt*
-//	  {"field": {"$gte": val}}  → Gte*
-//	  {"field": {"$lt":  val}}  → Lt*
-//	  {"field": {"$lte": val}}  → Lte*
-//	  {"field": {"$in":  [...]}} → In*
-//	  {"field": {"$nin": [...]}} → Nin*
-//
-// Multiple entries are ANDed. Returns nil when filters is empty.
-func chromaWhereFilter(filters map[string]interface{}) chromago.WhereFilter {
-	if len(filters) == 0 {
-		return nil
+// chromaFilter parses filters into the where clause Chroma evaluates and the
+// residual that is matched client-side against the returned metadata.
+func chromaFilter(filters map[string]interface{}) (chromago.WhereFilter, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return nil, nil, err
 	}
-	clauses := make([]chromago.WhereClause, 0, len(filters))
-	for k, v := range filters {
-		switch val := v.(type) {
-		case map[string]interface{}:
-			// Operator filter: {"$gt": 5} or {"$ne": "foo"} etc.
-			for op, operand := range val {
-				if clause := chromaOperatorClause(k, op, operand); clause != nil {
-					clauses = append(clauses, clause)
-				}
-			}
+	pushed, residual := splitFilter(expr, chromaNative)
+	return chromaWhereFilter(pushed), residual, nil
+}
+
+// chromaNative reports whether Chroma evaluates a filter exactly. Chroma
+// metadata is flat, so a dotted path is a literal key; it has no $not or
+// $exists, compares only numbers with $gt/$gte/$lt/$lte and needs $in / $nin
+// values of one type.
+func chromaNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterAnd, FilterOr:
+			return true
+		case FilterEq, FilterNe, FilterContains:
+			return chromaScalar(n.Value)
+		case FilterGt, FilterGte, FilterLt, FilterLte:
+			_, isString := n.Value.(string)
+			return chromaScalar(n.Value) && !isString
+		case FilterIn, FilterNin:
+			return chromaSetKind(n.Values()) != ""
+		}
+		return false
+	})
+}
+
+// chromaScalar reports whether v has a type the chroma-go clauses accept.
+func chromaScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, int, int32, int64, float32, float64:
+		return true
+	}
+	return false
+}
+
+// chromaSetKind returns the common kind of $in / $nin values — "string",
+// "int", "float" or "bool" — or "" when they are empty or mixed.
+func chromaSetKind(items []interface{}) string {
+	kind := ""
+	for _, item := range items {
+		var k string
+		switch item.(type) {
 		case string:
-			clauses = append(clauses, chromago.EqString(k, val))
-		case int:
-			clauses = append(clauses, chromago.EqInt(k, val))
-		case int32:
-			clauses = append(clauses, chromago.EqInt(k, int(val)))
-		case int64:
-			clauses = append(clauses, chromago.EqInt(k, int(val)))
-		case float32:
-			clauses = append(clauses, chromago.EqFloat(k, val))
-		case float64:
-			clauses = append(clauses, chromago.EqFloat(k, float32(val)))
+			k = "string"
+		case int, int32, int64:
+			k = "int"
+		case float32, float64:
+			k = "float"
 		case bool:
-			clauses = append(clauses, chromago.EqBool(k, val))
+			k = "bool"
 		default:
-			clauses = append(clauses, chromago.EqString(k, fmt.Sprintf("%v", v)))
+			return ""
+		}
+		if kind != "" && k != kind {
+			return ""
 		}
+		kind = k
 	}
-	if len(clauses) == 0 {
+	return kind
+}
+
+// chromaWhereFilter converts the natively evaluated part of a filter into a
+// chroma-go v0.4.x WhereClause:
+//
+//	$and / $or             → And / Or
+//	$eq, $ne               → Eq* / NotEq*
+//	$gt, $gte, $lt, $lte   → Gt* / Gte* / Lt* / Lte*
+//	$in, $nin              → In* / Nin*
+//	$contains              → MetadataContains*
+//
+// Returns nil when expr is nil.
+func chromaWhereFilter(expr *FilterExpr) chromago.WhereFilter {
+	if expr == nil {
 		return nil
 	}
-	if len(clauses) == 1 {
-		return clauses[0]
+	return chromaWhereClause(expr)
+}
+
+func chromaWhereClause(e *FilterExpr) chromago.WhereClause {
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		clauses := make([]chromago.WhereClause, len(e.Children))
+		for i, child := range e.Children {
+			clauses[i] = chromaWhereClause(child)
+		}
+		if e.Op == FilterOr {
+			return chromago.Or(clauses...)
+		}
+		return chromago.And(clauses...)
+	case FilterContains:
+		return chromaContainsClause(e.Field, e.Value)
+	}
+	return chromaOperatorClause(e.Field, e.Op, e.Value)
+}
+
+// chromaContainsClause builds an array-membership WhereClause.
+func chromaContainsClause(key string, v interface{}) chromago.WhereClause {
+	switch val := v.(type) {
+	case string:
+		return chromago.MetadataContainsString(key, val)
+	case int:
+		return chromago.MetadataContainsInt(key, val)
+	case int32:
+		return chromago.MetadataContainsInt(key, int(val))
+	case int64:
+		return chromago.MetadataContainsInt(key, int(val))
+	case float32:
+		return chromago.MetadataContainsFloat(key, val)
+	case float64:
+		return chromago.MetadataContainsFloat(key, float32(val))
+	case bool:
+		return chromago.MetadataContainsBool(key, val)
 	}
-	return chromago.And(clauses...)
+	return nil
 }
 
 // chromaOperatorClause builds a single WhereClause for one $op / value pair.
-// Returns nil if the operator or operand type is unsupported — the caller skips
-// nil clauses so unrecognised operators are silently ignored rather than panicking.
+// chromaNative only passes operators and operand types handled here.
 func chromaOperatorClause(key, op string, operand interface{}) chromago.WhereClause {
 	switch op {
 	case "$eq":
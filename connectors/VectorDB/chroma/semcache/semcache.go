@@ -0,0 +1,329 @@
+// Package vdbsemcache is a semantic answer cache kept in a VectorDB
+// collection. An answer is stored with the embedding of its question; a
+// later question whose embedding is similar enough to a stored one is
+// answered from the cache instead of calling the LLM again.
+//
+// Entries expire after their TTL and record the IDs of the source documents
+// their answer was generated from, so re-ingesting or deleting a document
+// can invalidate every answer that depends on it.
+package vdbsemcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-chroma"
+)
+
+const (
+	// DefaultCollection is the cache collection used when none is configured.
+	DefaultCollection = "semantic_cache"
+	// DefaultThreshold is the similarity a cached question must reach to be
+	// returned for a new one.
+	DefaultThreshold = 0.92
+	// lookupCandidates is the number of nearest entries read per lookup.
+	lookupCandidates = 3
+)
+
+// Payload keys of a cache entry. The question itself is the document content.
+const (
+	AnswerKey    = "answer"
+	NamespaceKey = "namespace"
+	SourceIDsKey = "sourceIds"
+	CreatedAtKey = "createdAt" // Unix seconds
+	ExpiresAtKey = "expiresAt" // Unix seconds, 0 = never
+	// MetadataKey holds the caller's metadata as a JSON string, so it
+	// round-trips through providers that only store flat scalar metadata.
+	MetadataKey = "metadata"
+)
+
+// entryNamespace is the UUID namespace of the deterministic entry IDs.
+var entryNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("urn:flogo:vectordb:semanticCache"))
+
+// now is replaced in tests.
+var now = time.Now
+
+// Entry is one cached answer.
+type Entry struct {
+	ID        string
+	Query     string
+	Answer    string
+	Namespace string
+	SourceIDs []string
+	Metadata  map[string]interface{}
+	CreatedAt time.Time
+	// ExpiresAt is zero for an entry without TTL.
+	ExpiresAt time.Time
+	// Score is the similarity of the entry to the looked-up question.
+	Score float64
+}
+
+// LookupOptions controls Lookup.
+type LookupOptions struct {
+	Namespace string
+	// Threshold is the minimum similarity score of a hit; <= 0 selects
+	// DefaultThreshold.
+	Threshold float64
+}
+
+// Lookup returns the most similar unexpired entry of the namespace whose
+// score reaches the threshold, or nil on a miss. A cache collection that
+// does not exist yet is a miss.
+//
+// Providers that match the expiry filter client-side read a fixed number of
+// candidates, so expired entries close to the question can fill them all and
+// hide a live entry. On a miss the expired entries are therefore purged and,
+// if any were deleted, the lookup is repeated once.
+func Lookup(ctx context.Context, client vectordb.VectorDBClient, collection string, vector []float64, opt LookupOptions) (*Entry, error) {
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("semantic cache: query vector is empty")
+	}
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	threshold := opt.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	e, err := lookup(ctx, client, collection, vector, opt.Namespace, threshold)
+	if e != nil || err != nil {
+		return e, err
+	}
+	// A failed purge leaves the miss as it is; the answer is generated and
+	// stored again.
+	purged, err := client.DeleteByFilter(ctx, collection, expiredFilter())
+	if err != nil || purged == 0 {
+		return nil, nil
+	}
+	return lookup(ctx, client, collection, vector, opt.Namespace, threshold)
+}
+
+// lookup searches the cache collection once for an unexpired hit.
+func lookup(ctx context.Context, client vectordb.VectorDBClient, collection string, vector []float64, namespace string, threshold float64) (*Entry, error) {
+	t := now().Unix()
+	results, err := client.VectorSearch(ctx, vectordb.SearchRequest{
+		CollectionName: collection,
+		QueryVector:    vector,
+		TopK:           lookupCandidates,
+		ScoreThreshold: threshold,
+		Filters: map[string]interface{}{
+			NamespaceKey: namespace,
+			"$or": []interface{}{
+				map[string]interface{}{ExpiresAtKey: int64(0)},
+				map[string]interface{}{ExpiresAtKey: map[string]interface{}{"$gt": t}},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Results are ranked by score; the checks guard against providers that
+	// apply the threshold or filter approximately.
+	for _, r := range results {
+		if r.Score < threshold {
+			break
+		}
+		e := entryFromResult(r)
+		if e.Namespace != namespace || (!e.ExpiresAt.IsZero() && e.ExpiresAt.Unix() <= t) {
+			continue
+		}
+		return e, nil
+	}
+	return nil, nil
+}
+
+// Store writes an entry for e.Query with the embedding vector, creating the
+// cache collection (cosine distance) on first use, and returns the entry with
+// its ID and timestamps set. The ID is derived from the namespace and the
+// normalised question, so storing the same question again replaces its
+// entry. ttl <= 0 stores an entry that does not expire.
+func Store(ctx context.Context, client vectordb.VectorDBClient, collection string, vector []float64, e Entry, ttl time.Duration) (*Entry, error) {
+	if strings.TrimSpace(e.Query) == "" {
+		return nil, fmt.Errorf("semantic cache: query is required")
+	}
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("semantic cache: query vector is empty")
+	}
+	if err := ensureCollection(ctx, client, collection, len(vector)); err != nil {
+		return nil, err
+	}
+
+	e.ID = EntryID(e.Namespace, e.Query)
+	e.CreatedAt = time.Unix(now().Unix(), 0)
+	e.ExpiresAt = time.Time{}
+	var expires int64
+	if ttl > 0 {
+		e.ExpiresAt = e.CreatedAt.Add(ttl)
+		expires = e.ExpiresAt.Unix()
+	}
+	sourceIDs := e.SourceIDs
+	if sourceIDs == nil {
+		sourceIDs = []string{}
+	}
+	metadata := "{}"
+	if len(e.Metadata) > 0 {
+		b, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("semantic cache: metadata: %w", err)
+		}
+		metadata = string(b)
+	}
+
+	err := client.UpsertDocuments(ctx, collection, []vectordb.Document{{
+		ID:      e.ID,
+		Vector:  vector,
+		Content: e.Query,
+		Payload: map[string]interface{}{
+			AnswerKey:    e.Answer,
+			NamespaceKey: e.Namespace,
+			SourceIDsKey: sourceIDs,
+			CreatedAtKey: e.CreatedAt.Unix(),
+			ExpiresAtKey: expires,
+			MetadataKey:  metadata,
+		},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Invalidate deletes every entry generated from any of the source document
+// IDs and returns the number deleted (-1 when the provider does not report
+// it).
+func Invalidate(ctx context.Context, client vectordb.VectorDBClient, collection string, sourceIDs []string) (int64, error) {
+	clauses := make([]interface{}, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id != "" {
+			clauses = append(clauses, map[string]interface{}{SourceIDsKey: map[string]interface{}{"$contains": id}})
+		}
+	}
+	if len(clauses) == 0 {
+		return 0, nil
+	}
+	filter := clauses[0].(map[string]interface{})
+	if len(clauses) > 1 {
+		filter = map[string]interface{}{"$or": clauses}
+	}
+	return deleteWhere(ctx, client, collection, filter)
+}
+
+// PurgeExpired deletes the entries whose TTL has passed.
+func PurgeExpired(ctx context.Context, client vectordb.VectorDBClient, collection string) (int64, error) {
+	return deleteWhere(ctx, client, collection, expiredFilter())
+}
+
+// expiredFilter matches the entries whose TTL has passed.
+func expiredFilter() map[string]interface{} {
+	return map[string]interface{}{
+		ExpiresAtKey: map[string]interface{}{"$gt": int64(0), "$lte": now().Unix()},
+	}
+}
+
+// EntryID returns the ID of the entry for a question: uuid5 of the
+// namespace and the question, lower-cased with its whitespace collapsed.
+func EntryID(namespace, query string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	return uuid.NewSHA1(entryNamespace, []byte(namespace+"\x00"+normalized)).String()
+}
+
+func deleteWhere(ctx context.Context, client vectordb.VectorDBClient, collection string, filter map[string]interface{}) (int64, error) {
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil || !exists {
+		return 0, err
+	}
+	return client.DeleteByFilter(ctx, collection, filter)
+}
+
+// ensureCollection creates the cache collection if it does not exist. A
+// collection created concurrently by another flow is not an error.
+func ensureCollection(ctx context.Context, client vectordb.VectorDBClient, collection string, dimensions int) error {
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	err = client.CreateCollection(ctx, vectordb.CollectionConfig{
+		Name:           collection,
+		Dimensions:     dimensions,
+		DistanceMetric: "cosine",
+	})
+	if err != nil {
+		if exists, existsErr := client.CollectionExists(ctx, collection); existsErr == nil && exists {
+			return nil
+		}
+		return fmt.Errorf("semantic cache: create collection %s: %w", collection, err)
+	}
+	return nil
+}
+
+// entryFromResult decodes a search result into an Entry.
+func entryFromResult(r vectordb.SearchResult) *Entry {
+	e := &Entry{ID: r.ID, Query: r.Content, Score: r.Score}
+	e.Answer, _ = r.Payload[AnswerKey].(string)
+	e.Namespace, _ = r.Payload[NamespaceKey].(string)
+	e.SourceIDs = stringList(r.Payload[SourceIDsKey])
+	if s, ok := r.Payload[CreatedAtKey]; ok {
+		if t, ok := unixSeconds(s); ok && t > 0 {
+			e.CreatedAt = time.Unix(t, 0)
+		}
+	}
+	if s, ok := r.Payload[ExpiresAtKey]; ok {
+		if t, ok := unixSeconds(s); ok && t > 0 {
+			e.ExpiresAt = time.Unix(t, 0)
+		}
+	}
+	if s, ok := r.Payload[MetadataKey].(string); ok && s != "" {
+		_ = json.Unmarshal([]byte(s), &e.Metadata)
+	}
+	return e
+}
+
+// stringList converts a payload array, which providers return as []string
+// or []interface{}, to []string.
+func stringList(v interface{}) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []interface{}:
+		out := make([]string, 0, len(l))
+		for _, item := range l {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// unixSeconds converts a numeric payload value, which providers may return
+// as any JSON number type, to int64.
+func unixSeconds(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float32:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,405 @@
+package vdbtransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-chroma"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-chroma/testutil/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memClient is an in-memory VectorDBClient. Scroll cursors are document
+// offsets. Methods a test does not use fall through to the testify mock.
+type memClient struct {
+	*mockclient.VectorDBClient
+	colls     map[string][]vectordb.Document
+	dims      map[string]int
+	failAfter int // UpsertDocuments fails once this many batches were written; 0 = never
+	upserts   int
+}
+
+func newMemClient() *memClient {
+	return &memClient{
+		VectorDBClient: &mockclient.VectorDBClient{},
+		colls:          map[string][]vectordb.Document{},
+		dims:           map[string]int{},
+	}
+}
+
+func (m *memClient) CreateCollection(_ context.Context, cfg vectordb.CollectionConfig) error {
+	m.colls[cfg.Name] = nil
+	m.dims[cfg.Name] = cfg.Dimensions
+	return nil
+}
+
+func (m *memClient) CollectionExists(_ context.Context, name string) (bool, error) {
+	_, ok := m.colls[name]
+	return ok, nil
+}
+
+func (m *memClient) UpsertDocuments(_ context.Context, name string, docs []vectordb.Document) error {
+	if m.failAfter > 0 && m.upserts >= m.failAfter {
+		return errors.New("connection reset")
+	}
+	m.upserts++
+	m.colls[name] = append(m.colls[name], docs...)
+	return nil
+}
+
+func (m *memClient) ScrollDocuments(_ context.Context, req vectordb.ScrollRequest) (*vectordb.ScrollResult, error) {
+	docs, ok := m.colls[req.CollectionName]
+	if !ok {
+		return nil, vectordb.NewError(vectordb.ErrCodeCollectionNotFound, req.CollectionName, nil)
+	}
+	start, _ := strconv.Atoi(req.Offset)
+	end := start + req.Limit
+	if end > len(docs) {
+		end = len(docs)
+	}
+	res := &vectordb.ScrollResult{Documents: append([]vectordb.Document(nil), docs[start:end]...), Total: -1}
+	if end < len(docs) {
+		res.NextOffset = strconv.Itoa(end)
+	}
+	return res, nil
+}
+
+func (m *memClient) CountDocuments(_ context.Context, name string, _ map[string]interface{}) (int64, error) {
+	return int64(len(m.colls[name])), nil
+}
+
+func seed(n, dims int) []vectordb.Document {
+	docs := make([]vectordb.Document, n)
+	for i := range docs {
+		v := make([]float64, dims)
+		v[i%dims] = 1
+		docs[i] = vectordb.Document{
+			ID:      fmt.Sprintf("doc-%d", i),
+			Vector:  v,
+			Content: fmt.Sprintf("chunk %d", i),
+			Payload: map[string]interface{}{"source": "test", "n": float64(i)},
+		}
+	}
+	return docs
+}
+
+func TestCopy_ResumesFromCheckpoint(t *testing.T) {
+	src := newMemClient()
+	src.colls["kb"] = seed(10, 3)
+	dst := newMemClient()
+	dst.failAfter = 2
+
+	var last Progress
+	opt := CopyOptions{Collection: "kb", BatchSize: 4, Checkpoint: func(p Progress) error { last = p; return nil }}
+	p, err := Copy(context.Background(), src, &ClientSink{Client: dst, Collection: "kb2"}, opt)
+	require.Error(t, err)
+	assert.Equal(t, int64(8), p.Documents)
+	assert.Equal(t, "8", p.Cursor, "cursor of the first unwritten batch")
+	assert.Equal(t, last, p)
+
+	dst.failAfter = 0
+	opt.Cursor = last.Cursor
+	p, err = Copy(context.Background(), src, &ClientSink{Client: dst, Collection: "kb2"}, opt)
+	require.NoError(t, err)
+	assert.True(t, p.Complete)
+	assert.Equal(t, int64(2), p.Documents)
+	assert.Equal(t, 3, p.Dimensions)
+	assert.Equal(t, src.colls["kb"], dst.colls["kb2"])
+}
+
+// pagedClient serves fixed ScrollDocuments pages keyed by offset, like a
+// provider that filters each fetched page client-side.
+type pagedClient struct {
+	*mockclient.VectorDBClient
+	pages map[string]*vectordb.ScrollResult
+}
+
+func (c *pagedClient) ScrollDocuments(_ context.Context, req vectordb.ScrollRequest) (*vectordb.ScrollResult, error) {
+	return c.pages[req.Offset], nil
+}
+
+func TestCopy_EmptyPageWithCursorIsNotTheEnd(t *testing.T) {
+	docs := seed(3, 2)
+	src := &pagedClient{pages: map[string]*vectordb.ScrollResult{
+		"":   {NextOffset: "p2"},
+		"p2": {Documents: docs[:2], NextOffset: "p3"},
+		"p3": {NextOffset: "p4"},
+		"p4": {Documents: docs[2:]},
+	}}
+	dst := newMemClient()
+	p, err := Copy(context.Background(), src, &ClientSink{Client: dst, Collection: "kb2"}, CopyOptions{Collection: "kb"})
+	require.NoError(t, err)
+	assert.True(t, p.Complete)
+	assert.Equal(t, int64(3), p.Documents)
+	assert.Equal(t, docs, dst.colls["kb2"])
+
+	dims, err := ProbeDimensions(context.Background(), src, "kb", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, dims)
+
+	src.pages["p3"].NextOffset = "p2"
+	p, err = Copy(context.Background(), src, &ClientSink{Client: newMemClient(), Collection: "kb2"}, CopyOptions{Collection: "kb"})
+	assert.ErrorContains(t, err, "repeated")
+	assert.False(t, p.Complete)
+}
+
+func TestCopy_Validation(t *testing.T) {
+	src := newMemClient()
+	src.colls["kb"] = seed(3, 3)
+	src.colls["kb"][2].Vector = []float64{1, 0}
+	_, err := Copy(context.Background(), src, &ClientSink{Client: newMemClient(), Collection: "x"}, CopyOptions{Collection: "kb"})
+	var vErr *vectordb.VDBError
+	require.ErrorAs(t, err, &vErr)
+	assert.Equal(t, vectordb.ErrCodeInvalidDimensions, vErr.Code)
+
+	src.colls["kb"][2].Vector = nil
+	_, err = Copy(context.Background(), src, &ClientSink{Client: newMemClient(), Collection: "x"}, CopyOptions{Collection: "kb"})
+	assert.ErrorContains(t, err, "has no vector")
+}
+
+func TestPrepareTarget(t *testing.T) {
+	ctx := context.Background()
+	c := newMemClient()
+
+	_, err := PrepareTarget(ctx, c, "kb", 3, "cosine", false)
+	assert.Error(t, err, "missing collection without createCollection")
+
+	created, err := PrepareTarget(ctx, c, "kb", 3, "cosine", true)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 3, c.dims["kb"])
+
+	c.colls["kb"] = seed(1, 3)
+	created, err = PrepareTarget(ctx, c, "kb", 3, "cosine", true)
+	require.NoError(t, err)
+	assert.False(t, created)
+	_, err = PrepareTarget(ctx, c, "kb", 4, "cosine", true)
+	assert.Error(t, err, "dimension mismatch")
+}
+
+func TestMetrics(t *testing.T) {
+	assert.Equal(t, "dot", NormalizeMetric("Dot_Product"))
+	assert.Equal(t, "euclidean", NormalizeMetric("L2"))
+	assert.NoError(t, CheckMetric("cos", "COSINE"))
+	assert.NoError(t, CheckMetric("", "dot"))
+	assert.Error(t, CheckMetric("cosine", "ip"))
+}
+
+func TestFile_RoundTrip(t *testing.T) {
+	for _, format := range []string{FormatJSONL, FormatParquet} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out."+format)
+			src := newMemClient()
+			src.colls["kb"] = seed(7, 4)
+
+			w, err := CreateFile(path, format)
+			require.NoError(t, err)
+			p, err := Copy(context.Background(), src, w, CopyOptions{Collection: "kb", BatchSize: 3})
+			require.NoError(t, err)
+			_, err = w.Close()
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), p.Documents)
+
+			r, err := OpenFile(path, format)
+			require.NoError(t, err)
+			defer r.Close()
+			require.NoError(t, r.Skip(2))
+			dst := newMemClient()
+			var checkpoints int
+			p, err = CopyFile(context.Background(), r, &ClientSink{Client: dst, Collection: "kb"}, 2, 4,
+				func(Progress) error { checkpoints++; return nil })
+			require.NoError(t, err)
+			assert.True(t, p.Complete)
+			assert.Equal(t, int64(5), p.Documents)
+			assert.Equal(t, 4, checkpoints, "three batches and the end of the file")
+			assert.Equal(t, src.colls["kb"][2:], dst.colls["kb"])
+		})
+	}
+}
+
+func TestFile_ResumeDiscardsUncheckpointedBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	docs := seed(4, 2)
+
+	w, err := CreateFile(path, FormatJSONL)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBatch(context.Background(), docs[:2]))
+	size, err := w.Flush()
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBatch(context.Background(), docs[2:3])) // lost: never checkpointed
+	_, err = w.Close()
+	require.NoError(t, err)
+
+	w, err = ResumeFile(path, size)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBatch(context.Background(), docs[2:]))
+	_, err = w.Close()
+	require.NoError(t, err)
+
+	r, err := OpenFile(path, FormatJSONL)
+	require.NoError(t, err)
+	defer r.Close()
+	got, err := r.ReadBatch(10)
+	require.NoError(t, err)
+	assert.Equal(t, docs, got)
+}
+
+func TestResolveFormat(t *testing.T) {
+	f, err := ResolveFormat("/tmp/a.ndjson", "auto")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSONL, f)
+	f, err = ResolveFormat("/tmp/a.bin", "Parquet")
+	require.NoError(t, err)
+	assert.Equal(t, FormatParquet, f)
+	_, err = ResolveFormat("/tmp/a.bin", "")
+	assert.Error(t, err)
+}
+
+func TestManifest_WriteRead(t *testing.T) {
+	path := ManifestPath(filepath.Join(t.TempDir(), "out.jsonl"))
+	m, err := ReadManifest(path)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	require.NoError(t, WriteManifest(path, &Manifest{Format: FormatJSONL, Collection: "kb", Dimensions: 3, Documents: 5, Complete: true}))
+	m, err = ReadManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.Version)
+	assert.Equal(t, int64(5), m.Documents)
+	assert.NotEmpty(t, m.UpdatedAt)
+}
+
+// --- A client from "another connector module": same shapes, different types. ---
+
+type otherDoc struct {
+	ID      string                 `json:"id"`
+	Vector  []float64              `json:"vector,omitempty"`
+	Content string                 `json:"content,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+type otherCollectionConfig struct {
+	Name           string
+	Dimensions     int
+	DistanceMetric string
+}
+
+type otherScrollRequest struct {
+	CollectionName string
+	Limit          int
+	Offset         string
+	WithVectors    bool
+}
+
+type otherScrollResult struct {
+	Documents  []otherDoc
+	NextOffset string
+	Total      int64
+}
+
+type otherClient struct {
+	docs    map[string][]otherDoc
+	created []otherCollectionConfig
+}
+
+func (c *otherClient) CreateCollection(_ context.Context, cfg otherCollectionConfig) error {
+	c.created = append(c.created, cfg)
+	c.docs[cfg.Name] = nil
+	return nil
+}
+func (c *otherClient) DeleteCollection(context.Context, string) error { return nil }
+func (c *otherClient) ListCollections(context.Context) ([]string, error) {
+	return []string{"kb"}, nil
+}
+func (c *otherClient) CollectionExists(_ context.Context, name string) (bool, error) {
+	_, ok := c.docs[name]
+	return ok, nil
+}
+func (c *otherClient) UpsertDocuments(_ context.Context, name string, docs []otherDoc) error {
+	c.docs[name] = append(c.docs[name], docs...)
+	return nil
+}
+func (c *otherClient) GetDocument(context.Context, string, string) (*otherDoc, error) {
+	return nil, errors.New("not found")
+}
+func (c *otherClient) DeleteDocuments(context.Context, string, []string) error { return nil }
+func (c *otherClient) DeleteByFilter(context.Context, string, map[string]interface{}) (int64, error) {
+	return 0, nil
+}
+func (c *otherClient) ScrollDocuments(_ context.Context, req otherScrollRequest) (*otherScrollResult, error) {
+	if !req.WithVectors {
+		return nil, errors.New("vectors not requested")
+	}
+	return &otherScrollResult{Documents: c.docs[req.CollectionName], Total: -1}, nil
+}
+func (c *otherClient) CountDocuments(_ context.Context, name string, _ map[string]interface{}) (int64, error) {
+	return int64(len(c.docs[name])), nil
+}
+func (c *otherClient) VectorSearch(context.Context, map[string]interface{}) ([]otherDoc, error) {
+	return nil, nil
+}
+func (c *otherClient) HybridSearch(context.Context, map[string]interface{}) ([]otherDoc, error) {
+	return nil, nil
+}
+func (c *otherClient) HealthCheck(context.Context) error { return nil }
+func (c *otherClient) DBType() string                    { return "other" }
+func (c *otherClient) Close() error                      { return nil }
+
+// otherClientAPI plays the role of the other module's VectorDBClient interface.
+type otherClientAPI interface {
+	DBType() string
+}
+
+type otherConnection struct{ client *otherClient }
+
+func (c *otherConnection) GetClient() otherClientAPI { return c.client }
+
+func TestClientFromConnection_ForeignModule(t *testing.T) {
+	ctx := context.Background()
+	other := &otherClient{docs: map[string][]otherDoc{
+		"kb": {{ID: "a", Vector: []float64{1, 0}, Payload: map[string]interface{}{"k": "v"}}},
+	}}
+	client, err := ClientFromConnection(&otherConnection{client: other})
+	require.NoError(t, err)
+	assert.Equal(t, "other", client.DBType())
+
+	// Pull from the foreign client into a native one ...
+	native := newMemClient()
+	p, err := Copy(ctx, client, &ClientSink{Client: native, Collection: "kb"}, CopyOptions{Collection: "kb"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), p.Documents)
+	assert.Equal(t, []vectordb.Document{{ID: "a", Vector: []float64{1, 0}, Payload: map[string]interface{}{"k": "v"}}}, native.colls["kb"])
+
+	// ... and push back into a new foreign collection.
+	created, err := PrepareTarget(ctx, client, "copy", 2, "dot", true)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, []otherCollectionConfig{{Name: "copy", Dimensions: 2, DistanceMetric: "dot"}}, other.created)
+	_, err = Copy(ctx, native, &ClientSink{Client: client, Collection: "copy"}, CopyOptions{Collection: "kb"})
+	require.NoError(t, err)
+	assert.Equal(t, other.docs["kb"], other.docs["copy"])
+	v := Verify(ctx, client, "copy", 1)
+	assert.True(t, v.Verified)
+
+	_, err = client.GetDocument(ctx, "kb", "x")
+	assert.EqualError(t, err, "not found")
+}
+
+func TestClientFromConnection_Native(t *testing.T) {
+	c := newMemClient()
+	got, err := ClientFromConnection(&nativeConnection{client: c})
+	require.NoError(t, err)
+	assert.Same(t, c, got)
+
+	_, err = ClientFromConnection(struct{}{})
+	assert.Error(t, err)
+}
+
+type nativeConnection struct{ client vectordb.VectorDBClient }
+
+func (c *nativeConnection) GetClient() vectordb.VectorDBClient { return c.client }
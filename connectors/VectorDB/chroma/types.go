@@ -68,7 +68,9 @@ type SearchRequest struct {
 	ScoreThreshold float64
 
 	// Filters is a provider-agnostic metadata filter expressed as a map.
-	// Supported operators per key: {"$eq", "$ne", "$gt", "$gte", "$lt", "$lte", "$in"}.
+	// Keys are payload paths (nested keys dot-separated) or $and / $or / $not;
+	// field operators are $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $exists
+	// and $contains. See ParseFilter.
 	Filters map[string]interface{}
 
 	// WithVectors includes the stored vector in each SearchResult.
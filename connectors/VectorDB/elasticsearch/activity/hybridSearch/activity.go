@@ -74,8 +74,17 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 			nil)
 	}
 
-	l.Debugf("HybridSearch: collection=%s topK=%d alpha=%.2f hasText=%v hasDense=%v hasFilters=%v",
-		collectionName, topK, alpha, input.QueryText != "", len(input.QueryVector) > 0, len(input.Filters) > 0)
+	// MMR over-fetches candidates and re-selects a diverse topK.
+	searchTopK := topK
+	if input.MMR {
+		if input.MMRLambda < 0 || input.MMRLambda > 1 {
+			return false, fmt.Errorf("vectordb-hybrid: mmrLambda must be between 0.0 and 1.0, got %.4f", input.MMRLambda)
+		}
+		searchTopK = vectordb.MMRFetchK(topK, input.MMRFetchK)
+	}
+
+	l.Debugf("HybridSearch: collection=%s topK=%d alpha=%.2f hasText=%v hasDense=%v hasFilters=%v mmr=%v",
+		collectionName, topK, alpha, input.QueryText != "", len(input.QueryVector) > 0, len(input.Filters) > 0, input.MMR)
 
 	// OTel trace tags
 	tc := ctx.GetTracingContext()
@@ -101,7 +110,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		CollectionName: collectionName,
 		QueryText:      input.QueryText,
 		QueryVector:    input.QueryVector,
-		TopK:           topK,
+		TopK:           searchTopK,
 		ScoreThreshold: input.ScoreThreshold,
 		Filters:        input.Filters,
 		Alpha:          alpha,
@@ -119,6 +128,16 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		return true, nil
 	}
 
+	if input.MMR {
+		candidates := len(results)
+		var mmrErr error
+		results, mmrErr = vectordb.DiversifyMMR(opCtx, a.conn.GetClient(), collectionName, input.QueryVector, results, topK, input.MMRLambda, false)
+		if mmrErr != nil {
+			l.Warnf("HybridSearch: MMR could not read every candidate vector: %v", mmrErr)
+		}
+		l.Debugf("HybridSearch: MMR selected %d of %d candidates lambda=%.2f", len(results), candidates, input.MMRLambda)
+	}
+
 	duration := time.Since(start)
 	l.Debugf("HybridSearch: collection=%s results=%d duration=%s", collectionName, len(results), duration)
 	if tc != nil {
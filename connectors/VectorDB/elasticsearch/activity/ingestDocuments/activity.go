@@ -23,6 +23,7 @@ func init() { _ = activity.Register(&Activity{}, New) }
 type Activity struct {
 	settings *Settings
 	conn     *vectordbconnector.ElasticsearchConnection
+	cache    vdbembed.EmbeddingCache // nil when embeddingCache is "none"
 }
 
 func (a *Activity) Metadata() *activity.Metadata { return activityMd }
@@ -40,41 +41,89 @@ func New(ctx activity.InitContext) (activity.Activity, error) {
 		return nil, fmt.Errorf("vectordb-ingest: invalid connection type, expected *ElasticsearchConnection")
 	}
 
-	// Inherit embedding provider settings from connector when not set.
-	cs := conn.GetSettings()
-	if cs.EnableEmbedding {
-		if s.EmbeddingProvider == "" {
-			s.EmbeddingProvider = cs.EmbeddingProvider
-		}
-		if s.EmbeddingAPIKey == "" {
-			s.EmbeddingAPIKey = cs.EmbeddingAPIKey
-		}
-		if s.EmbeddingBaseURL == "" {
-			s.EmbeddingBaseURL = cs.EmbeddingBaseURL
+	// Resolve embedding credentials: inherit from connector when opted in.
+	// Activity-level values (if set) always take precedence as an override.
+	if s.UseConnectorEmbedding {
+		connSettings := conn.GetSettings()
+		if !connSettings.EnableEmbedding {
+			ctx.Logger().Warnf("IngestDocuments: useConnectorEmbedding=true but connector does not have enableEmbedding set — falling back to activity-level settings")
+		} else {
+			if s.EmbeddingProvider == "" {
+				s.EmbeddingProvider = connSettings.EmbeddingProvider
+			}
+			if s.EmbeddingAPIKey == "" {
+				s.EmbeddingAPIKey = connSettings.EmbeddingAPIKey
+			}
+			if s.EmbeddingBaseURL == "" {
+				s.EmbeddingBaseURL = connSettings.EmbeddingBaseURL
+			}
 		}
 	}
 
-	if s.ChunkStrategy == "" {
-		s.ChunkStrategy = "paragraph"
+	if s.EmbeddingModel == "" {
+		s.EmbeddingModel = "text-embedding-3-small"
+	}
+	if s.ContentField == "" {
+		s.ContentField = "text"
 	}
-	if s.ChunkSize <= 0 {
-		s.ChunkSize = 1000
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 60
 	}
-	if s.ChunkOverlap < 0 {
-		s.ChunkOverlap = 0
+
+	// Resolve and validate chunking defaults at init time so
+	// misconfiguration is caught before the first request arrives.
+	if s.EnableChunking {
+		if s.ChunkStrategy == "" {
+			s.ChunkStrategy = "paragraph"
+		}
+		if s.ChunkSize <= 0 {
+			s.ChunkSize = 1000
+		}
+		if s.ChunkOverlap < 0 {
+			s.ChunkOverlap = 0
+		}
+		cfg := ChunkConfig{
+			Strategy: ChunkStrategy(s.ChunkStrategy),
+			Size:     s.ChunkSize,
+			Overlap:  s.ChunkOverlap,
+		}
+		if err := validateChunkConfig(cfg); err != nil {
+			return nil, fmt.Errorf("vectordb-ingest: chunking config invalid: %w", err)
+		}
+	}
+
+	switch s.IngestMode {
+	case "":
+		s.IngestMode = ingestModeFull
+	case ingestModeFull, ingestModeIncremental:
+	default:
+		return nil, fmt.Errorf("vectordb-ingest: ingestMode %q is invalid, expected \"full\" or \"incremental\"", s.IngestMode)
 	}
-	if s.BatchSize <= 0 {
-		s.BatchSize = 100
+
+	var cache vdbembed.EmbeddingCache
+	switch s.EmbeddingCache {
+	case "", "none":
+		s.EmbeddingCache = "none"
+	case "memory":
+		cache = vdbembed.NewMemoryCache(s.EmbeddingCacheSize)
+	case "disk":
+		dc, err := vdbembed.NewDiskCache(s.EmbeddingCacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("vectordb-ingest: %w", err)
+		}
+		cache = dc
+	default:
+		return nil, fmt.Errorf("vectordb-ingest: embeddingCache %q is invalid, expected \"none\", \"memory\" or \"disk\"", s.EmbeddingCache)
 	}
 
-	ctx.Logger().Infof("IngestDocuments initialised: connection=%s embeddingProvider=%s embeddingModel=%s chunkStrategy=%s",
-		conn.GetName(), s.EmbeddingProvider, s.EmbeddingModel, s.ChunkStrategy)
-	return &Activity{settings: s, conn: conn}, nil
+	ctx.Logger().Infof("IngestDocuments initialised: connection=%s provider=%s embeddingProvider=%s model=%s chunking=%v strategy=%s ingestMode=%s embeddingCache=%s",
+		conn.GetName(), "elasticsearch", s.EmbeddingProvider, s.EmbeddingModel, s.EnableChunking, s.ChunkStrategy, s.IngestMode, s.EmbeddingCache)
+	return &Activity{settings: s, conn: conn, cache: cache}, nil
 }
 
 func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	l := ctx.Logger()
-	l.Debugf("IngestDocuments: starting eval")
+	l.Infof("IngestDocuments: starting eval")
 
 	input := &Input{}
 	if err := ctx.GetInputObject(input); err != nil {
@@ -89,222 +138,354 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		return false, fmt.Errorf("vectordb-ingest: collectionName is required")
 	}
 
+	rawDocs, err := parseDocuments(input.Documents, a.settings.ContentField)
+	if err != nil {
+		return false, fmt.Errorf("vectordb-ingest: %w", err)
+	}
+
+	// Build a files slice from the scalar FileName+FileContent inputs.
+	// These are populated by the multipart REST upload flow.
+	var fileEntries []interface{}
+	if input.FileName != "" && input.FileContent != nil {
+		fileEntries = []interface{}{
+			map[string]interface{}{
+				"name":    input.FileName,
+				"content": input.FileContent,
+			},
+		}
+	}
+
+	// Process binary file uploads (PDF, DOCX, TXT, MD).
+	fileDocs, err := parseFiles(fileEntries, l)
+	if err != nil {
+		return false, fmt.Errorf("vectordb-ingest: %w", err)
+	}
+	rawDocs = append(rawDocs, fileDocs...)
+
+	if len(rawDocs) == 0 {
+		return false, fmt.Errorf("vectordb-ingest: at least one document or file is required")
+	}
+
+	sourceDocCount := len(rawDocs)
+	incremental := a.settings.IngestMode == ingestModeIncremental
+	if incremental {
+		if err := stampSources(rawDocs); err != nil {
+			return false, fmt.Errorf("vectordb-ingest: %w", err)
+		}
+	}
+	l.Debugf("IngestDocuments: collection=%s source_doc_count=%d fileName=%s", collectionName, sourceDocCount, input.FileName)
+
+	// ── Optional chunking ────────────────────────────────────────────────────
+	// When enabled, each input document is split into smaller segments before
+	// embedding. The rawDocs slice is replaced with the expanded chunk slice;
+	// all downstream steps (embedding, upsert) are unaware of the split.
+	if a.settings.EnableChunking {
+		cfg := ChunkConfig{
+			Strategy: ChunkStrategy(a.settings.ChunkStrategy),
+			Size:     a.settings.ChunkSize,
+			Overlap:  a.settings.ChunkOverlap,
+		}
+		rawDocs = expandChunks(rawDocs, cfg)
+		l.Debugf("IngestDocuments: chunking strategy=%s source_docs=%d chunks=%d",
+			cfg.Strategy, sourceDocCount, len(rawDocs))
+	}
+
+	// OTel trace tags
 	tc := ctx.GetTracingContext()
 	if tc != nil {
 		tc.SetTag("db.system", "vectordb")
 		tc.SetTag("db.operation", "ingestDocuments")
 		tc.SetTag("db.vectordb.provider", "elasticsearch")
 		tc.SetTag("db.vectordb.collection", collectionName)
+		tc.SetTag("db.vectordb.source_doc_count", sourceDocCount)
+		tc.SetTag("db.vectordb.chunk_count", len(rawDocs))
+		tc.SetTag("db.vectordb.chunking_enabled", a.settings.EnableChunking)
+		tc.SetTag("db.vectordb.chunk_strategy", a.settings.ChunkStrategy)
+		tc.SetTag("db.vectordb.embedding_provider", a.settings.EmbeddingProvider)
+		tc.SetTag("db.vectordb.embedding_model", a.settings.EmbeddingModel)
 	}
 
-	start := time.Now()
-
-	var rawTexts []string
-	var rawIDs []string
-
-	// File upload path
-	if input.FileContent != "" {
-		fileName := input.FileName
-		if fileName == "" {
-			fileName = "upload.bin"
-		}
-		ext := strings.ToLower(filepath.Ext(fileName))
-		text, err := parseFile(ext, []byte(input.FileContent))
-		if err != nil {
-			return false, fmt.Errorf("vectordb-ingest: file parse error: %w", err)
-		}
-		chunks := expandChunks([]string{text}, a.settings.ChunkStrategy, a.settings.ChunkSize, a.settings.ChunkOverlap)
-		rawTexts = append(rawTexts, chunks...)
+	timeout := a.settings.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = 60
 	}
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(timeout)*time.Second)
+	defer cancel()
 
-	// Inline documents path
-	for _, rawDoc := range input.Documents {
-		docMap, ok := rawDoc.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		text := ""
-		if c, ok := docMap["content"]; ok {
-			text = fmt.Sprintf("%v", c)
-		}
-		if text == "" {
-			if c, ok := docMap["text"]; ok {
-				text = fmt.Sprintf("%v", c)
+	start := time.Now()
+
+	// -----------------------------------------------------------------------
+	// Incremental mode: assign deterministic chunk IDs and drop the chunks
+	// already stored unchanged, so only new or modified chunks are embedded.
+	// -----------------------------------------------------------------------
+	var plan *incrementalPlan
+	if incremental {
+		var planErr error
+		plan, planErr = planIncremental(opCtx, a.conn.GetClient(), collectionName, rawDocs)
+		if planErr != nil {
+			l.Errorf("IngestDocuments: incremental planning failed: collection=%s error=%v", collectionName, planErr)
+			if tc != nil {
+				tc.SetTag("error", true)
+				tc.LogKV(map[string]interface{}{"event": "error", "message": planErr.Error()})
 			}
+			if err := ctx.SetOutputObject(&Output{
+				Success:  false,
+				Error:    fmt.Sprintf("incremental ingest failed: %v", planErr),
+				Duration: time.Since(start).String(),
+			}); err != nil {
+				l.Errorf("SetOutputObject: %v", err)
+			}
+			return true, nil
 		}
-		if text == "" {
-			continue
-		}
-		id := ""
-		if d, ok := docMap["id"]; ok {
-			id = fmt.Sprintf("%v", d)
-		}
-		chunks := expandChunks([]string{text}, a.settings.ChunkStrategy, a.settings.ChunkSize, a.settings.ChunkOverlap)
-		for range chunks {
-			rawIDs = append(rawIDs, id)
-		}
-		rawTexts = append(rawTexts, chunks...)
+		rawDocs = plan.Changed
+		l.Debugf("IngestDocuments: incremental chunks=%d changed=%d skipped=%d duplicates=%d stale=%d",
+			len(plan.IDs), len(plan.Changed), plan.Skipped, plan.Duplicates, plan.staleCount)
 	}
 
-	if len(rawTexts) == 0 {
-		return false, fmt.Errorf("vectordb-ingest: no text content found in input")
+	// -----------------------------------------------------------------------
+	// Step 1: Generate embeddings in batches to avoid API payload/rate limits.
+	// -----------------------------------------------------------------------
+	const defaultEmbeddingBatchSize = 100
+	batchSize := a.settings.EmbeddingBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultEmbeddingBatchSize
+	}
+	texts := make([]string, len(rawDocs))
+	for i, d := range rawDocs {
+		texts[i] = d.Text
 	}
 
-	l.Debugf("IngestDocuments: collection=%s chunks=%d", collectionName, len(rawTexts))
-
-	var allEmbeddings [][]float64
+	allEmbeddings := make([][]float64, 0, len(texts))
 	totalTokens := 0
 	embDimensions := 0
+	cacheHits := 0
 
-	if a.settings.EmbeddingProvider != "" && a.settings.EmbeddingModel != "" {
-		connSettings := a.conn.GetSettings()
-		embedTimeout := connSettings.TimeoutSeconds * 2
-		if embedTimeout <= 0 {
-			embedTimeout = 120
+	for batchStart := 0; batchStart < len(texts); batchStart += batchSize {
+		batchEnd := batchStart + batchSize
+		if batchEnd > len(texts) {
+			batchEnd = len(texts)
 		}
-		embedCtx, embedCancel := context.WithTimeout(ctx.GoContext(), time.Duration(embedTimeout)*time.Second)
-		defer embedCancel()
-
-		batchSize := a.settings.BatchSize
-		if batchSize <= 0 {
-			batchSize = 100
+		embReq := vdbembed.EmbeddingRequest{
+			Provider:   vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
+			APIKey:     a.settings.EmbeddingAPIKey,
+			BaseURL:    a.settings.EmbeddingBaseURL,
+			Model:      a.settings.EmbeddingModel,
+			Texts:      texts[batchStart:batchEnd],
+			Dimensions: a.settings.EmbeddingDimensions,
+			InputType:  "search_document", // Cohere: optimise for indexing, not querying
 		}
-
-		for batchStart := 0; batchStart < len(rawTexts); batchStart += batchSize {
-			batchEnd := batchStart + batchSize
-			if batchEnd > len(rawTexts) {
-				batchEnd = len(rawTexts)
-			}
-
-			embResp, embErr := vdbembed.CreateEmbeddings(embedCtx, vdbembed.EmbeddingRequest{
-				Provider:  vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
-				APIKey:    a.settings.EmbeddingAPIKey,
-				BaseURL:   a.settings.EmbeddingBaseURL,
-				Model:     a.settings.EmbeddingModel,
-				Texts:     rawTexts[batchStart:batchEnd],
-				InputType: "search_document",
-			})
-			if embErr != nil {
-				l.Errorf("IngestDocuments: embedding batch [%d-%d] error=%v", batchStart, batchEnd, embErr)
-				if tc != nil {
-					tc.SetTag("error", true)
-				}
-				if err2 := ctx.SetOutputObject(&Output{
-					Success:  false,
-					Error:    fmt.Sprintf("embedding batch [%d-%d] failed: %v", batchStart, batchEnd, embErr),
-					Duration: time.Since(start).String(),
-				}); err2 != nil {
-					l.Errorf("SetOutputObject: %v", err2)
-				}
-				return true, nil
+		embResp, embErr := vdbembed.CreateEmbeddingsCached(opCtx, embReq, a.cache)
+		if embErr != nil {
+			l.Errorf("IngestDocuments: embedding batch %d-%d failed: collection=%s error=%v",
+				batchStart, batchEnd, collectionName, embErr)
+			if tc != nil {
+				tc.SetTag("error", true)
+				tc.LogKV(map[string]interface{}{"event": "error", "message": embErr.Error()})
 			}
-			allEmbeddings = append(allEmbeddings, embResp.Embeddings...)
-			totalTokens += embResp.TokensUsed
-			if embDimensions == 0 {
-				embDimensions = embResp.Dimensions
+			if err := ctx.SetOutputObject(&Output{
+				Success:  false,
+				Error:    fmt.Sprintf("embedding batch [%d-%d] failed: %v", batchStart, batchEnd, embErr),
+				Duration: time.Since(start).String(),
+			}); err != nil {
+				l.Errorf("SetOutputObject: %v", err)
 			}
+			return true, nil
+		}
+		allEmbeddings = append(allEmbeddings, embResp.Embeddings...)
+		totalTokens += embResp.TokensUsed
+		cacheHits += embResp.CacheHits
+		if embDimensions == 0 {
+			embDimensions = embResp.Dimensions
 		}
-		l.Debugf("IngestDocuments: embedded %d texts dims=%d tokens=%d", len(rawTexts), embDimensions, totalTokens)
-		_ = totalTokens
 	}
 
-	docs := make([]vectordb.Document, len(rawTexts))
-	ids := make([]string, len(rawTexts))
-	for i, text := range rawTexts {
-		id := ""
-		if i < len(rawIDs) {
-			id = rawIDs[i]
-		}
+	l.Debugf("IngestDocuments: embedded %d texts dimensions=%d tokens=%d cacheHits=%d elapsed=%s",
+		len(rawDocs), embDimensions, totalTokens, cacheHits, time.Since(start))
+
+	// -----------------------------------------------------------------------
+	// Step 2: Build vectordb.Document slice — assign IDs and attach vectors.
+	// -----------------------------------------------------------------------
+	docs := make([]vectordb.Document, len(rawDocs))
+	ids := make([]string, len(rawDocs))
+	for i, raw := range rawDocs {
+		id := raw.ID
 		if id == "" {
-			id = uuid.NewString()
+			id = uuid.NewString() // auto-generate if caller did not provide one
 		}
 		ids[i] = id
 
-		doc := vectordb.Document{
-			ID:      id,
-			Content: text,
-			Payload: map[string]interface{}{
-				"text":   text,
-				"source": input.FileName,
-			},
+		payload := make(map[string]interface{}, len(raw.Metadata)+1)
+		for k, v := range raw.Metadata {
+			payload[k] = v
 		}
-		if i < len(allEmbeddings) {
-			doc.Vector = allEmbeddings[i]
+		// Always store the original text in the payload under the content field
+		// so ragQuery (and vectorSearch) can retrieve it.
+		payload[a.settings.ContentField] = raw.Text
+
+		docs[i] = vectordb.Document{
+			ID:      id,
+			Vector:  allEmbeddings[i],
+			Content: raw.Text,
+			Payload: payload,
 		}
-		docs[i] = doc
 	}
 
-	connSettings := a.conn.GetSettings()
-	opTimeout := connSettings.TimeoutSeconds
-	if opTimeout <= 0 {
-		opTimeout = 60
+	// -----------------------------------------------------------------------
+	// Step 3: Upsert into VectorDB in batches.
+	//
+	// The underlying validateUpsertDocuments enforces a hard cap per call
+	// (maxUpsertBatchSize = 5_000).  We split here using the same batch size
+	// that was used for embeddings so a single EmbeddingBatchSize setting
+	// controls both phases and callers never accidentally exceed the cap.
+	// -----------------------------------------------------------------------
+	for batchStart := 0; batchStart < len(docs); batchStart += batchSize {
+		batchEnd := batchStart + batchSize
+		if batchEnd > len(docs) {
+			batchEnd = len(docs)
+		}
+		if upsertErr := a.conn.GetClient().UpsertDocuments(opCtx, collectionName, docs[batchStart:batchEnd]); upsertErr != nil {
+			l.Errorf("IngestDocuments: upsert batch [%d-%d] failed: collection=%s error=%v",
+				batchStart, batchEnd, collectionName, upsertErr)
+			if tc != nil {
+				tc.SetTag("error", true)
+				tc.LogKV(map[string]interface{}{"event": "error", "message": upsertErr.Error()})
+			}
+			if err := ctx.SetOutputObject(&Output{
+				Success:  false,
+				Error:    fmt.Sprintf("upsert batch [%d-%d] failed: %v", batchStart, batchEnd, upsertErr),
+				Duration: time.Since(start).String(),
+			}); err != nil {
+				l.Errorf("SetOutputObject: %v", err)
+			}
+			return true, nil
+		}
 	}
 
-	batchSize := a.settings.BatchSize
-	if batchSize <= 0 {
-		batchSize = 100
+	// -----------------------------------------------------------------------
+	// Step 4 (incremental): delete stored chunks the sources no longer produce.
+	// Runs after the upsert so a failure never leaves a source without chunks.
+	// -----------------------------------------------------------------------
+	out := &Output{
+		Success:             true,
+		IngestedCount:       len(docs),
+		IDs:                 ids,
+		Dimensions:          embDimensions,
+		SourceDocumentCount: sourceDocCount,
+		ChunksCreated:       len(docs),
+		CacheHits:           cacheHits,
 	}
-
-	for offset := 0; offset < len(docs); offset += batchSize {
-		end := offset + batchSize
-		if end > len(docs) {
-			end = len(docs)
-		}
-		batch := docs[offset:end]
-
-		opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(opTimeout)*time.Second)
-		if err := a.conn.GetClient().UpsertDocuments(opCtx, collectionName, batch); err != nil {
-			cancel()
-			l.Errorf("IngestDocuments: upsert batch [%d-%d] error=%v", offset, end, err)
+	if plan != nil {
+		deleted, delErr := plan.deleteStale(opCtx, a.conn.GetClient(), collectionName)
+		if delErr != nil {
+			l.Errorf("IngestDocuments: stale chunk deletion failed: collection=%s error=%v", collectionName, delErr)
 			if tc != nil {
 				tc.SetTag("error", true)
+				tc.LogKV(map[string]interface{}{"event": "error", "message": delErr.Error()})
 			}
-			if err2 := ctx.SetOutputObject(&Output{
+			if err := ctx.SetOutputObject(&Output{
 				Success:  false,
-				Error:    fmt.Sprintf("upsert batch [%d-%d] failed: %v", offset, end, err),
+				Error:    fmt.Sprintf("stale chunk deletion failed after %d deletion(s): %v", deleted, delErr),
 				Duration: time.Since(start).String(),
-			}); err2 != nil {
-				l.Errorf("SetOutputObject: %v", err2)
+			}); err != nil {
+				l.Errorf("SetOutputObject: %v", err)
 			}
 			return true, nil
 		}
-		cancel()
-		l.Debugf("IngestDocuments: upserted batch [%d-%d]", offset, end)
+		out.IDs = plan.IDs
+		out.ChunksCreated = len(plan.IDs)
+		out.SkippedCount = plan.Skipped
+		out.DeletedCount = deleted
 	}
 
 	duration := time.Since(start)
-	l.Infof("IngestDocuments: collection=%s ingested=%d dims=%d duration=%s", collectionName, len(docs), embDimensions, duration)
+	out.Duration = duration.String()
+	l.Infof("IngestDocuments: success collection=%s ingested=%d skipped=%d deleted=%d dimensions=%d duration=%s",
+		collectionName, out.IngestedCount, out.SkippedCount, out.DeletedCount, embDimensions, duration)
 
-	if tc != nil {
-		tc.SetTag("db.vectordb.ingested_count", len(docs))
+	if err := ctx.SetOutputObject(out); err != nil {
+		l.Errorf("SetOutputObject: %v", err)
 	}
+	return true, nil
+}
 
-	idsInterface := make([]interface{}, len(ids))
-	for i, id := range ids {
-		idsInterface[i] = id
+// parseFiles converts a files[]interface{} input array into RawDocument slice by
+// extracting text from binary documents (PDF, DOCX, PPTX, XLSX, CSV, HTML, EML,
+// JSON, TXT, MD). The extracted sections are kept on the document so chunking
+// can attach page/slide/sheet metadata.
+// Each item must have "name" (string) and "content" (base64 string or []byte).
+func parseFiles(files []interface{}, l interface {
+	Debugf(string, ...interface{})
+	Infof(string, ...interface{})
+}) ([]RawDocument, error) {
+	if len(files) == 0 {
+		return nil, nil
 	}
+	docs := make([]RawDocument, 0, len(files))
+	for idx, item := range files {
+		m, ok := toStringMap(item)
+		if !ok {
+			return nil, fmt.Errorf("files[%d]: must be an object with 'name' and 'content' fields", idx)
+		}
 
-	if err := ctx.SetOutputObject(&Output{
-		Success:       true,
-		IngestedCount: len(docs),
-		IDs:           idsInterface,
-		Duration:      duration.String(),
-	}); err != nil {
-		l.Errorf("SetOutputObject: %v", err)
+		name, _ := m["name"].(string)
+		if name == "" {
+			name = fmt.Sprintf("file-%d.bin", idx)
+		}
+
+		contentRaw, hasContent := m["content"]
+		if !hasContent || contentRaw == nil {
+			return nil, fmt.Errorf("files[%d] (%s): 'content' field is required", idx, name)
+		}
+
+		data, err := fileContentToBytes(contentRaw)
+		if err != nil {
+			return nil, fmt.Errorf("files[%d] (%s): cannot decode content: %w", idx, name, err)
+		}
+
+		extracted, err := ExtractDocument(data, name)
+		if err != nil {
+			return nil, fmt.Errorf("files[%d] (%s): text extraction failed: %w", idx, name, err)
+		}
+		text := extracted.Text()
+		if text == "" {
+			return nil, fmt.Errorf("files[%d] (%s): no text could be extracted — is this a scanned/image PDF?", idx, name)
+		}
+
+		l.Debugf("parseFiles: extracted %d chars in %d section(s) from file=%s", len(text), len(extracted.Sections), name)
+
+		doc := RawDocument{
+			Text:     text,
+			Sections: extracted.Sections,
+			// Default metadata: source filename and type, then document-level
+			// fields from the extractor (title, e-mail headers); caller may
+			// override any of them via the "metadata" field.
+			Metadata: map[string]interface{}{
+				"source": name,
+				"type":   strings.TrimPrefix(filepath.Ext(name), "."),
+			},
+		}
+		for k, v := range extracted.Metadata {
+			doc.Metadata[k] = v
+		}
+		if id, ok := m["id"]; ok && id != nil {
+			doc.ID = fmt.Sprintf("%v", id)
+		}
+		if meta, ok := m["metadata"]; ok {
+			if mm, ok := meta.(map[string]interface{}); ok {
+				for k, v := range mm {
+					doc.Metadata[k] = v
+				}
+			}
+		}
+		docs = append(docs, doc)
 	}
-	return true, nil
+	return docs, nil
 }
 
-// parseFile dispatches to the appropriate text extractor based on file extension.
-func parseFile(ext string, data []byte) (string, error) {
-	switch ext {
-	case ".pdf":
-		return ExtractTextFromBytes(data, "pdf")
-	case ".docx":
-		return ExtractTextFromBytes(data, "docx")
-	case ".txt", ".md", ".csv", ".json", "":
-		return string(data), nil
-	default:
-		return string(data), nil
+// toStringMap converts an interface{} to map[string]interface{} if possible.
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
 	}
+	return nil, false
 }
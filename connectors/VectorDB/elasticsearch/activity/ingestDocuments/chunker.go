@@ -1,180 +1,408 @@
 package ingestDocuments
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
-	"unicode"
+
+	"github.com/google/uuid"
 )
 
-// ChunkStrategy constants
+// ChunkStrategy selects the text-splitting algorithm.
+type ChunkStrategy string
+
 const (
-	ChunkFixed     = "fixed"
-	ChunkSentence  = "sentence"
-	ChunkParagraph = "paragraph"
-	ChunkHeading   = "heading"
+	// ChunkStrategyFixed splits text into fixed-size character windows with
+	// configurable overlap between consecutive chunks (LangChain-style).
+	// Best for: uniform content where heading structure is absent.
+	ChunkStrategyFixed ChunkStrategy = "fixed"
+
+	// ChunkStrategySentence accumulates complete sentences until the chunk
+	// would exceed ChunkSize characters, then starts a new chunk.
+	// Best for: prose, support articles, log descriptions.
+	ChunkStrategySentence ChunkStrategy = "sentence"
+
+	// ChunkStrategyParagraph splits on one or more consecutive blank lines (\n\n).
+	// Best for: structured plain-text documents, README files.
+	ChunkStrategyParagraph ChunkStrategy = "paragraph"
+
+	// ChunkStrategyHeading splits on Markdown ATX headings (# through ######).
+	// Each heading and its content body becomes one chunk. The heading title is
+	// preserved as the first line of the chunk for context.
+	// Best for: Confluence pages exported as Markdown, wiki articles.
+	ChunkStrategyHeading ChunkStrategy = "heading"
 )
 
-// expandChunks applies the chosen strategy to each text and returns all chunks.
-func expandChunks(texts []string, strategy string, size, overlap int) []string {
-	if size <= 0 {
-		size = 512
+// Chunk linkage fields written on every chunk. Unlike the _ provenance keys
+// they are part of the payload contract: ragQuery reads them to fetch a
+// chunk's neighbours or its whole parent section (context expansion).
+const (
+	parentIDKey   = "parentId"   // ID shared by all chunks of one document
+	chunkIndexKey = "chunkIndex" // 0-based position of the chunk in the document
+	chunkCountKey = "chunkCount" // number of chunks the document produced
+)
+
+// ChunkConfig holds the resolved chunking parameters derived from Settings.
+type ChunkConfig struct {
+	Strategy ChunkStrategy
+	Size     int // target chunk size in characters; used by fixed and sentence
+	Overlap  int // character overlap between adjacent chunks; fixed only
+}
+
+// headingRe matches any Markdown ATX heading line (# through ######).
+var headingRe = regexp.MustCompile(`(?m)^#{1,6}\s`)
+
+// sentenceEndRe matches sentence-ending punctuation followed by whitespace.
+var sentenceEndRe = regexp.MustCompile(`[.!?]+[\s]+`)
+
+// validateChunkConfig returns an error if the config is self-inconsistent.
+func validateChunkConfig(cfg ChunkConfig) error {
+	switch cfg.Strategy {
+	case ChunkStrategyFixed, ChunkStrategySentence, ChunkStrategyParagraph, ChunkStrategyHeading:
+		// valid
+	default:
+		return fmt.Errorf("unknown chunk strategy %q: must be one of fixed, sentence, paragraph, heading", cfg.Strategy)
 	}
-	if overlap < 0 {
-		overlap = 0
+	if cfg.Strategy == ChunkStrategyFixed && cfg.Size <= 0 {
+		return fmt.Errorf("chunkSize must be > 0 when strategy is 'fixed'")
+	}
+	if cfg.Overlap < 0 {
+		return fmt.Errorf("chunkOverlap must be >= 0")
 	}
-	if strategy == "" {
-		strategy = ChunkFixed
+	if cfg.Strategy == ChunkStrategyFixed && cfg.Overlap >= cfg.Size {
+		return fmt.Errorf("chunkOverlap (%d) must be less than chunkSize (%d)", cfg.Overlap, cfg.Size)
 	}
+	return nil
+}
+
+// maxEmbeddingInputChars is a hard upper-bound applied to every chunk after
+// the primary splitting strategy runs. Any chunk that exceeds this limit is
+// further split using the fixed strategy with no overlap.
+// 8 000 characters ≈ 2 000 tokens for typical English text, which is safely
+// below the 8 192-token context window of models such as nomic-embed-text.
+const maxEmbeddingInputChars = 3500
+
+// expandChunks takes the parsed input documents and, for each document, splits
+// its Text field according to cfg. The returned slice replaces the input slice:
+// each chunk becomes an independent RawDocument inheriting the parent's metadata
+// plus provenance keys (_source_id, _chunk_index, _chunk_total, _chunk_strategy)
+// and the linkage keys parentId, chunkIndex and chunkCount. parentId is the
+// document id, else its incremental-mode source, else a generated UUID.
+//
+// Documents extracted from files carry Sections (pages, slides, sheets…). Each
+// section is chunked on its own, so a chunk never spans two pages, and the
+// section's location metadata (page, slide, sheet…) is copied onto its chunks.
+// Every chunk of a document that contains Markdown headings also gets a
+// section_path key — the heading trail in effect at the chunk, e.g.
+// "Installation > Prerequisites".
+//
+// When EnableChunking is false this function is never called; callers pass
+// through rawDocs unchanged.
+func expandChunks(docs []RawDocument, cfg ChunkConfig) []RawDocument {
+	var result []RawDocument
+	for _, doc := range docs {
+		sections := doc.Sections
+		if len(sections) == 0 {
+			sections = []DocumentSection{{Text: doc.Text}}
+		}
 
-	var all []string
-	for _, t := range texts {
-		t = strings.TrimSpace(t)
-		if t == "" {
+		type sectionChunk struct {
+			text string
+			meta map[string]interface{}
+		}
+		var chunks []sectionChunk
+		for _, sec := range sections {
+			// Safety cap: sub-split any chunk that exceeds the embedding model's
+			// effective context length. This guards against strategies like
+			// paragraph/heading producing oversized segments (e.g. tables with no
+			// blank-line breaks, large PDF sections, binary-fallback content).
+			for _, c := range chunkText(sec.Text, cfg) {
+				if len([]rune(c)) > maxEmbeddingInputChars {
+					for _, sub := range chunkFixed(c, maxEmbeddingInputChars, 0) {
+						chunks = append(chunks, sectionChunk{sub, sec.Metadata})
+					}
+				} else {
+					chunks = append(chunks, sectionChunk{c, sec.Metadata})
+				}
+			}
+		}
+
+		var trail headingTrail
+		total := len(chunks)
+		parentID := doc.ID
+		if parentID == "" {
+			parentID, _ = doc.Metadata[ingestSourceKey].(string)
+		}
+		if parentID == "" {
+			parentID = uuid.NewString()
+		}
+		for i, chunk := range chunks {
+			// Build chunk ID: "<parent-id>-chunk-<i>" or leave blank for UUID assignment.
+			chunkID := ""
+			if doc.ID != "" {
+				chunkID = fmt.Sprintf("%s-chunk-%d", doc.ID, i)
+			}
+
+			// Deep-copy parent metadata so each chunk has an independent map.
+			meta := make(map[string]interface{}, len(doc.Metadata)+len(chunk.meta)+8)
+			for k, v := range doc.Metadata {
+				meta[k] = v
+			}
+			for k, v := range chunk.meta {
+				meta[k] = v
+			}
+			if path := trail.advance(chunk.text); path != "" {
+				meta["section_path"] = path
+			}
+			// Provenance fields — written under reserved _ prefix to avoid clashes.
+			meta["_source_id"] = doc.ID
+			meta["_chunk_index"] = i
+			meta["_chunk_total"] = total
+			meta["_chunk_strategy"] = string(cfg.Strategy)
+			meta[parentIDKey] = parentID
+			meta[chunkIndexKey] = i
+			meta[chunkCountKey] = total
+
+			result = append(result, RawDocument{
+				ID:       chunkID,
+				Text:     chunk.text,
+				Metadata: meta,
+			})
+		}
+	}
+	return result
+}
+
+// headingLineRe captures the level marker and title of a Markdown ATX heading.
+var headingLineRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+// headingTrail tracks the open Markdown headings while a document's chunks are
+// visited in order.
+type headingTrail struct {
+	titles [6]string
+}
+
+// advance returns the section path for a chunk and then applies the chunk's
+// headings for the chunks that follow. Headings at the very start of the
+// chunk (before any body text) are part of its own path, so a chunk produced
+// by the heading strategy is labelled with the heading it begins with.
+func (t *headingTrail) advance(chunk string) string {
+	var path string
+	leading := true
+	for _, line := range strings.Split(chunk, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
 			continue
 		}
-		switch strategy {
-		case ChunkSentence:
-			all = append(all, chunkSentence(t, size, overlap)...)
-		case ChunkParagraph:
-			all = append(all, chunkParagraph(t, size, overlap)...)
-		case ChunkHeading:
-			all = append(all, chunkHeading(t, size, overlap)...)
-		default:
-			all = append(all, chunkFixed(t, size, overlap)...)
+		m := headingLineRe.FindStringSubmatch(line)
+		if m == nil {
+			if leading {
+				path, leading = t.path(), false
+			}
+			continue
 		}
+		level := len(m[1])
+		t.titles[level-1] = m[2]
+		for j := level; j < len(t.titles); j++ {
+			t.titles[j] = ""
+		}
+	}
+	if leading {
+		path = t.path()
 	}
-	return all
+	return path
 }
 
-// chunkFixed splits text into fixed-size windows (by rune count) with overlap.
-func chunkFixed(text string, size, overlap int) []string {
-	runes := []rune(text)
-	if len(runes) <= size {
+func (t *headingTrail) path() string {
+	var parts []string
+	for _, title := range t.titles {
+		if title != "" {
+			parts = append(parts, title)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+// chunkText dispatches to the appropriate splitting implementation.
+// Returns at least one element (the original text) even when no split occurs.
+func chunkText(text string, cfg ChunkConfig) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []string{""}
+	}
+	switch cfg.Strategy {
+	case ChunkStrategyFixed:
+		return chunkFixed(text, cfg.Size, cfg.Overlap)
+	case ChunkStrategySentence:
+		return chunkSentence(text, cfg.Size)
+	case ChunkStrategyParagraph:
+		return chunkParagraph(text)
+	case ChunkStrategyHeading:
+		return chunkHeading(text)
+	default:
 		return []string{text}
 	}
+}
+
+// ── Strategy implementations ─────────────────────────────────────────────────
+
+// chunkFixed splits text into windows of `size` runes, advancing by
+// (size - overlap) runes each step. Overlap prevents context loss at
+// boundaries (e.g. a sentence split across two chunks).
+func chunkFixed(text string, size, overlap int) []string {
+	if size <= 0 {
+		size = 1000
+	}
+	if overlap < 0 {
+		overlap = 0
+	}
+	// Safety: clamp overlap so step is always positive.
+	if overlap >= size {
+		overlap = size / 2
+	}
 	step := size - overlap
-	if step <= 0 {
-		step = 1
+	runes := []rune(text)
+	total := len(runes)
+	if total <= size {
+		return []string{text}
 	}
+
 	var chunks []string
-	for i := 0; i < len(runes); i += step {
-		end := i + size
-		if end > len(runes) {
-			end = len(runes)
+	for start := 0; start < total; start += step {
+		end := start + size
+		if end > total {
+			end = total
 		}
-		chunks = append(chunks, string(runes[i:end]))
-		if end == len(runes) {
+		chunk := strings.TrimSpace(string(runes[start:end]))
+		if chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end == total {
 			break
 		}
 	}
+	if len(chunks) == 0 {
+		return []string{text}
+	}
 	return chunks
 }
 
-// chunkSentence splits by sentence boundaries then re-aggregates into windows.
-func chunkSentence(text string, size, overlap int) []string {
-	sentences := splitSentences(text)
-	return aggregateChunks(sentences, size, overlap)
-}
+// chunkSentence accumulates complete sentences until appending the next would
+// exceed `size` characters, at which point a new chunk starts. Sentence
+// boundaries are detected by [.!?] followed by whitespace.
+func chunkSentence(text string, size int) []string {
+	if size <= 0 {
+		size = 1000
+	}
 
-// chunkParagraph splits by blank lines then re-aggregates.
-func chunkParagraph(text string, size, overlap int) []string {
-	parts := strings.Split(text, "\n\n")
-	var paras []string
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			paras = append(paras, p)
+	// Find sentence boundary positions while keeping the punctuation attached.
+	locs := sentenceEndRe.FindAllStringIndex(text, -1)
+	var sentences []string
+	prev := 0
+	for _, loc := range locs {
+		end := loc[1]
+		s := strings.TrimSpace(text[prev:end])
+		if s != "" {
+			sentences = append(sentences, s)
 		}
+		prev = end
 	}
-	return aggregateChunks(paras, size, overlap)
-}
-
-// chunkHeading splits at Markdown headings (# lines).
-func chunkHeading(text string, size, overlap int) []string {
-	lines := strings.Split(text, "\n")
-	var sections []string
-	var cur strings.Builder
-	for _, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), "#") && cur.Len() > 0 {
-			sections = append(sections, strings.TrimSpace(cur.String()))
-			cur.Reset()
+	// Trailing text after the last sentence boundary (e.g. no trailing period).
+	if prev < len(text) {
+		tail := strings.TrimSpace(text[prev:])
+		if tail != "" {
+			sentences = append(sentences, tail)
 		}
-		cur.WriteString(line)
-		cur.WriteString("\n")
 	}
-	if cur.Len() > 0 {
-		sections = append(sections, strings.TrimSpace(cur.String()))
+	if len(sentences) == 0 {
+		return []string{text}
 	}
-	return aggregateChunks(sections, size, overlap)
-}
 
-// aggregateChunks joins small pieces into windows of at most `size` runes.
-func aggregateChunks(pieces []string, size, overlap int) []string {
-	if len(pieces) == 0 {
-		return nil
-	}
 	var chunks []string
 	var buf strings.Builder
-	for _, p := range pieces {
-		candidate := buf.String()
-		if candidate != "" {
-			candidate += " " + p
-		} else {
-			candidate = p
-		}
-		if len([]rune(candidate)) > size && buf.Len() > 0 {
-			chunks = append(chunks, strings.TrimSpace(buf.String()))
-			// overlap: keep last `overlap` runes from the flushed chunk
-			if overlap > 0 {
-				prev := []rune(buf.String())
-				start := len(prev) - overlap
-				if start < 0 {
-					start = 0
-				}
-				buf.Reset()
-				buf.WriteString(string(prev[start:]))
-				buf.WriteString(" ")
-				buf.WriteString(p)
-			} else {
-				buf.Reset()
-				buf.WriteString(p)
+	for _, s := range sentences {
+		// If adding this sentence would overflow the target size, flush first.
+		if buf.Len() > 0 && buf.Len()+1+len(s) > size {
+			chunk := strings.TrimSpace(buf.String())
+			if chunk != "" {
+				chunks = append(chunks, chunk)
 			}
-		} else {
 			buf.Reset()
-			buf.WriteString(candidate)
 		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(s)
 	}
 	if buf.Len() > 0 {
-		chunks = append(chunks, strings.TrimSpace(buf.String()))
+		chunk := strings.TrimSpace(buf.String())
+		if chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+	}
+	if len(chunks) == 0 {
+		return []string{text}
 	}
 	return chunks
 }
 
-// splitSentences is a simple heuristic sentence splitter.
-func splitSentences(text string) []string {
-	var sentences []string
-	var cur strings.Builder
-	runes := []rune(text)
-	for i, r := range runes {
-		cur.WriteRune(r)
-		if r == '.' || r == '!' || r == '?' {
-			// Check next char is space/upper
-			if i+1 < len(runes) {
-				next := runes[i+1]
-				if next == ' ' || next == '\n' || unicode.IsUpper(next) {
-					s := strings.TrimSpace(cur.String())
-					if s != "" {
-						sentences = append(sentences, s)
-					}
-					cur.Reset()
-				}
-			}
+// chunkParagraph splits on one or more consecutive blank lines. This maps
+// naturally to Confluence pages exported as plain text or Markdown where
+// logical sections are separated by blank lines.
+func chunkParagraph(text string) []string {
+	// Normalise Windows line endings before splitting.
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	parts := strings.Split(text, "\n\n")
+	var chunks []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			chunks = append(chunks, p)
 		}
 	}
-	if cur.Len() > 0 {
-		s := strings.TrimSpace(cur.String())
-		if s != "" {
-			sentences = append(sentences, s)
+	if len(chunks) == 0 {
+		return []string{text}
+	}
+	return chunks
+}
+
+// chunkHeading splits on Markdown ATX heading lines (# through ######). Each
+// heading and all text until the next heading forms one chunk. The heading
+// title is included as the first line of the chunk so retrieval preserves the
+// section label — critical for Confluence-sourced runbooks and wikis.
+//
+// Text that appears before the first heading (e.g. page preamble) is returned
+// as an implicit leading chunk if non-empty.
+func chunkHeading(text string) []string {
+	// Normalise Windows line endings.
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	var chunks []string
+	var buf strings.Builder
+
+	flush := func() {
+		chunk := strings.TrimSpace(buf.String())
+		if chunk != "" {
+			chunks = append(chunks, chunk)
 		}
+		buf.Reset()
 	}
-	return sentences
+
+	for _, line := range lines {
+		if headingRe.MatchString(line) {
+			// Flush whatever was accumulated before this new heading.
+			flush()
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(line)
+	}
+	flush()
+
+	if len(chunks) == 0 {
+		return []string{text}
+	}
+	return chunks
 }
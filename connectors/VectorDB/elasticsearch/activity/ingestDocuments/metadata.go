@@ -1,29 +1,105 @@
 package ingestDocuments
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/project-flogo/core/support/connection"
 )
 
+// Settings holds activity-level configuration set once at flow design time.
 type Settings struct {
-	Connection        connection.Manager `md:"connection,required"`
-	DefaultCollection string             `md:"defaultCollection"`
-	ChunkStrategy     string             `md:"chunkStrategy"`
-	ChunkSize         int                `md:"chunkSize"`
-	ChunkOverlap      int                `md:"chunkOverlap"`
-	EmbeddingProvider string             `md:"embeddingProvider"`
-	EmbeddingAPIKey   string             `md:"embeddingAPIKey"`
-	EmbeddingBaseURL  string             `md:"embeddingBaseURL"`
-	EmbeddingModel    string             `md:"embeddingModel"`
-	BatchSize         int                `md:"batchSize"`
+	// Connection is the VectorDB connector reference.
+	Connection connection.Manager `md:"connection,required"`
+
+	// UseConnectorEmbedding instructs the activity to inherit the embedding
+	// provider, API key, and base URL from the VectorDB connector settings.
+	// When true, only embeddingModel and embeddingDimensions need to be set
+	// here. Set to false to supply per-activity embedding credentials instead.
+	UseConnectorEmbedding bool `md:"useConnectorEmbedding"`
+
+	// Embedding provider settings — same as createEmbeddings activity.
+	// Leave blank when UseConnectorEmbedding=true (inherited from connector).
+	EmbeddingProvider   string `md:"embeddingProvider"`
+	EmbeddingAPIKey     string `md:"embeddingAPIKey"`
+	EmbeddingBaseURL    string `md:"embeddingBaseURL"`
+	EmbeddingModel      string `md:"embeddingModel"`
+	EmbeddingDimensions int    `md:"embeddingDimensions"`
+
+	// DefaultCollection is used when the input does not supply one.
+	DefaultCollection string `md:"defaultCollection"`
+
+	// ContentField is the key inside each document's payload that holds the
+	// text to embed. Defaults to "text" if empty.
+	ContentField string `md:"contentField"`
+
+	// TimeoutSeconds caps the total operation (embed + upsert). Default 60.
+	TimeoutSeconds int `md:"timeoutSeconds"`
+
+	// EmbeddingBatchSize controls how many document texts are sent to the
+	// embedding API in a single request. Default 100. Tune down for providers
+	// with small payload or rate limits (e.g. 20 for free-tier OpenAI).
+	EmbeddingBatchSize int `md:"embeddingBatchSize"`
+
+	// ── Chunking ─────────────────────────────────────────────────────────────
+	// EnableChunking, when true, splits each input document's text into smaller
+	// segments before embedding. Removes the need for an upstream splitting step.
+	// Sub-fields below are only used when EnableChunking=true.
+	EnableChunking bool `md:"enableChunking"`
+
+	// ChunkStrategy selects the splitting algorithm.
+	// Allowed values: "fixed", "sentence", "paragraph", "heading".
+	// Default: "paragraph".
+	ChunkStrategy string `md:"chunkStrategy"`
+
+	// ChunkSize is the target chunk length in characters.
+	// Used by "fixed" (hard window) and "sentence" (soft accumulator).
+	// Ignored by "paragraph" and "heading". Default: 1000.
+	ChunkSize int `md:"chunkSize"`
+
+	// ChunkOverlap is the number of characters shared between consecutive
+	// chunks. Only meaningful for "fixed" strategy. Default: 200.
+	ChunkOverlap int `md:"chunkOverlap"`
+
+	// ── Embedding cache ──────────────────────────────────────────────────────
+	// EmbeddingCache keeps vectors keyed by a content hash of model, dimensions
+	// and text so unchanged texts are not re-embedded.
+	// Allowed values: "none", "memory" (in-process LRU), "disk". Default: "none".
+	EmbeddingCache string `md:"embeddingCache"`
+
+	// EmbeddingCacheSize is the capacity of the "memory" cache in vectors.
+	// Default: 10000.
+	EmbeddingCacheSize int `md:"embeddingCacheSize"`
+
+	// EmbeddingCacheDir is the directory of the "disk" cache. Required for "disk";
+	// it may be shared by several engines.
+	EmbeddingCacheDir string `md:"embeddingCacheDir"`
+
+	// ── Ingest mode ──────────────────────────────────────────────────────────
+	// IngestMode is "full" (default) or "incremental". In incremental mode chunk
+	// IDs are derived from the document source and the chunk's text hash,
+	// chunks already stored unchanged are skipped, and stored chunks of a
+	// re-ingested source that are no longer produced are deleted. Each run must
+	// therefore carry the complete content of every source it contains.
+	IngestMode string `md:"ingestMode"`
 }
 
+// Input holds the runtime inputs for an ingest operation.
 type Input struct {
-	CollectionName string        `md:"collectionName"`
-	Documents      []interface{} `md:"documents"`
-	FileName       string        `md:"fileName"`
-	FileContent    string        `md:"fileContent"`
+	// CollectionName overrides Settings.DefaultCollection at runtime.
+	CollectionName string `md:"collectionName"`
+
+	// Documents is an array of objects, each with at minimum a text field
+	// (default key: "text"). Optional fields: "id", "metadata" (object).
+	// Example: [{"id":"doc1","text":"hello world","metadata":{"source":"web"}}]
+	Documents []interface{} `md:"documents"`
+
+	// FileName and FileContent are the multipart REST upload inputs. Map:
+	//   fileName    = =$flow.multipartFormData.filename
+	//   fileContent = =$flow.multipartFormData.file[0]
+	// The activity synthesises a file entry from them internally.
+	FileName    string      `md:"fileName"`
+	FileContent interface{} `md:"fileContent"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
@@ -39,41 +115,154 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 	if val, ok := v["collectionName"]; ok {
 		i.CollectionName = fmt.Sprintf("%v", val)
 	}
-	if val, ok := v["documents"]; ok {
+	if val, ok := v["documents"]; ok && val != nil {
 		if arr, ok := val.([]interface{}); ok {
 			i.Documents = arr
+		} else {
+			return fmt.Errorf("vectordb-ingest: 'documents' must be an array")
 		}
 	}
-	if val, ok := v["fileName"]; ok {
+	if val, ok := v["fileName"]; ok && val != nil {
 		i.FileName = fmt.Sprintf("%v", val)
 	}
-	if val, ok := v["fileContent"]; ok {
-		i.FileContent = fmt.Sprintf("%v", val)
+	if val, ok := v["fileContent"]; ok && val != nil {
+		i.FileContent = val
 	}
 	return nil
 }
 
+// RawDocument is the parsed representation of one input item.
+type RawDocument struct {
+	ID       string
+	Text     string
+	Metadata map[string]interface{}
+	// Sections is the structure of a document extracted from a file (pages,
+	// slides, sheets…). Text is the sections joined; expandChunks chunks each
+	// section separately and copies its location metadata onto the chunks.
+	// Nil for documents supplied as text.
+	Sections []DocumentSection
+}
+
+// parseDocuments converts []interface{} input into typed RawDocument slice.
+// Returns an empty slice (not an error) when raw is nil or empty — callers
+// must ensure at least one source (documents or files) is provided.
+func parseDocuments(raw []interface{}, contentField string) ([]RawDocument, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if contentField == "" {
+		contentField = "text"
+	}
+	docs := make([]RawDocument, 0, len(raw))
+	for idx, item := range raw {
+		var m map[string]interface{}
+		switch v := item.(type) {
+		case map[string]interface{}:
+			m = v
+		default:
+			b, err := json.Marshal(item)
+			if err != nil {
+				return nil, fmt.Errorf("document[%d]: cannot marshal: %w", idx, err)
+			}
+			if err = json.Unmarshal(b, &m); err != nil {
+				return nil, fmt.Errorf("document[%d]: cannot unmarshal: %w", idx, err)
+			}
+		}
+
+		text, _ := m[contentField].(string)
+		if text == "" {
+			return nil, fmt.Errorf("document[%d]: field %q is required and must be a non-empty string", idx, contentField)
+		}
+
+		doc := RawDocument{
+			Text:     text,
+			Metadata: make(map[string]interface{}),
+		}
+		if id, ok := m["id"]; ok {
+			doc.ID = fmt.Sprintf("%v", id)
+		}
+		if meta, ok := m["metadata"]; ok {
+			if mm, ok := meta.(map[string]interface{}); ok {
+				doc.Metadata = mm
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// Output holds the results returned by the activity.
 type Output struct {
-	Success       bool          `md:"success"`
-	IngestedCount int           `md:"ingestedCount"`
-	IDs           []interface{} `md:"ids"`
-	Duration      string        `md:"duration"`
-	Error         string        `md:"error"`
+	Success       bool     `md:"success"`
+	IngestedCount int      `md:"ingestedCount"`
+	IDs           []string `md:"ids"`
+	Dimensions    int      `md:"dimensions"`
+	Duration      string   `md:"duration"`
+	Error         string   `md:"error"`
+	// SourceDocumentCount is the number of input documents before chunking.
+	// Equal to IngestedCount when chunking is disabled.
+	SourceDocumentCount int `md:"sourceDocumentCount"`
+	// ChunksCreated is the total number of chunks stored in VectorDB.
+	// Equal to IngestedCount when chunking is disabled.
+	ChunksCreated int `md:"chunksCreated"`
+	// SkippedCount is the number of chunks already stored unchanged and not
+	// re-embedded (incremental mode only).
+	SkippedCount int `md:"skippedCount"`
+	// DeletedCount is the number of stale chunks deleted (incremental mode only).
+	DeletedCount int `md:"deletedCount"`
+	// CacheHits is the number of embeddings served from the embedding cache.
+	CacheHits int `md:"cacheHits"`
 }
 
 func (o *Output) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"success":       o.Success,
-		"ingestedCount": o.IngestedCount,
-		"ids":           o.IDs,
-		"duration":      o.Duration,
-		"error":         o.Error,
+		"success":             o.Success,
+		"ingestedCount":       o.IngestedCount,
+		"ids":                 o.IDs,
+		"dimensions":          o.Dimensions,
+		"duration":            o.Duration,
+		"error":               o.Error,
+		"sourceDocumentCount": o.SourceDocumentCount,
+		"chunksCreated":       o.ChunksCreated,
+		"skippedCount":        o.SkippedCount,
+		"deletedCount":        o.DeletedCount,
+		"cacheHits":           o.CacheHits,
 	}
 }
 
 func (o *Output) FromMap(v map[string]interface{}) error {
-	if val, ok := v["success"]; ok {
-		o.Success, _ = val.(bool)
+	if val, ok := v["success"].(bool); ok {
+		o.Success = val
+	}
+	if val, ok := v["ingestedCount"].(int); ok {
+		o.IngestedCount = val
+	}
+	if val, ok := v["ids"].([]string); ok {
+		o.IDs = val
+	}
+	if val, ok := v["dimensions"].(int); ok {
+		o.Dimensions = val
+	}
+	if val, ok := v["duration"].(string); ok {
+		o.Duration = val
+	}
+	if val, ok := v["error"].(string); ok {
+		o.Error = val
+	}
+	if val, ok := v["sourceDocumentCount"].(int); ok {
+		o.SourceDocumentCount = val
+	}
+	if val, ok := v["chunksCreated"].(int); ok {
+		o.ChunksCreated = val
+	}
+	if val, ok := v["skippedCount"].(int); ok {
+		o.SkippedCount = val
+	}
+	if val, ok := v["deletedCount"].(int); ok {
+		o.DeletedCount = val
+	}
+	if val, ok := v["cacheHits"].(int); ok {
+		o.CacheHits = val
 	}
 	return nil
 }
@@ -1,25 +1,25 @@
 package ragQuery
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"strings"
 	"time"
 
-	vectordb "github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch"
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch"
 	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch/connector"
 	vdbembed "github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch/embeddings"
+	vdbsemcache "github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch/semcache"
 	"github.com/project-flogo/core/activity"
 	"github.com/project-flogo/core/data/metadata"
 )
 
 // ragLLMHTTPClient is a package-level HTTP client with explicit transport
-// timeouts for LLM generation calls.
+// timeouts for LLM generation calls. http.DefaultClient has no dial or
+// TLS-handshake timeout, which risks goroutine leaks on slow LLM endpoints.
 var ragLLMHTTPClient = &http.Client{
 	Transport: &http.Transport{
 		DialContext: (&net.Dialer{
@@ -27,7 +27,7 @@ var ragLLMHTTPClient = &http.Client{
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
 		TLSHandshakeTimeout:   10 * time.Second,
-		ResponseHeaderTimeout: 120 * time.Second,
+		ResponseHeaderTimeout: 120 * time.Second, // LLM inference can be slow
 		MaxIdleConns:          5,
 		IdleConnTimeout:       90 * time.Second,
 	},
@@ -57,26 +57,109 @@ func New(ctx activity.InitContext) (activity.Activity, error) {
 		return nil, fmt.Errorf("vectordb-rag: invalid connection type, expected *ElasticsearchConnection")
 	}
 
-	// Inherit embedding settings from connector when not set.
-	cs := conn.GetSettings()
-	if cs.EnableEmbedding {
-		if s.EmbeddingProvider == "" {
-			s.EmbeddingProvider = cs.EmbeddingProvider
-		}
-		if s.EmbeddingAPIKey == "" {
-			s.EmbeddingAPIKey = cs.EmbeddingAPIKey
-		}
-		if s.EmbeddingBaseURL == "" {
-			s.EmbeddingBaseURL = cs.EmbeddingBaseURL
+	// Resolve embedding credentials: inherit from connector when opted in.
+	// Activity-level values (if set) always take precedence as an override.
+	if s.UseConnectorEmbedding {
+		connSettings := conn.GetSettings()
+		if !connSettings.EnableEmbedding {
+			ctx.Logger().Warnf("RAGQuery: useConnectorEmbedding=true but connector does not have enableEmbedding set — falling back to activity-level settings")
+		} else {
+			if s.EmbeddingProvider == "" {
+				s.EmbeddingProvider = connSettings.EmbeddingProvider
+			}
+			if s.EmbeddingAPIKey == "" {
+				s.EmbeddingAPIKey = connSettings.EmbeddingAPIKey
+			}
+			if s.EmbeddingBaseURL == "" {
+				s.EmbeddingBaseURL = connSettings.EmbeddingBaseURL
+			}
 		}
 	}
 
+	if s.EmbeddingProvider == "" {
+		s.EmbeddingProvider = string(vdbembed.ProviderOpenAI)
+	}
+	if s.EmbeddingModel == "" {
+		return nil, fmt.Errorf("vectordb-rag: embeddingModel is required")
+	}
 	if s.DefaultTopK <= 0 {
 		s.DefaultTopK = 5
 	}
-
-	ctx.Logger().Infof("RAGQuery initialised: connection=%s embeddingProvider=%s embeddingModel=%s defaultTopK=%d",
-		conn.GetName(), s.EmbeddingProvider, s.EmbeddingModel, s.DefaultTopK)
+	if s.ContentField == "" {
+		s.ContentField = "text"
+	}
+	if s.ContextFormat == "" {
+		s.ContextFormat = "numbered"
+	}
+	if s.TimeoutSeconds <= 0 {
+		s.TimeoutSeconds = 30
+	}
+	// LLM generation defaults (only applied when EnableLLMGenerate=true at runtime)
+	if s.LLMProvider == "" {
+		s.LLMProvider = "Ollama"
+	}
+	if s.LLMModel == "" {
+		s.LLMModel = "llama3.1:8b"
+	}
+	if s.LLMBaseURL == "" {
+		s.LLMBaseURL = defaultLLMBaseURLs[s.LLMProvider]
+	}
+	if s.MaxTokens <= 0 {
+		s.MaxTokens = 1024
+	}
+	if s.SystemPrompt == "" {
+		s.SystemPrompt = "You are a helpful assistant. Answer the question using only the provided context. If the context does not contain enough information, say so."
+	}
+	switch s.RetrievalMode {
+	case "":
+		s.RetrievalMode = retrievalStandard
+	case retrievalStandard, retrievalMultiQuery, retrievalHyDE:
+	default:
+		return nil, fmt.Errorf("vectordb-rag: retrievalMode must be one of standard, multiQuery, hyde, got %q", s.RetrievalMode)
+	}
+	if s.QueryVariants <= 0 {
+		s.QueryVariants = defaultQueryVariants
+	}
+	if s.QueryVariants > maxQueryVariants {
+		return nil, fmt.Errorf("vectordb-rag: queryVariants must be at most %d, got %d", maxQueryVariants, s.QueryVariants)
+	}
+	if s.EnableMMR && (s.MMRLambda < 0 || s.MMRLambda > 1) {
+		return nil, fmt.Errorf("vectordb-rag: mmrLambda must be between 0.0 and 1.0, got %.4f", s.MMRLambda)
+	}
+	switch s.ContextExpansion {
+	case "":
+		s.ContextExpansion = expansionNone
+	case expansionNone, expansionNeighbors, expansionParent:
+	default:
+		return nil, fmt.Errorf("vectordb-rag: contextExpansion must be one of none, neighbors, parent, got %q", s.ContextExpansion)
+	}
+	if s.NeighborChunks <= 0 {
+		s.NeighborChunks = defaultNeighborChunks
+	}
+	if s.ContextTokenBudget < 0 {
+		return nil, fmt.Errorf("vectordb-rag: contextTokenBudget must be >= 0, got %d", s.ContextTokenBudget)
+	}
+	if s.CacheCollection == "" {
+		s.CacheCollection = vdbsemcache.DefaultCollection
+	}
+	if s.CacheSimilarityThreshold == 0 {
+		s.CacheSimilarityThreshold = vdbsemcache.DefaultThreshold
+	}
+	if s.EnableSemanticCache && (s.CacheSimilarityThreshold < 0 || s.CacheSimilarityThreshold > 1) {
+		return nil, fmt.Errorf("vectordb-rag: cacheSimilarityThreshold must be between 0.0 and 1.0, got %.4f", s.CacheSimilarityThreshold)
+	}
+	if s.CacheTTLSeconds < 0 {
+		return nil, fmt.Errorf("vectordb-rag: cacheTTLSeconds must be >= 0, got %d", s.CacheTTLSeconds)
+	}
+	usesLLM := s.EnableLLMGenerate || s.EnableQueryRewrite || s.RetrievalMode != retrievalStandard
+	if usesLLM && s.LLMProvider == "Azure OpenAI" && s.LLMBaseURL == "" {
+		return nil, fmt.Errorf("vectordb-rag: llmBaseURL is required for Azure OpenAI")
+	}
+	if s.SSEServerRef == "" {
+		s.SSEServerRef = "default"
+	}
+	ctx.Logger().Infof("RAGQuery initialised: connection=%s provider=%s embeddingModel=%s defaultTopK=%d retrievalMode=%s mmr=%v contextExpansion=%s queryRewrite=%v llmGenerate=%v llmProvider=%s streaming=%v citations=%v semanticCache=%v",
+		conn.GetName(), s.EmbeddingProvider, s.EmbeddingModel, s.DefaultTopK, s.RetrievalMode, s.EnableMMR, s.ContextExpansion, s.EnableQueryRewrite, s.EnableLLMGenerate, s.LLMProvider, s.EnableStreaming, s.EnableCitations, s.EnableSemanticCache && s.EnableLLMGenerate)
 	return &Activity{settings: s, conn: conn}, nil
 }
 
@@ -88,6 +171,9 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	if err := ctx.GetInputObject(input); err != nil {
 		return false, fmt.Errorf("vectordb-rag: %w", err)
 	}
+	if input.QueryText == "" {
+		return false, fmt.Errorf("vectordb-rag: queryText is required")
+	}
 
 	collectionName := input.CollectionName
 	if collectionName == "" {
@@ -96,216 +182,416 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	if collectionName == "" {
 		return false, fmt.Errorf("vectordb-rag: collectionName is required")
 	}
-	if input.Query == "" {
-		return false, fmt.Errorf("vectordb-rag: query is required")
-	}
 
 	topK := input.TopK
 	if topK <= 0 {
 		topK = a.settings.DefaultTopK
 	}
-	if topK <= 0 {
-		topK = 5
-	}
 
+	l.Debugf("RAGQuery: query=%q collection=%s topK=%d", input.QueryText, collectionName, topK)
+
+	// OTel trace tags
 	tc := ctx.GetTracingContext()
 	if tc != nil {
+		tc.SetTag("ai.operation", "ragQuery")
+		tc.SetTag("ai.embedding_provider", a.settings.EmbeddingProvider)
+		tc.SetTag("ai.embedding_model", a.settings.EmbeddingModel)
 		tc.SetTag("db.system", "vectordb")
-		tc.SetTag("db.operation", "ragQuery")
 		tc.SetTag("db.vectordb.provider", "elasticsearch")
 		tc.SetTag("db.vectordb.collection", collectionName)
 		tc.SetTag("db.vectordb.top_k", topK)
+		tc.SetTag("ai.retrieval_mode", a.settings.RetrievalMode)
 	}
 
+	opCtx, cancel := context.WithTimeout(ctx.GoContext(), time.Duration(a.settings.TimeoutSeconds)*time.Second)
+	defer cancel()
+
 	start := time.Now()
 
-	// Step 1: Embed the query
-	var queryVector []float64
-	if a.settings.EmbeddingProvider != "" && a.settings.EmbeddingModel != "" {
-		connSettings := a.conn.GetSettings()
-		embedTimeout := connSettings.TimeoutSeconds
-		if embedTimeout <= 0 {
-			embedTimeout = 30
+	// Step 0 (optional): query rewriting and multi-query / HyDE expansion
+	plan := a.planQueries(opCtx, l, input)
+
+	// Step 1: Embed the query text (one text per search; the HyDE passage in hyde mode)
+	l.Debugf("RAGQuery: embedding %d text(s) with provider=%s model=%s", len(plan.EmbedTexts), a.settings.EmbeddingProvider, a.settings.EmbeddingModel)
+	embResult, embErr := vdbembed.CreateEmbeddings(opCtx, vdbembed.EmbeddingRequest{
+		Provider:   vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
+		APIKey:     a.settings.EmbeddingAPIKey,
+		BaseURL:    a.settings.EmbeddingBaseURL,
+		Model:      a.settings.EmbeddingModel,
+		Texts:      plan.EmbedTexts,
+		Dimensions: a.settings.EmbeddingDimensions,
+	})
+	if embErr != nil {
+		l.Errorf("RAGQuery: embedding failed: %v", embErr)
+		if tc != nil {
+			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": embErr.Error()})
 		}
-		embedCtx, embedCancel := context.WithTimeout(ctx.GoContext(), time.Duration(embedTimeout)*time.Second)
-		embResp, embErr := vdbembed.CreateEmbeddings(embedCtx, vdbembed.EmbeddingRequest{
-			Provider: vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
-			APIKey:   a.settings.EmbeddingAPIKey,
-			BaseURL:  a.settings.EmbeddingBaseURL,
-			Model:    a.settings.EmbeddingModel,
-			Texts:    []string{input.Query},
-		})
-		embedCancel()
-		if embErr != nil {
-			l.Errorf("RAGQuery: embed error=%v", embErr)
-			if tc != nil {
-				tc.SetTag("error", true)
-			}
-			if err2 := ctx.SetOutputObject(&Output{
-				Success:  false,
-				Error:    embErr.Error(),
-				Duration: time.Since(start).String(),
-			}); err2 != nil {
-				l.Errorf("SetOutputObject: %v", err2)
-			}
-			return true, nil
+		if err := ctx.SetOutputObject(&Output{
+			Success:  false,
+			Error:    fmt.Sprintf("embedding failed: %s", embErr.Error()),
+			Duration: time.Since(start).String(),
+		}); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
 		}
-		if len(embResp.Embeddings) > 0 {
-			queryVector = embResp.Embeddings[0]
+		return true, nil
+	}
+	queryVector := embResult.Embeddings[0]
+	l.Debugf("RAGQuery: query embedded: dims=%d tokens=%d", len(queryVector), embResult.TokensUsed)
+
+	// Step 2: Vector search (or hybrid search if configured), once per query
+	if a.settings.UseHybridSearch {
+		// Descriptor default is 0.5 (balanced). Users who want pure BM25 should set
+		// alpha=0.0; users who want pure dense vector should set alpha=1.0.
+		// Negative values or values >1 are rejected as invalid configuration.
+		if alpha := a.settings.HybridAlpha; alpha < 0 || alpha > 1 {
+			return false, fmt.Errorf("vectordb-rag: hybridAlpha must be between 0.0 and 1.0, got %.4f", alpha)
 		}
 	}
 
-	// Step 2: Vector or hybrid search
-	connSettings := a.conn.GetSettings()
-	searchTimeout := connSettings.TimeoutSeconds
-	if searchTimeout <= 0 {
-		searchTimeout = 30
+	// With MMR every search over-fetches candidates; the diverse topK is
+	// selected after fusion.
+	searchTopK := topK
+	if a.settings.EnableMMR {
+		searchTopK = vectordb.MMRFetchK(topK, a.settings.MMRFetchK)
 	}
-	searchCtx, searchCancel := context.WithTimeout(ctx.GoContext(), time.Duration(searchTimeout)*time.Second)
-	defer searchCancel()
 
-	var results []vectordb.SearchResult
+	var searchResults []vectordb.SearchResult
 	var searchErr error
-
-	alpha := a.settings.HybridAlpha
-	if alpha > 0 && alpha < 1 && input.Query != "" && len(queryVector) > 0 {
-		results, searchErr = a.conn.GetClient().HybridSearch(searchCtx, vectordb.HybridSearchRequest{
-			CollectionName: collectionName,
-			QueryText:      input.Query,
-			QueryVector:    queryVector,
-			TopK:           topK,
-			ScoreThreshold: a.settings.ScoreThreshold,
-			Filters:        input.Filters,
-			Alpha:          alpha,
-		})
-	} else {
-		results, searchErr = a.conn.GetClient().VectorSearch(searchCtx, vectordb.SearchRequest{
-			CollectionName: collectionName,
-			QueryVector:    queryVector,
-			TopK:           topK,
-			ScoreThreshold: a.settings.ScoreThreshold,
-			Filters:        input.Filters,
-		})
+	lists := make([][]vectordb.SearchResult, 0, len(plan.Searches))
+	for i, q := range plan.Searches {
+		if i >= len(embResult.Embeddings) {
+			break
+		}
+		l.Debugf("RAGQuery: search collection=%s topK=%d hybrid=%v query=%q", collectionName, searchTopK, a.settings.UseHybridSearch, q)
+		var results []vectordb.SearchResult
+		results, searchErr = a.search(opCtx, collectionName, q, embResult.Embeddings[i], searchTopK, input.Filters)
+		if searchErr != nil {
+			break
+		}
+		lists = append(lists, results)
 	}
-
 	if searchErr != nil {
-		l.Errorf("RAGQuery: search error=%v", searchErr)
+		searchMode := "vector search"
+		if a.settings.UseHybridSearch {
+			searchMode = "hybrid search"
+		}
+		l.Errorf("RAGQuery: %s failed: collection=%s error=%v", searchMode, collectionName, searchErr)
 		if tc != nil {
 			tc.SetTag("error", true)
+			tc.LogKV(map[string]interface{}{"event": "error", "message": searchErr.Error()})
 		}
-		if err2 := ctx.SetOutputObject(&Output{
+		if err := ctx.SetOutputObject(&Output{
 			Success:  false,
-			Error:    searchErr.Error(),
+			Error:    fmt.Sprintf("%s failed: %s", searchMode, searchErr.Error()),
 			Duration: time.Since(start).String(),
-		}); err2 != nil {
-			l.Errorf("SetOutputObject: %v", err2)
+		}); err != nil {
+			l.Errorf("SetOutputObject: %v", err)
 		}
 		return true, nil
 	}
 
-	// Step 3: Build context string from retrieved docs
-	var ctxBuilder strings.Builder
-	for i, r := range results {
-		ctxBuilder.WriteString(fmt.Sprintf("Document %d (score=%.4f):\n%s\n\n", i+1, r.Score, r.Content))
+	if len(lists) == 1 {
+		searchResults = lists[0]
+	} else {
+		searchResults = fuseRRF(lists, searchTopK)
+		l.Debugf("RAGQuery: fused %d result lists with RRF", len(lists))
 	}
-	retrievedContext := ctxBuilder.String()
-
-	// Step 4: Generate answer via LLM (optional)
-	answer := ""
-	if a.settings.LLMEndpoint != "" {
-		prompt := fmt.Sprintf("Context:\n%s\n\nQuestion: %s\nAnswer:", retrievedContext, input.Query)
-		var llmErr error
-		answer, llmErr = callLLM(ctx.GoContext(), a.settings.LLMEndpoint, a.settings.LLMAPIKey,
-			a.settings.LLMModel, prompt, a.settings.LLMTimeoutSeconds)
-		if llmErr != nil {
-			l.Warnf("RAGQuery: LLM call failed: %v (returning context only)", llmErr)
+	if a.settings.EnableMMR {
+		candidates := len(searchResults)
+		var mmrErr error
+		searchResults, mmrErr = vectordb.DiversifyMMR(opCtx, a.conn.GetClient(), collectionName, queryVector, searchResults, topK, a.settings.MMRLambda, false)
+		if mmrErr != nil {
+			l.Warnf("RAGQuery: MMR could not read every candidate vector: %v", mmrErr)
+		}
+		l.Debugf("RAGQuery: MMR selected %d of %d candidates lambda=%.2f", len(searchResults), candidates, a.settings.MMRLambda)
+	}
+	if a.settings.ContextExpansion != expansionNone || a.settings.ContextTokenBudget > 0 {
+		retrieved := len(searchResults)
+		var expandErr error
+		searchResults, expandErr = expandContext(opCtx, a.conn.GetClient(), collectionName, searchResults, expansionConfig{
+			Mode:         a.settings.ContextExpansion,
+			Neighbors:    a.settings.NeighborChunks,
+			TokenBudget:  a.settings.ContextTokenBudget,
+			ContentField: a.settings.ContentField,
+		})
+		if expandErr != nil {
+			l.Warnf("RAGQuery: context expansion could not read every chunk window: %v", expandErr)
 		}
+		l.Debugf("RAGQuery: context expansion=%s built %d context documents from %d chunks", a.settings.ContextExpansion, len(searchResults), retrieved)
 	}
 
 	duration := time.Since(start)
-	l.Debugf("RAGQuery: collection=%s results=%d duration=%s hasAnswer=%v",
-		collectionName, len(results), duration, answer != "")
-
+	l.Debugf("RAGQuery: retrieved %d documents duration=%s", len(searchResults), duration)
 	if tc != nil {
-		tc.SetTag("db.vectordb.result_count", len(results))
+		tc.SetTag("db.vectordb.result_count", len(searchResults))
+		if plan.Rewritten != "" {
+			tc.SetTag("ai.rewritten_query", plan.Rewritten)
+		}
 	}
 
-	searchResultsOut := make([]interface{}, len(results))
-	for i, r := range results {
-		b, _ := json.Marshal(r)
-		var m map[string]interface{}
-		_ = json.Unmarshal(b, &m)
-		searchResultsOut[i] = m
+	// Step 3: Format context string for LLM
+	formattedContext := formatContext(searchResults, a.settings.ContentField, a.settings.ContextFormat)
+
+	// Build queryEmbedding output ([]interface{})
+	qEmbOut := make([]interface{}, len(queryVector))
+	for i, f := range queryVector {
+		qEmbOut[i] = f
+	}
+
+	// Build sourceDocuments output
+	sourceDocs := searchResultsToInterface(searchResults)
+
+	// Step 4 (optional): LLM answer generation
+	answer := ""
+	var citations []interface{}
+	streamedTokens := 0
+	cacheHit := false
+	if a.settings.EnableLLMGenerate {
+		systemPrompt := a.settings.SystemPrompt
+		if input.SystemPrompt != "" {
+			systemPrompt = input.SystemPrompt
+		}
+		// Citation markers refer to document numbers, which the plain format
+		// does not show; the prompt then uses the numbered format.
+		promptContext := formattedContext
+		if a.settings.EnableCitations {
+			systemPrompt = strings.TrimSpace(systemPrompt + "\n\n" + citationInstruction)
+			if a.settings.ContextFormat == "plain" {
+				promptContext = formatContext(searchResults, a.settings.ContentField, "numbered")
+			}
+		}
+
+		var stream *tokenStream
+		var onToken func(string)
+		if a.settings.EnableStreaming {
+			var streamErr error
+			stream, streamErr = newTokenStream(a.settings.SSEServerRef, input.StreamConnectionID, input.StreamTopic)
+			if streamErr != nil {
+				l.Warnf("RAGQuery: streaming disabled for this request: %v", streamErr)
+			} else {
+				onToken = stream.token
+			}
+		}
+
+		// Step 4a (optional): answer from the semantic cache
+		var cacheVec []float64
+		var cached *vdbsemcache.Entry
+		var namespace string
+		if a.settings.EnableSemanticCache {
+			namespace = cacheNamespace(collectionName, input.Filters, a.settings.LLMModel, systemPrompt)
+			var vecErr error
+			cacheVec, vecErr = a.cacheVector(opCtx, plan, embResult.Embeddings)
+			if vecErr != nil {
+				l.Warnf("RAGQuery: semantic cache skipped, embedding the question failed: %v", vecErr)
+			} else {
+				cached = a.lookupAnswer(opCtx, l, cacheVec, namespace)
+			}
+		}
+
+		var llmErr error
+		if cached != nil {
+			l.Debugf("RAGQuery: semantic cache hit id=%s score=%.4f cachedQuery=%q", cached.ID, cached.Score, cached.Query)
+			cacheHit = true
+			answer = cached.Answer
+			if a.settings.EnableCitations {
+				citations = cachedCitations(cached)
+			}
+			if stream != nil {
+				stream.token(answer)
+			}
+		} else {
+			l.Debugf("RAGQuery: generating answer with llmProvider=%s llmModel=%s streaming=%v", a.settings.LLMProvider, a.settings.LLMModel, onToken != nil)
+			answer, llmErr = a.generate(opCtx, plan.Query, promptContext, systemPrompt, onToken)
+		}
+		if llmErr != nil {
+			l.Warnf("RAGQuery: LLM generation failed (%v) — returning context only", llmErr)
+			answer = fmt.Sprintf("[LLM generation failed: %s]\n\nRetrieved context:\n%s", llmErr.Error(), formattedContext)
+			if stream != nil {
+				stream.send(streamEventError, map[string]interface{}{"error": llmErr.Error()})
+			}
+		} else {
+			if a.settings.EnableCitations && !cacheHit {
+				citations = citationsToInterface(extractCitations(answer, searchResults, a.settings.ContentField))
+			}
+			if cacheVec != nil && !cacheHit && answer != "" {
+				a.storeAnswer(opCtx, l, cacheVec, plan.Query, namespace, answer, citations, searchResults)
+			}
+			if stream != nil {
+				stream.send(streamEventDone, map[string]interface{}{
+					"answer":    answer,
+					"citations": citations,
+					"sources":   streamSources(searchResults),
+				})
+			}
+		}
+		if stream != nil {
+			streamedTokens = stream.tokens
+			if stream.err != nil {
+				l.Warnf("RAGQuery: SSE streaming stopped: %v", stream.err)
+			}
+		}
+		duration = time.Since(start)
 	}
 
 	if err := ctx.SetOutputObject(&Output{
-		Success:           true,
-		Answer:            answer,
-		Context:           retrievedContext,
-		SearchResults:     searchResultsOut,
-		SearchResultCount: len(results),
-		Duration:          duration.String(),
+		Success:              true,
+		Answer:               answer,
+		FormattedContext:     formattedContext,
+		SourceDocuments:      sourceDocs,
+		QueryEmbedding:       qEmbOut,
+		TotalFound:           len(searchResults),
+		Duration:             duration.String(),
+		Citations:            citations,
+		StreamedTokens:       streamedTokens,
+		RewrittenQuery:       plan.Rewritten,
+		QueryVariants:        queryVariantsOut(plan),
+		HypotheticalDocument: plan.Hypothetical,
+		CacheHit:             cacheHit,
 	}); err != nil {
 		l.Errorf("SetOutputObject: %v", err)
 	}
 	return true, nil
 }
 
-func callLLM(ctx context.Context, endpoint, apiKey, model, prompt string, timeoutSecs int) (string, error) {
-	if timeoutSecs <= 0 {
-		timeoutSecs = 30
+// formatContext builds an LLM-ready context string from search results.
+func formatContext(results []vectordb.SearchResult, contentField, format string) string {
+	if len(results) == 0 {
+		return ""
 	}
-	opCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSecs)*time.Second)
-	defer cancel()
-
-	reqBody, _ := json.Marshal(map[string]interface{}{
-		"model": model,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
-	})
-
-	req, err := http.NewRequestWithContext(opCtx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
-	if err != nil {
-		return "", fmt.Errorf("LLM: create request: %w", err)
+	if format == "json" {
+		return formatContextJSON(results, contentField)
+	}
+	var sb strings.Builder
+	for i, r := range results {
+		content := extractContent(r, contentField)
+		switch format {
+		case "markdown":
+			fmt.Fprintf(&sb, "**[%d]** *(score: %.4f)*\n\n%s\n\n---\n\n", i+1, r.Score, content)
+		case "xml":
+			fmt.Fprintf(&sb, "<document id=\"%d\" score=\"%.4f\">\n%s\n</document>\n", i+1, r.Score, content)
+		case "plain":
+			fmt.Fprintf(&sb, "%s\n\n", content)
+		default: // "numbered"
+			fmt.Fprintf(&sb, "%d. %s\n\n", i+1, content)
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
-	if apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+apiKey)
+	if format == "xml" {
+		return "<context>\n" + sb.String() + "</context>"
 	}
+	return strings.TrimRight(sb.String(), "\n")
+}
 
-	resp, err := ragLLMHTTPClient.Do(req)
+// formatContextJSON serialises results as a JSON array for native Flogo consumption.
+// Each element: {"index":1,"id":"...","score":0.92,"content":"...","payload":{...}}
+func formatContextJSON(results []vectordb.SearchResult, contentField string) string {
+	type jsonDoc struct {
+		Index   int                    `json:"index"`
+		ID      string                 `json:"id"`
+		Score   float64                `json:"score"`
+		Content string                 `json:"content"`
+		Payload map[string]interface{} `json:"payload"`
+	}
+	docs := make([]jsonDoc, len(results))
+	for i, r := range results {
+		payload := r.Payload
+		if payload == nil {
+			payload = map[string]interface{}{}
+		}
+		docs[i] = jsonDoc{
+			Index:   i + 1,
+			ID:      r.ID,
+			Score:   r.Score,
+			Content: extractContent(r, contentField),
+			Payload: payload,
+		}
+	}
+	b, err := json.Marshal(docs)
 	if err != nil {
-		return "", fmt.Errorf("LLM: request failed: %w", err)
+		return "[]"
 	}
-	defer resp.Body.Close()
+	return string(b)
+}
 
-	body, _ := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("LLM: HTTP %d: %s", resp.StatusCode, string(body))
+// extractContent pulls the text content from a SearchResult.
+// Priority: (1) r.Content (the first-class field populated by all providers),
+// (2) r.Payload[contentField] (custom key), (3) fallback — join all payload strings.
+func extractContent(r vectordb.SearchResult, contentField string) string {
+	// First-class Content field is always populated by all VectorDB providers.
+	if r.Content != "" {
+		return r.Content
 	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("LLM: decode response: %w", err)
+	if r.Payload != nil {
+		if val, ok := r.Payload[contentField]; ok && val != nil {
+			return fmt.Sprintf("%v", val)
+		}
+	}
+	// Last resort: join all non-empty string payload values.
+	var parts []string
+	for k, v := range r.Payload {
+		if s, ok := v.(string); ok && s != "" {
+			parts = append(parts, fmt.Sprintf("%s: %s", k, s))
+		}
 	}
+	return strings.Join(parts, " | ")
+}
 
-	// Extract content from OpenAI-compatible response
-	if choices, ok := result["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if msg, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := msg["content"].(string); ok {
-					return content, nil
-				}
-			}
-			if text, ok := choice["text"].(string); ok {
-				return text, nil
-			}
+// searchResultsToInterface converts []SearchResult to []interface{} for Flogo output.
+func searchResultsToInterface(results []vectordb.SearchResult) []interface{} {
+	out := make([]interface{}, len(results))
+	for i, r := range results {
+		out[i] = map[string]interface{}{
+			"id":      r.ID,
+			"score":   r.Score,
+			"content": r.Content,
+			"payload": r.Payload,
 		}
 	}
-	// Ollama generate endpoint
-	if response, ok := result["response"].(string); ok {
-		return response, nil
+	return out
+}
+
+// search runs one vector or hybrid search for a query and its embedding.
+func (a *Activity) search(ctx context.Context, collection, queryText string, vector []float64, topK int, filters map[string]interface{}) ([]vectordb.SearchResult, error) {
+	fqv := make([]float64, len(vector))
+	copy(fqv, vector)
+	if a.settings.UseHybridSearch {
+		return a.conn.GetClient().HybridSearch(ctx, vectordb.HybridSearchRequest{
+			CollectionName: collection,
+			QueryText:      queryText,
+			QueryVector:    fqv,
+			TopK:           topK,
+			ScoreThreshold: a.settings.ScoreThreshold,
+			Filters:        filters,
+			Alpha:          a.settings.HybridAlpha,
+			// SkipPayload defaults to false (zero value) = include payload.
+		})
+	}
+	return a.conn.GetClient().VectorSearch(ctx, vectordb.SearchRequest{
+		CollectionName: collection,
+		QueryVector:    fqv,
+		TopK:           topK,
+		ScoreThreshold: a.settings.ScoreThreshold,
+		Filters:        filters,
+		// SkipPayload defaults to false (zero value) = include payload.
+		WithVectors: a.settings.EnableMMR,
+	})
+}
+
+// queryVariantsOut returns the searched queries for the queryVariants output,
+// or nil when only the query itself was searched.
+func queryVariantsOut(plan queryPlan) []interface{} {
+	if len(plan.Searches) < 2 {
+		return nil
+	}
+	return stringsToInterface(plan.Searches)
+}
+
+// streamSources lists the retrieved documents for the final stream event so a
+// client can resolve citation numbers: [{"index":1,"id":"..."}].
+func streamSources(results []vectordb.SearchResult) []interface{} {
+	out := make([]interface{}, len(results))
+	for i, r := range results {
+		out[i] = map[string]interface{}{"index": i + 1, "id": r.ID}
 	}
-	return "", fmt.Errorf("LLM: unexpected response format")
+	return out
 }
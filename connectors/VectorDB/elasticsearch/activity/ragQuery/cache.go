@@ -0,0 +1,111 @@
+package ragQuery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch"
+	vdbembed "github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch/embeddings"
+	vdbsemcache "github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch/semcache"
+	"github.com/project-flogo/core/support/log"
+)
+
+// cacheNamespace partitions the answer cache by collection, filters, LLM
+// model and system prompt: the same question asked over different documents,
+// or answered by a different model or prompt, has a different answer.
+func cacheNamespace(collection string, filters map[string]interface{}, llmModel, systemPrompt string) string {
+	sum := sha256.Sum256([]byte(systemPrompt))
+	ns := collection + " " + llmModel + " " + hex.EncodeToString(sum[:8])
+	if len(filters) == 0 {
+		return ns
+	}
+	b, err := json.Marshal(filters) // map keys are sorted, so equal filters match
+	if err != nil {
+		return ns
+	}
+	return ns + " " + string(b)
+}
+
+// cacheVector returns the embedding of the question for the answer cache:
+// the first search embedding, unless HyDE embedded a hypothetical passage
+// instead, in which case the question is embedded on its own.
+func (a *Activity) cacheVector(ctx context.Context, plan queryPlan, embeddings [][]float64) ([]float64, error) {
+	if len(plan.EmbedTexts) > 0 && plan.EmbedTexts[0] == plan.Query {
+		return embeddings[0], nil
+	}
+	res, err := vdbembed.CreateEmbeddings(ctx, vdbembed.EmbeddingRequest{
+		Provider:   vdbembed.EmbeddingProvider(a.settings.EmbeddingProvider),
+		APIKey:     a.settings.EmbeddingAPIKey,
+		BaseURL:    a.settings.EmbeddingBaseURL,
+		Model:      a.settings.EmbeddingModel,
+		Texts:      []string{plan.Query},
+		Dimensions: a.settings.EmbeddingDimensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Embeddings[0], nil
+}
+
+// lookupAnswer returns the cached answer for the question, or nil on a miss.
+// Cache errors are logged and treated as a miss.
+func (a *Activity) lookupAnswer(ctx context.Context, l log.Logger, vector []float64, namespace string) *vdbsemcache.Entry {
+	entry, err := vdbsemcache.Lookup(ctx, a.conn.GetClient(), a.settings.CacheCollection, vector, vdbsemcache.LookupOptions{
+		Namespace: namespace,
+		Threshold: a.settings.CacheSimilarityThreshold,
+	})
+	if err != nil {
+		l.Warnf("RAGQuery: semantic cache lookup failed (%v) — generating the answer", err)
+		return nil
+	}
+	return entry
+}
+
+// storeAnswer caches a generated answer with the documents it was generated
+// from and its citations. Cache errors are logged only.
+func (a *Activity) storeAnswer(ctx context.Context, l log.Logger, vector []float64, query, namespace, answer string, citations []interface{}, results []vectordb.SearchResult) {
+	metadata := map[string]interface{}{"llmModel": a.settings.LLMModel}
+	if len(citations) > 0 {
+		metadata["citations"] = citations
+	}
+	entry, err := vdbsemcache.Store(ctx, a.conn.GetClient(), a.settings.CacheCollection, vector, vdbsemcache.Entry{
+		Query:     query,
+		Answer:    answer,
+		Namespace: namespace,
+		SourceIDs: cacheSourceIDs(results),
+		Metadata:  metadata,
+	}, time.Duration(a.settings.CacheTTLSeconds)*time.Second)
+	if err != nil {
+		l.Warnf("RAGQuery: semantic cache store failed: %v", err)
+		return
+	}
+	l.Debugf("RAGQuery: cached answer id=%s namespace=%q sources=%d", entry.ID, namespace, len(entry.SourceIDs))
+}
+
+// cacheSourceIDs lists the source documents of the context for cache
+// invalidation: the parentId of each chunk written by ingestDocuments, or
+// the result ID for documents without one.
+func cacheSourceIDs(results []vectordb.SearchResult) []string {
+	ids := make([]string, 0, len(results))
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		id, _ := r.Payload[parentIDKey].(string)
+		if id == "" {
+			id = r.ID
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// cachedCitations returns the citations stored with a cached answer.
+func cachedCitations(e *vdbsemcache.Entry) []interface{} {
+	c, _ := e.Metadata["citations"].([]interface{})
+	return c
+}
@@ -6,44 +6,151 @@ import (
 	"github.com/project-flogo/core/support/connection"
 )
 
+// Settings holds design-time activity configuration.
 type Settings struct {
-	Connection        connection.Manager `md:"connection,required"`
-	DefaultCollection string             `md:"defaultCollection"`
-	DefaultTopK       int                `md:"defaultTopK"`
-	ScoreThreshold    float64            `md:"scoreThreshold"`
-	HybridAlpha       float64            `md:"hybridAlpha"`
-	EmbeddingProvider string             `md:"embeddingProvider"`
-	EmbeddingAPIKey   string             `md:"embeddingAPIKey"`
-	EmbeddingBaseURL  string             `md:"embeddingBaseURL"`
-	EmbeddingModel    string             `md:"embeddingModel"`
-	LLMEndpoint       string             `md:"llmEndpoint"`
-	LLMAPIKey         string             `md:"llmAPIKey"`
-	LLMModel          string             `md:"llmModel"`
-	LLMTimeoutSeconds int                `md:"llmTimeoutSeconds"`
+	Connection connection.Manager `md:"connection,required"`
+	// UseConnectorEmbedding instructs the activity to inherit the embedding
+	// provider, API key, and base URL from the VectorDB connector settings.
+	// When true, only embeddingModel and embeddingDimensions need to be set
+	// here. Set to false to supply per-activity embedding credentials instead.
+	UseConnectorEmbedding bool    `md:"useConnectorEmbedding"`
+	EmbeddingProvider     string  `md:"embeddingProvider"`
+	EmbeddingAPIKey       string  `md:"embeddingAPIKey"`
+	EmbeddingBaseURL      string  `md:"embeddingBaseURL"`
+	EmbeddingModel        string  `md:"embeddingModel,required"`
+	EmbeddingDimensions   int     `md:"embeddingDimensions"`
+	DefaultCollection     string  `md:"defaultCollection"`
+	DefaultTopK           int     `md:"defaultTopK"`
+	ScoreThreshold        float64 `md:"scoreThreshold"`
+	ContentField          string  `md:"contentField"`
+	ContextFormat         string  `md:"contextFormat"`
+	TimeoutSeconds        int     `md:"timeoutSeconds"`
+	// UseHybridSearch enables hybrid (BM25 + dense) search instead of
+	// pure vector search. When true, queryText is used for the BM25 component
+	// and queryVector for the dense component.
+	UseHybridSearch bool `md:"useHybridSearch"`
+	// HybridAlpha controls the weighting between dense (1.0) and sparse/BM25 (0.0).
+	// Only used when UseHybridSearch=true. Default: 0.5 (balanced fusion).
+	HybridAlpha float64 `md:"hybridAlpha"`
+
+	// EnableLLMGenerate adds an LLM generation step after retrieval.
+	// When false (default), only retrieval is performed and Answer is empty.
+	EnableLLMGenerate bool `md:"enableLLMGenerate"`
+
+	// --- LLM Generation (only used when EnableLLMGenerate=true) ---
+	// LLMProvider is one of "Ollama", "OpenAI", "Azure OpenAI", "Anthropic",
+	// "Cohere" or "Custom" (any OpenAI-compatible API).
+	LLMProvider  string  `md:"llmProvider"`
+	LLMBaseURL   string  `md:"llmBaseURL"`
+	LLMAPIKey    string  `md:"llmAPIKey"`
+	LLMModel     string  `md:"llmModel"`
+	SystemPrompt string  `md:"systemPrompt"`
+	MaxTokens    int     `md:"maxTokens"`
+	Temperature  float64 `md:"temperature"`
+	// LLMAPIVersion is the Azure OpenAI api-version. Default: 2024-10-21.
+	LLMAPIVersion string `md:"llmAPIVersion"`
+
+	// EnableCitations asks the LLM to cite context documents as [n] and maps
+	// each answer sentence to sourceDocuments IDs in the citations output.
+	EnableCitations bool `md:"enableCitations"`
+
+	// EnableStreaming streams the answer tokens as server-sent events through
+	// the SSE trigger registered as SSEServerRef (default "default"). The
+	// target connection or topic is chosen per request.
+	EnableStreaming bool   `md:"enableStreaming"`
+	SSEServerRef    string `md:"sseServerRef"`
+
+	// --- Query transformation (uses the LLM settings above) ---
+	// RetrievalMode is "standard" (default), "multiQuery" (search
+	// QueryVariants LLM-generated rephrasings and fuse the results with
+	// reciprocal rank fusion) or "hyde" (embed an LLM-written hypothetical
+	// answer instead of the question).
+	RetrievalMode string `md:"retrievalMode"`
+	QueryVariants int    `md:"queryVariants"`
+	// EnableQueryRewrite rewrites a follow-up question into a standalone
+	// query using the chatHistory input before retrieval.
+	EnableQueryRewrite bool `md:"enableQueryRewrite"`
+
+	// EnableMMR re-selects the retrieved documents with maximal marginal
+	// relevance: MMRFetchK candidates are fetched per search and a diverse
+	// top-K is kept. MMRLambda weighs relevance (1.0) against diversity (0.0).
+	EnableMMR bool    `md:"enableMMR"`
+	MMRLambda float64 `md:"mmrLambda"`
+	MMRFetchK int     `md:"mmrFetchK"`
+
+	// ContextExpansion replaces each retrieved chunk with a larger window of
+	// its document before the context is formatted: "none" (default),
+	// "neighbors" (NeighborChunks chunks on each side) or "parent" (the
+	// chunk's whole section). ContextTokenBudget caps the estimated size of
+	// the context in tokens; 0 = unlimited.
+	ContextExpansion   string `md:"contextExpansion"`
+	NeighborChunks     int    `md:"neighborChunks"`
+	ContextTokenBudget int    `md:"contextTokenBudget"`
+
+	// EnableSemanticCache looks up the answer to a similar earlier question
+	// in CacheCollection before generating, and caches generated answers for
+	// CacheTTLSeconds (0 = no expiry). Only used when EnableLLMGenerate=true.
+	EnableSemanticCache      bool    `md:"enableSemanticCache"`
+	CacheCollection          string  `md:"cacheCollection"`
+	CacheSimilarityThreshold float64 `md:"cacheSimilarityThreshold"`
+	CacheTTLSeconds          int     `md:"cacheTTLSeconds"`
 }
 
+// String returns a human-readable representation of Settings with sensitive
+// fields (EmbeddingAPIKey) replaced by "[redacted]".
+// Prevents API keys from leaking into Flogo logs or error messages.
+func (s Settings) String() string {
+	apiKey := ""
+	if s.EmbeddingAPIKey != "" {
+		apiKey = "[redacted]"
+	}
+	llmKey := ""
+	if s.LLMAPIKey != "" {
+		llmKey = "[redacted]"
+	}
+	return fmt.Sprintf(
+		"ragQuery.Settings{provider:%q model:%q dims:%d topK:%d collection:%q hybrid:%v alpha:%.2f llmGenerate:%v llmProvider:%q llmModel:%q apiKey:%s llmApiKey:%s}",
+		s.EmbeddingProvider, s.EmbeddingModel, s.EmbeddingDimensions,
+		s.DefaultTopK, s.DefaultCollection, s.UseHybridSearch, s.HybridAlpha,
+		s.EnableLLMGenerate, s.LLMProvider, s.LLMModel, apiKey, llmKey,
+	)
+}
+
+// Input holds runtime data for the activity.
 type Input struct {
+	QueryText      string                 `md:"queryText"`
 	CollectionName string                 `md:"collectionName"`
-	Query          string                 `md:"query"`
 	TopK           int                    `md:"topK"`
 	Filters        map[string]interface{} `md:"filters"`
+	SystemPrompt   string                 `md:"systemPrompt"`
+	// StreamConnectionID and StreamTopic address the SSE clients that receive
+	// the token stream; with neither, tokens are broadcast to all clients.
+	StreamConnectionID string `md:"streamConnectionId"`
+	StreamTopic        string `md:"streamTopic"`
+	// ChatHistory holds the previous conversation turns, oldest first, as
+	// {"role","content"} objects. Used by query rewriting.
+	ChatHistory []interface{} `md:"chatHistory"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"collectionName": i.CollectionName,
-		"query":          i.Query,
-		"topK":           i.TopK,
-		"filters":        i.Filters,
+		"queryText":          i.QueryText,
+		"collectionName":     i.CollectionName,
+		"topK":               i.TopK,
+		"filters":            i.Filters,
+		"systemPrompt":       i.SystemPrompt,
+		"streamConnectionId": i.StreamConnectionID,
+		"streamTopic":        i.StreamTopic,
+		"chatHistory":        i.ChatHistory,
 	}
 }
 
 func (i *Input) FromMap(v map[string]interface{}) error {
-	if val, ok := v["collectionName"]; ok {
-		i.CollectionName = fmt.Sprintf("%v", val)
+	if val, ok := v["queryText"]; ok && val != nil {
+		i.QueryText = fmt.Sprintf("%v", val)
 	}
-	if val, ok := v["query"]; ok {
-		i.Query = fmt.Sprintf("%v", val)
+	if val, ok := v["collectionName"]; ok && val != nil {
+		i.CollectionName = fmt.Sprintf("%v", val)
 	}
 	if val, ok := v["topK"]; ok {
 		switch n := val.(type) {
@@ -58,28 +165,64 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 			i.Filters = m
 		}
 	}
+	if val, ok := v["systemPrompt"]; ok && val != nil {
+		i.SystemPrompt = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["streamConnectionId"]; ok && val != nil {
+		i.StreamConnectionID = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["streamTopic"]; ok && val != nil {
+		i.StreamTopic = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["chatHistory"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.ChatHistory = arr
+		}
+	}
 	return nil
 }
 
+// Output holds the activity result.
 type Output struct {
-	Success           bool          `md:"success"`
-	Answer            string        `md:"answer"`
-	Context           string        `md:"context"`
-	SearchResults     []interface{} `md:"searchResults"`
-	SearchResultCount int           `md:"searchResultCount"`
-	Duration          string        `md:"duration"`
-	Error             string        `md:"error"`
+	Success          bool          `md:"success"`
+	Answer           string        `md:"answer"`
+	FormattedContext string        `md:"formattedContext"`
+	SourceDocuments  []interface{} `md:"sourceDocuments"`
+	QueryEmbedding   []interface{} `md:"queryEmbedding"`
+	TotalFound       int           `md:"totalFound"`
+	Duration         string        `md:"duration"`
+	Error            string        `md:"error"`
+	// Citations maps answer sentences to sourceDocuments (enableCitations).
+	Citations []interface{} `md:"citations"`
+	// StreamedTokens is the number of token events sent (enableStreaming).
+	StreamedTokens int `md:"streamedTokens"`
+	// RewrittenQuery is the standalone question used for retrieval and
+	// generation when enableQueryRewrite rewrote the query.
+	RewrittenQuery string `md:"rewrittenQuery"`
+	// QueryVariants lists every query searched (multiQuery mode).
+	QueryVariants []interface{} `md:"queryVariants"`
+	// HypotheticalDocument is the passage embedded in hyde mode.
+	HypotheticalDocument string `md:"hypotheticalDocument"`
+	// CacheHit is true when the answer came from the semantic cache.
+	CacheHit bool `md:"cacheHit"`
 }
 
 func (o *Output) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"success":           o.Success,
-		"answer":            o.Answer,
-		"context":           o.Context,
-		"searchResults":     o.SearchResults,
-		"searchResultCount": o.SearchResultCount,
-		"duration":          o.Duration,
-		"error":             o.Error,
+		"success":              o.Success,
+		"answer":               o.Answer,
+		"formattedContext":     o.FormattedContext,
+		"sourceDocuments":      o.SourceDocuments,
+		"queryEmbedding":       o.QueryEmbedding,
+		"totalFound":           o.TotalFound,
+		"duration":             o.Duration,
+		"error":                o.Error,
+		"citations":            o.Citations,
+		"streamedTokens":       o.StreamedTokens,
+		"rewrittenQuery":       o.RewrittenQuery,
+		"queryVariants":        o.QueryVariants,
+		"hypotheticalDocument": o.HypotheticalDocument,
+		"cacheHit":             o.CacheHit,
 	}
 }
 
@@ -22,6 +22,9 @@ type Input struct {
 	Filters        map[string]interface{} `md:"filters"`
 	WithVectors    bool                   `md:"withVectors"`
 	SkipPayload    bool                   `md:"skipPayload"`
+	MMR            bool                   `md:"mmr"`
+	MMRLambda      float64                `md:"mmrLambda"`
+	MMRFetchK      int                    `md:"mmrFetchK"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
@@ -33,6 +36,9 @@ func (i *Input) ToMap() map[string]interface{} {
 		"filters":        i.Filters,
 		"withVectors":    i.WithVectors,
 		"skipPayload":    i.SkipPayload,
+		"mmr":            i.MMR,
+		"mmrLambda":      i.MMRLambda,
+		"mmrFetchK":      i.MMRFetchK,
 	}
 }
 
@@ -74,6 +80,22 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 	if val, ok := v["skipPayload"]; ok {
 		i.SkipPayload, _ = val.(bool)
 	}
+	if val, ok := v["mmr"]; ok {
+		i.MMR, _ = val.(bool)
+	}
+	if val, ok := v["mmrLambda"]; ok {
+		if f, ok := val.(float64); ok {
+			i.MMRLambda = f
+		}
+	}
+	if val, ok := v["mmrFetchK"]; ok {
+		switch n := val.(type) {
+		case int:
+			i.MMRFetchK = n
+		case float64:
+			i.MMRFetchK = int(n)
+		}
+	}
 	return nil
 }
 
@@ -20,6 +20,7 @@ type elasticsearchClient struct {
 }
 
 var _ VectorDBClient = (*elasticsearchClient)(nil)
+var _ MetricReader = (*elasticsearchClient)(nil)
 
 func newElasticsearchClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	transport := &http.Transport{
@@ -143,6 +144,43 @@ func (c *elasticsearchClient) CollectionExists(ctx context.Context, name string)
 	return false, newError(ErrCodeProviderError, fmt.Sprintf("CollectionExists: unexpected status %d", statusCode), nil)
 }
 
+// CollectionMetric returns the similarity of the index's embedding field.
+func (c *elasticsearchClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", newError(ErrCodeInvalidCollectionName, "", nil)
+	}
+	body, statusCode, err := c.doRequest(ctx, http.MethodGet, "/"+name+"/_mapping", nil)
+	if err != nil {
+		return "", err
+	}
+	if statusCode != http.StatusOK {
+		return "", c.mapError(statusCode, body, "CollectionMetric")
+	}
+	var mappings map[string]struct {
+		Mappings struct {
+			Properties struct {
+				Embedding struct {
+					Similarity string `json:"similarity"`
+				} `json:"embedding"`
+			} `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := json.Unmarshal(body, &mappings); err != nil {
+		return "", newError(ErrCodeProviderError, "CollectionMetric: decode mapping", err)
+	}
+	for _, m := range mappings {
+		switch s := m.Mappings.Properties.Embedding.Similarity; s {
+		case "dot_product", "max_inner_product":
+			return "dot", nil
+		case "l2_norm":
+			return "euclidean", nil
+		default:
+			return s, nil
+		}
+	}
+	return "", nil
+}
+
 func (c *elasticsearchClient) CreateCollection(ctx context.Context, cfg CollectionConfig) error {
 	if err := validateCollectionConfig(cfg); err != nil {
 		return err
@@ -414,7 +452,10 @@ func (c *elasticsearchClient) DeleteByFilter(ctx context.Context, collectionName
 		return -1, newError(ErrCodeProviderError, "DeleteByFilter requires at least one filter to prevent accidental full-collection deletion", nil)
 	}
 
-	query := buildESFilter(filters)
+	query, err := esFilter(filters)
+	if err != nil {
+		return -1, err
+	}
 	reqBody := map[string]interface{}{"query": query}
 
 	body, statusCode, err := c.doRequest(ctx, http.MethodPost, "/"+collectionName+"/_delete_by_query", reqBody)
@@ -439,7 +480,10 @@ func (c *elasticsearchClient) CountDocuments(ctx context.Context, collectionName
 		return 0, newError(ErrCodeInvalidCollectionName, "", nil)
 	}
 
-	query := buildESQuery(filters)
+	query, err := esFilter(filters)
+	if err != nil {
+		return 0, err
+	}
 	reqBody := map[string]interface{}{"query": query}
 
 	body, statusCode, err := c.doRequest(ctx, http.MethodPost, "/"+collectionName+"/_count", reqBody)
@@ -475,7 +519,10 @@ func (c *elasticsearchClient) ScrollDocuments(ctx context.Context, req ScrollReq
 		}
 	}
 
-	query := buildESQuery(req.Filters)
+	query, err := esFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	reqBody := map[string]interface{}{
 		"from":  from,
 		"size":  limit,
@@ -521,6 +568,10 @@ func (c *elasticsearchClient) VectorSearch(ctx context.Context, req SearchReques
 		return nil, err
 	}
 
+	filter, err := esFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	reqBody := map[string]interface{}{
 		"size": req.TopK,
 		"knn": map[string]interface{}{
@@ -532,7 +583,7 @@ func (c *elasticsearchClient) VectorSearch(ctx context.Context, req SearchReques
 	}
 	if len(req.Filters) > 0 {
 		knn := reqBody["knn"].(map[string]interface{})
-		knn["filter"] = buildESFilter(req.Filters)
+		knn["filter"] = filter
 	}
 
 	body, statusCode, err := c.doRequest(ctx, http.MethodPost, "/"+req.CollectionName+"/_search", reqBody)
@@ -580,6 +631,10 @@ func (c *elasticsearchClient) HybridSearch(ctx context.Context, req HybridSearch
 		return nil, newError(ErrCodeInvalidAlpha, "", nil)
 	}
 
+	filter, err := esFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	reqBody := map[string]interface{}{
 		"size": req.TopK,
 	}
@@ -593,7 +648,7 @@ func (c *elasticsearchClient) HybridSearch(ctx context.Context, req HybridSearch
 			"boost":          req.Alpha,
 		}
 		if len(req.Filters) > 0 {
-			knn["filter"] = buildESFilter(req.Filters)
+			knn["filter"] = filter
 		}
 		reqBody["knn"] = knn
 	}
@@ -611,7 +666,7 @@ func (c *elasticsearchClient) HybridSearch(ctx context.Context, req HybridSearch
 			},
 		}
 		if len(req.Filters) > 0 {
-			boolQuery["filter"] = []interface{}{buildESFilter(req.Filters)}
+			boolQuery["filter"] = []interface{}{filter}
 		}
 		reqBody["query"] = map[string]interface{}{"bool": boolQuery}
 	}
@@ -654,101 +709,68 @@ func (c *elasticsearchClient) HybridSearch(ctx context.Context, req HybridSearch
 
 // --- Helpers ---
 
-// buildESQuery converts filters to ES query. If filters nil/empty, returns match_all.
-func buildESQuery(filters map[string]interface{}) map[string]interface{} {
-	if len(filters) == 0 {
-		return map[string]interface{}{"match_all": map[string]interface{}{}}
+// esFilter validates filters and converts them to an ES query. Every filter
+// operator has an ES equivalent, so nothing is left to match client-side.
+func esFilter(filters map[string]interface{}) (map[string]interface{}, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return nil, err
 	}
-	return buildESFilter(filters)
+	return buildESFilter(expr), nil
 }
 
-// buildESFilter converts filter map to ES bool/must query.
-func buildESFilter(filters map[string]interface{}) map[string]interface{} {
-	if len(filters) == 0 {
+// buildESFilter converts a parsed filter to an ES bool query. A nil filter
+// returns match_all.
+func buildESFilter(expr *FilterExpr) map[string]interface{} {
+	if expr == nil {
 		return map[string]interface{}{"match_all": map[string]interface{}{}}
 	}
-
-	var must []interface{}
-	for key, value := range filters {
-		field := "metadata." + key
-		switch v := value.(type) {
-		case map[string]interface{}:
-			for op, opVal := range v {
-				switch op {
-				case "$eq":
-					must = append(must, map[string]interface{}{"term": map[string]interface{}{field: opVal}})
-				case "$ne":
-					must = append(must, map[string]interface{}{
-						"bool": map[string]interface{}{
-							"must_not": []interface{}{
-								map[string]interface{}{"term": map[string]interface{}{field: opVal}},
-							},
-						},
-					})
-				case "$gt":
-					must = append(must, map[string]interface{}{"range": map[string]interface{}{field: map[string]interface{}{"gt": opVal}}})
-				case "$gte":
-					must = append(must, map[string]interface{}{"range": map[string]interface{}{field: map[string]interface{}{"gte": opVal}}})
-				case "$lt":
-					must = append(must, map[string]interface{}{"range": map[string]interface{}{field: map[string]interface{}{"lt": opVal}}})
-				case "$lte":
-					must = append(must, map[string]interface{}{"range": map[string]interface{}{field: map[string]interface{}{"lte": opVal}}})
-				case "$in":
-					must = append(must, map[string]interface{}{"terms": map[string]interface{}{field: toInterfaceSlice(opVal)}})
-				case "$nin":
-					must = append(must, map[string]interface{}{
-						"bool": map[string]interface{}{
-							"must_not": []interface{}{
-								map[string]interface{}{"terms": map[string]interface{}{field: toInterfaceSlice(opVal)}},
-							},
-						},
-					})
-				}
-			}
-		default:
-			must = append(must, map[string]interface{}{"term": map[string]interface{}{field: v}})
+	field := "metadata." + expr.Field
+	switch expr.Op {
+	case FilterAnd:
+		return esBool("must", esClauses(expr.Children))
+	case FilterOr:
+		q := esBool("should", esClauses(expr.Children))
+		q["bool"].(map[string]interface{})["minimum_should_match"] = 1
+		return q
+	case FilterNot:
+		return esBool("must_not", esClauses(expr.Children))
+	case FilterEq, FilterContains:
+		return map[string]interface{}{"term": map[string]interface{}{field: expr.Value}}
+	case FilterNe:
+		return esBool("must_not", []interface{}{
+			map[string]interface{}{"term": map[string]interface{}{field: expr.Value}},
+		})
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		return map[string]interface{}{"range": map[string]interface{}{field: map[string]interface{}{expr.Op[1:]: expr.Value}}}
+	case FilterIn:
+		return map[string]interface{}{"terms": map[string]interface{}{field: expr.Values()}}
+	case FilterNin:
+		return esBool("must_not", []interface{}{
+			map[string]interface{}{"terms": map[string]interface{}{field: expr.Values()}},
+		})
+	case FilterExists:
+		exists := map[string]interface{}{"exists": map[string]interface{}{"field": field}}
+		if expr.Value == true {
+			return exists
 		}
+		return esBool("must_not", []interface{}{exists})
 	}
+	return map[string]interface{}{"match_all": map[string]interface{}{}}
+}
 
-	if len(must) == 1 {
-		return must[0].(map[string]interface{})
-	}
-	return map[string]interface{}{
-		"bool": map[string]interface{}{"must": must},
+func esClauses(children []*FilterExpr) []interface{} {
+	out := make([]interface{}, len(children))
+	for i, c := range children {
+		out[i] = buildESFilter(c)
 	}
+	return out
 }
 
-// toInterfaceSlice converts typed slices to []interface{} for ES terms queries.
-func toInterfaceSlice(v interface{}) []interface{} {
-	switch val := v.(type) {
-	case []interface{}:
-		return val
-	case []string:
-		out := make([]interface{}, len(val))
-		for i, s := range val {
-			out[i] = s
-		}
-		return out
-	case []int:
-		out := make([]interface{}, len(val))
-		for i, n := range val {
-			out[i] = n
-		}
-		return out
-	case []int64:
-		out := make([]interface{}, len(val))
-		for i, n := range val {
-			out[i] = n
-		}
-		return out
-	case []float64:
-		out := make([]interface{}, len(val))
-		for i, f := range val {
-			out[i] = f
-		}
-		return out
+func esBool(occur string, clauses []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{occur: clauses},
 	}
-	return []interface{}{v}
 }
 
 // parseSearchHits parses an ES _search response and extracts SearchResult slice.
@@ -0,0 +1,244 @@
+package vdbtransfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-elasticsearch"
+)
+
+// ClientFromConnection returns the VectorDBClient behind a connection of any
+// VectorDB connector — the value returned by connection.Manager
+// GetConnection().
+//
+// Every connector module declares its own copy of the vectordb types, so a
+// client from another module does not implement this module's
+// VectorDBClient. Such clients are wrapped in an adapter that calls their
+// methods by name and converts requests and results through JSON; the types
+// have the same fields in every module. The conversion costs one JSON round
+// trip per batch, which is small next to the network calls of a migration.
+func ClientFromConnection(conn interface{}) (vectordb.VectorDBClient, error) {
+	if c, ok := conn.(interface {
+		GetClient() vectordb.VectorDBClient
+	}); ok {
+		if client := c.GetClient(); client != nil {
+			return client, nil
+		}
+		return nil, fmt.Errorf("connection has no client")
+	}
+	if conn == nil {
+		return nil, fmt.Errorf("connection is nil")
+	}
+	getClient := reflect.ValueOf(conn).MethodByName("GetClient")
+	if !getClient.IsValid() || getClient.Type().NumIn() != 0 || getClient.Type().NumOut() != 1 {
+		return nil, fmt.Errorf("%T is not a VectorDB connection: it has no GetClient method", conn)
+	}
+	client := getClient.Call(nil)[0]
+	if client.Kind() == reflect.Interface {
+		if client.IsNil() {
+			return nil, fmt.Errorf("connection has no client")
+		}
+		client = client.Elem()
+	}
+	if native, ok := client.Interface().(vectordb.VectorDBClient); ok {
+		return native, nil
+	}
+	fc := &foreignClient{v: client}
+	for _, name := range clientMethods {
+		if !client.MethodByName(name).IsValid() {
+			return nil, fmt.Errorf("%s client has no %s method", client.Type(), name)
+		}
+	}
+	return fc, nil
+}
+
+// clientMethods lists the VectorDBClient methods the adapter forwards.
+var clientMethods = []string{
+	"CreateCollection", "DeleteCollection", "ListCollections", "CollectionExists",
+	"UpsertDocuments", "GetDocument", "DeleteDocuments", "DeleteByFilter",
+	"ScrollDocuments", "CountDocuments", "VectorSearch", "HybridSearch",
+	"HealthCheck", "DBType", "Close",
+}
+
+// foreignClient adapts a VectorDBClient declared by another connector module.
+type foreignClient struct {
+	v reflect.Value
+}
+
+// call invokes a method, converting each argument to the parameter type
+// when it is not assignable. The last result, if it is an error, is
+// returned separately.
+func (f *foreignClient) call(name string, args ...interface{}) ([]reflect.Value, error) {
+	m := f.v.MethodByName(name)
+	mt := m.Type()
+	if mt.NumIn() != len(args) {
+		return nil, fmt.Errorf("%s: expected %d arguments, got %d", name, mt.NumIn(), len(args))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		pt := mt.In(i)
+		av := reflect.ValueOf(arg)
+		switch {
+		case !av.IsValid():
+			in[i] = reflect.Zero(pt)
+		case av.Type().AssignableTo(pt):
+			in[i] = av
+		default:
+			p := reflect.New(pt)
+			if err := convert(arg, p.Interface()); err != nil {
+				return nil, fmt.Errorf("%s: argument %d: %w", name, i, err)
+			}
+			in[i] = p.Elem()
+		}
+	}
+	out := m.Call(in)
+	if n := len(out); n > 0 && mt.Out(n-1) == reflect.TypeOf((*error)(nil)).Elem() {
+		if err, _ := out[n-1].Interface().(error); err != nil {
+			return out[:n-1], err
+		}
+		out = out[:n-1]
+	}
+	return out, nil
+}
+
+// convert copies src into dst, a pointer, through JSON.
+func convert(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// result converts the first result of a call into dst.
+func result(out []reflect.Value, err error, dst interface{}) error {
+	if err != nil {
+		return err
+	}
+	return convert(out[0].Interface(), dst)
+}
+
+func (f *foreignClient) CreateCollection(ctx context.Context, cfg vectordb.CollectionConfig) error {
+	_, err := f.call("CreateCollection", ctx, cfg)
+	return err
+}
+
+func (f *foreignClient) DeleteCollection(ctx context.Context, name string) error {
+	_, err := f.call("DeleteCollection", ctx, name)
+	return err
+}
+
+func (f *foreignClient) ListCollections(ctx context.Context) ([]string, error) {
+	out, err := f.call("ListCollections", ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, _ := out[0].Interface().([]string)
+	return names, nil
+}
+
+func (f *foreignClient) CollectionExists(ctx context.Context, name string) (bool, error) {
+	out, err := f.call("CollectionExists", ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return out[0].Bool(), nil
+}
+
+func (f *foreignClient) UpsertDocuments(ctx context.Context, collectionName string, docs []vectordb.Document) error {
+	_, err := f.call("UpsertDocuments", ctx, collectionName, docs)
+	return err
+}
+
+func (f *foreignClient) GetDocument(ctx context.Context, collectionName, id string) (*vectordb.Document, error) {
+	var doc *vectordb.Document
+	out, err := f.call("GetDocument", ctx, collectionName, id)
+	if err := result(out, err, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (f *foreignClient) DeleteDocuments(ctx context.Context, collectionName string, ids []string) error {
+	_, err := f.call("DeleteDocuments", ctx, collectionName, ids)
+	return err
+}
+
+func (f *foreignClient) DeleteByFilter(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
+	out, err := f.call("DeleteByFilter", ctx, collectionName, filters)
+	if err != nil {
+		return 0, err
+	}
+	return out[0].Int(), nil
+}
+
+func (f *foreignClient) ScrollDocuments(ctx context.Context, req vectordb.ScrollRequest) (*vectordb.ScrollResult, error) {
+	var res *vectordb.ScrollResult
+	out, err := f.call("ScrollDocuments", ctx, req)
+	if err := result(out, err, &res); err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = &vectordb.ScrollResult{}
+	}
+	return res, nil
+}
+
+func (f *foreignClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
+	out, err := f.call("CountDocuments", ctx, collectionName, filters)
+	if err != nil {
+		return 0, err
+	}
+	return out[0].Int(), nil
+}
+
+func (f *foreignClient) VectorSearch(ctx context.Context, req vectordb.SearchRequest) ([]vectordb.SearchResult, error) {
+	var res []vectordb.SearchResult
+	out, err := f.call("VectorSearch", ctx, req)
+	if err := result(out, err, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (f *foreignClient) HybridSearch(ctx context.Context, req vectordb.HybridSearchRequest) ([]vectordb.SearchResult, error) {
+	var res []vectordb.SearchResult
+	out, err := f.call("HybridSearch", ctx, req)
+	if err := result(out, err, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (f *foreignClient) HealthCheck(ctx context.Context) error {
+	_, err := f.call("HealthCheck", ctx)
+	return err
+}
+
+func (f *foreignClient) DBType() string {
+	out, err := f.call("DBType")
+	if err != nil {
+		return ""
+	}
+	return out[0].String()
+}
+
+func (f *foreignClient) Close() error {
+	_, err := f.call("Close")
+	return err
+}
+
+// CollectionMetric forwards to the foreign client's CollectionMetric when
+// it has one, and reports "" otherwise.
+func (f *foreignClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	if !f.v.MethodByName("CollectionMetric").IsValid() {
+		return "", nil
+	}
+	out, err := f.call("CollectionMetric", ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return out[0].String(), nil
+}
@@ -59,6 +59,10 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	if input.ReplicationFactor <= 0 {
 		input.ReplicationFactor = 1
 	}
+	metadataFields, err := input.ToMetadataFields()
+	if err != nil {
+		return false, fmt.Errorf("vectordb-create-col: %w", err)
+	}
 
 	l.Debugf("CreateCollection: name=%s dims=%d metric=%s onDisk=%v replicas=%d",
 		input.CollectionName, input.Dimensions, input.DistanceMetric, input.OnDisk, input.ReplicationFactor)
@@ -88,6 +92,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		DistanceMetric:    input.DistanceMetric,
 		OnDisk:            input.OnDisk,
 		ReplicationFactor: input.ReplicationFactor,
+		MetadataFields:    metadataFields,
 	}
 	if createErr := a.conn.GetClient().CreateCollection(opCtx, cfg); createErr != nil {
 		// Treat "already exists" as success — idempotent create
@@ -112,7 +117,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	}
 
 	duration := time.Since(start)
-	l.Infof("CreateCollection: created collection=%s dims=%d duration=%s", input.CollectionName, input.Dimensions, duration)
+	l.Infof("CreateCollection: created collection=%s dims=%d metadataFields=%d duration=%s", input.CollectionName, input.Dimensions, len(metadataFields), duration)
 	if err := ctx.SetOutputObject(&Output{Success: true, Duration: duration.String()}); err != nil {
 		l.Errorf("SetOutputObject: %v", err)
 	}
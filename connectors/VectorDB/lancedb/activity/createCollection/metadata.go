@@ -1,8 +1,10 @@
 package createCollection
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-lancedb"
 	"github.com/project-flogo/core/support/connection"
 )
 
@@ -16,6 +18,9 @@ type Input struct {
 	DistanceMetric    string `md:"distanceMetric"`
 	OnDisk            bool   `md:"onDisk"`
 	ReplicationFactor int    `md:"replicationFactor"`
+	// MetadataFields declares payload keys stored as typed Arrow columns, e.g.
+	// [{"name": "year", "type": "int64"}].
+	MetadataFields []interface{} `md:"metadataFields"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
@@ -25,6 +30,7 @@ func (i *Input) ToMap() map[string]interface{} {
 		"distanceMetric":    i.DistanceMetric,
 		"onDisk":            i.OnDisk,
 		"replicationFactor": i.ReplicationFactor,
+		"metadataFields":    i.MetadataFields,
 	}
 }
 
@@ -54,9 +60,34 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 			i.ReplicationFactor = int(n)
 		}
 	}
+	if val, ok := v["metadataFields"]; ok && val != nil {
+		if arr, ok := val.([]interface{}); ok {
+			i.MetadataFields = arr
+		} else {
+			return fmt.Errorf("vectordb-create-col: 'metadataFields' must be an array")
+		}
+	}
 	return nil
 }
 
+// ToMetadataFields converts the generic metadataFields input to
+// []vectordb.MetadataField.
+func (i *Input) ToMetadataFields() ([]vectordb.MetadataField, error) {
+	fields := make([]vectordb.MetadataField, 0, len(i.MetadataFields))
+	for idx, raw := range i.MetadataFields {
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("metadataFields[%d]: cannot marshal: %w", idx, err)
+		}
+		var f vectordb.MetadataField
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, fmt.Errorf("metadataFields[%d]: %w", idx, err)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
 type Output struct {
 	Success  bool   `md:"success"`
 	Duration string `md:"duration"`
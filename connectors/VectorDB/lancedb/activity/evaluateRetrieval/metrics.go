@@ -0,0 +1,112 @@
+package evaluateRetrieval
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Retrieval metrics over one ranked list of document IDs. relevance maps
+// each relevant document ID to its grade (> 0); expectedIds are grade 1.
+
+// recallAtK returns the fraction of the relevant documents found in the
+// first k results.
+func recallAtK(ranked []string, relevance map[string]float64, k int) float64 {
+	if len(relevance) == 0 {
+		return 0
+	}
+	found := 0
+	for i, id := range ranked {
+		if i >= k {
+			break
+		}
+		if relevance[id] > 0 {
+			found++
+		}
+	}
+	return float64(found) / float64(len(relevance))
+}
+
+// reciprocalRank returns 1/rank of the first relevant result, or 0 when no
+// relevant document was retrieved.
+func reciprocalRank(ranked []string, relevance map[string]float64) float64 {
+	for i, id := range ranked {
+		if relevance[id] > 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// ndcgAtK returns the normalised discounted cumulative gain of the first k
+// results, using the grade as the gain and log2(rank+1) as the discount.
+func ndcgAtK(ranked []string, relevance map[string]float64, k int) float64 {
+	var dcg float64
+	for i, id := range ranked {
+		if i >= k {
+			break
+		}
+		dcg += relevance[id] / math.Log2(float64(i+2))
+	}
+	grades := make([]float64, 0, len(relevance))
+	for _, g := range relevance {
+		if g > 0 {
+			grades = append(grades, g)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(grades)))
+	var idcg float64
+	for i, g := range grades {
+		if i >= k {
+			break
+		}
+		idcg += g / math.Log2(float64(i+2))
+	}
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+// queryMetrics computes every metric of one query, keyed by output name.
+func queryMetrics(ranked []string, relevance map[string]float64, kValues []int) map[string]float64 {
+	m := make(map[string]float64, 2*len(kValues)+1)
+	m["mrr"] = reciprocalRank(ranked, relevance)
+	for _, k := range kValues {
+		m[fmt.Sprintf("recall@%d", k)] = recallAtK(ranked, relevance, k)
+		m[fmt.Sprintf("ndcg@%d", k)] = ndcgAtK(ranked, relevance, k)
+	}
+	return m
+}
+
+// latencyStats summarises per-query latencies in milliseconds. Percentiles
+// use the nearest-rank method.
+func latencyStats(latencies []time.Duration) map[string]interface{} {
+	if len(latencies) == 0 {
+		return map[string]interface{}{"p50Ms": 0.0, "p95Ms": 0.0, "p99Ms": 0.0, "meanMs": 0.0, "maxMs": 0.0}
+	}
+	ms := make([]float64, len(latencies))
+	var sum float64
+	for i, d := range latencies {
+		ms[i] = float64(d) / float64(time.Millisecond)
+		sum += ms[i]
+	}
+	sort.Float64s(ms)
+	return map[string]interface{}{
+		"p50Ms":  percentile(ms, 50),
+		"p95Ms":  percentile(ms, 95),
+		"p99Ms":  percentile(ms, 99),
+		"meanMs": sum / float64(len(ms)),
+		"maxMs":  ms[len(ms)-1],
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
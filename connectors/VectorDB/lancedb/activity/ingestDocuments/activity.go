@@ -23,6 +23,7 @@ func init() { _ = activity.Register(&Activity{}, New) }
 type Activity struct {
 	settings *Settings
 	conn     *vectordbconnector.LanceDBConnection
+	cache    vdbembed.EmbeddingCache // nil when embeddingCache is "none"
 }
 
 func (a *Activity) Metadata() *activity.Metadata { return activityMd }
@@ -90,9 +91,34 @@ func New(ctx activity.InitContext) (activity.Activity, error) {
 			return nil, fmt.Errorf("vectordb-ingest: chunking config invalid: %w", err)
 		}
 	}
-	ctx.Logger().Infof("IngestDocuments initialised: connection=%s provider=%s embeddingProvider=%s model=%s chunking=%v strategy=%s",
-		conn.GetName(), "lancedb", s.EmbeddingProvider, s.EmbeddingModel, s.EnableChunking, s.ChunkStrategy)
-	return &Activity{settings: s, conn: conn}, nil
+
+	switch s.IngestMode {
+	case "":
+		s.IngestMode = ingestModeFull
+	case ingestModeFull, ingestModeIncremental:
+	default:
+		return nil, fmt.Errorf("vectordb-ingest: ingestMode %q is invalid, expected \"full\" or \"incremental\"", s.IngestMode)
+	}
+
+	var cache vdbembed.EmbeddingCache
+	switch s.EmbeddingCache {
+	case "", "none":
+		s.EmbeddingCache = "none"
+	case "memory":
+		cache = vdbembed.NewMemoryCache(s.EmbeddingCacheSize)
+	case "disk":
+		dc, err := vdbembed.NewDiskCache(s.EmbeddingCacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("vectordb-ingest: %w", err)
+		}
+		cache = dc
+	default:
+		return nil, fmt.Errorf("vectordb-ingest: embeddingCache %q is invalid, expected \"none\", \"memory\" or \"disk\"", s.EmbeddingCache)
+	}
+
+	ctx.Logger().Infof("IngestDocuments initialised: connection=%s provider=%s embeddingProvider=%s model=%s chunking=%v strategy=%s ingestMode=%s embeddingCache=%s",
+		conn.GetName(), "lancedb", s.EmbeddingProvider, s.EmbeddingModel, s.EnableChunking, s.ChunkStrategy, s.IngestMode, s.EmbeddingCache)
+	return &Activity{settings: s, conn: conn, cache: cache}, nil
 }
 
 func (a *Activity) Eval(ctx activity.Context) (bool, error) {
@@ -141,6 +167,12 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	}
 
 	sourceDocCount := len(rawDocs)
+	incremental := a.settings.IngestMode == ingestModeIncremental
+	if incremental {
+		if err := stampSources(rawDocs); err != nil {
+			return false, fmt.Errorf("vectordb-ingest: %w", err)
+		}
+	}
 	l.Debugf("IngestDocuments: collection=%s source_doc_count=%d fileName=%s", collectionName, sourceDocCount, input.FileName)
 
 	// ── Optional chunking ────────────────────────────────────────────────────
@@ -182,6 +214,34 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 
 	start := time.Now()
 
+	// -----------------------------------------------------------------------
+	// Incremental mode: assign deterministic chunk IDs and drop the chunks
+	// already stored unchanged, so only new or modified chunks are embedded.
+	// -----------------------------------------------------------------------
+	var plan *incrementalPlan
+	if incremental {
+		var planErr error
+		plan, planErr = planIncremental(opCtx, a.conn.GetClient(), collectionName, rawDocs)
+		if planErr != nil {
+			l.Errorf("IngestDocuments: incremental planning failed: collection=%s error=%v", collectionName, planErr)
+			if tc != nil {
+				tc.SetTag("error", true)
+				tc.LogKV(map[string]interface{}{"event": "error", "message": planErr.Error()})
+			}
+			if err := ctx.SetOutputObject(&Output{
+				Success:  false,
+				Error:    fmt.Sprintf("incremental ingest failed: %v", planErr),
+				Duration: time.Since(start).String(),
+			}); err != nil {
+				l.Errorf("SetOutputObject: %v", err)
+			}
+			return true, nil
+		}
+		rawDocs = plan.Changed
+		l.Debugf("IngestDocuments: incremental chunks=%d changed=%d skipped=%d duplicates=%d stale=%d",
+			len(plan.IDs), len(plan.Changed), plan.Skipped, plan.Duplicates, plan.staleCount)
+	}
+
 	// -----------------------------------------------------------------------
 	// Step 1: Generate embeddings in batches to avoid API payload/rate limits.
 	// -----------------------------------------------------------------------
@@ -198,6 +258,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 	allEmbeddings := make([][]float64, 0, len(texts))
 	totalTokens := 0
 	embDimensions := 0
+	cacheHits := 0
 
 	for batchStart := 0; batchStart < len(texts); batchStart += batchSize {
 		batchEnd := batchStart + batchSize
@@ -213,7 +274,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 			Dimensions: a.settings.EmbeddingDimensions,
 			InputType:  "search_document", // Cohere: optimise for indexing, not querying
 		}
-		embResp, embErr := vdbembed.CreateEmbeddings(opCtx, embReq)
+		embResp, embErr := vdbembed.CreateEmbeddingsCached(opCtx, embReq, a.cache)
 		if embErr != nil {
 			l.Errorf("IngestDocuments: embedding batch %d-%d failed: collection=%s error=%v",
 				batchStart, batchEnd, collectionName, embErr)
@@ -232,13 +293,14 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		}
 		allEmbeddings = append(allEmbeddings, embResp.Embeddings...)
 		totalTokens += embResp.TokensUsed
+		cacheHits += embResp.CacheHits
 		if embDimensions == 0 {
 			embDimensions = embResp.Dimensions
 		}
 	}
 
-	l.Debugf("IngestDocuments: embedded %d texts dimensions=%d tokens=%d elapsed=%s",
-		len(rawDocs), embDimensions, totalTokens, time.Since(start))
+	l.Debugf("IngestDocuments: embedded %d texts dimensions=%d tokens=%d cacheHits=%d elapsed=%s",
+		len(rawDocs), embDimensions, totalTokens, cacheHits, time.Since(start))
 
 	// -----------------------------------------------------------------------
 	// Step 2: Build vectordb.Document slice — assign IDs and attach vectors.
@@ -299,26 +361,57 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		}
 	}
 
-	duration := time.Since(start)
-	l.Infof("IngestDocuments: success collection=%s ingested=%d dimensions=%d duration=%s",
-		collectionName, len(docs), embDimensions, duration)
-
-	if err := ctx.SetOutputObject(&Output{
+	// -----------------------------------------------------------------------
+	// Step 4 (incremental): delete stored chunks the sources no longer produce.
+	// Runs after the upsert so a failure never leaves a source without chunks.
+	// -----------------------------------------------------------------------
+	out := &Output{
 		Success:             true,
 		IngestedCount:       len(docs),
 		IDs:                 ids,
 		Dimensions:          embDimensions,
-		Duration:            duration.String(),
 		SourceDocumentCount: sourceDocCount,
 		ChunksCreated:       len(docs),
-	}); err != nil {
+		CacheHits:           cacheHits,
+	}
+	if plan != nil {
+		deleted, delErr := plan.deleteStale(opCtx, a.conn.GetClient(), collectionName)
+		if delErr != nil {
+			l.Errorf("IngestDocuments: stale chunk deletion failed: collection=%s error=%v", collectionName, delErr)
+			if tc != nil {
+				tc.SetTag("error", true)
+				tc.LogKV(map[string]interface{}{"event": "error", "message": delErr.Error()})
+			}
+			if err := ctx.SetOutputObject(&Output{
+				Success:  false,
+				Error:    fmt.Sprintf("stale chunk deletion failed after %d deletion(s): %v", deleted, delErr),
+				Duration: time.Since(start).String(),
+			}); err != nil {
+				l.Errorf("SetOutputObject: %v", err)
+			}
+			return true, nil
+		}
+		out.IDs = plan.IDs
+		out.ChunksCreated = len(plan.IDs)
+		out.SkippedCount = plan.Skipped
+		out.DeletedCount = deleted
+	}
+
+	duration := time.Since(start)
+	out.Duration = duration.String()
+	l.Infof("IngestDocuments: success collection=%s ingested=%d skipped=%d deleted=%d dimensions=%d duration=%s",
+		collectionName, out.IngestedCount, out.SkippedCount, out.DeletedCount, embDimensions, duration)
+
+	if err := ctx.SetOutputObject(out); err != nil {
 		l.Errorf("SetOutputObject: %v", err)
 	}
 	return true, nil
 }
 
 // parseFiles converts a files[]interface{} input array into RawDocument slice by
-// extracting text from binary documents (PDF, DOCX, TXT, MD).
+// extracting text from binary documents (PDF, DOCX, PPTX, XLSX, CSV, HTML, EML,
+// JSON, TXT, MD). The extracted sections are kept on the document so chunking
+// can attach page/slide/sheet metadata.
 // Each item must have "name" (string) and "content" (base64 string or []byte).
 func parseFiles(files []interface{}, l interface {
 	Debugf(string, ...interface{})
@@ -349,24 +442,31 @@ func parseFiles(files []interface{}, l interface {
 			return nil, fmt.Errorf("files[%d] (%s): cannot decode content: %w", idx, name, err)
 		}
 
-		text, err := ExtractTextFromBytes(data, name)
+		extracted, err := ExtractDocument(data, name)
 		if err != nil {
 			return nil, fmt.Errorf("files[%d] (%s): text extraction failed: %w", idx, name, err)
 		}
+		text := extracted.Text()
 		if text == "" {
 			return nil, fmt.Errorf("files[%d] (%s): no text could be extracted — is this a scanned/image PDF?", idx, name)
 		}
 
-		l.Debugf("parseFiles: extracted %d chars from file=%s", len(text), name)
+		l.Debugf("parseFiles: extracted %d chars in %d section(s) from file=%s", len(text), len(extracted.Sections), name)
 
 		doc := RawDocument{
-			Text: text,
-			// Default metadata: source filename and type; caller may override via "metadata" field.
+			Text:     text,
+			Sections: extracted.Sections,
+			// Default metadata: source filename and type, then document-level
+			// fields from the extractor (title, e-mail headers); caller may
+			// override any of them via the "metadata" field.
 			Metadata: map[string]interface{}{
 				"source": name,
 				"type":   strings.TrimPrefix(filepath.Ext(name), "."),
 			},
 		}
+		for k, v := range extracted.Metadata {
+			doc.Metadata[k] = v
+		}
 		if id, ok := m["id"]; ok && id != nil {
 			doc.ID = fmt.Sprintf("%v", id)
 		}
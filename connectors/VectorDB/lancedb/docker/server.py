@@ -6,9 +6,11 @@ vectordb-lancedb connector:
 
   GET  /v1/table/                      list_tables
   GET  /v1/table/{name}/               describe table (200) or 404
+  POST /v1/table/{name}/describe/      table version and Arrow schema JSON
   POST /v1/table/{name}/               create table (schema JSON or empty-data fallback)
   DELETE /v1/table/{name}/             drop table
   GET  /v1/table/{name}/count_rows/    count rows (returns plain integer)
+  POST /v1/table/{name}/count_rows/    count rows matching {"predicate": "..."}
   POST /v1/table/{name}/insert/        append records
   POST /v1/table/{name}/merge_insert/  upsert records (on=id by default)
   POST /v1/table/{name}/delete/        delete by SQL predicate
@@ -17,6 +19,7 @@ vectordb-lancedb connector:
 
 import os
 import json
+from datetime import datetime, timezone
 import pyarrow as pa
 import lancedb
 from fastapi import FastAPI, HTTPException, Request
@@ -64,6 +67,17 @@ def describe_table(name: str):
     return {"name": name, "rows": tbl.count_rows()}
 
 
+@app.post("/v1/table/{name}/describe/")
+def describe_schema(name: str):
+    db = _db()
+    tbl = _open_or_404(db, name)
+    return {
+        "table":   name,
+        "version": tbl.version,
+        "schema":  {"fields": [_field_to_json(f) for f in tbl.schema]},
+    }
+
+
 @app.post("/v1/table/{name}/")
 async def create_table(name: str, request: Request):
     db = _db()
@@ -89,11 +103,17 @@ async def create_table(name: str, request: Request):
             detail="Cannot determine embedding dimensions from request body",
         )
 
+    try:
+        extra = _metadata_fields(body)
+    except ValueError as exc:
+        raise HTTPException(status_code=422, detail=str(exc)) from exc
+
     schema = pa.schema([
         pa.field("id",        pa.utf8(),                       nullable=False),
         pa.field("content",   pa.utf8(),                       nullable=True),
         pa.field("metadata",  pa.utf8(),                       nullable=True),
         pa.field("embedding", pa.list_(pa.float32(), dims),    nullable=True),
+        *extra,
     ])
     db.create_table(name, schema=schema)
     return {"name": name, "status": "created", "dimensions": dims}
@@ -120,6 +140,19 @@ def count_rows(name: str):
     return PlainTextResponse(str(tbl.count_rows()))
 
 
+@app.post("/v1/table/{name}/count_rows/")
+async def count_rows_filtered(name: str, request: Request):
+    db = _db()
+    tbl = _open_or_404(db, name)
+    body = await request.json()
+    predicate = (body.get("predicate") or "").strip()
+    try:
+        n = tbl.count_rows(predicate or None)
+    except Exception as exc:
+        raise HTTPException(status_code=400, detail=f"Count error: {exc}") from exc
+    return PlainTextResponse(str(n))
+
+
 @app.post("/v1/table/{name}/insert/")
 async def insert(name: str, request: Request):
     db = _db()
@@ -205,6 +238,8 @@ async def query_table(name: str, request: Request):
                     # Index may already exist or creation failed; attempt search anyway.
                     _fts_indexed.add(fts_key)
             q = tbl.search(fts_query, query_type="fts").limit(limit)
+            if filter_sql:
+                q = q.where(filter_sql, prefilter=prefilter)
             rows = q.to_list()
 
         else:
@@ -238,6 +273,69 @@ def _extract_dims(body: dict) -> int | None:
     return None
 
 
+# Arrow JSON type names accepted for metadata columns, as sent by the
+# connector's CreateCollection.
+_ARROW_TYPES = {
+    "utf8":    pa.utf8(),
+    "string":  pa.utf8(),
+    "int32":   pa.int32(),
+    "int64":   pa.int64(),
+    "float32": pa.float32(),
+    "float":   pa.float32(),
+    "float64": pa.float64(),
+    "double":  pa.float64(),
+    "bool":    pa.bool_(),
+    "boolean": pa.bool_(),
+}
+
+_BUILTIN_COLUMNS = {"id", "content", "metadata", "embedding"}
+
+
+def _metadata_fields(body: dict) -> list:
+    """Build the typed metadata columns declared after the built-in ones."""
+    fields = []
+    for field in body.get("schema", {}).get("fields", []):
+        name = field.get("name")
+        if name in _BUILTIN_COLUMNS:
+            continue
+        ft = field.get("type", {})
+        type_name = ft.get("type") if isinstance(ft, dict) else ft
+        if type_name == "timestamp":
+            arrow_type = pa.timestamp("us")
+        elif type_name in _ARROW_TYPES:
+            arrow_type = _ARROW_TYPES[type_name]
+        else:
+            raise ValueError(f"unsupported type {type_name!r} for column {name!r}")
+        fields.append(pa.field(name, arrow_type, nullable=True))
+    return fields
+
+
+def _field_to_json(field: pa.Field) -> dict:
+    """Describe an Arrow field in the JSON form used by create_table."""
+    t = field.type
+    if pa.types.is_fixed_size_list(t):
+        ft = {"type": "fixed_size_list", "list_size": t.list_size}
+    elif pa.types.is_timestamp(t):
+        ft = {"type": "timestamp", "unit": "microsecond"}
+    elif pa.types.is_string(t):
+        ft = {"type": "utf8"}
+    elif pa.types.is_boolean(t):
+        ft = {"type": "bool"}
+    else:
+        ft = {"type": str(t)}  # int32, int64, float, double, ...
+    return {"name": field.name, "type": ft, "nullable": field.nullable}
+
+
+def _to_timestamp(value):
+    """Parse an ISO-8601 string (the connector sends zone-less UTC) to a naive UTC datetime."""
+    if value is None or isinstance(value, datetime):
+        return value
+    dt = datetime.fromisoformat(str(value).replace("Z", "+00:00"))
+    if dt.tzinfo is not None:
+        dt = dt.astimezone(timezone.utc).replace(tzinfo=None)
+    return dt
+
+
 def _to_arrow(schema: pa.Schema, records: list) -> pa.Table:
     """Convert a list of dict records to a PyArrow table matching *schema*."""
     emb_field = schema.field("embedding")
@@ -260,12 +358,21 @@ def _to_arrow(schema: pa.Schema, records: list) -> pa.Table:
         else:
             embeddings.append([0.0] * (dims or 1))
 
-    return pa.table({
+    columns = {
         "id":        pa.array(ids,        type=pa.utf8()),
         "content":   pa.array(contents,   type=pa.utf8()),
         "metadata":  pa.array(metadatas,  type=pa.utf8()),
         "embedding": pa.array(embeddings, type=emb_field.type),
-    })
+    }
+    # Typed metadata columns: missing keys become nulls.
+    for field in schema:
+        if field.name in columns:
+            continue
+        values = [rec.get(field.name) for rec in records]
+        if pa.types.is_timestamp(field.type):
+            values = [_to_timestamp(v) for v in values]
+        columns[field.name] = pa.array(values, type=field.type)
+    return pa.table(columns, schema=schema)
 
 
 def _row_to_dict(row: dict) -> dict:
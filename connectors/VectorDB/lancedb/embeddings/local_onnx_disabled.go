@@ -0,0 +1,13 @@
+//go:build !onnx
+
+package vdbembed
+
+import "errors"
+
+// errONNXNotBuilt is returned by the Local provider in binaries built
+// without the onnx build tag, which links the ONNX Runtime bindings.
+var errONNXNotBuilt = errors.New("the Local provider needs a binary built with -tags onnx")
+
+func newONNXRunner(modelPath string, batchSize, seqLen, hidden int, useTypeIDs bool) (localRunner, error) {
+	return nil, errONNXNotBuilt
+}
@@ -16,6 +16,7 @@ const (
 	ErrCodeInvalidDimensions     = "VDB-COL-2003"
 	ErrCodeInvalidMetric         = "VDB-COL-2004"
 	ErrCodeInvalidCollectionName = "VDB-COL-2005"
+	ErrCodeInvalidMetadataField  = "VDB-COL-2006"
 
 	// Document errors
 	ErrCodeDocumentNotFound  = "VDB-DOC-3001"
@@ -29,6 +30,7 @@ const (
 	ErrCodeInvalidTopK        = "VDB-SRH-4002"
 	ErrCodeInvalidAlpha       = "VDB-SRH-4003"
 	ErrCodeHybridNotSupported = "VDB-SRH-4004"
+	ErrCodeInvalidFilter      = "VDB-SRH-4005"
 
 	// Connection / provider errors
 	ErrCodeConnectionFailed  = "VDB-CON-5001"
@@ -55,6 +57,7 @@ var ErrorMessages = map[string]string{
 	ErrCodeInvalidDimensions:     "Dimensions must be greater than 0",
 	ErrCodeInvalidMetric:         "DistanceMetric must be one of: cosine, dot, euclidean",
 	ErrCodeInvalidCollectionName: "Collection name must not be empty",
+	ErrCodeInvalidMetadataField:  "Metadata field declaration or value is invalid",
 	ErrCodeDocumentNotFound:      "Document not found",
 	ErrCodeInvalidVector:         "Vector is nil or empty",
 	ErrCodeEmptyDocumentList:     "Document list must not be empty",
@@ -64,6 +67,7 @@ var ErrorMessages = map[string]string{
 	ErrCodeInvalidTopK:           "TopK must be greater than 0",
 	ErrCodeInvalidAlpha:          "Alpha must be between 0.0 and 1.0",
 	ErrCodeHybridNotSupported:    "This provider does not support native hybrid search",
+	ErrCodeInvalidFilter:         "Filter cannot be translated to a LanceDB predicate",
 	ErrCodeConnectionFailed:      "Failed to establish connection to vector database",
 	ErrCodeConnectionTimeout:     "Connection to vector database timed out",
 	ErrCodeAuthFailed:            "Authentication failed — check credentials",
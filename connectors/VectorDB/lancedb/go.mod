@@ -2,6 +2,10 @@ module github.com/mpandav-tibco/flogo-extensions/vectordb-lancedb
 
 go 1.25
 
+// pure-onnx is the real module: the Local embedding provider runs models with
+// pure-onnx/ort in binaries built with -tags onnx. It loads the onnxruntime
+// shared library with ebitengine/purego, which builds with CGO_ENABLED=0.
+
 toolchain go1.25.9
 
 require (
@@ -9,14 +13,33 @@ require (
 	github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
 	github.com/project-flogo/core v1.6.18
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/net v0.50.0
 )
 
 require (
+	github.com/andybalholm/brotli v1.1.1 // indirect
 	github.com/araddon/dateparse v0.0.0-20190622164848-0fb0a474d195 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/ebitengine/purego v0.10.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/parquet-go/bitpack v1.0.0 // indirect
+	github.com/parquet-go/jsonlite v1.0.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/twpayne/go-geom v1.6.1 // indirect
 	go.uber.org/atomic v1.6.0 // indirect
 	go.uber.org/multierr v1.5.0 // indirect
 	go.uber.org/zap v1.16.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+require (
+	github.com/amikos-tech/pure-onnx v0.0.1
+	github.com/mpandav-tibco/flogo-custom-extensions/sse v0.0.0-00010101000000-000000000000
+	github.com/parquet-go/parquet-go v0.32.0
+	golang.org/x/text v0.34.0
+)
+
+replace github.com/mpandav-tibco/flogo-custom-extensions/sse => ../../sse
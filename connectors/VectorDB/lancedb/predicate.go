@@ -0,0 +1,231 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// predicate returns the SQL predicate for filters on the named table and the
+// residual filter the predicate cannot express. Conditions on typed metadata
+// columns compile to SQL comparisons; the residual is matched client-side
+// against the payloads of the returned rows, after a LIKE prefilter on the
+// JSON metadata string narrows them down.
+func (c *lanceDBClient) predicate(ctx context.Context, table string, filters map[string]interface{}) (string, *FilterExpr, error) {
+	if len(filters) == 0 {
+		return "", nil, nil
+	}
+	return lanceDBFilter(filters, c.tableColumns(ctx, table))
+}
+
+// lanceDBFilter parses filters and splits them into a LanceDB SQL predicate
+// and a residual filter.
+//
+// On typed columns, equality and $ne use = and <>, $gt/$gte/$lt/$lte become
+// range comparisons, $in/$nin become IN lists and $exists becomes IS [NOT]
+// NULL; $and, $or and $not over such conditions map to AND, OR and NOT. $ne
+// and $nin also match rows where the column is null, like the other
+// connectors' "must not".
+//
+// Everything else — keys without a typed column, nested paths and $contains —
+// is left in the residual. Its top-level $eq, $in and $contains conditions
+// are also added to the predicate as LIKE matches on the metadata string;
+// these match a superset of the rows and the residual decides.
+func lanceDBFilter(filters map[string]interface{}, columns lanceDBColumns) (string, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return "", nil, err
+	}
+	pushed, residual := splitFilter(expr, func(e *FilterExpr) bool {
+		return lanceDBNative(e, columns)
+	})
+	var clauses []string
+	if pushed != nil {
+		clause, err := buildLanceDBPredicate(pushed, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+	if residual != nil {
+		for _, c := range residual.Conjuncts() {
+			if like := lanceDBLikeClause(c); like != "" {
+				clauses = append(clauses, like)
+			}
+		}
+	}
+	return strings.Join(clauses, " AND "), residual, nil
+}
+
+// lanceDBNative reports whether e only references typed columns with
+// operators a SQL predicate supports.
+func lanceDBNative(e *FilterExpr, columns lanceDBColumns) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterAnd, FilterOr, FilterNot:
+			return true
+		case FilterContains:
+			return false
+		}
+		_, typed := columns[n.Field]
+		return typed
+	})
+}
+
+// buildLanceDBPredicate converts a parsed filter on typed columns to a
+// LanceDB SQL predicate. A literal that does not fit its column's type is
+// rejected with ErrCodeInvalidFilter.
+func buildLanceDBPredicate(e *FilterExpr, columns lanceDBColumns) (string, error) {
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		parts := make([]string, len(e.Children))
+		for i, c := range e.Children {
+			part, err := buildLanceDBPredicate(c, columns)
+			if err != nil {
+				return "", err
+			}
+			if e.Op == FilterOr && c.Op == FilterAnd {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		if e.Op == FilterOr {
+			return "(" + strings.Join(parts, " OR ") + ")", nil
+		}
+		return strings.Join(parts, " AND "), nil
+	case FilterNot:
+		inner, err := buildLanceDBPredicate(e.Children[0], columns)
+		if err != nil {
+			return "", err
+		}
+		// IS NOT TRUE rather than NOT, so rows where the inner condition is
+		// null (a missing column) match, as they do client-side.
+		return "(" + inner + ") IS NOT TRUE", nil
+	}
+	clause, err := lanceDBTypedClause(e.Field, columns[e.Field], e)
+	if err != nil {
+		return "", newError(ErrCodeInvalidFilter, fmt.Sprintf("filter %q: %v", e.Field, err), nil)
+	}
+	return clause, nil
+}
+
+// lanceDBTypedClause builds the SQL comparison for one condition on a typed
+// metadata column.
+func lanceDBTypedClause(column string, kind columnKind, e *FilterExpr) (string, error) {
+	ident := "`" + column + "`"
+	switch e.Op {
+	case FilterEq:
+		lit, err := sqlLiteral(kind, e.Value)
+		if err != nil {
+			return "", err
+		}
+		return ident + " = " + lit, nil
+	case FilterNe:
+		lit, err := sqlLiteral(kind, e.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s <> %s)", ident, ident, lit), nil
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		lit, err := sqlLiteral(kind, e.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", ident, rangeOperators[e.Op], lit), nil
+	case FilterIn, FilterNin:
+		items := e.Values()
+		lits := make([]string, len(items))
+		for i, item := range items {
+			lit, err := sqlLiteral(kind, item)
+			if err != nil {
+				return "", err
+			}
+			lits[i] = lit
+		}
+		list := strings.Join(lits, ", ")
+		if e.Op == FilterIn {
+			if len(lits) == 0 {
+				return "FALSE", nil
+			}
+			return fmt.Sprintf("%s IN (%s)", ident, list), nil
+		}
+		if len(lits) == 0 {
+			return "TRUE", nil
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", ident, ident, list), nil
+	case FilterExists:
+		if e.Value == true {
+			return ident + " IS NOT NULL", nil
+		}
+		return ident + " IS NULL", nil
+	}
+	return "", fmt.Errorf("unsupported operator %q", e.Op)
+}
+
+// rangeOperators maps range filter operators to SQL.
+var rangeOperators = map[string]string{
+	FilterGt: ">", FilterGte: ">=", FilterLt: "<", FilterLte: "<=",
+}
+
+// lanceDBLikeClause returns the LIKE prefilter for a residual condition, or
+// "" when it has none. Nested paths are matched on their last segment, which
+// only narrows the rows the residual is evaluated on.
+func lanceDBLikeClause(e *FilterExpr) string {
+	if e.IsLogical() {
+		return ""
+	}
+	path := e.Path()
+	key := path[len(path)-1]
+	switch e.Op {
+	case FilterEq:
+		return "(" + lanceDBLikeEq(key, e.Value) + ")"
+	case FilterIn:
+		items := e.Values()
+		if len(items) == 0 {
+			return ""
+		}
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = lanceDBLikeEq(key, item)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")"
+	case FilterContains:
+		return lanceDBLike(fmt.Sprintf(`%%"%s":[%%%s%%`, lanceDBLikeKey(key), lanceDBLikeValue(e.Value)))
+	}
+	return ""
+}
+
+// lanceDBLikeEq matches key=value in the metadata string, either as a scalar
+// or as an element of an array value.
+func lanceDBLikeEq(key string, val interface{}) string {
+	k, v := lanceDBLikeKey(key), lanceDBLikeValue(val)
+	return lanceDBLike(fmt.Sprintf(`%%"%s":%s%%`, k, v)) + " OR " +
+		lanceDBLike(fmt.Sprintf(`%%"%s":[%%%s%%`, k, v))
+}
+
+// lanceDBLike builds a LIKE clause on the metadata column.
+func lanceDBLike(pattern string) string {
+	return "metadata LIKE '" + strings.ReplaceAll(pattern, "'", "''") + "'"
+}
+
+// lanceDBLikeKey returns a payload key as it appears inside the metadata
+// string, without the surrounding quotes.
+func lanceDBLikeKey(key string) string {
+	b, _ := json.Marshal(key)
+	return string(b[1 : len(b)-1])
+}
+
+// lanceDBLikeValue formats a value as it appears inside the metadata string.
+// UpsertDocuments encodes payloads with encoding/json, so numbers and
+// strings are formatted the same way here.
+func lanceDBLikeValue(val interface{}) string {
+	if f, ok := filterNumber(val); ok {
+		val = f
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%v", val))
+	}
+	return string(b)
+}
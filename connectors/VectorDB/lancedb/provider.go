@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,6 +18,10 @@ import (
 type lanceDBClient struct {
 	cfg        ConnectionConfig
 	httpClient *http.Client
+
+	// schemas caches the typed metadata columns of each table (see tableColumns).
+	schemaMu sync.RWMutex
+	schemas  map[string]lanceDBColumns
 }
 
 // Compile-time proof that lanceDBClient satisfies the full VectorDBClient interface.
@@ -26,6 +31,7 @@ func newLanceDBClient(_ context.Context, cfg ConnectionConfig) (VectorDBClient,
 	return &lanceDBClient{
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		schemas:    make(map[string]lanceDBColumns),
 	}, nil
 }
 
@@ -176,11 +182,12 @@ func (c *lanceDBClient) CreateCollection(ctx context.Context, cfg CollectionConf
 
 	endpoint := fmt.Sprintf("%s/v1/table/%s/", c.baseURL(), cfg.Name)
 
-	// Build Arrow-like schema body for LanceDB REST API.
+	// Build Arrow-like schema body for LanceDB REST API. Declared metadata
+	// fields follow the four built-in columns.
 	schemaBody := map[string]interface{}{
 		"schema": map[string]interface{}{
 			"type": "struct",
-			"fields": []interface{}{
+			"fields": append([]interface{}{
 				map[string]interface{}{
 					"name":     "id",
 					"type":     map[string]interface{}{"type": "utf8"},
@@ -211,7 +218,7 @@ func (c *lanceDBClient) CreateCollection(ctx context.Context, cfg CollectionConf
 					},
 					"nullable": true,
 				},
-			},
+			}, metadataSchemaFields(cfg.MetadataFields)...),
 		},
 	}
 
@@ -255,6 +262,7 @@ func (c *lanceDBClient) CreateCollection(ctx context.Context, cfg CollectionConf
 		}
 		return newError(ErrCodeProviderError, "CreateCollection failed", err)
 	}
+	c.forgetTableColumns(cfg.Name)
 	return nil
 }
 
@@ -281,6 +289,7 @@ func (c *lanceDBClient) DeleteCollection(ctx context.Context, name string) error
 	}); err != nil {
 		return err
 	}
+	c.forgetTableColumns(name)
 	return nil
 }
 
@@ -339,6 +348,16 @@ func (c *lanceDBClient) UpsertDocuments(ctx context.Context, collectionName stri
 		return err
 	}
 
+	// Typed metadata columns are only looked up when some document carries
+	// a payload to fill them from; otherwise they are left null.
+	var columns lanceDBColumns
+	for _, doc := range docs {
+		if len(doc.Payload) > 0 {
+			columns = c.tableColumns(ctx, collectionName)
+			break
+		}
+	}
+
 	// Build records array for LanceDB.
 	records := make([]map[string]interface{}, len(docs))
 	for i, doc := range docs {
@@ -348,6 +367,14 @@ func (c *lanceDBClient) UpsertDocuments(ctx context.Context, collectionName stri
 			"metadata":  payloadToJSON(doc.Payload),
 			"embedding": toFloat32Slice(doc.Vector),
 		}
+		for name, kind := range columns {
+			v, err := columnValue(kind, doc.Payload[name])
+			if err != nil {
+				return newError(ErrCodeInvalidMetadataField,
+					fmt.Sprintf("document %q: metadata field %q: %v", doc.ID, name, err), nil)
+			}
+			rec[name] = v
+		}
 		records[i] = rec
 	}
 
@@ -453,7 +480,9 @@ func (c *lanceDBClient) DeleteDocuments(ctx context.Context, collectionName stri
 }
 
 // DeleteByFilter removes documents matching the given filter.
-// Returns -1 because LanceDB does not report a delete count.
+// Returns -1 because LanceDB does not report a delete count, except when part
+// of the filter is matched client-side: the matching IDs are then collected
+// by a scan and deleted by ID.
 func (c *lanceDBClient) DeleteByFilter(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
 	if collectionName == "" {
 		return 0, newError(ErrCodeInvalidCollectionName, "", nil)
@@ -462,7 +491,23 @@ func (c *lanceDBClient) DeleteByFilter(ctx context.Context, collectionName strin
 		return 0, newError(ErrCodeProviderError, "DeleteByFilter requires at least one filter", nil)
 	}
 
-	predicate := buildLanceDBPredicate(filters)
+	predicate, residual, err := c.predicate(ctx, collectionName, filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	endpoint := fmt.Sprintf("%s/v1/table/%s/delete/", c.baseURL(), collectionName)
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
 		_, err := c.doRequestChecked(ctx, http.MethodPost, endpoint,
@@ -474,44 +519,34 @@ func (c *lanceDBClient) DeleteByFilter(ctx context.Context, collectionName strin
 	return -1, nil // LanceDB does not report count
 }
 
-// CountDocuments returns the row count for the collection.
+// CountDocuments returns the row count for the collection. With filters the
+// predicate is evaluated by the server's count_rows endpoint; a filter with a
+// residual part is counted by scanning the matching documents.
 func (c *lanceDBClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
 	if collectionName == "" {
 		return 0, newError(ErrCodeInvalidCollectionName, "", nil)
 	}
 
-	// If filters are provided, use query to count results.
+	endpoint := fmt.Sprintf("%s/v1/table/%s/count_rows/", c.baseURL(), collectionName)
+	method, reqBody := http.MethodGet, interface{}(nil)
 	if len(filters) > 0 {
-		predicate := buildLanceDBPredicate(filters)
-		endpoint := fmt.Sprintf("%s/v1/table/%s/query/", c.baseURL(), collectionName)
-		reqBody := map[string]interface{}{
-			"prefilter": true,
-			"filter":    predicate,
-			"limit":     1_000_000, // large limit to count all
+		predicate, residual, err := c.predicate(ctx, collectionName, filters)
+		if err != nil {
+			return 0, err
 		}
-		var count int64
-		if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
-			body, err := c.doRequestChecked(ctx, http.MethodPost, endpoint, reqBody, ErrCodeCollectionNotFound)
+		if residual != nil {
+			ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
 			if err != nil {
-				return err
-			}
-			results, parseErr := parseQueryResponse(body)
-			if parseErr != nil {
-				return parseErr
+				return 0, err
 			}
-			count = int64(len(results))
-			return nil
-		}); err != nil {
-			return 0, newError(ErrCodeProviderError, "CountDocuments (filtered) failed", err)
+			return int64(len(ids)), nil
 		}
-		return count, nil
+		method, reqBody = http.MethodPost, map[string]interface{}{"predicate": predicate}
 	}
 
-	// No filter: use count_rows endpoint.
-	endpoint := fmt.Sprintf("%s/v1/table/%s/count_rows/", c.baseURL(), collectionName)
 	var count int64
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
-		body, err := c.doRequestChecked(ctx, http.MethodGet, endpoint, nil, ErrCodeCollectionNotFound)
+		body, err := c.doRequestChecked(ctx, method, endpoint, reqBody, ErrCodeCollectionNotFound)
 		if err != nil {
 			return err
 		}
@@ -564,8 +599,12 @@ func (c *lanceDBClient) ScrollDocuments(ctx context.Context, req ScrollRequest)
 		"offset":       offset,
 		"with_row_id":  false,
 	}
-	if len(req.Filters) > 0 {
-		reqBody["filter"] = buildLanceDBPredicate(req.Filters)
+	predicate, residual, err := c.predicate(ctx, req.CollectionName, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if predicate != "" {
+		reqBody["filter"] = predicate
 		reqBody["prefilter"] = true
 	}
 
@@ -585,7 +624,7 @@ func (c *lanceDBClient) ScrollDocuments(ctx context.Context, req ScrollRequest)
 			nextOffset = strconv.Itoa(offset + len(docs))
 		}
 		result = &ScrollResult{
-			Documents:  docs,
+			Documents:  matchDocuments(docs, residual),
 			NextOffset: nextOffset,
 			Total:      -1,
 		}
@@ -614,14 +653,18 @@ func (c *lanceDBClient) VectorSearch(ctx context.Context, req SearchRequest) ([]
 	metric := lanceDBMetric(filters)
 	delete(filters, "_metric")
 
+	predicate, residual, err := c.predicate(ctx, req.CollectionName, filters)
+	if err != nil {
+		return nil, err
+	}
 	reqBody := map[string]interface{}{
 		"vector":      toFloat32Slice(req.QueryVector),
-		"limit":       req.TopK,
+		"limit":       searchLimit(req.TopK, residual),
 		"metric":      metric,
 		"with_row_id": false,
 	}
-	if len(filters) > 0 {
-		reqBody["filter"] = buildLanceDBPredicate(filters)
+	if predicate != "" {
+		reqBody["filter"] = predicate
 		reqBody["prefilter"] = true
 	}
 
@@ -631,12 +674,12 @@ func (c *lanceDBClient) VectorSearch(ctx context.Context, req SearchRequest) ([]
 		if err != nil {
 			return err
 		}
-		results = parseSearchResponse(body, req.ScoreThreshold, req.SkipPayload, req.WithVectors, metric)
+		results = parseSearchResponse(body, req.ScoreThreshold, req.SkipPayload && residual == nil, req.WithVectors, metric)
 		return nil
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
 	}
-	return results, nil
+	return matchResults(results, residual, req.TopK, req.SkipPayload), nil
 }
 
 // HybridSearch performs combined dense vector + full-text search using RRF fusion.
@@ -664,8 +707,13 @@ func (c *lanceDBClient) HybridSearch(ctx context.Context, req HybridSearchReques
 		})
 	}
 
+	predicate, residual, err := c.predicate(ctx, req.CollectionName, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
 	// Run both searches in parallel.
-	candidateK := req.TopK * 2
+	candidateK := searchLimit(req.TopK, residual) * 2
 	if candidateK < 20 {
 		candidateK = 20
 	}
@@ -682,15 +730,15 @@ func (c *lanceDBClient) HybridSearch(ctx context.Context, req HybridSearchReques
 		"metric":      "cosine",
 		"with_row_id": false,
 	}
-	if len(req.Filters) > 0 {
-		vBody["filter"] = buildLanceDBPredicate(req.Filters)
+	if predicate != "" {
+		vBody["filter"] = predicate
 		vBody["prefilter"] = true
 	}
 	vRespBody, err := c.doRequestChecked(ctx, http.MethodPost, vEndpoint, vBody, ErrCodeCollectionNotFound)
 	if err != nil {
 		return nil, newError(ErrCodeProviderError, "HybridSearch (dense) failed", err)
 	}
-	vectorResults = parseSearchResponse(vRespBody, 0, false, false, "cosine")
+	vectorResults = matchResults(parseSearchResponse(vRespBody, 0, false, false, "cosine"), residual, candidateK, false)
 
 	// Full-text search (may fail if not indexed — fall back to vector-only).
 	tEndpoint := fmt.Sprintf("%s/v1/table/%s/query/", c.baseURL(), req.CollectionName)
@@ -702,13 +750,13 @@ func (c *lanceDBClient) HybridSearch(ctx context.Context, req HybridSearchReques
 		"limit":       candidateK,
 		"with_row_id": false,
 	}
-	if len(req.Filters) > 0 {
-		tBody["filter"] = buildLanceDBPredicate(req.Filters)
+	if predicate != "" {
+		tBody["filter"] = predicate
 		tBody["prefilter"] = true
 	}
 	tRespBody, tErr := c.doRequestChecked(ctx, http.MethodPost, tEndpoint, tBody, ErrCodeCollectionNotFound)
 	if tErr == nil {
-		textResults = parseSearchResponse(tRespBody, 0, false, false, "")
+		textResults = matchResults(parseSearchResponse(tRespBody, 0, false, false, ""), residual, candidateK, false)
 	}
 	// If FTS fails, use vector results only.
 	if tErr != nil || len(textResults) == 0 {
@@ -744,27 +792,35 @@ func (c *lanceDBClient) HybridSearch(ctx context.Context, req HybridSearchReques
 
 // fullTextSearch runs FTS-only search as a fallback.
 func (c *lanceDBClient) fullTextSearch(ctx context.Context, req HybridSearchRequest) ([]SearchResult, error) {
+	predicate, residual, err := c.predicate(ctx, req.CollectionName, req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	endpoint := fmt.Sprintf("%s/v1/table/%s/query/", c.baseURL(), req.CollectionName)
 	reqBody := map[string]interface{}{
 		"full_text_search": map[string]interface{}{
 			"query":   req.QueryText,
 			"columns": []string{"content"},
 		},
-		"limit":       req.TopK,
+		"limit":       searchLimit(req.TopK, residual),
 		"with_row_id": false,
 	}
+	if predicate != "" {
+		reqBody["filter"] = predicate
+		reqBody["prefilter"] = true
+	}
 	var results []SearchResult
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
 		body, err := c.doRequestChecked(ctx, http.MethodPost, endpoint, reqBody, ErrCodeCollectionNotFound)
 		if err != nil {
 			return err
 		}
-		results = parseSearchResponse(body, req.ScoreThreshold, req.SkipPayload, false, "")
+		results = parseSearchResponse(body, req.ScoreThreshold, req.SkipPayload && residual == nil, false, "")
 		return nil
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "fullTextSearch failed", err)
 	}
-	return results, nil
+	return matchResults(results, residual, req.TopK, req.SkipPayload), nil
 }
 
 // ── Helpers ───────────────────────────────────────────────────────────────────
@@ -993,83 +1049,3 @@ func rrfFuse(vectorResults, textResults []SearchResult, alpha float64, topK int,
 	}
 	return results
 }
-
-// buildLanceDBPredicate converts a generic filter map to a LanceDB SQL-like predicate.
-// Since metadata is stored as a JSON string column, string equality is matched with LIKE.
-// Numeric range operators ($gt/$gte/$lt/$lte) are not supported and are skipped.
-// $in is expanded as OR-joined LIKE clauses.
-func buildLanceDBPredicate(filters map[string]interface{}) string {
-	clauses := make([]string, 0, len(filters))
-	for key, value := range filters {
-		switch v := value.(type) {
-		case map[string]interface{}:
-			for op, opVal := range v {
-				switch op {
-				case "$eq":
-					clauses = append(clauses, lanceDBLikeExpr(key, opVal))
-				case "$ne":
-					clauses = append(clauses, fmt.Sprintf(`metadata NOT LIKE '%%"%s":%s%%'`, key, lanceDBLikeValue(opVal)))
-				case "$in":
-					if orClause := lanceDBInClause(key, opVal); orClause != "" {
-						clauses = append(clauses, "("+orClause+")")
-					}
-				case "$nin":
-					if orClause := lanceDBInClause(key, opVal); orClause != "" {
-						clauses = append(clauses, "NOT ("+orClause+")")
-					}
-				// $gt/$gte/$lt/$lte require JSON extraction which LanceDB/DataFusion
-				// does not support on plain string columns — skip silently.
-				}
-			}
-		default:
-			clauses = append(clauses, lanceDBLikeExpr(key, v))
-		}
-	}
-	if len(clauses) == 0 {
-		return ""
-	}
-	return strings.Join(clauses, " AND ")
-}
-
-// lanceDBLikeExpr builds a LIKE clause for a single key=value equality match.
-func lanceDBLikeExpr(key string, val interface{}) string {
-	return fmt.Sprintf(`metadata LIKE '%%"%s":%s%%'`, key, lanceDBLikeValue(val))
-}
-
-// lanceDBLikeValue formats a value for use inside a LIKE pattern.
-// Strings are quoted; numbers and booleans are unquoted (matching JSON serialisation).
-func lanceDBLikeValue(val interface{}) string {
-	switch v := val.(type) {
-	case string:
-		escaped := strings.ReplaceAll(v, "'", "''")
-		return fmt.Sprintf(`"%s"`, escaped)
-	case bool:
-		if v {
-			return "true"
-		}
-		return "false"
-	default:
-		return fmt.Sprintf("%v", v)
-	}
-}
-
-// lanceDBInClause builds an OR-joined set of LIKE clauses for $in matching.
-func lanceDBInClause(key string, val interface{}) string {
-	var items []interface{}
-	switch v := val.(type) {
-	case []interface{}:
-		items = v
-	case []string:
-		items = make([]interface{}, len(v))
-		for i, s := range v {
-			items[i] = s
-		}
-	default:
-		return lanceDBLikeExpr(key, val)
-	}
-	parts := make([]string, 0, len(items))
-	for _, item := range items {
-		parts = append(parts, lanceDBLikeExpr(key, item))
-	}
-	return strings.Join(parts, " OR ")
-}
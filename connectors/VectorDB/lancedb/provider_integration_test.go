@@ -153,3 +153,82 @@ func TestLanceDB_Integration(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestLanceDB_TypedMetadata_Integration(t *testing.T) {
+	host := "localhost"
+	if h := os.Getenv("LANCEDB_HOST"); h != "" {
+		host = h
+	}
+	cfg := ConnectionConfig{
+		Host:           host,
+		Port:           18181,
+		Scheme:         "http",
+		TimeoutSeconds: 30,
+		MaxRetries:     3,
+		RetryBackoffMs: 500,
+	}
+	client, err := NewClient(context.Background(), cfg)
+	if err != nil {
+		t.Skipf("LanceDB not reachable at %s:%d: %v", host, cfg.Port, err)
+	}
+	if hErr := client.HealthCheck(context.Background()); hErr != nil {
+		t.Skipf("LanceDB health check failed at %s:%d: %v", host, cfg.Port, hErr)
+	}
+	defer client.Close()
+
+	const testCollection = "lancedb_typed_metadata_test"
+	ctx := context.Background()
+	_ = client.DeleteCollection(ctx, testCollection)
+	defer client.DeleteCollection(ctx, testCollection)
+
+	require.NoError(t, client.CreateCollection(ctx, CollectionConfig{
+		Name:       testCollection,
+		Dimensions: 4,
+		MetadataFields: []MetadataField{
+			{Name: "year", Type: "int64"},
+			{Name: "rating", Type: "double"},
+			{Name: "lang", Type: "string"},
+			{Name: "published", Type: "timestamp"},
+		},
+	}))
+	require.NoError(t, client.UpsertDocuments(ctx, testCollection, []Document{
+		{ID: "a", Content: "alpha", Vector: []float64{1, 0, 0, 0}, Payload: map[string]interface{}{"year": 2019, "rating": 3.5, "lang": "en", "published": "2019-06-01T00:00:00Z"}},
+		{ID: "b", Content: "beta", Vector: []float64{0, 1, 0, 0}, Payload: map[string]interface{}{"year": 2021, "rating": 4.5, "lang": "de", "published": "2021-06-01T00:00:00Z"}},
+		{ID: "c", Content: "gamma", Vector: []float64{0, 0, 1, 0}, Payload: map[string]interface{}{"year": 2023, "rating": 4.9, "lang": "en", "published": "2023-06-01T00:00:00Z"}},
+		{ID: "d", Content: "delta", Vector: []float64{0, 0, 0, 1}, Payload: map[string]interface{}{"note": "untyped only"}},
+	}))
+
+	count, err := client.CountDocuments(ctx, testCollection, map[string]interface{}{
+		"year": map[string]interface{}{"$gte": 2020},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = client.CountDocuments(ctx, testCollection, map[string]interface{}{
+		"lang":      map[string]interface{}{"$in": []interface{}{"en"}},
+		"published": map[string]interface{}{"$lt": "2020-01-01T00:00:00Z"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	results, err := client.VectorSearch(ctx, SearchRequest{
+		CollectionName: testCollection,
+		QueryVector:    []float64{0, 0, 1, 0},
+		TopK:           10,
+		Filters:        map[string]interface{}{"rating": map[string]interface{}{"$gt": 4.0}},
+	})
+	require.NoError(t, err)
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	assert.ElementsMatch(t, []string{"b", "c"}, ids)
+
+	_, err = client.DeleteByFilter(ctx, testCollection, map[string]interface{}{
+		"lang": map[string]interface{}{"$ne": "en"},
+	})
+	require.NoError(t, err)
+	total, err := client.CountDocuments(ctx, testCollection, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total, "b (de) and d (no lang) are removed")
+}
@@ -279,9 +279,11 @@ func TestPayloadToJSON_RoundTrip(t *testing.T) {
 }
 
 func TestBuildLanceDBPredicate(t *testing.T) {
-	p := buildLanceDBPredicate(map[string]interface{}{"category": "ml"})
+	p, residual, err := lanceDBFilter(map[string]interface{}{"category": "ml"}, nil)
+	require.NoError(t, err)
 	assert.Contains(t, p, "category")
 	assert.Contains(t, p, "ml")
+	require.NotNil(t, residual, "untyped keys are verified client-side")
 }
 
 func TestRRFFuse_Basic(t *testing.T) {
@@ -297,3 +299,194 @@ func TestRRFFuse_Basic(t *testing.T) {
 	}
 	assert.Contains(t, ids, "b")
 }
+
+func TestBuildLanceDBPredicate_TypedColumns(t *testing.T) {
+	cols := lanceDBColumns{"year": kindInt, "score": kindFloat, "lang": kindString, "draft": kindBool, "published": kindTimestamp}
+	p, residual, err := lanceDBFilter(map[string]interface{}{
+		"year":      map[string]interface{}{"$gte": 2020.0, "$lt": 2024},
+		"score":     map[string]interface{}{"$gt": 0.5},
+		"lang":      map[string]interface{}{"$in": []interface{}{"en", "it's"}},
+		"draft":     false,
+		"published": map[string]interface{}{"$lte": "2024-03-01T12:00:00+01:00"},
+		"category":  "ml",
+	}, cols)
+	require.NoError(t, err)
+	assert.Equal(t, "`draft` = false"+
+		" AND `lang` IN ('en', 'it''s')"+
+		" AND `published` <= TIMESTAMP '2024-03-01 11:00:00'"+
+		" AND `score` > 0.5"+
+		" AND `year` >= 2020 AND `year` < 2024"+
+		` AND (metadata LIKE '%"category":"ml"%' OR metadata LIKE '%"category":[%"ml"%')`, p)
+	require.NotNil(t, residual)
+	assert.Equal(t, FilterEq, residual.Op)
+	assert.Equal(t, "category", residual.Field)
+}
+
+func TestBuildLanceDBPredicate_TypedNegation(t *testing.T) {
+	cols := lanceDBColumns{"lang": kindString, "year": kindInt}
+	p, residual, err := lanceDBFilter(map[string]interface{}{
+		"lang": map[string]interface{}{"$nin": []string{"de", "fr"}},
+		"year": map[string]interface{}{"$ne": 2021},
+	}, cols)
+	require.NoError(t, err)
+	assert.Nil(t, residual)
+	assert.Equal(t, "(`lang` IS NULL OR `lang` NOT IN ('de', 'fr')) AND (`year` IS NULL OR `year` <> 2021)", p)
+}
+
+func TestBuildLanceDBPredicate_Logical(t *testing.T) {
+	cols := lanceDBColumns{"lang": kindString, "year": kindInt, "reviewer": kindString}
+	p, residual, err := lanceDBFilter(map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"lang": "en", "year": map[string]interface{}{"$gte": 2020}},
+			map[string]interface{}{"reviewer": map[string]interface{}{"$exists": true}},
+		},
+		"$not": map[string]interface{}{"lang": "de"},
+	}, cols)
+	require.NoError(t, err)
+	assert.Nil(t, residual)
+	assert.Equal(t, "(`lang` = 'de') IS NOT TRUE AND ((`lang` = 'en' AND `year` >= 2020) OR `reviewer` IS NOT NULL)", p)
+}
+
+func TestBuildLanceDBPredicate_Residual(t *testing.T) {
+	cols := lanceDBColumns{"year": kindInt}
+	p, residual, err := lanceDBFilter(map[string]interface{}{
+		"year":             map[string]interface{}{"$gt": 2020},
+		"meta.author.team": "search",
+		"tags":             map[string]interface{}{"$contains": "faq"},
+		"rating":           map[string]interface{}{"$gte": 4},
+	}, cols)
+	require.NoError(t, err)
+	assert.Equal(t, "`year` > 2020"+
+		` AND (metadata LIKE '%"team":"search"%' OR metadata LIKE '%"team":[%"search"%')`+
+		` AND metadata LIKE '%"tags":[%"faq"%'`, p)
+	require.NotNil(t, residual)
+	assert.Len(t, residual.Conjuncts(), 3)
+	assert.True(t, residual.Match(map[string]interface{}{
+		"meta":   map[string]interface{}{"author": map[string]interface{}{"team": "search"}},
+		"tags":   []interface{}{"faq"},
+		"rating": 5.0,
+	}))
+}
+
+func TestBuildLanceDBPredicate_Errors(t *testing.T) {
+	_, _, err := lanceDBFilter(map[string]interface{}{"year": map[string]interface{}{"$gt": "recent"}},
+		lanceDBColumns{"year": kindInt})
+	require.Error(t, err)
+	assert.Equal(t, ErrCodeInvalidFilter, err.(*VDBError).Code)
+
+	_, _, err = lanceDBFilter(map[string]interface{}{"tag": map[string]interface{}{"$regex": "a.*"}}, nil)
+	require.Error(t, err)
+	assert.Equal(t, ErrCodeInvalidFilter, err.(*VDBError).Code)
+}
+
+func TestValidateMetadataFields(t *testing.T) {
+	assert.NoError(t, validateMetadataFields([]MetadataField{{Name: "year", Type: "int64"}, {Name: "published", Type: "timestamp"}}))
+	for _, fields := range [][]MetadataField{
+		{{Name: "1year", Type: "int64"}},
+		{{Name: "Content", Type: "string"}},
+		{{Name: "year", Type: "int64"}, {Name: "YEAR", Type: "double"}},
+		{{Name: "tags", Type: "string[]"}},
+	} {
+		err := validateMetadataFields(fields)
+		require.Error(t, err, "%v", fields)
+		assert.Equal(t, ErrCodeInvalidMetadataField, err.(*VDBError).Code)
+	}
+}
+
+func TestParseDescribeColumns(t *testing.T) {
+	cols := parseDescribeColumns([]byte(`{"schema":{"fields":[
+		{"name":"id","type":{"type":"utf8"}},
+		{"name":"embedding","type":{"type":"fixed_size_list"}},
+		{"name":"year","type":{"type":"int64"}},
+		{"name":"score","type":"double"},
+		{"name":"published","type":{"type":"timestamp","unit":"microsecond"}},
+		{"name":"tags","type":{"type":"list"}}
+	]}}`))
+	assert.Equal(t, lanceDBColumns{"year": kindInt, "score": kindFloat, "published": kindTimestamp}, cols)
+}
+
+func TestCreateCollection_MetadataFields(t *testing.T) {
+	var gotBody map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := newTestClientForServer(srv)
+	err := c.CreateCollection(context.Background(), CollectionConfig{
+		Name: "mytest", Dimensions: 8,
+		MetadataFields: []MetadataField{{Name: "year", Type: "int64"}, {Name: "published", Type: "datetime"}},
+	})
+	require.NoError(t, err)
+	fields := gotBody["schema"].(map[string]interface{})["fields"].([]interface{})
+	require.Len(t, fields, 6)
+	assert.Equal(t, "year", fields[4].(map[string]interface{})["name"])
+	assert.Equal(t, map[string]interface{}{"type": "timestamp", "unit": "microsecond"}, fields[5].(map[string]interface{})["type"])
+
+	err = c.CreateCollection(context.Background(), CollectionConfig{
+		Name: "mytest", Dimensions: 8, MetadataFields: []MetadataField{{Name: "embedding", Type: "string"}},
+	})
+	require.Error(t, err)
+}
+
+func TestUpsertDocuments_TypedColumns(t *testing.T) {
+	var describes int
+	var gotBody map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/describe/") {
+			describes++
+			w.Write([]byte(`{"schema":{"fields":[{"name":"id","type":{"type":"utf8"}},{"name":"year","type":{"type":"int64"}},{"name":"published","type":{"type":"timestamp"}}]}}`))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClientForServer(srv)
+	docs := []Document{
+		{ID: "d1", Vector: []float64{0.1}, Payload: map[string]interface{}{"year": 2023.0, "published": "2023-05-01T10:00:00Z"}},
+		{ID: "d2", Vector: []float64{0.2}, Payload: map[string]interface{}{"other": "x"}},
+	}
+	require.NoError(t, c.UpsertDocuments(context.Background(), "mycol", docs))
+	require.NoError(t, c.UpsertDocuments(context.Background(), "mycol", docs))
+	assert.Equal(t, 1, describes, "schema is cached per table")
+
+	data := gotBody["data"].([]interface{})
+	d1, d2 := data[0].(map[string]interface{}), data[1].(map[string]interface{})
+	assert.Equal(t, 2023.0, d1["year"])
+	assert.Equal(t, "2023-05-01T10:00:00", d1["published"])
+	assert.Contains(t, d2, "year")
+	assert.Nil(t, d2["year"])
+
+	err := c.UpsertDocuments(context.Background(), "mycol", []Document{
+		{ID: "bad", Vector: []float64{0.1}, Payload: map[string]interface{}{"year": "last year"}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, ErrCodeInvalidMetadataField, err.(*VDBError).Code)
+}
+
+func TestCountDocuments_FilteredServerSide(t *testing.T) {
+	var gotMethod string
+	var gotBody map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/describe/") {
+			w.Write([]byte(`{"schema":{"fields":[{"name":"year","type":{"type":"int32"}}]}}`))
+			return
+		}
+		gotMethod = r.Method
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`3`))
+	}))
+	defer srv.Close()
+
+	c := newTestClientForServer(srv)
+	count, err := c.CountDocuments(context.Background(), "mycol", map[string]interface{}{
+		"year": map[string]interface{}{"$gte": 2020},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, http.MethodPost, gotMethod)
+	assert.Equal(t, "`year` >= 2020", gotBody["predicate"])
+}
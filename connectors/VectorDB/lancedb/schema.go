@@ -0,0 +1,339 @@
+package vectordb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columnKind is the value class of a typed metadata column. It decides how
+// payload values are converted on upsert and how filter literals are written.
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindTimestamp
+)
+
+// lanceDBColumns maps the typed metadata columns of a table to their kind.
+type lanceDBColumns map[string]columnKind
+
+// metadataFieldNameRe matches metadata column names. Names are always quoted
+// with backticks in predicates, so SQL keywords are allowed.
+var metadataFieldNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,127}$`)
+
+// reservedColumnNames are the built-in columns every table is created with.
+var reservedColumnNames = map[string]bool{
+	"id": true, "content": true, "metadata": true, "embedding": true,
+}
+
+// timestampLayout is how timestamp column values are sent on upsert: UTC
+// without a zone suffix, matching the zone-less timestamp[us] column type.
+const timestampLayout = "2006-01-02T15:04:05.999999"
+
+// metadataColumnKind maps a declared MetadataField type to its column kind.
+func metadataColumnKind(t string) (columnKind, bool) {
+	switch strings.ToLower(strings.TrimSpace(t)) {
+	case "string":
+		return kindString, true
+	case "int32", "int64":
+		return kindInt, true
+	case "float", "double":
+		return kindFloat, true
+	case "boolean", "bool":
+		return kindBool, true
+	case "timestamp", "datetime":
+		return kindTimestamp, true
+	}
+	return 0, false
+}
+
+// metadataArrowType returns the Arrow JSON type of a declared MetadataField.
+// Timestamps are stored as zone-less microseconds in UTC.
+func metadataArrowType(t string) map[string]interface{} {
+	switch strings.ToLower(strings.TrimSpace(t)) {
+	case "int32":
+		return map[string]interface{}{"type": "int32"}
+	case "int64":
+		return map[string]interface{}{"type": "int64"}
+	case "float":
+		return map[string]interface{}{"type": "float32"}
+	case "double":
+		return map[string]interface{}{"type": "float64"}
+	case "boolean", "bool":
+		return map[string]interface{}{"type": "bool"}
+	case "timestamp", "datetime":
+		return map[string]interface{}{"type": "timestamp", "unit": "microsecond"}
+	default:
+		return map[string]interface{}{"type": "utf8"}
+	}
+}
+
+// metadataSchemaFields returns the Arrow schema fields for declared metadata
+// columns, all nullable so documents may omit them.
+func metadataSchemaFields(fields []MetadataField) []interface{} {
+	out := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, map[string]interface{}{
+			"name":     f.Name,
+			"type":     metadataArrowType(f.Type),
+			"nullable": true,
+		})
+	}
+	return out
+}
+
+// arrowColumnKind maps an Arrow type name reported by describe to a column
+// kind. Types that cannot be filtered with a scalar literal are not typed.
+func arrowColumnKind(name string) (columnKind, bool) {
+	name = strings.ToLower(name)
+	switch {
+	case name == "utf8" || name == "large_utf8" || name == "string" || name == "large_string":
+		return kindString, true
+	case strings.HasPrefix(name, "int") || strings.HasPrefix(name, "uint"):
+		return kindInt, true
+	case strings.HasPrefix(name, "float") || name == "halffloat" || name == "double":
+		return kindFloat, true
+	case name == "bool" || name == "boolean":
+		return kindBool, true
+	case strings.HasPrefix(name, "timestamp") || strings.HasPrefix(name, "date"):
+		return kindTimestamp, true
+	}
+	return 0, false
+}
+
+// parseDescribeColumns extracts the typed metadata columns from a describe
+// response: {"schema": {"fields": [{"name": ..., "type": {"type": ...}}]}}.
+// The type may also be given as a bare string.
+func parseDescribeColumns(body []byte) lanceDBColumns {
+	var resp struct {
+		Schema struct {
+			Fields []struct {
+				Name string          `json:"name"`
+				Type json.RawMessage `json:"type"`
+			} `json:"fields"`
+		} `json:"schema"`
+	}
+	cols := lanceDBColumns{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return cols
+	}
+	for _, f := range resp.Schema.Fields {
+		if reservedColumnNames[strings.ToLower(f.Name)] || !metadataFieldNameRe.MatchString(f.Name) {
+			continue
+		}
+		var typeName string
+		if err := json.Unmarshal(f.Type, &typeName); err != nil {
+			var obj struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(f.Type, &obj); err != nil {
+				continue
+			}
+			typeName = obj.Type
+		}
+		if kind, ok := arrowColumnKind(typeName); ok {
+			cols[f.Name] = kind
+		}
+	}
+	return cols
+}
+
+// tableColumns returns the typed metadata columns of a table, describing it
+// on first use. Failures are not cached and yield no columns, so filters fall
+// back to LIKE matching on the JSON metadata string.
+func (c *lanceDBClient) tableColumns(ctx context.Context, name string) lanceDBColumns {
+	c.schemaMu.RLock()
+	cols, ok := c.schemas[name]
+	c.schemaMu.RUnlock()
+	if ok {
+		return cols
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/table/%s/describe/", c.baseURL(), name)
+	body, err := c.doRequestChecked(ctx, http.MethodPost, endpoint, map[string]interface{}{}, ErrCodeCollectionNotFound)
+	if err != nil {
+		return nil
+	}
+	cols = parseDescribeColumns(body)
+
+	c.schemaMu.Lock()
+	if c.schemas == nil {
+		c.schemas = make(map[string]lanceDBColumns)
+	}
+	c.schemas[name] = cols
+	c.schemaMu.Unlock()
+	return cols
+}
+
+// forgetTableColumns drops the cached columns of a table after it is created
+// or deleted.
+func (c *lanceDBClient) forgetTableColumns(name string) {
+	c.schemaMu.Lock()
+	delete(c.schemas, name)
+	c.schemaMu.Unlock()
+}
+
+// columnValue converts a payload value to the JSON value stored in a typed
+// column. nil stays nil (a null cell).
+func columnValue(kind columnKind, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch kind {
+	case kindInt:
+		return toInt64(v)
+	case kindFloat:
+		return toFloat64(v)
+	case kindBool:
+		return toBool(v)
+	case kindTimestamp:
+		t, err := toTime(v)
+		if err != nil {
+			return nil, err
+		}
+		return t.UTC().Format(timestampLayout), nil
+	default:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+}
+
+// sqlLiteral renders a filter value as a SQL literal for a column of the
+// given kind. Integer columns accept fractional bounds ($gt: 2.5).
+func sqlLiteral(kind columnKind, v interface{}) (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("null is not a filter value")
+	}
+	switch kind {
+	case kindInt:
+		if n, err := toInt64(v); err == nil {
+			return strconv.FormatInt(n, 10), nil
+		}
+		fallthrough
+	case kindFloat:
+		f, err := toFloat64(v)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case kindBool:
+		b, err := toBool(v)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+	case kindTimestamp:
+		t, err := toTime(v)
+		if err != nil {
+			return "", err
+		}
+		return "TIMESTAMP '" + t.UTC().Format("2006-01-02 15:04:05.999999") + "'", nil
+	default:
+		s, err := columnValue(kindString, v)
+		if err != nil {
+			return "", err
+		}
+		return "'" + strings.ReplaceAll(s.(string), "'", "''") + "'", nil
+	}
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+	case float32:
+		return toInt64(float64(n))
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, fmt.Errorf("%v is not an integer", n)
+		}
+		return int64(n), nil
+	}
+	return 0, fmt.Errorf("%v (%T) is not an integer", v, v)
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(n), 64)
+	}
+	return 0, fmt.Errorf("%v (%T) is not a number", v, v)
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(b))
+	}
+	return false, fmt.Errorf("%v (%T) is not a boolean", v, v)
+}
+
+// timeLayouts are the accepted string forms of timestamp values. Values
+// without a zone are taken as UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// toTime converts an RFC 3339 string, a date, a time.Time or Unix seconds
+// to a time.
+func toTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		s := strings.TrimSpace(t)
+		for _, layout := range timeLayouts {
+			if parsed, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%q is not an RFC 3339 timestamp or date", t)
+	}
+	secs, err := toFloat64(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v (%T) is not a timestamp", v, v)
+	}
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC(), nil
+}
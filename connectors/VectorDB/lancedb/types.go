@@ -45,6 +45,17 @@ type CollectionConfig struct {
 
 	// ReplicationFactor sets the replica count (not applicable for LanceDB REST; reserved for interface compatibility).
 	ReplicationFactor int
+
+	// MetadataFields declares payload keys that become typed Arrow columns.
+	// Filters on these keys compile to SQL comparisons instead of LIKE matching.
+	MetadataFields []MetadataField
+}
+
+// MetadataField declares a payload key that is stored as its own typed Arrow
+// column in addition to the JSON metadata string.
+type MetadataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // string, int32, int64, float, double, boolean, timestamp
 }
 
 // SearchRequest encapsulates a vector similarity search.
@@ -62,6 +73,9 @@ type SearchRequest struct {
 	ScoreThreshold float64
 
 	// Filters is a provider-agnostic metadata filter expressed as a map.
+	// Keys are payload paths (nested keys dot-separated) or $and / $or / $not;
+	// field operators are $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $exists
+	// and $contains. See ParseFilter.
 	Filters map[string]interface{}
 
 	// WithVectors includes the stored vector in each SearchResult.
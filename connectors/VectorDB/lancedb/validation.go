@@ -76,6 +76,33 @@ func validateCollectionConfig(cfg CollectionConfig) error {
 		return newError(ErrCodeInvalidMetric,
 			fmt.Sprintf("DistanceMetric %q is invalid; use: cosine, dot, euclidean", cfg.DistanceMetric), nil)
 	}
+	return validateMetadataFields(cfg.MetadataFields)
+}
+
+// validateMetadataFields checks that each declared field has a plain
+// identifier name, does not shadow a built-in column and has a supported type.
+func validateMetadataFields(fields []MetadataField) error {
+	seen := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		if !metadataFieldNameRe.MatchString(f.Name) {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: name %q must start with a letter and contain only letters, digits and underscores", i, f.Name), nil)
+		}
+		key := strings.ToLower(f.Name)
+		if reservedColumnNames[key] {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: name %q is reserved", i, f.Name), nil)
+		}
+		if seen[key] {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: duplicate name %q", i, f.Name), nil)
+		}
+		seen[key] = true
+		if _, ok := metadataColumnKind(f.Type); !ok {
+			return newError(ErrCodeInvalidMetadataField,
+				fmt.Sprintf("metadataFields[%d]: unsupported type %q (string, int32, int64, float, double, boolean, timestamp)", i, f.Type), nil)
+		}
+	}
 	return nil
 }
 
@@ -117,3 +117,21 @@ func TestCreateCollection_ClientError(t *testing.T) {
 	assert.Equal(t, false, ctx.outputs["success"])
 	assert.Contains(t, ctx.outputs["error"].(string), "connection refused")
 }
+
+func TestCreateCollection_EnableSparsePassedThrough(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CreateCollection", mock.Anything, mock.MatchedBy(func(cfg vectordb.CollectionConfig) bool {
+		return cfg.Name == "col" && cfg.EnableSparse
+	})).Return(nil)
+
+	a := &Activity{conn: newTestConn(mc), settings: &Settings{}}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col",
+		"enableSparse":   true,
+	}}
+	ok, err := a.Eval(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, true, ctx.outputs["success"])
+	mc.AssertExpectations(t)
+}
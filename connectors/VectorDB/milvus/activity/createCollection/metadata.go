@@ -16,6 +16,7 @@ type Input struct {
 	DistanceMetric    string `md:"distanceMetric"`
 	OnDisk            bool   `md:"onDisk"`
 	ReplicationFactor int    `md:"replicationFactor"`
+	EnableSparse      bool   `md:"enableSparse"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
@@ -25,6 +26,7 @@ func (i *Input) ToMap() map[string]interface{} {
 		"distanceMetric":    i.DistanceMetric,
 		"onDisk":            i.OnDisk,
 		"replicationFactor": i.ReplicationFactor,
+		"enableSparse":      i.EnableSparse,
 	}
 }
 
@@ -54,6 +56,9 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 			i.ReplicationFactor = int(n)
 		}
 	}
+	if val, ok := v["enableSparse"]; ok {
+		i.EnableSparse, _ = val.(bool)
+	}
 	return nil
 }
 
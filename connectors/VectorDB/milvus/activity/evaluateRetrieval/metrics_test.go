@@ -0,0 +1,46 @@
+package evaluateRetrieval
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecallAndMRR(t *testing.T) {
+	rel := map[string]float64{"a": 1, "b": 1}
+	ranked := []string{"x", "a", "y", "b"}
+
+	assert.Equal(t, 0.0, recallAtK(ranked, rel, 1))
+	assert.Equal(t, 0.5, recallAtK(ranked, rel, 3))
+	assert.Equal(t, 1.0, recallAtK(ranked, rel, 10))
+	assert.Equal(t, 0.5, reciprocalRank(ranked, rel))
+	assert.Equal(t, 0.0, reciprocalRank([]string{"x"}, rel))
+}
+
+func TestNDCG_Graded(t *testing.T) {
+	rel := map[string]float64{"a": 3, "b": 1}
+
+	assert.InDelta(t, 1.0, ndcgAtK([]string{"a", "b"}, rel, 2), 1e-12)
+	// Swapped order: DCG = 1 + 3/log2(3), IDCG = 3 + 1/log2(3).
+	want := (1 + 3/math.Log2(3)) / (3 + 1/math.Log2(3))
+	assert.InDelta(t, want, ndcgAtK([]string{"b", "a"}, rel, 2), 1e-12)
+	assert.Equal(t, 0.0, ndcgAtK([]string{"x"}, rel, 5))
+}
+
+func TestLatencyStats_NearestRank(t *testing.T) {
+	var lat []time.Duration
+	for i := 1; i <= 100; i++ {
+		lat = append(lat, time.Duration(i)*time.Millisecond)
+	}
+	s := latencyStats(lat)
+	assert.Equal(t, 50.0, s["p50Ms"])
+	assert.Equal(t, 95.0, s["p95Ms"])
+	assert.Equal(t, 99.0, s["p99Ms"])
+	assert.Equal(t, 100.0, s["maxMs"])
+	assert.Equal(t, 50.5, s["meanMs"])
+
+	s = latencyStats([]time.Duration{7 * time.Millisecond})
+	assert.Equal(t, 7.0, s["p99Ms"])
+}
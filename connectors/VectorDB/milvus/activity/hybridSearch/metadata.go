@@ -21,8 +21,12 @@ type Input struct {
 	TopK           int                    `md:"topK"`
 	ScoreThreshold float64                `md:"scoreThreshold"`
 	Alpha          float64                `md:"alpha"`
+	Fusion         string                 `md:"fusion"`
 	Filters        map[string]interface{} `md:"filters"`
 	SkipPayload    bool                   `md:"skipPayload"`
+	MMR            bool                   `md:"mmr"`
+	MMRLambda      float64                `md:"mmrLambda"`
+	MMRFetchK      int                    `md:"mmrFetchK"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
@@ -33,8 +37,12 @@ func (i *Input) ToMap() map[string]interface{} {
 		"topK":           i.TopK,
 		"scoreThreshold": i.ScoreThreshold,
 		"alpha":          i.Alpha,
+		"fusion":         i.Fusion,
 		"filters":        i.Filters,
 		"skipPayload":    i.SkipPayload,
+		"mmr":            i.MMR,
+		"mmrLambda":      i.MMRLambda,
+		"mmrFetchK":      i.MMRFetchK,
 	}
 }
 
@@ -73,6 +81,9 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 			i.Alpha = f
 		}
 	}
+	if val, ok := v["fusion"]; ok && val != nil {
+		i.Fusion = fmt.Sprintf("%v", val)
+	}
 	if val, ok := v["filters"]; ok {
 		if m, ok := val.(map[string]interface{}); ok {
 			i.Filters = m
@@ -81,6 +92,22 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 	if val, ok := v["skipPayload"]; ok {
 		i.SkipPayload, _ = val.(bool)
 	}
+	if val, ok := v["mmr"]; ok {
+		i.MMR, _ = val.(bool)
+	}
+	if val, ok := v["mmrLambda"]; ok {
+		if f, ok := val.(float64); ok {
+			i.MMRLambda = f
+		}
+	}
+	if val, ok := v["mmrFetchK"]; ok {
+		switch n := val.(type) {
+		case int:
+			i.MMRFetchK = n
+		case float64:
+			i.MMRFetchK = int(n)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,172 @@
+package ingestDocuments
+
+// docextract_json.go — JSON (.json) and JSON Lines (.jsonl / .ndjson)
+// extraction using stdlib encoding/json.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonTitleKeys are top-level record fields used, in order of preference, as
+// the record heading.
+var jsonTitleKeys = []string{"title", "name", "subject", "id"}
+
+// extractJSON extracts a JSON file. Every scalar value becomes one
+// "path: value" line, in document order, with dotted object keys and [i]
+// array indexes in the path:
+//
+//	customer.name: Acme
+//	items[0].sku: A-100
+//
+// A top-level array is treated as a list of records: each element becomes
+// its own section headed "## Record <n>" (or "## Record <n>: <title>" when
+// the record has a title, name, subject or id field) with "record" (1-based)
+// section metadata. Any other top-level value is a single section.
+func extractJSON(data []byte) (*ExtractedDocument, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	first, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("json: parse error: %w", err)
+	}
+	if d, ok := first.(json.Delim); ok && d == '[' {
+		doc := &ExtractedDocument{}
+		for n := 1; dec.More(); n++ {
+			var lines []string
+			if err := flattenJSON(dec, "", &lines); err != nil {
+				return nil, fmt.Errorf("json: record %d: %w", n, err)
+			}
+			doc.Sections = appendRecord(doc.Sections, n, lines)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("json: parse error: %w", err)
+		}
+		return doc, nil
+	}
+
+	var lines []string
+	if err := flattenJSONValue(dec, first, "", &lines); err != nil {
+		return nil, fmt.Errorf("json: parse error: %w", err)
+	}
+	return singleSection(strings.Join(lines, "\n")), nil
+}
+
+// extractJSONLines extracts a JSON Lines file: one record per non-empty line,
+// rendered like the elements of a top-level JSON array.
+func extractJSONLines(data []byte) (*ExtractedDocument, error) {
+	doc := &ExtractedDocument{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), len(data)+1)
+	n := 0
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n++
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var lines []string
+		if err := flattenJSON(dec, "", &lines); err != nil {
+			return nil, fmt.Errorf("jsonl: line %d: %w", lineNo, err)
+		}
+		doc.Sections = appendRecord(doc.Sections, n, lines)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("jsonl: read error: %w", err)
+	}
+	return doc, nil
+}
+
+// appendRecord adds record n as a section, titled from its title keys.
+func appendRecord(sections []DocumentSection, n int, lines []string) []DocumentSection {
+	if len(lines) == 0 {
+		return sections
+	}
+	heading := fmt.Sprintf("## Record %d", n)
+	for _, key := range jsonTitleKeys {
+		if v, ok := lookupLine(lines, key); ok && v != "" {
+			heading += ": " + v
+			break
+		}
+	}
+	return append(sections, DocumentSection{
+		Text:     heading + "\n" + strings.Join(lines, "\n"),
+		Metadata: map[string]interface{}{"record": n},
+	})
+}
+
+// lookupLine returns the value of the "key: value" line for a top-level key.
+func lookupLine(lines []string, key string) (string, bool) {
+	prefix := key + ": "
+	for _, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			return strings.TrimPrefix(l, prefix), true
+		}
+	}
+	return "", false
+}
+
+// flattenJSON reads the next value from dec and appends its "path: value"
+// lines.
+func flattenJSON(dec *json.Decoder, path string, lines *[]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	return flattenJSONValue(dec, tok, path, lines)
+}
+
+// flattenJSONValue appends the lines of the value that starts with tok.
+// Objects and arrays are read token by token so keys keep their order.
+func flattenJSONValue(dec *json.Decoder, tok json.Token, path string, lines *[]string) error {
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := kt.(string)
+				child := key
+				if path != "" {
+					child = path + "." + key
+				}
+				if err := flattenJSON(dec, child, lines); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; dec.More(); i++ {
+				if err := flattenJSON(dec, fmt.Sprintf("%s[%d]", path, i), lines); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("unexpected %q", v)
+		}
+		_, err := dec.Token() // closing delimiter
+		return err
+	case nil:
+		return nil // null carries no text
+	default:
+		s := strings.Join(strings.Fields(fmt.Sprint(v)), " ")
+		if s == "" {
+			return nil
+		}
+		if path == "" {
+			*lines = append(*lines, s)
+		} else {
+			*lines = append(*lines, path+": "+s)
+		}
+		return nil
+	}
+}
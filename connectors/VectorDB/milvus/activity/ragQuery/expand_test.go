@@ -0,0 +1,224 @@
+package ragQuery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-milvus"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-milvus/testutil/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkStore answers ScrollDocuments from an in-memory list of chunks,
+// applying the parentId, section_path and chunkIndex filters.
+type chunkStore struct {
+	vectordb.VectorDBClient
+	docs []vectordb.Document
+	err  error
+}
+
+func (c *chunkStore) ScrollDocuments(_ context.Context, req vectordb.ScrollRequest) (*vectordb.ScrollResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var out []vectordb.Document
+	for _, d := range c.docs {
+		if d.Payload[parentIDKey] != req.Filters[parentIDKey] {
+			continue
+		}
+		if s, ok := req.Filters[sectionPathKey]; ok && d.Payload[sectionPathKey] != s {
+			continue
+		}
+		if rng, ok := req.Filters[chunkIndexKey].(map[string]interface{}); ok {
+			i := d.Payload[chunkIndexKey].(int)
+			if i < rng["$gte"].(int) || i > rng["$lte"].(int) {
+				continue
+			}
+		}
+		out = append(out, d)
+	}
+	return &vectordb.ScrollResult{Documents: out, Total: int64(len(out))}, nil
+}
+
+// storedChunks returns the chunks "<parent> c<i>" of one parent, newest
+// first so that callers must sort them; sections[i] is the section_path of
+// chunk i.
+func storedChunks(parent string, sections ...string) []vectordb.Document {
+	docs := make([]vectordb.Document, 0, len(sections))
+	for i := len(sections) - 1; i >= 0; i-- {
+		docs = append(docs, vectordb.Document{
+			ID:      fmt.Sprintf("%s-chunk-%d", parent, i),
+			Content: fmt.Sprintf("%s c%d", parent, i),
+			Payload: map[string]interface{}{
+				parentIDKey: parent, chunkIndexKey: i, chunkCountKey: len(sections), sectionPathKey: sections[i],
+			},
+		})
+	}
+	return docs
+}
+
+func hit(parent string, index, count int, score float64) vectordb.SearchResult {
+	return vectordb.SearchResult{
+		ID:      fmt.Sprintf("%s-chunk-%d", parent, index),
+		Score:   score,
+		Content: fmt.Sprintf("%s c%d", parent, index),
+		Payload: map[string]interface{}{parentIDKey: parent, chunkIndexKey: float64(index), chunkCountKey: float64(count)},
+	}
+}
+
+func TestBuildWindows_MergesOverlappingNeighbors(t *testing.T) {
+	results := []vectordb.SearchResult{
+		hit("p1", 2, 10, 0.9),
+		hit("p2", 0, 10, 0.85),
+		hit("p1", 4, 10, 0.95),
+		hit("p1", 9, 10, 0.7),
+		{ID: "unlinked", Score: 0.6},
+	}
+	windows := buildWindows(results, expansionConfig{Mode: expansionNeighbors, Neighbors: 1})
+	require.Len(t, windows, 4)
+
+	// p1 chunks 2 and 4 touch: one window at the rank of chunk 2, with the
+	// better-scoring chunk 4 as its best hit.
+	assert.Equal(t, [2]int{1, 5}, [2]int{windows[0].lo, windows[0].hi})
+	assert.Equal(t, "p1-chunk-4", windows[0].best.ID)
+	assert.Equal(t, 2, windows[0].hits)
+	assert.Equal(t, [2]int{0, 1}, [2]int{windows[1].lo, windows[1].hi}, "clamped at the first chunk")
+	assert.Equal(t, [2]int{8, 9}, [2]int{windows[2].lo, windows[2].hi}, "clamped at chunkCount")
+	assert.Equal(t, "unlinked", windows[3].best.ID)
+	assert.False(t, windows[3].linked)
+}
+
+func TestExpandContext_Neighbors(t *testing.T) {
+	client := &chunkStore{docs: storedChunks("p1", "", "", "", "", "")}
+	got, err := expandContext(context.Background(), client, "docs",
+		[]vectordb.SearchResult{hit("p1", 2, 5, 0.9), {ID: "other", Content: "unlinked"}},
+		expansionConfig{Mode: expansionNeighbors, Neighbors: 1, ContentField: "text"})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "p1-chunk-2", got[0].ID)
+	assert.Equal(t, 0.9, got[0].Score)
+	assert.Equal(t, "p1 c1\n\np1 c2\n\np1 c3", got[0].Content)
+	assert.Equal(t, 1, got[0].Payload["expandedChunkStart"])
+	assert.Equal(t, 3, got[0].Payload["expandedChunkEnd"])
+	assert.Equal(t, 1, got[0].Payload["expandedHits"])
+	assert.Equal(t, "unlinked", got[1].Content)
+}
+
+func TestExpandContext_ParentSection(t *testing.T) {
+	client := &chunkStore{docs: storedChunks("p1", "Install", "Install", "Usage", "Usage", "Usage")}
+	h := hit("p1", 3, 5, 0.9)
+	h.Payload[sectionPathKey] = "Usage"
+	got, err := expandContext(context.Background(), client, "docs", []vectordb.SearchResult{h},
+		expansionConfig{Mode: expansionParent, ContentField: "text"})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "p1 c2\n\np1 c3\n\np1 c4", got[0].Content)
+}
+
+func TestExpandContext_TokenBudget(t *testing.T) {
+	client := &chunkStore{docs: storedChunks("p1", "", "", "", "", "")}
+	results := []vectordb.SearchResult{hit("p1", 2, 5, 0.9), {ID: "b", Content: strings.Repeat("x", 40)}}
+
+	// The 3-chunk passage (19 characters, 5 tokens) does not fit in 4 tokens:
+	// the retrieved chunk (2 tokens) is used instead, and the next document
+	// (10 tokens) ends the context.
+	got, err := expandContext(context.Background(), client, "docs", results,
+		expansionConfig{Mode: expansionNeighbors, Neighbors: 1, TokenBudget: 4, ContentField: "text"})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "p1 c2", got[0].Content)
+
+	// The budget also applies without expansion.
+	got, _ = expandContext(context.Background(), nil, "docs", results,
+		expansionConfig{Mode: expansionNone, TokenBudget: 12, ContentField: "text"})
+	assert.Len(t, got, 2)
+}
+
+func TestExpandContext_ScrollErrorKeepsRetrievedChunk(t *testing.T) {
+	client := &chunkStore{err: errors.New("unavailable")}
+	got, err := expandContext(context.Background(), client, "docs", []vectordb.SearchResult{hit("p1", 2, 5, 0.9)},
+		expansionConfig{Mode: expansionNeighbors, Neighbors: 1, ContentField: "text"})
+	assert.Error(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "p1 c2", got[0].Content)
+}
+
+// pagedStore serves fixed ScrollDocuments pages keyed by offset.
+type pagedStore struct {
+	vectordb.VectorDBClient
+	pages map[string]*vectordb.ScrollResult
+	calls int
+}
+
+func (p *pagedStore) ScrollDocuments(_ context.Context, req vectordb.ScrollRequest) (*vectordb.ScrollResult, error) {
+	p.calls++
+	return p.pages[req.Offset], nil
+}
+
+func TestFetchWindow_FollowsEmptyPages(t *testing.T) {
+	docs := storedChunks("p1", "", "", "")
+	client := &pagedStore{pages: map[string]*vectordb.ScrollResult{
+		"":       {NextOffset: "page-2"},
+		"page-2": {Documents: docs[:2], NextOffset: "page-3"},
+		"page-3": {Documents: docs[2:], NextOffset: "page-2"},
+	}}
+	w := &chunkWindow{best: hit("p1", 1, 3, 0.9), parentID: "p1", lo: 0, hi: 2}
+
+	got, err := fetchWindow(context.Background(), client, "docs", w, "text")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "p1 c0\n\np1 c1\n\np1 c2", got.Content)
+	assert.Equal(t, 3, client.calls, "an empty page does not end the scroll; a repeated offset does")
+}
+
+func TestJoinChunks_RemovesOverlap(t *testing.T) {
+	first := "Flogo flows are triggered by events from many sources."
+	second := "events from many sources. Each flow runs its activities in order."
+	assert.Equal(t, "Flogo flows are triggered by events from many sources. Each flow runs its activities in order.",
+		joinChunks([]string{first, second}))
+
+	// A short coincidental match is not an overlap.
+	assert.Equal(t, "ends with a\n\na starts", joinChunks([]string{"ends with a", "a starts"}))
+}
+
+func TestRAGQuery_ContextExpansion(t *testing.T) {
+	embedServer := makeEmbedServer([]float64{1, 0})
+	defer embedServer.Close()
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, mock.Anything).Return([]vectordb.SearchResult{hit("guide", 1, 3, 0.9)}, nil)
+	mc.On("ScrollDocuments", mock.Anything, mock.MatchedBy(func(r vectordb.ScrollRequest) bool {
+		return r.Filters[parentIDKey] == "guide"
+	})).Return(&vectordb.ScrollResult{Documents: storedChunks("guide", "", "", "")}, nil)
+
+	a := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  embedServer.URL,
+			EmbeddingModel:    "text-embedding-3-small",
+			DefaultCollection: "docs",
+			DefaultTopK:       1,
+			ContentField:      "text",
+			ContextFormat:     "plain",
+			TimeoutSeconds:    10,
+			ContextExpansion:  expansionNeighbors,
+			NeighborChunks:    1,
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "how do I install it?"}}
+
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "guide c0\n\nguide c1\n\nguide c2", ctx.outputs["formattedContext"])
+	docs := ctx.outputs["sourceDocuments"].([]interface{})
+	require.Len(t, docs, 1)
+	assert.Equal(t, "guide-chunk-1", docs[0].(map[string]interface{})["id"])
+	mc.AssertExpectations(t)
+}
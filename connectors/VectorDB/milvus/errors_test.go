@@ -99,9 +99,16 @@ func TestMilvusSafeFieldName_AllSpecialChars(t *testing.T) {
 // buildMilvusExpr — non-schema numeric, bool, multi-key
 // ---------------------------------------------------------------------------
 
+func mustMilvusExpr(t *testing.T, filters map[string]interface{}) string {
+	t.Helper()
+	expr, _, err := milvusFilter(filters)
+	require.NoError(t, err)
+	return expr
+}
+
 func TestBuildMilvusExpr_NonSchemaNumericFloat(t *testing.T) {
 	// float64 non-schema field → _metadata LIKE with numeric pattern
-	expr := buildMilvusExpr(map[string]interface{}{"rank": float64(3)})
+	expr := mustMilvusExpr(t, map[string]interface{}{"rank": float64(3)})
 	assert.Contains(t, expr, "_metadata like")
 	assert.Contains(t, expr, `"rank"`)
 	assert.Contains(t, expr, "3")
@@ -110,14 +117,14 @@ func TestBuildMilvusExpr_NonSchemaNumericFloat(t *testing.T) {
 }
 
 func TestBuildMilvusExpr_NonSchemaNumericInt(t *testing.T) {
-	expr := buildMilvusExpr(map[string]interface{}{"rank": int(5)})
+	expr := mustMilvusExpr(t, map[string]interface{}{"rank": int(5)})
 	assert.Contains(t, expr, "_metadata like")
 	assert.Contains(t, expr, `"rank"`)
 	assert.Contains(t, expr, "5")
 }
 
 func TestBuildMilvusExpr_NonSchemaBool(t *testing.T) {
-	expr := buildMilvusExpr(map[string]interface{}{"active": true})
+	expr := mustMilvusExpr(t, map[string]interface{}{"active": true})
 	assert.Contains(t, expr, "_metadata like")
 	assert.Contains(t, expr, `"active"`)
 	assert.Contains(t, expr, "true")
@@ -125,7 +132,7 @@ func TestBuildMilvusExpr_NonSchemaBool(t *testing.T) {
 
 func TestBuildMilvusExpr_MultiKey_SchemaAndPayload(t *testing.T) {
 	// One schema column + one payload field → direct expr AND LIKE
-	expr := buildMilvusExpr(map[string]interface{}{
+	expr := mustMilvusExpr(t, map[string]interface{}{
 		"_id":      "doc-1",
 		"category": "tech",
 	})
@@ -136,7 +143,7 @@ func TestBuildMilvusExpr_MultiKey_SchemaAndPayload(t *testing.T) {
 
 func TestBuildMilvusExpr_MultiKey_AllPayload(t *testing.T) {
 	// Multiple non-schema keys → multiple LIKE clauses joined by &&
-	expr := buildMilvusExpr(map[string]interface{}{
+	expr := mustMilvusExpr(t, map[string]interface{}{
 		"category": "tech",
 		"active":   true,
 	})
@@ -212,7 +219,7 @@ func TestMilvusEscapeLike_NoSpecialChars(t *testing.T) {
 
 func TestBuildMilvusExpr_NonSchemaStringWithUnderscore_EscapedInLike(t *testing.T) {
 	// Field name "price_usd" → key part in LIKE must be "price\_usd"
-	expr := buildMilvusExpr(map[string]interface{}{"price_usd": "hello_world"})
+	expr := mustMilvusExpr(t, map[string]interface{}{"price_usd": "hello_world"})
 	assert.Contains(t, expr, "_metadata like")
 	assert.Contains(t, expr, `price\_usd`)
 	assert.Contains(t, expr, `hello\_world`)
@@ -220,7 +227,7 @@ func TestBuildMilvusExpr_NonSchemaStringWithUnderscore_EscapedInLike(t *testing.
 
 func TestBuildMilvusExpr_NonSchemaStringWithPercent_EscapedInLike(t *testing.T) {
 	// Value "50% off" → value part in LIKE must escape %
-	expr := buildMilvusExpr(map[string]interface{}{"discount": "50% off"})
+	expr := mustMilvusExpr(t, map[string]interface{}{"discount": "50% off"})
 	assert.Contains(t, expr, "_metadata like")
 	assert.Contains(t, expr, `50\% off`)
 	// Must not contain a bare % wildcard for the value portion
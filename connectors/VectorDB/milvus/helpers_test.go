@@ -3,6 +3,7 @@ package vectordb
 import (
 	"testing"
 
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,6 +34,66 @@ func TestNormalizeDistanceMetric(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// normalizeFusion
+// ---------------------------------------------------------------------------
+
+func TestNormalizeFusion(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"", "weighted"},
+		{"weighted", "weighted"},
+		{"RRF", "rrf"},
+		{" rrf ", "rrf"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := normalizeFusion(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNormalizeFusion_Invalid(t *testing.T) {
+	_, err := normalizeFusion("dbsf")
+	require.Error(t, err)
+	var ve *VDBError
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, ErrCodeInvalidFusion, ve.Code)
+}
+
+// ---------------------------------------------------------------------------
+// milvusDenseSearchParam
+// ---------------------------------------------------------------------------
+
+func TestMilvusDenseSearchParam(t *testing.T) {
+	cases := []struct {
+		indexType entity.IndexType
+		limit     int
+		want      map[string]interface{}
+	}{
+		{entity.HNSW, 10, map[string]interface{}{"ef": milvusSearchListMin}},
+		{entity.HNSW, 200, map[string]interface{}{"ef": 200}},
+		{entity.DISKANN, 200, map[string]interface{}{"search_list": 200}},
+		{entity.IvfFlat, 200, map[string]interface{}{"nprobe": milvusSearchNProbe}},
+		{entity.IvfPQ, 10, map[string]interface{}{"nprobe": milvusSearchNProbe}},
+		{entity.SCANN, 10, map[string]interface{}{"nprobe": milvusSearchNProbe, "reorder_k": milvusSearchListMin}},
+		{entity.Flat, 10, map[string]interface{}{}},
+		{entity.AUTOINDEX, 10, map[string]interface{}{"level": 1}},
+		{entity.IndexType("FUTURE_INDEX"), 10, map[string]interface{}{"level": 1}},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.indexType), func(t *testing.T) {
+			sp, err := milvusDenseSearchParam(tc.indexType, tc.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, sp.Params())
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // validateCollectionConfig
 // ---------------------------------------------------------------------------
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	milvusclient "github.com/milvus-io/milvus-sdk-go/v2/client"
@@ -17,10 +18,18 @@ import (
 type milvusClient struct {
 	client milvusclient.Client
 	cfg    ConnectionConfig
+
+	// sparseCollections caches, per collection name, whether the collection
+	// declares the milvusSparseField sparse vector field (bool values).
+	sparseCollections sync.Map
+	// vectorIndexTypes caches, per collection name, the index type of the
+	// dense "vector" field (entity.IndexType values).
+	vectorIndexTypes sync.Map
 }
 
 // Compile-time proof that milvusClient satisfies the full VectorDBClient interface.
 var _ VectorDBClient = (*milvusClient)(nil)
+var _ MetricReader = (*milvusClient)(nil)
 
 // milvusMaxVarCharLen is the maximum VarChar field length configured for
 // the content and _metadata schema fields. Documents exceeding this limit
@@ -127,6 +136,11 @@ func (c *milvusClient) CreateCollection(ctx context.Context, cfg CollectionConfi
 			WithName("vector").
 			WithDataType(entity.FieldTypeFloatVector).
 			WithDim(int64(cfg.Dimensions)))
+	if cfg.EnableSparse {
+		schema.WithField(entity.NewField().
+			WithName(milvusSparseField).
+			WithDataType(entity.FieldTypeSparseVector))
+	}
 
 	// Milvus 2.4 does not return an error when a collection with the same name
 	// already exists — it silently succeeds. We check explicitly so callers
@@ -159,12 +173,24 @@ func (c *milvusClient) CreateCollection(ctx context.Context, cfg CollectionConfi
 	if err = c.client.CreateIndex(ctx, cfg.Name, "vector", idx, false); err != nil {
 		return newError(ErrCodeProviderError, "CreateCollection: failed to create vector index", err)
 	}
+	if cfg.EnableSparse {
+		// Sparse vectors only support inner product; drop_ratio_build=0 keeps
+		// every term so short keyword queries still match.
+		sparseIdx, err := entity.NewIndexSparseInverted(entity.IP, 0)
+		if err != nil {
+			return newError(ErrCodeProviderError, "CreateCollection: failed to build sparse index params", err)
+		}
+		if err = c.client.CreateIndex(ctx, cfg.Name, milvusSparseField, sparseIdx, false); err != nil {
+			return newError(ErrCodeProviderError, "CreateCollection: failed to create sparse index", err)
+		}
+	}
 
 	// Load collection into memory so it is immediately queryable
 	if err = c.client.LoadCollection(ctx, cfg.Name, false); err != nil {
 		return newError(ErrCodeProviderError, "CreateCollection: failed to load collection", err)
 	}
 
+	c.sparseCollections.Store(cfg.Name, cfg.EnableSparse)
 	return nil
 }
 
@@ -174,9 +200,98 @@ func (c *milvusClient) DeleteCollection(ctx context.Context, name string) error
 	}); err != nil {
 		return newError(ErrCodeProviderError, "DeleteCollection failed", err)
 	}
+	c.sparseCollections.Delete(name)
+	c.vectorIndexTypes.Delete(name)
 	return nil
 }
 
+// collectionHasSparse reports whether the collection declares the connector's
+// sparse vector field. The answer is cached per collection; collections that
+// are dropped and recreated through this client refresh the cache.
+func (c *milvusClient) collectionHasSparse(ctx context.Context, name string) (bool, error) {
+	if v, ok := c.sparseCollections.Load(name); ok {
+		return v.(bool), nil
+	}
+	var coll *entity.Collection
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var retryErr error
+		coll, retryErr = c.client.DescribeCollection(ctx, name)
+		return retryErr
+	}); err != nil {
+		return false, err
+	}
+	has := false
+	if coll.Schema != nil {
+		for _, f := range coll.Schema.Fields {
+			if f.Name == milvusSparseField && f.DataType == entity.FieldTypeSparseVector {
+				has = true
+				break
+			}
+		}
+	}
+	c.sparseCollections.Store(name, has)
+	return has, nil
+}
+
+// vectorIndexType returns the index type of the collection's dense "vector"
+// field, cached per collection. Collections created by CreateCollection use
+// HNSW; collections created elsewhere may use any dense index.
+func (c *milvusClient) vectorIndexType(ctx context.Context, name string) (entity.IndexType, error) {
+	if v, ok := c.vectorIndexTypes.Load(name); ok {
+		return v.(entity.IndexType), nil
+	}
+	var indexes []entity.Index
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var retryErr error
+		indexes, retryErr = c.client.DescribeIndex(ctx, name, "vector")
+		return retryErr
+	}); err != nil {
+		return "", err
+	}
+	if len(indexes) == 0 {
+		return "", fmt.Errorf("collection %q has no index on the vector field", name)
+	}
+	it := indexes[0].IndexType()
+	c.vectorIndexTypes.Store(name, it)
+	return it, nil
+}
+
+// milvusDenseSearchParam builds the search parameters matching the dense
+// index type, sized for a request of limit results: HNSW and DiskANN search
+// lists are at least limit long, IVF indexes probe milvusSearchNProbe
+// clusters. Unknown index types use AUTOINDEX parameters, which Milvus
+// accepts for any index.
+func milvusDenseSearchParam(indexType entity.IndexType, limit int) (entity.SearchParam, error) {
+	width := limit
+	if width < milvusSearchListMin {
+		width = milvusSearchListMin
+	}
+	switch indexType {
+	case entity.HNSW:
+		return entity.NewIndexHNSWSearchParam(width)
+	case entity.IvfHNSW:
+		return entity.NewIndexIvfHNSWSearchParam(milvusSearchNProbe, width)
+	case entity.DISKANN:
+		return entity.NewIndexDISKANNSearchParam(width)
+	case entity.IvfFlat:
+		return entity.NewIndexIvfFlatSearchParam(milvusSearchNProbe)
+	case entity.IvfSQ8:
+		return entity.NewIndexIvfSQ8SearchParam(milvusSearchNProbe)
+	case entity.IvfPQ:
+		return entity.NewIndexIvfPQSearchParam(milvusSearchNProbe)
+	case entity.SCANN:
+		return entity.NewIndexSCANNSearchParam(milvusSearchNProbe, width)
+	case entity.GPUIvfFlat:
+		return entity.NewIndexGPUIvfFlatSearchParam(milvusSearchNProbe)
+	case entity.GPUIvfPQ:
+		return entity.NewIndexGPUIvfPQSearchParam(milvusSearchNProbe)
+	case entity.Flat:
+		return entity.NewIndexFlatSearchParam()
+	default:
+		return entity.NewIndexAUTOINDEXSearchParam(1)
+	}
+}
+
 func (c *milvusClient) ListCollections(ctx context.Context) ([]string, error) {
 	var cols []*entity.Collection
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
@@ -205,6 +320,32 @@ func (c *milvusClient) CollectionExists(ctx context.Context, name string) (bool,
 	return ok, nil
 }
 
+// CollectionMetric returns the metric type of the index on the dense
+// "vector" field.
+func (c *milvusClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	var indexes []entity.Index
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var retryErr error
+		indexes, retryErr = c.client.DescribeIndex(ctx, name, "vector")
+		return retryErr
+	}); err != nil {
+		return "", newError(ErrCodeProviderError, "CollectionMetric failed", err)
+	}
+	if len(indexes) == 0 {
+		return "", nil
+	}
+	metric := indexes[0].Params()["metric_type"]
+	switch entity.MetricType(strings.ToUpper(metric)) {
+	case entity.COSINE:
+		return "cosine", nil
+	case entity.IP:
+		return "dot", nil
+	case entity.L2:
+		return "euclidean", nil
+	}
+	return strings.ToLower(metric), nil
+}
+
 // --- Document operations ---
 
 func (c *milvusClient) UpsertDocuments(ctx context.Context, collectionName string, docs []Document) error {
@@ -251,9 +392,29 @@ func (c *milvusClient) UpsertDocuments(ctx context.Context, collectionName strin
 	contentCol := entity.NewColumnVarChar("content", contents)
 	metaCol := entity.NewColumnVarChar("_metadata", metadatas)
 	vectorCol := entity.NewColumnFloatVector("vector", len(docs[0].Vector), vectors)
+	columns := []entity.Column{idCol, contentCol, metaCol, vectorCol}
+
+	hasSparse, err := c.collectionHasSparse(ctx, collectionName)
+	if err != nil {
+		return newError(ErrCodeProviderError, "UpsertDocuments: failed to read collection schema", err)
+	}
+	if hasSparse {
+		// The sparse field is required on every row of such a collection;
+		// documents without terms get an empty sparse vector.
+		sparse := make([]entity.SparseEmbedding, len(docs))
+		for i, doc := range docs {
+			emb, err := entity.NewSliceSparseEmbedding(buildSparseVector(doc.Content))
+			if err != nil {
+				return newError(ErrCodeProviderError,
+					fmt.Sprintf("UpsertDocuments: document[%d] (id=%s) sparse vector", i, doc.ID), err)
+			}
+			sparse[i] = emb
+		}
+		columns = append(columns, entity.NewColumnSparseVectors(milvusSparseField, sparse))
+	}
 
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
-		_, insertErr := c.client.Upsert(ctx, collectionName, "", idCol, contentCol, metaCol, vectorCol)
+		_, insertErr := c.client.Upsert(ctx, collectionName, "", columns...)
 		return insertErr
 	}); err != nil {
 		return newError(ErrCodeProviderError, "UpsertDocuments failed", err)
@@ -340,10 +501,24 @@ func (c *milvusClient) DeleteDocuments(ctx context.Context, collectionName strin
 }
 
 func (c *milvusClient) DeleteByFilter(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
-	expr := buildMilvusExpr(filters)
-	if expr == "" {
+	expr, residual, err := milvusFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	if expr == "" && residual == nil {
 		return 0, newError(ErrCodeProviderError, "DeleteByFilter requires at least one filter", nil)
 	}
+	if residual != nil {
+		// Part of the filter is matched client-side: delete by ID instead.
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
 		return c.client.Delete(ctx, collectionName, "", expr)
 	}); err != nil {
@@ -353,7 +528,10 @@ func (c *milvusClient) DeleteByFilter(ctx context.Context, collectionName string
 }
 
 func (c *milvusClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (*ScrollResult, error) {
-	expr := buildMilvusExpr(req.Filters)
+	expr, residual, err := milvusFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	if expr == "" {
 		expr = "_id != \"\""
 	}
@@ -385,6 +563,15 @@ func (c *milvusClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 		if len(docs) == limit {
 			nextOffset = fmt.Sprintf("%d", offset+limit)
 		}
+		if residual != nil {
+			matched := docs[:0]
+			for _, d := range docs {
+				if residual.Match(milvusMatchFields(d.ID, d.Content, d.Payload)) {
+					matched = append(matched, d)
+				}
+			}
+			docs = matched
+		}
 		scrollResult = &ScrollResult{Documents: docs, NextOffset: nextOffset, Total: -1}
 		return nil
 	}); err != nil {
@@ -394,7 +581,17 @@ func (c *milvusClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 }
 
 func (c *milvusClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
-	expr := buildMilvusExpr(filters)
+	expr, residual, err := milvusFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	if expr == "" {
 		expr = "_id != \"\""
 	}
@@ -430,14 +627,16 @@ func (c *milvusClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 		return nil, newError(ErrCodeProviderError, "VectorSearch: failed to build search params", err)
 	}
 
+	expr, residual, err := milvusFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	queryVector := entity.FloatVector(toFloat32Slice(req.QueryVector))
 	outputFields := []string{"_id"}
-	if !req.SkipPayload {
+	if !req.SkipPayload || residual != nil {
 		outputFields = append(outputFields, "content", "_metadata")
 	}
 
-	expr := buildMilvusExpr(req.Filters)
-
 	tCtx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSeconds)*time.Second)
 	defer cancel()
 
@@ -452,7 +651,7 @@ func (c *milvusClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 			[]entity.Vector{queryVector},
 			"vector",
 			milvusMetricType(c.cfg.DefaultMetricType),
-			req.TopK,
+			searchLimit(req.TopK, residual),
 			sp,
 		)
 		return sErr
@@ -460,24 +659,128 @@ func (c *milvusClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
 	}
 
-	return milvusSearchResultsToResults(searchResults, req.ScoreThreshold), nil
+	results := milvusSearchResultsToResults(searchResults, req.ScoreThreshold)
+	return milvusMatchResults(results, residual, req.TopK, req.SkipPayload), nil
 }
 
+// HybridSearch runs a native Milvus hybrid search when the collection was
+// created with CollectionConfig.EnableSparse: a dense ANN request on "vector"
+// and a keyword sparse ANN request on milvusSparseField are reranked server-side
+// by a WeightedRanker (weights Alpha, 1-Alpha) or an RRFRanker. Collections
+// without the sparse field fall back to dense search.
 func (c *milvusClient) HybridSearch(ctx context.Context, req HybridSearchRequest) ([]SearchResult, error) {
-	// Milvus 2.4+ supports hybrid search with sparse+dense vectors, but that
-	// requires a sparse vector field to be configured on the collection at
-	// creation time — which this connector does not yet provision.
-	// Fall back to dense vector search so the interface contract is honoured:
-	// "Providers without native hybrid support fall back to VectorSearch."
-	logger.Debugf("Milvus: HybridSearch falling back to dense VectorSearch (sparse+dense not provisioned)")
-	return c.VectorSearch(ctx, SearchRequest{
+	fusion, err := normalizeFusion(req.Fusion)
+	if err != nil {
+		return nil, err
+	}
+	denseOnly := SearchRequest{
 		CollectionName: req.CollectionName,
 		QueryVector:    req.QueryVector,
 		TopK:           req.TopK,
 		ScoreThreshold: req.ScoreThreshold,
 		Filters:        req.Filters,
 		SkipPayload:    req.SkipPayload,
-	})
+	}
+	if req.CollectionName == "" {
+		return nil, newError(ErrCodeInvalidCollectionName, "", nil)
+	}
+	if req.TopK <= 0 {
+		return nil, newError(ErrCodeInvalidTopK, fmt.Sprintf("topK=%d must be > 0", req.TopK), nil)
+	}
+
+	sparseIdx, sparseVals := buildSparseQuery(req.QueryText)
+	if len(sparseIdx) == 0 || req.Alpha >= 1 {
+		return c.VectorSearch(ctx, denseOnly)
+	}
+
+	tCtx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	hasSparse, err := c.collectionHasSparse(tCtx, req.CollectionName)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch failed: could not describe collection", err)
+	}
+	if !hasSparse {
+		logger.Warnf("Milvus: HybridSearch falling back to dense vector search — collection %q has no sparse field; "+
+			"recreate it with enableSparse=true to enable the keyword component.", req.CollectionName)
+		return c.VectorSearch(ctx, denseOnly)
+	}
+
+	sparseVec, err := entity.NewSliceSparseEmbedding(sparseIdx, sparseVals)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch: failed to build sparse query", err)
+	}
+	sparseSP, err := entity.NewIndexSparseInvertedSearchParam(0)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch: failed to build sparse search params", err)
+	}
+
+	expr, residual, err := milvusFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	limit := searchLimit(req.TopK, residual)
+	outputFields := []string{"_id"}
+	if !req.SkipPayload || residual != nil {
+		outputFields = append(outputFields, "content", "_metadata")
+	}
+
+	var searchResults []milvusclient.SearchResult
+	if len(req.QueryVector) == 0 || req.Alpha <= 0 {
+		// Sparse/keyword only.
+		if err := withRetry(tCtx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+			var sErr error
+			searchResults, sErr = c.client.Search(tCtx, req.CollectionName, nil, expr, outputFields,
+				[]entity.Vector{sparseVec}, milvusSparseField, entity.IP, limit, sparseSP)
+			return sErr
+		}); err != nil {
+			return nil, newError(ErrCodeProviderError, "HybridSearch failed", err)
+		}
+		return milvusMatchResults(milvusSearchResultsToResults(searchResults, 0), residual, req.TopK, req.SkipPayload), nil
+	}
+
+	// Each side over-fetches so the reranker has enough overlap to work with.
+	prefetch := limit * hybridPrefetchFactor
+	indexType, err := c.vectorIndexType(tCtx, req.CollectionName)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch failed: could not describe vector index", err)
+	}
+	denseSP, err := milvusDenseSearchParam(indexType, prefetch)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch: failed to build dense search params", err)
+	}
+	metric := milvusMetricType(c.cfg.DefaultMetricType)
+	// The caller's threshold is a dense similarity score, so it is applied to
+	// the dense request (as a range-search radius) rather than to the
+	// reranked score. L2 scores are distances, where a lower bound is
+	// meaningless, so the threshold is ignored there.
+	if req.ScoreThreshold > 0 && metric != entity.L2 {
+		denseSP.AddRadius(req.ScoreThreshold)
+	}
+
+	subRequests := []*milvusclient.ANNSearchRequest{
+		milvusclient.NewANNSearchRequest("vector", metric, expr,
+			[]entity.Vector{entity.FloatVector(toFloat32Slice(req.QueryVector))}, denseSP, prefetch),
+		milvusclient.NewANNSearchRequest(milvusSparseField, entity.IP, expr,
+			[]entity.Vector{sparseVec}, sparseSP, prefetch),
+	}
+	var reranker milvusclient.Reranker
+	switch fusion {
+	case "rrf":
+		reranker = milvusclient.NewRRFReranker()
+	default:
+		reranker = milvusclient.NewWeightedReranker([]float64{req.Alpha, 1 - req.Alpha})
+	}
+
+	if err := withRetry(tCtx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var sErr error
+		searchResults, sErr = c.client.HybridSearch(tCtx, req.CollectionName, nil, limit,
+			outputFields, reranker, subRequests)
+		return sErr
+	}); err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch failed", err)
+	}
+	return milvusMatchResults(milvusSearchResultsToResults(searchResults, 0), residual, req.TopK, req.SkipPayload), nil
 }
 
 // --- Helpers ---
@@ -492,125 +795,209 @@ var milvusSchemaFields = map[string]bool{
 	"_metadata": true,
 }
 
-// buildMilvusExpr converts a generic filter map to a Milvus boolean expression.
+// milvusFilter validates filters and converts them to a Milvus boolean
+// expression plus a residual that is matched client-side.
 //
-// Each entry can be a scalar (equality) or an operator map:
+// Conditions on schema fields (_id, content) are translated exactly, including
+// under $and / $or / $not:
 //
-//	Scalar:   {"field": "foo"}               → field == "foo"
-//	Operator: {"field": {"$gt": 5}}          → field > 5
-//	          {"field": {"$in": ["a","b"]}}   → field in ["a","b"]
-//	          {"field": {"$nin": [1,2,3]}}    → field not in [1,2,3]
-//	          {"field": {"$ne": "foo"}}       → field != "foo"
+//	{"_id": "a"}                        → _id == "a"
+//	{"_id": {"$in": ["a","b"]}}         → _id in ["a", "b"]
+//	{"$or": [{"_id": "a"}, {"content": "b"}]} → (_id == "a" || content == "b")
 //
-// Payload fields that are not first-class Milvus schema fields are
-// automatically routed to a LIKE wildcard on _metadata so that callers can
-// filter on arbitrary document payload keys. Only equality semantics
-// are used for non-schema payload fields.
-//
-// String values are quote-escaped to prevent expression injection.
-// Multiple entries are ANDed.
-func buildMilvusExpr(filters map[string]interface{}) string {
-	if len(filters) == 0 {
-		return ""
+// Payload keys live inside the _metadata VarChar JSON blob, which Milvus can
+// only search with LIKE. Payload conditions therefore stay in the residual;
+// top-level $eq, $in and $contains conditions are additionally pushed down as
+// LIKE pre-filters that never exclude a matching document, so the residual
+// only has to discard false positives.
+func milvusFilter(filters map[string]interface{}) (string, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return "", nil, err
 	}
-	parts := make([]string, 0, len(filters))
-	for k, v := range filters {
-		// Field names: only allow alphanumeric + underscore to prevent injection.
-		safeK := milvusSafeFieldName(k)
-
-		// Payload fields that are not top-level schema columns are stored as
-		// JSON inside _metadata.  For scalar equality values, route them to a
-		// LIKE substring match.  Operator-map values ($gt, $in, …) fall through
-		// to the direct-expression path below — the caller is responsible for
-		// ensuring such fields exist as schema columns.
-		if !milvusSchemaFields[k] {
-			_, isOpMap := v.(map[string]interface{})
-			if !isOpMap {
-				var fragment string
-				switch val := v.(type) {
-				case string:
-					keyBytes, _ := json.Marshal(k)
-					valBytes, _ := json.Marshal(val)
-					// Escape LIKE wildcards in both the key and value so that common
-					// field names (e.g. "price_usd") and values (e.g. "50% off") do
-					// not expand as wildcards, causing false-positive matches.
-					fragment = fmt.Sprintf(`%%"%s":%s%%`,
-						milvusEscapeLike(string(keyBytes[1:len(keyBytes)-1])),
-						milvusEscapeLike(string(valBytes)))
-				case int:
-					keyBytes, _ := json.Marshal(k)
-					fragment = fmt.Sprintf(`%%"%s":%d%%`, milvusEscapeLike(string(keyBytes[1:len(keyBytes)-1])), val)
-				case int64:
-					keyBytes, _ := json.Marshal(k)
-					fragment = fmt.Sprintf(`%%"%s":%d%%`, milvusEscapeLike(string(keyBytes[1:len(keyBytes)-1])), val)
-				case float64:
-					keyBytes, _ := json.Marshal(k)
-					fragment = fmt.Sprintf(`%%"%s":%g%%`, milvusEscapeLike(string(keyBytes[1:len(keyBytes)-1])), val)
-				case bool:
-					keyBytes, _ := json.Marshal(k)
-					fragment = fmt.Sprintf(`%%"%s":%v%%`, milvusEscapeLike(string(keyBytes[1:len(keyBytes)-1])), val)
-				default:
-					keyBytes, _ := json.Marshal(k)
-					valBytes, _ := json.Marshal(fmt.Sprintf("%v", val))
-					fragment = fmt.Sprintf(`%%"%s":%s%%`,
-						milvusEscapeLike(string(keyBytes[1:len(keyBytes)-1])),
-						milvusEscapeLike(string(valBytes)))
-				}
-				parts = append(parts, fmt.Sprintf("_metadata like '%s'", fragment))
-				continue
+	pushed, residual := splitFilter(expr, milvusNative)
+	var parts []string
+	if pushed != nil {
+		parts = append(parts, buildMilvusExpr(pushed))
+	}
+	if residual != nil {
+		for _, c := range residual.Conjuncts() {
+			if like := milvusLikeExpr(c); like != "" {
+				parts = append(parts, like)
 			}
 		}
+	}
+	return strings.Join(parts, " && "), residual, nil
+}
 
-		switch val := v.(type) {
-		case map[string]interface{}:
-			if expr := milvusOperatorExpr(safeK, val); expr != "" {
-				parts = append(parts, expr)
+// milvusNative reports whether e only references schema fields with
+// operators Milvus expressions support.
+func milvusNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterAnd, FilterOr, FilterNot:
+			return true
+		case FilterExists, FilterContains:
+			return false
+		case FilterIn, FilterNin:
+			if len(n.Values()) == 0 {
+				return false
 			}
-		case string:
-			parts = append(parts, fmt.Sprintf(`%s == "%s"`, safeK, milvusEscapeString(val)))
-		case int:
-			parts = append(parts, fmt.Sprintf(`%s == %d`, safeK, val))
-		case int64:
-			parts = append(parts, fmt.Sprintf(`%s == %d`, safeK, val))
-		case float64:
-			parts = append(parts, fmt.Sprintf(`%s == %g`, safeK, val))
-		case bool:
-			parts = append(parts, fmt.Sprintf(`%s == %v`, safeK, val))
-		default:
-			parts = append(parts, fmt.Sprintf(`%s == "%s"`, safeK, milvusEscapeString(fmt.Sprintf("%v", val))))
 		}
+		return milvusSchemaFields[n.Field] && n.Field != "_metadata"
+	})
+}
+
+// buildMilvusExpr converts a parsed filter on schema fields to a Milvus
+// boolean expression. String values are quote-escaped to prevent expression
+// injection.
+func buildMilvusExpr(e *FilterExpr) string {
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		parts := make([]string, len(e.Children))
+		for i, c := range e.Children {
+			parts[i] = buildMilvusExpr(c)
+			if c.Op == FilterAnd {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		if e.Op == FilterOr {
+			return "(" + strings.Join(parts, " || ") + ")"
+		}
+		return strings.Join(parts, " && ")
+	case FilterNot:
+		return "not (" + buildMilvusExpr(e.Children[0]) + ")"
+	}
+	key := milvusSafeFieldName(e.Field)
+	v := milvusOperand(e.Value)
+	switch e.Op {
+	case FilterEq:
+		return milvusEqExpr(key, v)
+	case FilterNe:
+		return milvusScalarExpr(key, "!=", v)
+	case FilterGt:
+		return milvusScalarExpr(key, ">", v)
+	case FilterGte:
+		return milvusScalarExpr(key, ">=", v)
+	case FilterLt:
+		return milvusScalarExpr(key, "<", v)
+	case FilterLte:
+		return milvusScalarExpr(key, "<=", v)
+	case FilterIn:
+		return milvusSetExpr(key, e.Value, false)
+	case FilterNin:
+		return milvusSetExpr(key, e.Value, true)
 	}
-	return strings.Join(parts, " && ")
+	return ""
+}
+
+// milvusOperand converts number types other than int, int64 and float64 to
+// float64, the types the expression helpers format.
+func milvusOperand(v interface{}) interface{} {
+	switch v.(type) {
+	case int, int64, float64:
+		return v
+	}
+	if f, ok := filterNumber(v); ok {
+		return f
+	}
+	return v
 }
 
-// milvusOperatorExpr builds a single expression fragment for one {"$op": val} map.
-func milvusOperatorExpr(key string, ops map[string]interface{}) string {
-	parts := make([]string, 0, len(ops))
-	for op, operand := range ops {
-		var expr string
-		switch op {
-		case "$eq":
-			expr = milvusEqExpr(key, operand)
-		case "$ne":
-			expr = milvusScalarExpr(key, "!=", operand)
-		case "$gt":
-			expr = milvusScalarExpr(key, ">", operand)
-		case "$gte":
-			expr = milvusScalarExpr(key, ">=", operand)
-		case "$lt":
-			expr = milvusScalarExpr(key, "<", operand)
-		case "$lte":
-			expr = milvusScalarExpr(key, "<=", operand)
-		case "$in":
-			expr = milvusSetExpr(key, operand, false)
-		case "$nin":
-			expr = milvusSetExpr(key, operand, true)
+// milvusLikeExpr returns a LIKE pre-filter on _metadata for a payload
+// condition, or "" when the condition has none. The pattern keys on the last
+// path segment and accepts the value either as the field value or inside an
+// array, so it matches at least every document the condition matches.
+func milvusLikeExpr(e *FilterExpr) string {
+	if e.IsLogical() || milvusSchemaFields[e.Field] {
+		return ""
+	}
+	path := e.Path()
+	key := path[len(path)-1]
+	switch e.Op {
+	case FilterEq:
+		return milvusLikeEq(key, e.Value)
+	case FilterIn:
+		items := e.Values()
+		if len(items) == 0 {
+			return ""
 		}
-		if expr != "" {
-			parts = append(parts, expr)
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = milvusLikeEq(key, item)
 		}
+		return "(" + strings.Join(parts, " || ") + ")"
+	case FilterContains:
+		return milvusLike(fmt.Sprintf(`%%"%s":[%%%s%%`, milvusLikeJSON(key), milvusLikeValue(e.Value)))
 	}
-	return strings.Join(parts, " && ")
+	return ""
+}
+
+func milvusLikeEq(key string, v interface{}) string {
+	k, val := milvusLikeJSON(key), milvusLikeValue(v)
+	return fmt.Sprintf("(%s || %s)",
+		milvusLike(fmt.Sprintf(`%%"%s":%s%%`, k, val)),
+		milvusLike(fmt.Sprintf(`%%"%s":[%%%s%%`, k, val)))
+}
+
+func milvusLike(pattern string) string {
+	return fmt.Sprintf("_metadata like '%s'", strings.ReplaceAll(pattern, "'", `\'`))
+}
+
+// milvusLikeJSON returns a payload key as it appears inside _metadata,
+// without the surrounding quotes, with LIKE wildcards escaped.
+func milvusLikeJSON(key string) string {
+	b, _ := json.Marshal(key)
+	return milvusEscapeLike(string(b[1 : len(b)-1]))
+}
+
+// milvusLikeValue returns a scalar as it appears inside _metadata, with LIKE
+// wildcards escaped. UpsertDocuments encodes payloads with encoding/json, so
+// numbers and strings are formatted the same way here.
+func milvusLikeValue(v interface{}) string {
+	if f, ok := filterNumber(v); ok {
+		v = f
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%v", v))
+	}
+	return milvusEscapeLike(string(b))
+}
+
+// milvusMatchFields returns the fields a residual filter is evaluated
+// against: the payload plus the _id and content schema fields, which a
+// residual can reference under $or / $not.
+func milvusMatchFields(id, content string, payload map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(payload)+2)
+	for k, v := range payload {
+		fields[k] = v
+	}
+	fields["_id"] = id
+	fields["content"] = content
+	return fields
+}
+
+// milvusMatchResults keeps the search results that match residual, up to
+// topK, and drops the payloads fetched for matching when skipPayload is set.
+func milvusMatchResults(results []SearchResult, residual *FilterExpr, topK int, skipPayload bool) []SearchResult {
+	if residual == nil {
+		return results
+	}
+	out := results[:0]
+	for _, r := range results {
+		if !residual.Match(milvusMatchFields(r.ID, r.Content, r.Payload)) {
+			continue
+		}
+		if skipPayload {
+			r.Payload, r.Content = nil, ""
+		}
+		out = append(out, r)
+		if len(out) == topK {
+			break
+		}
+	}
+	return out
 }
 
 func milvusEqExpr(key string, v interface{}) string {
@@ -653,7 +1040,7 @@ func milvusSetExpr(key string, operand interface{}, notIn bool) string {
 	}
 	parts := make([]string, 0, len(items))
 	for _, item := range items {
-		switch val := item.(type) {
+		switch val := milvusOperand(item).(type) {
 		case string:
 			parts = append(parts, fmt.Sprintf(`"%s"`, milvusEscapeString(val)))
 		case int:
@@ -713,3 +713,65 @@ func TestMilvus_Integration(t *testing.T) {
 		countFiltersSupported: true,
 	})
 }
+
+// TestMilvus_NativeHybrid_Integration exercises the sparse+dense hybrid path on
+// a collection created with EnableSparse: a keyword-only query for an error code
+// must surface the one document containing it even though its dense vector is
+// the furthest from the query vector.
+func TestMilvus_NativeHybrid_Integration(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	client, err := vectordb.NewClient(ctx, vectordb.ConnectionConfig{
+		Host:           "localhost",
+		Port:           19530,
+		TimeoutSeconds: 30,
+	})
+	require.NoError(t, err, "NewClient")
+	defer client.Close()
+
+	colName := fmt.Sprintf("integhybrid%d", time.Now().UnixMilli())
+	require.NoError(t, client.CreateCollection(ctx, vectordb.CollectionConfig{
+		Name:         colName,
+		Dimensions:   testDimensions,
+		EnableSparse: true,
+	}))
+	defer client.DeleteCollection(ctx, colName) //nolint
+
+	require.NoError(t, client.UpsertDocuments(ctx, colName, []vectordb.Document{
+		{ID: "near-1", Vector: makeVec(0), Content: "How to reset the router to factory defaults"},
+		{ID: "near-2", Vector: makeVec(10), Content: "Router firmware upgrade guide"},
+		{ID: "far-code", Vector: makeVec(180), Content: "Fix for error ERR-4012 during provisioning"},
+	}))
+
+	t.Run("SparseOnly", func(t *testing.T) {
+		results, err := client.HybridSearch(ctx, vectordb.HybridSearchRequest{
+			CollectionName: colName,
+			QueryText:      "ERR-4012",
+			TopK:           3,
+			Alpha:          0,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "far-code", results[0].ID)
+	})
+
+	for _, fusion := range []string{"weighted", "rrf"} {
+		t.Run("Fusion_"+fusion, func(t *testing.T) {
+			results, err := client.HybridSearch(ctx, vectordb.HybridSearchRequest{
+				CollectionName: colName,
+				QueryText:      "ERR-4012",
+				QueryVector:    makeVec(0),
+				TopK:           3,
+				Alpha:          0.5,
+				Fusion:         fusion,
+			})
+			require.NoError(t, err)
+			ids := make([]string, len(results))
+			for i, r := range results {
+				ids[i] = r.ID
+			}
+			assert.Contains(t, ids, "far-code", "keyword match must survive reranking")
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package vectordb
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// milvusSparseField is the SPARSE_FLOAT_VECTOR field added to collections
+// created with CollectionConfig.EnableSparse. Collections created without it
+// keep the original four-field schema and work unchanged.
+const milvusSparseField = "sparse"
+
+// Term-frequency saturation parameters. Sparse weights are computed
+// client-side because the Milvus 2.5 built-in BM25 function needs a collection
+// function schema that the v2.4 Go SDK cannot declare, and client-computed
+// weights also work against Milvus 2.4 servers. The weights are the
+// length-normalised, saturated TF component of BM25 only: the inverted index
+// stores them as-is and Milvus adds no IDF, so rare and common terms weigh the
+// same. This is a keyword-overlap score, not BM25.
+const (
+	tfK1            = 1.2
+	tfB             = 0.75
+	tfAvgDocLen     = 256.0
+	maxSparseTokens = 4096
+)
+
+// sparseTokenize splits text into lower-case terms suitable for keyword
+// matching. Letters, digits and the joiners '-', '_' and '.' are kept inside
+// a term so identifiers such as error codes ("ERR-4012") and product SKUs
+// ("SKU_88.1") survive as single tokens; leading/trailing joiners are trimmed.
+func sparseTokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.'
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, "-_.")
+		if f == "" {
+			continue
+		}
+		tokens = append(tokens, f)
+		if len(tokens) >= maxSparseTokens {
+			break
+		}
+	}
+	return tokens
+}
+
+// sparseTermIndex hashes a term to its sparse-vector dimension with FNV-32a.
+// Milvus accepts indices in [0, 2^32-1), so the one out-of-range hash value
+// is folded onto its neighbour.
+func sparseTermIndex(term string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(term))
+	if idx := h.Sum32(); idx != math.MaxUint32 {
+		return idx
+	}
+	return math.MaxUint32 - 1
+}
+
+// buildSparseVector builds the document-side sparse vector for text from
+// saturated, length-normalised term frequencies (no IDF, see tfK1):
+//
+//	w(t) = tf * (k1 + 1) / (tf + k1 * (1 - b + b * |d| / avgdl))
+//
+// Entries are sorted by index so identical inputs produce identical vectors.
+// Returns nil, nil for text without any terms.
+func buildSparseVector(text string) (indices []uint32, values []float32) {
+	tokens := sparseTokenize(text)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	tf := make(map[uint32]int, len(tokens))
+	for _, tok := range tokens {
+		tf[sparseTermIndex(tok)]++
+	}
+	norm := tfK1 * (1 - tfB + tfB*float64(len(tokens))/tfAvgDocLen)
+	weights := make(map[uint32]float32, len(tf))
+	for idx, cnt := range tf {
+		f := float64(cnt)
+		weights[idx] = float32(f * (tfK1 + 1) / (f + norm))
+	}
+	return sortedSparse(weights)
+}
+
+// buildSparseQuery builds the query-side sparse vector for text. Every
+// distinct term gets weight 1.0, so the inner-product score is the sum of the
+// stored document weights of the query terms.
+func buildSparseQuery(text string) (indices []uint32, values []float32) {
+	tokens := sparseTokenize(text)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	weights := make(map[uint32]float32, len(tokens))
+	for _, tok := range tokens {
+		weights[sparseTermIndex(tok)] = 1
+	}
+	return sortedSparse(weights)
+}
+
+func sortedSparse(weights map[uint32]float32) ([]uint32, []float32) {
+	indices := make([]uint32, 0, len(weights))
+	for idx := range weights {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	values := make([]float32, len(indices))
+	for i, idx := range indices {
+		values[i] = weights[idx]
+	}
+	return indices, values
+}
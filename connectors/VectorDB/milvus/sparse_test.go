@@ -0,0 +1,64 @@
+package vectordb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// sparseTokenize
+// ---------------------------------------------------------------------------
+
+func TestSparseTokenize_KeepsIdentifiers(t *testing.T) {
+	got := sparseTokenize("Error ERR-4012 on SKU_88.1, see docs.")
+	assert.Equal(t, []string{"error", "err-4012", "on", "sku_88.1", "see", "docs"}, got)
+}
+
+func TestSparseTokenize_Empty(t *testing.T) {
+	assert.Empty(t, sparseTokenize(""))
+	assert.Empty(t, sparseTokenize(" -- ... __ "))
+}
+
+// ---------------------------------------------------------------------------
+// buildSparseVector / buildSparseQuery
+// ---------------------------------------------------------------------------
+
+func TestBuildSparseVector_Deterministic(t *testing.T) {
+	i1, v1 := buildSparseVector("reset the router then reset the modem")
+	i2, v2 := buildSparseVector("reset the router then reset the modem")
+	assert.Equal(t, i1, i2)
+	assert.Equal(t, v1, v2)
+	for j := 1; j < len(i1); j++ {
+		assert.Less(t, i1[j-1], i1[j], "indices must be sorted ascending")
+	}
+}
+
+func TestBuildSparseVector_TermFrequencySaturates(t *testing.T) {
+	indices, values := buildSparseVector("reset reset reset modem")
+	require.Len(t, indices, 2)
+	weights := map[uint32]float32{}
+	for j, idx := range indices {
+		weights[idx] = values[j]
+	}
+	reset := weights[sparseTermIndex("reset")]
+	modem := weights[sparseTermIndex("modem")]
+	assert.Greater(t, reset, modem, "repeated term must weigh more")
+	assert.Less(t, reset, 3*modem, "saturation must keep tf=3 below 3x tf=1")
+	assert.Less(t, float64(reset), tfK1+1, "weight is bounded by k1+1")
+}
+
+func TestBuildSparseVector_Empty(t *testing.T) {
+	indices, values := buildSparseVector("")
+	assert.Nil(t, indices)
+	assert.Nil(t, values)
+}
+
+func TestBuildSparseQuery_UnitWeights(t *testing.T) {
+	indices, values := buildSparseQuery("ERR-4012 err-4012 router")
+	require.Len(t, indices, 2)
+	for _, v := range values {
+		assert.Equal(t, float32(1), v)
+	}
+}
@@ -51,6 +51,12 @@ type CollectionConfig struct {
 	// ReplicationFactor sets the replica count for Weaviate / Milvus clusters.
 	// Defaults to 1.
 	ReplicationFactor int
+
+	// EnableSparse adds a SPARSE_FLOAT_VECTOR field alongside the dense one.
+	// Documents upserted into such a collection get term-frequency weights
+	// computed from Content, and HybridSearch runs a native Milvus hybrid search instead
+	// of falling back to dense-only search.
+	EnableSparse bool
 }
 
 // SearchRequest encapsulates a vector similarity search.
@@ -68,7 +74,9 @@ type SearchRequest struct {
 	ScoreThreshold float64
 
 	// Filters is a provider-agnostic metadata filter expressed as a map.
-	// Supported operators per key: {"$eq", "$ne", "$gt", "$gte", "$lt", "$lte", "$in"}.
+	// Keys are payload paths (nested keys dot-separated) or $and / $or / $not;
+	// field operators are $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $exists
+	// and $contains. See ParseFilter.
 	Filters map[string]interface{}
 
 	// WithVectors includes the stored vector in each SearchResult.
@@ -98,6 +106,13 @@ type HybridSearchRequest struct {
 	// Alpha weights the fusion: 0.0 = sparse/BM25 only, 1.0 = dense only, 0.5 = balanced.
 	Alpha float64
 
+	// Fusion selects the Milvus reranker that combines the dense and sparse
+	// result lists: "weighted" (WeightedRanker with weights Alpha and
+	// 1-Alpha) or "rrf" (RRFRanker, k=60). Empty defaults to "weighted".
+	// RRF has no per-source weights, so Alpha only selects the single-source
+	// paths at 0.0 and 1.0.
+	Fusion string
+
 	// SkipPayload suppresses payload/metadata in each SearchResult when true.
 	// The zero value (false) is the safe default: payload is included.
 	// Mirrors SearchRequest.SkipPayload for uniform behaviour across search operations.
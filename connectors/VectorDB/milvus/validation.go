@@ -32,6 +32,31 @@ func normalizeDistanceMetric(metric string) string {
 	return m
 }
 
+// hybridPrefetchFactor is how many candidates each side of a native hybrid
+// search fetches, as a multiple of TopK, before reranking.
+const hybridPrefetchFactor = 4
+
+// Dense search parameters for milvusDenseSearchParam: HNSW/DiskANN search
+// lists hold at least milvusSearchListMin candidates (the previous fixed
+// HNSW ef), IVF-family indexes probe milvusSearchNProbe clusters.
+const (
+	milvusSearchListMin = 64
+	milvusSearchNProbe  = 16
+)
+
+// normalizeFusion lowercases and validates a HybridSearchRequest.Fusion value,
+// returning "weighted" for empty input.
+func normalizeFusion(fusion string) (string, error) {
+	f := strings.ToLower(strings.TrimSpace(fusion))
+	switch f {
+	case "":
+		return "weighted", nil
+	case "weighted", "rrf":
+		return f, nil
+	}
+	return "", newError(ErrCodeInvalidFusion, fmt.Sprintf("fusion %q is invalid; use: weighted, rrf", fusion), nil)
+}
+
 // validateSearchRequest validates common search parameters.
 func validateSearchRequest(collectionName string, queryVector []float64, topK int) error {
 	if collectionName == "" {
@@ -45,3 +45,54 @@ func DestroyEnvironment() error {
 func EnsureOnnxRuntimeSharedLibrary(_ ...BootstrapOption) (string, error) {
 	return "", errNotSupported
 }
+
+// Shape is a tensor shape (stub).
+type Shape []int64
+
+// Value is an input or output value of a session (stub).
+type Value interface {
+	Destroy() error
+}
+
+// TensorData lists the element types a Tensor can hold (stub).
+type TensorData interface {
+	~float32 | ~float64 | ~int8 | ~uint8 | ~int16 | ~uint16 | ~int32 | ~uint32 | ~int64 | ~uint64
+}
+
+// Tensor is a no-op stub for ort.Tensor.
+type Tensor[T TensorData] struct {
+	data []T
+}
+
+// NewTensor always returns errNotSupported.
+func NewTensor[T TensorData](_ Shape, _ []T) (*Tensor[T], error) {
+	return nil, errNotSupported
+}
+
+// NewEmptyTensor always returns errNotSupported.
+func NewEmptyTensor[T TensorData](_ Shape) (*Tensor[T], error) {
+	return nil, errNotSupported
+}
+
+// GetData returns the (empty) backing slice.
+func (t *Tensor[T]) GetData() []T { return t.data }
+
+// Destroy always returns errNotSupported.
+func (t *Tensor[T]) Destroy() error { return errNotSupported }
+
+// SessionOptions configures a session (stub).
+type SessionOptions struct{}
+
+// AdvancedSession is a no-op stub for ort.AdvancedSession.
+type AdvancedSession struct{}
+
+// NewAdvancedSession always returns errNotSupported.
+func NewAdvancedSession(_ string, _, _ []string, _, _ []Value, _ *SessionOptions) (*AdvancedSession, error) {
+	return nil, errNotSupported
+}
+
+// Run always returns errNotSupported.
+func (s *AdvancedSession) Run() error { return errNotSupported }
+
+// Destroy always returns errNotSupported.
+func (s *AdvancedSession) Destroy() error { return errNotSupported }
@@ -0,0 +1,90 @@
+package evaluateRetrieval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// rerankHTTPClient is a package-level client with explicit dial and TLS
+// timeouts; the overall deadline comes from the caller's context.
+var rerankHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// rerankResult is a single ranked document in the rerank response.
+type rerankResult struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+// newAPIReranker returns a reranker that calls a Cohere/Jina-compatible
+// rerank endpoint. Requests are not retried: a retry would be counted in
+// the measured latency, so a failure is reported as a failed query instead.
+func newAPIReranker(endpoint, apiKey, model string) reranker {
+	return func(ctx context.Context, query string, documents []string, topN int) ([]int, error) {
+		payload, err := json.Marshal(map[string]interface{}{
+			"model":     model,
+			"query":     query,
+			"documents": documents,
+			"top_n":     topN,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		resp, err := rerankHTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("http request: %w", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+		if err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("endpoint returned HTTP %d: %s", resp.StatusCode, string(body))
+		}
+		var parsed struct {
+			Results []rerankResult `json:"results"`
+			// Some providers wrap the results in "data".
+			Data []rerankResult `json:"data"`
+		}
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return nil, fmt.Errorf("unmarshal response: %w", err)
+		}
+		results := parsed.Results
+		if len(results) == 0 {
+			results = parsed.Data
+		}
+		// Providers return results best first, but sort defensively.
+		sort.SliceStable(results, func(i, j int) bool { return results[i].RelevanceScore > results[j].RelevanceScore })
+		order := make([]int, len(results))
+		for i, r := range results {
+			order[i] = r.Index
+		}
+		return order, nil
+	}
+}
@@ -38,6 +38,8 @@ type Settings struct {
 	EnableLLMGenerate bool `md:"enableLLMGenerate"`
 
 	// --- LLM Generation (only used when EnableLLMGenerate=true) ---
+	// LLMProvider is one of "Ollama", "OpenAI", "Azure OpenAI", "Anthropic",
+	// "Cohere" or "Custom" (any OpenAI-compatible API).
 	LLMProvider  string  `md:"llmProvider"`
 	LLMBaseURL   string  `md:"llmBaseURL"`
 	LLMAPIKey    string  `md:"llmAPIKey"`
@@ -45,6 +47,53 @@ type Settings struct {
 	SystemPrompt string  `md:"systemPrompt"`
 	MaxTokens    int     `md:"maxTokens"`
 	Temperature  float64 `md:"temperature"`
+	// LLMAPIVersion is the Azure OpenAI api-version. Default: 2024-10-21.
+	LLMAPIVersion string `md:"llmAPIVersion"`
+
+	// EnableCitations asks the LLM to cite context documents as [n] and maps
+	// each answer sentence to sourceDocuments IDs in the citations output.
+	EnableCitations bool `md:"enableCitations"`
+
+	// EnableStreaming streams the answer tokens as server-sent events through
+	// the SSE trigger registered as SSEServerRef (default "default"). The
+	// target connection or topic is chosen per request.
+	EnableStreaming bool   `md:"enableStreaming"`
+	SSEServerRef    string `md:"sseServerRef"`
+
+	// --- Query transformation (uses the LLM settings above) ---
+	// RetrievalMode is "standard" (default), "multiQuery" (search
+	// QueryVariants LLM-generated rephrasings and fuse the results with
+	// reciprocal rank fusion) or "hyde" (embed an LLM-written hypothetical
+	// answer instead of the question).
+	RetrievalMode string `md:"retrievalMode"`
+	QueryVariants int    `md:"queryVariants"`
+	// EnableQueryRewrite rewrites a follow-up question into a standalone
+	// query using the chatHistory input before retrieval.
+	EnableQueryRewrite bool `md:"enableQueryRewrite"`
+
+	// EnableMMR re-selects the retrieved documents with maximal marginal
+	// relevance: MMRFetchK candidates are fetched per search and a diverse
+	// top-K is kept. MMRLambda weighs relevance (1.0) against diversity (0.0).
+	EnableMMR bool    `md:"enableMMR"`
+	MMRLambda float64 `md:"mmrLambda"`
+	MMRFetchK int     `md:"mmrFetchK"`
+
+	// ContextExpansion replaces each retrieved chunk with a larger window of
+	// its document before the context is formatted: "none" (default),
+	// "neighbors" (NeighborChunks chunks on each side) or "parent" (the
+	// chunk's whole section). ContextTokenBudget caps the estimated size of
+	// the context in tokens; 0 = unlimited.
+	ContextExpansion   string `md:"contextExpansion"`
+	NeighborChunks     int    `md:"neighborChunks"`
+	ContextTokenBudget int    `md:"contextTokenBudget"`
+
+	// EnableSemanticCache looks up the answer to a similar earlier question
+	// in CacheCollection before generating, and caches generated answers for
+	// CacheTTLSeconds (0 = no expiry). Only used when EnableLLMGenerate=true.
+	EnableSemanticCache      bool    `md:"enableSemanticCache"`
+	CacheCollection          string  `md:"cacheCollection"`
+	CacheSimilarityThreshold float64 `md:"cacheSimilarityThreshold"`
+	CacheTTLSeconds          int     `md:"cacheTTLSeconds"`
 }
 
 // String returns a human-readable representation of Settings with sensitive
@@ -74,15 +123,25 @@ type Input struct {
 	TopK           int                    `md:"topK"`
 	Filters        map[string]interface{} `md:"filters"`
 	SystemPrompt   string                 `md:"systemPrompt"`
+	// StreamConnectionID and StreamTopic address the SSE clients that receive
+	// the token stream; with neither, tokens are broadcast to all clients.
+	StreamConnectionID string `md:"streamConnectionId"`
+	StreamTopic        string `md:"streamTopic"`
+	// ChatHistory holds the previous conversation turns, oldest first, as
+	// {"role","content"} objects. Used by query rewriting.
+	ChatHistory []interface{} `md:"chatHistory"`
 }
 
 func (i *Input) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"queryText":      i.QueryText,
-		"collectionName": i.CollectionName,
-		"topK":           i.TopK,
-		"filters":        i.Filters,
-		"systemPrompt":   i.SystemPrompt,
+		"queryText":          i.QueryText,
+		"collectionName":     i.CollectionName,
+		"topK":               i.TopK,
+		"filters":            i.Filters,
+		"systemPrompt":       i.SystemPrompt,
+		"streamConnectionId": i.StreamConnectionID,
+		"streamTopic":        i.StreamTopic,
+		"chatHistory":        i.ChatHistory,
 	}
 }
 
@@ -109,6 +168,17 @@ func (i *Input) FromMap(v map[string]interface{}) error {
 	if val, ok := v["systemPrompt"]; ok && val != nil {
 		i.SystemPrompt = fmt.Sprintf("%v", val)
 	}
+	if val, ok := v["streamConnectionId"]; ok && val != nil {
+		i.StreamConnectionID = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["streamTopic"]; ok && val != nil {
+		i.StreamTopic = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["chatHistory"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.ChatHistory = arr
+		}
+	}
 	return nil
 }
 
@@ -122,18 +192,37 @@ type Output struct {
 	TotalFound       int           `md:"totalFound"`
 	Duration         string        `md:"duration"`
 	Error            string        `md:"error"`
+	// Citations maps answer sentences to sourceDocuments (enableCitations).
+	Citations []interface{} `md:"citations"`
+	// StreamedTokens is the number of token events sent (enableStreaming).
+	StreamedTokens int `md:"streamedTokens"`
+	// RewrittenQuery is the standalone question used for retrieval and
+	// generation when enableQueryRewrite rewrote the query.
+	RewrittenQuery string `md:"rewrittenQuery"`
+	// QueryVariants lists every query searched (multiQuery mode).
+	QueryVariants []interface{} `md:"queryVariants"`
+	// HypotheticalDocument is the passage embedded in hyde mode.
+	HypotheticalDocument string `md:"hypotheticalDocument"`
+	// CacheHit is true when the answer came from the semantic cache.
+	CacheHit bool `md:"cacheHit"`
 }
 
 func (o *Output) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"success":          o.Success,
-		"answer":           o.Answer,
-		"formattedContext": o.FormattedContext,
-		"sourceDocuments":  o.SourceDocuments,
-		"queryEmbedding":   o.QueryEmbedding,
-		"totalFound":       o.TotalFound,
-		"duration":         o.Duration,
-		"error":            o.Error,
+		"success":              o.Success,
+		"answer":               o.Answer,
+		"formattedContext":     o.FormattedContext,
+		"sourceDocuments":      o.SourceDocuments,
+		"queryEmbedding":       o.QueryEmbedding,
+		"totalFound":           o.TotalFound,
+		"duration":             o.Duration,
+		"error":                o.Error,
+		"citations":            o.Citations,
+		"streamedTokens":       o.StreamedTokens,
+		"rewrittenQuery":       o.RewrittenQuery,
+		"queryVariants":        o.QueryVariants,
+		"hypotheticalDocument": o.HypotheticalDocument,
+		"cacheHit":             o.CacheHit,
 	}
 }
 
@@ -0,0 +1,163 @@
+package semanticCacheStore
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/support/connection"
+)
+
+// Settings holds design-time activity configuration.
+type Settings struct {
+	Connection connection.Manager `md:"connection,required"`
+	// UseConnectorEmbedding inherits the embedding provider, API key, and base
+	// URL from the VectorDB connector. Requires 'Configure Embedding Provider'
+	// on the connection.
+	UseConnectorEmbedding bool   `md:"useConnectorEmbedding"`
+	EmbeddingProvider     string `md:"embeddingProvider"`
+	EmbeddingAPIKey       string `md:"embeddingAPIKey"`
+	EmbeddingBaseURL      string `md:"embeddingBaseURL"`
+	EmbeddingModel        string `md:"embeddingModel,required"`
+	EmbeddingDimensions   int    `md:"embeddingDimensions"`
+	// CacheCollection is the collection holding the cached answers. It is
+	// created on the first store. Default: semantic_cache.
+	CacheCollection string `md:"cacheCollection"`
+	// DefaultTTLSeconds is the lifetime of an entry when the input does not
+	// set ttlSeconds; 0 = entries do not expire.
+	DefaultTTLSeconds int `md:"defaultTTLSeconds"`
+	TimeoutSeconds    int `md:"timeoutSeconds"`
+}
+
+// String returns a human-readable representation of Settings with the
+// embedding API key replaced by "[redacted]".
+func (s Settings) String() string {
+	apiKey := ""
+	if s.EmbeddingAPIKey != "" {
+		apiKey = "[redacted]"
+	}
+	return fmt.Sprintf(
+		"semanticCacheStore.Settings{provider:%q model:%q dims:%d collection:%q ttl:%d apiKey:%s}",
+		s.EmbeddingProvider, s.EmbeddingModel, s.EmbeddingDimensions, s.CacheCollection, s.DefaultTTLSeconds, apiKey,
+	)
+}
+
+// Input holds runtime data for the activity.
+type Input struct {
+	QueryText string `md:"queryText"`
+	Answer    string `md:"answer"`
+	// QueryVector, when set, is used instead of embedding QueryText, e.g.
+	// the queryEmbedding output of semanticCacheLookup.
+	QueryVector []float64 `md:"queryVector"`
+	Namespace   string    `md:"namespace"`
+	// SourceIDs are the IDs of the documents the answer was generated from.
+	SourceIDs []interface{}          `md:"sourceIds"`
+	Metadata  map[string]interface{} `md:"metadata"`
+	// TTLSeconds overrides DefaultTTLSeconds; 0 = use the default, < 0 =
+	// never expire.
+	TTLSeconds int `md:"ttlSeconds"`
+	// InvalidateSourceIDs deletes every entry generated from these source
+	// documents before the new answer is stored.
+	InvalidateSourceIDs []interface{} `md:"invalidateSourceIds"`
+	// PurgeExpired deletes the entries whose TTL has passed.
+	PurgeExpired bool `md:"purgeExpired"`
+}
+
+func (i *Input) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"queryText":           i.QueryText,
+		"answer":              i.Answer,
+		"queryVector":         i.QueryVector,
+		"namespace":           i.Namespace,
+		"sourceIds":           i.SourceIDs,
+		"metadata":            i.Metadata,
+		"ttlSeconds":          i.TTLSeconds,
+		"invalidateSourceIds": i.InvalidateSourceIDs,
+		"purgeExpired":        i.PurgeExpired,
+	}
+}
+
+func (i *Input) FromMap(v map[string]interface{}) error {
+	if val, ok := v["queryText"]; ok && val != nil {
+		i.QueryText = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["answer"]; ok && val != nil {
+		i.Answer = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["queryVector"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.QueryVector = make([]float64, len(arr))
+			for j, f := range arr {
+				if fv, ok := f.(float64); ok {
+					i.QueryVector[j] = fv
+				}
+			}
+		}
+	}
+	if val, ok := v["namespace"]; ok && val != nil {
+		i.Namespace = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["sourceIds"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.SourceIDs = arr
+		}
+	}
+	if val, ok := v["metadata"]; ok {
+		if m, ok := val.(map[string]interface{}); ok {
+			i.Metadata = m
+		}
+	}
+	if val, ok := v["ttlSeconds"]; ok {
+		switch n := val.(type) {
+		case int:
+			i.TTLSeconds = n
+		case float64:
+			i.TTLSeconds = int(n)
+		}
+	}
+	if val, ok := v["invalidateSourceIds"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.InvalidateSourceIDs = arr
+		}
+	}
+	if val, ok := v["purgeExpired"]; ok {
+		i.PurgeExpired, _ = val.(bool)
+	}
+	return nil
+}
+
+// Output holds the activity result.
+type Output struct {
+	Success bool `md:"success"`
+	// CacheID is the ID of the stored entry; empty when only invalidation
+	// or purging was requested.
+	CacheID string `md:"cacheId"`
+	// ExpiresAt is the RFC 3339 expiry of the stored entry; empty without TTL.
+	ExpiresAt string `md:"expiresAt"`
+	// InvalidatedCount and PurgedCount are the entries deleted (-1 when the
+	// provider does not report a count).
+	InvalidatedCount int64  `md:"invalidatedCount"`
+	PurgedCount      int64  `md:"purgedCount"`
+	Duration         string `md:"duration"`
+	Error            string `md:"error"`
+}
+
+func (o *Output) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"success":          o.Success,
+		"cacheId":          o.CacheID,
+		"expiresAt":        o.ExpiresAt,
+		"invalidatedCount": o.InvalidatedCount,
+		"purgedCount":      o.PurgedCount,
+		"duration":         o.Duration,
+		"error":            o.Error,
+	}
+}
+
+func (o *Output) FromMap(v map[string]interface{}) error {
+	if val, ok := v["success"]; ok {
+		o.Success, _ = val.(bool)
+	}
+	if val, ok := v["cacheId"]; ok && val != nil {
+		o.CacheID = fmt.Sprintf("%v", val)
+	}
+	return nil
+}
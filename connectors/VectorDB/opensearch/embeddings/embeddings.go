@@ -42,6 +42,10 @@ const (
 	ProviderCohere      EmbeddingProvider = "Cohere"
 	ProviderOllama      EmbeddingProvider = "Ollama"
 	ProviderCustom      EmbeddingProvider = "Custom"
+	// ProviderLocal runs an ONNX sentence-transformer on CPU. Model is the
+	// model directory: model.onnx (or onnx/model.onnx), tokenizer.json and
+	// config.json, as exported by Hugging Face Optimum.
+	ProviderLocal EmbeddingProvider = "Local"
 )
 
 // EmbeddingRequest holds all parameters for generating vector embeddings.
@@ -59,6 +63,12 @@ type EmbeddingRequest struct {
 	// AzureAPIVersion overrides the Azure OpenAI api-version query parameter
 	// (default: "2024-02-01"). Only used when Provider == ProviderAzureOpenAI.
 	AzureAPIVersion string
+
+	// LocalBatchSize is the number of texts per model run and LocalWorkers
+	// the number of concurrent model sessions (0 = defaults: 32 texts, half
+	// the CPUs up to 4). Only used when Provider == ProviderLocal.
+	LocalBatchSize int
+	LocalWorkers   int
 }
 
 // EmbeddingResponse holds the result of an embedding API call.
@@ -66,6 +76,9 @@ type EmbeddingResponse struct {
 	Embeddings [][]float64
 	Dimensions int
 	TokensUsed int
+	// CacheHits is the number of texts served from an EmbeddingCache
+	// (CreateEmbeddingsCached only).
+	CacheHits int
 }
 
 // CreateEmbeddings dispatches to the correct provider implementation and
@@ -79,6 +92,8 @@ func CreateEmbeddings(ctx context.Context, req EmbeddingRequest) (*EmbeddingResp
 		return callCohereEmbedAPI(ctx, req)
 	case ProviderOllama:
 		return callOllamaEmbedAPI(ctx, req)
+	case ProviderLocal:
+		return callLocalEmbed(ctx, req)
 	default: // OpenAI, Azure OpenAI, Custom — all use OpenAI-compatible format
 		return callOpenAIEmbedAPI(ctx, req)
 	}
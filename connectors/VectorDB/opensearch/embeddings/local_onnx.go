@@ -0,0 +1,132 @@
+//go:build onnx
+
+package vdbembed
+
+import (
+	"fmt"
+	"sync"
+
+	ort "github.com/amikos-tech/pure-onnx/ort"
+)
+
+// The ONNX Runtime environment is process-wide and initialised once, by
+// the first model load. The shared library is the libraryPath passed to
+// InitONNXRuntime when set; otherwise pure-onnx locates it:
+// ONNXRUNTIME_LIB_PATH selects a local build and
+// ONNXRUNTIME_DISABLE_DOWNLOAD=true forbids fetching one, which air-gapped
+// installs should set. Initialisation errors are kept so every model load
+// reports the cause.
+var (
+	ortInitMu   sync.Mutex
+	ortInitDone bool
+	ortInitPath string
+	ortInitErr  error
+)
+
+// InitONNXRuntime loads the ONNX Runtime shared library for the process.
+// Every in-process model (Local embeddings, local rerank) shares it, so a
+// later call naming a different library fails instead of being ignored.
+func InitONNXRuntime(libraryPath string) error {
+	ortInitMu.Lock()
+	defer ortInitMu.Unlock()
+	if ortInitDone {
+		if ortInitErr == nil && libraryPath != "" && libraryPath != ortInitPath {
+			from := ortInitPath
+			if from == "" {
+				from = "ONNXRUNTIME_LIB_PATH or the download cache"
+			}
+			return fmt.Errorf("ONNX runtime is already loaded from %s; one runtime library is used per process", from)
+		}
+		return ortInitErr
+	}
+	var opts []ort.BootstrapOption
+	if libraryPath != "" {
+		// Air-gapped installs point at a local onnxruntime library and must
+		// never attempt a download.
+		opts = append(opts, ort.WithBootstrapLibraryPath(libraryPath), ort.WithBootstrapDisableDownload(true))
+	}
+	ortInitDone, ortInitPath = true, libraryPath
+	ortInitErr = ort.InitializeEnvironmentWithBootstrap(opts...)
+	return ortInitErr
+}
+
+// onnxRunner runs a sentence-transformer ONNX model on CPU. Input and
+// output tensors are allocated once at the batch shape and refilled for
+// every run.
+type onnxRunner struct {
+	session *ort.AdvancedSession
+	ids     *ort.Tensor[int64]
+	mask    *ort.Tensor[int64]
+	types   *ort.Tensor[int64] // nil when the model takes no token_type_ids
+	hidden  *ort.Tensor[float32]
+}
+
+// newONNXRunner loads the model at modelPath. Sentence-transformer exports
+// take input_ids and attention_mask (plus token_type_ids for BERT-style
+// models) and return last_hidden_state.
+func newONNXRunner(modelPath string, batchSize, seqLen, hidden int, useTypeIDs bool) (localRunner, error) {
+	if err := InitONNXRuntime(""); err != nil {
+		return nil, fmt.Errorf("initialise ONNX runtime: %w", err)
+	}
+	r := &onnxRunner{}
+	shape := ort.Shape{int64(batchSize), int64(seqLen)}
+	n := batchSize * seqLen
+	var err error
+	if r.ids, err = ort.NewTensor(shape, make([]int64, n)); err != nil {
+		return nil, err
+	}
+	if r.mask, err = ort.NewTensor(shape, make([]int64, n)); err != nil {
+		_ = r.close()
+		return nil, err
+	}
+	inputNames := []string{"input_ids", "attention_mask"}
+	inputs := []ort.Value{r.ids, r.mask}
+	if useTypeIDs {
+		if r.types, err = ort.NewTensor(shape, make([]int64, n)); err != nil {
+			_ = r.close()
+			return nil, err
+		}
+		inputNames = append(inputNames, "token_type_ids")
+		inputs = append(inputs, r.types)
+	}
+	if r.hidden, err = ort.NewEmptyTensor[float32](ort.Shape{int64(batchSize), int64(seqLen), int64(hidden)}); err != nil {
+		_ = r.close()
+		return nil, err
+	}
+	r.session, err = ort.NewAdvancedSession(modelPath, inputNames, []string{"last_hidden_state"},
+		inputs, []ort.Value{r.hidden}, nil)
+	if err != nil {
+		_ = r.close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *onnxRunner) run(ids, mask, types []int64) ([]float32, error) {
+	copy(r.ids.GetData(), ids)
+	copy(r.mask.GetData(), mask)
+	if r.types != nil {
+		copy(r.types.GetData(), types)
+	}
+	if err := r.session.Run(); err != nil {
+		return nil, err
+	}
+	// The output buffer is reused by the next run, and the caller pools the
+	// result after returning the session.
+	return append([]float32(nil), r.hidden.GetData()...), nil
+}
+
+func (r *onnxRunner) close() error {
+	if r.session != nil {
+		_ = r.session.Destroy()
+	}
+	for _, t := range []*ort.Tensor[int64]{r.ids, r.mask, r.types} {
+		if t != nil {
+			_ = t.Destroy()
+		}
+	}
+	if r.hidden != nil {
+		_ = r.hidden.Destroy()
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ type openSearchClient struct {
 
 // Compile-time proof that openSearchClient satisfies the full VectorDBClient interface.
 var _ VectorDBClient = (*openSearchClient)(nil)
+var _ MetricReader = (*openSearchClient)(nil)
 
 func newOpenSearchClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	scheme := "http"
@@ -180,6 +181,52 @@ func (c *openSearchClient) CollectionExists(ctx context.Context, name string) (b
 	return exists, nil
 }
 
+// CollectionMetric returns the space_type of the index's knn_vector field,
+// declared on the field itself or in its method.
+func (c *openSearchClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	endpoint := fmt.Sprintf("%s/%s/_mapping", c.baseURL, name)
+	var body []byte
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var reqErr error
+		body, reqErr = c.doRequestHandleErrors(ctx, http.MethodGet, endpoint, nil)
+		return reqErr
+	}); err != nil {
+		return "", err
+	}
+	var mappings map[string]struct {
+		Mappings struct {
+			Properties struct {
+				Embedding struct {
+					SpaceType string `json:"space_type"`
+					Method    struct {
+						SpaceType string `json:"space_type"`
+					} `json:"method"`
+				} `json:"embedding"`
+			} `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := json.Unmarshal(body, &mappings); err != nil {
+		return "", newError(ErrCodeProviderError, "CollectionMetric: decode mapping", err)
+	}
+	for _, m := range mappings {
+		space := m.Mappings.Properties.Embedding.SpaceType
+		if space == "" {
+			space = m.Mappings.Properties.Embedding.Method.SpaceType
+		}
+		switch space {
+		case "cosinesimil":
+			return "cosine", nil
+		case "innerproduct":
+			return "dot", nil
+		case "l2":
+			return "euclidean", nil
+		default:
+			return space, nil
+		}
+	}
+	return "", nil
+}
+
 // CreateCollection creates a new OpenSearch index with knn_vector mapping.
 func (c *openSearchClient) CreateCollection(ctx context.Context, cfg CollectionConfig) error {
 	if err := validateCollectionConfig(cfg); err != nil {
@@ -501,7 +548,10 @@ func (c *openSearchClient) DeleteByFilter(ctx context.Context, collectionName st
 	if len(filters) == 0 {
 		return 0, newError(ErrCodeProviderError, "DeleteByFilter requires at least one filter to prevent accidental full-collection deletion", nil)
 	}
-	query := buildOpenSearchFilter(filters)
+	query, err := openSearchFilter(filters)
+	if err != nil {
+		return 0, err
+	}
 	reqBody := map[string]interface{}{
 		"query": query,
 	}
@@ -532,7 +582,10 @@ func (c *openSearchClient) DeleteByFilter(ctx context.Context, collectionName st
 
 // CountDocuments returns the total count of documents matching the optional filter.
 func (c *openSearchClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
-	query := buildOpenSearchFilter(filters)
+	query, err := openSearchFilter(filters)
+	if err != nil {
+		return 0, err
+	}
 	reqBody := map[string]interface{}{
 		"query": query,
 	}
@@ -573,7 +626,10 @@ func (c *openSearchClient) ScrollDocuments(ctx context.Context, req ScrollReques
 		fmt.Sscanf(req.Offset, "%d", &from)
 	}
 
-	query := buildOpenSearchFilter(req.Filters)
+	query, err := openSearchFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	reqBody := map[string]interface{}{
 		"from":  from,
 		"size":  limit,
@@ -646,9 +702,12 @@ func (c *openSearchClient) VectorSearch(ctx context.Context, req SearchRequest)
 		},
 	}
 
+	filterQuery, err := openSearchFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	var query map[string]interface{}
 	if len(req.Filters) > 0 {
-		filterQuery := buildOpenSearchFilter(req.Filters)
 		// Wrap knn + filter in bool.must
 		query = map[string]interface{}{
 			"bool": map[string]interface{}{
@@ -695,6 +754,11 @@ func (c *openSearchClient) HybridSearch(ctx context.Context, req HybridSearchReq
 		return nil, newError(ErrCodeInvalidTopK, fmt.Sprintf("topK=%d must be > 0", req.TopK), nil)
 	}
 
+	filterQuery, err := openSearchFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
 	// Alpha: 0.0 = pure BM25, 1.0 = pure dense vector, 0.5 = balanced
 	alpha := req.Alpha
 	if alpha < 0 {
@@ -737,7 +801,7 @@ func (c *openSearchClient) HybridSearch(ctx context.Context, req HybridSearchReq
 		"minimum_should_match": 1,
 	}
 	if len(req.Filters) > 0 {
-		boolQuery["filter"] = []interface{}{buildOpenSearchFilter(req.Filters)}
+		boolQuery["filter"] = []interface{}{filterQuery}
 	}
 	query := map[string]interface{}{
 		"bool": boolQuery,
@@ -769,90 +833,78 @@ func (c *openSearchClient) HybridSearch(ctx context.Context, req HybridSearchReq
 
 // ── Filter builder ───────────────────────────────────────────────────────────
 
-// buildOpenSearchFilter converts a generic filter map to OpenSearch query syntax.
-// nil or empty filters → {"match_all": {}}
-// Single entry → term/range/terms query
-// Multiple entries → {"bool": {"must": [...]}}
-func buildOpenSearchFilter(filters map[string]interface{}) map[string]interface{} {
-	if len(filters) == 0 {
-		return map[string]interface{}{"match_all": map[string]interface{}{}}
+// openSearchFilter validates filters and converts them to OpenSearch query
+// syntax. Every filter operator has a native equivalent, so nothing is left
+// to match client-side.
+func openSearchFilter(filters map[string]interface{}) (map[string]interface{}, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return nil, err
 	}
+	return buildOpenSearchFilter(expr), nil
+}
 
-	clauses := make([]interface{}, 0, len(filters))
-	for k, v := range filters {
-		fieldPath := "metadata." + k
-		switch val := v.(type) {
-		case map[string]interface{}:
-			// Operator map
-			clause := buildOperatorClause(fieldPath, val)
-			clauses = append(clauses, clause)
-		default:
-			// Direct value → term query
-			clauses = append(clauses, map[string]interface{}{
-				"term": map[string]interface{}{fieldPath: val},
-			})
-		}
+// buildOpenSearchFilter converts a parsed filter to OpenSearch query syntax.
+// nil filter → {"match_all": {}}
+// Field condition → term/range/terms/exists query
+// $and / $or / $not → {"bool": {"must" | "should" | "must_not": [...]}}
+func buildOpenSearchFilter(expr *FilterExpr) map[string]interface{} {
+	if expr == nil {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
 	}
 
-	if len(clauses) == 1 {
-		return clauses[0].(map[string]interface{})
-	}
-	return map[string]interface{}{
-		"bool": map[string]interface{}{
-			"must": clauses,
-		},
+	field := "metadata." + expr.Field
+	switch expr.Op {
+	case FilterAnd:
+		return openSearchBool("must", openSearchClauses(expr.Children))
+	case FilterOr:
+		q := openSearchBool("should", openSearchClauses(expr.Children))
+		q["bool"].(map[string]interface{})["minimum_should_match"] = 1
+		return q
+	case FilterNot:
+		return openSearchBool("must_not", openSearchClauses(expr.Children))
+	case FilterEq, FilterContains:
+		// A term query on an array field matches any element.
+		return map[string]interface{}{
+			"term": map[string]interface{}{field: expr.Value},
+		}
+	case FilterNe:
+		return openSearchBool("must_not", []interface{}{
+			map[string]interface{}{"term": map[string]interface{}{field: expr.Value}},
+		})
+	case FilterIn:
+		return map[string]interface{}{
+			"terms": map[string]interface{}{field: expr.Values()},
+		}
+	case FilterNin:
+		return openSearchBool("must_not", []interface{}{
+			map[string]interface{}{"terms": map[string]interface{}{field: expr.Values()}},
+		})
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		return map[string]interface{}{
+			"range": map[string]interface{}{field: map[string]interface{}{expr.Op[1:]: expr.Value}},
+		}
+	case FilterExists:
+		exists := map[string]interface{}{"exists": map[string]interface{}{"field": field}}
+		if expr.Value == true {
+			return exists
+		}
+		return openSearchBool("must_not", []interface{}{exists})
 	}
+	return map[string]interface{}{"match_all": map[string]interface{}{}}
 }
 
-// buildOperatorClause converts a single operator map to an OpenSearch query clause.
-func buildOperatorClause(field string, ops map[string]interface{}) map[string]interface{} {
-	rangeOps := map[string]interface{}{}
-	hasRange := false
-
-	for op, val := range ops {
-		switch op {
-		case "$eq":
-			return map[string]interface{}{
-				"term": map[string]interface{}{field: val},
-			}
-		case "$ne":
-			return map[string]interface{}{
-				"bool": map[string]interface{}{
-					"must_not": []interface{}{
-						map[string]interface{}{
-							"term": map[string]interface{}{field: val},
-						},
-					},
-				},
-			}
-		case "$in":
-			return map[string]interface{}{
-				"terms": map[string]interface{}{field: val},
-			}
-		case "$gt":
-			rangeOps["gt"] = val
-			hasRange = true
-		case "$gte":
-			rangeOps["gte"] = val
-			hasRange = true
-		case "$lt":
-			rangeOps["lt"] = val
-			hasRange = true
-		case "$lte":
-			rangeOps["lte"] = val
-			hasRange = true
-		}
-	}
-
-	if hasRange {
-		return map[string]interface{}{
-			"range": map[string]interface{}{field: rangeOps},
-		}
+func openSearchClauses(children []*FilterExpr) []interface{} {
+	clauses := make([]interface{}, len(children))
+	for i, c := range children {
+		clauses[i] = buildOpenSearchFilter(c)
 	}
+	return clauses
+}
 
-	// Fallback: use term with the raw map
+func openSearchBool(occur string, clauses []interface{}) map[string]interface{} {
 	return map[string]interface{}{
-		"term": map[string]interface{}{field: ops},
+		"bool": map[string]interface{}{occur: clauses},
 	}
 }
 
@@ -484,15 +484,22 @@ func TestHealthCheck_Unhealthy(t *testing.T) {
 // Filter builder
 // ---------------------------------------------------------------------------
 
+func mustOpenSearchFilter(t *testing.T, filters map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	q, err := openSearchFilter(filters)
+	require.NoError(t, err)
+	return q
+}
+
 func TestBuildOpenSearchFilter_Nil(t *testing.T) {
-	result := buildOpenSearchFilter(nil)
+	result := mustOpenSearchFilter(t, nil)
 	matchAll, ok := result["match_all"]
 	assert.True(t, ok)
 	assert.NotNil(t, matchAll)
 }
 
 func TestBuildOpenSearchFilter_DirectValue(t *testing.T) {
-	result := buildOpenSearchFilter(map[string]interface{}{
+	result := mustOpenSearchFilter(t, map[string]interface{}{
 		"category": "tech",
 	})
 	term, ok := result["term"]
@@ -502,7 +509,7 @@ func TestBuildOpenSearchFilter_DirectValue(t *testing.T) {
 }
 
 func TestBuildOpenSearchFilter_OperatorGte(t *testing.T) {
-	result := buildOpenSearchFilter(map[string]interface{}{
+	result := mustOpenSearchFilter(t, map[string]interface{}{
 		"score": map[string]interface{}{"$gte": 10},
 	})
 	rangeQ, ok := result["range"]
@@ -513,7 +520,7 @@ func TestBuildOpenSearchFilter_OperatorGte(t *testing.T) {
 }
 
 func TestBuildOpenSearchFilter_OperatorIn(t *testing.T) {
-	result := buildOpenSearchFilter(map[string]interface{}{
+	result := mustOpenSearchFilter(t, map[string]interface{}{
 		"tags": map[string]interface{}{"$in": []string{"rag", "llm"}},
 	})
 	terms, ok := result["terms"]
@@ -523,7 +530,7 @@ func TestBuildOpenSearchFilter_OperatorIn(t *testing.T) {
 }
 
 func TestBuildOpenSearchFilter_OperatorNe(t *testing.T) {
-	result := buildOpenSearchFilter(map[string]interface{}{
+	result := mustOpenSearchFilter(t, map[string]interface{}{
 		"status": map[string]interface{}{"$ne": "archived"},
 	})
 	boolQ, ok := result["bool"]
@@ -533,7 +540,7 @@ func TestBuildOpenSearchFilter_OperatorNe(t *testing.T) {
 }
 
 func TestBuildOpenSearchFilter_MultipleFilters(t *testing.T) {
-	result := buildOpenSearchFilter(map[string]interface{}{
+	result := mustOpenSearchFilter(t, map[string]interface{}{
 		"category": "tech",
 		"year":     2024,
 	})
@@ -545,6 +552,39 @@ func TestBuildOpenSearchFilter_MultipleFilters(t *testing.T) {
 	assert.Len(t, must, 2)
 }
 
+func TestBuildOpenSearchFilter_Logical(t *testing.T) {
+	result := mustOpenSearchFilter(t, map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"meta.team": "search"},
+			map[string]interface{}{"$not": map[string]interface{}{"status": "draft"}},
+		},
+	})
+	boolMap := result["bool"].(map[string]interface{})
+	should, ok := boolMap["should"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, should, 2)
+	assert.Equal(t, 1, boolMap["minimum_should_match"])
+	term := should[0].(map[string]interface{})["term"].(map[string]interface{})
+	assert.Equal(t, "search", term["metadata.meta.team"])
+	notBool := should[1].(map[string]interface{})["bool"].(map[string]interface{})
+	assert.NotNil(t, notBool["must_not"])
+}
+
+func TestBuildOpenSearchFilter_Exists(t *testing.T) {
+	result := mustOpenSearchFilter(t, map[string]interface{}{
+		"author": map[string]interface{}{"$exists": true},
+	})
+	exists := result["exists"].(map[string]interface{})
+	assert.Equal(t, "metadata.author", exists["field"])
+}
+
+func TestBuildOpenSearchFilter_Invalid(t *testing.T) {
+	_, err := openSearchFilter(map[string]interface{}{
+		"a": map[string]interface{}{"$regex": "x"},
+	})
+	require.Error(t, err)
+}
+
 // ---------------------------------------------------------------------------
 // ScrollDocuments
 // ---------------------------------------------------------------------------
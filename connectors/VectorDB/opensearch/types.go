@@ -65,7 +65,9 @@ type SearchRequest struct {
 	ScoreThreshold float64
 
 	// Filters is a provider-agnostic metadata filter expressed as a map.
-	// Supported operators per key: {"$eq", "$ne", "$gt", "$gte", "$lt", "$lte", "$in"}.
+	// Keys are payload paths (nested keys dot-separated) or $and / $or / $not;
+	// field operators are $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $exists
+	// and $contains. See ParseFilter.
 	Filters map[string]interface{}
 
 	// WithVectors includes the stored vector in each SearchResult.
@@ -224,3 +226,13 @@ type VectorDBClient interface {
 	// Close releases all resources held by this client.
 	Close() error
 }
+
+// MetricReader is implemented by clients that can read the distance metric
+// an existing collection was created with. It is optional: callers
+// type-assert for it and treat a client without it as unable to tell.
+type MetricReader interface {
+	// CollectionMetric returns "cosine", "dot" or "euclidean" (or the
+	// provider's own name for any other metric), or "" when the collection
+	// does not record one.
+	CollectionMetric(ctx context.Context, name string) (string, error)
+}
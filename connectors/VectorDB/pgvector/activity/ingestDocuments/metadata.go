@@ -60,6 +60,28 @@ type Settings struct {
 	// ChunkOverlap is the number of characters shared between consecutive
 	// chunks. Only meaningful for "fixed" strategy. Default: 200.
 	ChunkOverlap int `md:"chunkOverlap"`
+
+	// ── Embedding cache ──────────────────────────────────────────────────────
+	// EmbeddingCache keeps vectors keyed by a content hash of model, dimensions
+	// and text so unchanged texts are not re-embedded.
+	// Allowed values: "none", "memory" (in-process LRU), "disk". Default: "none".
+	EmbeddingCache string `md:"embeddingCache"`
+
+	// EmbeddingCacheSize is the capacity of the "memory" cache in vectors.
+	// Default: 10000.
+	EmbeddingCacheSize int `md:"embeddingCacheSize"`
+
+	// EmbeddingCacheDir is the directory of the "disk" cache. Required for "disk";
+	// it may be shared by several engines.
+	EmbeddingCacheDir string `md:"embeddingCacheDir"`
+
+	// ── Ingest mode ──────────────────────────────────────────────────────────
+	// IngestMode is "full" (default) or "incremental". In incremental mode chunk
+	// IDs are derived from the document source and the chunk's text hash,
+	// chunks already stored unchanged are skipped, and stored chunks of a
+	// re-ingested source that are no longer produced are deleted. Each run must
+	// therefore carry the complete content of every source it contains.
+	IngestMode string `md:"ingestMode"`
 }
 
 // Input holds the runtime inputs for an ingest operation.
@@ -114,6 +136,11 @@ type RawDocument struct {
 	ID       string
 	Text     string
 	Metadata map[string]interface{}
+	// Sections is the structure of a document extracted from a file (pages,
+	// slides, sheets…). Text is the sections joined; expandChunks chunks each
+	// section separately and copies its location metadata onto the chunks.
+	// Nil for documents supplied as text.
+	Sections []DocumentSection
 }
 
 // parseDocuments converts []interface{} input into typed RawDocument slice.
@@ -178,6 +205,13 @@ type Output struct {
 	// ChunksCreated is the total number of chunks stored in VectorDB.
 	// Equal to IngestedCount when chunking is disabled.
 	ChunksCreated int `md:"chunksCreated"`
+	// SkippedCount is the number of chunks already stored unchanged and not
+	// re-embedded (incremental mode only).
+	SkippedCount int `md:"skippedCount"`
+	// DeletedCount is the number of stale chunks deleted (incremental mode only).
+	DeletedCount int `md:"deletedCount"`
+	// CacheHits is the number of embeddings served from the embedding cache.
+	CacheHits int `md:"cacheHits"`
 }
 
 func (o *Output) ToMap() map[string]interface{} {
@@ -190,6 +224,9 @@ func (o *Output) ToMap() map[string]interface{} {
 		"error":               o.Error,
 		"sourceDocumentCount": o.SourceDocumentCount,
 		"chunksCreated":       o.ChunksCreated,
+		"skippedCount":        o.SkippedCount,
+		"deletedCount":        o.DeletedCount,
+		"cacheHits":           o.CacheHits,
 	}
 }
 
@@ -218,5 +255,14 @@ func (o *Output) FromMap(v map[string]interface{}) error {
 	if val, ok := v["chunksCreated"].(int); ok {
 		o.ChunksCreated = val
 	}
+	if val, ok := v["skippedCount"].(int); ok {
+		o.SkippedCount = val
+	}
+	if val, ok := v["deletedCount"].(int); ok {
+		o.DeletedCount = val
+	}
+	if val, ok := v["cacheHits"].(int); ok {
+		o.CacheHits = val
+	}
 	return nil
 }
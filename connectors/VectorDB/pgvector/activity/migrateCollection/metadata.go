@@ -0,0 +1,140 @@
+package migrateCollection
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/support/connection"
+)
+
+const (
+	// directionPush copies from Connection to PeerConnection.
+	directionPush = "push"
+	// directionPull copies from PeerConnection to Connection.
+	directionPull = "pull"
+)
+
+type Settings struct {
+	Connection connection.Manager `md:"connection,required"`
+	// PeerConnection is the other side of the migration. It can be a
+	// connection of any VectorDB connector, including the deprecated
+	// monolith connector.
+	PeerConnection connection.Manager `md:"peerConnection,required"`
+	// Direction is "push" (Connection → PeerConnection, default) or "pull"
+	// (PeerConnection → Connection).
+	Direction string `md:"direction"`
+	// BatchSize is the number of documents per scroll page and upsert.
+	// Default: 256.
+	BatchSize        int    `md:"batchSize"`
+	CreateCollection bool   `md:"createCollection"`
+	DistanceMetric   string `md:"distanceMetric"`
+	Verify           bool   `md:"verify"`
+	// CheckpointPath, when set, is a file recording the cursor after every
+	// batch, so a re-run resumes an interrupted migration.
+	CheckpointPath string `md:"checkpointPath"`
+	TimeoutSeconds int    `md:"timeoutSeconds"`
+}
+
+type Input struct {
+	SourceCollection string `md:"sourceCollection"`
+	// TargetCollection defaults to SourceCollection.
+	TargetCollection string                 `md:"targetCollection"`
+	Filters          map[string]interface{} `md:"filters"`
+	// SourceDistanceMetric is the metric of the source collection, read
+	// from the collection when empty. It must match the target's metric.
+	SourceDistanceMetric string `md:"sourceDistanceMetric"`
+	// ResumeCursor continues a migration from the cursor output of an
+	// interrupted run. It takes precedence over the checkpoint file.
+	ResumeCursor string `md:"resumeCursor"`
+	// Restart ignores the checkpoint file and starts from the first
+	// document.
+	Restart bool `md:"restart"`
+}
+
+func (i *Input) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"sourceCollection":     i.SourceCollection,
+		"targetCollection":     i.TargetCollection,
+		"filters":              i.Filters,
+		"sourceDistanceMetric": i.SourceDistanceMetric,
+		"resumeCursor":         i.ResumeCursor,
+		"restart":              i.Restart,
+	}
+}
+
+func (i *Input) FromMap(v map[string]interface{}) error {
+	if val, ok := v["sourceCollection"]; ok && val != nil {
+		i.SourceCollection = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["targetCollection"]; ok && val != nil {
+		i.TargetCollection = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["filters"]; ok {
+		if m, ok := val.(map[string]interface{}); ok {
+			i.Filters = m
+		}
+	}
+	if val, ok := v["sourceDistanceMetric"]; ok && val != nil {
+		i.SourceDistanceMetric = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["resumeCursor"]; ok && val != nil {
+		i.ResumeCursor = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["restart"]; ok {
+		i.Restart, _ = val.(bool)
+	}
+	return nil
+}
+
+type Output struct {
+	Success bool `md:"success"`
+	// MigratedCount is the number of documents copied by this run;
+	// DocumentCount also includes those copied before a resume.
+	MigratedCount int64 `md:"migratedCount"`
+	DocumentCount int64 `md:"documentCount"`
+	Dimensions    int   `md:"dimensions"`
+	// Cursor is the source cursor of the first batch that was not copied
+	// when the migration was interrupted. Pass it as resumeCursor.
+	Cursor            string `md:"cursor"`
+	Complete          bool   `md:"complete"`
+	Created           bool   `md:"created"`
+	Verified          bool   `md:"verified"`
+	SourceCount       int64  `md:"sourceCount"`
+	TargetCount       int64  `md:"targetCount"`
+	VerificationError string `md:"verificationError"`
+	Duration          string `md:"duration"`
+	Error             string `md:"error"`
+}
+
+func (o *Output) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"success":           o.Success,
+		"migratedCount":     o.MigratedCount,
+		"documentCount":     o.DocumentCount,
+		"dimensions":        o.Dimensions,
+		"cursor":            o.Cursor,
+		"complete":          o.Complete,
+		"created":           o.Created,
+		"verified":          o.Verified,
+		"sourceCount":       o.SourceCount,
+		"targetCount":       o.TargetCount,
+		"verificationError": o.VerificationError,
+		"duration":          o.Duration,
+		"error":             o.Error,
+	}
+}
+
+func (o *Output) FromMap(v map[string]interface{}) error {
+	if val, ok := v["success"]; ok {
+		o.Success, _ = val.(bool)
+	}
+	if val, ok := v["cursor"]; ok {
+		o.Cursor, _ = val.(string)
+	}
+	if val, ok := v["complete"]; ok {
+		o.Complete, _ = val.(bool)
+	}
+	if val, ok := v["verified"]; ok {
+		o.Verified, _ = val.(bool)
+	}
+	return nil
+}
@@ -61,3 +61,13 @@ type VectorDBClient interface {
 	// Close releases all resources held by this client (closes the connection pool).
 	Close() error
 }
+
+// MetricReader is implemented by clients that can read the distance metric
+// an existing collection was created with. It is optional: callers
+// type-assert for it and treat a client without it as unable to tell.
+type MetricReader interface {
+	// CollectionMetric returns "cosine", "dot" or "euclidean" (or the
+	// provider's own name for any other metric), or "" when the collection
+	// does not record one.
+	CollectionMetric(ctx context.Context, name string) (string, error)
+}
@@ -0,0 +1,193 @@
+package vectordb
+
+import (
+	"context"
+	"math"
+)
+
+// Maximal marginal relevance (MMR) re-selects a diverse top-K from an
+// over-fetched candidate list: each pick maximises
+//
+//	lambda * relevance(candidate) - (1 - lambda) * max similarity(candidate, picked)
+//
+// so near-duplicates of an already selected result lose to slightly less
+// relevant but different ones. The search activities fetch MMRFetchK
+// candidates with their vectors and pass them to SelectMMR.
+
+const (
+	// DefaultMMRLambda balances relevance and diversity equally.
+	DefaultMMRLambda = 0.5
+
+	// mmrFetchFactor and mmrMinFetchK size the default candidate pool.
+	mmrFetchFactor = 4
+	mmrMinFetchK   = 20
+)
+
+// MMRFetchK returns the number of candidates to fetch for an MMR selection of
+// topK results. fetchK <= 0 selects the default of 4 × topK, at least 20;
+// a fetchK below topK is raised to topK.
+func MMRFetchK(topK, fetchK int) int {
+	if fetchK <= 0 {
+		fetchK = topK * mmrFetchFactor
+		if fetchK < mmrMinFetchK {
+			fetchK = mmrMinFetchK
+		}
+	}
+	if fetchK < topK {
+		fetchK = topK
+	}
+	return fetchK
+}
+
+// FetchMissingVectors fills in the vector of every result that was returned
+// without one, using GetDocument. It is needed for searches that cannot
+// return vectors themselves (hybrid search). Results whose document cannot be
+// read keep a nil vector; the first error is returned after all results have
+// been tried.
+func FetchMissingVectors(ctx context.Context, client VectorDBClient, collectionName string, results []SearchResult) error {
+	var firstErr error
+	for i := range results {
+		if len(results[i].Vector) > 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		doc, err := client.GetDocument(ctx, collectionName, results[i].ID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if doc != nil {
+			results[i].Vector = doc.Vector
+		}
+	}
+	return firstErr
+}
+
+// DiversifyMMR is the MMR step shared by the search activities: it fetches
+// the candidate vectors the search did not return, selects topK results with
+// SelectMMR and, unless keepVectors is set, drops the vectors again. An error
+// from FetchMissingVectors is returned with the selection, which is still
+// usable: candidates without a vector only lose their redundancy penalty.
+func DiversifyMMR(ctx context.Context, client VectorDBClient, collectionName string, queryVector []float64, candidates []SearchResult, topK int, lambda float64, keepVectors bool) ([]SearchResult, error) {
+	err := FetchMissingVectors(ctx, client, collectionName, candidates)
+	out := SelectMMR(queryVector, candidates, topK, lambda)
+	if !keepVectors {
+		for i := range out {
+			out[i].Vector = nil
+		}
+	}
+	return out, err
+}
+
+// SelectMMR returns up to topK candidates in MMR order. Relevance is the
+// cosine similarity to queryVector when it is set and every candidate has a
+// vector of the same size; otherwise it is the candidate's search score,
+// min-max normalised to [0, 1] so it is on the same scale as the diversity
+// term. Candidates without a vector are never penalised for redundancy. When
+// no candidate has a vector, the first topK candidates are returned as
+// ranked. Scores are left unchanged.
+func SelectMMR(queryVector []float64, candidates []SearchResult, topK int, lambda float64) []SearchResult {
+	if topK <= 0 || len(candidates) == 0 {
+		return nil
+	}
+	if !anyVector(candidates) {
+		if len(candidates) > topK {
+			candidates = candidates[:topK]
+		}
+		return candidates
+	}
+
+	relevance := mmrRelevance(queryVector, candidates)
+	// maxSim[i] is the highest similarity of candidate i to any pick so far;
+	// it stays 0 until a similarity can be computed.
+	maxSim := make([]float64, len(candidates))
+	hasSim := make([]bool, len(candidates))
+	picked := make([]bool, len(candidates))
+	out := make([]SearchResult, 0, topK)
+	for len(out) < topK && len(out) < len(candidates) {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range candidates {
+			if picked[i] {
+				continue
+			}
+			score := lambda*relevance[i] - (1-lambda)*maxSim[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked[best] = true
+		out = append(out, candidates[best])
+		for i := range candidates {
+			if picked[i] {
+				continue
+			}
+			if sim, ok := cosineSimilarity(candidates[i].Vector, candidates[best].Vector); ok && (!hasSim[i] || sim > maxSim[i]) {
+				maxSim[i], hasSim[i] = sim, true
+			}
+		}
+	}
+	return out
+}
+
+// mmrRelevance returns the relevance term of each candidate.
+func mmrRelevance(queryVector []float64, candidates []SearchResult) []float64 {
+	relevance := make([]float64, len(candidates))
+	useCosine := len(queryVector) > 0
+	for _, c := range candidates {
+		if len(c.Vector) != len(queryVector) {
+			useCosine = false
+			break
+		}
+	}
+	if useCosine {
+		for i, c := range candidates {
+			relevance[i], _ = cosineSimilarity(queryVector, c.Vector)
+		}
+		return relevance
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range candidates {
+		lo = math.Min(lo, c.Score)
+		hi = math.Max(hi, c.Score)
+	}
+	for i, c := range candidates {
+		if hi > lo {
+			relevance[i] = (c.Score - lo) / (hi - lo)
+		} else {
+			relevance[i] = 1
+		}
+	}
+	return relevance
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b. ok is
+// false when the vectors differ in size or either has zero length.
+func cosineSimilarity(a, b []float64) (sim float64, ok bool) {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0, false
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0, false
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb)), true
+}
+
+func anyVector(results []SearchResult) bool {
+	for _, r := range results {
+		if len(r.Vector) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,7 @@ type pgvectorClient struct {
 
 // Compile-time assertion: pgvectorClient implements VectorDBClient.
 var _ VectorDBClient = (*pgvectorClient)(nil)
+var _ MetricReader = (*pgvectorClient)(nil)
 
 // newPgvectorClient creates a new pgvector client with a connection pool.
 func newPgvectorClient(ctx context.Context, cfg ConnectionConfig) (VectorDBClient, error) {
@@ -215,6 +216,48 @@ func (c *pgvectorClient) CollectionExists(ctx context.Context, name string) (boo
 	return count > 0, nil
 }
 
+// CollectionMetric returns the metric of the operator class of the table's
+// ANN index on the embedding column, or "" for a table without one.
+func (c *pgvectorClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", newError(ErrCodeInvalidCollectionName, "", nil)
+	}
+	tableName := pgvectorSafeTableName(name)
+	conn, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return "", newError(ErrCodeConnectionFailed, "collection metric: acquire connection failed", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx,
+		"SELECT indexdef FROM pg_indexes WHERE tablename = $1 AND schemaname = 'public'", tableName)
+	if err != nil {
+		return "", newError(ErrCodeProviderError, "collection metric: query failed", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var def string
+		if err := rows.Scan(&def); err != nil {
+			return "", newError(ErrCodeProviderError, "collection metric: scan failed", err)
+		}
+		if !strings.Contains(def, "embedding") {
+			continue
+		}
+		switch {
+		case strings.Contains(def, "_cosine_ops"):
+			return "cosine", nil
+		case strings.Contains(def, "_ip_ops"):
+			return "dot", nil
+		case strings.Contains(def, "_l2_ops"):
+			return "euclidean", nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", newError(ErrCodeProviderError, "collection metric: rows error", err)
+	}
+	return "", nil
+}
+
 // UpsertDocuments inserts or updates documents in the collection using batch operations.
 // Uses ON CONFLICT (id) DO UPDATE to implement upsert semantics.
 func (c *pgvectorClient) UpsertDocuments(ctx context.Context, collectionName string, docs []Document) error {
@@ -344,7 +387,10 @@ func (c *pgvectorClient) DeleteByFilter(ctx context.Context, collectionName stri
 	}
 	defer conn.Release()
 
-	whereClause, args := buildPgvectorFilter(filters, 1)
+	whereClause, args, err := pgvectorFilter(filters, 1)
+	if err != nil {
+		return -1, err
+	}
 	var sql string
 	if whereClause == "" {
 		sql = fmt.Sprintf("DELETE FROM %s", tableName)
@@ -384,7 +430,10 @@ func (c *pgvectorClient) ScrollDocuments(ctx context.Context, req ScrollRequest)
 	}
 	defer conn.Release()
 
-	whereClause, filterArgs := buildPgvectorFilter(req.Filters, 1)
+	whereClause, filterArgs, err := pgvectorFilter(req.Filters, 1)
+	if err != nil {
+		return nil, err
+	}
 	var selectCols string
 	if req.WithVectors {
 		selectCols = "id, content, metadata, embedding"
@@ -482,7 +531,10 @@ func (c *pgvectorClient) CountDocuments(ctx context.Context, collectionName stri
 	}
 	defer conn.Release()
 
-	whereClause, args := buildPgvectorFilter(filters, 1)
+	whereClause, args, err := pgvectorFilter(filters, 1)
+	if err != nil {
+		return 0, err
+	}
 	var sql string
 	if whereClause == "" {
 		sql = fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
@@ -527,7 +579,10 @@ func (c *pgvectorClient) VectorSearch(ctx context.Context, req SearchRequest) ([
 	vec := pgvector.NewVector(toFloat32Slice(req.QueryVector))
 
 	// Build WHERE clause from filters.
-	whereClause, filterArgs := buildPgvectorFilter(req.Filters, 2) // $1 is the query vector
+	whereClause, filterArgs, err := pgvectorFilter(req.Filters, 2) // $1 is the query vector
+	if err != nil {
+		return nil, err
+	}
 	args := append([]interface{}{vec}, filterArgs...)
 
 	var selectCols string
@@ -648,7 +703,10 @@ func (c *pgvectorClient) HybridSearch(ctx context.Context, req HybridSearchReque
 	}
 	defer conn.Release()
 
-	whereClause, filterArgs := buildPgvectorFilter(req.Filters, 3) // $1=vec, $2=querytext, $3+=filters
+	whereClause, filterArgs, err := pgvectorFilter(req.Filters, 3) // $1=vec, $2=querytext, $3+=filters
+	if err != nil {
+		return nil, err
+	}
 	vec := pgvector.NewVector(toFloat32Slice(req.QueryVector))
 	candidateLimit := req.TopK * 10
 	if candidateLimit < 100 {
@@ -742,7 +800,10 @@ func (c *pgvectorClient) textOnlySearch(ctx context.Context, req HybridSearchReq
 	}
 	defer conn.Release()
 
-	whereClause, filterArgs := buildPgvectorFilter(req.Filters, 2)
+	whereClause, filterArgs, err := pgvectorFilter(req.Filters, 2)
+	if err != nil {
+		return nil, err
+	}
 	var filterSQL string
 	if whereClause != "" {
 		filterSQL = "AND " + whereClause
@@ -814,12 +875,9 @@ func pgvectorSafeTableName(name string) string {
 	return safe
 }
 
-// pgvectorSafeFieldName sanitizes a metadata field name for JSONB access.
-var safeFieldRe = regexp.MustCompile(`[^a-z0-9_]`)
-
-func pgvectorSafeFieldName(name string) string {
-	lower := strings.ToLower(name)
-	return safeFieldRe.ReplaceAllString(lower, "_")
+// pgvectorQuoteLiteral quotes s as a PostgreSQL string literal.
+func pgvectorQuoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
 
 // pgvectorDistanceOps returns the distance operator pattern and score expression type for a metric.
@@ -858,81 +916,115 @@ func computeScore(scoreExpr string, dist float64) float64 {
 	}
 }
 
-// buildPgvectorFilter converts a generic filter map to a parameterized PostgreSQL WHERE clause.
+// pgvectorFilter validates filters and converts them to a parameterized
+// PostgreSQL WHERE clause. Every filter operator maps to a JSONB expression,
+// so nothing is left to match client-side.
+func pgvectorFilter(filters map[string]interface{}, startIdx int) (string, []interface{}, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return "", nil, err
+	}
+	clause, args := buildPgvectorFilter(expr, startIdx)
+	return clause, args, nil
+}
+
+// buildPgvectorFilter converts a parsed filter to a parameterized PostgreSQL WHERE clause.
 // startIdx is the starting parameter index (e.g., 1 for $1, 2 for $2 if $1 is used by the query vector).
 //
-// Supported filter operators: $eq, $ne, $gt, $gte, $lt, $lte, $in.
-// Metadata fields are accessed as JSONB: metadata->>'fieldname'.
+// Metadata fields are accessed as JSONB: metadata->>'fieldname', or
+// metadata#>>ARRAY['a','b'] for nested paths.
 // Numeric comparisons cast the JSONB text to numeric: (metadata->>'fieldname')::numeric.
+// Conditions that SQL would evaluate to NULL for a missing field ($ne, $nin,
+// $not) are written so that a missing field behaves as in FilterExpr.Match.
 //
 // Example:
 //
 //	{"category": {"$eq": "science"}} → "metadata->>'category' = $1"
 //	{"year": {"$gt": 2020}} → "(metadata->>'year')::numeric > $1"
 //	{"tags": {"$in": ["a","b"]}} → "metadata->>'tags' = ANY($1)"
-func buildPgvectorFilter(filters map[string]interface{}, startIdx int) (string, []interface{}) {
-	if len(filters) == 0 {
+//	{"tags": {"$contains": "a"}} → "metadata->'tags' @> $1::jsonb"
+func buildPgvectorFilter(expr *FilterExpr, startIdx int) (string, []interface{}) {
+	if expr == nil {
 		return "", nil
 	}
+	b := &pgvectorFilterBuilder{idx: startIdx}
+	return b.clause(expr), b.args
+}
 
-	var clauses []string
-	var args []interface{}
-	idx := startIdx
-
-	for key, value := range filters {
-		fieldName := pgvectorSafeFieldName(key)
-		jsonbField := fmt.Sprintf("metadata->>'%s'", fieldName)
-		numericField := fmt.Sprintf("(metadata->>'%s')::numeric", fieldName)
-
-		switch v := value.(type) {
-		case map[string]interface{}:
-			// Operator-based filter.
-			for op, opVal := range v {
-				switch op {
-				case "$eq":
-					clauses = append(clauses, fmt.Sprintf("%s = $%d", jsonbField, idx))
-					args = append(args, fmt.Sprintf("%v", opVal))
-					idx++
-				case "$ne":
-					clauses = append(clauses, fmt.Sprintf("%s != $%d", jsonbField, idx))
-					args = append(args, fmt.Sprintf("%v", opVal))
-					idx++
-				case "$gt":
-					clauses = append(clauses, fmt.Sprintf("%s > $%d", numericField, idx))
-					args = append(args, opVal)
-					idx++
-				case "$gte":
-					clauses = append(clauses, fmt.Sprintf("%s >= $%d", numericField, idx))
-					args = append(args, opVal)
-					idx++
-				case "$lt":
-					clauses = append(clauses, fmt.Sprintf("%s < $%d", numericField, idx))
-					args = append(args, opVal)
-					idx++
-				case "$lte":
-					clauses = append(clauses, fmt.Sprintf("%s <= $%d", numericField, idx))
-					args = append(args, opVal)
-					idx++
-				case "$in":
-					// Convert value to []string for ANY() operator.
-					if arr, ok := opVal.([]interface{}); ok {
-						strs := make([]string, len(arr))
-						for i, item := range arr {
-							strs[i] = fmt.Sprintf("%v", item)
-						}
-						clauses = append(clauses, fmt.Sprintf("%s = ANY($%d)", jsonbField, idx))
-						args = append(args, strs)
-						idx++
-					}
-				}
-			}
-		default:
-			// Simple equality: {"field": "value"}.
-			clauses = append(clauses, fmt.Sprintf("%s = $%d", jsonbField, idx))
-			args = append(args, fmt.Sprintf("%v", v))
-			idx++
+type pgvectorFilterBuilder struct {
+	idx  int
+	args []interface{}
+}
+
+// param records an argument and returns its placeholder.
+func (b *pgvectorFilterBuilder) param(v interface{}) string {
+	b.args = append(b.args, v)
+	b.idx++
+	return fmt.Sprintf("$%d", b.idx-1)
+}
+
+func (b *pgvectorFilterBuilder) clause(e *FilterExpr) string {
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		parts := make([]string, len(e.Children))
+		for i, c := range e.Children {
+			parts[i] = b.clause(c)
+		}
+		sep := " AND "
+		if e.Op == FilterOr {
+			sep = " OR "
 		}
+		return "(" + strings.Join(parts, sep) + ")"
+	case FilterNot:
+		return "NOT COALESCE(" + b.clause(e.Children[0]) + ", false)"
+	}
+
+	textField, jsonField := pgvectorFieldExprs(e)
+	switch e.Op {
+	case FilterEq:
+		return fmt.Sprintf("%s = %s", textField, b.param(fmt.Sprintf("%v", e.Value)))
+	case FilterNe:
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", textField, b.param(fmt.Sprintf("%v", e.Value)))
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		op := map[string]string{FilterGt: ">", FilterGte: ">=", FilterLt: "<", FilterLte: "<="}[e.Op]
+		if _, isStr := e.Value.(string); isStr {
+			return fmt.Sprintf("%s %s %s", textField, op, b.param(e.Value))
+		}
+		return fmt.Sprintf("(%s)::numeric %s %s", textField, op, b.param(e.Value))
+	case FilterIn, FilterNin:
+		// Convert values to []string for the ANY() operator.
+		items := e.Values()
+		strs := make([]string, len(items))
+		for i, item := range items {
+			strs[i] = fmt.Sprintf("%v", item)
+		}
+		in := fmt.Sprintf("%s = ANY(%s)", textField, b.param(strs))
+		if e.Op == FilterNin {
+			return "NOT COALESCE(" + in + ", false)"
+		}
+		return in
+	case FilterExists:
+		if e.Value == true {
+			return jsonField + " IS NOT NULL"
+		}
+		return jsonField + " IS NULL"
+	case FilterContains:
+		element, _ := json.Marshal([]interface{}{e.Value})
+		return fmt.Sprintf("%s @> %s::jsonb", jsonField, b.param(string(element)))
 	}
+	return "TRUE"
+}
 
-	return strings.Join(clauses, " AND "), args
+// pgvectorFieldExprs returns the text and JSONB accessors of a field path.
+func pgvectorFieldExprs(e *FilterExpr) (textField, jsonField string) {
+	if !e.Nested() {
+		key := pgvectorQuoteLiteral(e.Field)
+		return "metadata->>" + key, "metadata->" + key
+	}
+	segs := e.Path()
+	for i, seg := range segs {
+		segs[i] = pgvectorQuoteLiteral(seg)
+	}
+	path := "ARRAY[" + strings.Join(segs, ",") + "]"
+	return "metadata#>>" + path, "metadata#>" + path
 }
@@ -114,8 +114,15 @@ func TestPgvectorSafeTableName(t *testing.T) {
 
 // --- Filter building ---
 
+func mustPgvectorFilter(t *testing.T, filters map[string]interface{}, startIdx int) (string, []interface{}) {
+	t.Helper()
+	clause, args, err := pgvectorFilter(filters, startIdx)
+	require.NoError(t, err)
+	return clause, args
+}
+
 func TestBuildPgvectorFilter_Empty(t *testing.T) {
-	clause, args := buildPgvectorFilter(nil, 1)
+	clause, args := mustPgvectorFilter(t, nil, 1)
 	assert.Equal(t, "", clause)
 	assert.Nil(t, args)
 }
@@ -124,7 +131,7 @@ func TestBuildPgvectorFilter_SimpleEquality(t *testing.T) {
 	filters := map[string]interface{}{
 		"category": "science",
 	}
-	clause, args := buildPgvectorFilter(filters, 1)
+	clause, args := mustPgvectorFilter(t, filters, 1)
 	assert.Contains(t, clause, "metadata->>'category'")
 	assert.Contains(t, clause, "$1")
 	assert.Equal(t, 1, len(args))
@@ -135,7 +142,7 @@ func TestBuildPgvectorFilter_EqOperator(t *testing.T) {
 	filters := map[string]interface{}{
 		"status": map[string]interface{}{"$eq": "active"},
 	}
-	clause, args := buildPgvectorFilter(filters, 1)
+	clause, args := mustPgvectorFilter(t, filters, 1)
 	assert.Contains(t, clause, "metadata->>'status' = $1")
 	assert.Equal(t, 1, len(args))
 	assert.Equal(t, "active", args[0])
@@ -145,7 +152,7 @@ func TestBuildPgvectorFilter_NumericGt(t *testing.T) {
 	filters := map[string]interface{}{
 		"year": map[string]interface{}{"$gt": float64(2020)},
 	}
-	clause, args := buildPgvectorFilter(filters, 1)
+	clause, args := mustPgvectorFilter(t, filters, 1)
 	assert.Contains(t, clause, "(metadata->>'year')::numeric > $1")
 	require.Equal(t, 1, len(args))
 }
@@ -154,7 +161,7 @@ func TestBuildPgvectorFilter_InOperator(t *testing.T) {
 	filters := map[string]interface{}{
 		"tags": map[string]interface{}{"$in": []interface{}{"a", "b", "c"}},
 	}
-	clause, args := buildPgvectorFilter(filters, 1)
+	clause, args := mustPgvectorFilter(t, filters, 1)
 	assert.Contains(t, clause, "metadata->>'tags' = ANY($1)")
 	require.Equal(t, 1, len(args))
 	strs, ok := args[0].([]string)
@@ -167,7 +174,7 @@ func TestBuildPgvectorFilter_MultipleConditions(t *testing.T) {
 		"category": map[string]interface{}{"$eq": "tech"},
 		"year":     map[string]interface{}{"$gte": float64(2022)},
 	}
-	clause, args := buildPgvectorFilter(filters, 1)
+	clause, args := mustPgvectorFilter(t, filters, 1)
 	assert.Contains(t, clause, "AND")
 	assert.Equal(t, 2, len(args))
 }
@@ -177,11 +184,49 @@ func TestBuildPgvectorFilter_ParamStartIdx(t *testing.T) {
 	filters := map[string]interface{}{
 		"category": "tech",
 	}
-	clause, args := buildPgvectorFilter(filters, 2)
+	clause, args := mustPgvectorFilter(t, filters, 2)
 	assert.Contains(t, clause, "$2")
 	assert.Equal(t, 1, len(args))
 }
 
+func TestBuildPgvectorFilter_Logical(t *testing.T) {
+	filters := map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"meta.team": "search"},
+			map[string]interface{}{"$not": map[string]interface{}{"status": map[string]interface{}{"$ne": "draft"}}},
+		},
+		"tags": map[string]interface{}{"$contains": "faq"},
+	}
+	clause, args := mustPgvectorFilter(t, filters, 1)
+	assert.Equal(t, "((metadata#>>ARRAY['meta','team'] = $1 OR "+
+		"NOT COALESCE(metadata->>'status' IS DISTINCT FROM $2, false)) AND "+
+		"metadata->'tags' @> $3::jsonb)", clause)
+	assert.Equal(t, []interface{}{"search", "draft", `["faq"]`}, args)
+}
+
+func TestBuildPgvectorFilter_ExistsAndNin(t *testing.T) {
+	filters := map[string]interface{}{
+		"author": map[string]interface{}{"$exists": false},
+		"lang":   map[string]interface{}{"$nin": []interface{}{"de"}},
+		"title":  map[string]interface{}{"$gte": "m"},
+	}
+	clause, args := mustPgvectorFilter(t, filters, 1)
+	assert.Equal(t, "(metadata->'author' IS NULL AND "+
+		"NOT COALESCE(metadata->>'lang' = ANY($1), false) AND "+
+		"metadata->>'title' >= $2)", clause)
+	assert.Len(t, args, 2)
+}
+
+func TestBuildPgvectorFilter_QuotesKeys(t *testing.T) {
+	clause, _ := mustPgvectorFilter(t, map[string]interface{}{"it's": "x"}, 1)
+	assert.Equal(t, "metadata->>'it''s' = $1", clause)
+}
+
+func TestBuildPgvectorFilter_Invalid(t *testing.T) {
+	_, _, err := pgvectorFilter(map[string]interface{}{"a": map[string]interface{}{"$regex": "x"}}, 1)
+	require.Error(t, err)
+}
+
 // --- Score computation ---
 
 func TestComputeScore_Cosine(t *testing.T) {
@@ -0,0 +1,178 @@
+package evaluateRetrieval
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/support/connection"
+)
+
+// Settings holds design-time activity configuration. The search settings
+// are the baseline configuration; each parameterGrid entry overrides them.
+type Settings struct {
+	Connection        connection.Manager `md:"connection,required"`
+	DefaultCollection string             `md:"defaultCollection"`
+	// SearchMode is "vector" (default) or "hybrid".
+	SearchMode     string  `md:"searchMode"`
+	DefaultTopK    int     `md:"defaultTopK"`
+	ScoreThreshold float64 `md:"scoreThreshold"`
+	HybridAlpha    float64 `md:"hybridAlpha"`
+	// IDField names the payload field holding the document ID. When set,
+	// chunk results are mapped to their document and scored once per
+	// document, so a golden set can list document IDs rather than chunk IDs.
+	IDField      string `md:"idField"`
+	ContentField string `md:"contentField"`
+
+	// --- Query embedding (only used for golden questions without a queryVector) ---
+	UseConnectorEmbedding bool   `md:"useConnectorEmbedding"`
+	EmbeddingProvider     string `md:"embeddingProvider"`
+	EmbeddingAPIKey       string `md:"embeddingAPIKey"`
+	EmbeddingBaseURL      string `md:"embeddingBaseURL"`
+	EmbeddingModel        string `md:"embeddingModel"`
+	EmbeddingDimensions   int    `md:"embeddingDimensions"`
+
+	// --- Rerank stage (only used when EnableRerank=true or a grid entry sets rerank) ---
+	EnableRerank   bool   `md:"enableRerank"`
+	RerankEndpoint string `md:"rerankEndpoint"`
+	RerankAPIKey   string `md:"rerankAPIKey"`
+	RerankModel    string `md:"rerankModel"`
+
+	// PrimaryMetric ranks the runs when comparing a parameter grid,
+	// e.g. "mrr", "recall@5" or "ndcg@10". Default: "mrr".
+	PrimaryMetric  string `md:"primaryMetric"`
+	TimeoutSeconds int    `md:"timeoutSeconds"`
+}
+
+// String returns a human-readable representation of Settings with the API
+// keys replaced by "[redacted]".
+func (s Settings) String() string {
+	embeddingKey, rerankKey := "", ""
+	if s.EmbeddingAPIKey != "" {
+		embeddingKey = "[redacted]"
+	}
+	if s.RerankAPIKey != "" {
+		rerankKey = "[redacted]"
+	}
+	return fmt.Sprintf("Settings{SearchMode:%s DefaultTopK:%d ScoreThreshold:%.4f HybridAlpha:%.2f IDField:%s EmbeddingProvider:%s EmbeddingModel:%s EmbeddingAPIKey:%s EnableRerank:%v RerankEndpoint:%s RerankAPIKey:%s PrimaryMetric:%s}",
+		s.SearchMode, s.DefaultTopK, s.ScoreThreshold, s.HybridAlpha, s.IDField, s.EmbeddingProvider, s.EmbeddingModel, embeddingKey,
+		s.EnableRerank, s.RerankEndpoint, rerankKey, s.PrimaryMetric)
+}
+
+type Input struct {
+	CollectionName string `md:"collectionName"`
+	// GoldenSet is a list of {"query", "expectedIds", "relevance", "queryVector", "filters"} objects.
+	GoldenSet []interface{} `md:"goldenSet"`
+	// KValues are the cut-offs for recall@k and nDCG@k. Default: [1, 3, 5, 10].
+	KValues []int `md:"kValues"`
+	// ParameterGrid is a list of configurations to compare, each overriding
+	// some of topK, scoreThreshold, searchMode, alpha and rerank.
+	ParameterGrid   []interface{} `md:"parameterGrid"`
+	IncludePerQuery bool          `md:"includePerQuery"`
+}
+
+func (i *Input) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"collectionName":  i.CollectionName,
+		"goldenSet":       i.GoldenSet,
+		"kValues":         i.KValues,
+		"parameterGrid":   i.ParameterGrid,
+		"includePerQuery": i.IncludePerQuery,
+	}
+}
+
+func (i *Input) FromMap(v map[string]interface{}) error {
+	if val, ok := v["collectionName"]; ok && val != nil {
+		i.CollectionName = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["goldenSet"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.GoldenSet = arr
+		}
+	}
+	if val, ok := v["kValues"]; ok {
+		switch arr := val.(type) {
+		case []int:
+			i.KValues = arr
+		case []interface{}:
+			i.KValues = make([]int, 0, len(arr))
+			for _, k := range arr {
+				if n, ok := toInt(k); ok {
+					i.KValues = append(i.KValues, n)
+				}
+			}
+		}
+	}
+	if val, ok := v["parameterGrid"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			i.ParameterGrid = arr
+		}
+	}
+	if val, ok := v["includePerQuery"]; ok {
+		i.IncludePerQuery, _ = val.(bool)
+	}
+	return nil
+}
+
+type Output struct {
+	Success bool `md:"success"`
+	// Runs holds one report per evaluated configuration, in grid order.
+	Runs []interface{} `md:"runs"`
+	// BestRun is the name of the run with the highest PrimaryMetric.
+	BestRun    string `md:"bestRun"`
+	QueryCount int    `md:"queryCount"`
+	Duration   string `md:"duration"`
+	Error      string `md:"error"`
+}
+
+func (o *Output) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"success":    o.Success,
+		"runs":       o.Runs,
+		"bestRun":    o.BestRun,
+		"queryCount": o.QueryCount,
+		"duration":   o.Duration,
+		"error":      o.Error,
+	}
+}
+
+func (o *Output) FromMap(v map[string]interface{}) error {
+	if val, ok := v["success"]; ok {
+		o.Success, _ = val.(bool)
+	}
+	if val, ok := v["runs"]; ok {
+		if arr, ok := val.([]interface{}); ok {
+			o.Runs = arr
+		}
+	}
+	if val, ok := v["bestRun"]; ok {
+		o.BestRun, _ = val.(string)
+	}
+	return nil
+}
+
+// toInt converts a JSON number (float64) or Go int to an int.
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// toFloat converts a JSON number or Go int to a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -455,3 +455,28 @@ func TestParseDocuments_MetadataPassthrough(t *testing.T) {
 	assert.Equal(t, "news", docs[0].Metadata["category"])
 	assert.Equal(t, float64(1), docs[0].Metadata["priority"])
 }
+
+func TestParseFiles_KeepsSectionsAndDocumentMetadata(t *testing.T) {
+	files := []interface{}{
+		map[string]interface{}{
+			"name":     "kb.jsonl",
+			"content":  []byte("{\"title\": \"a\"}\n{\"title\": \"b\"}\n"),
+			"metadata": map[string]interface{}{"team": "IAM"},
+		},
+		map[string]interface{}{
+			"name":     "page.html",
+			"content":  []byte("<title>Home</title><h1>Hi</h1>"),
+			"metadata": map[string]interface{}{"title": "Overridden"},
+		},
+	}
+	docs, err := parseFiles(files, log.RootLogger())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	require.Len(t, docs[0].Sections, 2)
+	assert.Equal(t, "## Record 1: a\ntitle: a\n\n## Record 2: b\ntitle: b", docs[0].Text)
+	assert.Equal(t, "jsonl", docs[0].Metadata["type"])
+	assert.Equal(t, "IAM", docs[0].Metadata["team"])
+
+	assert.Equal(t, "Overridden", docs[1].Metadata["title"], "caller metadata wins over extracted metadata")
+}
@@ -0,0 +1,65 @@
+//go:build integration && onnx
+
+// Integration test for the Local provider with a real sentence-transformer
+// and the real ONNX Runtime. It needs a model directory exported to ONNX
+// and, on air-gapped machines, a local onnxruntime library:
+//
+//	huggingface-cli download sentence-transformers/all-MiniLM-L6-v2 \
+//	    onnx/model.onnx tokenizer.json config.json sentence_bert_config.json \
+//	    --local-dir /models/all-MiniLM-L6-v2
+//
+// Execute:
+//
+//	VDB_LOCAL_MODEL_DIR=/models/all-MiniLM-L6-v2 \
+//	VDB_ONNXRUNTIME_LIB=/opt/onnxruntime/lib/libonnxruntime.so \
+//	    go test -tags "integration onnx" -run TestCreateEmbeddings_LocalRealModel -v ./embeddings/
+//
+// Without VDB_ONNXRUNTIME_LIB the runtime is located by pure-onnx
+// (ONNXRUNTIME_LIB_PATH) or downloaded on first use.
+package vdbembed
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEmbeddings_LocalRealModel(t *testing.T) {
+	dir := os.Getenv("VDB_LOCAL_MODEL_DIR")
+	if dir == "" {
+		t.Skip("VDB_LOCAL_MODEL_DIR is not set")
+	}
+	require.NoError(t, InitONNXRuntime(os.Getenv("VDB_ONNXRUNTIME_LIB")))
+
+	resp, err := CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Provider: ProviderLocal,
+		Model:    dir,
+		Texts: []string{
+			"A cat is sitting on the mat.",
+			"There is a cat on the rug.",
+			"Stock markets fell sharply on Monday.",
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Embeddings, 3)
+	assert.Greater(t, resp.TokensUsed, 0)
+
+	dot := func(a, b []float64) float64 {
+		var s float64
+		for i := range a {
+			s += a[i] * b[i]
+		}
+		return s
+	}
+	for _, vec := range resp.Embeddings {
+		require.Len(t, vec, resp.Dimensions)
+		assert.InDelta(t, 1, math.Sqrt(dot(vec, vec)), 1e-6, "vectors are L2-normalised")
+	}
+	similar := dot(resp.Embeddings[0], resp.Embeddings[1])
+	unrelated := dot(resp.Embeddings[0], resp.Embeddings[2])
+	assert.Greater(t, similar, unrelated, "paraphrases are closer than unrelated text")
+}
@@ -0,0 +1,104 @@
+package vectordb
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMMRFetchK(t *testing.T) {
+	tests := []struct {
+		topK, fetchK, want int
+	}{
+		{5, 0, 20},
+		{10, 0, 40},
+		{5, 12, 12},
+		{10, 4, 10},
+	}
+	for _, tt := range tests {
+		if got := MMRFetchK(tt.topK, tt.fetchK); got != tt.want {
+			t.Errorf("MMRFetchK(%d, %d) = %d, want %d", tt.topK, tt.fetchK, got, tt.want)
+		}
+	}
+}
+
+func TestSelectMMR_SkipsNearDuplicates(t *testing.T) {
+	query := []float64{1, 0}
+	candidates := []SearchResult{
+		{ID: "a", Score: 0.99, Vector: []float64{1, 0.05}},
+		{ID: "a-copy", Score: 0.98, Vector: []float64{1, 0.06}},
+		{ID: "b", Score: 0.80, Vector: []float64{0.8, -0.6}},
+	}
+	got := SelectMMR(query, candidates, 2, DefaultMMRLambda)
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("SelectMMR = %v, want [a b]", ids(got))
+	}
+
+	// lambda = 1 is plain relevance ranking.
+	got = SelectMMR(query, candidates, 2, 1)
+	if got[1].ID != "a-copy" {
+		t.Errorf("SelectMMR(lambda=1) = %v, want [a a-copy]", ids(got))
+	}
+}
+
+func TestSelectMMR_ScoreRelevanceWithoutQueryVector(t *testing.T) {
+	candidates := []SearchResult{
+		{ID: "a", Score: 12, Vector: []float64{0, 1}},
+		{ID: "a-copy", Score: 11, Vector: []float64{0, 1}},
+		{ID: "b", Score: 9, Vector: []float64{1, 0}},
+	}
+	got := SelectMMR(nil, candidates, 2, DefaultMMRLambda)
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("SelectMMR = %v, want [a b]", ids(got))
+	}
+}
+
+func TestSelectMMR_NoVectors(t *testing.T) {
+	candidates := []SearchResult{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	got := SelectMMR([]float64{1}, candidates, 2, DefaultMMRLambda)
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("SelectMMR = %v, want ranked [a b]", ids(got))
+	}
+	if got := SelectMMR(nil, nil, 3, DefaultMMRLambda); got != nil {
+		t.Errorf("SelectMMR(no candidates) = %v, want nil", got)
+	}
+}
+
+type getDocumentClient struct {
+	VectorDBClient
+	docs map[string]*Document
+}
+
+func (c getDocumentClient) GetDocument(_ context.Context, _, id string) (*Document, error) {
+	if d, ok := c.docs[id]; ok {
+		return d, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestFetchMissingVectors(t *testing.T) {
+	client := getDocumentClient{docs: map[string]*Document{"b": {ID: "b", Vector: []float64{0, 1}}}}
+	results := []SearchResult{
+		{ID: "a", Vector: []float64{1, 0}},
+		{ID: "b"},
+		{ID: "c"},
+	}
+	err := FetchMissingVectors(context.Background(), client, "col", results)
+	if err == nil {
+		t.Error("expected the error for the missing document")
+	}
+	if len(results[1].Vector) != 2 {
+		t.Errorf("vector of b not fetched: %+v", results[1])
+	}
+	if results[2].Vector != nil {
+		t.Errorf("vector of c = %v, want nil", results[2].Vector)
+	}
+}
+
+func ids(results []SearchResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.ID
+	}
+	return out
+}
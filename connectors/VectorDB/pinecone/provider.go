@@ -24,6 +24,7 @@ type pineconeClient struct {
 
 // Compile-time proof that pineconeClient satisfies the full VectorDBClient interface.
 var _ VectorDBClient = (*pineconeClient)(nil)
+var _ MetricReader = (*pineconeClient)(nil)
 
 func newPineconeClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	return &pineconeClient{
@@ -301,6 +302,32 @@ func (c *pineconeClient) CollectionExists(ctx context.Context, name string) (boo
 	return exists, nil
 }
 
+// CollectionMetric returns the metric the Pinecone index was created with.
+func (c *pineconeClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	endpoint := fmt.Sprintf("%s/indexes/%s", c.controlPlaneURL(), name)
+	var metric string
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		body, err := c.doRequest(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		var resp struct {
+			Metric string `json:"metric"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return newError(ErrCodeProviderError, "pinecone: decode index description", err)
+		}
+		metric = resp.Metric
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	if metric == "dotproduct" {
+		return "dot", nil
+	}
+	return metric, nil
+}
+
 // ── Document operations ──────────────────────────────────────────────────────
 
 // UpsertDocuments inserts or updates documents in the given Pinecone index.
@@ -418,13 +445,31 @@ func (c *pineconeClient) DeleteByFilter(ctx context.Context, collectionName stri
 	if len(filters) == 0 {
 		return 0, newError(ErrCodeProviderError, "DeleteByFilter requires at least one filter", nil)
 	}
+	filter, residual, err := pineconeFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		// Part of the filter is matched client-side: delete by ID instead.
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	indexHost, err := c.getIndexHost(ctx, collectionName)
 	if err != nil {
 		return 0, err
 	}
 
 	reqBody := map[string]interface{}{
-		"filter":    buildPineconeFilter(filters),
+		"filter":    filter,
 		"deleteAll": false,
 	}
 	endpoint := fmt.Sprintf("%s/vectors/delete", indexHost)
@@ -440,11 +485,17 @@ func (c *pineconeClient) DeleteByFilter(ctx context.Context, collectionName stri
 // ScrollDocuments paginates through documents using the Pinecone list + fetch approach.
 // Step 1: list IDs via /vectors/list?prefix=&limit=N&paginationToken=X
 // Step 2: fetch full vectors+metadata via /vectors/fetch?ids=...
+// The list endpoint does not filter, so Filters are matched client-side and a
+// page may hold fewer than Limit documents.
 func (c *pineconeClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (*ScrollResult, error) {
 	limit := req.Limit
 	if limit <= 0 {
 		limit = 100
 	}
+	filter, err := ParseFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 
 	indexHost, err := c.getIndexHost(ctx, req.CollectionName)
 	if err != nil {
@@ -497,7 +548,7 @@ func (c *pineconeClient) ScrollDocuments(ctx context.Context, req ScrollRequest)
 	}
 
 	return &ScrollResult{
-		Documents:  docs,
+		Documents:  matchDocuments(docs, filter),
 		NextOffset: nextToken,
 		Total:      -1,
 	}, nil
@@ -545,6 +596,9 @@ func (c *pineconeClient) fetchByIDs(ctx context.Context, indexHost string, ids [
 // describe_index_stats is a data-plane endpoint on the index host, NOT the control plane.
 func (c *pineconeClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
 	if len(filters) > 0 {
+		if _, err := ParseFilter(filters); err != nil {
+			return 0, err
+		}
 		return -1, nil
 	}
 
@@ -587,14 +641,19 @@ func (c *pineconeClient) VectorSearch(ctx context.Context, req SearchRequest) ([
 		return nil, err
 	}
 
+	filter, residual, err := pineconeFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	withPayload := !req.SkipPayload || residual != nil
 	reqBody := map[string]interface{}{
 		"vector":          toFloat32Slice(req.QueryVector),
-		"topK":            req.TopK,
-		"includeMetadata": !req.SkipPayload,
+		"topK":            searchLimit(req.TopK, residual),
+		"includeMetadata": withPayload,
 		"includeValues":   req.WithVectors,
 	}
-	if len(req.Filters) > 0 {
-		reqBody["filter"] = buildPineconeFilter(req.Filters)
+	if filter != nil {
+		reqBody["filter"] = filter
 	}
 
 	endpoint := fmt.Sprintf("%s/query", indexHost)
@@ -624,7 +683,7 @@ func (c *pineconeClient) VectorSearch(ctx context.Context, req SearchRequest) ([
 				ID:    m.ID,
 				Score: m.Score,
 			}
-			if !req.SkipPayload && m.Metadata != nil {
+			if withPayload && m.Metadata != nil {
 				if content, ok := m.Metadata["_content"].(string); ok {
 					sr.Content = content
 				}
@@ -647,7 +706,7 @@ func (c *pineconeClient) VectorSearch(ctx context.Context, req SearchRequest) ([
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
 	}
-	return results, nil
+	return matchResults(results, residual, req.TopK, req.SkipPayload), nil
 }
 
 // HybridSearch performs combined dense + sparse search using Pinecone's sparseVector support.
@@ -665,9 +724,14 @@ func (c *pineconeClient) HybridSearch(ctx context.Context, req HybridSearchReque
 		return nil, err
 	}
 
+	filter, residual, err := pineconeFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	withPayload := !req.SkipPayload || residual != nil
 	reqBody := map[string]interface{}{
-		"topK":            req.TopK,
-		"includeMetadata": !req.SkipPayload,
+		"topK":            searchLimit(req.TopK, residual),
+		"includeMetadata": withPayload,
 	}
 
 	if len(req.QueryVector) > 0 {
@@ -687,8 +751,8 @@ func (c *pineconeClient) HybridSearch(ctx context.Context, req HybridSearchReque
 		}
 	}
 
-	if len(req.Filters) > 0 {
-		reqBody["filter"] = buildPineconeFilter(req.Filters)
+	if filter != nil {
+		reqBody["filter"] = filter
 	}
 
 	endpoint := fmt.Sprintf("%s/query", indexHost)
@@ -718,7 +782,7 @@ func (c *pineconeClient) HybridSearch(ctx context.Context, req HybridSearchReque
 				ID:    m.ID,
 				Score: m.Score,
 			}
-			if !req.SkipPayload && m.Metadata != nil {
+			if withPayload && m.Metadata != nil {
 				if content, ok := m.Metadata["_content"].(string); ok {
 					sr.Content = content
 				}
@@ -738,7 +802,7 @@ func (c *pineconeClient) HybridSearch(ctx context.Context, req HybridSearchReque
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "HybridSearch failed", err)
 	}
-	return results, nil
+	return matchResults(results, residual, req.TopK, req.SkipPayload), nil
 }
 
 // HealthCheck verifies that the Pinecone control plane is reachable.
@@ -822,27 +886,59 @@ func buildSparseVector(text string) (indices []uint32, values []float32) {
 
 // ── Filter builder ───────────────────────────────────────────────────────────
 
-// buildPineconeFilter converts a generic filter map to Pinecone metadata filter syntax.
-// Pinecone uses MongoDB-style operators: $eq, $ne, $gt, $gte, $lt, $lte, $in.
-//
-// Multiple top-level entries are ANDed implicitly.
-// Each value can be a scalar (equality) or an operator map.
-func buildPineconeFilter(filters map[string]interface{}) map[string]interface{} {
-	if len(filters) == 0 {
+// pineconeFilter validates filters and splits them into a Pinecone metadata
+// filter and a residual that is matched client-side. $not, nested paths and
+// string ranges have no Pinecone equivalent.
+func pineconeFilter(filters map[string]interface{}) (map[string]interface{}, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	pushed, residual := splitFilter(expr, pineconeNative)
+	return buildPineconeFilter(pushed), residual, nil
+}
+
+// pineconeNative reports whether Pinecone can evaluate e. Pinecone metadata
+// is flat, and its range operators only compare numbers.
+func pineconeNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterNot:
+			return false
+		case FilterAnd, FilterOr:
+			return true
+		case FilterGt, FilterGte, FilterLt, FilterLte:
+			if _, isNum := filterNumber(n.Value); !isNum {
+				return false
+			}
+		}
+		return !n.Nested()
+	})
+}
+
+// buildPineconeFilter converts a parsed filter to Pinecone metadata filter syntax.
+// Pinecone uses MongoDB-style operators: $eq, $ne, $gt, $gte, $lt, $lte, $in,
+// $nin, $exists, $and and $or. $contains becomes $in with one value, which
+// matches any element of a list field.
+func buildPineconeFilter(expr *FilterExpr) map[string]interface{} {
+	if expr == nil {
 		return nil
 	}
-	result := make(map[string]interface{}, len(filters))
-	for k, v := range filters {
-		switch val := v.(type) {
-		case map[string]interface{}:
-			// Operator map — pass through as-is (Pinecone uses the same $ syntax).
-			result[k] = val
-		default:
-			// Scalar equality → $eq wrapper.
-			result[k] = map[string]interface{}{"$eq": v}
+	switch expr.Op {
+	case FilterAnd, FilterOr:
+		children := make([]interface{}, len(expr.Children))
+		for i, c := range expr.Children {
+			children[i] = buildPineconeFilter(c)
 		}
+		return map[string]interface{}{expr.Op: children}
+	case FilterContains:
+		return map[string]interface{}{
+			expr.Field: map[string]interface{}{FilterIn: []interface{}{expr.Value}},
+		}
+	}
+	return map[string]interface{}{
+		expr.Field: map[string]interface{}{expr.Op: expr.Value},
 	}
-	return result
 }
 
 // ── Document conversion helpers ──────────────────────────────────────────────
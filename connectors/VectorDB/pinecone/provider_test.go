@@ -121,8 +121,16 @@ func TestNewClientReturnsVectorDBClient(t *testing.T) {
 
 // ── buildPineconeFilter ──────────────────────────────────────────────────────
 
+func mustPineconeFilter(t *testing.T, filters map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	f, residual, err := pineconeFilter(filters)
+	require.NoError(t, err)
+	require.Nil(t, residual)
+	return f
+}
+
 func TestBuildPineconeFilterScalarEquality(t *testing.T) {
-	f := buildPineconeFilter(map[string]interface{}{
+	f := mustPineconeFilter(t, map[string]interface{}{
 		"category": "science",
 	})
 	require.NotNil(t, f)
@@ -132,7 +140,7 @@ func TestBuildPineconeFilterScalarEquality(t *testing.T) {
 }
 
 func TestBuildPineconeFilterOperatorPassthrough(t *testing.T) {
-	f := buildPineconeFilter(map[string]interface{}{
+	f := mustPineconeFilter(t, map[string]interface{}{
 		"score": map[string]interface{}{"$gt": 0.5},
 	})
 	require.NotNil(t, f)
@@ -142,8 +150,42 @@ func TestBuildPineconeFilterOperatorPassthrough(t *testing.T) {
 }
 
 func TestBuildPineconeFilterNil(t *testing.T) {
-	assert.Nil(t, buildPineconeFilter(nil))
-	assert.Nil(t, buildPineconeFilter(map[string]interface{}{}))
+	assert.Nil(t, mustPineconeFilter(t, nil))
+	assert.Nil(t, mustPineconeFilter(t, map[string]interface{}{}))
+}
+
+func TestBuildPineconeFilterLogical(t *testing.T) {
+	f := mustPineconeFilter(t, map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"genre": "drama"},
+			map[string]interface{}{"year": map[string]interface{}{"$gte": 2020}},
+		},
+		"tags": map[string]interface{}{"$contains": "faq"},
+	})
+	and, ok := f["$and"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, and, 2)
+	or, ok := and[0].(map[string]interface{})["$or"].([]interface{})
+	require.True(t, ok)
+	assert.Len(t, or, 2)
+	tags := and[1].(map[string]interface{})["tags"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"faq"}, tags["$in"])
+}
+
+func TestPineconeFilterResidual(t *testing.T) {
+	f, residual, err := pineconeFilter(map[string]interface{}{
+		"genre":     "drama",
+		"$not":      map[string]interface{}{"status": "draft"},
+		"meta.team": "search",
+		"title":     map[string]interface{}{"$gt": "m"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"genre": map[string]interface{}{"$eq": "drama"}}, f)
+	require.NotNil(t, residual)
+	assert.Len(t, residual.Conjuncts(), 3)
+
+	_, _, err = pineconeFilter(map[string]interface{}{"a": map[string]interface{}{"$regex": "x"}})
+	require.Error(t, err)
 }
 
 // ── VDBError ─────────────────────────────────────────────────────────────────
@@ -60,8 +60,8 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		input.ReplicationFactor = 1
 	}
 
-	l.Debugf("CreateCollection: name=%s dims=%d metric=%s onDisk=%v replicas=%d",
-		input.CollectionName, input.Dimensions, input.DistanceMetric, input.OnDisk, input.ReplicationFactor)
+	l.Debugf("CreateCollection: name=%s dims=%d metric=%s onDisk=%v replicas=%d sparse=%v",
+		input.CollectionName, input.Dimensions, input.DistanceMetric, input.OnDisk, input.ReplicationFactor, input.EnableSparse)
 
 	// OTel trace tags
 	tc := ctx.GetTracingContext()
@@ -88,6 +88,7 @@ func (a *Activity) Eval(ctx activity.Context) (bool, error) {
 		DistanceMetric:    input.DistanceMetric,
 		OnDisk:            input.OnDisk,
 		ReplicationFactor: input.ReplicationFactor,
+		EnableSparse:      input.EnableSparse,
 	}
 	if createErr := a.conn.GetClient().CreateCollection(opCtx, cfg); createErr != nil {
 		// Treat "already exists" as success — idempotent create
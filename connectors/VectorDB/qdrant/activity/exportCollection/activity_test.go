@@ -0,0 +1,177 @@
+package exportCollection
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant"
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant/connector"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant/testutil/mock"
+	vdbtransfer "github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant/transfer"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/core/support/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeActivityContext struct {
+	inputs  map[string]interface{}
+	outputs map[string]interface{}
+}
+
+func (f *fakeActivityContext) ActivityHost() activity.Host        { return nil }
+func (f *fakeActivityContext) Name() string                       { return "test" }
+func (f *fakeActivityContext) GetSetting(name string) interface{} { return nil }
+func (f *fakeActivityContext) GetInput(name string) interface{} {
+	if f.inputs == nil {
+		return nil
+	}
+	return f.inputs[name]
+}
+func (f *fakeActivityContext) SetOutput(name string, value interface{}) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	f.outputs[name] = value
+	return nil
+}
+func (f *fakeActivityContext) GetInputObject(obj data.StructValue) error {
+	return obj.FromMap(f.inputs)
+}
+func (f *fakeActivityContext) SetOutputObject(obj data.StructValue) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	for k, v := range obj.ToMap() {
+		f.outputs[k] = v
+	}
+	return nil
+}
+func (f *fakeActivityContext) GetSharedTempData() map[string]interface{} { return nil }
+func (f *fakeActivityContext) Logger() log.Logger                        { return log.RootLogger() }
+func (f *fakeActivityContext) GetTracingContext() trace.TracingContext   { return nil }
+func (f *fakeActivityContext) GoContext() context.Context                { return context.Background() }
+
+func newTestConn(client vectordb.VectorDBClient) *vectordbconnector.QdrantConnection {
+	s := &vectordbconnector.Settings{Host: "localhost", TimeoutSeconds: 5}
+	return vectordbconnector.NewConnectionForTest("test-conn", client, s)
+}
+
+var testDocs = []vectordb.Document{
+	{ID: "a", Vector: []float64{1, 0}, Content: "alpha", Payload: map[string]interface{}{"lang": "en"}},
+	{ID: "b", Vector: []float64{0, 1}, Content: "beta"},
+}
+
+func scrollPage(mc *mockclient.VectorDBClient, offset string, docs []vectordb.Document, next string) {
+	mc.On("ScrollDocuments", mock.Anything, mock.MatchedBy(func(r vectordb.ScrollRequest) bool {
+		return r.CollectionName == "kb" && r.Offset == offset && r.WithVectors
+	})).Return(&vectordb.ScrollResult{Documents: docs, NextOffset: next, Total: -1}, nil).Once()
+}
+
+func readAll(t *testing.T, path, format string) []vectordb.Document {
+	r, err := vdbtransfer.OpenFile(path, format)
+	require.NoError(t, err)
+	defer r.Close()
+	docs, err := r.ReadBatch(100)
+	require.NoError(t, err)
+	return docs
+}
+
+func TestExportCollection_JSONLAndParquet(t *testing.T) {
+	for _, ext := range []string{"jsonl", "parquet"} {
+		t.Run(ext, func(t *testing.T) {
+			mc := &mockclient.VectorDBClient{}
+			scrollPage(mc, "", testDocs[:1], "c1")
+			scrollPage(mc, "c1", testDocs[1:], "")
+
+			path := filepath.Join(t.TempDir(), "kb."+ext)
+			a := &Activity{conn: newTestConn(mc), settings: &Settings{BatchSize: 1, Format: "auto", TimeoutSeconds: 30}}
+			ctx := &fakeActivityContext{inputs: map[string]interface{}{
+				"collectionName": "kb", "filePath": path, "distanceMetric": "Cosine",
+			}}
+			ok, err := a.Eval(ctx)
+			require.True(t, ok)
+			require.NoError(t, err)
+			assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+			assert.Equal(t, int64(2), ctx.outputs["documentCount"])
+			assert.Equal(t, 2, ctx.outputs["dimensions"])
+			assert.Equal(t, ext, ctx.outputs["format"])
+			mc.AssertExpectations(t)
+
+			assert.Equal(t, testDocs, readAll(t, path, ext))
+			m, err := vdbtransfer.ReadManifest(vdbtransfer.ManifestPath(path))
+			require.NoError(t, err)
+			assert.True(t, m.Complete)
+			assert.Equal(t, int64(2), m.Documents)
+			assert.Equal(t, "cosine", m.DistanceMetric)
+			assert.Equal(t, "qdrant", m.Provider)
+		})
+	}
+}
+
+func TestExportCollection_ResumesInterruptedJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kb.jsonl")
+	w, err := vdbtransfer.CreateFile(path, vdbtransfer.FormatJSONL)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBatch(context.Background(), testDocs[:1]))
+	size, err := w.Close()
+	require.NoError(t, err)
+	// A partial line from the batch that was being written when the run died.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, _ = f.WriteString(`{"id":"b","vec`)
+	f.Close()
+	require.NoError(t, vdbtransfer.WriteManifest(vdbtransfer.ManifestPath(path), &vdbtransfer.Manifest{
+		Format: "jsonl", Collection: "kb", Dimensions: 2, Documents: 1, Cursor: "c1", Bytes: size,
+	}))
+
+	mc := &mockclient.VectorDBClient{}
+	scrollPage(mc, "c1", testDocs[1:], "")
+	a := &Activity{conn: newTestConn(mc), settings: &Settings{Format: "auto", TimeoutSeconds: 30}}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"collectionName": "kb", "filePath": path}}
+	_, err = a.Eval(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, true, ctx.outputs["resumed"])
+	assert.Equal(t, int64(1), ctx.outputs["exportedCount"])
+	assert.Equal(t, int64(2), ctx.outputs["documentCount"])
+	mc.AssertExpectations(t)
+	assert.Equal(t, testDocs, readAll(t, path, "jsonl"))
+}
+
+func TestExportCollection_ScrollErrorKeepsCursor(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	scrollPage(mc, "", testDocs[:1], "c1")
+	mc.On("ScrollDocuments", mock.Anything, mock.MatchedBy(func(r vectordb.ScrollRequest) bool { return r.Offset == "c1" })).
+		Return((*vectordb.ScrollResult)(nil), assert.AnError)
+
+	path := filepath.Join(t.TempDir(), "kb.jsonl")
+	a := &Activity{conn: newTestConn(mc), settings: &Settings{Format: "auto", TimeoutSeconds: 30}}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"collectionName": "kb", "filePath": path}}
+	ok, err := a.Eval(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, false, ctx.outputs["success"])
+	assert.Equal(t, "c1", ctx.outputs["cursor"])
+	m, err := vdbtransfer.ReadManifest(vdbtransfer.ManifestPath(path))
+	require.NoError(t, err)
+	assert.False(t, m.Complete)
+	assert.Equal(t, "c1", m.Cursor)
+}
+
+func TestExportCollection_Validation(t *testing.T) {
+	a := &Activity{conn: newTestConn(&mockclient.VectorDBClient{}), settings: &Settings{Format: "auto", TimeoutSeconds: 30}}
+	for name, inputs := range map[string]map[string]interface{}{
+		"missing collection": {"filePath": "/tmp/x.jsonl"},
+		"missing file":       {"collectionName": "kb"},
+		"unknown extension":  {"collectionName": "kb", "filePath": "/tmp/x.csv"},
+	} {
+		_, err := a.Eval(&fakeActivityContext{inputs: inputs})
+		assert.Error(t, err, name)
+	}
+}
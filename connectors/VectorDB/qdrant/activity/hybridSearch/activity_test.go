@@ -213,3 +213,57 @@ func TestHybridSearch_AlphaBoundaryValid(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestHybridSearch_Fusion_Forwarded(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("HybridSearch", mock.Anything, mock.MatchedBy(func(r vectordb.HybridSearchRequest) bool {
+		return r.Fusion == "dbsf"
+	})).Return([]vectordb.SearchResult{}, nil)
+
+	a := &Activity{conn: newTestConn(mc), settings: &Settings{}}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col",
+		"queryText":      "ERR-4012",
+		"queryVector":    []interface{}{0.1, 0.2},
+		"alpha":          0.5,
+		"fusion":         "dbsf",
+	}}
+	ok, err := a.Eval(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	mc.AssertExpectations(t)
+}
+
+func TestHybridSearch_MMRFetchesVectors(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("HybridSearch", mock.Anything, mock.MatchedBy(func(r vectordb.HybridSearchRequest) bool {
+		return r.TopK == 6
+	})).Return([]vectordb.SearchResult{
+		{ID: "a", Score: 0.9},
+		{ID: "a-copy", Score: 0.8},
+		{ID: "b", Score: 0.7},
+	}, nil)
+	mc.On("GetDocument", mock.Anything, "col", "a").Return(&vectordb.Document{ID: "a", Vector: []float64{0, 1}}, nil)
+	mc.On("GetDocument", mock.Anything, "col", "a-copy").Return(&vectordb.Document{ID: "a-copy", Vector: []float64{0, 1}}, nil)
+	mc.On("GetDocument", mock.Anything, "col", "b").Return(&vectordb.Document{ID: "b", Vector: []float64{1, 0}}, nil)
+
+	a := &Activity{conn: newTestConn(mc), settings: &Settings{}}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col",
+		"queryText":      "flogo",
+		"topK":           2,
+		"alpha":          0.0,
+		"mmr":            true,
+		"mmrLambda":      0.5,
+		"mmrFetchK":      6,
+	}}
+	ok, err := a.Eval(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	results := ctx.outputs["results"].([]interface{})
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "a", results[0].(map[string]interface{})["id"])
+		assert.Equal(t, "b", results[1].(map[string]interface{})["id"])
+	}
+	mc.AssertExpectations(t)
+}
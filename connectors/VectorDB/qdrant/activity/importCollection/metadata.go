@@ -0,0 +1,107 @@
+package importCollection
+
+import (
+	"fmt"
+
+	"github.com/project-flogo/core/support/connection"
+)
+
+type Settings struct {
+	Connection        connection.Manager `md:"connection,required"`
+	DefaultCollection string             `md:"defaultCollection"`
+	// BatchSize is the number of documents per upsert. Default: 256.
+	BatchSize int `md:"batchSize"`
+	// Format is "auto" (from the file extension), "jsonl" or "parquet".
+	Format string `md:"format"`
+	// CreateCollection creates a missing target collection with the
+	// dimensions of the export and DistanceMetric.
+	CreateCollection bool   `md:"createCollection"`
+	DistanceMetric   string `md:"distanceMetric"`
+	// Verify compares the target's CountDocuments with the number of
+	// documents in the file after the import.
+	Verify         bool `md:"verify"`
+	TimeoutSeconds int  `md:"timeoutSeconds"`
+}
+
+type Input struct {
+	CollectionName string `md:"collectionName"`
+	FilePath       string `md:"filePath"`
+	// Restart ignores the checkpoint of an interrupted import and starts
+	// from the first document.
+	Restart bool `md:"restart"`
+}
+
+func (i *Input) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"collectionName": i.CollectionName,
+		"filePath":       i.FilePath,
+		"restart":        i.Restart,
+	}
+}
+
+func (i *Input) FromMap(v map[string]interface{}) error {
+	if val, ok := v["collectionName"]; ok && val != nil {
+		i.CollectionName = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["filePath"]; ok && val != nil {
+		i.FilePath = fmt.Sprintf("%v", val)
+	}
+	if val, ok := v["restart"]; ok {
+		i.Restart, _ = val.(bool)
+	}
+	return nil
+}
+
+type Output struct {
+	Success bool `md:"success"`
+	// ImportedCount is the number of documents written by this run;
+	// DocumentCount also includes those written before a resume.
+	ImportedCount int64 `md:"importedCount"`
+	DocumentCount int64 `md:"documentCount"`
+	Dimensions    int   `md:"dimensions"`
+	Resumed       bool  `md:"resumed"`
+	Created       bool  `md:"created"`
+	// Verified is true when the target holds at least ExpectedCount
+	// documents after the import.
+	Verified          bool   `md:"verified"`
+	ExpectedCount     int64  `md:"expectedCount"`
+	TargetCount       int64  `md:"targetCount"`
+	VerificationError string `md:"verificationError"`
+	Duration          string `md:"duration"`
+	Error             string `md:"error"`
+}
+
+func (o *Output) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"success":           o.Success,
+		"importedCount":     o.ImportedCount,
+		"documentCount":     o.DocumentCount,
+		"dimensions":        o.Dimensions,
+		"resumed":           o.Resumed,
+		"created":           o.Created,
+		"verified":          o.Verified,
+		"expectedCount":     o.ExpectedCount,
+		"targetCount":       o.TargetCount,
+		"verificationError": o.VerificationError,
+		"duration":          o.Duration,
+		"error":             o.Error,
+	}
+}
+
+func (o *Output) FromMap(v map[string]interface{}) error {
+	if val, ok := v["success"]; ok {
+		o.Success, _ = val.(bool)
+	}
+	if val, ok := v["documentCount"]; ok {
+		switch n := val.(type) {
+		case int64:
+			o.DocumentCount = n
+		case float64:
+			o.DocumentCount = int64(n)
+		}
+	}
+	if val, ok := v["verified"]; ok {
+		o.Verified, _ = val.(bool)
+	}
+	return nil
+}
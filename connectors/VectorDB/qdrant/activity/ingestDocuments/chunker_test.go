@@ -278,9 +278,30 @@ func TestExpandChunks_ProvenanceMetadataAttached(t *testing.T) {
 		assert.Equal(t, 2, chunk.Metadata["_chunk_total"])
 		assert.Equal(t, "heading", chunk.Metadata["_chunk_strategy"])
 		assert.Equal(t, "IPS", chunk.Metadata["team"], "parent metadata must be inherited")
+		assert.Equal(t, "page-42", chunk.Metadata["parentId"])
+		assert.Equal(t, i, chunk.Metadata["chunkIndex"])
+		assert.Equal(t, 2, chunk.Metadata["chunkCount"])
 	}
 }
 
+func TestExpandChunks_ParentIDWithoutDocumentID(t *testing.T) {
+	// Without an ID the chunks of one document still share a parentId: the
+	// incremental-mode source when stamped, otherwise a generated UUID.
+	docs := []RawDocument{
+		{Text: "para one\n\npara two", Metadata: map[string]interface{}{"_ingest_source": "faq.md"}},
+		{Text: "para three\n\npara four", Metadata: map[string]interface{}{}},
+	}
+	result := expandChunks(docs, ChunkConfig{Strategy: ChunkStrategyParagraph})
+	require.Len(t, result, 4)
+	assert.Equal(t, "faq.md", result[0].Metadata["parentId"])
+	assert.Equal(t, "faq.md", result[1].Metadata["parentId"])
+
+	generated, _ := result[2].Metadata["parentId"].(string)
+	assert.NotEmpty(t, generated)
+	assert.Equal(t, generated, result[3].Metadata["parentId"])
+	assert.Equal(t, 1, result[3].Metadata["chunkIndex"])
+}
+
 func TestExpandChunks_MetadataIsolation(t *testing.T) {
 	// Modifying one chunk's metadata must not affect another chunk.
 	docs := []RawDocument{
@@ -306,6 +327,58 @@ func TestExpandChunks_MultipleSourceDocuments(t *testing.T) {
 	assert.Equal(t, "d2-chunk-0", result[2].ID)
 }
 
+func TestExpandChunks_SectionsChunkedSeparatelyWithLocationMetadata(t *testing.T) {
+	docs := []RawDocument{{
+		ID:       "guide",
+		Text:     "ignored when sections are present",
+		Metadata: map[string]interface{}{"source": "guide.pdf"},
+		Sections: []DocumentSection{
+			{Text: "# Install\nIntro.\n## Linux\napt install", Metadata: map[string]interface{}{"page": 1}},
+			{Text: "continued on page two\n## Windows\nrun setup.exe", Metadata: map[string]interface{}{"page": 2}},
+		},
+	}}
+	result := expandChunks(docs, ChunkConfig{Strategy: ChunkStrategyHeading})
+	require.Len(t, result, 4)
+
+	// A chunk never spans two pages; the Linux section continues on page 2
+	// under the same section path.
+	want := []struct {
+		text string
+		page int
+		path string
+	}{
+		{"# Install\nIntro.", 1, "Install"},
+		{"## Linux\napt install", 1, "Install > Linux"},
+		{"continued on page two", 2, "Install > Linux"},
+		{"## Windows\nrun setup.exe", 2, "Install > Windows"},
+	}
+	for i, w := range want {
+		assert.Equal(t, w.text, result[i].Text, "chunk %d", i)
+		assert.Equal(t, w.page, result[i].Metadata["page"], "chunk %d", i)
+		assert.Equal(t, w.path, result[i].Metadata["section_path"], "chunk %d", i)
+		assert.Equal(t, "guide.pdf", result[i].Metadata["source"], "chunk %d", i)
+		assert.Equal(t, i, result[i].Metadata["_chunk_index"], "chunk %d", i)
+		assert.Equal(t, 4, result[i].Metadata["_chunk_total"], "chunk %d", i)
+	}
+}
+
+func TestExpandChunks_SectionPathOnlyWhenHeadingsPresent(t *testing.T) {
+	result := expandChunks([]RawDocument{
+		{ID: "d1", Text: "para one\n\npara two", Metadata: map[string]interface{}{}},
+	}, ChunkConfig{Strategy: ChunkStrategyParagraph})
+	require.Len(t, result, 2)
+	assert.NotContains(t, result[0].Metadata, "section_path")
+}
+
+func TestHeadingTrail_Advance(t *testing.T) {
+	var trail headingTrail
+	assert.Equal(t, "", trail.advance("preamble"))
+	assert.Equal(t, "A", trail.advance("# A\nbody\n## B"), "leading heading labels the chunk")
+	assert.Equal(t, "A > B", trail.advance("more of B\n### C\ntext"))
+	assert.Equal(t, "A > B > C", trail.advance("tail of C"))
+	assert.Equal(t, "D", trail.advance("# D ##\ntext"), "a higher-level heading closes deeper ones; closing hashes are dropped")
+}
+
 // ── Eval integration: chunking enabled ───────────────────────────────────────
 
 func TestIngestDocuments_ChunkingParagraph(t *testing.T) {
@@ -0,0 +1,303 @@
+package ingestDocuments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant"
+	vdbembed "github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant/embeddings"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant/testutil/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEmbedServer is batchEmbedServer that also counts embedded texts.
+func countingEmbedServer(dims int, texts *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		atomic.AddInt64(texts, int64(len(req.Input)))
+		data := make([]map[string]interface{}, len(req.Input))
+		for i := range req.Input {
+			emb := make([]float64, dims)
+			for j := range emb {
+				emb[j] = float64(i+1) * 0.1
+			}
+			data[i] = map[string]interface{}{"embedding": emb, "index": i}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":  data,
+			"usage": map[string]interface{}{"total_tokens": len(req.Input) * 5},
+		})
+	}))
+}
+
+func sourcedDocs(src string, texts ...string) []RawDocument {
+	docs := make([]RawDocument, len(texts))
+	for i, t := range texts {
+		docs[i] = RawDocument{Text: t, Metadata: map[string]interface{}{"source": src}}
+	}
+	return docs
+}
+
+func emptyStore(mc *mockclient.VectorDBClient) {
+	mc.On("ScrollDocuments", mock.Anything, mock.Anything).Return(&vectordb.ScrollResult{}, nil)
+}
+
+func TestStampSources(t *testing.T) {
+	docs := []RawDocument{
+		{ID: "doc-1", Text: "a", Metadata: map[string]interface{}{}},
+		{ID: "doc-2", Text: "b", Metadata: map[string]interface{}{"source": "guide.pdf"}},
+	}
+	original := docs[1].Metadata
+	require.NoError(t, stampSources(docs))
+	assert.Equal(t, "doc-1", docs[0].Metadata[ingestSourceKey])
+	assert.Equal(t, "guide.pdf", docs[1].Metadata[ingestSourceKey])
+	assert.NotContains(t, original, ingestSourceKey, "caller metadata must not be modified")
+
+	err := stampSources([]RawDocument{{Text: "no source"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "document[0]")
+}
+
+func TestPlanIncremental_DeterministicIDsAndDedup(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	emptyStore(mc)
+
+	docs := sourcedDocs("a.txt", "alpha", "beta", "alpha")
+	require.NoError(t, stampSources(docs))
+	plan, err := planIncremental(context.Background(), mc, "col", docs)
+	require.NoError(t, err)
+	assert.Len(t, plan.IDs, 2)
+	assert.Equal(t, 1, plan.Duplicates)
+	assert.Len(t, plan.Changed, 2)
+	assert.Equal(t, 0, plan.Skipped)
+
+	again, err := planIncremental(context.Background(), mc, "col", docs)
+	require.NoError(t, err)
+	assert.Equal(t, plan.IDs, again.IDs, "IDs must be stable across runs")
+
+	other := sourcedDocs("b.txt", "alpha")
+	require.NoError(t, stampSources(other))
+	otherPlan, err := planIncremental(context.Background(), mc, "col", other)
+	require.NoError(t, err)
+	assert.NotEqual(t, plan.IDs[0], otherPlan.IDs[0], "same text in another source gets another ID")
+
+	ch := plan.Changed[0].Metadata
+	assert.Equal(t, sha256Hex([]byte("alpha")), ch[chunkHashKey])
+	assert.NotEmpty(t, ch[payloadHashKey])
+}
+
+func TestPlanIncremental_SkipsUnchangedAndFindsStale(t *testing.T) {
+	docs := sourcedDocs("a.txt", "kept", "edited")
+	require.NoError(t, stampSources(docs))
+
+	first := &mockclient.VectorDBClient{}
+	emptyStore(first)
+	seed, err := planIncremental(context.Background(), first, "col", docs)
+	require.NoError(t, err)
+	kept := seed.Changed[0]
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("ScrollDocuments", mock.Anything, vectordb.ScrollRequest{
+		CollectionName: "col",
+		Limit:          incrementalScrollPage,
+		Filters:        map[string]interface{}{ingestSourceKey: "a.txt"},
+	}).Return(&vectordb.ScrollResult{
+		Documents: []vectordb.Document{{ID: kept.ID, Payload: kept.Metadata}},
+		// A second page proves the cursor is followed.
+		NextOffset: "page-2",
+	}, nil).Once()
+	mc.On("ScrollDocuments", mock.Anything, vectordb.ScrollRequest{
+		CollectionName: "col",
+		Limit:          incrementalScrollPage,
+		Offset:         "page-2",
+		Filters:        map[string]interface{}{ingestSourceKey: "a.txt"},
+	}).Return(&vectordb.ScrollResult{
+		Documents: []vectordb.Document{{ID: "old-id", Payload: map[string]interface{}{
+			ingestSourceKey: "a.txt", chunkHashKey: "oldhash", payloadHashKey: "x",
+		}}},
+	}, nil).Once()
+
+	plan, err := planIncremental(context.Background(), mc, "col", docs)
+	require.NoError(t, err)
+	assert.Equal(t, 1, plan.Skipped)
+	require.Len(t, plan.Changed, 1)
+	assert.Equal(t, "edited", plan.Changed[0].Text)
+	assert.Equal(t, []string{"oldhash"}, plan.stale["a.txt"])
+
+	mc.On("DeleteByFilter", mock.Anything, "col", map[string]interface{}{
+		ingestSourceKey: "a.txt", chunkHashKey: "oldhash",
+	}).Return(int64(1), nil).Once()
+	deleted, err := plan.deleteStale(context.Background(), mc, "col")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	mc.AssertExpectations(t)
+}
+
+func TestStoredChunks_FollowsEmptyPages(t *testing.T) {
+	scroll := func(offset string) vectordb.ScrollRequest {
+		return vectordb.ScrollRequest{
+			CollectionName: "col",
+			Limit:          incrementalScrollPage,
+			Offset:         offset,
+			Filters:        map[string]interface{}{ingestSourceKey: "a.txt"},
+		}
+	}
+	mc := &mockclient.VectorDBClient{}
+	// Providers that match part of the filter client-side can return an
+	// empty page before the end of the scroll.
+	mc.On("ScrollDocuments", mock.Anything, scroll("")).Return(&vectordb.ScrollResult{NextOffset: "page-2"}, nil).Once()
+	mc.On("ScrollDocuments", mock.Anything, scroll("page-2")).Return(&vectordb.ScrollResult{
+		Documents:  []vectordb.Document{{ID: "c1", Payload: map[string]interface{}{chunkHashKey: "h1"}}},
+		NextOffset: "page-3",
+	}, nil).Once()
+	// A provider that hands back an offset it already returned ends the scroll.
+	mc.On("ScrollDocuments", mock.Anything, scroll("page-3")).Return(&vectordb.ScrollResult{NextOffset: "page-2"}, nil).Once()
+
+	stored, err := storedChunks(context.Background(), mc, "col", "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]storedHashes{"c1": {chunkHash: "h1"}}, stored)
+	mc.AssertExpectations(t)
+}
+
+func TestIngestDocuments_Incremental(t *testing.T) {
+	var embedded int64
+	srv := countingEmbedServer(4, &embedded)
+	defer srv.Close()
+
+	settings := &Settings{
+		EmbeddingProvider: "OpenAI",
+		EmbeddingBaseURL:  srv.URL + "/v1",
+		EmbeddingModel:    "text-embedding-3-small",
+		ContentField:      "text",
+		IngestMode:        ingestModeIncremental,
+	}
+	inputs := map[string]interface{}{
+		"collectionName": "inc-col",
+		"documents": []interface{}{
+			map[string]interface{}{"id": "guide", "text": "unchanged paragraph"},
+			map[string]interface{}{"id": "guide-2", "text": "new text", "metadata": map[string]interface{}{"source": "guide"}},
+		},
+	}
+
+	// Seed: what a previous run stored for the unchanged paragraph.
+	prev := []RawDocument{{ID: "guide", Text: "unchanged paragraph", Metadata: map[string]interface{}{}}}
+	require.NoError(t, stampSources(prev))
+	seedClient := &mockclient.VectorDBClient{}
+	emptyStore(seedClient)
+	seed, err := planIncremental(context.Background(), seedClient, "inc-col", prev)
+	require.NoError(t, err)
+	stored := seed.Changed[0]
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("ScrollDocuments", mock.Anything, mock.Anything).Return(&vectordb.ScrollResult{
+		Documents: []vectordb.Document{
+			{ID: stored.ID, Payload: stored.Metadata},
+			{ID: "gone", Payload: map[string]interface{}{ingestSourceKey: "guide", chunkHashKey: "stalehash"}},
+		},
+	}, nil)
+	var upserted []vectordb.Document
+	mc.On("UpsertDocuments", mock.Anything, "inc-col", mock.Anything).
+		Run(func(args mock.Arguments) { upserted = args.Get(2).([]vectordb.Document) }).
+		Return(nil).Once()
+	mc.On("DeleteByFilter", mock.Anything, "inc-col", map[string]interface{}{
+		ingestSourceKey: "guide", chunkHashKey: "stalehash",
+	}).Return(int64(1), nil).Once()
+
+	act := &Activity{conn: newTestConn(mc), settings: settings}
+	ctx := &fakeActivityContext{inputs: inputs}
+	done, err := act.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	out := getOutput(ctx.outputs)
+	require.True(t, out.Success, out.Error)
+	assert.Equal(t, 1, out.IngestedCount)
+	assert.Equal(t, 2, out.ChunksCreated)
+	assert.Len(t, out.IDs, 2)
+	assert.Equal(t, stored.ID, out.IDs[0])
+	assert.Equal(t, 1, ctx.outputs["skippedCount"])
+	assert.Equal(t, 1, ctx.outputs["deletedCount"])
+	assert.Equal(t, int64(1), embedded, "only the new chunk is embedded")
+
+	require.Len(t, upserted, 1)
+	assert.Equal(t, "new text", upserted[0].Content)
+	assert.Equal(t, "guide", upserted[0].Payload[ingestSourceKey])
+	assert.Equal(t, "new text", upserted[0].Payload["text"])
+	mc.AssertExpectations(t)
+}
+
+func TestIngestDocuments_IncrementalScrollFails(t *testing.T) {
+	srv := batchEmbedServer(4)
+	defer srv.Close()
+	mc := &mockclient.VectorDBClient{}
+	mc.On("ScrollDocuments", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+	act := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  srv.URL + "/v1",
+			ContentField:      "text",
+			IngestMode:        ingestModeIncremental,
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "inc-col",
+		"documents":      []interface{}{map[string]interface{}{"id": "d", "text": "x"}},
+	}}
+	done, err := act.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, done)
+	out := getOutput(ctx.outputs)
+	assert.False(t, out.Success)
+	assert.Contains(t, out.Error, "incremental ingest failed")
+	mc.AssertNotCalled(t, "UpsertDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIngestDocuments_EmbeddingCache(t *testing.T) {
+	var embedded int64
+	srv := countingEmbedServer(4, &embedded)
+	defer srv.Close()
+	mc := &mockclient.VectorDBClient{}
+	mc.On("UpsertDocuments", mock.Anything, "cache-col", mock.Anything).Return(nil)
+	act := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  srv.URL + "/v1",
+			EmbeddingModel:    "text-embedding-3-small",
+			ContentField:      "text",
+		},
+		cache: vdbembed.NewMemoryCache(10),
+	}
+	inputs := map[string]interface{}{
+		"collectionName": "cache-col",
+		"documents": []interface{}{
+			map[string]interface{}{"text": "one"},
+			map[string]interface{}{"text": "two"},
+			map[string]interface{}{"text": "one"},
+		},
+	}
+
+	first := &fakeActivityContext{inputs: inputs}
+	_, err := act.Eval(first)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), embedded, "duplicate texts are embedded once")
+	assert.Equal(t, 0, first.outputs["cacheHits"])
+
+	second := &fakeActivityContext{inputs: inputs}
+	_, err = act.Eval(second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), embedded, "second run is served from cache")
+	assert.Equal(t, 3, second.outputs["cacheHits"])
+	assert.Equal(t, 3, getOutput(second.outputs).IngestedCount)
+}
@@ -522,3 +522,43 @@ func TestSettingsString_NoAPIKey(t *testing.T) {
 	assert.NotContains(t, out, "[redacted]")
 	assert.Contains(t, out, "ollama")
 }
+
+func TestRAGQuery_MMR(t *testing.T) {
+	embedServer := makeEmbedServer([]float64{1, 0})
+	defer embedServer.Close()
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, mock.MatchedBy(func(r vectordb.SearchRequest) bool {
+		return r.TopK == 20 && r.WithVectors
+	})).Return([]vectordb.SearchResult{
+		{ID: "a", Score: 0.99, Vector: []float64{1, 0.05}, Payload: map[string]interface{}{"text": "Flogo apps are event-driven."}},
+		{ID: "a-copy", Score: 0.98, Vector: []float64{1, 0.06}, Payload: map[string]interface{}{"text": "Flogo apps are event driven."}},
+		{ID: "b", Score: 0.80, Vector: []float64{0.8, -0.6}, Payload: map[string]interface{}{"text": "Flows are built in VS Code."}},
+	}, nil)
+
+	a := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  embedServer.URL,
+			EmbeddingModel:    "text-embedding-3-small",
+			DefaultCollection: "docs",
+			DefaultTopK:       2,
+			ContentField:      "text",
+			ContextFormat:     "plain",
+			TimeoutSeconds:    10,
+			EnableMMR:         true,
+			MMRLambda:         0.5,
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "what is Flogo?"}}
+
+	ok, err := a.Eval(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ctx.outputs["totalFound"])
+	formatted, _ := ctx.outputs["formattedContext"].(string)
+	assert.Contains(t, formatted, "Flows are built in VS Code.")
+	assert.NotContains(t, formatted, "event driven.")
+	mc.AssertExpectations(t)
+}
@@ -0,0 +1,261 @@
+package ragQuery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-qdrant/testutil/mock"
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedLLMServer is an OpenAI-compatible chat server that answers by the
+// first system prompt prefix that matches, and records the user prompts.
+func scriptedLLMServer(t *testing.T, replies map[string]string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var prompts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		system, user := "", ""
+		for _, m := range req.Messages {
+			if m.Role == "system" {
+				system = m.Content
+			} else {
+				user = m.Content
+			}
+		}
+		prompts = append(prompts, user)
+		for prefix, reply := range replies {
+			if strings.HasPrefix(system, prefix) {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"role": "assistant", "content": reply}}},
+				})
+				return
+			}
+		}
+		http.Error(w, "unexpected prompt", http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &prompts
+}
+
+// multiEmbedServer returns one embedding per input text: [len(text), i].
+func multiEmbedServer(t *testing.T, texts *[]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		*texts = append(*texts, req.Input...)
+		data := make([]openAIEmbedData, len(req.Input))
+		for i, in := range req.Input {
+			data[i] = openAIEmbedData{Embedding: []float64{float64(len(in)), float64(i)}, Index: i}
+		}
+		_ = json.NewEncoder(w).Encode(openAIEmbedResponse{Data: data, Usage: openAIEmbedUsage{TotalTokens: 3}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFuseRRF(t *testing.T) {
+	lists := [][]vectordb.SearchResult{
+		{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.8}, {ID: "c", Score: 0.7}},
+		{{ID: "b", Score: 0.95}, {ID: "d", Score: 0.6}},
+		{{ID: "b", Score: 0.5}, {ID: "a", Score: 0.4}},
+	}
+	fused := fuseRRF(lists, 3)
+	require.Len(t, fused, 3)
+	assert.Equal(t, "b", fused[0].ID, "found by every query")
+	assert.Equal(t, 0.95, fused[0].Score, "keeps the best similarity score")
+	assert.Equal(t, "a", fused[1].ID)
+	assert.Equal(t, "d", fused[2].ID, "rank 2 in one list beats rank 3")
+
+	assert.Len(t, fuseRRF(lists, 0), 4, "topK 0 keeps everything")
+	assert.Empty(t, fuseRRF(nil, 5))
+}
+
+func TestChatMessage(t *testing.T) {
+	role, content := chatMessage(map[string]interface{}{"role": "assistant", "content": " v1 supports X. "})
+	assert.Equal(t, "assistant", role)
+	assert.Equal(t, "v1 supports X.", content)
+
+	role, content = chatMessage(map[string]interface{}{"text": "hi"})
+	assert.Equal(t, "user", role)
+	assert.Equal(t, "hi", content)
+
+	role, content = chatMessage("plain question")
+	assert.Equal(t, "user", role)
+	assert.Equal(t, "plain question", content)
+
+	_, content = chatMessage(42)
+	assert.Empty(t, content)
+}
+
+func TestQueryVariants_ParsesAndDedupes(t *testing.T) {
+	srv, _ := scriptedLLMServer(t, map[string]string{
+		"Generate": "1. How do I install Flogo?\n- flogo setup guide\n\n\"What is Flogo\"\n* How do I install flogo?\nextra line",
+	})
+	a := &Activity{settings: &Settings{LLMProvider: "OpenAI", LLMBaseURL: srv.URL, QueryVariants: 3}}
+
+	variants, err := a.queryVariants(context.Background(), "What is Flogo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"How do I install Flogo?", "flogo setup guide", "extra line"}, variants)
+}
+
+func TestPlanQueries_FallsBackOnLLMError(t *testing.T) {
+	srv, _ := scriptedLLMServer(t, nil) // every call fails
+	a := &Activity{settings: &Settings{
+		LLMProvider:        "OpenAI",
+		LLMBaseURL:         srv.URL,
+		RetrievalMode:      retrievalHyDE,
+		EnableQueryRewrite: true,
+	}}
+	plan := a.planQueries(context.Background(), log.RootLogger(), &Input{
+		QueryText:   "and v2?",
+		ChatHistory: []interface{}{"what is new in v1?"},
+	})
+	assert.Equal(t, "and v2?", plan.Query)
+	assert.Empty(t, plan.Rewritten)
+	assert.Empty(t, plan.Hypothetical)
+	assert.Equal(t, []string{"and v2?"}, plan.EmbedTexts)
+}
+
+func TestRAGQuery_MultiQueryFusesResults(t *testing.T) {
+	var embedded []string
+	embedSrv := multiEmbedServer(t, &embedded)
+	llmSrv, _ := scriptedLLMServer(t, map[string]string{"Generate": "flogo install steps\nset up flogo"})
+
+	mc := &mockclient.VectorDBClient{}
+	// multiEmbedServer puts the batch position in the second dimension.
+	byQuery := func(i float64) interface{} {
+		return mock.MatchedBy(func(r vectordb.SearchRequest) bool { return r.QueryVector[1] == i })
+	}
+	mc.On("VectorSearch", mock.Anything, byQuery(0)).Return([]vectordb.SearchResult{{ID: "original", Score: 0.9}}, nil).Once()
+	mc.On("VectorSearch", mock.Anything, byQuery(1)).Return([]vectordb.SearchResult{{ID: "shared", Score: 0.7}, {ID: "only-variant-1", Score: 0.6}}, nil).Once()
+	mc.On("VectorSearch", mock.Anything, byQuery(2)).Return([]vectordb.SearchResult{{ID: "shared", Score: 0.8}, {ID: "only-variant-2", Score: 0.5}}, nil).Once()
+
+	a := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  embedSrv.URL,
+			DefaultCollection: "docs",
+			DefaultTopK:       3,
+			ContentField:      "text",
+			TimeoutSeconds:    10,
+			LLMProvider:       "OpenAI",
+			LLMBaseURL:        llmSrv.URL,
+			RetrievalMode:     retrievalMultiQuery,
+			QueryVariants:     2,
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "how to install flogo"}}
+
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, []string{"how to install flogo", "flogo install steps", "set up flogo"}, embedded, "one embedding call for all queries")
+	assert.Equal(t, []interface{}{"how to install flogo", "flogo install steps", "set up flogo"}, ctx.outputs["queryVariants"])
+	mc.AssertExpectations(t)
+
+	docs := ctx.outputs["sourceDocuments"].([]interface{})
+	require.Len(t, docs, 3)
+	assert.Equal(t, "shared", docs[0].(map[string]interface{})["id"])
+	assert.Equal(t, 3, ctx.outputs["totalFound"])
+}
+
+func TestRAGQuery_HyDEEmbedsHypotheticalDocument(t *testing.T) {
+	var embedded []string
+	embedSrv := multiEmbedServer(t, &embedded)
+	llmSrv, _ := scriptedLLMServer(t, map[string]string{"Write a short passage": "Flogo apps are installed with the flogo CLI."})
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, mock.Anything).Return([]vectordb.SearchResult{{ID: "doc1", Score: 0.9}}, nil).Once()
+
+	a := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  embedSrv.URL,
+			DefaultCollection: "docs",
+			DefaultTopK:       3,
+			ContentField:      "text",
+			TimeoutSeconds:    10,
+			LLMProvider:       "OpenAI",
+			LLMBaseURL:        llmSrv.URL,
+			RetrievalMode:     retrievalHyDE,
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "install?"}}
+
+	_, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, []string{"Flogo apps are installed with the flogo CLI."}, embedded)
+	assert.Equal(t, "Flogo apps are installed with the flogo CLI.", ctx.outputs["hypotheticalDocument"])
+	assert.Nil(t, ctx.outputs["queryVariants"])
+	mc.AssertExpectations(t)
+}
+
+func TestRAGQuery_RewritesFollowUpQuestion(t *testing.T) {
+	var embedded []string
+	embedSrv := multiEmbedServer(t, &embedded)
+	llmSrv, prompts := scriptedLLMServer(t, map[string]string{
+		"Rewrite":           "\"What changed in Flogo v2?\"",
+		"You are a helpful": "v2 adds streams.",
+	})
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("HybridSearch", mock.Anything, mock.MatchedBy(func(r vectordb.HybridSearchRequest) bool {
+		return r.QueryText == "What changed in Flogo v2?"
+	})).Return([]vectordb.SearchResult{{ID: "doc1", Score: 0.9, Payload: map[string]interface{}{"text": "v2 adds streams"}}}, nil).Once()
+
+	a := &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider:  "OpenAI",
+			EmbeddingBaseURL:   embedSrv.URL,
+			DefaultCollection:  "docs",
+			DefaultTopK:        3,
+			ContentField:       "text",
+			TimeoutSeconds:     10,
+			UseHybridSearch:    true,
+			HybridAlpha:        0.5,
+			LLMProvider:        "OpenAI",
+			LLMBaseURL:         llmSrv.URL,
+			EnableQueryRewrite: true,
+			EnableLLMGenerate:  true,
+			SystemPrompt:       "You are a helpful assistant.",
+		},
+	}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"queryText": "and what about v2?",
+		"chatHistory": []interface{}{
+			map[string]interface{}{"role": "user", "content": "What is new in Flogo v1.6?"},
+			map[string]interface{}{"role": "assistant", "content": "v1.6 adds OpenTelemetry."},
+		},
+	}}
+
+	_, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, "What changed in Flogo v2?", ctx.outputs["rewrittenQuery"])
+	assert.Equal(t, []string{"What changed in Flogo v2?"}, embedded)
+	assert.Equal(t, "v2 adds streams.", ctx.outputs["answer"])
+
+	require.Len(t, *prompts, 2)
+	assert.Contains(t, (*prompts)[0], "assistant: v1.6 adds OpenTelemetry.")
+	assert.Contains(t, (*prompts)[0], "Last question: and what about v2?")
+	assert.Contains(t, (*prompts)[1], "Question: What changed in Flogo v2?", "the answer is generated for the standalone question")
+	mc.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package ragQuery
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sse "github.com/mpandav-tibco/flogo-custom-extensions/sse/trigger"
+)
+
+// SSE event types written by a token stream.
+const (
+	streamEventToken = "token" // one generated text delta
+	streamEventDone  = "done"  // final answer, citations and sources
+	streamEventError = "error" // generation failed
+)
+
+// tokenStream forwards generated tokens to the clients of an SSE trigger
+// running in the same engine, addressed like the SSE Send activity: one
+// connection, a topic, or all connections.
+//
+// Sending is best effort: the first send error is kept and later events are
+// dropped, so a disconnected client never fails the RAG query.
+type tokenStream struct {
+	server       sse.SSEServerInterface
+	connectionID string
+	topic        string
+	tokens       int
+	err          error
+}
+
+// newTokenStream looks up the SSE server registered as serverRef ("default"
+// when empty). connectionID takes precedence over topic; with neither, events
+// are broadcast to every connection.
+func newTokenStream(serverRef, connectionID, topic string) (*tokenStream, error) {
+	if serverRef == "" {
+		serverRef = "default"
+	}
+	server, ok := sse.GetSSEServer(serverRef)
+	if !ok {
+		return nil, fmt.Errorf("SSE server %q not found (registered: %v); is the SSE trigger running in this app?",
+			serverRef, sse.ListRegisteredServers())
+	}
+	return &tokenStream{server: server, connectionID: connectionID, topic: topic}, nil
+}
+
+// token sends one text delta as a "token" event: {"index":n,"delta":"..."}.
+func (t *tokenStream) token(delta string) {
+	t.send(streamEventToken, map[string]interface{}{"index": t.tokens, "delta": delta})
+	t.tokens++
+}
+
+// send marshals data as JSON and dispatches it as one SSE event.
+func (t *tokenStream) send(eventType string, data interface{}) {
+	if t.err != nil {
+		return
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.err = err
+		return
+	}
+	event := &sse.SSEEventData{Event: eventType, Data: string(b)}
+	switch {
+	case t.connectionID != "":
+		t.err = t.server.SendEventToConnection(t.connectionID, event)
+	case t.topic != "":
+		t.err = t.server.BroadcastEventToTopic(t.topic, event)
+	default:
+		t.err = t.server.BroadcastEvent(event)
+	}
+}
@@ -0,0 +1,334 @@
+package vdbembed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+const (
+	// defaultLocalBatchSize is the number of texts encoded per model run.
+	defaultLocalBatchSize = 32
+	// defaultLocalSequenceLength is used when the model directory does not
+	// declare max_seq_length; it is also the upper bound.
+	defaultLocalSequenceLength = 512
+)
+
+// localRunner runs a sentence-transformer model over one batch of tokenized
+// texts. Inputs are row-major [batchSize × seqLen]; the result is the
+// last hidden state, row-major [batchSize × seqLen × hidden]. onnxRunner is
+// the production implementation.
+type localRunner interface {
+	run(ids, mask, types []int64) ([]float32, error)
+	close() error
+}
+
+// newLocalRunner creates one model session. Replaced in tests.
+var newLocalRunner = newONNXRunner
+
+// localModel is a loaded model directory. runners holds one session per
+// worker; a batch holds a session for the duration of one run.
+type localModel struct {
+	tok       *Tokenizer
+	runners   chan localRunner
+	workers   int
+	batchSize int
+	seqLen    int
+	hidden    int
+}
+
+// localModelKey identifies a loaded model; requests with the same model
+// directory and tuning share the sessions.
+type localModelKey struct {
+	dir                string
+	batchSize, workers int
+}
+
+var (
+	localModelsMu sync.Mutex
+	localModels   = map[localModelKey]*localModel{}
+)
+
+// localModelConfig holds the fields read from config.json and
+// sentence_bert_config.json.
+type localModelConfig struct {
+	HiddenSize            int `json:"hidden_size"`
+	Dim                   int `json:"dim"` // DistilBERT
+	TypeVocabSize         int `json:"type_vocab_size"`
+	MaxPositionEmbeddings int `json:"max_position_embeddings"`
+	MaxSeqLength          int `json:"max_seq_length"`
+}
+
+// getLocalModel returns the shared model for the directory, loading the
+// tokenizer and creating the sessions on first use. Load failures are not
+// cached, so a model copied into place later is picked up by the next call.
+func getLocalModel(dir string, batchSize, workers int) (*localModel, error) {
+	if batchSize <= 0 {
+		batchSize = defaultLocalBatchSize
+	}
+	if workers <= 0 {
+		workers = defaultLocalWorkers()
+	}
+	key := localModelKey{dir: dir, batchSize: batchSize, workers: workers}
+	localModelsMu.Lock()
+	defer localModelsMu.Unlock()
+	if m, ok := localModels[key]; ok {
+		return m, nil
+	}
+
+	modelPath, err := findLocalModelFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	var cfg localModelConfig
+	if err := readJSONFile(filepath.Join(dir, "config.json"), &cfg); err != nil {
+		return nil, err
+	}
+	// sentence_bert_config.json is optional; it carries max_seq_length.
+	if err := readJSONFile(filepath.Join(dir, "sentence_bert_config.json"), &cfg); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	hidden := cfg.HiddenSize
+	if hidden == 0 {
+		hidden = cfg.Dim
+	}
+	if hidden <= 0 {
+		return nil, fmt.Errorf("config.json in %s declares no hidden_size", dir)
+	}
+	seqLen := cfg.MaxSeqLength
+	if seqLen <= 0 {
+		seqLen = cfg.MaxPositionEmbeddings
+	}
+	if seqLen <= 0 || seqLen > defaultLocalSequenceLength {
+		seqLen = defaultLocalSequenceLength
+	}
+	tok, err := LoadTokenizer(filepath.Join(dir, "tokenizer.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &localModel{tok: tok, runners: make(chan localRunner, workers), workers: workers,
+		batchSize: batchSize, seqLen: seqLen, hidden: hidden}
+	for i := 0; i < workers; i++ {
+		r, err := newLocalRunner(modelPath, batchSize, seqLen, hidden, cfg.TypeVocabSize > 1)
+		if err != nil {
+			close(m.runners)
+			for created := range m.runners {
+				_ = created.close()
+			}
+			return nil, fmt.Errorf("load model %s: %w", modelPath, err)
+		}
+		m.runners <- r
+	}
+	localModels[key] = m
+	return m, nil
+}
+
+// defaultLocalWorkers returns half the CPUs, between 1 and 4. ONNX Runtime
+// already parallelises each run, so more sessions mostly add memory.
+func defaultLocalWorkers() int {
+	n := runtime.NumCPU() / 2
+	if n < 1 {
+		n = 1
+	}
+	if n > 4 {
+		n = 4
+	}
+	return n
+}
+
+// findLocalModelFile returns model.onnx in dir or in its onnx/ subdirectory
+// (the Hugging Face export layout).
+func findLocalModelFile(dir string) (string, error) {
+	for _, p := range []string{filepath.Join(dir, "model.onnx"), filepath.Join(dir, "onnx", "model.onnx")} {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no model.onnx in %s or %s", dir, filepath.Join(dir, "onnx"))
+}
+
+func readJSONFile(path string, v interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// embed returns the mean-pooled, L2-normalised embedding of every text and
+// the number of non-padding tokens encoded. Batches are spread over the
+// model's sessions.
+func (m *localModel) embed(ctx context.Context, texts []string) ([][]float64, int, error) {
+	out := make([][]float64, len(texts))
+	tokens := make([]int, (len(texts)+m.batchSize-1)/m.batchSize)
+
+	batches := make(chan int)
+	errs := make(chan error, m.workers)
+	var wg sync.WaitGroup
+	for w := 0; w < m.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range batches {
+				n, err := m.embedBatch(ctx, texts, start, out)
+				if err != nil {
+					errs <- err
+					return
+				}
+				tokens[start/m.batchSize] = n
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for start := 0; start < len(texts); start += m.batchSize {
+		select {
+		case batches <- start:
+		case err = <-errs:
+			break feed
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(batches)
+	wg.Wait()
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := 0
+	for _, n := range tokens {
+		total += n
+	}
+	return out, total, nil
+}
+
+// embedBatch encodes texts[start:start+batchSize] into out. The last batch
+// is padded with empty rows whose outputs are discarded.
+func (m *localModel) embedBatch(ctx context.Context, texts []string, start int, out [][]float64) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	end := start + m.batchSize
+	if end > len(texts) {
+		end = len(texts)
+	}
+	n := m.batchSize * m.seqLen
+	ids, mask, types := make([]int64, n), make([]int64, n), make([]int64, n)
+	for i := range ids {
+		ids[i] = int64(m.tok.padID)
+	}
+	tokens := 0
+	for row, text := range texts[start:end] {
+		rIDs, rMask := m.tok.EncodeFixed(text, m.seqLen)
+		off := row * m.seqLen
+		copy(ids[off:], rIDs)
+		copy(mask[off:], rMask)
+		for _, v := range rMask {
+			tokens += int(v)
+		}
+	}
+
+	var runner localRunner
+	select {
+	case runner = <-m.runners:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	hidden, err := runner.run(ids, mask, types)
+	m.runners <- runner
+	if err != nil {
+		return 0, fmt.Errorf("run model: %w", err)
+	}
+	if len(hidden) < (end-start)*m.seqLen*m.hidden {
+		return 0, fmt.Errorf("model returned %d values for %d texts of %d×%d", len(hidden), end-start, m.seqLen, m.hidden)
+	}
+	for row := 0; row < end-start; row++ {
+		out[start+row] = meanPool(hidden, mask, row, m.seqLen, m.hidden)
+	}
+	return tokens, nil
+}
+
+// meanPool averages the hidden states of the unmasked tokens of one row
+// and L2-normalises the result.
+func meanPool(hidden []float32, mask []int64, row, seqLen, dim int) []float64 {
+	vec := make([]float64, dim)
+	count := 0
+	for t := 0; t < seqLen; t++ {
+		if mask[row*seqLen+t] == 0 {
+			continue
+		}
+		count++
+		h := hidden[(row*seqLen+t)*dim : (row*seqLen+t+1)*dim]
+		for i, v := range h {
+			vec[i] += float64(v)
+		}
+	}
+	if count > 0 {
+		for i := range vec {
+			vec[i] /= float64(count)
+		}
+	}
+	return l2Normalize(vec)
+}
+
+func l2Normalize(vec []float64) []float64 {
+	var sum float64
+	for _, v := range vec {
+		sum += v * v
+	}
+	if sum == 0 {
+		return vec
+	}
+	norm := math.Sqrt(sum)
+	for i := range vec {
+		vec[i] /= norm
+	}
+	return vec
+}
+
+// callLocalEmbed embeds the texts with the model directory named by
+// req.Model. A positive req.Dimensions below the model size truncates each
+// vector and re-normalises it (Matryoshka-trained models).
+func callLocalEmbed(ctx context.Context, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	if req.Model == "" {
+		return nil, fmt.Errorf("embeddings: local model directory is required")
+	}
+	m, err := getLocalModel(req.Model, req.LocalBatchSize, req.LocalWorkers)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings: local %w", err)
+	}
+	embeddings, tokens, err := m.embed(ctx, req.Texts)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings: local %w", err)
+	}
+	dims := m.hidden
+	if req.Dimensions > 0 && req.Dimensions < dims {
+		dims = req.Dimensions
+		for i, vec := range embeddings {
+			embeddings[i] = l2Normalize(vec[:dims])
+		}
+	}
+	return &EmbeddingResponse{
+		Embeddings: embeddings,
+		Dimensions: dims,
+		TokensUsed: tokens,
+	}, nil
+}
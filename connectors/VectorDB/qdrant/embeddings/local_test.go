@@ -0,0 +1,224 @@
+package vdbembed
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLocalTokenizer is a minimal BERT-style tokenizer.json.
+const testLocalTokenizer = `{
+  "normalizer": {"type": "BertNormalizer", "lowercase": true},
+  "pre_tokenizer": {"type": "BertPreTokenizer"},
+  "post_processor": {"type": "TemplateProcessing",
+    "single": [{"SpecialToken": {"id": "[CLS]", "type_id": 0}}, {"Sequence": {"id": "A", "type_id": 0}}, {"SpecialToken": {"id": "[SEP]", "type_id": 0}}],
+    "special_tokens": {"[CLS]": {"ids": [2]}, "[SEP]": {"ids": [3]}}},
+  "model": {
+    "type": "WordPiece",
+    "unk_token": "[UNK]",
+    "vocab": {"[PAD]": 0, "[UNK]": 1, "[CLS]": 2, "[SEP]": 3, "cat": 4, "dog": 5, "##s": 6, "!": 7}
+  }
+}`
+
+// writeLocalModel creates a model directory with a placeholder model file.
+func writeLocalModel(t *testing.T, config string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "onnx"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "onnx", "model.onnx"), []byte("onnx"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tokenizer.json"), []byte(testLocalTokenizer), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600))
+	return dir
+}
+
+// fakeLocalRunner returns a hidden state of [id, 1, 0] for every token, so
+// the pooled vector reflects the token ids of the row.
+type fakeLocalRunner struct {
+	seqLen, hidden int
+	runs           *int32
+	err            error
+}
+
+func (f *fakeLocalRunner) run(ids, mask, types []int64) ([]float32, error) {
+	atomic.AddInt32(f.runs, 1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([]float32, len(ids)*f.hidden)
+	for i, id := range ids {
+		out[i*f.hidden] = float32(id)
+		out[i*f.hidden+1] = 1
+	}
+	return out, nil
+}
+
+func (f *fakeLocalRunner) close() error { return nil }
+
+func useFakeLocalRunner(t *testing.T, runErr error) *int32 {
+	t.Helper()
+	var runs int32
+	orig := newLocalRunner
+	newLocalRunner = func(_ string, _, seqLen, hidden int, _ bool) (localRunner, error) {
+		return &fakeLocalRunner{seqLen: seqLen, hidden: hidden, runs: &runs, err: runErr}, nil
+	}
+	t.Cleanup(func() { newLocalRunner = orig })
+	return &runs
+}
+
+func TestTokenizer_EncodeFixed(t *testing.T) {
+	dir := writeLocalModel(t, `{}`)
+	tok, err := LoadTokenizer(filepath.Join(dir, "tokenizer.json"))
+	require.NoError(t, err)
+
+	ids, mask := tok.EncodeFixed("Cats dog!", 8)
+	assert.Equal(t, []int64{2, 4, 6, 5, 7, 3, 0, 0}, ids)
+	assert.Equal(t, []int64{1, 1, 1, 1, 1, 1, 0, 0}, mask)
+
+	ids, _ = tok.EncodeFixed("cat cat cat cat", 4)
+	assert.Equal(t, []int64{2, 4, 4, 3}, ids, "truncation keeps the special tokens")
+}
+
+// testPairTokenizer is a minimal BERT cross-encoder tokenizer.json.
+const testPairTokenizer = `{
+  "normalizer": {"type": "BertNormalizer", "lowercase": true},
+  "pre_tokenizer": {"type": "BertPreTokenizer"},
+  "post_processor": {"type": "BertProcessing", "sep": ["[SEP]", 3], "cls": ["[CLS]", 2]},
+  "model": {
+    "type": "WordPiece",
+    "unk_token": "[UNK]",
+    "vocab": {"[PAD]": 0, "[UNK]": 1, "[CLS]": 2, "[SEP]": 3, "what": 4, "is": 5, "ai": 6, "cat": 7, "##s": 8, "?": 9, "dog": 10}
+  }
+}`
+
+// testUnigramTokenizer is a minimal XLM-RoBERTa-style tokenizer.json.
+const testUnigramTokenizer = `{
+  "added_tokens": [{"id": 0, "content": "<s>"}, {"id": 1, "content": "<pad>"}, {"id": 2, "content": "</s>"}],
+  "normalizer": {"type": "Sequence", "normalizers": [{"type": "Precompiled"}]},
+  "pre_tokenizer": {"type": "Metaspace", "replacement": "▁"},
+  "post_processor": {"type": "RobertaProcessing", "sep": ["</s>", 2], "cls": ["<s>", 0]},
+  "model": {
+    "type": "Unigram",
+    "unk_id": 3,
+    "vocab": [["<s>", 0], ["<pad>", 0], ["</s>", 0], ["<unk>", 0], ["▁hello", -1.0], ["▁he", -2.0], ["llo", -2.0], ["▁world", -1.5]]
+  }
+}`
+
+func writeTokenizer(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tokenizer.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestTokenizer_WordPiecePair(t *testing.T) {
+	tok, err := LoadTokenizer(writeTokenizer(t, testPairTokenizer))
+	require.NoError(t, err)
+	require.True(t, tok.HasPairTemplate())
+	assert.True(t, tok.UsesTypeIDs())
+
+	ids, mask, types := tok.EncodePair("What is AI?", "Cats", 10)
+	assert.Equal(t, []int64{2, 4, 5, 6, 9, 3, 7, 8, 3, 0}, ids)
+	assert.Equal(t, []int64{1, 1, 1, 1, 1, 1, 1, 1, 1, 0}, mask)
+	assert.Equal(t, []int64{0, 0, 0, 0, 0, 0, 1, 1, 1, 0}, types)
+	assert.Equal(t, []int{1}, tok.encode("zebra"), "unknown word maps to [UNK]")
+}
+
+func TestTokenizer_PairTruncatesDocumentFirst(t *testing.T) {
+	tok, err := LoadTokenizer(writeTokenizer(t, testPairTokenizer))
+	require.NoError(t, err)
+
+	ids, _, _ := tok.EncodePair("cat", "dog dog dog dog dog", 6)
+	assert.Equal(t, []int64{2, 7, 3, 10, 10, 3}, ids)
+}
+
+func TestTokenizer_UnigramPair(t *testing.T) {
+	tok, err := LoadTokenizer(writeTokenizer(t, testUnigramTokenizer))
+	require.NoError(t, err)
+	assert.False(t, tok.UsesTypeIDs())
+
+	assert.Equal(t, []int{4, 7}, tok.encode("hello world"))
+	ids, mask, types := tok.EncodePair("hello", "world", 8)
+	assert.Equal(t, []int64{0, 4, 2, 2, 7, 2, 1, 1}, ids)
+	assert.Equal(t, []int64{1, 1, 1, 1, 1, 1, 0, 0}, mask)
+	assert.Equal(t, []int64{0, 0, 0, 0, 0, 0, 0, 0}, types)
+}
+
+func TestTokenizer_SingleTemplateHasNoPair(t *testing.T) {
+	tok, err := LoadTokenizer(writeTokenizer(t, testLocalTokenizer))
+	require.NoError(t, err)
+	assert.False(t, tok.HasPairTemplate())
+}
+
+func TestTokenizer_RejectsUnsupportedModel(t *testing.T) {
+	_, err := LoadTokenizer(writeTokenizer(t, `{"model": {"type": "BPE"}}`))
+	assert.ErrorContains(t, err, "unsupported tokenizer model")
+}
+
+func TestCreateEmbeddings_Local(t *testing.T) {
+	runs := useFakeLocalRunner(t, nil)
+	dir := writeLocalModel(t, `{"hidden_size": 3, "max_position_embeddings": 8}`)
+
+	resp, err := CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Provider:       ProviderLocal,
+		Model:          dir,
+		Texts:          []string{"cat", "dog", "cats", "cat dog"},
+		LocalBatchSize: 3,
+		LocalWorkers:   2,
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(runs), "4 texts in batches of 3")
+	assert.Equal(t, 3, resp.Dimensions)
+	assert.Equal(t, 14, resp.TokensUsed)
+	require.Len(t, resp.Embeddings, 4)
+
+	// "cat" → tokens [CLS]=2, cat=4, [SEP]=3: mean [3, 1, 0], normalised.
+	n := math.Sqrt(10)
+	assert.InDeltaSlice(t, []float64{3 / n, 1 / n, 0}, resp.Embeddings[0], 1e-9)
+	for _, v := range resp.Embeddings {
+		var sum float64
+		for _, x := range v {
+			sum += x * x
+		}
+		assert.InDelta(t, 1, sum, 1e-9)
+	}
+}
+
+func TestCreateEmbeddings_LocalTruncatesDimensions(t *testing.T) {
+	useFakeLocalRunner(t, nil)
+	dir := writeLocalModel(t, `{"hidden_size": 3}`)
+
+	resp, err := CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Provider: ProviderLocal, Model: dir, Texts: []string{"cat"}, Dimensions: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Dimensions)
+	n := math.Sqrt(10)
+	assert.InDeltaSlice(t, []float64{3 / n, 1 / n}, resp.Embeddings[0], 1e-9)
+}
+
+func TestCreateEmbeddings_LocalErrors(t *testing.T) {
+	useFakeLocalRunner(t, errors.New("boom"))
+
+	_, err := CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Provider: ProviderLocal, Model: t.TempDir(), Texts: []string{"cat"},
+	})
+	assert.ErrorContains(t, err, "no model.onnx")
+
+	_, err = CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Provider: ProviderLocal, Model: writeLocalModel(t, `{}`), Texts: []string{"cat"},
+	})
+	assert.ErrorContains(t, err, "declares no hidden_size")
+
+	_, err = CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Provider: ProviderLocal, Model: writeLocalModel(t, `{"hidden_size": 3}`), Texts: []string{"cat", "dog"},
+		LocalBatchSize: 1, LocalWorkers: 2,
+	})
+	assert.ErrorContains(t, err, "boom")
+}
@@ -29,6 +29,8 @@ const (
 	ErrCodeInvalidTopK        = "VDB-SRH-4002"
 	ErrCodeInvalidAlpha       = "VDB-SRH-4003"
 	ErrCodeHybridNotSupported = "VDB-SRH-4004"
+	ErrCodeInvalidFusion      = "VDB-SRH-4005"
+	ErrCodeInvalidFilter      = "VDB-SRH-4006"
 
 	// Connection / provider errors
 	ErrCodeConnectionFailed  = "VDB-CON-5001"
@@ -66,6 +68,8 @@ var ErrorMessages = map[string]string{
 	ErrCodeInvalidTopK:           "TopK must be greater than 0",
 	ErrCodeInvalidAlpha:          "Alpha must be between 0.0 and 1.0",
 	ErrCodeHybridNotSupported:    "This provider does not support native hybrid search",
+	ErrCodeInvalidFusion:         "Fusion must be one of: rrf, dbsf",
+	ErrCodeInvalidFilter:         "Filter is not a valid filter expression",
 	ErrCodeConnectionFailed:      "Failed to establish connection to vector database",
 	ErrCodeConnectionTimeout:     "Connection to vector database timed out",
 	ErrCodeAuthFailed:            "Authentication failed — check API key / credentials",
@@ -33,6 +33,37 @@ func TestNormalizeDistanceMetric(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// normalizeFusion
+// ---------------------------------------------------------------------------
+
+func TestNormalizeFusion(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"", "rrf"},
+		{"rrf", "rrf"},
+		{"RRF", "rrf"},
+		{" dbsf ", "dbsf"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := normalizeFusion(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNormalizeFusion_Invalid(t *testing.T) {
+	_, err := normalizeFusion("linear")
+	require.Error(t, err)
+	var ve *VDBError
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, ErrCodeInvalidFusion, ve.Code)
+}
+
 // ---------------------------------------------------------------------------
 // validateCollectionConfig
 // ---------------------------------------------------------------------------
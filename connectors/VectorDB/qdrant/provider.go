@@ -3,8 +3,10 @@ package vectordb
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,10 +17,15 @@ import (
 type qdrantClient struct {
 	client *qdrant.Client
 	cfg    ConnectionConfig
+
+	// sparseCollections caches, per collection name, whether the collection
+	// declares the qdrantSparseVectorName sparse vector (bool values).
+	sparseCollections sync.Map
 }
 
 // Compile-time proof that qdrantClient satisfies the full VectorDBClient interface.
 var _ VectorDBClient = (*qdrantClient)(nil)
+var _ MetricReader = (*qdrantClient)(nil)
 
 func newQdrantClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	tlsCfg, err := buildTLSConfig(cfg)
@@ -80,6 +87,13 @@ func (c *qdrantClient) CreateCollection(ctx context.Context, cfg CollectionConfi
 			OnDisk:   &onDisk,
 		}),
 	}
+	if cfg.EnableSparse {
+		// The IDF modifier makes Qdrant weight sparse dot products by inverse
+		// document frequency, completing the BM25 score on the server side.
+		collCfg.SparseVectorsConfig = qdrant.NewSparseVectorsConfig(map[string]*qdrant.SparseVectorParams{
+			qdrantSparseVectorName: {Modifier: qdrant.Modifier_Idf.Enum()},
+		})
+	}
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
 		retryErr := c.client.CreateCollection(ctx, collCfg)
 		if retryErr != nil && strings.Contains(retryErr.Error(), "already exists") {
@@ -92,10 +106,12 @@ func (c *qdrantClient) CreateCollection(ctx context.Context, cfg CollectionConfi
 		}
 		return newError(ErrCodeProviderError, "CreateCollection failed", err)
 	}
+	c.sparseCollections.Store(cfg.Name, cfg.EnableSparse)
 	return nil
 }
 
 func (c *qdrantClient) DeleteCollection(ctx context.Context, name string) error {
+	c.sparseCollections.Delete(name)
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
 		return c.client.DeleteCollection(ctx, name)
 	}); err != nil {
@@ -104,6 +120,26 @@ func (c *qdrantClient) DeleteCollection(ctx context.Context, name string) error
 	return nil
 }
 
+// collectionHasSparse reports whether the collection declares the connector's
+// named sparse vector. The answer is cached per collection; collections that
+// are dropped through DeleteCollection are evicted from the cache.
+func (c *qdrantClient) collectionHasSparse(ctx context.Context, name string) (bool, error) {
+	if v, ok := c.sparseCollections.Load(name); ok {
+		return v.(bool), nil
+	}
+	var info *qdrant.CollectionInfo
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var retryErr error
+		info, retryErr = c.client.GetCollectionInfo(ctx, name)
+		return retryErr
+	}); err != nil {
+		return false, err
+	}
+	_, has := info.GetConfig().GetParams().GetSparseVectorsConfig().GetMap()[qdrantSparseVectorName]
+	c.sparseCollections.Store(name, has)
+	return has, nil
+}
+
 func (c *qdrantClient) ListCollections(ctx context.Context) ([]string, error) {
 	var resp []string
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
@@ -132,6 +168,34 @@ func (c *qdrantClient) CollectionExists(ctx context.Context, name string) (bool,
 	return exists, nil
 }
 
+// CollectionMetric returns the distance of the collection's unnamed dense
+// vector. Collections with only named vectors report "".
+func (c *qdrantClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	var info *qdrant.CollectionInfo
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var retryErr error
+		info, retryErr = c.client.GetCollectionInfo(ctx, name)
+		return retryErr
+	}); err != nil {
+		return "", newError(ErrCodeProviderError, "CollectionMetric failed", err)
+	}
+	params := info.GetConfig().GetParams().GetVectorsConfig().GetParams()
+	if params == nil {
+		return "", nil
+	}
+	switch params.GetDistance() {
+	case qdrant.Distance_Cosine:
+		return "cosine", nil
+	case qdrant.Distance_Dot:
+		return "dot", nil
+	case qdrant.Distance_Euclid:
+		return "euclidean", nil
+	case qdrant.Distance_Manhattan:
+		return "manhattan", nil
+	}
+	return "", nil
+}
+
 // --- Document operations ---
 
 func (c *qdrantClient) UpsertDocuments(ctx context.Context, collectionName string, docs []Document) error {
@@ -142,6 +206,11 @@ func (c *qdrantClient) UpsertDocuments(ctx context.Context, collectionName strin
 		return err
 	}
 
+	hasSparse, err := c.collectionHasSparse(ctx, collectionName)
+	if err != nil {
+		return newError(ErrCodeProviderError, "UpsertDocuments failed: could not read collection info", err)
+	}
+
 	points := make([]*qdrant.PointStruct, len(docs))
 	for i, doc := range docs {
 		payload := qdrantPayload(doc.Payload)
@@ -152,9 +221,17 @@ func (c *qdrantClient) UpsertDocuments(ctx context.Context, collectionName strin
 		// Qdrant only accepts UUID or uint64; arbitrary strings are converted to a
 		// deterministic UUID v5 by qdrantResolveID.
 		payload["_original_id"] = &qdrant.Value{Kind: &qdrant.Value_StringValue{StringValue: doc.ID}}
+		vectors := qdrant.NewVectorsDense(toFloat32Slice(doc.Vector))
+		if hasSparse {
+			named := map[string]*qdrant.Vector{"": qdrant.NewVectorDense(toFloat32Slice(doc.Vector))}
+			if indices, values := buildSparseVector(doc.Content); len(indices) > 0 {
+				named[qdrantSparseVectorName] = qdrant.NewVectorSparse(indices, values)
+			}
+			vectors = qdrant.NewVectorsMap(named)
+		}
 		points[i] = &qdrant.PointStruct{
 			Id:      qdrantResolveID(doc.ID),
-			Vectors: qdrant.NewVectorsDense(toFloat32Slice(doc.Vector)),
+			Vectors: vectors,
 			Payload: payload,
 		}
 	}
@@ -233,12 +310,29 @@ func (c *qdrantClient) DeleteByFilter(ctx context.Context, collectionName string
 		// Require at least one filter to prevent accidental data loss.
 		return 0, newError(ErrCodeProviderError, "DeleteByFilter requires at least one filter", nil)
 	}
+	filter, residual, err := qdrantFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	wait := true
 	delReq := &qdrant.DeletePoints{
 		CollectionName: collectionName,
 		Points: &qdrant.PointsSelector{
 			PointsSelectorOneOf: &qdrant.PointsSelector_Filter{
-				Filter: buildQdrantFilter(filters),
+				Filter: filter,
 			},
 		},
 		Wait: &wait,
@@ -253,6 +347,10 @@ func (c *qdrantClient) DeleteByFilter(ctx context.Context, collectionName string
 }
 
 func (c *qdrantClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (*ScrollResult, error) {
+	filter, residual, err := qdrantFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 	limit := uint32(req.Limit)
 	if limit == 0 {
 		limit = 100
@@ -261,7 +359,7 @@ func (c *qdrantClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 	withPayload := true
 	params := &qdrant.ScrollPoints{
 		CollectionName: req.CollectionName,
-		Filter:         buildQdrantFilter(req.Filters),
+		Filter:         filter,
 		Limit:          &limit,
 		WithPayload:    qdrant.NewWithPayload(withPayload),
 		WithVectors:    &qdrant.WithVectorsSelector{SelectorOptions: &qdrant.WithVectorsSelector_Enable{Enable: req.WithVectors}},
@@ -292,7 +390,7 @@ func (c *qdrantClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 				nextOffset = fmt.Sprintf("%d", num)
 			}
 		}
-		scrollResult = &ScrollResult{Documents: docs, NextOffset: nextOffset, Total: -1}
+		scrollResult = &ScrollResult{Documents: matchDocuments(docs, residual), NextOffset: nextOffset, Total: -1}
 		return nil
 	}); err != nil {
 		return nil, newError(ErrCodeProviderError, "ScrollDocuments failed", err)
@@ -301,10 +399,21 @@ func (c *qdrantClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (
 }
 
 func (c *qdrantClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
+	filter, residual, err := qdrantFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 	exact := true
 	countReq := &qdrant.CountPoints{
 		CollectionName: collectionName,
-		Filter:         buildQdrantFilter(filters),
+		Filter:         filter,
 		Exact:          &exact,
 	}
 	var count uint64
@@ -325,13 +434,19 @@ func (c *qdrantClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 		return nil, err
 	}
 
+	filter, residual, err := qdrantFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
 	tCtx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSeconds)*time.Second)
 	defer cancel()
 
-	limit := uint64(req.TopK)
+	limit := uint64(searchLimit(req.TopK, residual))
 	// SkipPayload=false (zero value) means include payload — the safe default.
 	// Set req.SkipPayload=true for ranking-only passes that don't need metadata.
-	withPayload := !req.SkipPayload
+	// The payload is always fetched when part of the filter is matched here.
+	withPayload := !req.SkipPayload || residual != nil
 
 	qparams := &qdrant.QueryPoints{
 		CollectionName: req.CollectionName,
@@ -339,7 +454,7 @@ func (c *qdrantClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 		Limit:          &limit,
 		WithPayload:    qdrant.NewWithPayload(withPayload),
 		WithVectors:    &qdrant.WithVectorsSelector{SelectorOptions: &qdrant.WithVectorsSelector_Enable{Enable: req.WithVectors}},
-		Filter:         buildQdrantFilter(req.Filters),
+		Filter:         filter,
 	}
 	// Only set ScoreThreshold when explicitly requested (> 0).
 	// Passing 0.0 to Qdrant is treated as a literal threshold and would filter
@@ -358,18 +473,19 @@ func (c *qdrantClient) VectorSearch(ctx context.Context, req SearchRequest) ([]S
 		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
 	}
 
-	return qdrantScoredPointsToResults(results), nil
+	return matchResults(qdrantScoredPointsToResults(results), residual, req.TopK, req.SkipPayload), nil
 }
 
+// HybridSearch runs native sparse+dense search when the collection was created
+// with CollectionConfig.EnableSparse: the dense and BM25 sparse queries are issued
+// as Query API prefetches and combined server-side with RRF (weighted by Alpha)
+// or DBSF. Collections without the sparse vector fall back to dense search.
 func (c *qdrantClient) HybridSearch(ctx context.Context, req HybridSearchRequest) ([]SearchResult, error) {
-	// Qdrant supports native hybrid search via Prefetch + Fusion, but that requires
-	// a sparse vector field configured on the collection at creation time.
-	// Until sparse indexing is supported by this connector, we fall back to dense
-	// vector search so callers get best-effort results rather than an error.
-	// TODO: Implement qdrant.QueryPoints with Prefetch for true sparse+dense hybrid.
-	logger.Warnf("Qdrant: HybridSearch falling back to dense vector search — sparse indexing is not configured " +
-		"on this connector; BM25/keyword component is absent. Set alpha=1.0 or use a provider with native hybrid support.")
-	return c.VectorSearch(ctx, SearchRequest{
+	fusion, err := normalizeFusion(req.Fusion)
+	if err != nil {
+		return nil, err
+	}
+	denseOnly := SearchRequest{
 		CollectionName: req.CollectionName,
 		QueryVector:    req.QueryVector,
 		TopK:           req.TopK,
@@ -377,7 +493,89 @@ func (c *qdrantClient) HybridSearch(ctx context.Context, req HybridSearchRequest
 		Filters:        req.Filters,
 		WithVectors:    false,
 		SkipPayload:    req.SkipPayload,
-	})
+	}
+	if req.CollectionName == "" {
+		return nil, newError(ErrCodeInvalidCollectionName, "", nil)
+	}
+	if req.TopK <= 0 {
+		return nil, newError(ErrCodeInvalidTopK, fmt.Sprintf("topK=%d must be > 0", req.TopK), nil)
+	}
+
+	sparseIdx, sparseVals := buildSparseQuery(req.QueryText)
+	if len(sparseIdx) == 0 || req.Alpha >= 1 {
+		return c.VectorSearch(ctx, denseOnly)
+	}
+
+	tCtx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	hasSparse, err := c.collectionHasSparse(tCtx, req.CollectionName)
+	if err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch failed: could not read collection info", err)
+	}
+	if !hasSparse {
+		logger.Warnf("Qdrant: HybridSearch falling back to dense vector search — collection %q has no sparse vector; "+
+			"recreate it with enableSparse=true to enable the BM25/keyword component.", req.CollectionName)
+		return c.VectorSearch(ctx, denseOnly)
+	}
+
+	filter, residual, err := qdrantFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	limit := uint64(searchLimit(req.TopK, residual))
+	sparseName := qdrantSparseVectorName
+	qparams := &qdrant.QueryPoints{
+		CollectionName: req.CollectionName,
+		Limit:          &limit,
+		WithPayload:    qdrant.NewWithPayload(!req.SkipPayload || residual != nil),
+		Filter:         filter,
+	}
+
+	if len(req.QueryVector) == 0 || req.Alpha <= 0 {
+		// Sparse/BM25 only.
+		qparams.Query = qdrant.NewQuerySparse(sparseIdx, sparseVals)
+		qparams.Using = &sparseName
+	} else {
+		// Each side over-fetches so fusion has enough overlap to re-rank.
+		prefetchLimit := limit * hybridPrefetchFactor
+		dense := &qdrant.PrefetchQuery{
+			Query:  qdrant.NewQueryDense(toFloat32Slice(req.QueryVector)),
+			Filter: filter,
+			Limit:  &prefetchLimit,
+		}
+		// The caller's threshold is a dense similarity score, so it is applied to
+		// the dense prefetch rather than to the fused (rank-based) score.
+		if req.ScoreThreshold > 0 {
+			st := float32(req.ScoreThreshold)
+			dense.ScoreThreshold = &st
+		}
+		sparse := &qdrant.PrefetchQuery{
+			Query:  qdrant.NewQuerySparse(sparseIdx, sparseVals),
+			Using:  &sparseName,
+			Filter: filter,
+			Limit:  &prefetchLimit,
+		}
+		qparams.Prefetch = []*qdrant.PrefetchQuery{dense, sparse}
+		switch fusion {
+		case "dbsf":
+			qparams.Query = qdrant.NewQueryFusion(qdrant.Fusion_DBSF)
+		default:
+			qparams.Query = qdrant.NewQueryRRF(&qdrant.Rrf{
+				Weights: []float32{float32(req.Alpha), float32(1 - req.Alpha)},
+			})
+		}
+	}
+
+	var results []*qdrant.ScoredPoint
+	if err := withRetry(tCtx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var qErr error
+		results, qErr = c.client.Query(tCtx, qparams)
+		return qErr
+	}); err != nil {
+		return nil, newError(ErrCodeProviderError, "HybridSearch failed", err)
+	}
+	return matchResults(qdrantScoredPointsToResults(results), residual, req.TopK, req.SkipPayload), nil
 }
 
 // --- Helpers ---
@@ -404,24 +602,69 @@ func qdrantResolveID(id string) *qdrant.PointId {
 func qdrantPayload(m map[string]interface{}) map[string]*qdrant.Value {
 	out := make(map[string]*qdrant.Value, len(m))
 	for k, v := range m {
-		switch val := v.(type) {
-		case string:
-			out[k] = &qdrant.Value{Kind: &qdrant.Value_StringValue{StringValue: val}}
-		case int:
-			out[k] = qdrant.NewValueInt(int64(val))
-		case int64:
-			out[k] = qdrant.NewValueInt(val)
-		case float64:
-			out[k] = qdrant.NewValueDouble(val)
-		case bool:
-			out[k] = qdrant.NewValueBool(val)
-		default:
-			out[k] = &qdrant.Value{Kind: &qdrant.Value_StringValue{StringValue: fmt.Sprintf("%v", val)}}
-		}
+		out[k] = qdrantValue(v)
 	}
 	return out
 }
 
+// qdrantValue converts one payload value. Objects and arrays are stored as
+// Qdrant structs and lists so that nested paths and array membership can be
+// filtered on; unknown types are stored as their string form.
+func qdrantValue(v interface{}) *qdrant.Value {
+	switch val := v.(type) {
+	case string:
+		return &qdrant.Value{Kind: &qdrant.Value_StringValue{StringValue: val}}
+	case int:
+		return qdrant.NewValueInt(int64(val))
+	case int64:
+		return qdrant.NewValueInt(val)
+	case float64:
+		return qdrant.NewValueDouble(val)
+	case bool:
+		return qdrant.NewValueBool(val)
+	case map[string]interface{}:
+		return qdrant.NewValueFromFields(qdrantPayload(val))
+	}
+	if rv := reflect.ValueOf(v); v != nil && rv.Kind() == reflect.Slice {
+		items := make([]*qdrant.Value, rv.Len())
+		for i := range items {
+			items[i] = qdrantValue(rv.Index(i).Interface())
+		}
+		return qdrant.NewValueFromList(items...)
+	}
+	if f, ok := filterNumber(v); ok {
+		return qdrant.NewValueDouble(f)
+	}
+	return &qdrant.Value{Kind: &qdrant.Value_StringValue{StringValue: fmt.Sprintf("%v", v)}}
+}
+
+// qdrantDecodeValue converts a stored payload value back to a Go value.
+func qdrantDecodeValue(v *qdrant.Value) interface{} {
+	switch val := v.GetKind().(type) {
+	case *qdrant.Value_StringValue:
+		return val.StringValue
+	case *qdrant.Value_IntegerValue:
+		return val.IntegerValue
+	case *qdrant.Value_DoubleValue:
+		return val.DoubleValue
+	case *qdrant.Value_BoolValue:
+		return val.BoolValue
+	case *qdrant.Value_ListValue:
+		items := make([]interface{}, len(val.ListValue.GetValues()))
+		for i, item := range val.ListValue.GetValues() {
+			items[i] = qdrantDecodeValue(item)
+		}
+		return items
+	case *qdrant.Value_StructValue:
+		fields := make(map[string]interface{}, len(val.StructValue.GetFields()))
+		for k, item := range val.StructValue.GetFields() {
+			fields[k] = qdrantDecodeValue(item)
+		}
+		return fields
+	}
+	return nil
+}
+
 // qdrantDecodeDocument reconstructs a Document from the raw Qdrant point components.
 // Shared by qdrantPointToDocument (RetrievedPoint) and qdrantScoredPointsToResults
 // (ScoredPoint) to keep payload/ID/vector extraction logic in one place.
@@ -446,12 +689,9 @@ func qdrantDecodeDocument(pointID *qdrant.PointId, payload map[string]*qdrant.Va
 			default:
 				doc.Payload[k] = val.StringValue
 			}
-		case *qdrant.Value_IntegerValue:
-			doc.Payload[k] = val.IntegerValue
-		case *qdrant.Value_DoubleValue:
-			doc.Payload[k] = val.DoubleValue
-		case *qdrant.Value_BoolValue:
-			doc.Payload[k] = val.BoolValue
+		case *qdrant.Value_IntegerValue, *qdrant.Value_DoubleValue, *qdrant.Value_BoolValue,
+			*qdrant.Value_ListValue, *qdrant.Value_StructValue:
+			doc.Payload[k] = qdrantDecodeValue(v)
 		}
 	}
 	if vec != nil {
@@ -460,23 +700,35 @@ func qdrantDecodeDocument(pointID *qdrant.PointId, payload map[string]*qdrant.Va
 	return doc
 }
 
-func qdrantPointToDocument(p *qdrant.RetrievedPoint) *Document {
-	var vec []float32
-	if p.GetVectors() != nil {
-		vec = p.GetVectors().GetVector().GetData()
+// qdrantDenseVector extracts the dense vector from a point's vectors output.
+// Collections with a sparse vector return named vectors, in which case the
+// unnamed ("") dense entry is used.
+func qdrantDenseVector(vo *qdrant.VectorsOutput) []float32 {
+	if vo == nil {
+		return nil
 	}
-	doc := qdrantDecodeDocument(p.GetId(), p.GetPayload(), vec)
+	if named := vo.GetVectors(); named != nil {
+		v := named.GetVectors()[""]
+		if d := v.GetDense(); d != nil {
+			return d.GetData()
+		}
+		return v.GetData()
+	}
+	if d := vo.GetVector().GetDense(); d != nil {
+		return d.GetData()
+	}
+	return vo.GetVector().GetData()
+}
+
+func qdrantPointToDocument(p *qdrant.RetrievedPoint) *Document {
+	doc := qdrantDecodeDocument(p.GetId(), p.GetPayload(), qdrantDenseVector(p.GetVectors()))
 	return &doc
 }
 
 func qdrantScoredPointsToResults(pts []*qdrant.ScoredPoint) []SearchResult {
 	out := make([]SearchResult, len(pts))
 	for i, p := range pts {
-		var vec []float32
-		if p.GetVectors() != nil {
-			vec = p.GetVectors().GetVector().GetData()
-		}
-		doc := qdrantDecodeDocument(p.GetId(), p.GetPayload(), vec)
+		doc := qdrantDecodeDocument(p.GetId(), p.GetPayload(), qdrantDenseVector(p.GetVectors()))
 		out[i] = SearchResult{
 			ID:      doc.ID,
 			Score:   float64(p.GetScore()),
@@ -488,170 +740,156 @@ func qdrantScoredPointsToResults(pts []*qdrant.ScoredPoint) []SearchResult {
 	return out
 }
 
-// buildQdrantFilter builds a Qdrant gRPC Filter from a generic key/value map.
-//
-// Each map entry can be either a scalar equality or an operator map:
+// qdrantFilter parses filters into the Qdrant Filter and the residual that
+// is matched client-side.
+func qdrantFilter(filters map[string]interface{}) (*qdrant.Filter, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	pushed, residual := splitFilter(expr, qdrantNative)
+	return buildQdrantFilter(pushed), residual, nil
+}
+
+// qdrantNative reports whether Qdrant evaluates a filter exactly. Payload
+// paths are native (Qdrant uses the same dotted keys). Range conditions
+// need a number — Qdrant does not order keyword payloads — and an empty
+// $in / $nin has no Qdrant match.
+func qdrantNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterGt, FilterGte, FilterLt, FilterLte:
+			_, isNum := toFloat64(n.Value)
+			return isNum
+		case FilterIn, FilterNin:
+			return len(n.Values()) > 0
+		}
+		return true
+	})
+}
+
+// buildQdrantFilter converts a filter expression into a Qdrant gRPC Filter:
 //
-//	Scalar:   {"field": "foo"}               → keyword match
-//	Operator: {"field": {"$gt": 5.0}}        → numeric range
-//	          {"field": {"$in": ["a","b"]}}   → keyword/integer set match
-//	          {"field": {"$nin": [1,2,3]}}    → except-integers
-//	          {"field": {"$ne": "foo"}}       → except-keywords
+//	$and / $or / $not      → Must / Should / MustNot (nested as filter conditions)
+//	$eq, $contains         → keyword / integer / boolean match, or a tight range for floats
+//	$in                    → keywords / integers match
+//	$ne, $nin              → MustNot of the $eq / $in match (missing keys match)
+//	$gt, $gte, $lt, $lte   → numeric range
+//	$exists                → MustNot / Must IsEmpty
 //
-// Multiple top-level entries are ANDed via Must[].
-// Unrecognised operators are silently skipped (log a warning upstream if needed).
-func buildQdrantFilter(filters map[string]interface{}) *qdrant.Filter {
-	if len(filters) == 0 {
+// A match on an array payload matches when any element matches, so $eq and
+// $contains share one condition. Returns nil when expr is nil.
+func buildQdrantFilter(expr *FilterExpr) *qdrant.Filter {
+	if expr == nil {
 		return nil
 	}
-	conds := make([]*qdrant.Condition, 0, len(filters))
-	for k, v := range filters {
-		switch val := v.(type) {
-		case map[string]interface{}:
-			// Operator map: {"$gt": 5} etc.
-			if cond := qdrantOperatorCondition(k, val); cond != nil {
-				conds = append(conds, cond)
-			}
-		case bool:
-			conds = append(conds, qdrantMatchCond(k,
-				&qdrant.Match{MatchValue: &qdrant.Match_Boolean{Boolean: val}}))
-		case int:
-			conds = append(conds, qdrantMatchCond(k,
-				&qdrant.Match{MatchValue: &qdrant.Match_Integer{Integer: int64(val)}}))
-		case int64:
-			conds = append(conds, qdrantMatchCond(k,
-				&qdrant.Match{MatchValue: &qdrant.Match_Integer{Integer: val}}))
-		case float64:
-			// Qdrant has no float equality match; fall back to a tight range.
-			conds = append(conds, qdrantRangeCond(k, &qdrant.Range{
-				Gte: float64Ptr(val),
-				Lte: float64Ptr(val),
-			}))
-		default:
-			conds = append(conds, qdrantMatchCond(k,
-				&qdrant.Match{MatchValue: &qdrant.Match_Keyword{Keyword: fmt.Sprintf("%v", v)}}))
-		}
+	conds := make([]*qdrant.Condition, 0, len(expr.Children))
+	for _, c := range expr.Children {
+		conds = append(conds, qdrantCondition(c))
 	}
-	if len(conds) == 0 {
-		return nil
+	switch expr.Op {
+	case FilterAnd:
+		return &qdrant.Filter{Must: conds}
+	case FilterOr:
+		return &qdrant.Filter{Should: conds}
+	case FilterNot:
+		return &qdrant.Filter{MustNot: conds}
 	}
-	return &qdrant.Filter{Must: conds}
+	return &qdrant.Filter{Must: []*qdrant.Condition{qdrantCondition(expr)}}
 }
 
-// qdrantOperatorCondition converts a single {"$op": operand} map into a Condition.
-// Returns nil for unsupported operators so the caller can skip gracefully.
-func qdrantOperatorCondition(key string, ops map[string]interface{}) *qdrant.Condition {
-	// Collect range fields first (multiple range ops on one field share one Range struct).
-	r := &qdrant.Range{}
-	hasRange := false
-
-	for op, operand := range ops {
-		switch op {
-		case "$eq":
-			return qdrantEqCondition(key, operand)
-		case "$ne":
-			return qdrantNeCondition(key, operand)
-		case "$in":
-			return qdrantInCondition(key, operand, false)
-		case "$nin":
-			return qdrantInCondition(key, operand, true)
-		case "$gt":
-			if f, ok := toFloat64(operand); ok {
-				r.Gt = float64Ptr(f)
-				hasRange = true
-			}
-		case "$gte":
-			if f, ok := toFloat64(operand); ok {
-				r.Gte = float64Ptr(f)
-				hasRange = true
-			}
-		case "$lt":
-			if f, ok := toFloat64(operand); ok {
-				r.Lt = float64Ptr(f)
-				hasRange = true
-			}
-		case "$lte":
-			if f, ok := toFloat64(operand); ok {
-				r.Lte = float64Ptr(f)
-				hasRange = true
-			}
+// qdrantCondition converts one expression node into a Condition. Logical
+// nodes become nested filter conditions.
+func qdrantCondition(e *FilterExpr) *qdrant.Condition {
+	switch e.Op {
+	case FilterAnd, FilterOr, FilterNot:
+		return qdrant.NewFilterAsCondition(buildQdrantFilter(e))
+	case FilterEq, FilterContains:
+		return qdrantEqCondition(e.Field, e.Value)
+	case FilterNe:
+		return qdrantNotCondition(qdrantEqCondition(e.Field, e.Value))
+	case FilterIn:
+		return qdrantInCondition(e.Field, e.Values())
+	case FilterNin:
+		return qdrantNotCondition(qdrantInCondition(e.Field, e.Values()))
+	case FilterExists:
+		if exists, _ := e.Value.(bool); exists {
+			return qdrantNotCondition(qdrant.NewIsEmpty(e.Field))
 		}
+		return qdrant.NewIsEmpty(e.Field)
 	}
-	if hasRange {
-		return qdrantRangeCond(key, r)
+	f, _ := toFloat64(e.Value)
+	r := &qdrant.Range{}
+	switch e.Op {
+	case FilterGt:
+		r.Gt = float64Ptr(f)
+	case FilterGte:
+		r.Gte = float64Ptr(f)
+	case FilterLt:
+		r.Lt = float64Ptr(f)
+	default:
+		r.Lte = float64Ptr(f)
 	}
-	return nil
+	return qdrantRangeCond(e.Field, r)
+}
+
+// qdrantNotCondition negates a condition with a nested MustNot filter.
+func qdrantNotCondition(c *qdrant.Condition) *qdrant.Condition {
+	return qdrant.NewFilterAsCondition(&qdrant.Filter{MustNot: []*qdrant.Condition{c}})
 }
 
 // qdrantEqCondition builds an equality Condition.
 func qdrantEqCondition(key string, v interface{}) *qdrant.Condition {
 	switch val := v.(type) {
+	case string:
+		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Keyword{Keyword: val}})
 	case bool:
 		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Boolean{Boolean: val}})
 	case int:
 		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Integer{Integer: int64(val)}})
+	case int32:
+		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Integer{Integer: int64(val)}})
 	case int64:
 		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Integer{Integer: val}})
-	case float64:
-		return qdrantRangeCond(key, &qdrant.Range{Gte: float64Ptr(val), Lte: float64Ptr(val)})
-	default:
-		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Keyword{Keyword: fmt.Sprintf("%v", v)}})
 	}
-}
-
-// qdrantNeCondition builds a not-equal Condition using ExceptKeywords / ExceptIntegers.
-func qdrantNeCondition(key string, v interface{}) *qdrant.Condition {
-	switch val := v.(type) {
-	case int:
-		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_ExceptIntegers{
-			ExceptIntegers: &qdrant.RepeatedIntegers{Integers: []int64{int64(val)}}}})
-	case int64:
-		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_ExceptIntegers{
-			ExceptIntegers: &qdrant.RepeatedIntegers{Integers: []int64{val}}}})
-	default:
-		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_ExceptKeywords{
-			ExceptKeywords: &qdrant.RepeatedStrings{Strings: []string{fmt.Sprintf("%v", v)}}}})
+	if f, ok := filterNumber(v); ok {
+		// Qdrant has no float equality match; fall back to a tight range.
+		return qdrantRangeCond(key, &qdrant.Range{Gte: float64Ptr(f), Lte: float64Ptr(f)})
 	}
+	return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Keyword{Keyword: fmt.Sprintf("%v", v)}})
 }
 
-// qdrantInCondition builds a set-membership Condition (In / Nin).
-func qdrantInCondition(key string, operand interface{}, notIn bool) *qdrant.Condition {
-	items, ok := operand.([]interface{})
-	if !ok || len(items) == 0 {
-		return nil
-	}
-	// Infer type from first element.
-	switch items[0].(type) {
-	case int, int32, int64:
-		ints := make([]int64, 0, len(items))
-		for _, item := range items {
-			switch n := item.(type) {
-			case int:
-				ints = append(ints, int64(n))
-			case int32:
-				ints = append(ints, int64(n))
-			case int64:
-				ints = append(ints, n)
-			}
-		}
-		if notIn {
-			return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_ExceptIntegers{
-				ExceptIntegers: &qdrant.RepeatedIntegers{Integers: ints}}})
-		}
-		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Integers{
-			Integers: &qdrant.RepeatedIntegers{Integers: ints}}})
-	default:
-		strs := make([]string, 0, len(items))
-		for _, item := range items {
-			strs = append(strs, fmt.Sprintf("%v", item))
-		}
-		if notIn {
-			return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_ExceptKeywords{
-				ExceptKeywords: &qdrant.RepeatedStrings{Strings: strs}}})
+// qdrantInCondition builds a set-membership Condition. All-string and
+// all-integer sets use a single Keywords / Integers match; other sets are an
+// OR of equality conditions.
+func qdrantInCondition(key string, items []interface{}) *qdrant.Condition {
+	strs := make([]string, 0, len(items))
+	ints := make([]int64, 0, len(items))
+	for _, item := range items {
+		switch n := item.(type) {
+		case string:
+			strs = append(strs, n)
+		case int:
+			ints = append(ints, int64(n))
+		case int32:
+			ints = append(ints, int64(n))
+		case int64:
+			ints = append(ints, n)
 		}
+	}
+	switch len(items) {
+	case len(strs):
 		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Keywords{
 			Keywords: &qdrant.RepeatedStrings{Strings: strs}}})
+	case len(ints):
+		return qdrantMatchCond(key, &qdrant.Match{MatchValue: &qdrant.Match_Integers{
+			Integers: &qdrant.RepeatedIntegers{Integers: ints}}})
+	}
+	conds := make([]*qdrant.Condition, len(items))
+	for i, item := range items {
+		conds[i] = qdrantEqCondition(key, item)
 	}
+	return qdrant.NewFilterAsCondition(&qdrant.Filter{Should: conds})
 }
 
 func qdrantMatchCond(key string, m *qdrant.Match) *qdrant.Condition {
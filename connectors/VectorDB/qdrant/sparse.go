@@ -0,0 +1,105 @@
+package vectordb
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// qdrantSparseVectorName is the named sparse vector declared on collections
+// created with CollectionConfig.EnableSparse. The dense vector stays unnamed
+// (""), so collections created before sparse support keep working unchanged.
+const qdrantSparseVectorName = "text-sparse"
+
+// BM25 term-frequency saturation parameters. Qdrant applies the IDF half of
+// BM25 server-side via Modifier_Idf on the sparse vector, so only the TF
+// component is computed client-side.
+const (
+	bm25K1          = 1.2
+	bm25B           = 0.75
+	bm25AvgDocLen   = 256.0
+	maxSparseTokens = 4096
+)
+
+// sparseTokenize splits text into lower-case terms suitable for keyword
+// matching. Letters, digits and the joiners '-', '_' and '.' are kept inside
+// a term so identifiers such as error codes ("ERR-4012") and product SKUs
+// ("SKU_88.1") survive as single tokens; leading/trailing joiners are trimmed.
+func sparseTokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.'
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, "-_.")
+		if f == "" {
+			continue
+		}
+		tokens = append(tokens, f)
+		if len(tokens) >= maxSparseTokens {
+			break
+		}
+	}
+	return tokens
+}
+
+// sparseTermIndex hashes a term to its sparse-vector dimension with FNV-32a.
+func sparseTermIndex(term string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(term))
+	return h.Sum32()
+}
+
+// buildSparseVector builds the document-side sparse vector for text using
+// BM25 term-frequency saturation:
+//
+//	w(t) = tf * (k1 + 1) / (tf + k1 * (1 - b + b * |d| / avgdl))
+//
+// Entries are sorted by index so identical inputs produce identical vectors.
+// Returns nil, nil for text without any terms.
+func buildSparseVector(text string) (indices []uint32, values []float32) {
+	tokens := sparseTokenize(text)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	tf := make(map[uint32]int, len(tokens))
+	for _, tok := range tokens {
+		tf[sparseTermIndex(tok)]++
+	}
+	norm := bm25K1 * (1 - bm25B + bm25B*float64(len(tokens))/bm25AvgDocLen)
+	weights := make(map[uint32]float32, len(tf))
+	for idx, cnt := range tf {
+		f := float64(cnt)
+		weights[idx] = float32(f * (bm25K1 + 1) / (f + norm))
+	}
+	return sortedSparse(weights)
+}
+
+// buildSparseQuery builds the query-side sparse vector for text. Every
+// distinct term gets weight 1.0; ranking comes from the stored document
+// weights combined with the collection-level IDF modifier.
+func buildSparseQuery(text string) (indices []uint32, values []float32) {
+	tokens := sparseTokenize(text)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	weights := make(map[uint32]float32, len(tokens))
+	for _, tok := range tokens {
+		weights[sparseTermIndex(tok)] = 1
+	}
+	return sortedSparse(weights)
+}
+
+func sortedSparse(weights map[uint32]float32) ([]uint32, []float32) {
+	indices := make([]uint32, 0, len(weights))
+	for idx := range weights {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	values := make([]float32, len(indices))
+	for i, idx := range indices {
+		values[i] = weights[idx]
+	}
+	return indices, values
+}
@@ -33,6 +33,23 @@ func normalizeDistanceMetric(metric string) string {
 	return m
 }
 
+// hybridPrefetchFactor is how many candidates each side of a native hybrid
+// query fetches, as a multiple of TopK, before fusion.
+const hybridPrefetchFactor = 4
+
+// normalizeFusion lowercases and validates a HybridSearchRequest.Fusion value,
+// returning "rrf" for empty input.
+func normalizeFusion(fusion string) (string, error) {
+	f := strings.ToLower(strings.TrimSpace(fusion))
+	switch f {
+	case "":
+		return "rrf", nil
+	case "rrf", "dbsf":
+		return f, nil
+	}
+	return "", newError(ErrCodeInvalidFusion, fmt.Sprintf("fusion %q is invalid; use: rrf, dbsf", fusion), nil)
+}
+
 // validateSearchRequest validates common search parameters.
 func validateSearchRequest(collectionName string, queryVector []float64, topK int) error {
 	if collectionName == "" {
@@ -3,12 +3,21 @@ package ingestDocuments
 // docextract.go — Binary document text extraction for IngestDocuments activity.
 //
 // Supported formats:
-//   .pdf  — github.com/ledongthuc/pdf  (MIT, pure Go, CGo-free)
-//   .docx — stdlib archive/zip + encoding/xml  (no extra dependency)
-//   .txt / .md — raw UTF-8 passthrough
+//   .pdf          — github.com/ledongthuc/pdf  (MIT, pure Go, CGo-free)
+//   .docx         — stdlib archive/zip + encoding/xml  (no extra dependency)
+//   .pptx / .xlsx — stdlib archive/zip + encoding/xml  (docextract_ooxml.go)
+//   .html / .htm  — golang.org/x/net/html  (docextract_html.go)
+//   .csv / .tsv   — stdlib encoding/csv  (docextract_table.go)
+//   .eml          — stdlib net/mail + mime/multipart  (docextract_eml.go)
+//   .json / .jsonl — stdlib encoding/json  (docextract_json.go)
+//   .txt / .md    — raw UTF-8 passthrough
+//
+// Every extractor renders structure as Markdown the heading chunk strategy can
+// split on: "#" heading lines, "| a | b |" table rows, "Header: value" rows.
 //
 // Usage:
 //   text, err := ExtractTextFromBytes(data, "report.pdf")
+//   doc, err := ExtractDocument(data, "deck.pptx") // per-slide sections
 
 import (
 	"archive/zip"
@@ -19,34 +28,97 @@ import (
 	"io"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/ledongthuc/pdf"
 )
 
+// DocumentSection is one structural unit of an extracted file — a PDF page, a
+// slide, a worksheet, a JSON record — with the location metadata (e.g.
+// {"page": 3}) that is copied onto every chunk cut from it.
+type DocumentSection struct {
+	Text     string
+	Metadata map[string]interface{}
+}
+
+// ExtractedDocument is the structured result of ExtractDocument.
+type ExtractedDocument struct {
+	Sections []DocumentSection
+	// Metadata holds document-level fields such as the HTML title or e-mail
+	// headers. parseFiles adds them to the document payload.
+	Metadata map[string]interface{}
+}
+
+// Text joins the non-empty sections with blank lines.
+func (d *ExtractedDocument) Text() string {
+	parts := make([]string, 0, len(d.Sections))
+	for _, s := range d.Sections {
+		if t := strings.TrimSpace(s.Text); t != "" {
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// singleSection wraps text that has no finer location than the file itself.
+func singleSection(text string) *ExtractedDocument {
+	return &ExtractedDocument{Sections: []DocumentSection{{Text: strings.TrimSpace(text)}}}
+}
+
 // ExtractTextFromBytes extracts plain text from raw file bytes, using the
 // filename extension to select the correct parser.
 func ExtractTextFromBytes(data []byte, filename string) (string, error) {
+	doc, err := ExtractDocument(data, filename)
+	if err != nil {
+		return "", err
+	}
+	return doc.Text(), nil
+}
+
+// ExtractDocument extracts the text of a file split into its structural
+// sections, using the filename extension to select the correct parser.
+func ExtractDocument(data []byte, filename string) (*ExtractedDocument, error) {
 	ext := strings.ToLower(filepath.Ext(filename))
 	switch ext {
 	case ".pdf":
 		return extractPDF(data)
 	case ".docx":
-		return extractDOCX(data)
+		text, err := extractDOCX(data)
+		if err != nil {
+			return nil, err
+		}
+		return singleSection(text), nil
+	case ".pptx":
+		return extractPPTX(data)
+	case ".xlsx", ".xlsm":
+		return extractXLSX(data)
+	case ".csv":
+		return extractDelimited(data, ',')
+	case ".tsv":
+		return extractDelimited(data, '\t')
+	case ".html", ".htm", ".xhtml":
+		return extractHTML(data)
+	case ".eml":
+		return extractEML(data)
+	case ".json":
+		return extractJSON(data)
+	case ".jsonl", ".ndjson":
+		return extractJSONLines(data)
 	case ".txt", ".md", ".text", ".markdown":
-		return strings.TrimSpace(string(data)), nil
+		return singleSection(string(data)), nil
 	case ".doc":
 		// Legacy binary Word format (.doc) is not extractable without a
 		// specialised parser. Please convert to .docx (File → Save As in Word)
 		// or export as PDF before ingesting.
-		return "", fmt.Errorf("unsupported file type %q: legacy binary .doc format cannot be read — convert to .docx or .pdf first", ext)
+		return nil, fmt.Errorf("unsupported file type %q: legacy binary .doc format cannot be read — convert to .docx or .pdf first", ext)
 	default:
 		// Graceful fallback: if the content looks like UTF-8 text, return it as-is.
 		if looksLikeText(data) {
-			return strings.TrimSpace(string(data)), nil
+			return singleSection(string(data)), nil
 		}
-		return "", fmt.Errorf("unsupported file type %q — supported: .pdf, .docx, .txt, .md", ext)
+		return nil, fmt.Errorf("unsupported file type %q — supported: .pdf, .docx, .pptx, .xlsx, .csv, .tsv, .html, .eml, .json, .jsonl, .txt, .md", ext)
 	}
 }
 
@@ -61,12 +133,17 @@ func ExtractTextFromBytes(data []byte, filename string) (string, error) {
 //   - Re-joins words split by typographic hyphens at end-of-line.
 //   - Emits ## markers before detected section headings so the heading chunk
 //     strategy can split on them.
-func extractPDF(data []byte) (string, error) {
+//
+// Each non-empty page becomes one section carrying its 1-based page number.
+func extractPDF(data []byte) (*ExtractedDocument, error) {
 	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
-		return "", fmt.Errorf("pdf: open failed: %w", err)
+		return nil, fmt.Errorf("pdf: open failed: %w", err)
 	}
-	var pages []string
+	var (
+		pages   []string
+		pageNos []int
+	)
 	for i := 1; i <= r.NumPage(); i++ {
 		p := r.Page(i)
 		if p.V.IsNull() {
@@ -75,16 +152,27 @@ func extractPDF(data []byte) (string, error) {
 		pageText := extractPageRows(p)
 		if strings.TrimSpace(pageText) != "" {
 			pages = append(pages, pageText)
+			pageNos = append(pageNos, i)
 		}
 	}
+	doc := &ExtractedDocument{}
 	if len(pages) == 0 {
-		return "", nil
+		return doc, nil
 	}
 	// Level 2: strip repeating page headers/footers.
 	pages = stripPageHeadersFooters(pages)
-	combined := strings.Join(pages, "\n\n")
-	// Level 2: hyphen rejoining, heading detection, whitespace normalisation.
-	return postProcessPDFText(combined), nil
+	for i, page := range pages {
+		// Level 2: hyphen rejoining, heading detection, whitespace normalisation.
+		text := postProcessPDFText(page)
+		if text == "" {
+			continue
+		}
+		doc.Sections = append(doc.Sections, DocumentSection{
+			Text:     text,
+			Metadata: map[string]interface{}{"page": pageNos[i]},
+		})
+	}
+	return doc, nil
 }
 
 // extractPageRows converts one PDF page into a string using GetTextByRow so
@@ -308,12 +396,30 @@ func extractDOCX(data []byte) (string, error) {
 	return "", fmt.Errorf("docx: word/document.xml not found — is this a valid .docx file?")
 }
 
+// docxHeadingStyleRe matches the built-in heading paragraph styles
+// ("Heading1" … "Heading6"; Word writes the style ID without a space).
+var docxHeadingStyleRe = regexp.MustCompile(`^(?i)heading\s?([1-6])$`)
+
 // parseWordXML streams word/document.xml and extracts text from <w:t> elements.
-// Each <w:p> paragraph boundary inserts a newline.
+// Each <w:p> paragraph boundary inserts a newline. Paragraphs styled as Title
+// or Heading 1–6 (or carrying an outline level) are emitted as Markdown
+// headings, and each table row (<w:tr>) becomes one "| cell | cell |" line.
 func parseWordXML(r io.Reader) (string, error) {
 	const wNS = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
-	var sb strings.Builder
-	inText := false
+	var (
+		sb         strings.Builder
+		para       strings.Builder
+		cell       strings.Builder
+		row        []string
+		inText     bool
+		tableDepth int
+		level      int // heading level of the current paragraph; 0 = body text
+	)
+	newline := func() {
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+	}
 	dec := xml.NewDecoder(r)
 	for {
 		tok, err := dec.Token()
@@ -325,32 +431,124 @@ func parseWordXML(r io.Reader) (string, error) {
 		}
 		switch t := tok.(type) {
 		case xml.StartElement:
+			if t.Name.Space != wNS && t.Name.Space != "" {
+				continue
+			}
 			switch t.Name.Local {
 			case "p":
-				// Paragraph boundary — add newline between paragraphs.
-				if sb.Len() > 0 {
-					sb.WriteByte('\n')
+				para.Reset()
+				level = 0
+			case "pStyle":
+				val := xmlAttr(t, "val")
+				if strings.EqualFold(val, "Title") {
+					level = 1
+				} else if m := docxHeadingStyleRe.FindStringSubmatch(val); m != nil {
+					level, _ = strconv.Atoi(m[1])
+				}
+			case "outlineLvl":
+				// Outline levels are 0-based; 9 means body text.
+				if n, err := strconv.Atoi(xmlAttr(t, "val")); err == nil && n < 6 && level == 0 {
+					level = n + 1
 				}
 			case "t":
 				// Text run — only collect chars inside <w:t>.
-				if t.Name.Space == wNS || t.Name.Space == "" {
-					inText = true
-				}
+				inText = true
+			case "tab":
+				para.WriteByte(' ')
 			case "br", "cr":
 				// Line break inside a paragraph.
-				sb.WriteByte('\n')
+				para.WriteByte('\n')
+			case "tbl":
+				tableDepth++
+			case "tr":
+				if tableDepth == 1 {
+					row = row[:0]
+				}
+			case "tc":
+				if tableDepth == 1 {
+					cell.Reset()
+				}
 			}
 		case xml.EndElement:
-			if t.Name.Local == "t" {
+			if t.Name.Space != wNS && t.Name.Space != "" {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
 				inText = false
+			case "p":
+				text := para.String()
+				if tableDepth > 0 {
+					// Paragraphs inside a cell are joined into one cell value.
+					if s := strings.TrimSpace(text); s != "" {
+						if cell.Len() > 0 {
+							cell.WriteByte(' ')
+						}
+						cell.WriteString(s)
+					}
+					continue
+				}
+				if level > 0 && strings.TrimSpace(text) != "" {
+					// A blank line before the heading, none after it, so the
+					// paragraph strategy keeps a heading with its body.
+					if sb.Len() > 0 {
+						sb.WriteString("\n\n")
+					}
+					sb.WriteString(strings.Repeat("#", level) + " " + strings.TrimSpace(text))
+					continue
+				}
+				newline()
+				sb.WriteString(text)
+			case "tc":
+				if tableDepth == 1 {
+					row = append(row, cell.String())
+				}
+			case "tr":
+				if tableDepth == 1 {
+					if line := tableRow(row); line != "" {
+						newline()
+						sb.WriteString(line)
+					}
+				}
+			case "tbl":
+				tableDepth--
 			}
 		case xml.CharData:
 			if inText {
-				sb.Write(t)
+				para.Write(t)
 			}
 		}
 	}
-	return strings.TrimSpace(sb.String()), nil
+	return strings.TrimSpace(pdfBlankCollapseRe.ReplaceAllString(sb.String(), "\n\n")), nil
+}
+
+// xmlAttr returns the value of the attribute with the given local name.
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// tableRow renders table cells as one Markdown-style "| a | b |" line. Cell
+// whitespace is collapsed and literal pipes are escaped so a row stays on one
+// line. Rows whose cells are all empty render as "".
+func tableRow(cells []string) string {
+	empty := true
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.Join(strings.Fields(c), " ")
+		out[i] = strings.ReplaceAll(c, "|", `\|`)
+		if c != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return ""
+	}
+	return "| " + strings.Join(out, " | ") + " |"
 }
 
 // fileContentToBytes converts the `content` field of a files[] item to []byte.
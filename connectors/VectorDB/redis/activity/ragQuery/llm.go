@@ -0,0 +1,569 @@
+package ragQuery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ── LLM providers ───────────────────────────────────────────────────────────
+//
+// Each provider turns an llmRequest into one HTTP call. When onToken is
+// non-nil the provider requests a streamed response and calls onToken with
+// every text delta as it arrives; the full answer is returned either way.
+
+// llmRequest is the provider-neutral generation request.
+type llmRequest struct {
+	System      string // system / instruction prompt
+	User        string // context and question
+	Model       string
+	MaxTokens   int
+	Temperature float64
+}
+
+// llmProvider generates an answer with one LLM API.
+type llmProvider func(ctx context.Context, s *Settings, req llmRequest, onToken func(string)) (string, error)
+
+// llmProviders maps the llmProvider setting to its implementation. Providers
+// not listed here use the OpenAI-compatible chat completions API.
+var llmProviders = map[string]llmProvider{
+	"Ollama":       generateOllama,
+	"OpenAI":       generateOpenAICompat,
+	"Azure OpenAI": generateOpenAICompat,
+	"Custom":       generateOpenAICompat,
+	"Anthropic":    generateAnthropic,
+	"Cohere":       generateCohere,
+}
+
+// defaultLLMBaseURLs are used when llmBaseURL is empty. Azure OpenAI has no
+// default: the resource endpoint is always required.
+var defaultLLMBaseURLs = map[string]string{
+	"Ollama":    "http://localhost:11434",
+	"OpenAI":    "https://api.openai.com",
+	"Anthropic": "https://api.anthropic.com",
+	"Cohere":    "https://api.cohere.com",
+}
+
+const (
+	// anthropicAPIVersion is the anthropic-version header of the Messages API.
+	anthropicAPIVersion = "2023-06-01"
+	// defaultAzureLLMAPIVersion is the api-version used for Azure OpenAI chat
+	// completions when llmAPIVersion is empty.
+	defaultAzureLLMAPIVersion = "2024-10-21"
+)
+
+// generate calls the configured LLM to produce an answer grounded in context.
+// onToken, when non-nil, receives the answer incrementally.
+func (a *Activity) generate(ctx context.Context, query, context_, systemPrompt string, onToken func(string)) (string, error) {
+	return a.callLLM(ctx, systemPrompt, buildUserPrompt(context_, query), onToken)
+}
+
+// callLLM sends one system and user prompt to the configured LLM and returns
+// the trimmed response. It is shared by answer generation and the query
+// transformations of the retrieval modes.
+func (a *Activity) callLLM(ctx context.Context, system, user string, onToken func(string)) (string, error) {
+	provider, ok := llmProviders[a.settings.LLMProvider]
+	if !ok {
+		provider = generateOpenAICompat
+	}
+	out, err := provider(ctx, a.settings, llmRequest{
+		System:      system,
+		User:        user,
+		Model:       a.settings.LLMModel,
+		MaxTokens:   a.settings.MaxTokens,
+		Temperature: a.settings.Temperature,
+	}, onToken)
+	return strings.TrimSpace(out), err
+}
+
+// buildPrompt joins the system prompt and the user turn into the single
+// prompt string taken by completion APIs without a system field.
+func buildPrompt(systemPrompt, user string) string {
+	if systemPrompt == "" {
+		return user
+	}
+	return systemPrompt + "\n\n" + user
+}
+
+// buildUserPrompt constructs the user turn of a chat request: the retrieved
+// context followed by the question. The system prompt is sent separately.
+func buildUserPrompt(context_, query string) string {
+	return "Context:\n" + context_ + "\n\nQuestion: " + query + "\n\nAnswer:"
+}
+
+// postLLM sends a JSON request and returns the response for the caller to
+// read. Non-2xx responses are returned as errors with the (truncated) body.
+func postLLM(ctx context.Context, name, endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: marshal request: %w", name, err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("%s: create request: %w", name, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := ragLLMHTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: http: %w", name, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, fmt.Errorf("%s: status %d: %s", name, resp.StatusCode, string(b))
+	}
+	return resp, nil
+}
+
+// readLLMBody reads a non-streaming response body, limited to 10 MB to
+// prevent unbounded memory allocation from a large response.
+func readLLMBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+}
+
+// scanLines calls fn with each non-empty line of a streamed response. For
+// server-sent events the "data:" prefix is stripped and other SSE fields are
+// skipped. fn returns false to stop reading.
+func scanLines(r io.Reader, sse bool, fn func(line string) (bool, error)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 4<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if sse {
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			line = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+		if line == "" {
+			continue
+		}
+		more, err := fn(line)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func llmBaseURL(s *Settings) string {
+	base := s.LLMBaseURL
+	if base == "" {
+		base = defaultLLMBaseURLs[s.LLMProvider]
+	}
+	return strings.TrimRight(base, "/")
+}
+
+// ── Ollama ──────────────────────────────────────────────────────────────────
+
+// ollamaGenerateRequest is the Ollama /api/generate request body.
+type ollamaGenerateRequest struct {
+	Model   string                 `json:"model"`
+	Prompt  string                 `json:"prompt"`
+	Stream  bool                   `json:"stream"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// ollamaGenerateResponse is the non-streaming Ollama response, and also one
+// line of the streamed (newline-delimited JSON) response.
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+func generateOllama(ctx context.Context, s *Settings, req llmRequest, onToken func(string)) (string, error) {
+	body := ollamaGenerateRequest{
+		Model:  req.Model,
+		Prompt: buildPrompt(req.System, req.User),
+		Stream: onToken != nil,
+	}
+	if req.MaxTokens > 0 || req.Temperature > 0 {
+		body.Options = map[string]interface{}{}
+		if req.MaxTokens > 0 {
+			body.Options["num_predict"] = req.MaxTokens
+		}
+		if req.Temperature > 0 {
+			body.Options["temperature"] = req.Temperature
+		}
+	}
+	resp, err := postLLM(ctx, "ollama", llmBaseURL(s)+"/api/generate", body, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if onToken == nil {
+		b, err := readLLMBody(resp)
+		if err != nil {
+			return "", fmt.Errorf("ollama: read response: %w", err)
+		}
+		var result ollamaGenerateResponse
+		if err := json.Unmarshal(b, &result); err != nil {
+			return "", fmt.Errorf("ollama: parse response: %w", err)
+		}
+		if result.Error != "" {
+			return "", fmt.Errorf("ollama: %s", result.Error)
+		}
+		return result.Response, nil
+	}
+
+	defer resp.Body.Close()
+	var sb strings.Builder
+	err = scanLines(resp.Body, false, func(line string) (bool, error) {
+		var chunk ollamaGenerateResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, fmt.Errorf("ollama: parse stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return false, fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if chunk.Response != "" {
+			sb.WriteString(chunk.Response)
+			onToken(chunk.Response)
+		}
+		return !chunk.Done, nil
+	})
+	return sb.String(), err
+}
+
+// ── OpenAI-compatible (OpenAI, Azure OpenAI, Custom) ────────────────────────
+
+// openAIChatRequest is a minimal OpenAI /v1/chat/completions request body.
+type openAIChatRequest struct {
+	Model       string              `json:"model,omitempty"`
+	Messages    []openAIChatMessage `json:"messages"`
+	MaxTokens   int                 `json:"max_tokens,omitempty"`
+	Temperature float64             `json:"temperature,omitempty"`
+	Stream      bool                `json:"stream,omitempty"`
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIChatMessage `json:"message"`
+		Delta   openAIChatMessage `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// openAIChatEndpoint returns the chat completions URL and auth headers.
+// Azure OpenAI: llmBaseURL is the resource endpoint
+// (https://<resource>.openai.azure.com) and llmModel the deployment name, or
+// llmBaseURL is the full deployment URL; the key is sent as api-key.
+func openAIChatEndpoint(s *Settings) (string, map[string]string) {
+	base := llmBaseURL(s)
+	headers := map[string]string{}
+	if s.LLMProvider != "Azure OpenAI" {
+		if s.LLMAPIKey != "" {
+			headers["Authorization"] = "Bearer " + s.LLMAPIKey
+		}
+		return base + "/v1/chat/completions", headers
+	}
+
+	if s.LLMAPIKey != "" {
+		headers["api-key"] = s.LLMAPIKey
+	}
+	endpoint := base
+	if !strings.Contains(endpoint, "/deployments/") {
+		endpoint += "/openai/deployments/" + url.PathEscape(s.LLMModel)
+	}
+	if !strings.Contains(endpoint, "/chat/completions") {
+		if i := strings.Index(endpoint, "?"); i >= 0 {
+			endpoint = strings.TrimRight(endpoint[:i], "/") + "/chat/completions" + endpoint[i:]
+		} else {
+			endpoint += "/chat/completions"
+		}
+	}
+	if !strings.Contains(endpoint, "api-version=") {
+		version := s.LLMAPIVersion
+		if version == "" {
+			version = defaultAzureLLMAPIVersion
+		}
+		sep := "?"
+		if strings.Contains(endpoint, "?") {
+			sep = "&"
+		}
+		endpoint += sep + "api-version=" + url.QueryEscape(version)
+	}
+	return endpoint, headers
+}
+
+func generateOpenAICompat(ctx context.Context, s *Settings, req llmRequest, onToken func(string)) (string, error) {
+	endpoint, headers := openAIChatEndpoint(s)
+	var messages []openAIChatMessage
+	if req.System != "" {
+		messages = append(messages, openAIChatMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, openAIChatMessage{Role: "user", Content: req.User})
+	body := openAIChatRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      onToken != nil,
+	}
+	if s.LLMProvider == "Azure OpenAI" {
+		body.Model = "" // the deployment in the URL selects the model
+	}
+
+	resp, err := postLLM(ctx, "openai-compat", endpoint, body, headers)
+	if err != nil {
+		return "", err
+	}
+
+	if onToken == nil {
+		b, err := readLLMBody(resp)
+		if err != nil {
+			return "", fmt.Errorf("openai-compat: read response: %w", err)
+		}
+		var result openAIChatResponse
+		if err := json.Unmarshal(b, &result); err != nil {
+			return "", fmt.Errorf("openai-compat: parse response: %w", err)
+		}
+		if result.Error != nil {
+			return "", fmt.Errorf("openai-compat: %s", result.Error.Message)
+		}
+		if len(result.Choices) == 0 {
+			return "", fmt.Errorf("openai-compat: no choices in response")
+		}
+		return result.Choices[0].Message.Content, nil
+	}
+
+	defer resp.Body.Close()
+	var sb strings.Builder
+	err = scanLines(resp.Body, true, func(line string) (bool, error) {
+		if line == "[DONE]" {
+			return false, nil
+		}
+		var chunk openAIChatResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, fmt.Errorf("openai-compat: parse stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return false, fmt.Errorf("openai-compat: %s", chunk.Error.Message)
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content != "" {
+				sb.WriteString(c.Delta.Content)
+				onToken(c.Delta.Content)
+			}
+		}
+		return true, nil
+	})
+	return sb.String(), err
+}
+
+// ── Anthropic Messages API ──────────────────────────────────────────────────
+
+type anthropicMessagesRequest struct {
+	Model       string              `json:"model"`
+	System      string              `json:"system,omitempty"`
+	Messages    []openAIChatMessage `json:"messages"`
+	MaxTokens   int                 `json:"max_tokens"`
+	Temperature float64             `json:"temperature,omitempty"`
+	Stream      bool                `json:"stream,omitempty"`
+}
+
+type anthropicMessagesResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// anthropicStreamEvent is one server-sent event of a streamed Messages call.
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func generateAnthropic(ctx context.Context, s *Settings, req llmRequest, onToken func(string)) (string, error) {
+	headers := map[string]string{"anthropic-version": anthropicAPIVersion}
+	if s.LLMAPIKey != "" {
+		headers["x-api-key"] = s.LLMAPIKey
+	}
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024 // required by the Messages API
+	}
+	resp, err := postLLM(ctx, "anthropic", llmBaseURL(s)+"/v1/messages", anthropicMessagesRequest{
+		Model:       req.Model,
+		System:      req.System,
+		Messages:    []openAIChatMessage{{Role: "user", Content: req.User}},
+		MaxTokens:   maxTokens,
+		Temperature: req.Temperature,
+		Stream:      onToken != nil,
+	}, headers)
+	if err != nil {
+		return "", err
+	}
+
+	if onToken == nil {
+		b, err := readLLMBody(resp)
+		if err != nil {
+			return "", fmt.Errorf("anthropic: read response: %w", err)
+		}
+		var result anthropicMessagesResponse
+		if err := json.Unmarshal(b, &result); err != nil {
+			return "", fmt.Errorf("anthropic: parse response: %w", err)
+		}
+		if result.Error != nil {
+			return "", fmt.Errorf("anthropic: %s", result.Error.Message)
+		}
+		var sb strings.Builder
+		for _, c := range result.Content {
+			if c.Type == "text" {
+				sb.WriteString(c.Text)
+			}
+		}
+		if sb.Len() == 0 {
+			return "", fmt.Errorf("anthropic: no text content in response")
+		}
+		return sb.String(), nil
+	}
+
+	defer resp.Body.Close()
+	var sb strings.Builder
+	err = scanLines(resp.Body, true, func(line string) (bool, error) {
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			return false, fmt.Errorf("anthropic: parse stream: %w", err)
+		}
+		switch ev.Type {
+		case "content_block_delta":
+			if ev.Delta.Type == "text_delta" && ev.Delta.Text != "" {
+				sb.WriteString(ev.Delta.Text)
+				onToken(ev.Delta.Text)
+			}
+		case "error":
+			msg := "stream error"
+			if ev.Error != nil {
+				msg = ev.Error.Message
+			}
+			return false, fmt.Errorf("anthropic: %s", msg)
+		case "message_stop":
+			return false, nil
+		}
+		return true, nil
+	})
+	return sb.String(), err
+}
+
+// ── Cohere Chat API (v2) ────────────────────────────────────────────────────
+
+type cohereChatRequest struct {
+	Model       string              `json:"model"`
+	Messages    []openAIChatMessage `json:"messages"`
+	MaxTokens   int                 `json:"max_tokens,omitempty"`
+	Temperature float64             `json:"temperature,omitempty"`
+	Stream      bool                `json:"stream,omitempty"`
+}
+
+type cohereChatResponse struct {
+	Message struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+// cohereStreamEvent is one server-sent event of a streamed v2 chat call.
+type cohereStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Message struct {
+			Content struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"message"`
+	} `json:"delta"`
+}
+
+func generateCohere(ctx context.Context, s *Settings, req llmRequest, onToken func(string)) (string, error) {
+	headers := map[string]string{}
+	if s.LLMAPIKey != "" {
+		headers["Authorization"] = "Bearer " + s.LLMAPIKey
+	}
+	var messages []openAIChatMessage
+	if req.System != "" {
+		messages = append(messages, openAIChatMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, openAIChatMessage{Role: "user", Content: req.User})
+	resp, err := postLLM(ctx, "cohere", llmBaseURL(s)+"/v2/chat", cohereChatRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      onToken != nil,
+	}, headers)
+	if err != nil {
+		return "", err
+	}
+
+	if onToken == nil {
+		b, err := readLLMBody(resp)
+		if err != nil {
+			return "", fmt.Errorf("cohere: read response: %w", err)
+		}
+		var result cohereChatResponse
+		if err := json.Unmarshal(b, &result); err != nil {
+			return "", fmt.Errorf("cohere: parse response: %w", err)
+		}
+		var sb strings.Builder
+		for _, c := range result.Message.Content {
+			if c.Type == "text" {
+				sb.WriteString(c.Text)
+			}
+		}
+		if sb.Len() == 0 {
+			return "", fmt.Errorf("cohere: no text content in response")
+		}
+		return sb.String(), nil
+	}
+
+	defer resp.Body.Close()
+	var sb strings.Builder
+	err = scanLines(resp.Body, true, func(line string) (bool, error) {
+		var ev cohereStreamEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			return false, fmt.Errorf("cohere: parse stream: %w", err)
+		}
+		switch ev.Type {
+		case "content-delta":
+			if t := ev.Delta.Message.Content.Text; t != "" {
+				sb.WriteString(t)
+				onToken(t)
+			}
+		case "message-end":
+			return false, nil
+		}
+		return true, nil
+	})
+	return sb.String(), err
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -20,6 +21,7 @@ type redisClient struct {
 
 // Compile-time proof that redisClient satisfies the full VectorDBClient interface.
 var _ VectorDBClient = (*redisClient)(nil)
+var _ MetricReader = (*redisClient)(nil)
 
 func newRedisClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	rdb := redis.NewClient(&redis.Options{
@@ -158,6 +160,51 @@ func (c *redisClient) CollectionExists(ctx context.Context, name string) (bool,
 	return true, nil
 }
 
+// CollectionMetric returns the DISTANCE_METRIC of the index's vector field,
+// read from FT.INFO.
+func (c *redisClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", newError(ErrCodeInvalidCollectionName, "", nil)
+	}
+	info, err := c.client.Do(ctx, "FT.INFO", name).Result()
+	if err != nil {
+		if isRedisIndexNotFound(err) {
+			return "", newError(ErrCodeCollectionNotFound,
+				fmt.Sprintf("index %q does not exist", name), err)
+		}
+		return "", newError(ErrCodeProviderError,
+			fmt.Sprintf("FT.INFO %q failed", name), err)
+	}
+	switch metric := strings.ToUpper(redisInfoValue(info, "distance_metric")); metric {
+	case "COSINE":
+		return "cosine", nil
+	case "IP":
+		return "dot", nil
+	case "L2":
+		return "euclidean", nil
+	default:
+		return strings.ToLower(metric), nil
+	}
+}
+
+// redisInfoValue returns the value following the first key in a RESP2
+// FT.INFO reply, searching nested attribute lists.
+func redisInfoValue(reply interface{}, key string) string {
+	list, ok := reply.([]interface{})
+	if !ok {
+		return ""
+	}
+	for i, item := range list {
+		if s, ok := item.(string); ok && strings.EqualFold(s, key) && i+1 < len(list) {
+			return fmt.Sprintf("%v", list[i+1])
+		}
+		if v := redisInfoValue(item, key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // ListCollections returns names of all RediSearch indexes via FT._LIST.
 func (c *redisClient) ListCollections(ctx context.Context) ([]string, error) {
 	result, err := c.client.Do(ctx, "FT._LIST").StringSlice()
@@ -273,7 +320,21 @@ func (c *redisClient) DeleteByFilter(ctx context.Context, collectionName string,
 			"DeleteByFilter: filters must not be empty (would delete all documents)", nil)
 	}
 
-	query := buildRedisFilter(filters)
+	query, residual, err := redisFilter(filters)
+	if err != nil {
+		return -1, err
+	}
+	if residual != nil {
+		// Part of the filter is matched client-side: delete by ID instead.
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return -1, err
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return -1, err
+		}
+		return int64(len(ids)), nil
+	}
 	// Use FT.SEARCH to find matching document IDs
 	// LIMIT 0 10000 to get a large batch (practical limit for filter-based delete)
 	raw, err := c.client.Do(ctx, "FT.SEARCH", collectionName, query,
@@ -334,7 +395,10 @@ func (c *redisClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (*
 		}
 	}
 
-	query := buildRedisFilter(req.Filters)
+	query, residual, err := redisFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
 
 	var returnFields []interface{}
 	if req.WithVectors {
@@ -343,23 +407,44 @@ func (c *redisClient) ScrollDocuments(ctx context.Context, req ScrollRequest) (*
 		returnFields = []interface{}{"RETURN", "3", "id", "content", "metadata"}
 	}
 
-	args := []interface{}{"FT.SEARCH", req.CollectionName, query}
-	args = append(args, returnFields...)
-	args = append(args, "LIMIT", offset, limit)
-
-	raw, err := c.client.Do(ctx, args...).Slice()
-	if err != nil {
-		return nil, newError(ErrCodeProviderError, "FT.SEARCH (scroll) failed", err)
-	}
-
-	docs, total, parseErr := parseDocumentsFromSearch(raw, req.WithVectors)
-	if parseErr != nil {
-		return nil, parseErr
-	}
-
+	// Without a residual one FT.SEARCH page is the result. Otherwise pages
+	// are read until limit documents match, so a page is only short or empty
+	// at the end of the scroll; NextOffset resumes after the last document
+	// returned.
+	var docs []Document
+	var total int64
 	nextOffset := ""
-	if len(docs) == limit {
-		nextOffset = strconv.Itoa(offset + len(docs))
+	for pos := offset; ; {
+		args := []interface{}{"FT.SEARCH", req.CollectionName, query}
+		args = append(args, returnFields...)
+		args = append(args, "LIMIT", pos, limit)
+
+		raw, err := c.client.Do(ctx, args...).Slice()
+		if err != nil {
+			return nil, newError(ErrCodeProviderError, "FT.SEARCH (scroll) failed", err)
+		}
+		page, n, parseErr := parseDocumentsFromSearch(raw, req.WithVectors)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		total = n
+		for i, d := range page {
+			if residual != nil && !residual.Match(redisMatchFields(d.ID, d.Payload)) {
+				continue
+			}
+			docs = append(docs, d)
+			if len(docs) == limit {
+				nextOffset = strconv.Itoa(pos + i + 1)
+				break
+			}
+		}
+		if nextOffset != "" || len(page) < limit {
+			break
+		}
+		pos += len(page)
+	}
+	if residual != nil {
+		total = -1
 	}
 
 	return &ScrollResult{
@@ -375,7 +460,17 @@ func (c *redisClient) CountDocuments(ctx context.Context, collectionName string,
 	if collectionName == "" {
 		return 0, newError(ErrCodeInvalidCollectionName, "", nil)
 	}
-	query := buildRedisFilter(filters)
+	query, residual, err := redisFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
 
 	raw, err := c.client.Do(ctx, "FT.SEARCH", collectionName, query,
 		"LIMIT", "0", "0",
@@ -401,8 +496,12 @@ func (c *redisClient) VectorSearch(ctx context.Context, req SearchRequest) ([]Se
 		return nil, err
 	}
 
+	filter, residual, err := redisFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
 	vecBytes := encodeVector(req.QueryVector)
-	query := fmt.Sprintf("*=>[KNN %d @embedding $vec AS __score__]", req.TopK)
 
 	var returnFields []interface{}
 	if req.WithVectors {
@@ -411,23 +510,21 @@ func (c *redisClient) VectorSearch(ctx context.Context, req SearchRequest) ([]Se
 		returnFields = []interface{}{"RETURN", "4", "id", "content", "metadata", "__score__"}
 	}
 
-	args := []interface{}{"FT.SEARCH", req.CollectionName, query}
-	args = append(args, returnFields...)
-	args = append(args, "PARAMS", "2", "vec", vecBytes)
-	args = append(args, "SORTBY", "__score__")
-	args = append(args, "LIMIT", "0", req.TopK)
-	args = append(args, "DIALECT", "2")
-
-	raw, err := c.client.Do(ctx, args...).Slice()
-	if err != nil {
-		return nil, newError(ErrCodeProviderError, "FT.SEARCH (vector) failed", err)
-	}
+	return redisSearchMatching(req.TopK, residual, req.SkipPayload, func(limit int) ([]SearchResult, error) {
+		query := fmt.Sprintf("%s=>[KNN %d @embedding $vec AS __score__]", filter, limit)
+		args := []interface{}{"FT.SEARCH", req.CollectionName, query}
+		args = append(args, returnFields...)
+		args = append(args, "PARAMS", "2", "vec", vecBytes)
+		args = append(args, "SORTBY", "__score__")
+		args = append(args, "LIMIT", "0", limit)
+		args = append(args, "DIALECT", "2")
 
-	results, parseErr := parseSearchResults(raw, req.SkipPayload, req.WithVectors, req.ScoreThreshold, true)
-	if parseErr != nil {
-		return nil, parseErr
-	}
-	return results, nil
+		raw, err := c.client.Do(ctx, args...).Slice()
+		if err != nil {
+			return nil, newError(ErrCodeProviderError, "FT.SEARCH (vector) failed", err)
+		}
+		return parseSearchResults(raw, req.SkipPayload && residual == nil, req.WithVectors, req.ScoreThreshold, true)
+	})
 }
 
 // HybridSearch combines keyword (BM25) and vector (KNN) search using RediSearch DIALECT 2.
@@ -449,24 +546,38 @@ func (c *redisClient) HybridSearch(ctx context.Context, req HybridSearchRequest)
 		return nil, err
 	}
 
+	filter, residual, err := redisFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
 	vecBytes := encodeVector(req.QueryVector)
 
 	// Build word list from query text for BM25 match
 	words := strings.Fields(req.QueryText)
 	wordQuery := strings.Join(words, " ")
 
-	// Hybrid: (text match) pre-filter + KNN
-	query := fmt.Sprintf("(@content:(%s))=>[KNN %d @embedding $vec AS __score__]", wordQuery, req.TopK)
-
-	args := []interface{}{"FT.SEARCH", req.CollectionName, query,
-		"RETURN", "4", "id", "content", "metadata", "__score__",
-		"PARAMS", "2", "vec", vecBytes,
-		"SORTBY", "__score__",
-		"LIMIT", "0", req.TopK,
-		"DIALECT", "2",
+	// Hybrid: (text match + filter) pre-filter + KNN
+	prefilter := fmt.Sprintf("@content:(%s)", wordQuery)
+	if filter != "*" {
+		prefilter += " " + filter
 	}
 
-	raw, err := c.client.Do(ctx, args...).Slice()
+	results, err := redisSearchMatching(req.TopK, residual, req.SkipPayload, func(limit int) ([]SearchResult, error) {
+		query := fmt.Sprintf("(%s)=>[KNN %d @embedding $vec AS __score__]", prefilter, limit)
+		args := []interface{}{"FT.SEARCH", req.CollectionName, query,
+			"RETURN", "4", "id", "content", "metadata", "__score__",
+			"PARAMS", "2", "vec", vecBytes,
+			"SORTBY", "__score__",
+			"LIMIT", "0", limit,
+			"DIALECT", "2",
+		}
+		raw, err := c.client.Do(ctx, args...).Slice()
+		if err != nil {
+			return nil, err
+		}
+		return parseSearchResults(raw, req.SkipPayload && residual == nil, false, req.ScoreThreshold, true)
+	})
 	if err != nil {
 		// Fall back to pure vector search on syntax/parse errors from text component
 		return c.VectorSearch(ctx, SearchRequest{
@@ -478,48 +589,200 @@ func (c *redisClient) HybridSearch(ctx context.Context, req HybridSearchRequest)
 			SkipPayload:    req.SkipPayload,
 		})
 	}
+	return results, nil
+}
 
-	results, parseErr := parseSearchResults(raw, req.SkipPayload, false, req.ScoreThreshold, true)
-	if parseErr != nil {
-		return nil, parseErr
+// redisMaxCandidates caps the KNN candidates requested while a residual
+// filter is matched; it is the default MAXSEARCHRESULTS of RediSearch.
+const redisMaxCandidates = 10000
+
+// redisSearchMatching runs search for the topK results when there is no
+// residual. Otherwise it starts with searchLimit candidates and doubles them
+// until topK results match the residual, the index has no more candidates,
+// or redisMaxCandidates is reached.
+func redisSearchMatching(topK int, residual *FilterExpr, skipPayload bool, search func(limit int) ([]SearchResult, error)) ([]SearchResult, error) {
+	limit := searchLimit(topK, residual)
+	for {
+		results, err := search(limit)
+		if err != nil {
+			return nil, err
+		}
+		// A short page means KNN ran out of candidates, or the rest scored
+		// below the threshold.
+		exhausted := len(results) < limit || limit >= redisMaxCandidates
+		results = redisMatchResults(results, residual, topK, skipPayload)
+		if residual == nil || len(results) >= topK || exhausted {
+			return results, nil
+		}
+		limit *= 2
+		if limit > redisMaxCandidates {
+			limit = redisMaxCandidates
+		}
 	}
-	return results, nil
 }
 
 // ── Filter building ──────────────────────────────────────────────────────────
 
-// buildRedisFilter converts a filter map to a RediSearch query string.
-// For nil/empty filters, returns "*" (match all).
-// For metadata equality filters: (@metadata:*"key":"value"*)
-// For multiple filters, combines with space (AND in RediSearch).
-func buildRedisFilter(filters map[string]interface{}) string {
-	if len(filters) == 0 {
-		return "*"
+// redisFilter validates filters and converts them to a RediSearch query
+// plus a residual that is matched client-side.
+//
+// The index declares id as a TAG field, so $eq / $ne / $in / $nin conditions
+// on "id", and $and / $or / $not over them, are translated natively:
+//
+//	{"id": "a"}                    → (@id:{a})
+//	{"id": {"$in": ["a","b"]}}     → (@id:{a | b})
+//	{"id": {"$ne": "a"}}           → (-@id:{a})
+//
+// The payload is stored as a JSON string in the metadata TEXT field. A
+// top-level equality on a scalar value is also sent as a pattern over that
+// JSON, which narrows the candidates server-side:
+//
+//	{"category": "news"}           → (@metadata:*"category":"news"*)
+//
+// The pattern is not exact on a tokenized field, so those conditions stay in
+// the residual with every other payload condition. It matches the value as
+// stored, so array members are filtered with $contains, which is residual
+// only.
+func redisFilter(filters map[string]interface{}) (string, *FilterExpr, error) {
+	expr, err := ParseFilter(filters)
+	if err != nil {
+		return "", nil, err
 	}
-
+	pushed, residual := splitFilter(expr, redisNative)
 	var parts []string
-	for k, v := range filters {
-		switch tv := v.(type) {
-		case string:
-			// Escape quotes in the value for JSON substring match
-			escaped := strings.ReplaceAll(tv, `"`, `\"`)
-			parts = append(parts, fmt.Sprintf(`@metadata:*"%s":"%s"*`, k, escaped))
-		case float64:
-			parts = append(parts, fmt.Sprintf(`@metadata:*"%s":%v*`, k, tv))
-		case int, int32, int64:
-			parts = append(parts, fmt.Sprintf(`@metadata:*"%s":%v*`, k, tv))
-		case bool:
-			parts = append(parts, fmt.Sprintf(`@metadata:*"%s":%v*`, k, tv))
+	if pushed != nil {
+		parts = append(parts, buildRedisFilter(pushed))
+	}
+	for _, c := range residual.Conjuncts() {
+		if clause, ok := redisMetadataClause(c); ok {
+			parts = append(parts, clause)
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return "*", residual, nil
+	case 1:
+		return parts[0], residual, nil
+	}
+	return "(" + strings.Join(parts, " ") + ")", residual, nil
+}
+
+// redisMetadataClause returns the @metadata pattern for an equality on a
+// top-level payload key with a scalar value: the key and value as they
+// appear in the stored JSON.
+func redisMetadataClause(e *FilterExpr) (string, bool) {
+	if e.Op != FilterEq || e.Nested() || e.Field == "id" {
+		return "", false
+	}
+	switch v := e.Value.(type) {
+	case string:
+		escaped := strings.ReplaceAll(v, `"`, `\"`)
+		return fmt.Sprintf(`(@metadata:*"%s":"%s"*)`, e.Field, escaped), true
+	case float64, float32, int, int32, int64, bool:
+		return fmt.Sprintf(`(@metadata:*"%s":%v*)`, e.Field, v), true
+	}
+	return "", false
+}
+
+// redisNative reports whether e only references the id TAG field.
+func redisNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterAnd, FilterOr, FilterNot:
+			return true
+		case FilterEq, FilterNe:
+		case FilterIn, FilterNin:
+			if len(n.Values()) == 0 {
+				return false
+			}
 		default:
-			valStr := fmt.Sprintf("%v", v)
-			parts = append(parts, fmt.Sprintf(`@metadata:*"%s":"%s"*`, k, valStr))
+			return false
 		}
+		return n.Field == "id"
+	})
+}
+
+// buildRedisFilter converts a parsed filter on the id field to a RediSearch
+// query string. For a nil filter, returns "*" (match all). Conditions are
+// combined with space (AND), | (OR) and - (NOT).
+func buildRedisFilter(e *FilterExpr) string {
+	if e == nil {
+		return "*"
+	}
+	switch e.Op {
+	case FilterAnd, FilterOr:
+		parts := make([]string, len(e.Children))
+		for i, c := range e.Children {
+			parts[i] = buildRedisFilter(c)
+		}
+		sep := " "
+		if e.Op == FilterOr {
+			sep = " | "
+		}
+		return "(" + strings.Join(parts, sep) + ")"
+	case FilterNot:
+		return "(-" + buildRedisFilter(e.Children[0]) + ")"
+	}
+	values := []interface{}{e.Value}
+	if e.Op == FilterIn || e.Op == FilterNin {
+		values = e.Values()
+	}
+	tags := make([]string, len(values))
+	for i, v := range values {
+		tags[i] = redisEscapeTag(fmt.Sprintf("%v", v))
+	}
+	clause := "@id:{" + strings.Join(tags, " | ") + "}"
+	if e.Op == FilterNe || e.Op == FilterNin {
+		clause = "-" + clause
 	}
+	return "(" + clause + ")"
+}
+
+// redisEscapeTag backslash-escapes the characters that separate or quote
+// tokens in a RediSearch TAG query.
+func redisEscapeTag(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// redisMatchFields returns the fields a residual filter is evaluated
+// against: the payload plus the id TAG field, which a residual can reference
+// under $or / $not.
+func redisMatchFields(id string, payload map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(payload)+1)
+	for k, v := range payload {
+		fields[k] = v
+	}
+	fields["id"] = id
+	return fields
+}
 
-	if len(parts) == 1 {
-		return "(" + parts[0] + ")"
+// redisMatchResults keeps the search results that match residual, up to
+// topK, and drops the payloads fetched for matching when skipPayload is set.
+func redisMatchResults(results []SearchResult, residual *FilterExpr, topK int, skipPayload bool) []SearchResult {
+	if residual == nil {
+		return results
+	}
+	out := results[:0]
+	for _, r := range results {
+		if !residual.Match(redisMatchFields(r.ID, r.Payload)) {
+			continue
+		}
+		if skipPayload {
+			r.Payload = nil
+		}
+		out = append(out, r)
+		if len(out) == topK {
+			break
+		}
 	}
-	return "(" + strings.Join(parts, " ") + ")"
+	return out
 }
 
 // ── Search result parsing ────────────────────────────────────────────────────
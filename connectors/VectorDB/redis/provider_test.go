@@ -5,12 +5,14 @@ package vectordb
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"math"
 	"net"
 	"strconv"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -204,28 +206,186 @@ func TestCreateCollection_MiniredisSkip(t *testing.T) {
 	t.Skip("miniredis: FT.CREATE not supported")
 }
 
+func mustRedisFilter(t *testing.T, filters map[string]interface{}) (string, *FilterExpr) {
+	t.Helper()
+	q, residual, err := redisFilter(filters)
+	require.NoError(t, err)
+	return q, residual
+}
+
 func TestBuildRedisFilter_Empty(t *testing.T) {
-	q := buildRedisFilter(nil)
+	q, residual := mustRedisFilter(t, nil)
 	assert.Equal(t, "*", q)
+	assert.Nil(t, residual)
 
-	q2 := buildRedisFilter(map[string]interface{}{})
+	q2, _ := mustRedisFilter(t, map[string]interface{}{})
 	assert.Equal(t, "*", q2)
 }
 
 func TestBuildRedisFilter_Single(t *testing.T) {
-	q := buildRedisFilter(map[string]interface{}{"category": "science"})
+	q, residual := mustRedisFilter(t, map[string]interface{}{"category": "science"})
 	assert.Contains(t, q, `"category":"science"`)
 	assert.Contains(t, q, "@metadata:")
+	// The pattern is approximate on a tokenized field: matched again client-side.
+	require.NotNil(t, residual)
+	assert.True(t, residual.Match(map[string]interface{}{"category": "science"}))
 }
 
 func TestBuildRedisFilter_Multiple(t *testing.T) {
-	q := buildRedisFilter(map[string]interface{}{
+	q, residual := mustRedisFilter(t, map[string]interface{}{
 		"category": "science",
 		"year":     2024.0,
 	})
 	assert.Contains(t, q, "@metadata:")
 	assert.Contains(t, q, "category")
 	assert.Contains(t, q, "year")
+	require.NotNil(t, residual)
+	assert.Len(t, residual.Conjuncts(), 2)
+}
+
+func TestBuildRedisFilter_OnlyScalarEqualityIsPushed(t *testing.T) {
+	q, residual := mustRedisFilter(t, map[string]interface{}{
+		"year":             map[string]interface{}{"$gte": 2020},
+		"meta.author.team": "search",
+		"$or": []interface{}{
+			map[string]interface{}{"lang": "en"},
+			map[string]interface{}{"lang": "de"},
+		},
+	})
+	assert.Equal(t, "*", q)
+	require.NotNil(t, residual)
+	assert.Len(t, residual.Conjuncts(), 3)
+}
+
+func TestBuildRedisFilter_IDTag(t *testing.T) {
+	q, residual := mustRedisFilter(t, map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"id": map[string]interface{}{"$in": []interface{}{"doc-1", "doc 2"}}},
+			map[string]interface{}{"$not": map[string]interface{}{"id": "x"}},
+		},
+		"category": "science",
+	})
+	assert.Equal(t, `(((@id:{doc\-1 | doc\ 2}) | (-(@id:{x}))) (@metadata:*"category":"science"*))`, q)
+	require.NotNil(t, residual)
+	assert.Equal(t, "category", residual.Field)
+}
+
+func TestRedisMatchResults(t *testing.T) {
+	_, residual := mustRedisFilter(t, map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"id": "a"},
+			map[string]interface{}{"category": "news"},
+		},
+	})
+	results := []SearchResult{
+		{ID: "a", Payload: map[string]interface{}{"category": "blog"}},
+		{ID: "b", Payload: map[string]interface{}{"category": "blog"}},
+		{ID: "c", Payload: map[string]interface{}{"category": "news"}},
+	}
+	got := redisMatchResults(results, residual, 10, true)
+	require.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].ID)
+	assert.Equal(t, "c", got[1].ID)
+	assert.Nil(t, got[0].Payload)
+}
+
+// fakeFTSearch registers an FT.SEARCH on mr that ignores the query and
+// returns docs[offset:offset+count] of the LIMIT argument, so the client-side
+// residual does all the filtering. It records the LIMIT count of every call.
+func fakeFTSearch(t *testing.T, mr *miniredis.Miniredis, docs []Document) *[]int {
+	t.Helper()
+	var limits []int
+	err := mr.Server().Register("FT.SEARCH", func(c *server.Peer, _ string, args []string) {
+		pos, count := 0, len(docs)
+		for i, a := range args {
+			if a == "LIMIT" && i+2 < len(args) {
+				pos, _ = strconv.Atoi(args[i+1])
+				count, _ = strconv.Atoi(args[i+2])
+			}
+		}
+		limits = append(limits, count)
+		end := pos + count
+		if end > len(docs) {
+			end = len(docs)
+		}
+		if pos > end {
+			pos = end
+		}
+		page := docs[pos:end]
+		c.WriteLen(1 + 2*len(page))
+		c.WriteInt(len(docs))
+		for _, d := range page {
+			meta, _ := json.Marshal(d.Payload)
+			c.WriteBulk(docKey(args[0], d.ID))
+			c.WriteStrings([]string{"id", d.ID, "content", d.Content, "metadata", string(meta), "__score__", "0.1"})
+		}
+	})
+	require.NoError(t, err)
+	return &limits
+}
+
+// pagedDocs returns ten documents; only the last three are news.
+func pagedDocs() []Document {
+	docs := make([]Document, 10)
+	for i := range docs {
+		category := "blog"
+		if i >= 7 {
+			category = "news"
+		}
+		docs[i] = Document{ID: "d" + strconv.Itoa(i), Content: "text", Payload: map[string]interface{}{"category": category}}
+	}
+	return docs
+}
+
+func TestScrollDocuments_ResidualReadsUntilLimitMatches(t *testing.T) {
+	c, mr := newTestClient(t)
+	fakeFTSearch(t, mr, pagedDocs())
+	filters := map[string]interface{}{"category": "news"}
+
+	page, err := c.ScrollDocuments(context.Background(), ScrollRequest{CollectionName: "idx", Limit: 2, Filters: filters})
+	require.NoError(t, err)
+	require.Len(t, page.Documents, 2, "non-matching pages are skipped instead of returned empty")
+	assert.Equal(t, "d7", page.Documents[0].ID)
+	assert.Equal(t, "d8", page.Documents[1].ID)
+	assert.Equal(t, "9", page.NextOffset, "resumes after the last document returned")
+	assert.EqualValues(t, -1, page.Total)
+
+	page, err = c.ScrollDocuments(context.Background(), ScrollRequest{CollectionName: "idx", Limit: 2, Filters: filters, Offset: page.NextOffset})
+	require.NoError(t, err)
+	require.Len(t, page.Documents, 1)
+	assert.Equal(t, "d9", page.Documents[0].ID)
+	assert.Empty(t, page.NextOffset)
+}
+
+func TestScrollDocuments_NoResidualReadsOnePage(t *testing.T) {
+	c, mr := newTestClient(t)
+	limits := fakeFTSearch(t, mr, pagedDocs())
+
+	page, err := c.ScrollDocuments(context.Background(), ScrollRequest{CollectionName: "idx", Limit: 4})
+	require.NoError(t, err)
+	assert.Len(t, page.Documents, 4)
+	assert.Equal(t, "4", page.NextOffset)
+	assert.EqualValues(t, 10, page.Total)
+	assert.Equal(t, []int{4}, *limits)
+}
+
+func TestVectorSearch_ResidualWidensKNNUntilTopKMatch(t *testing.T) {
+	c, mr := newTestClient(t)
+	limits := fakeFTSearch(t, mr, pagedDocs())
+
+	results, err := c.VectorSearch(context.Background(), SearchRequest{
+		CollectionName: "idx",
+		QueryVector:    []float64{1, 0},
+		TopK:           2,
+		Filters:        map[string]interface{}{"category": "news"},
+		SkipPayload:    true,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "d7", results[0].ID)
+	assert.Equal(t, "d8", results[1].ID)
+	assert.Nil(t, results[0].Payload)
+	assert.Equal(t, []int{8, 16}, *limits, "topK×4 candidates, then doubled until the index is exhausted")
 }
 
 func TestUpsertDocuments_Validation(t *testing.T) {
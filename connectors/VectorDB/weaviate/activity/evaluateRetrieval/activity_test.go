@@ -0,0 +1,263 @@
+package evaluateRetrieval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate"
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/connector"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/testutil/mock"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/core/support/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeActivityContext struct {
+	inputs  map[string]interface{}
+	outputs map[string]interface{}
+}
+
+func (f *fakeActivityContext) ActivityHost() activity.Host        { return nil }
+func (f *fakeActivityContext) Name() string                       { return "test" }
+func (f *fakeActivityContext) GetSetting(name string) interface{} { return nil }
+func (f *fakeActivityContext) GetInput(name string) interface{} {
+	if f.inputs == nil {
+		return nil
+	}
+	return f.inputs[name]
+}
+func (f *fakeActivityContext) SetOutput(name string, value interface{}) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	f.outputs[name] = value
+	return nil
+}
+func (f *fakeActivityContext) GetInputObject(obj data.StructValue) error {
+	return obj.FromMap(f.inputs)
+}
+func (f *fakeActivityContext) SetOutputObject(obj data.StructValue) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	for k, v := range obj.ToMap() {
+		f.outputs[k] = v
+	}
+	return nil
+}
+func (f *fakeActivityContext) GetSharedTempData() map[string]interface{} { return nil }
+func (f *fakeActivityContext) Logger() log.Logger                        { return log.RootLogger() }
+func (f *fakeActivityContext) GetTracingContext() trace.TracingContext   { return nil }
+func (f *fakeActivityContext) GoContext() context.Context                { return context.Background() }
+
+func newTestActivity(client vectordb.VectorDBClient, s *Settings) *Activity {
+	conn := vectordbconnector.NewConnectionForTest("test-conn",
+		client, &vectordbconnector.Settings{Host: "localhost", TimeoutSeconds: 5})
+	if s.SearchMode == "" {
+		s.SearchMode = searchModeVector
+	}
+	if s.DefaultTopK == 0 {
+		s.DefaultTopK = 3
+	}
+	if s.PrimaryMetric == "" {
+		s.PrimaryMetric = "mrr"
+	}
+	if s.ContentField == "" {
+		s.ContentField = "text"
+	}
+	s.TimeoutSeconds = 5
+	return &Activity{settings: s, conn: conn}
+}
+
+// twoQueries is a golden set with pre-computed vectors, so no embedding
+// provider is needed.
+func twoQueries() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"query": "q1", "queryVector": []interface{}{1.0, 0.0}, "expectedIds": []interface{}{"a"}},
+		map[string]interface{}{"query": "q2", "queryVector": []interface{}{0.0, 1.0}, "expectedIds": []interface{}{"d"}},
+	}
+}
+
+func isQuery(x float64) interface{} {
+	return mock.MatchedBy(func(r vectordb.SearchRequest) bool { return r.QueryVector[0] == x })
+}
+
+func TestEvaluate_VectorSearchMetrics(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, isQuery(1)).
+		Return([]vectordb.SearchResult{{ID: "a"}, {ID: "b"}, {ID: "c"}}, nil)
+	mc.On("VectorSearch", mock.Anything, isQuery(0)).
+		Return([]vectordb.SearchResult{{ID: "b"}, {ID: "d"}, {ID: "c"}}, nil)
+
+	a := newTestActivity(mc, &Settings{})
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName":  "col",
+		"goldenSet":       twoQueries(),
+		"kValues":         []interface{}{1.0, 3.0},
+		"includePerQuery": true,
+	}}
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, 2, ctx.outputs["queryCount"])
+
+	runs := ctx.outputs["runs"].([]interface{})
+	require.Len(t, runs, 1)
+	run := runs[0].(map[string]interface{})
+	assert.Equal(t, "vector topK=3", run["name"])
+	assert.Equal(t, ctx.outputs["bestRun"], run["name"])
+	metrics := run["metrics"].(map[string]interface{})
+	assert.InDelta(t, 0.75, metrics["mrr"], 1e-12)
+	assert.InDelta(t, 0.5, metrics["recall@1"], 1e-12)
+	assert.InDelta(t, 1.0, metrics["recall@3"], 1e-12)
+	assert.Equal(t, 0, run["failedQueries"])
+	assert.Len(t, run["queries"], 2)
+	assert.Contains(t, run["latency"], "p95Ms")
+	mc.AssertExpectations(t)
+}
+
+func TestEvaluate_GridComparison(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, mock.MatchedBy(func(r vectordb.SearchRequest) bool { return r.TopK == 1 })).
+		Return([]vectordb.SearchResult{{ID: "x"}}, nil)
+	mc.On("VectorSearch", mock.Anything, mock.MatchedBy(func(r vectordb.SearchRequest) bool { return r.TopK == 5 })).
+		Return([]vectordb.SearchResult{{ID: "x"}, {ID: "a"}, {ID: "d"}}, nil)
+	mc.On("HybridSearch", mock.Anything, mock.MatchedBy(func(r vectordb.HybridSearchRequest) bool {
+		return r.Alpha == 0.3 && r.QueryText != ""
+	})).Return([]vectordb.SearchResult{{ID: "a"}, {ID: "d"}}, nil)
+
+	a := newTestActivity(mc, &Settings{PrimaryMetric: "recall@1"})
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col",
+		"goldenSet":      twoQueries(),
+		"kValues":        []interface{}{1.0},
+		"parameterGrid": []interface{}{
+			map[string]interface{}{"topK": 1.0},
+			map[string]interface{}{"name": "wide", "topK": 5.0},
+			map[string]interface{}{"searchMode": "hybrid", "alpha": 0.3},
+		},
+	}}
+	_, err := a.Eval(ctx)
+	require.NoError(t, err)
+
+	runs := ctx.outputs["runs"].([]interface{})
+	require.Len(t, runs, 3)
+	names := []string{}
+	for _, r := range runs {
+		names = append(names, r.(map[string]interface{})["name"].(string))
+	}
+	assert.Equal(t, []string{"vector topK=1", "wide", "hybrid topK=3 alpha=0.3"}, names)
+	assert.Equal(t, "hybrid topK=3 alpha=0.3", ctx.outputs["bestRun"])
+	assert.NotContains(t, runs[0].(map[string]interface{}), "queries")
+}
+
+func TestEvaluate_RerankAndIDField(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Documents []string `json:"documents"`
+			TopN      int      `json:"top_n"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, 4, body.TopN)
+		// Rank the last candidate first.
+		results := []map[string]interface{}{}
+		for i := len(body.Documents) - 1; i >= 0; i-- {
+			results = append(results, map[string]interface{}{"index": i, "relevance_score": float64(i)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	defer srv.Close()
+
+	chunks := []vectordb.SearchResult{
+		{ID: "c1", Content: "one", Payload: map[string]interface{}{"docId": "x"}},
+		{ID: "c2", Content: "two", Payload: map[string]interface{}{"docId": "x"}},
+		{ID: "c3", Content: "three", Payload: map[string]interface{}{"docId": "y"}},
+		{ID: "c4", Content: "four", Payload: map[string]interface{}{"docId": "a"}},
+	}
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, mock.MatchedBy(func(r vectordb.SearchRequest) bool {
+		return !r.SkipPayload
+	})).Return(chunks, nil)
+
+	a := newTestActivity(mc, &Settings{DefaultTopK: 2, IDField: "docId", RerankEndpoint: srv.URL})
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName":  "col",
+		"goldenSet":       twoQueries()[:1],
+		"includePerQuery": true,
+		"parameterGrid": []interface{}{
+			map[string]interface{}{"name": "plain", "rerankCandidates": 4.0},
+			map[string]interface{}{"name": "reranked", "rerank": true, "rerankCandidates": 4.0},
+		},
+	}}
+	_, err := a.Eval(ctx)
+	require.NoError(t, err)
+	runs := ctx.outputs["runs"].([]interface{})
+
+	plain := runs[0].(map[string]interface{})
+	assert.Equal(t, 0.0, plain["metrics"].(map[string]interface{})["mrr"])
+	reranked := runs[1].(map[string]interface{})
+	assert.Equal(t, 1.0, reranked["metrics"].(map[string]interface{})["mrr"])
+	detail := reranked["queries"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"a", "y"}, detail["retrieved"], "chunks are mapped to distinct documents")
+	assert.Equal(t, "reranked", ctx.outputs["bestRun"])
+}
+
+func TestEvaluate_FailedQueriesAreCounted(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, isQuery(1)).Return([]vectordb.SearchResult{{ID: "a"}}, nil)
+	mc.On("VectorSearch", mock.Anything, isQuery(0)).Return([]vectordb.SearchResult(nil), errors.New("unavailable"))
+
+	a := newTestActivity(mc, &Settings{})
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"collectionName": "col", "goldenSet": twoQueries()}}
+	_, err := a.Eval(ctx)
+	require.NoError(t, err)
+	run := ctx.outputs["runs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 1, run["failedQueries"])
+	assert.Contains(t, run["error"], "unavailable")
+	assert.Equal(t, 1.0, run["metrics"].(map[string]interface{})["mrr"])
+}
+
+func TestEvaluate_Validation(t *testing.T) {
+	a := newTestActivity(&mockclient.VectorDBClient{}, &Settings{})
+	cases := map[string]map[string]interface{}{
+		"goldenSet is required":       {"collectionName": "col"},
+		"expectedIds or relevance":    {"collectionName": "col", "goldenSet": []interface{}{map[string]interface{}{"query": "q"}}},
+		"query or queryVector":        {"collectionName": "col", "goldenSet": []interface{}{map[string]interface{}{"expectedIds": []interface{}{"a"}}}},
+		"collectionName is required":  {"goldenSet": twoQueries()},
+		"searchMode must be":          {"collectionName": "col", "goldenSet": twoQueries(), "parameterGrid": []interface{}{map[string]interface{}{"searchMode": "bm25"}}},
+		"requires the rerankEndpoint": {"collectionName": "col", "goldenSet": twoQueries(), "parameterGrid": []interface{}{map[string]interface{}{"rerank": true}}},
+		"duplicate run name":          {"collectionName": "col", "goldenSet": twoQueries(), "parameterGrid": []interface{}{map[string]interface{}{}, map[string]interface{}{}}},
+	}
+	for want, inputs := range cases {
+		_, err := a.Eval(&fakeActivityContext{inputs: inputs})
+		assert.ErrorContains(t, err, want)
+	}
+
+	a.settings.PrimaryMetric = "ndcg@10"
+	_, err := a.Eval(&fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col", "goldenSet": twoQueries(), "kValues": []interface{}{2.0, 4.0},
+	}})
+	assert.ErrorContains(t, err, "primaryMetric must be mrr")
+}
+
+func TestEvaluate_EmbeddingRequiredWithoutVectors(t *testing.T) {
+	a := newTestActivity(&mockclient.VectorDBClient{}, &Settings{})
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col",
+		"goldenSet":      []interface{}{map[string]interface{}{"query": "q", "expectedIds": []interface{}{"a"}}},
+	}}
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, false, ctx.outputs["success"])
+	assert.Contains(t, ctx.outputs["error"], "embeddingModel is required")
+}
@@ -0,0 +1,236 @@
+package ingestDocuments
+
+// docextract_html.go — HTML (.html / .htm) extraction using golang.org/x/net/html,
+// the HTML5 parser, so unclosed tags and malformed markup are handled the way
+// browsers handle them.
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlSkipped are elements whose content is never document text.
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Nav: true, atom.Iframe: true,
+}
+
+// htmlBlocks are elements that start a new line.
+var htmlBlocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Blockquote: true, atom.Ul: true, atom.Ol: true, atom.Dl: true,
+	atom.Dt: true, atom.Dd: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Form: true, atom.Fieldset: true, atom.Address: true, atom.Hr: true,
+	atom.Caption: true, atom.Details: true, atom.Summary: true,
+}
+
+// extractHTML extracts an HTML page as Markdown-like text: <h1>–<h6> become
+// "#"–"######" headings, list items "- " lines, table rows "| a | b |" lines,
+// and <pre> blocks keep their line breaks. Navigation, scripts and styles are
+// dropped. The <title> is returned as the document-level "title" metadata.
+func extractHTML(data []byte) (*ExtractedDocument, error) {
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("html: parse error: %w", err)
+	}
+	w := &htmlWriter{}
+	w.walk(root)
+
+	doc := singleSection(pdfBlankCollapseRe.ReplaceAllString(string(w.buf), "\n\n"))
+	if title := htmlTitle(root); title != "" {
+		doc.Metadata = map[string]interface{}{"title": title}
+	}
+	return doc, nil
+}
+
+// htmlWriter accumulates the rendered text of an HTML tree.
+type htmlWriter struct {
+	buf   []byte
+	inPre int
+}
+
+func (w *htmlWriter) write(s string) { w.buf = append(w.buf, s...) }
+
+// text appends inline text, collapsing runs of whitespace outside <pre>.
+func (w *htmlWriter) text(s string) {
+	if w.inPre > 0 {
+		w.write(s)
+		return
+	}
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" {
+			w.space()
+		}
+		return
+	}
+	if isHTMLSpace(s[0]) {
+		w.space()
+	}
+	w.write(strings.Join(words, " "))
+	if isHTMLSpace(s[len(s)-1]) {
+		w.space()
+	}
+}
+
+func isHTMLSpace(b byte) bool { return b == ' ' || b == '\n' || b == '\t' || b == '\r' || b == '\f' }
+
+// space appends one space unless the output is empty or already ends in
+// whitespace.
+func (w *htmlWriter) space() {
+	if n := len(w.buf); n > 0 && w.buf[n-1] != ' ' && w.buf[n-1] != '\n' {
+		w.buf = append(w.buf, ' ')
+	}
+}
+
+// breakLines ends the current line and, when n is 2, leaves a blank line.
+func (w *htmlWriter) breakLines(n int) {
+	w.buf = bytes.TrimRight(w.buf, " ")
+	if len(w.buf) == 0 {
+		return
+	}
+	trailing := len(w.buf) - len(bytes.TrimRight(w.buf, "\n"))
+	for ; trailing < n; trailing++ {
+		w.buf = append(w.buf, '\n')
+	}
+}
+
+func (w *htmlWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+		if htmlSkipped[n.DataAtom] {
+			return
+		}
+		switch n.DataAtom {
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			if title := inlineText(n); title != "" {
+				w.breakLines(2)
+				w.write(strings.Repeat("#", int(n.Data[1]-'0')) + " " + title)
+				w.breakLines(1)
+			}
+			return
+		case atom.Table:
+			w.breakLines(1)
+			w.table(n)
+			w.breakLines(1)
+			return
+		case atom.Br:
+			w.breakLines(1)
+			return
+		case atom.Li:
+			w.breakLines(1)
+			w.write("- ")
+			w.children(n)
+			w.breakLines(1)
+			return
+		case atom.Pre:
+			w.breakLines(1)
+			w.inPre++
+			w.children(n)
+			w.inPre--
+			w.breakLines(1)
+			return
+		case atom.Img:
+			for _, a := range n.Attr {
+				if a.Key == "alt" && strings.TrimSpace(a.Val) != "" {
+					w.text(" " + a.Val + " ")
+				}
+			}
+			return
+		}
+		if htmlBlocks[n.DataAtom] {
+			w.breakLines(1)
+			w.children(n)
+			w.breakLines(1)
+			return
+		}
+	}
+	w.children(n)
+}
+
+func (w *htmlWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+}
+
+// table writes one "| a | b |" line per row of a table, including rows inside
+// <thead>/<tbody>/<tfoot> but not rows of nested tables, which are flattened
+// into their enclosing cell.
+func (w *htmlWriter) table(t *html.Node) {
+	var rows func(n *html.Node)
+	rows = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.DataAtom {
+			case atom.Tr:
+				var cells []string
+				for td := c.FirstChild; td != nil; td = td.NextSibling {
+					if td.DataAtom == atom.Td || td.DataAtom == atom.Th {
+						cells = append(cells, inlineText(td))
+					}
+				}
+				if line := tableRow(cells); line != "" {
+					w.write(line)
+					w.write("\n")
+				}
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				rows(c)
+			case atom.Caption:
+				if s := inlineText(c); s != "" {
+					w.write(s)
+					w.write("\n")
+				}
+			}
+		}
+	}
+	rows(t)
+}
+
+// inlineText returns the text content of n on one line.
+func inlineText(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode && htmlSkipped[n.DataAtom] {
+			return
+		}
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		// Block boundaries separate words; inline elements (<b>, <a>) do not.
+		sep := n.Type == html.ElementNode && (htmlBlocks[n.DataAtom] || n.DataAtom == atom.Br ||
+			n.DataAtom == atom.Li || n.DataAtom == atom.Td || n.DataAtom == atom.Th || n.DataAtom == atom.Tr)
+		if sep {
+			sb.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+		if sep {
+			sb.WriteByte(' ')
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// htmlTitle returns the text of the first <title> element.
+func htmlTitle(n *html.Node) string {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Title {
+		return inlineText(n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if t := htmlTitle(c); t != "" {
+			return t
+		}
+	}
+	return ""
+}
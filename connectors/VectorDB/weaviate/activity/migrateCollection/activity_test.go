@@ -0,0 +1,259 @@
+package migrateCollection
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate"
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/connector"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/testutil/mock"
+	vdbtransfer "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/transfer"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/core/support/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeActivityContext struct {
+	inputs  map[string]interface{}
+	outputs map[string]interface{}
+}
+
+func (f *fakeActivityContext) ActivityHost() activity.Host        { return nil }
+func (f *fakeActivityContext) Name() string                       { return "test" }
+func (f *fakeActivityContext) GetSetting(name string) interface{} { return nil }
+func (f *fakeActivityContext) GetInput(name string) interface{} {
+	if f.inputs == nil {
+		return nil
+	}
+	return f.inputs[name]
+}
+func (f *fakeActivityContext) SetOutput(name string, value interface{}) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	f.outputs[name] = value
+	return nil
+}
+func (f *fakeActivityContext) GetInputObject(obj data.StructValue) error {
+	return obj.FromMap(f.inputs)
+}
+func (f *fakeActivityContext) SetOutputObject(obj data.StructValue) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	for k, v := range obj.ToMap() {
+		f.outputs[k] = v
+	}
+	return nil
+}
+func (f *fakeActivityContext) GetSharedTempData() map[string]interface{} { return nil }
+func (f *fakeActivityContext) Logger() log.Logger                        { return log.RootLogger() }
+func (f *fakeActivityContext) GetTracingContext() trace.TracingContext   { return nil }
+func (f *fakeActivityContext) GoContext() context.Context                { return context.Background() }
+
+func newTestConn(client vectordb.VectorDBClient) *vectordbconnector.WeaviateConnection {
+	s := &vectordbconnector.Settings{Host: "localhost", TimeoutSeconds: 5}
+	return vectordbconnector.NewConnectionForTest("test-conn", client, s)
+}
+
+var testDocs = []vectordb.Document{
+	{ID: "a", Vector: []float64{1, 0}, Content: "alpha", Payload: map[string]interface{}{"lang": "en"}},
+	{ID: "b", Vector: []float64{0, 1}, Content: "beta"},
+}
+
+func testSettings() *Settings {
+	return &Settings{Direction: directionPush, BatchSize: 1, CreateCollection: true, DistanceMetric: "cosine", Verify: true, TimeoutSeconds: 30}
+}
+
+// source expects a two-page scroll of "kb" (plus the dimension probe) and a count.
+func source(dbType string) *mockclient.VectorDBClient {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("DBType").Return(dbType)
+	mc.On("ScrollDocuments", mock.Anything, mock.MatchedBy(func(r vectordb.ScrollRequest) bool {
+		return r.CollectionName == "kb" && r.Offset == "" && r.WithVectors
+	})).Return(&vectordb.ScrollResult{Documents: testDocs[:1], NextOffset: "c1"}, nil)
+	mc.On("ScrollDocuments", mock.Anything, mock.MatchedBy(func(r vectordb.ScrollRequest) bool {
+		return r.CollectionName == "kb" && r.Offset == "c1" && r.WithVectors
+	})).Return(&vectordb.ScrollResult{Documents: testDocs[1:]}, nil)
+	mc.On("CountDocuments", mock.Anything, "kb", map[string]interface{}(nil)).Return(int64(2), nil)
+	return mc
+}
+
+// target expects "kb-copy" to be created and to receive both documents.
+func target(dbType string) *mockclient.VectorDBClient {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("DBType").Return(dbType)
+	mc.On("CollectionExists", mock.Anything, "kb-copy").Return(false, nil)
+	mc.On("CreateCollection", mock.Anything, vectordb.CollectionConfig{Name: "kb-copy", Dimensions: 2, DistanceMetric: "cosine"}).Return(nil)
+	mc.On("UpsertDocuments", mock.Anything, "kb-copy", testDocs[:1]).Return(nil).Once()
+	mc.On("UpsertDocuments", mock.Anything, "kb-copy", testDocs[1:]).Return(nil).Once()
+	mc.On("CountDocuments", mock.Anything, "kb-copy", map[string]interface{}(nil)).Return(int64(2), nil)
+	return mc
+}
+
+func TestMigrateCollection_PushAndPull(t *testing.T) {
+	for _, direction := range []string{directionPush, directionPull} {
+		t.Run(direction, func(t *testing.T) {
+			src, dst := source("weaviate"), target("qdrant")
+			own, peer := src, dst
+			if direction == directionPull {
+				own, peer = dst, src
+			}
+			s := testSettings()
+			s.Direction = direction
+			a := &Activity{conn: newTestConn(own), peer: peer, settings: s}
+			ctx := &fakeActivityContext{inputs: map[string]interface{}{
+				"sourceCollection": "kb", "targetCollection": "kb-copy", "sourceDistanceMetric": "COSINE",
+			}}
+			ok, err := a.Eval(ctx)
+			require.True(t, ok)
+			require.NoError(t, err)
+			assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+			assert.Equal(t, int64(2), ctx.outputs["migratedCount"])
+			assert.Equal(t, 2, ctx.outputs["dimensions"])
+			assert.Equal(t, true, ctx.outputs["complete"])
+			assert.Equal(t, true, ctx.outputs["created"])
+			assert.Equal(t, true, ctx.outputs["verified"])
+			assert.Equal(t, int64(2), ctx.outputs["sourceCount"])
+			src.AssertExpectations(t)
+			dst.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMigrateCollection_ResumesFromCheckpoint(t *testing.T) {
+	cpPath := filepath.Join(t.TempDir(), "migrate.json")
+	require.NoError(t, vdbtransfer.WriteCheckpoint(cpPath, &vdbtransfer.Checkpoint{
+		Source: "weaviate:kb", Target: "qdrant:kb-copy", Cursor: "c1", Documents: 1, Dimensions: 2,
+	}))
+
+	src := &mockclient.VectorDBClient{}
+	src.On("DBType").Return("weaviate")
+	src.On("ScrollDocuments", mock.Anything, mock.MatchedBy(func(r vectordb.ScrollRequest) bool { return r.Offset == "c1" })).
+		Return(&vectordb.ScrollResult{Documents: testDocs[1:]}, nil)
+	dst := &mockclient.VectorDBClient{}
+	dst.On("DBType").Return("qdrant")
+	dst.On("CollectionExists", mock.Anything, "kb-copy").Return(true, nil)
+	dst.On("ScrollDocuments", mock.Anything, mock.Anything).Return(&vectordb.ScrollResult{Documents: testDocs[:1]}, nil)
+	dst.On("UpsertDocuments", mock.Anything, "kb-copy", testDocs[1:]).Return(nil).Once()
+
+	s := testSettings()
+	s.Verify = false
+	s.CheckpointPath = cpPath
+	a := &Activity{conn: newTestConn(src), peer: dst, settings: s}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"sourceCollection": "kb", "targetCollection": "kb-copy", "sourceDistanceMetric": "cosine",
+	}}
+	_, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, int64(1), ctx.outputs["migratedCount"])
+	assert.Equal(t, int64(2), ctx.outputs["documentCount"])
+	assert.Equal(t, false, ctx.outputs["created"])
+	src.AssertExpectations(t)
+	dst.AssertExpectations(t)
+
+	cp, err := vdbtransfer.ReadCheckpoint(cpPath)
+	require.NoError(t, err)
+	assert.True(t, cp.Complete)
+	assert.Equal(t, int64(2), cp.Documents)
+}
+
+func TestMigrateCollection_UpsertErrorReturnsCursor(t *testing.T) {
+	src := source("weaviate")
+	dst := &mockclient.VectorDBClient{}
+	dst.On("DBType").Return("qdrant")
+	dst.On("CollectionExists", mock.Anything, "kb").Return(true, nil)
+	dst.On("ScrollDocuments", mock.Anything, mock.Anything).Return(&vectordb.ScrollResult{}, nil)
+	dst.On("UpsertDocuments", mock.Anything, "kb", testDocs[:1]).Return(nil).Once()
+	dst.On("UpsertDocuments", mock.Anything, "kb", testDocs[1:]).Return(assert.AnError).Once()
+
+	a := &Activity{conn: newTestConn(src), peer: dst, settings: testSettings()}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"sourceCollection": "kb", "sourceDistanceMetric": "cosine", "resumeCursor": ""}}
+	ok, err := a.Eval(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, false, ctx.outputs["success"])
+	assert.Equal(t, "c1", ctx.outputs["cursor"])
+	assert.Equal(t, int64(1), ctx.outputs["migratedCount"])
+}
+
+func TestMigrateCollection_Validation(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("DBType").Return("weaviate")
+	mc.On("CollectionExists", mock.Anything, "kb").Return(false, nil)
+
+	a := &Activity{conn: newTestConn(mc), peer: mc, settings: testSettings()}
+	_, err := a.Eval(&fakeActivityContext{inputs: map[string]interface{}{}})
+	assert.Error(t, err, "sourceCollection is required")
+
+	a.sameConn = true
+	_, err = a.Eval(&fakeActivityContext{inputs: map[string]interface{}{"sourceCollection": "kb"}})
+	assert.Error(t, err, "copying a collection onto itself")
+
+	a.sameConn = false
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"sourceCollection": "kb", "sourceDistanceMetric": "dot"}}
+	_, err = a.Eval(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, false, ctx.outputs["success"])
+	assert.Contains(t, ctx.outputs["error"], "distance metric mismatch")
+}
+
+// metricClient is a client that reports the metric of its collections.
+type metricClient struct {
+	*mockclient.VectorDBClient
+	metric string
+}
+
+func (c metricClient) CollectionMetric(context.Context, string) (string, error) { return c.metric, nil }
+
+func TestMigrateCollection_ChecksExistingTargetMetric(t *testing.T) {
+	cases := map[string]struct {
+		sourceMetric string
+		src, dst     vectordb.VectorDBClient
+		want         string
+	}{
+		"target metric differs from the setting": {
+			sourceMetric: "cosine",
+			src:          &mockclient.VectorDBClient{},
+			dst:          metricClient{VectorDBClient: &mockclient.VectorDBClient{}, metric: "dot"},
+			want:         "distance metric mismatch: source uses cosine, target uses dot",
+		},
+		"source metric read from the collection": {
+			src:  metricClient{VectorDBClient: &mockclient.VectorDBClient{}, metric: "euclidean"},
+			dst:  metricClient{VectorDBClient: &mockclient.VectorDBClient{}, metric: "cosine"},
+			want: "distance metric mismatch: source uses euclidean, target uses cosine",
+		},
+		"neither metric known": {
+			src:  &mockclient.VectorDBClient{},
+			dst:  &mockclient.VectorDBClient{},
+			want: "cannot determine the distance metric",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, c := range []vectordb.VectorDBClient{tc.src, tc.dst} {
+				mc, ok := c.(*mockclient.VectorDBClient)
+				if !ok {
+					mc = c.(metricClient).VectorDBClient
+				}
+				mc.On("DBType").Return("weaviate")
+				mc.On("CollectionExists", mock.Anything, "kb-copy").Return(true, nil)
+			}
+			a := &Activity{conn: newTestConn(tc.src), peer: tc.dst, settings: testSettings()}
+			ctx := &fakeActivityContext{inputs: map[string]interface{}{
+				"sourceCollection": "kb", "targetCollection": "kb-copy", "sourceDistanceMetric": tc.sourceMetric,
+			}}
+			ok, err := a.Eval(ctx)
+			assert.True(t, ok)
+			assert.NoError(t, err)
+			assert.Equal(t, false, ctx.outputs["success"])
+			assert.Contains(t, ctx.outputs["error"], tc.want)
+		})
+	}
+}
@@ -15,7 +15,11 @@ var core_1 = require("@angular/core"),
     // Note: systemPrompt is intentionally excluded — both the design-time default (settings)
     // and the per-request override (input) are always visible so users can prepare/override
     // the prompt regardless of whether LLM generation is currently enabled.
-    LLM_FIELDS = ["llmProvider", "llmBaseURL", "llmAPIKey", "llmModel", "maxTokens", "temperature"],
+    LLM_FIELDS = ["enableCitations", "enableStreaming", "enableSemanticCache"],
+
+    // These fields are visible whenever the LLM is used: for generation, query
+    // rewriting, or the multiQuery / hyde retrieval modes
+    LLM_CONNECTION_FIELDS = ["llmProvider", "llmBaseURL", "llmAPIKey", "llmModel", "maxTokens", "temperature"],
 
     RAGQueryActivityHandler = function (t) {
         function e(e, i) {
@@ -60,6 +64,48 @@ var core_1 = require("@angular/core"),
                     return wi_contrib_1.ValidationResult.newValidationResult().setVisible(hybrid);
                 }
 
+                // --- Azure api-version: only relevant for Azure OpenAI ---
+                if (fieldName === "llmAPIVersion") {
+                    var azure = n.getContextVar(ctx, "llmProvider") === "Azure OpenAI";
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible(n.isLLMUsed(ctx) && azure);
+                }
+
+                // --- SSE server: only relevant when streaming is enabled ---
+                if (fieldName === "sseServerRef") {
+                    var llmGenS = n.getContextVar(ctx, "enableLLMGenerate");
+                    var streaming = n.getContextVar(ctx, "enableStreaming");
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible((llmGenS === true || llmGenS === "true") && (streaming === true || streaming === "true"));
+                }
+
+                // --- MMR tuning: only relevant when MMR is enabled ---
+                if (fieldName === "mmrLambda" || fieldName === "mmrFetchK") {
+                    var mmr = n.getContextVar(ctx, "enableMMR");
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible(mmr === true || mmr === "true");
+                }
+
+                // --- Neighbor chunks: only relevant for neighbor expansion ---
+                if (fieldName === "neighborChunks") {
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible(n.getContextVar(ctx, "contextExpansion") === "neighbors");
+                }
+
+                // --- Semantic cache tuning: only relevant when the cache is enabled ---
+                if (fieldName === "cacheCollection" || fieldName === "cacheSimilarityThreshold" || fieldName === "cacheTTLSeconds") {
+                    var llmGenC = n.getContextVar(ctx, "enableLLMGenerate");
+                    var cache = n.getContextVar(ctx, "enableSemanticCache");
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible((llmGenC === true || llmGenC === "true") && (cache === true || cache === "true"));
+                }
+
+                // --- Query variants: only relevant for multi-query retrieval ---
+                if (fieldName === "queryVariants") {
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible(n.getContextVar(ctx, "retrievalMode") === "multiQuery");
+                }
+
+                // --- LLM connection fields: visible whenever the LLM is called, i.e. for
+                // generation, query rewriting or the multiQuery / hyde retrieval modes ---
+                if (LLM_CONNECTION_FIELDS.indexOf(fieldName) !== -1) {
+                    return wi_contrib_1.ValidationResult.newValidationResult().setVisible(n.isLLMUsed(ctx));
+                }
+
                 // --- LLM fields: only visible when enableLLMGenerate=true ---
                 if (LLM_FIELDS.indexOf(fieldName) !== -1) {
                     var enableLLM = n.getContextVar(ctx, "enableLLMGenerate");
@@ -76,6 +122,12 @@ var core_1 = require("@angular/core"),
         e.prototype.getContextVar = function (ctx, name) {
             return ctx.getField(name) ? void 0 === ctx.getField(name).value ? "" : ctx.getField(name).value : "";
         };
+        e.prototype.isLLMUsed = function (ctx) {
+            var gen = this.getContextVar(ctx, "enableLLMGenerate");
+            var rewrite = this.getContextVar(ctx, "enableQueryRewrite");
+            var mode = this.getContextVar(ctx, "retrievalMode");
+            return gen === true || gen === "true" || rewrite === true || rewrite === "true" || (mode !== "" && mode !== "standard");
+        };
         e = __decorate([wi_contrib_1.WiContrib({}), core_1.Injectable(), __metadata("design:paramtypes", [core_1.Injector, http_1.Http])], e);
         return e;
     }(wi_contrib_1.WiServiceHandlerContribution);
@@ -0,0 +1,132 @@
+package ragQuery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate"
+	vdbsemcache "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/semcache"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/testutil/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheSettings(embedURL, llmURL string) *Settings {
+	return &Settings{
+		EmbeddingProvider:        "OpenAI",
+		EmbeddingBaseURL:         embedURL,
+		EmbeddingModel:           "text-embedding-3-small",
+		DefaultCollection:        "docs",
+		DefaultTopK:              2,
+		ContentField:             "text",
+		ContextFormat:            "plain",
+		TimeoutSeconds:           10,
+		EnableLLMGenerate:        true,
+		LLMProvider:              "Ollama",
+		LLMBaseURL:               llmURL,
+		EnableSemanticCache:      true,
+		CacheCollection:          vdbsemcache.DefaultCollection,
+		CacheSimilarityThreshold: vdbsemcache.DefaultThreshold,
+		CacheTTLSeconds:          3600,
+	}
+}
+
+// testNamespace is the answer cache namespace of cacheSettings for the
+// "docs" collection without filters.
+var testNamespace = cacheNamespace("docs", nil, "", "")
+
+func onCollection(name string) interface{} {
+	return mock.MatchedBy(func(r vectordb.SearchRequest) bool { return r.CollectionName == name })
+}
+
+func TestRAGQuery_SemanticCacheHit(t *testing.T) {
+	embedServer := makeEmbedServer([]float64{1, 0})
+	defer embedServer.Close()
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the LLM must not be called on a cache hit")
+	}))
+	defer llm.Close()
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, onCollection("docs")).Return([]vectordb.SearchResult{
+		{ID: "d1", Score: 0.9, Payload: map[string]interface{}{"text": "Flogo apps are event-driven."}},
+	}, nil)
+	mc.On("CollectionExists", mock.Anything, vdbsemcache.DefaultCollection).Return(true, nil)
+	mc.On("VectorSearch", mock.Anything, mock.MatchedBy(func(r vectordb.SearchRequest) bool {
+		return r.CollectionName == vdbsemcache.DefaultCollection && r.Filters[vdbsemcache.NamespaceKey] == testNamespace
+	})).Return([]vectordb.SearchResult{{
+		ID: "c1", Score: 0.97, Content: "What is Flogo?",
+		Payload: map[string]interface{}{
+			vdbsemcache.AnswerKey:    "An event-driven app framework [1].",
+			vdbsemcache.NamespaceKey: testNamespace,
+			vdbsemcache.ExpiresAtKey: int64(0),
+		},
+	}}, nil)
+
+	a := &Activity{conn: newTestConn(mc), settings: cacheSettings(embedServer.URL, llm.URL)}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "what is flogo"}}
+
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, true, ctx.outputs["cacheHit"])
+	assert.Equal(t, "An event-driven app framework [1].", ctx.outputs["answer"])
+	mc.AssertExpectations(t)
+	mc.AssertNotCalled(t, "UpsertDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRAGQuery_SemanticCacheMissStoresAnswer(t *testing.T) {
+	embedServer := makeEmbedServer([]float64{1, 0})
+	defer embedServer.Close()
+	llm, _, _ := captureServer(t, `{"response":"Flogo is event-driven.","done":true}`)
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("VectorSearch", mock.Anything, onCollection("docs")).Return([]vectordb.SearchResult{
+		{ID: "d1#0", Score: 0.9, Payload: map[string]interface{}{"text": "Flogo apps are event-driven.", "parentId": "d1"}},
+	}, nil)
+	mc.On("CollectionExists", mock.Anything, vdbsemcache.DefaultCollection).Return(true, nil)
+	mc.On("VectorSearch", mock.Anything, onCollection(vdbsemcache.DefaultCollection)).Return([]vectordb.SearchResult{}, nil)
+	mc.On("DeleteByFilter", mock.Anything, vdbsemcache.DefaultCollection, mock.Anything).Return(int64(0), nil)
+	mc.On("UpsertDocuments", mock.Anything, vdbsemcache.DefaultCollection, mock.MatchedBy(func(docs []vectordb.Document) bool {
+		if len(docs) != 1 {
+			return false
+		}
+		p := docs[0].Payload
+		return docs[0].Content == "what is flogo" &&
+			p[vdbsemcache.AnswerKey] == "Flogo is event-driven." &&
+			p[vdbsemcache.NamespaceKey] == testNamespace &&
+			assert.ObjectsAreEqual([]string{"d1"}, p[vdbsemcache.SourceIDsKey])
+	})).Return(nil)
+
+	a := &Activity{conn: newTestConn(mc), settings: cacheSettings(embedServer.URL, llm.URL)}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "what is flogo"}}
+
+	ok, err := a.Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, false, ctx.outputs["cacheHit"])
+	assert.Equal(t, "Flogo is event-driven.", ctx.outputs["answer"])
+	mc.AssertExpectations(t)
+}
+
+func TestCacheNamespace(t *testing.T) {
+	base := cacheNamespace("docs", nil, "llama3", "Answer briefly.")
+	assert.Equal(t, base, cacheNamespace("docs", map[string]interface{}{}, "llama3", "Answer briefly."))
+	assert.Equal(t, base+` {"a":1,"b":"x"}`, cacheNamespace("docs", map[string]interface{}{"b": "x", "a": 1}, "llama3", "Answer briefly."))
+	assert.NotEqual(t, base, cacheNamespace("other", nil, "llama3", "Answer briefly."))
+	assert.NotEqual(t, base, cacheNamespace("docs", nil, "mistral", "Answer briefly."))
+	assert.NotEqual(t, base, cacheNamespace("docs", nil, "llama3", "Answer in French."))
+}
+
+func TestCacheSourceIDs(t *testing.T) {
+	ids := cacheSourceIDs([]vectordb.SearchResult{
+		{ID: "p1#0", Payload: map[string]interface{}{"parentId": "p1"}},
+		{ID: "p1#1", Payload: map[string]interface{}{"parentId": "p1"}},
+		{ID: "doc2"},
+	})
+	assert.Equal(t, []string{"p1", "doc2"}, ids)
+}
@@ -0,0 +1,66 @@
+package ragQuery
+
+import (
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var citationDocs = []vectordb.SearchResult{
+	{ID: "doc-a", Payload: map[string]interface{}{"text": "Flogo is an event-driven integration framework written in Go."}},
+	{ID: "doc-b", Payload: map[string]interface{}{"text": "Weaviate stores vectors and payloads for similarity search."}},
+}
+
+func TestExtractCitations_Markers(t *testing.T) {
+	answer := "Flogo is written in Go. [1] Weaviate stores vectors [2][1]. Unsupported claim [9]."
+	cs := extractCitations(answer, citationDocs, "text")
+	require.Len(t, cs, 2)
+
+	assert.Equal(t, "Flogo is written in Go.", cs[0].Sentence)
+	assert.Equal(t, []string{"doc-a"}, cs[0].SourceIDs)
+	assert.Equal(t, []int{1}, cs[0].SourceIndexes)
+	assert.Equal(t, "marker", cs[0].Method)
+	assert.Equal(t, 0, cs[0].Start)
+	assert.Equal(t, len("Flogo is written in Go. [1]"), cs[0].End)
+
+	assert.Equal(t, "Weaviate stores vectors.", cs[1].Sentence)
+	assert.Equal(t, []string{"doc-b", "doc-a"}, cs[1].SourceIDs)
+	assert.Equal(t, []int{2, 1}, cs[1].SourceIndexes)
+}
+
+func TestExtractCitations_OverlapFallback(t *testing.T) {
+	answer := "Weaviate stores vectors for similarity search.\nThe weather is nice today."
+	cs := extractCitations(answer, citationDocs, "text")
+	require.Len(t, cs, 1, "sentences without support are omitted")
+	assert.Equal(t, "overlap", cs[0].Method)
+	assert.Equal(t, []string{"doc-b"}, cs[0].SourceIDs)
+	assert.Equal(t, []int{2}, cs[0].SourceIndexes)
+}
+
+func TestExtractCitations_RuneOffsets(t *testing.T) {
+	answer := "Größe matters. [1] Flogo runs on Go. [1]"
+	cs := extractCitations(answer, citationDocs, "text")
+	require.Len(t, cs, 2)
+	runes := []rune(answer)
+	assert.Equal(t, "Flogo runs on Go. [1]", string(runes[cs[1].Start:cs[1].End]))
+}
+
+func TestExtractCitations_Empty(t *testing.T) {
+	assert.Nil(t, extractCitations("", citationDocs, "text"))
+	assert.Nil(t, extractCitations("Something. [1]", nil, "text"))
+}
+
+func TestCitationsToInterface(t *testing.T) {
+	out := citationsToInterface([]Citation{{Sentence: "s", Start: 0, End: 5, SourceIDs: []string{"x"}, SourceIndexes: []int{1}, Method: "marker"}})
+	require.Len(t, out, 1)
+	assert.Equal(t, map[string]interface{}{
+		"sentence":      "s",
+		"start":         0,
+		"end":           5,
+		"sourceIds":     []interface{}{"x"},
+		"sourceIndexes": []interface{}{1},
+		"method":        "marker",
+	}, out[0])
+}
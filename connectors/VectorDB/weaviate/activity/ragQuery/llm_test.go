@@ -0,0 +1,237 @@
+package ragQuery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureServer records the last request and replies with body. Streamed
+// bodies are written line by line with a flush after each.
+func captureServer(t *testing.T, lines ...string) (*httptest.Server, *http.Request, map[string]interface{}) {
+	t.Helper()
+	var gotReq http.Request
+	gotBody := map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = *r.Clone(context.Background())
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		f, _ := w.(http.Flusher)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+			if f != nil {
+				f.Flush()
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &gotReq, gotBody
+}
+
+func collectTokens(tokens *[]string) func(string) {
+	return func(s string) { *tokens = append(*tokens, s) }
+}
+
+func TestGenerate_Ollama(t *testing.T) {
+	srv, req, body := captureServer(t, `{"response":" Flogo is fast. ","done":true}`)
+	a := &Activity{settings: &Settings{LLMProvider: "Ollama", LLMBaseURL: srv.URL, LLMModel: "llama3", MaxTokens: 64, Temperature: 0.2}}
+
+	answer, err := a.generate(context.Background(), "what?", "ctx", "sys", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Flogo is fast.", answer)
+	assert.Equal(t, "/api/generate", req.URL.Path)
+	assert.Equal(t, "sys\n\nContext:\nctx\n\nQuestion: what?\n\nAnswer:", body["prompt"])
+	assert.Equal(t, false, body["stream"])
+	assert.Equal(t, map[string]interface{}{"num_predict": float64(64), "temperature": 0.2}, body["options"])
+}
+
+func TestGenerate_OllamaStream(t *testing.T) {
+	srv, _, body := captureServer(t,
+		`{"response":"Hel","done":false}`,
+		`{"response":"lo","done":false}`,
+		`{"response":"","done":true}`,
+	)
+	a := &Activity{settings: &Settings{LLMProvider: "Ollama", LLMBaseURL: srv.URL}}
+
+	var tokens []string
+	answer, err := a.generate(context.Background(), "q", "c", "", collectTokens(&tokens))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", answer)
+	assert.Equal(t, []string{"Hel", "lo"}, tokens)
+	assert.Equal(t, true, body["stream"])
+}
+
+func TestGenerate_OpenAISystemMessage(t *testing.T) {
+	srv, req, body := captureServer(t, `{"choices":[{"message":{"role":"assistant","content":"42"}}]}`)
+	a := &Activity{settings: &Settings{LLMProvider: "OpenAI", LLMBaseURL: srv.URL, LLMAPIKey: "sk-x", LLMModel: "gpt-4o-mini"}}
+
+	answer, err := a.generate(context.Background(), "q", "c", "be brief", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "42", answer)
+	assert.Equal(t, "/v1/chat/completions", req.URL.Path)
+	assert.Equal(t, "Bearer sk-x", req.Header.Get("Authorization"))
+	assert.Equal(t, "gpt-4o-mini", body["model"])
+	msgs := body["messages"].([]interface{})
+	require.Len(t, msgs, 2)
+	assert.Equal(t, map[string]interface{}{"role": "system", "content": "be brief"}, msgs[0])
+	assert.Equal(t, "user", msgs[1].(map[string]interface{})["role"])
+}
+
+func TestGenerate_OpenAIStream(t *testing.T) {
+	srv, _, _ := captureServer(t,
+		`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"content":"A"}}]}`,
+		`data: {"choices":[{"delta":{"content":"B"}}]}`,
+		`data: [DONE]`,
+	)
+	a := &Activity{settings: &Settings{LLMProvider: "Custom", LLMBaseURL: srv.URL}}
+
+	var tokens []string
+	answer, err := a.generate(context.Background(), "q", "c", "", collectTokens(&tokens))
+	require.NoError(t, err)
+	assert.Equal(t, "AB", answer)
+	assert.Equal(t, []string{"A", "B"}, tokens)
+}
+
+func TestGenerate_AzureOpenAI(t *testing.T) {
+	srv, req, body := captureServer(t, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	a := &Activity{settings: &Settings{LLMProvider: "Azure OpenAI", LLMBaseURL: srv.URL + "/", LLMAPIKey: "azkey", LLMModel: "gpt4o-deploy"}}
+
+	answer, err := a.generate(context.Background(), "q", "c", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", answer)
+	assert.Equal(t, "/openai/deployments/gpt4o-deploy/chat/completions", req.URL.Path)
+	assert.Equal(t, defaultAzureLLMAPIVersion, req.URL.Query().Get("api-version"))
+	assert.Equal(t, "azkey", req.Header.Get("api-key"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+	assert.NotContains(t, body, "model")
+}
+
+func TestOpenAIChatEndpoint_AzureDeploymentURL(t *testing.T) {
+	endpoint, _ := openAIChatEndpoint(&Settings{
+		LLMProvider:   "Azure OpenAI",
+		LLMBaseURL:    "https://r.openai.azure.com/openai/deployments/d1?api-version=2024-06-01",
+		LLMAPIVersion: "ignored",
+	})
+	assert.Equal(t, "https://r.openai.azure.com/openai/deployments/d1/chat/completions?api-version=2024-06-01", endpoint)
+
+	endpoint, _ = openAIChatEndpoint(&Settings{
+		LLMProvider:   "Azure OpenAI",
+		LLMBaseURL:    "https://r.openai.azure.com",
+		LLMModel:      "d2",
+		LLMAPIVersion: "2025-01-01-preview",
+	})
+	assert.Equal(t, "https://r.openai.azure.com/openai/deployments/d2/chat/completions?api-version=2025-01-01-preview", endpoint)
+}
+
+func TestGenerate_Anthropic(t *testing.T) {
+	srv, req, body := captureServer(t, `{"content":[{"type":"text","text":"Paris."}],"stop_reason":"end_turn"}`)
+	a := &Activity{settings: &Settings{LLMProvider: "Anthropic", LLMBaseURL: srv.URL, LLMAPIKey: "ant-key", LLMModel: "claude-sonnet-4-5"}}
+
+	answer, err := a.generate(context.Background(), "capital?", "c", "sys", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Paris.", answer)
+	assert.Equal(t, "/v1/messages", req.URL.Path)
+	assert.Equal(t, "ant-key", req.Header.Get("x-api-key"))
+	assert.Equal(t, anthropicAPIVersion, req.Header.Get("anthropic-version"))
+	assert.Equal(t, "sys", body["system"])
+	assert.Equal(t, float64(1024), body["max_tokens"], "max_tokens is required by the API")
+	msgs := body["messages"].([]interface{})
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "user", msgs[0].(map[string]interface{})["role"])
+}
+
+func TestGenerate_AnthropicStream(t *testing.T) {
+	srv, _, _ := captureServer(t,
+		`event: message_start`,
+		`data: {"type":"message_start","message":{"id":"m1"}}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Par"}}`,
+		``,
+		`event: ping`,
+		`data: {"type":"ping"}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"is."}}`,
+		``,
+		`event: message_stop`,
+		`data: {"type":"message_stop"}`,
+	)
+	a := &Activity{settings: &Settings{LLMProvider: "Anthropic", LLMBaseURL: srv.URL}}
+
+	var tokens []string
+	answer, err := a.generate(context.Background(), "q", "c", "", collectTokens(&tokens))
+	require.NoError(t, err)
+	assert.Equal(t, "Paris.", answer)
+	assert.Equal(t, []string{"Par", "is."}, tokens)
+}
+
+func TestGenerate_AnthropicStreamError(t *testing.T) {
+	srv, _, _ := captureServer(t,
+		`event: error`,
+		`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+	)
+	a := &Activity{settings: &Settings{LLMProvider: "Anthropic", LLMBaseURL: srv.URL}}
+
+	_, err := a.generate(context.Background(), "q", "c", "", func(string) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Overloaded")
+}
+
+func TestGenerate_Cohere(t *testing.T) {
+	srv, req, body := captureServer(t, `{"message":{"role":"assistant","content":[{"type":"text","text":"Yes."}]}}`)
+	a := &Activity{settings: &Settings{LLMProvider: "Cohere", LLMBaseURL: srv.URL, LLMAPIKey: "co-key", LLMModel: "command-r-plus"}}
+
+	answer, err := a.generate(context.Background(), "q", "c", "sys", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Yes.", answer)
+	assert.Equal(t, "/v2/chat", req.URL.Path)
+	assert.Equal(t, "Bearer co-key", req.Header.Get("Authorization"))
+	assert.Len(t, body["messages"], 2)
+}
+
+func TestGenerate_CohereStream(t *testing.T) {
+	srv, _, _ := captureServer(t,
+		`event: message-start`,
+		`data: {"type":"message-start"}`,
+		`event: content-delta`,
+		`data: {"type":"content-delta","index":0,"delta":{"message":{"content":{"text":"Ye"}}}}`,
+		`event: content-delta`,
+		`data: {"type":"content-delta","index":0,"delta":{"message":{"content":{"text":"s."}}}}`,
+		`event: message-end`,
+		`data: {"type":"message-end"}`,
+	)
+	a := &Activity{settings: &Settings{LLMProvider: "Cohere", LLMBaseURL: srv.URL}}
+
+	var tokens []string
+	answer, err := a.generate(context.Background(), "q", "c", "", collectTokens(&tokens))
+	require.NoError(t, err)
+	assert.Equal(t, "Yes.", answer)
+	assert.Equal(t, []string{"Ye", "s."}, tokens)
+}
+
+func TestGenerate_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"bad key"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	a := &Activity{settings: &Settings{LLMProvider: "Anthropic", LLMBaseURL: srv.URL}}
+
+	_, err := a.generate(context.Background(), "q", "c", "", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "anthropic: status 401")
+}
+
+func TestLLMBaseURL_Defaults(t *testing.T) {
+	assert.Equal(t, "https://api.anthropic.com", llmBaseURL(&Settings{LLMProvider: "Anthropic"}))
+	assert.Equal(t, "https://api.cohere.com", llmBaseURL(&Settings{LLMProvider: "Cohere"}))
+	assert.Equal(t, "http://proxy:8080", llmBaseURL(&Settings{LLMProvider: "Anthropic", LLMBaseURL: "http://proxy:8080/"}))
+	assert.Equal(t, "", llmBaseURL(&Settings{LLMProvider: "Azure OpenAI"}))
+}
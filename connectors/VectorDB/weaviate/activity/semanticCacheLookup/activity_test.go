@@ -0,0 +1,201 @@
+package semanticCacheLookup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate"
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/connector"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/testutil/mock"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/core/support/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// fake activity context
+// ---------------------------------------------------------------------------
+
+type fakeActivityContext struct {
+	inputs  map[string]interface{}
+	outputs map[string]interface{}
+}
+
+func (f *fakeActivityContext) ActivityHost() activity.Host        { return nil }
+func (f *fakeActivityContext) Name() string                       { return "test" }
+func (f *fakeActivityContext) GetSetting(name string) interface{} { return nil }
+func (f *fakeActivityContext) GetInput(name string) interface{} {
+	if f.inputs == nil {
+		return nil
+	}
+	return f.inputs[name]
+}
+func (f *fakeActivityContext) SetOutput(name string, value interface{}) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	f.outputs[name] = value
+	return nil
+}
+func (f *fakeActivityContext) GetInputObject(obj data.StructValue) error {
+	return obj.FromMap(f.inputs)
+}
+func (f *fakeActivityContext) SetOutputObject(obj data.StructValue) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	for k, v := range obj.ToMap() {
+		f.outputs[k] = v
+	}
+	return nil
+}
+func (f *fakeActivityContext) GetSharedTempData() map[string]interface{} { return nil }
+func (f *fakeActivityContext) Logger() log.Logger                        { return log.RootLogger() }
+func (f *fakeActivityContext) GetTracingContext() trace.TracingContext   { return nil }
+func (f *fakeActivityContext) GoContext() context.Context                { return context.Background() }
+
+// ---------------------------------------------------------------------------
+// local JSON fixture types (mirrors of vdbembed unexported response structs)
+// ---------------------------------------------------------------------------
+
+type openAIEmbedData struct {
+	Embedding []float64 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+type openAIEmbedUsage struct {
+	TotalTokens int `json:"total_tokens"`
+}
+type openAIEmbedResponse struct {
+	Data  []openAIEmbedData `json:"data"`
+	Usage openAIEmbedUsage  `json:"usage"`
+}
+
+// ---------------------------------------------------------------------------
+// helpers
+// ---------------------------------------------------------------------------
+
+func makeEmbedServer(vec []float64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openAIEmbedResponse{
+			Data:  []openAIEmbedData{{Embedding: vec, Index: 0}},
+			Usage: openAIEmbedUsage{TotalTokens: 10},
+		})
+	}))
+}
+
+func newTestConn(client vectordb.VectorDBClient) *vectordbconnector.WeaviateConnection {
+	s := &vectordbconnector.Settings{Host: "localhost", TimeoutSeconds: 5}
+	return vectordbconnector.NewConnectionForTest("test-conn", client, s)
+}
+
+// ---------------------------------------------------------------------------
+// tests
+// ---------------------------------------------------------------------------
+
+func cachedEntry(score float64) vectordb.SearchResult {
+	return vectordb.SearchResult{
+		ID:      "entry-1",
+		Score:   score,
+		Content: "How do I reset my password?",
+		Payload: map[string]interface{}{
+			"answer":    "Use the reset link on the sign-in page.",
+			"namespace": "docs",
+			"sourceIds": []interface{}{"faq-1"},
+			"createdAt": float64(1700000000),
+			"expiresAt": float64(0),
+			"metadata":  `{"model":"gpt-4o"}`,
+		},
+	}
+}
+
+func newLookup(mc *mockclient.VectorDBClient, embedURL string) *Activity {
+	return &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider:   "OpenAI",
+			EmbeddingBaseURL:    embedURL,
+			EmbeddingModel:      "text-embedding-3-small",
+			CacheCollection:     "semantic_cache",
+			SimilarityThreshold: 0.9,
+			TimeoutSeconds:      10,
+		},
+	}
+}
+
+func TestSemanticCacheLookup_Hit(t *testing.T) {
+	embedServer := makeEmbedServer([]float64{0.6, 0.8})
+	defer embedServer.Close()
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "semantic_cache").Return(true, nil)
+	mc.On("VectorSearch", mock.Anything, mock.MatchedBy(func(r vectordb.SearchRequest) bool {
+		return r.CollectionName == "semantic_cache" && r.ScoreThreshold == 0.9 && r.Filters["namespace"] == "docs"
+	})).Return([]vectordb.SearchResult{cachedEntry(0.97)}, nil)
+
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "how can I reset my password", "namespace": "docs"}}
+	ok, err := newLookup(mc, embedServer.URL).Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, true, ctx.outputs["hit"])
+	assert.Equal(t, "Use the reset link on the sign-in page.", ctx.outputs["answer"])
+	assert.Equal(t, "How do I reset my password?", ctx.outputs["cachedQuery"])
+	assert.Equal(t, 0.97, ctx.outputs["score"])
+	assert.Equal(t, []interface{}{"faq-1"}, ctx.outputs["sourceIds"])
+	assert.Equal(t, map[string]interface{}{"model": "gpt-4o"}, ctx.outputs["metadata"])
+	assert.Equal(t, "2023-11-14T22:13:20Z", ctx.outputs["createdAt"])
+	assert.Equal(t, "", ctx.outputs["expiresAt"])
+	assert.Equal(t, []interface{}{0.6, 0.8}, ctx.outputs["queryEmbedding"])
+	mc.AssertExpectations(t)
+}
+
+func TestSemanticCacheLookup_MissBelowThreshold(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "semantic_cache").Return(true, nil)
+	mc.On("VectorSearch", mock.Anything, mock.Anything).Return([]vectordb.SearchResult{cachedEntry(0.85)}, nil)
+	mc.On("DeleteByFilter", mock.Anything, "semantic_cache", mock.Anything).Return(int64(0), nil)
+
+	// queryVector skips the embedding call: no embedding server is needed.
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"queryVector": []interface{}{0.6, 0.8}, "namespace": "docs",
+	}}
+	ok, err := newLookup(mc, "http://127.0.0.1:1").Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, false, ctx.outputs["hit"])
+	assert.Equal(t, "", ctx.outputs["answer"])
+}
+
+func TestSemanticCacheLookup_MissingCollectionIsMiss(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "semantic_cache").Return(false, nil)
+
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryVector": []interface{}{1.0, 0.0}}}
+	ok, err := newLookup(mc, "").Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"])
+	assert.Equal(t, false, ctx.outputs["hit"])
+	mc.AssertNotCalled(t, "VectorSearch", mock.Anything, mock.Anything)
+}
+
+func TestSemanticCacheLookup_MissingQuery(t *testing.T) {
+	ok, err := newLookup(&mockclient.VectorDBClient{}, "").Eval(&fakeActivityContext{inputs: map[string]interface{}{}})
+	assert.False(t, ok)
+	assert.Error(t, err)
+}
+
+func TestSemanticCacheLookup_SettingsStringRedactsAPIKey(t *testing.T) {
+	s := Settings{EmbeddingAPIKey: "sk-secret", EmbeddingModel: "m"}
+	assert.NotContains(t, s.String(), "sk-secret")
+	assert.Contains(t, s.String(), "[redacted]")
+}
@@ -0,0 +1,216 @@
+package semanticCacheStore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate"
+	vectordbconnector "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/connector"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/testutil/mock"
+	"github.com/project-flogo/core/activity"
+	"github.com/project-flogo/core/data"
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/core/support/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// fake activity context
+// ---------------------------------------------------------------------------
+
+type fakeActivityContext struct {
+	inputs  map[string]interface{}
+	outputs map[string]interface{}
+}
+
+func (f *fakeActivityContext) ActivityHost() activity.Host        { return nil }
+func (f *fakeActivityContext) Name() string                       { return "test" }
+func (f *fakeActivityContext) GetSetting(name string) interface{} { return nil }
+func (f *fakeActivityContext) GetInput(name string) interface{} {
+	if f.inputs == nil {
+		return nil
+	}
+	return f.inputs[name]
+}
+func (f *fakeActivityContext) SetOutput(name string, value interface{}) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	f.outputs[name] = value
+	return nil
+}
+func (f *fakeActivityContext) GetInputObject(obj data.StructValue) error {
+	return obj.FromMap(f.inputs)
+}
+func (f *fakeActivityContext) SetOutputObject(obj data.StructValue) error {
+	if f.outputs == nil {
+		f.outputs = make(map[string]interface{})
+	}
+	for k, v := range obj.ToMap() {
+		f.outputs[k] = v
+	}
+	return nil
+}
+func (f *fakeActivityContext) GetSharedTempData() map[string]interface{} { return nil }
+func (f *fakeActivityContext) Logger() log.Logger                        { return log.RootLogger() }
+func (f *fakeActivityContext) GetTracingContext() trace.TracingContext   { return nil }
+func (f *fakeActivityContext) GoContext() context.Context                { return context.Background() }
+
+// ---------------------------------------------------------------------------
+// local JSON fixture types (mirrors of vdbembed unexported response structs)
+// ---------------------------------------------------------------------------
+
+type openAIEmbedData struct {
+	Embedding []float64 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+type openAIEmbedUsage struct {
+	TotalTokens int `json:"total_tokens"`
+}
+type openAIEmbedResponse struct {
+	Data  []openAIEmbedData `json:"data"`
+	Usage openAIEmbedUsage  `json:"usage"`
+}
+
+// ---------------------------------------------------------------------------
+// helpers
+// ---------------------------------------------------------------------------
+
+func makeEmbedServer(vec []float64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openAIEmbedResponse{
+			Data:  []openAIEmbedData{{Embedding: vec, Index: 0}},
+			Usage: openAIEmbedUsage{TotalTokens: 10},
+		})
+	}))
+}
+
+func newTestConn(client vectordb.VectorDBClient) *vectordbconnector.WeaviateConnection {
+	s := &vectordbconnector.Settings{Host: "localhost", TimeoutSeconds: 5}
+	return vectordbconnector.NewConnectionForTest("test-conn", client, s)
+}
+
+// ---------------------------------------------------------------------------
+// tests
+// ---------------------------------------------------------------------------
+
+func newStore(mc *mockclient.VectorDBClient, embedURL string) *Activity {
+	return &Activity{
+		conn: newTestConn(mc),
+		settings: &Settings{
+			EmbeddingProvider: "OpenAI",
+			EmbeddingBaseURL:  embedURL,
+			EmbeddingModel:    "text-embedding-3-small",
+			CacheCollection:   "semantic_cache",
+			DefaultTTLSeconds: 3600,
+			TimeoutSeconds:    10,
+		},
+	}
+}
+
+func TestSemanticCacheStore_CreatesCollectionAndStores(t *testing.T) {
+	embedServer := makeEmbedServer([]float64{0.6, 0.8})
+	defer embedServer.Close()
+
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "semantic_cache").Return(false, nil).Once()
+	mc.On("CreateCollection", mock.Anything, mock.MatchedBy(func(c vectordb.CollectionConfig) bool {
+		return c.Name == "semantic_cache" && c.Dimensions == 2 && c.DistanceMetric == "cosine"
+	})).Return(nil)
+	var stored vectordb.Document
+	mc.On("UpsertDocuments", mock.Anything, "semantic_cache", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(2).([]vectordb.Document)[0]
+	}).Return(nil)
+
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"queryText": "How do I reset my password?",
+		"answer":    "Use the reset link.",
+		"namespace": "docs",
+		"sourceIds": []interface{}{"faq-1", "faq-2"},
+		"metadata":  map[string]interface{}{"model": "gpt-4o"},
+	}}
+	ok, err := newStore(mc, embedServer.URL).Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, stored.ID, ctx.outputs["cacheId"])
+	assert.NotEmpty(t, ctx.outputs["expiresAt"])
+
+	assert.Equal(t, []float64{0.6, 0.8}, stored.Vector)
+	assert.Equal(t, "How do I reset my password?", stored.Content)
+	assert.Equal(t, "Use the reset link.", stored.Payload["answer"])
+	assert.Equal(t, "docs", stored.Payload["namespace"])
+	assert.Equal(t, []string{"faq-1", "faq-2"}, stored.Payload["sourceIds"])
+	assert.Equal(t, `{"model":"gpt-4o"}`, stored.Payload["metadata"])
+	assert.Equal(t, int64(3600), stored.Payload["expiresAt"].(int64)-stored.Payload["createdAt"].(int64))
+	mc.AssertExpectations(t)
+}
+
+func TestSemanticCacheStore_InvalidateOnly(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "semantic_cache").Return(true, nil)
+	mc.On("DeleteByFilter", mock.Anything, "semantic_cache", map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"sourceIds": map[string]interface{}{"$contains": "faq-1"}},
+			map[string]interface{}{"sourceIds": map[string]interface{}{"$contains": "faq-2"}},
+		},
+	}).Return(int64(3), nil)
+
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"invalidateSourceIds": []interface{}{"faq-1", "faq-2"}}}
+	ok, err := newStore(mc, "").Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, int64(3), ctx.outputs["invalidatedCount"])
+	assert.Equal(t, "", ctx.outputs["cacheId"])
+	mc.AssertNotCalled(t, "UpsertDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSemanticCacheStore_ReusesQueryVectorWithoutTTL(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "semantic_cache").Return(true, nil)
+	mc.On("UpsertDocuments", mock.Anything, "semantic_cache", mock.MatchedBy(func(docs []vectordb.Document) bool {
+		return len(docs) == 1 && docs[0].Payload["expiresAt"] == int64(0)
+	})).Return(nil)
+
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"queryText": "q", "answer": "a", "queryVector": []interface{}{1.0, 0.0}, "ttlSeconds": -1,
+	}}
+	ok, err := newStore(mc, "http://127.0.0.1:1").Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, ctx.outputs["success"], ctx.outputs["error"])
+	assert.Equal(t, "", ctx.outputs["expiresAt"])
+	mc.AssertExpectations(t)
+}
+
+func TestSemanticCacheStore_UpsertFails(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	mc.On("CollectionExists", mock.Anything, "semantic_cache").Return(true, nil)
+	mc.On("UpsertDocuments", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("connection refused"))
+
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{"queryText": "q", "answer": "a", "queryVector": []interface{}{1.0}}}
+	ok, err := newStore(mc, "").Eval(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, false, ctx.outputs["success"])
+	assert.Contains(t, ctx.outputs["error"], "connection refused")
+}
+
+func TestSemanticCacheStore_Validation(t *testing.T) {
+	a := newStore(&mockclient.VectorDBClient{}, "")
+	ok, err := a.Eval(&fakeActivityContext{inputs: map[string]interface{}{}})
+	assert.False(t, ok)
+	assert.Error(t, err, "nothing to do")
+
+	ok, err = a.Eval(&fakeActivityContext{inputs: map[string]interface{}{"queryText": "q"}})
+	assert.False(t, ok)
+	assert.Error(t, err, "answer is required")
+}
@@ -172,3 +172,47 @@ func TestVectorSearch_SkipPayload_Forwarded(t *testing.T) {
 	assert.NoError(t, err)
 	mc.AssertExpectations(t)
 }
+
+func TestVectorSearch_MMR(t *testing.T) {
+	mc := &mockclient.VectorDBClient{}
+	candidates := []vectordb.SearchResult{
+		{ID: "a", Score: 0.99, Vector: []float64{1, 0.05}},
+		{ID: "a-copy", Score: 0.98, Vector: []float64{1, 0.06}},
+		{ID: "b", Score: 0.80, Vector: []float64{0.8, -0.6}},
+	}
+	mc.On("VectorSearch", mock.Anything, mock.MatchedBy(func(r vectordb.SearchRequest) bool {
+		return r.TopK == 20 && r.WithVectors
+	})).Return(candidates, nil)
+
+	a := &Activity{conn: newTestConn(mc), settings: &Settings{}}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col",
+		"queryVector":    []interface{}{1.0, 0.0},
+		"topK":           2,
+		"mmr":            true,
+		"mmrLambda":      0.5,
+	}}
+	ok, err := a.Eval(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	results := ctx.outputs["results"].([]interface{})
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "a", results[0].(map[string]interface{})["id"])
+		assert.Equal(t, "b", results[1].(map[string]interface{})["id"])
+		assert.NotContains(t, results[0].(map[string]interface{}), "vector")
+	}
+	mc.AssertExpectations(t)
+}
+
+func TestVectorSearch_MMRLambdaOutOfRange(t *testing.T) {
+	a := &Activity{conn: newTestConn(&mockclient.VectorDBClient{}), settings: &Settings{}}
+	ctx := &fakeActivityContext{inputs: map[string]interface{}{
+		"collectionName": "col",
+		"queryVector":    []interface{}{0.1},
+		"mmr":            true,
+		"mmrLambda":      1.5,
+	}}
+	_, err := a.Eval(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "mmrLambda")
+}
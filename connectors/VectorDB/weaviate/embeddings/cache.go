@@ -0,0 +1,227 @@
+package vdbembed
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// EmbeddingCache stores embedding vectors by content key so that texts which
+// were embedded before are not sent to the embedding API again.
+// Implementations must be safe for concurrent use.
+type EmbeddingCache interface {
+	// Get returns the cached vector for key, if any.
+	Get(key string) ([]float64, bool)
+	// Put stores vector under key.
+	Put(key string, vector []float64)
+}
+
+// CacheKey returns the content hash used as the cache key for one text: the
+// hex SHA-256 of the model, requested dimensions, Cohere input type and text.
+// The same text embedded by a different model or at a different size gets a
+// different key.
+func CacheKey(model string, dimensions int, inputType, text string) string {
+	h := sha256.New()
+	for _, part := range []string{model, strconv.Itoa(dimensions), inputType, text} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CreateEmbeddingsCached is CreateEmbeddings with a read-through cache. Texts
+// found in cache are not sent to the provider, and identical texts within
+// req.Texts are embedded once. A nil cache only deduplicates.
+// EmbeddingResponse.CacheHits reports how many texts were served from cache;
+// TokensUsed counts only the texts that were embedded.
+func CreateEmbeddingsCached(ctx context.Context, req EmbeddingRequest, cache EmbeddingCache) (*EmbeddingResponse, error) {
+	if len(req.Texts) == 0 {
+		return nil, fmt.Errorf("embeddings: at least one input text is required")
+	}
+
+	out := &EmbeddingResponse{Embeddings: make([][]float64, len(req.Texts))}
+	keys := make([]string, len(req.Texts))
+	pending := make(map[string][]int) // key → positions waiting for that vector
+	var missTexts []string
+	var missKeys []string
+	for i, text := range req.Texts {
+		key := CacheKey(req.Model, req.Dimensions, req.InputType, text)
+		keys[i] = key
+		if cache != nil {
+			if vec, ok := cache.Get(key); ok {
+				out.Embeddings[i] = vec
+				out.CacheHits++
+				continue
+			}
+		}
+		if _, seen := pending[key]; !seen {
+			missTexts = append(missTexts, text)
+			missKeys = append(missKeys, key)
+		}
+		pending[key] = append(pending[key], i)
+	}
+
+	if len(missTexts) > 0 {
+		missReq := req
+		missReq.Texts = missTexts
+		resp, err := CreateEmbeddings(ctx, missReq)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Embeddings) != len(missTexts) {
+			return nil, fmt.Errorf("embeddings: provider returned %d vectors for %d texts", len(resp.Embeddings), len(missTexts))
+		}
+		for j, vec := range resp.Embeddings {
+			for _, i := range pending[missKeys[j]] {
+				out.Embeddings[i] = vec
+			}
+			if cache != nil {
+				cache.Put(missKeys[j], vec)
+			}
+		}
+		out.TokensUsed = resp.TokensUsed
+		out.Dimensions = resp.Dimensions
+	}
+	if out.Dimensions == 0 && len(out.Embeddings) > 0 {
+		out.Dimensions = len(out.Embeddings[0])
+	}
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// In-memory LRU store
+// ---------------------------------------------------------------------------
+
+// DefaultMemoryCacheEntries is the capacity of a memory cache created with a
+// non-positive size.
+const DefaultMemoryCacheEntries = 10000
+
+type memoryEntry struct {
+	key    string
+	vector []float64
+}
+
+// memoryCache is a fixed-capacity LRU of vectors.
+type memoryCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+// NewMemoryCache returns an in-process LRU cache holding up to maxEntries
+// vectors (DefaultMemoryCacheEntries when maxEntries <= 0).
+func NewMemoryCache(maxEntries int) EmbeddingCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &memoryCache{max: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *memoryCache) Get(key string) ([]float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return append([]float64(nil), el.Value.(*memoryEntry).vector...), true
+}
+
+func (c *memoryCache) Put(key string, vector []float64) {
+	vector = append([]float64(nil), vector...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*memoryEntry).vector = vector
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, vector: vector})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// On-disk store
+// ---------------------------------------------------------------------------
+
+// diskCache stores one file per key, <dir>/<key[:2]>/<key>.vec, holding the
+// vector as little-endian float64s. Files are written to a temporary name and
+// renamed, so concurrent writers and crashes never leave a partial vector.
+// Entries are never evicted; delete the directory to clear the cache.
+type diskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a cache persisted under dir, creating the directory if
+// needed. The cache survives restarts and may be shared by several processes.
+func NewDiskCache(dir string) (EmbeddingCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("embeddings: disk cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("embeddings: cannot create cache directory %q: %w", dir, err)
+	}
+	return &diskCache{dir: dir}, nil
+}
+
+func (c *diskCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".vec")
+}
+
+func (c *diskCache) Get(key string) ([]float64, bool) {
+	if len(key) < 2 {
+		return nil, false
+	}
+	b, err := os.ReadFile(c.path(key))
+	if err != nil || len(b) == 0 || len(b)%8 != 0 {
+		return nil, false
+	}
+	vec := make([]float64, len(b)/8)
+	for i := range vec {
+		vec[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[i*8:]))
+	}
+	return vec, true
+}
+
+// Put stores the vector; write errors are ignored because a missing entry
+// only costs a re-embedding.
+func (c *diskCache) Put(key string, vector []float64) {
+	if len(key) < 2 || len(vector) == 0 {
+		return
+	}
+	b := make([]byte, 8*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint64(b[i*8:], math.Float64bits(v))
+	}
+	sub := filepath.Join(c.dir, key[:2])
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(sub, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, werr := f.Write(b)
+	cerr := f.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		os.Remove(f.Name())
+	}
+}
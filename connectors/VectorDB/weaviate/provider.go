@@ -25,6 +25,7 @@ type weaviateClient struct {
 
 // Compile-time proof that weaviateClient satisfies the full VectorDBClient interface.
 var _ VectorDBClient = (*weaviateClient)(nil)
+var _ MetricReader = (*weaviateClient)(nil)
 
 func newWeaviateClient(cfg ConnectionConfig) (VectorDBClient, error) {
 	tlsCfg, err := buildTLSConfig(cfg)
@@ -219,6 +220,32 @@ func (c *weaviateClient) CollectionExists(ctx context.Context, name string) (boo
 	return exists, nil
 }
 
+// CollectionMetric returns the distance of the class's vector index.
+// Weaviate defaults an unset distance to cosine; classes with only named
+// vectors report "".
+func (c *weaviateClient) CollectionMetric(ctx context.Context, name string) (string, error) {
+	var class *models.Class
+	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
+		var retryErr error
+		class, retryErr = c.client.Schema().ClassGetter().WithClassName(weaviateClassName(name)).Do(ctx)
+		return retryErr
+	}); err != nil {
+		return "", newError(ErrCodeProviderError, "CollectionMetric failed", err)
+	}
+	cfg, ok := class.VectorIndexConfig.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+	distance, _ := cfg["distance"].(string)
+	switch distance {
+	case "", "cosine":
+		return "cosine", nil
+	case "l2-squared":
+		return "euclidean", nil
+	}
+	return distance, nil
+}
+
 // --- Document operations ---
 
 func (c *weaviateClient) UpsertDocuments(ctx context.Context, collectionName string, docs []Document) error {
@@ -356,9 +383,20 @@ func (c *weaviateClient) DeleteByFilter(ctx context.Context, collectionName stri
 	}
 	className := weaviateClassName(collectionName)
 
-	whereFilter, err := buildWeaviateWhereFilter(filters)
+	whereFilter, residual, err := weaviateFilter(filters)
 	if err != nil {
-		return 0, newError(ErrCodeProviderError, fmt.Sprintf("DeleteByFilter: cannot build filter: %s", err), nil)
+		return 0, err
+	}
+	if residual != nil {
+		// Part of the filter is matched client-side: delete by ID instead.
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		if err := c.DeleteDocuments(ctx, collectionName, ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
 	}
 
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
@@ -389,15 +427,15 @@ func (c *weaviateClient) ScrollDocuments(ctx context.Context, req ScrollRequest)
 		}
 	}
 
+	wf, residual, err := weaviateFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
 	var scrollResult *ScrollResult
-	if len(req.Filters) > 0 {
+	if wf != nil || residual != nil {
 		// The Data API (ObjectsGetter) does not support WHERE filters.
 		// Switch to GraphQL Get with WithWhere + WithOffset + WithLimit.
-		wf, buildErr := buildWeaviateWhereFilter(req.Filters)
-		if buildErr != nil {
-			return nil, newError(ErrCodeProviderError,
-				fmt.Sprintf("ScrollDocuments: cannot build filter: %s", buildErr), nil)
-		}
 		gqlFields := []graphql.Field{
 			{Name: "_additional { id }"},
 			{Name: "content"},
@@ -406,25 +444,29 @@ func (c *weaviateClient) ScrollDocuments(ctx context.Context, req ScrollRequest)
 		}
 		var gqlResult *models.GraphQLResponse
 		if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
-			var gqlErr error
-			gqlResult, gqlErr = c.client.GraphQL().Get().
+			q := c.client.GraphQL().Get().
 				WithClassName(className).
 				WithFields(gqlFields...).
-				WithWhere(wf).
 				WithLimit(limit).
-				WithOffset(offset).
-				Do(ctx)
+				WithOffset(offset)
+			if wf != nil {
+				q = q.WithWhere(wf)
+			}
+			var gqlErr error
+			gqlResult, gqlErr = q.Do(ctx)
 			return gqlErr
 		}); err != nil {
 			return nil, newError(ErrCodeProviderError, "ScrollDocuments failed", err)
 		}
 		rawDocs := weaviateGraphQLToResults(gqlResult, className)
-		docs := make([]Document, len(rawDocs))
-		for i, sr := range rawDocs {
-			docs[i] = Document{ID: sr.ID, Content: sr.Content, Payload: sr.Payload}
+		docs := make([]Document, 0, len(rawDocs))
+		for _, sr := range rawDocs {
+			if residual == nil || residual.Match(weaviateMatchFields(sr.ID, sr.Content, sr.Payload)) {
+				docs = append(docs, Document{ID: sr.ID, Content: sr.Content, Payload: sr.Payload})
+			}
 		}
 		nextOffset := ""
-		if len(docs) == limit {
+		if len(rawDocs) == limit {
 			nextOffset = fmt.Sprintf("%d", offset+limit)
 		}
 		return &ScrollResult{Documents: docs, NextOffset: nextOffset, Total: -1}, nil
@@ -459,23 +501,29 @@ func (c *weaviateClient) ScrollDocuments(ctx context.Context, req ScrollRequest)
 func (c *weaviateClient) CountDocuments(ctx context.Context, collectionName string, filters map[string]interface{}) (int64, error) {
 	className := weaviateClassName(collectionName)
 
-	// Weaviate's generic filter-to-Where translation requires knowing each
-	// property's type (string / int / float / bool) at query time — information
-	// we do not carry in the provider-agnostic filter map.  Rather than
-	// silently returning the full-collection count (which would be wrong), we
-	// surface ErrCodeNotImplemented so callers can handle the gap explicitly.
-	if len(filters) > 0 {
-		return 0, newError(ErrCodeNotImplemented,
-			"CountDocuments with filters is not yet implemented for Weaviate; omit filters to count all documents", nil)
+	wf, residual, err := weaviateFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	if residual != nil {
+		// Payload conditions are matched client-side, so count by scanning.
+		ids, err := scrollMatchingIDs(ctx, c.ScrollDocuments, collectionName, filters)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
 	}
 
 	field := graphql.Field{Name: "meta { count }"}
 	var count int64
 	if err := withRetry(ctx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
-		result, retryErr := c.client.GraphQL().Aggregate().
+		q := c.client.GraphQL().Aggregate().
 			WithClassName(className).
-			WithFields(field).
-			Do(ctx)
+			WithFields(field)
+		if wf != nil {
+			q = q.WithWhere(wf)
+		}
+		result, retryErr := q.Do(ctx)
 		if retryErr != nil {
 			return retryErr
 		}
@@ -526,7 +574,11 @@ func (c *weaviateClient) VectorSearch(ctx context.Context, req SearchRequest) ([
 		nearVector = nearVector.WithDistance(distanceThreshold)
 	}
 
-	fields := weaviateSearchFields(req.SkipPayload, "_additional { id certainty distance }")
+	wf, residual, err := weaviateFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	fields := weaviateSearchFields(req.SkipPayload && residual == nil, "_additional { id certainty distance }")
 
 	var result *models.GraphQLResponse
 	if err := withRetry(tCtx, c.cfg.MaxRetries, c.cfg.RetryBackoffMs, func() error {
@@ -535,13 +587,8 @@ func (c *weaviateClient) VectorSearch(ctx context.Context, req SearchRequest) ([
 			WithClassName(className).
 			WithFields(fields...).
 			WithNearVector(nearVector).
-			WithLimit(req.TopK)
-		if len(req.Filters) > 0 {
-			wf, buildErr := buildWeaviateWhereFilter(req.Filters)
-			if buildErr != nil {
-				gqlErr = newError(ErrCodeProviderError, fmt.Sprintf("VectorSearch: cannot build filter: %s", buildErr), nil)
-				return gqlErr
-			}
+			WithLimit(searchLimit(req.TopK, residual))
+		if wf != nil {
 			q = q.WithWhere(wf)
 		}
 		result, gqlErr = q.Do(tCtx)
@@ -550,7 +597,7 @@ func (c *weaviateClient) VectorSearch(ctx context.Context, req SearchRequest) ([
 		return nil, newError(ErrCodeProviderError, "VectorSearch failed", err)
 	}
 
-	return weaviateGraphQLToResults(result, className), nil
+	return weaviateMatchResults(weaviateGraphQLToResults(result, className), residual, req.TopK, req.SkipPayload), nil
 }
 
 func (c *weaviateClient) HybridSearch(ctx context.Context, req HybridSearchRequest) ([]SearchResult, error) {
@@ -564,7 +611,11 @@ func (c *weaviateClient) HybridSearch(ctx context.Context, req HybridSearchReque
 
 	className := weaviateClassName(req.CollectionName)
 
-	fields := weaviateSearchFields(req.SkipPayload, "_additional { id score }")
+	wf, residual, err := weaviateFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	fields := weaviateSearchFields(req.SkipPayload && residual == nil, "_additional { id score }")
 
 	tCtx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSeconds)*time.Second)
 	defer cancel()
@@ -582,13 +633,8 @@ func (c *weaviateClient) HybridSearch(ctx context.Context, req HybridSearchReque
 			WithClassName(className).
 			WithFields(fields...).
 			WithHybrid(hybrid).
-			WithLimit(req.TopK)
-		if len(req.Filters) > 0 {
-			wf, buildErr := buildWeaviateWhereFilter(req.Filters)
-			if buildErr != nil {
-				gqlErr = newError(ErrCodeProviderError, fmt.Sprintf("HybridSearch: cannot build filter: %s", buildErr), nil)
-				return gqlErr
-			}
+			WithLimit(searchLimit(req.TopK, residual))
+		if wf != nil {
 			q = q.WithWhere(wf)
 		}
 		result, gqlErr = q.Do(tCtx)
@@ -597,7 +643,7 @@ func (c *weaviateClient) HybridSearch(ctx context.Context, req HybridSearchReque
 		return nil, newError(ErrCodeProviderError, "HybridSearch failed", err)
 	}
 
-	return weaviateGraphQLToResults(result, className), nil
+	return weaviateMatchResults(weaviateGraphQLToResults(result, className), residual, req.TopK, req.SkipPayload), nil
 }
 
 // --- Helpers ---
@@ -732,128 +778,198 @@ var weaviateSchemaProps = map[string]bool{
 	"_metadata": true,
 }
 
-// buildWeaviateWhereFilter converts the provider-agnostic filter map to a
-// Weaviate *filters.WhereBuilder. Supported operators: $eq (default), $ne,
-// $gt, $gte, $lt, $lte. String values use WithValueText; numeric values use
-// WithValueNumber; booleans use WithValueBoolean.
-//
-// Payload fields that are not first-class Weaviate schema properties are
-// automatically routed to a LIKE wildcard filter on _metadata so that callers
-// can filter on arbitrary document payload keys without knowing the schema
-// layout. Only $eq semantics are supported for non-schema payload fields.
+// weaviateFilter validates filters and converts them to a Weaviate where
+// filter plus a residual that is matched client-side.
 //
-// Each key in the map may be:
-//   - a scalar value: interpreted as field == value (implicit $eq)
-//   - a map[string]interface{} with a single operator key
-func buildWeaviateWhereFilter(f map[string]interface{}) (*filters.WhereBuilder, error) {
-	type clause struct {
-		field     string
-		op        filters.WhereOperator
-		valueStr  string
-		valueNum  float64
-		valueBool bool
-		kind      string // "text", "number", "bool"
-	}
-
-	opMap := map[string]filters.WhereOperator{
-		"$eq":  filters.Equal,
-		"$ne":  filters.NotEqual,
-		"$gt":  filters.GreaterThan,
-		"$gte": filters.GreaterThanEqual,
-		"$lt":  filters.LessThan,
-		"$lte": filters.LessThanEqual,
-	}
-
-	clauses := make([]clause, 0, len(f))
-	for field, raw := range f {
-		op := filters.Equal
-		value := raw
-
-		if opMap2, ok := raw.(map[string]interface{}); ok {
-			for opKey, opVal := range opMap2 {
-				mapped, ok := opMap[opKey]
-				if !ok {
-					return nil, fmt.Errorf("unsupported operator %q for Weaviate filter on field %q", opKey, field)
-				}
-				op = mapped
-				value = opVal
-				break
+// Conditions on the _docId and content schema properties are translated
+// exactly, including under $and / $or / $not ($in becomes an Or of Equal
+// operands). Payload keys live inside the _metadata JSON text property, which
+// Weaviate can only search with Like. Payload conditions therefore stay in
+// the residual; top-level $eq, $in and $contains conditions are additionally
+// pushed down as Like pre-filters that never exclude a matching document, so
+// the residual only has to discard false positives.
+func weaviateFilter(f map[string]interface{}) (*filters.WhereBuilder, *FilterExpr, error) {
+	expr, err := ParseFilter(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	pushed, residual := splitFilter(expr, weaviateNative)
+	var operands []*filters.WhereBuilder
+	if pushed != nil {
+		for _, c := range pushed.Conjuncts() {
+			operands = append(operands, buildWeaviateWhereFilter(c))
+		}
+	}
+	if residual != nil {
+		for _, c := range residual.Conjuncts() {
+			if like := weaviateLikeFilter(c); like != nil {
+				operands = append(operands, like)
 			}
 		}
+	}
+	switch len(operands) {
+	case 0:
+		return nil, residual, nil
+	case 1:
+		return operands[0], residual, nil
+	}
+	return filters.Where().WithOperator(filters.And).WithOperands(operands), residual, nil
+}
 
-		c := clause{field: field, op: op}
-		switch v := value.(type) {
-		case string:
-			c.kind = "text"
-			c.valueStr = v
-		case float64:
-			c.kind = "number"
-			c.valueNum = v
-		case int:
-			c.kind = "number"
-			c.valueNum = float64(v)
-		case int64:
-			c.kind = "number"
-			c.valueNum = float64(v)
-		case bool:
-			c.kind = "bool"
-			c.valueBool = v
-		default:
-			c.kind = "text"
-			c.valueStr = fmt.Sprintf("%v", v)
-		}
-		clauses = append(clauses, c)
-	}
-
-	builder := func(c clause) *filters.WhereBuilder {
-		// Payload fields that are not first-class schema properties are stored
-		// as JSON inside _metadata.  Route them to a LIKE substring match so
-		// that callers can filter on arbitrary payload keys.
-		if !weaviateSchemaProps[c.field] {
-			// Build the JSON fragment we expect to find inside _metadata.
-			// json.Marshal produces compact, deterministic output so
-			// `"key":"value"` or `"key":42` or `"key":true` will always match.
-			var fragment string
-			switch c.kind {
-			case "text":
-				// JSON-encode string: "field":"value"
-				keyBytes, _ := json.Marshal(c.field)
-				valBytes, _ := json.Marshal(c.valueStr)
-				fragment = fmt.Sprintf("*%s:%s*", string(keyBytes), string(valBytes))
-			case "number":
-				keyBytes, _ := json.Marshal(c.field)
-				fragment = fmt.Sprintf("*%s:%v*", string(keyBytes), c.valueNum)
-			case "bool":
-				keyBytes, _ := json.Marshal(c.field)
-				fragment = fmt.Sprintf("*%s:%v*", string(keyBytes), c.valueBool)
-			default:
-				keyBytes, _ := json.Marshal(c.field)
-				valBytes, _ := json.Marshal(c.valueStr)
-				fragment = fmt.Sprintf("*%s:%s*", string(keyBytes), string(valBytes))
+// weaviateNative reports whether e only references schema properties with
+// operators that have a Weaviate equivalent. Both schema properties are
+// text, so range operators are matched client-side.
+func weaviateNative(e *FilterExpr) bool {
+	return e.All(func(n *FilterExpr) bool {
+		switch n.Op {
+		case FilterAnd, FilterOr, FilterNot:
+			return true
+		case FilterEq, FilterNe:
+		case FilterIn, FilterNin:
+			if len(n.Values()) == 0 {
+				return false
 			}
-			return filters.Where().
-				WithPath([]string{"_metadata"}).
-				WithOperator(filters.Like).
-				WithValueText(fragment)
-		}
-		w := filters.Where().WithPath([]string{c.field}).WithOperator(c.op)
-		switch c.kind {
-		case "number":
-			return w.WithValueNumber(c.valueNum)
-		case "bool":
-			return w.WithValueBoolean(c.valueBool)
 		default:
-			return w.WithValueText(c.valueStr)
+			return false
 		}
+		return weaviateSchemaProps[n.Field] && n.Field != "_metadata"
+	})
+}
+
+// buildWeaviateWhereFilter converts a parsed filter on schema properties to
+// a Weaviate *filters.WhereBuilder. String values use WithValueText; numeric
+// values use WithValueNumber; booleans use WithValueBoolean.
+func buildWeaviateWhereFilter(e *FilterExpr) *filters.WhereBuilder {
+	switch e.Op {
+	case FilterAnd, FilterOr, FilterNot:
+		operands := make([]*filters.WhereBuilder, len(e.Children))
+		for i, c := range e.Children {
+			operands[i] = buildWeaviateWhereFilter(c)
+		}
+		op := map[string]filters.WhereOperator{FilterAnd: filters.And, FilterOr: filters.Or, FilterNot: filters.Not}[e.Op]
+		return filters.Where().WithOperator(op).WithOperands(operands)
+	case FilterIn, FilterNin:
+		items := e.Values()
+		operands := make([]*filters.WhereBuilder, len(items))
+		for i, item := range items {
+			operands[i] = weaviateCompare(e.Field, filters.Equal, item)
+		}
+		w := operands[0]
+		if len(operands) > 1 {
+			w = filters.Where().WithOperator(filters.Or).WithOperands(operands)
+		}
+		if e.Op == FilterNin {
+			return filters.Where().WithOperator(filters.Not).WithOperands([]*filters.WhereBuilder{w})
+		}
+		return w
+	case FilterNe:
+		return weaviateCompare(e.Field, filters.NotEqual, e.Value)
+	}
+	return weaviateCompare(e.Field, filters.Equal, e.Value)
+}
+
+func weaviateCompare(field string, op filters.WhereOperator, v interface{}) *filters.WhereBuilder {
+	w := filters.Where().WithPath([]string{field}).WithOperator(op)
+	if b, ok := v.(bool); ok {
+		return w.WithValueBoolean(b)
+	}
+	if f, ok := filterNumber(v); ok {
+		return w.WithValueNumber(f)
 	}
+	return w.WithValueText(fmt.Sprintf("%v", v))
+}
 
-	if len(clauses) == 1 {
-		return builder(clauses[0]), nil
+// weaviateLikeFilter returns a Like pre-filter on _metadata for a payload
+// condition, or nil when the condition has none. The pattern keys on the
+// last path segment and accepts the value either as the field value or
+// inside an array, so it matches at least every document the condition
+// matches. json.Marshal produces the same compact output UpsertDocuments
+// stores, so `"key":"value"`, `"key":42` or `"key":true` will always match.
+func weaviateLikeFilter(e *FilterExpr) *filters.WhereBuilder {
+	if e.IsLogical() || weaviateSchemaProps[e.Field] {
+		return nil
+	}
+	path := e.Path()
+	key := path[len(path)-1]
+	switch e.Op {
+	case FilterEq:
+		return weaviateLikeEq(key, e.Value)
+	case FilterIn:
+		items := e.Values()
+		if len(items) == 0 {
+			return nil
+		}
+		operands := make([]*filters.WhereBuilder, len(items))
+		for i, item := range items {
+			operands[i] = weaviateLikeEq(key, item)
+		}
+		return filters.Where().WithOperator(filters.Or).WithOperands(operands)
+	case FilterContains:
+		return weaviateLike(fmt.Sprintf("*%s:[*%s*", weaviateLikeJSON(key), weaviateLikeJSON(e.Value)))
 	}
+	return nil
+}
 
-	operands := make([]*filters.WhereBuilder, len(clauses))
-	for i, c := range clauses {
-		operands[i] = builder(c)
+func weaviateLikeEq(key string, v interface{}) *filters.WhereBuilder {
+	k, val := weaviateLikeJSON(key), weaviateLikeJSON(v)
+	return filters.Where().WithOperator(filters.Or).WithOperands([]*filters.WhereBuilder{
+		weaviateLike(fmt.Sprintf("*%s:%s*", k, val)),
+		weaviateLike(fmt.Sprintf("*%s:[*%s*", k, val)),
+	})
+}
+
+func weaviateLike(pattern string) *filters.WhereBuilder {
+	return filters.Where().
+		WithPath([]string{"_metadata"}).
+		WithOperator(filters.Like).
+		WithValueText(pattern)
+}
+
+// weaviateLikeJSON returns a key or scalar as it appears inside _metadata.
+// Like wildcards (* and ?) in the value still act as wildcards, which only
+// widens the pre-filter.
+func weaviateLikeJSON(v interface{}) string {
+	if f, ok := filterNumber(v); ok {
+		v = f
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%v", v))
 	}
-	return filters.Where().WithOperator(filters.And).WithOperands(operands), nil
+	return string(b)
+}
+
+// weaviateMatchFields returns the fields a residual filter is evaluated
+// against: the payload plus the _docId and content schema properties, which
+// a residual can reference under $or / $not.
+func weaviateMatchFields(id, content string, payload map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(payload)+2)
+	for k, v := range payload {
+		fields[k] = v
+	}
+	fields["_docId"] = id
+	fields["content"] = content
+	return fields
+}
+
+// weaviateMatchResults keeps the search results that match residual, up to
+// topK, and drops the payloads fetched for matching when skipPayload is set.
+func weaviateMatchResults(results []SearchResult, residual *FilterExpr, topK int, skipPayload bool) []SearchResult {
+	if residual == nil {
+		return results
+	}
+	out := results[:0]
+	for _, r := range results {
+		if !residual.Match(weaviateMatchFields(r.ID, r.Content, r.Payload)) {
+			continue
+		}
+		if skipPayload {
+			r.Payload, r.Content = nil, ""
+		}
+		out = append(out, r)
+		if len(out) == topK {
+			break
+		}
+	}
+	return out
 }
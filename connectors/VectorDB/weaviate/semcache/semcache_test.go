@@ -0,0 +1,216 @@
+package vdbsemcache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate"
+	mockclient "github.com/mpandav-tibco/flogo-extensions/vectordb-weaviate/testutil/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memCache is an in-memory cache collection scored by cosine similarity.
+// Methods a test does not use fall through to the testify mock.
+type memCache struct {
+	*mockclient.VectorDBClient
+	docs    map[string]vectordb.Document
+	created *vectordb.CollectionConfig
+}
+
+func newMemCache() *memCache {
+	return &memCache{VectorDBClient: &mockclient.VectorDBClient{}}
+}
+
+func (m *memCache) CollectionExists(_ context.Context, _ string) (bool, error) {
+	return m.docs != nil, nil
+}
+
+func (m *memCache) CreateCollection(_ context.Context, cfg vectordb.CollectionConfig) error {
+	m.docs = map[string]vectordb.Document{}
+	m.created = &cfg
+	return nil
+}
+
+func (m *memCache) UpsertDocuments(_ context.Context, _ string, docs []vectordb.Document) error {
+	for _, d := range docs {
+		m.docs[d.ID] = d
+	}
+	return nil
+}
+
+func (m *memCache) VectorSearch(_ context.Context, req vectordb.SearchRequest) ([]vectordb.SearchResult, error) {
+	expr, err := vectordb.ParseFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	var out []vectordb.SearchResult
+	for _, d := range m.docs {
+		score := cosine(req.QueryVector, d.Vector)
+		if score < req.ScoreThreshold || (expr != nil && !expr.Match(d.Payload)) {
+			continue
+		}
+		out = append(out, vectordb.SearchResult{ID: d.ID, Score: score, Content: d.Content, Payload: d.Payload})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > req.TopK {
+		out = out[:req.TopK]
+	}
+	return out, nil
+}
+
+func (m *memCache) DeleteByFilter(_ context.Context, _ string, filters map[string]interface{}) (int64, error) {
+	expr, err := vectordb.ParseFilter(filters)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for id, d := range m.docs {
+		if expr.Match(d.Payload) {
+			delete(m.docs, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func setClock(t *testing.T, ts time.Time) {
+	now = func() time.Time { return ts }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestStoreAndLookup(t *testing.T) {
+	setClock(t, time.Unix(1_700_000_000, 0))
+	c := newMemCache()
+	ctx := context.Background()
+
+	miss, err := Lookup(ctx, c, "cache", []float64{1, 0}, LookupOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, miss, "a missing collection is a miss")
+
+	stored, err := Store(ctx, c, "cache", []float64{1, 0}, Entry{
+		Query:     "How do I reset my password?",
+		Answer:    "Use the reset link.",
+		SourceIDs: []string{"faq"},
+		Metadata:  map[string]interface{}{"model": "gpt-4o"},
+	}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, EntryID("", "how do I  reset my password?"), stored.ID)
+	assert.Equal(t, time.Unix(1_700_003_600, 0), stored.ExpiresAt)
+	require.NotNil(t, c.created)
+	assert.Equal(t, 2, c.created.Dimensions)
+
+	hit, err := Lookup(ctx, c, "cache", []float64{0.99, 0.1}, LookupOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, hit)
+	assert.Equal(t, "Use the reset link.", hit.Answer)
+	assert.Equal(t, "How do I reset my password?", hit.Query)
+	assert.Equal(t, []string{"faq"}, hit.SourceIDs)
+	assert.Equal(t, map[string]interface{}{"model": "gpt-4o"}, hit.Metadata)
+	assert.Equal(t, time.Unix(1_700_003_600, 0), hit.ExpiresAt)
+	assert.Greater(t, hit.Score, 0.99)
+
+	miss, err = Lookup(ctx, c, "cache", []float64{0.7, 0.7}, LookupOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, miss, "below the threshold")
+
+	miss, err = Lookup(ctx, c, "cache", []float64{1, 0}, LookupOptions{Namespace: "hr"})
+	require.NoError(t, err)
+	assert.Nil(t, miss, "another namespace")
+
+	setClock(t, time.Unix(1_700_003_600, 0))
+	miss, err = Lookup(ctx, c, "cache", []float64{1, 0}, LookupOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, miss, "expired")
+}
+
+func TestInvalidateAndPurge(t *testing.T) {
+	setClock(t, time.Unix(1_700_000_000, 0))
+	c := newMemCache()
+	ctx := context.Background()
+
+	n, err := Invalidate(ctx, c, "cache", []string{"a"})
+	require.NoError(t, err)
+	assert.Zero(t, n, "nothing to invalidate before the first store")
+
+	store := func(q string, vec []float64, ttl time.Duration, sources ...string) {
+		_, err := Store(ctx, c, "cache", vec, Entry{Query: q, Answer: q, SourceIDs: sources}, ttl)
+		require.NoError(t, err)
+	}
+	store("q1", []float64{1, 0}, 0, "a", "b")
+	store("q2", []float64{0, 1}, 0, "b")
+	store("q3", []float64{1, 1}, 0, "c")
+	store("q4", []float64{1, 2}, time.Minute)
+
+	n, err = Invalidate(ctx, c, "cache", []string{"a", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Len(t, c.docs, 2)
+
+	setClock(t, time.Unix(1_700_000_060, 0))
+	n, err = PurgeExpired(ctx, c, "cache")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "entries without TTL are kept")
+	require.Len(t, c.docs, 1)
+	assert.Contains(t, c.docs, EntryID("", "q2"))
+}
+
+// residualCache matches filters after reading the TopK nearest entries, like
+// a provider that applies part of a filter client-side.
+type residualCache struct{ *memCache }
+
+func (m residualCache) VectorSearch(ctx context.Context, req vectordb.SearchRequest) ([]vectordb.SearchResult, error) {
+	filters := req.Filters
+	req.Filters = nil
+	results, err := m.memCache.VectorSearch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := vectordb.ParseFilter(filters)
+	if err != nil {
+		return nil, err
+	}
+	out := results[:0]
+	for _, r := range results {
+		if expr.Match(r.Payload) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestLookupPurgesExpiredCandidates(t *testing.T) {
+	setClock(t, time.Unix(1_700_000_000, 0))
+	c := residualCache{newMemCache()}
+	ctx := context.Background()
+
+	store := func(q string, vec []float64, ttl time.Duration) {
+		_, err := Store(ctx, c, "cache", vec, Entry{Query: q, Answer: q}, ttl)
+		require.NoError(t, err)
+	}
+	store("live", []float64{0.96, 0.28}, 0)
+	for i, y := range []float64{0, 0.01, 0.02} {
+		store(fmt.Sprintf("expired %d", i), []float64{1, y}, time.Minute)
+	}
+
+	setClock(t, time.Unix(1_700_000_060, 0))
+	hit, err := Lookup(ctx, c, "cache", []float64{1, 0}, LookupOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, hit, "expired entries must not hide a live one")
+	assert.Equal(t, "live", hit.Answer)
+	assert.Len(t, c.docs, 1, "the expired entries are purged")
+}
@@ -96,18 +96,18 @@ func (a *Activity) Eval(ctx activity.Context) (done bool, err error) {
 		event.ID, event.Event, len(event.Data))
 
 	// Send event based on target type
-	sentCount, err := a.sendEvent(server, target, event)
+	sentCount, eventID, err := a.sendEvent(server, target, event)
 	if err != nil {
 		return a.setErrorOutput(ctx, fmt.Sprintf("Failed to send event: %v", err))
 	}
 	a.logger.Infof("Successfully sent SSE event (id=%s) to %d clients via %s target",
-		event.ID, sentCount, target.Type)
+		eventID, sentCount, target.Type)
 
 	// Set success output
 	output := &Output{
 		Success:   true,
 		SentCount: sentCount,
-		EventID:   event.ID,
+		EventID:   eventID,
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 
@@ -249,12 +249,6 @@ func (a *Activity) getSSEServer() (sse.SSEServerInterface, error) {
 
 // createSSEEvent creates an SSE event from input and settings
 func (a *Activity) createSSEEvent(input *Input) (*sse.SSEEventData, error) {
-	// Generate event ID if not provided
-	eventID := input.EventID
-	if eventID == "" {
-		eventID = fmt.Sprintf("evt_%d_%d", time.Now().UnixNano(), time.Now().Unix()%1000)
-	}
-
 	// Use input eventType if provided, otherwise use setting (input takes precedence)
 	eventType := input.EventType
 	if eventType == "" {
@@ -287,7 +281,7 @@ func (a *Activity) createSSEEvent(input *Input) (*sse.SSEEventData, error) {
 	}
 
 	event := &sse.SSEEventData{
-		ID:    eventID,
+		ID:    input.EventID, // empty: the trigger assigns a sequential ID on send
 		Event: eventType,
 		Data:  dataStr,
 		Retry: retry,
@@ -362,34 +356,58 @@ func isComplexType(data interface{}) bool {
 	}
 }
 
-// sendEvent sends the event to the appropriate target
-func (a *Activity) sendEvent(server sse.SSEServerInterface, target *ParsedTarget, event *sse.SSEEventData) (int, error) {
+// sendEvent sends the event to the appropriate target and returns the number
+// of clients it went to and the ID it was sent with. The SSE trigger assigns
+// the ID of an event sent without one; for servers that do not report
+// assigned IDs one is generated here.
+func (a *Activity) sendEvent(server sse.SSEServerInterface, target *ParsedTarget, event *sse.SSEEventData) (int, string, error) {
+	idSender, reportsID := server.(sse.SSEEventIDSender)
+	if !reportsID && event.ID == "" {
+		event.ID = fmt.Sprintf("evt_%d", time.Now().UnixNano())
+	}
+	eventID := event.ID
+
 	switch target.Type {
 	case TargetAll:
 		a.logger.Debugf("Broadcasting event to all connected clients")
-		err := server.BroadcastEvent(event)
+		var err error
+		if reportsID {
+			eventID, err = idSender.BroadcastEventWithID(event)
+		} else {
+			err = server.BroadcastEvent(event)
+		}
 		if err != nil {
-			return 0, err
+			return 0, "", err
 		}
 		// Count active connections
 		connections := server.GetActiveConnections()
 		a.logger.Debugf("Broadcast sent to %d active connections", len(connections))
-		return len(connections), nil
+		return len(connections), eventID, nil
 
 	case TargetConnection:
 		a.logger.Debugf("Sending event to specific connection: %s", target.Identifier)
-		err := server.SendEventToConnection(target.Identifier, event)
+		var err error
+		if reportsID {
+			eventID, err = idSender.SendEventToConnectionWithID(target.Identifier, event)
+		} else {
+			err = server.SendEventToConnection(target.Identifier, event)
+		}
 		if err != nil {
 			a.logger.Errorf("Failed to send to connection %s: %v", target.Identifier, err)
-			return 0, err
+			return 0, "", err
 		}
-		return 1, nil
+		return 1, eventID, nil
 
 	case TargetTopic:
 		a.logger.Debugf("Broadcasting event to topic: %s", target.Identifier)
-		err := server.BroadcastEventToTopic(target.Identifier, event)
+		var err error
+		if reportsID {
+			eventID, err = idSender.BroadcastEventToTopicWithID(target.Identifier, event)
+		} else {
+			err = server.BroadcastEventToTopic(target.Identifier, event)
+		}
 		if err != nil {
-			return 0, err
+			return 0, "", err
 		}
 		// Count connections subscribed to this topic
 		connections := server.GetActiveConnections()
@@ -400,10 +418,10 @@ func (a *Activity) sendEvent(server sse.SSEServerInterface, target *ParsedTarget
 			}
 		}
 		a.logger.Debugf("Topic broadcast sent to %d matching connections", count)
-		return count, nil
+		return count, eventID, nil
 
 	default:
-		return 0, fmt.Errorf("unsupported target type")
+		return 0, "", fmt.Errorf("unsupported target type")
 	}
 }
 
@@ -3,8 +3,11 @@ package ssesend
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/project-flogo/core/support/log"
+
 	sse "github.com/mpandav-tibco/flogo-custom-extensions/sse/trigger"
 )
 
@@ -285,3 +288,62 @@ func TestSSESendActivity_IsComplexType(t *testing.T) {
 		}
 	}
 }
+
+// IDSSEServer also reports the IDs it assigns, like the SSE trigger.
+type IDSSEServer struct {
+	SimpleSSEServer
+	nextID int
+}
+
+func (s *IDSSEServer) assign(event *sse.SSEEventData) string {
+	if event.ID != "" {
+		return event.ID
+	}
+	s.nextID++
+	return fmt.Sprintf("srv-%d", s.nextID)
+}
+
+func (s *IDSSEServer) BroadcastEventWithID(event *sse.SSEEventData) (string, error) {
+	return s.assign(event), s.BroadcastEvent(event)
+}
+
+func (s *IDSSEServer) BroadcastEventToTopicWithID(topic string, event *sse.SSEEventData) (string, error) {
+	return s.assign(event), s.BroadcastEventToTopic(topic, event)
+}
+
+func (s *IDSSEServer) SendEventToConnectionWithID(connectionID string, event *sse.SSEEventData) (string, error) {
+	return s.assign(event), s.SendEventToConnection(connectionID, event)
+}
+
+func TestSSESendActivity_SendEventReturnsID(t *testing.T) {
+	act := &Activity{logger: log.RootLogger()}
+	targets := []*ParsedTarget{
+		{Type: TargetAll},
+		{Type: TargetTopic, Identifier: "orders"},
+		{Type: TargetConnection, Identifier: "c1"},
+	}
+
+	server := &IDSSEServer{}
+	for i, target := range targets {
+		_, id, err := act.sendEvent(server, target, &sse.SSEEventData{Data: "x"})
+		if err != nil {
+			t.Fatalf("target %d: %v", target.Type, err)
+		}
+		if want := fmt.Sprintf("srv-%d", i+1); id != want {
+			t.Errorf("target %d: expected the server-assigned ID %q, got %q", target.Type, want, id)
+		}
+	}
+	if _, id, _ := act.sendEvent(server, targets[0], &sse.SSEEventData{ID: "own", Data: "x"}); id != "own" {
+		t.Errorf("expected the caller's ID, got %q", id)
+	}
+
+	// A server that does not report IDs gets one generated by the activity.
+	simple := &SimpleSSEServer{}
+	_, id, err := act.sendEvent(simple, targets[0], &sse.SSEEventData{Data: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(id, "evt_") || simple.events[0].ID != id {
+		t.Errorf("expected a generated evt_ ID on the sent event, got %q / %q", id, simple.events[0].ID)
+	}
+}
@@ -12,6 +12,12 @@ type Settings struct {
 	CORSEnabled bool `md:"corsEnabled"`
 	// HeartbeatInterval is the keep-alive heartbeat interval in seconds (0 = disabled).
 	HeartbeatInterval int `md:"heartbeatInterval"`
+	// ReplayBufferSize is the number of recent events kept per topic for
+	// Last-Event-ID replay on reconnect (0 = disabled).
+	ReplayBufferSize int `md:"replayBufferSize"`
+	// ReplayBufferTTL is how long, in seconds, buffered events stay replayable
+	// (0 = until evicted by newer events).
+	ReplayBufferTTL int `md:"replayBufferTTL"`
 }
 
 // HandlerSettings define per-handler configuration.
@@ -0,0 +1,174 @@
+package sse
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── Replay buffer ─────────────────────────────────────────────────────────────
+
+// formatEventID returns the ID assigned to an event sent without one:
+// the issuing trigger's epoch and the event's sequence number.
+func formatEventID(epoch string, seq uint64) string {
+	return epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseEventID returns the sequence number of an ID formatted by
+// formatEventID with epoch. ok is false for any other ID, including
+// numeric IDs chosen by senders or clients.
+func parseEventID(id, epoch string) (seq uint64, ok bool) {
+	rest, found := strings.CutPrefix(id, epoch+"-")
+	if !found {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(rest, 10, 64)
+	return seq, err == nil
+}
+
+// replayEntry is one buffered event. seq is the trigger-wide sequence number
+// assigned at broadcast time; it orders entries across topics and is part of
+// the event ID whenever the sender did not supply one.
+type replayEntry struct {
+	seq   uint64
+	at    time.Time
+	event SSEEventData
+}
+
+// replayRing is a fixed-capacity FIFO of entries; the oldest entry is
+// overwritten once the ring is full.
+type replayRing struct {
+	buf  []replayEntry
+	head int
+	n    int
+}
+
+func newReplayRing(size int) *replayRing {
+	return &replayRing{buf: make([]replayEntry, size)}
+}
+
+func (r *replayRing) push(e replayEntry) {
+	if r.n < len(r.buf) {
+		r.buf[(r.head+r.n)%len(r.buf)] = e
+		r.n++
+		return
+	}
+	r.buf[r.head] = e
+	r.head = (r.head + 1) % len(r.buf)
+}
+
+// dropBefore evicts entries buffered before cutoff.
+func (r *replayRing) dropBefore(cutoff time.Time) {
+	for r.n > 0 && r.buf[r.head].at.Before(cutoff) {
+		r.buf[r.head] = replayEntry{}
+		r.head = (r.head + 1) % len(r.buf)
+		r.n--
+	}
+}
+
+func (r *replayRing) each(fn func(e *replayEntry)) {
+	for i := 0; i < r.n; i++ {
+		fn(&r.buf[(r.head+i)%len(r.buf)])
+	}
+}
+
+// replayBuffer keeps the most recent events per topic so a reconnecting
+// client can be sent what it missed. Events from BroadcastEvent go to a
+// dedicated ring that every connection replays; events from
+// BroadcastEventToTopic go to the ring of their topic.
+type replayBuffer struct {
+	size  int
+	ttl   time.Duration
+	epoch string // of the IDs the trigger assigns
+
+	mu     sync.Mutex
+	all    *replayRing
+	topics map[string]*replayRing
+}
+
+func newReplayBuffer(size int, ttl time.Duration, epoch string) *replayBuffer {
+	return &replayBuffer{
+		size:   size,
+		ttl:    ttl,
+		epoch:  epoch,
+		all:    newReplayRing(size),
+		topics: make(map[string]*replayRing),
+	}
+}
+
+// add buffers a copy of event. broadcast is true for events sent to every
+// connection, in which case topic is ignored.
+func (b *replayBuffer) add(seq uint64, broadcast bool, topic string, event *SSEEventData) {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ring := b.all
+	if !broadcast {
+		ring = b.topics[topic]
+		if ring == nil {
+			ring = newReplayRing(b.size)
+			b.topics[topic] = ring
+		}
+	}
+	if b.ttl > 0 {
+		ring.dropBefore(now.Add(-b.ttl))
+	}
+	ring.push(replayEntry{seq: seq, at: now, event: *event})
+}
+
+// since returns, oldest first, the buffered events a connection subscribed to
+// topic would have received after the event identified by lastEventID. The ID
+// is matched against buffered event IDs first; failing that, an ID this
+// trigger assigned is placed by its sequence number, so a client whose last
+// event has already been evicted still gets everything still retained. ok is
+// false when the ID cannot be placed, in which case nothing is replayed: the
+// client's own IDs and IDs issued by another trigger instance say nothing
+// about this buffer.
+func (b *replayBuffer) since(lastEventID, topic string) (events []SSEEventData, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rings := []*replayRing{b.all}
+	if topic == "" {
+		// Unfiltered connections receive every topic's events.
+		for _, r := range b.topics {
+			rings = append(rings, r)
+		}
+	} else if r := b.topics[topic]; r != nil {
+		rings = append(rings, r)
+	}
+
+	var cutoff time.Time
+	if b.ttl > 0 {
+		cutoff = time.Now().Add(-b.ttl)
+	}
+	var entries []replayEntry
+	anchor, found := uint64(0), false
+	for _, r := range rings {
+		r.each(func(e *replayEntry) {
+			if e.at.Before(cutoff) {
+				return
+			}
+			if e.event.ID == lastEventID && (!found || e.seq > anchor) {
+				anchor, found = e.seq, true
+			}
+			entries = append(entries, *e)
+		})
+	}
+	if !found {
+		if anchor, found = parseEventID(lastEventID, b.epoch); !found {
+			return nil, false
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	for _, e := range entries {
+		if e.seq > anchor {
+			events = append(events, e.event)
+		}
+	}
+	return events, true
+}
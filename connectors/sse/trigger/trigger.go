@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/project-flogo/core/data/metadata"
@@ -51,6 +53,15 @@ type SSEServerInterface interface {
 	GetActiveConnections() []ConnectionInfo
 }
 
+// SSEEventIDSender is implemented by SSE servers that report the ID each
+// event was sent with, including IDs they assign to events sent without
+// one. The SSE Send activity uses it for its eventId output.
+type SSEEventIDSender interface {
+	BroadcastEventWithID(event *SSEEventData) (string, error)
+	BroadcastEventToTopicWithID(topic string, event *SSEEventData) (string, error)
+	SendEventToConnectionWithID(connectionID string, event *SSEEventData) (string, error)
+}
+
 // ── Global registry ───────────────────────────────────────────────────────────
 
 var (
@@ -117,6 +128,13 @@ type Trigger struct {
 	conns    map[string]*connection
 	stopOnce sync.Once
 	done     chan struct{}
+
+	// seq is the last assigned event sequence number. Assigned IDs carry
+	// epoch, which is unique to this trigger instance, so a Last-Event-ID
+	// is only read as a sequence number when this instance issued it.
+	seq    uint64
+	epoch  string
+	replay *replayBuffer // nil when replayBufferSize is 0
 }
 
 // Factory creates Trigger instances.
@@ -140,11 +158,22 @@ func (*Factory) New(config *trigger.Config) (trigger.Trigger, error) {
 	if s.HeartbeatInterval == 0 {
 		s.HeartbeatInterval = 30
 	}
-	return &Trigger{
+	if s.ReplayBufferSize < 0 {
+		return nil, fmt.Errorf("sse-trigger: replayBufferSize must be >= 0, got %d", s.ReplayBufferSize)
+	}
+	if s.ReplayBufferTTL < 0 {
+		return nil, fmt.Errorf("sse-trigger: replayBufferTTL must be >= 0, got %d", s.ReplayBufferTTL)
+	}
+	t := &Trigger{
 		settings: s,
 		conns:    make(map[string]*connection),
 		done:     make(chan struct{}),
-	}, nil
+		epoch:    strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	if s.ReplayBufferSize > 0 {
+		t.replay = newReplayBuffer(s.ReplayBufferSize, time.Duration(s.ReplayBufferTTL)*time.Second, t.epoch)
+	}
+	return t, nil
 }
 
 // Metadata returns the trigger metadata.
@@ -246,8 +275,18 @@ func (t *Trigger) handleSSE(w http.ResponseWriter, r *http.Request) {
 		done:  make(chan struct{}),
 	}
 
+	// Registering the connection and reading the replay buffer under the
+	// write lock means every broadcast is either in the replay or delivered
+	// live on conn.ch, never both and never neither.
+	var missed []SSEEventData
 	t.mu.Lock()
 	t.conns[connID] = conn
+	if t.replay != nil && lastEventID != "" {
+		var ok bool
+		if missed, ok = t.replay.since(lastEventID, topic); !ok {
+			t.logger.Debugf("SSE connection %s: Last-Event-ID %q is not in the replay buffer, nothing to replay", connID, lastEventID)
+		}
+	}
 	t.mu.Unlock()
 
 	defer func() {
@@ -261,6 +300,14 @@ func (t *Trigger) handleSSE(w http.ResponseWriter, r *http.Request) {
 	go t.fireHandlers(r, connID, topic, lastEventID)
 
 	bw := bufio.NewWriter(w)
+	if len(missed) > 0 {
+		t.logger.Debugf("SSE connection %s: replaying %d events after %q", connID, len(missed), lastEventID)
+		for i := range missed {
+			writeSSEEvent(bw, &missed[i])
+		}
+		bw.Flush()
+		flusher.Flush()
+	}
 	for {
 		select {
 		case evt, ok := <-conn.ch:
@@ -339,7 +386,8 @@ func (t *Trigger) runHeartbeat() {
 	for {
 		select {
 		case <-ticker.C:
-			_ = t.BroadcastEvent(&SSEEventData{Event: "heartbeat", Data: "ping"})
+			// Heartbeats carry no ID and are not buffered for replay.
+			t.fanOut(func(*connection) bool { return true }, &SSEEventData{Event: "heartbeat", Data: "ping"})
 		case <-t.done:
 			return
 		}
@@ -348,51 +396,103 @@ func (t *Trigger) runHeartbeat() {
 
 // ── SSEServerInterface implementation ────────────────────────────────────────
 
-// BroadcastEvent sends an event to all active connections.
-func (t *Trigger) BroadcastEvent(event *SSEEventData) error {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+// assignID takes the next sequence number for event. When the sender left the
+// ID empty, it returns a copy of event with an ID made of the trigger's epoch
+// and the sequence number.
+func (t *Trigger) assignID(event *SSEEventData) (*SSEEventData, uint64) {
+	seq := atomic.AddUint64(&t.seq, 1)
+	if event.ID != "" {
+		return event, seq
+	}
+	// Assign on a copy: callers that reuse the event struct must not resend
+	// the previous event's ID.
+	assigned := *event
+	assigned.ID = formatEventID(t.epoch, seq)
+	return &assigned, seq
+}
+
+// fanOut queues event on every connection accepted by match. Callers must
+// hold t.mu (read or write).
+func (t *Trigger) fanOut(match func(*connection) bool, event *SSEEventData) {
 	for _, conn := range t.conns {
+		if !match(conn) {
+			continue
+		}
 		select {
 		case conn.ch <- event:
 		default:
 			// Drop for slow consumers rather than blocking the caller.
 		}
 	}
-	return nil
+}
+
+// BroadcastEvent sends an event to all active connections. An event without
+// an ID is sent with an auto-assigned, monotonically increasing ID; the
+// caller's event is left unchanged.
+func (t *Trigger) BroadcastEvent(event *SSEEventData) error {
+	_, err := t.BroadcastEventWithID(event)
+	return err
+}
+
+// BroadcastEventWithID is BroadcastEvent, returning the ID the event was
+// sent with.
+func (t *Trigger) BroadcastEventWithID(event *SSEEventData) (string, error) {
+	event, seq := t.assignID(event)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.replay != nil {
+		t.replay.add(seq, true, "", event)
+	}
+	t.fanOut(func(*connection) bool { return true }, event)
+	return event.ID, nil
 }
 
 // BroadcastEventToTopic sends an event to connections subscribed to a topic
-// (or to all connections that have no topic filter).
+// (or to all connections that have no topic filter). An event without an ID
+// is sent with an auto-assigned, monotonically increasing ID; the caller's
+// event is left unchanged.
 func (t *Trigger) BroadcastEventToTopic(topic string, event *SSEEventData) error {
+	_, err := t.BroadcastEventToTopicWithID(topic, event)
+	return err
+}
+
+// BroadcastEventToTopicWithID is BroadcastEventToTopic, returning the ID the
+// event was sent with.
+func (t *Trigger) BroadcastEventToTopicWithID(topic string, event *SSEEventData) (string, error) {
+	event, seq := t.assignID(event)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	for _, conn := range t.conns {
-		if conn.topic == topic || conn.topic == "" {
-			select {
-			case conn.ch <- event:
-			default:
-			}
-		}
+	if t.replay != nil {
+		t.replay.add(seq, false, topic, event)
 	}
-	return nil
+	t.fanOut(func(conn *connection) bool { return conn.topic == topic || conn.topic == "" }, event)
+	return event.ID, nil
 }
 
 // SendEventToConnection sends an event to a specific connection by ID.
+// Direct sends get an ID like broadcasts but are not buffered for replay.
 func (t *Trigger) SendEventToConnection(connectionID string, event *SSEEventData) error {
+	_, err := t.SendEventToConnectionWithID(connectionID, event)
+	return err
+}
+
+// SendEventToConnectionWithID is SendEventToConnection, returning the ID the
+// event was sent with.
+func (t *Trigger) SendEventToConnectionWithID(connectionID string, event *SSEEventData) (string, error) {
+	event, _ = t.assignID(event)
 	t.mu.RLock()
 	conn, ok := t.conns[connectionID]
 	t.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("sse-trigger: connection %q not found", connectionID)
+		return "", fmt.Errorf("sse-trigger: connection %q not found", connectionID)
 	}
 	select {
 	case conn.ch <- event:
-		return nil
+		return event.ID, nil
 	case <-conn.done:
-		return fmt.Errorf("sse-trigger: connection %q is closed", connectionID)
+		return "", fmt.Errorf("sse-trigger: connection %q is closed", connectionID)
 	default:
-		return fmt.Errorf("sse-trigger: send buffer full for connection %q", connectionID)
+		return "", fmt.Errorf("sse-trigger: send buffer full for connection %q", connectionID)
 	}
 }
 
@@ -0,0 +1,246 @@
+package sse
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/project-flogo/core/trigger"
+)
+
+func newTestTrigger(t *testing.T, settings map[string]interface{}) *Trigger {
+	t.Helper()
+	trg, err := (&Factory{}).New(&trigger.Config{Settings: settings})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tr := trg.(*Trigger)
+	tr.logger = log.RootLogger()
+	return tr
+}
+
+func eventIDs(events []SSEEventData) []string {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestFactory_ReplaySettings(t *testing.T) {
+	tr := newTestTrigger(t, map[string]interface{}{"port": 9998})
+	if tr.replay != nil {
+		t.Errorf("replay buffer should be disabled when replayBufferSize is unset")
+	}
+
+	tr = newTestTrigger(t, map[string]interface{}{"port": 9998, "replayBufferSize": 10, "replayBufferTTL": 60})
+	if tr.replay == nil || tr.replay.size != 10 || tr.replay.ttl != time.Minute {
+		t.Errorf("unexpected replay buffer: %+v", tr.replay)
+	}
+
+	if _, err := (&Factory{}).New(&trigger.Config{Settings: map[string]interface{}{"port": 9998, "replayBufferSize": -1}}); err == nil {
+		t.Errorf("expected error for negative replayBufferSize")
+	}
+}
+
+func TestBroadcast_AssignsMonotonicIDs(t *testing.T) {
+	tr := newTestTrigger(t, map[string]interface{}{"port": 9998})
+	conn := &connection{id: "c1", ch: make(chan *SSEEventData, 10), done: make(chan struct{})}
+	tr.conns[conn.id] = conn
+	received := func() *SSEEventData {
+		select {
+		case e := <-conn.ch:
+			return e
+		default:
+			t.Fatal("expected a queued event")
+			return nil
+		}
+	}
+
+	// The same struct is reused for every send, as a flow would.
+	event := &SSEEventData{Data: "a"}
+	_ = tr.BroadcastEvent(event)
+	first := received()
+	_ = tr.BroadcastEventToTopic("orders", event)
+	second := received()
+	_ = tr.BroadcastEvent(&SSEEventData{ID: "order-42", Data: "c"})
+	custom := received()
+
+	if event.ID != "" {
+		t.Errorf("caller's event modified: ID = %q", event.ID)
+	}
+	a, ok := parseEventID(first.ID, tr.epoch)
+	if !ok {
+		t.Fatalf("expected an assigned ID, got %q", first.ID)
+	}
+	b, ok := parseEventID(second.ID, tr.epoch)
+	if !ok {
+		t.Fatalf("expected an assigned ID, got %q", second.ID)
+	}
+	if b <= a {
+		t.Errorf("IDs not increasing: %d then %d", a, b)
+	}
+	if custom.ID != "order-42" {
+		t.Errorf("caller-provided ID overwritten: %q", custom.ID)
+	}
+}
+
+func TestSendWithID_ReturnsAssignedID(t *testing.T) {
+	tr := newTestTrigger(t, map[string]interface{}{"port": 9998})
+	conn := &connection{id: "c1", ch: make(chan *SSEEventData, 10), done: make(chan struct{})}
+	tr.conns[conn.id] = conn
+
+	sends := map[string]func(*SSEEventData) (string, error){
+		"broadcast": tr.BroadcastEventWithID,
+		"topic": func(e *SSEEventData) (string, error) {
+			return tr.BroadcastEventToTopicWithID("orders", e)
+		},
+		"connection": func(e *SSEEventData) (string, error) {
+			return tr.SendEventToConnectionWithID("c1", e)
+		},
+	}
+	for name, send := range sends {
+		id, err := send(&SSEEventData{Data: name})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if sent := <-conn.ch; id == "" || sent.ID != id {
+			t.Errorf("%s: returned ID %q, sent %q", name, id, sent.ID)
+		}
+		if id, _ := send(&SSEEventData{ID: "own", Data: name}); id != "own" {
+			t.Errorf("%s: returned ID %q for a caller-provided ID", name, id)
+		}
+		<-conn.ch
+	}
+}
+
+func TestReplayBuffer_EvictsOldest(t *testing.T) {
+	b := newReplayBuffer(3, 0, "e1")
+	for seq := uint64(1); seq <= 5; seq++ {
+		b.add(seq, true, "", &SSEEventData{ID: formatEventID("e1", seq)})
+	}
+
+	events, ok := b.since("e1-3", "")
+	if !ok {
+		t.Fatal("expected ID e1-3 to be found")
+	}
+	if got := strings.Join(eventIDs(events), ","); got != "e1-4,e1-5" {
+		t.Errorf("since e1-3 = %s, want e1-4,e1-5", got)
+	}
+
+	// e1-1 was evicted; as an ID this trigger assigned it still places the
+	// client before everything retained.
+	events, ok = b.since("e1-1", "")
+	if !ok {
+		t.Fatal("expected an assigned ID to be accepted")
+	}
+	if got := strings.Join(eventIDs(events), ","); got != "e1-3,e1-4,e1-5" {
+		t.Errorf("since e1-1 = %s, want e1-3,e1-4,e1-5", got)
+	}
+
+	// Neither a client's own numeric ID nor one issued by another trigger
+	// instance can be placed.
+	for _, id := range []string{"unknown", "1", "9999", "e2-1", "e1-x"} {
+		if _, ok := b.since(id, ""); ok {
+			t.Errorf("expected %q to be rejected", id)
+		}
+	}
+}
+
+func TestReplayBuffer_TTL(t *testing.T) {
+	b := newReplayBuffer(10, time.Hour, "e1")
+	b.add(1, true, "", &SSEEventData{ID: "e1-1"})
+	b.add(2, true, "", &SSEEventData{ID: "e1-2"})
+	b.all.buf[b.all.head].at = time.Now().Add(-2 * time.Hour)
+
+	events, ok := b.since("e1-0", "")
+	if !ok {
+		t.Fatal("expected an assigned ID to be accepted")
+	}
+	if got := strings.Join(eventIDs(events), ","); got != "e1-2" {
+		t.Errorf("since e1-0 = %s, want e1-2 (e1-1 expired)", got)
+	}
+}
+
+func TestReplayBuffer_TopicRouting(t *testing.T) {
+	b := newReplayBuffer(10, 0, "e1")
+	b.add(1, false, "orders", &SSEEventData{ID: "o1"})
+	b.add(2, false, "prices", &SSEEventData{ID: "p1"})
+	b.add(3, true, "", &SSEEventData{ID: "all1"})
+	b.add(4, false, "orders", &SSEEventData{ID: "o2"})
+
+	tests := []struct {
+		topic string
+		found bool
+		want  string
+	}{
+		{"orders", true, "all1,o2"},
+		{"", true, "p1,all1,o2"},
+		// o1 is not visible to prices subscribers and was not assigned.
+		{"prices", false, ""},
+	}
+	for _, tc := range tests {
+		events, ok := b.since("o1", tc.topic)
+		if ok != tc.found {
+			t.Errorf("topic %q: found = %v, want %v", tc.topic, ok, tc.found)
+			continue
+		}
+		if got := strings.Join(eventIDs(events), ","); got != tc.want {
+			t.Errorf("topic %q: since o1 = %s, want %s", tc.topic, got, tc.want)
+		}
+	}
+}
+
+func TestHandleSSE_ReplaysMissedEventsBeforeLive(t *testing.T) {
+	tr := newTestTrigger(t, map[string]interface{}{"port": 9998, "replayBufferSize": 10})
+	srv := httptest.NewServer(http.HandlerFunc(tr.handleSSE))
+	defer srv.Close()
+
+	first := &SSEEventData{ID: "order-1", Data: "one"}
+	_ = tr.BroadcastEventToTopic("orders", first)
+	_ = tr.BroadcastEventToTopic("orders", &SSEEventData{Data: "two"})
+	_ = tr.BroadcastEventToTopic("prices", &SSEEventData{Data: "skip"})
+	_ = tr.BroadcastEvent(&SSEEventData{Event: "update", Data: "three"})
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"?topic=orders", nil)
+	req.Header.Set("Last-Event-ID", first.ID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			if strings.HasPrefix(sc.Text(), "data: ") {
+				lines <- strings.TrimPrefix(sc.Text(), "data: ")
+			}
+		}
+		close(lines)
+	}()
+	next := func() string {
+		select {
+		case l := <-lines:
+			return l
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return ""
+		}
+	}
+
+	for _, want := range []string{"two", "three"} {
+		if got := next(); got != want {
+			t.Fatalf("replayed %q, want %q", got, want)
+		}
+	}
+	_ = tr.BroadcastEventToTopic("orders", &SSEEventData{Data: "live"})
+	if got := next(); got != "live" {
+		t.Fatalf("live event %q, want %q", got, "live")
+	}
+}
@@ -0,0 +1,435 @@
+// Package cdccheckpoint is the durable position store shared by the CDC
+// triggers (mongodb-cdc-listener, mysql-binlog-listener and
+// sqlserver-cdc-listener). A trigger resumes from the position committed by
+// a previous run instead of replaying or skipping changes after a restart.
+//
+// The file backend is always available. The sql backend needs a dialect
+// package for the checkpoint database, which also loads its database/sql
+// driver:
+//
+//	import _ "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres"
+//
+// so each trigger links only the drivers it offers.
+package cdccheckpoint
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-flogo/core/support/log"
+)
+
+// Checkpoint store backends.
+const (
+	StoreNone = "none"
+	StoreFile = "file"
+	StoreSQL  = "sql"
+)
+
+// Checkpoint modes.
+const (
+	// ModeOnSuccess advances the position only after the flow for an event
+	// completed successfully; a failed flow stops the stream so the event is
+	// redelivered on reconnect or restart (at-least-once).
+	ModeOnSuccess = "onSuccess"
+	// ModeAlways advances the position whether or not the flow succeeded;
+	// flow errors are logged and the stream moves on (at-most-once for failed
+	// events, no redelivery loops).
+	ModeAlways = "always"
+)
+
+const (
+	// DefaultTable is the checkpoint table used when Config.Table is empty.
+	DefaultTable = "flogo_cdc_checkpoints"
+	// OpenTimeout bounds opening a store, including creating the SQL table.
+	OpenTimeout = 30 * time.Second
+
+	saveTimeout = 10 * time.Second
+)
+
+var tablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Store persists stream positions so a restarted trigger resumes where it
+// left off. Positions are opaque strings owned by the listener that produced
+// them; the store only keys them by checkpoint key.
+type Store interface {
+	// Load returns the stored position for key, or "" when none is stored.
+	Load(ctx context.Context, key string) (string, error)
+	// Save durably records position as the latest position for key.
+	Save(ctx context.Context, key, position string) error
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// Config selects and configures a checkpoint backend.
+type Config struct {
+	Store    string // none, file, sql
+	File     string // checkpoint file path (file store)
+	Driver   string // registered dialect, e.g. postgres (sql store)
+	DSN      string // driver connection string (sql store)
+	Table    string // checkpoint table (sql store, default flogo_cdc_checkpoints)
+	Interval int    // commit every N position advances (default 1)
+	Mode     string // onSuccess (default) or always
+}
+
+// Enabled reports whether a durable checkpoint store is configured.
+func (c Config) Enabled() bool {
+	s := strings.ToLower(strings.TrimSpace(c.Store))
+	return s != "" && s != StoreNone
+}
+
+// AdvanceOnError reports whether positions advance past events whose flow failed.
+func (c Config) AdvanceOnError() bool {
+	return c.Enabled() && strings.EqualFold(strings.TrimSpace(c.Mode), ModeAlways)
+}
+
+// Validate checks the checkpoint configuration.
+func (c Config) Validate() error {
+	switch strings.ToLower(strings.TrimSpace(c.Mode)) {
+	case "", strings.ToLower(ModeOnSuccess), ModeAlways:
+	default:
+		return fmt.Errorf("invalid checkpointMode %q (expected onSuccess or always)", c.Mode)
+	}
+	if c.Interval < 0 {
+		return fmt.Errorf("checkpointInterval must not be negative")
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Store)) {
+	case "", StoreNone:
+		return nil
+	case StoreFile:
+		if strings.TrimSpace(c.File) == "" {
+			return fmt.Errorf("checkpointFile is required when checkpointStore is file")
+		}
+		return nil
+	case StoreSQL:
+		if _, err := dialectFor(c.Driver); err != nil {
+			return err
+		}
+		if strings.TrimSpace(c.DSN) == "" {
+			return fmt.Errorf("checkpointDSN is required when checkpointStore is sql")
+		}
+		if c.Table != "" && !tablePattern.MatchString(c.Table) {
+			return fmt.Errorf("invalid checkpointTable %q", c.Table)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid checkpointStore %q (expected none, file, or sql)", c.Store)
+	}
+}
+
+// Open opens the backend selected by cfg. It returns a nil store when
+// checkpointing is disabled.
+func Open(ctx context.Context, cfg Config) (Store, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.Store)) {
+	case StoreFile:
+		return newFileStore(cfg.File), nil
+	case StoreSQL:
+		return newSQLStore(ctx, cfg)
+	default:
+		return nil, nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// File backend
+// ---------------------------------------------------------------------------
+
+// fileLocks serialises read-modify-write cycles per file path, so several
+// triggers in one process can share a checkpoint file.
+var fileLocks sync.Map
+
+type fileEntry struct {
+	Position  string    `json:"position"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// fileStore keeps all checkpoints in a single JSON document keyed by
+// checkpoint key. Every save rewrites the document through a temporary file
+// and an atomic rename, so a crash never leaves a torn file behind.
+type fileStore struct {
+	path string
+	mu   *sync.Mutex
+}
+
+func newFileStore(path string) *fileStore {
+	path = filepath.Clean(path)
+	mu, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	return &fileStore{path: path, mu: mu.(*sync.Mutex)}
+}
+
+func (s *fileStore) Load(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	return entries[key].Position, nil
+}
+
+func (s *fileStore) Save(_ context.Context, key, position string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	entries[key] = fileEntry{Position: position, UpdatedAt: time.Now().UTC()}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoints: %w", err)
+	}
+	return s.writeAtomic(data)
+}
+
+func (s *fileStore) Close() error { return nil }
+
+func (s *fileStore) read() (map[string]fileEntry, error) {
+	entries := make(map[string]fileEntry)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint file %q: %w", s.path, err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file %q: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+func (s *fileStore) writeAtomic(data []byte) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory %q: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary checkpoint file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmpName, s.path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint file %q: %w", s.path, err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// SQL table backend
+// ---------------------------------------------------------------------------
+
+// Dialect holds the database/sql driver name and statements for one
+// checkpoint database. %s in each statement is replaced by the (validated)
+// table name.
+type Dialect struct {
+	Driver string // database/sql driver name
+	Create string // create the table when missing
+	Load   string // select position by checkpoint_key
+	Upsert string // insert or update (checkpoint_key, position, updated_at)
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = make(map[string]Dialect)
+)
+
+// RegisterDialect makes a checkpoint database available under name. Dialect
+// packages call it from init after importing their driver.
+func RegisterDialect(name string, d Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[strings.ToLower(name)] = d
+}
+
+// Dialects returns the names of the registered checkpoint databases.
+func Dialects() []string {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func dialectFor(driver string) (Dialect, error) {
+	dialectsMu.RLock()
+	d, ok := dialects[strings.ToLower(strings.TrimSpace(driver))]
+	dialectsMu.RUnlock()
+	if !ok {
+		return Dialect{}, fmt.Errorf("invalid checkpointDriver %q (expected %s)", driver, strings.Join(Dialects(), ", "))
+	}
+	return d, nil
+}
+
+// sqlStore keeps one row per checkpoint key in a database table, creating
+// the table on first use.
+type sqlStore struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+}
+
+func newSQLStore(ctx context.Context, cfg Config) (*sqlStore, error) {
+	dialect, err := dialectFor(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
+	table := cfg.Table
+	if table == "" {
+		table = DefaultTable
+	}
+	db, err := sql.Open(dialect.Driver, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint database: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(dialect.Create, table)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create checkpoint table %q: %w", table, err)
+	}
+	return &sqlStore{db: db, dialect: dialect, table: table}, nil
+}
+
+func (s *sqlStore) Load(ctx context.Context, key string) (string, error) {
+	var position string
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(s.dialect.Load, s.table), key).Scan(&position)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load checkpoint %q: %w", key, err)
+	}
+	return position, nil
+}
+
+func (s *sqlStore) Save(ctx context.Context, key, position string) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(s.dialect.Upsert, s.table), key, position, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint %q: %w", key, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// ---------------------------------------------------------------------------
+// Checkpointer
+// ---------------------------------------------------------------------------
+
+// Checkpointer tracks the latest handled position of one handler and commits
+// it to the store every interval advances. All methods are safe on a nil
+// receiver, which stands for "checkpointing disabled".
+type Checkpointer struct {
+	store    Store
+	key      string
+	interval int
+	logger   log.Logger
+
+	mu       sync.Mutex
+	position string // latest advanced position
+	pending  int    // advances since the last successful save
+}
+
+// NewCheckpointer returns a Checkpointer for key, or nil when store is nil.
+func NewCheckpointer(store Store, key string, interval int, logger log.Logger) *Checkpointer {
+	if store == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = 1
+	}
+	return &Checkpointer{store: store, key: key, interval: interval, logger: logger}
+}
+
+// Load returns the committed position, or "" when none is stored.
+func (c *Checkpointer) Load(ctx context.Context) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	position, err := c.store.Load(ctx, c.key)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.position = position
+	c.pending = 0
+	c.mu.Unlock()
+	return position, nil
+}
+
+// Advance records position as handled and commits it once interval advances
+// have accumulated. A failed commit is logged and retried on the next advance.
+func (c *Checkpointer) Advance(ctx context.Context, position string) {
+	if c == nil || position == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if position == c.position && c.pending == 0 {
+		return
+	}
+	c.position = position
+	c.pending++
+	if c.pending >= c.interval {
+		c.saveLocked(ctx)
+	}
+}
+
+// Flush commits any advanced but not yet committed position.
+func (c *Checkpointer) Flush(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending > 0 {
+		c.saveLocked(ctx)
+	}
+}
+
+func (c *Checkpointer) saveLocked(ctx context.Context) {
+	// Commit even while the stream is shutting down: the position was reached
+	// by handled events, so dropping it would only cause redelivery.
+	if ctx.Err() != nil {
+		ctx = context.Background()
+	}
+	saveCtx, cancel := context.WithTimeout(ctx, saveTimeout)
+	defer cancel()
+	if err := c.store.Save(saveCtx, c.key, c.position); err != nil {
+		c.logger.Warnf("Failed to commit checkpoint %q: %v", c.key, err)
+		return
+	}
+	c.pending = 0
+}
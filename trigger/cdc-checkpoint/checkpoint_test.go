@@ -0,0 +1,189 @@
+package cdccheckpoint
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Stand-in for a dialect package; Validate only needs the registration.
+	RegisterDialect("postgres", Dialect{Driver: "postgres"})
+	RegisterDialect("sqlserver", Dialect{Driver: "sqlserver"})
+}
+
+// memoryStore is an in-memory Store that records saves.
+type memoryStore struct {
+	positions map[string]string
+	saves     int
+	failSaves bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{positions: make(map[string]string)}
+}
+
+func (m *memoryStore) Load(_ context.Context, key string) (string, error) {
+	return m.positions[key], nil
+}
+
+func (m *memoryStore) Save(_ context.Context, key, position string) error {
+	if m.failSaves {
+		return errors.New("store unavailable")
+	}
+	m.positions[key] = position
+	m.saves++
+	return nil
+}
+
+func (m *memoryStore) Close() error { return nil }
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "checkpoints.json")
+
+	store, err := Open(ctx, Config{Store: StoreFile, File: path})
+	require.NoError(t, err)
+
+	pos, err := store.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.Empty(t, pos, "missing file should load as empty")
+
+	require.NoError(t, store.Save(ctx, "a", "pos-1"))
+	require.NoError(t, store.Save(ctx, "b", "pos-2"))
+	require.NoError(t, store.Save(ctx, "a", "pos-3"))
+
+	// A fresh store on the same file sees the committed positions.
+	reopened, err := Open(ctx, Config{Store: StoreFile, File: path})
+	require.NoError(t, err)
+	pos, err = reopened.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "pos-3", pos)
+	pos, err = reopened.Load(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "pos-2", pos)
+
+	matches, err := filepath.Glob(path + ".tmp-*")
+	require.NoError(t, err)
+	assert.Empty(t, matches, "temporary files must not be left behind")
+}
+
+func TestOpen_NoneReturnsNil(t *testing.T) {
+	store, err := Open(context.Background(), Config{})
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	store, err = Open(context.Background(), Config{Store: "none"})
+	require.NoError(t, err)
+	assert.Nil(t, store)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"disabled", Config{}, false},
+		{"file", Config{Store: "file", File: "/tmp/cp.json"}, false},
+		{"file without path", Config{Store: "file"}, true},
+		{"sql postgres", Config{Store: "sql", Driver: "postgres", DSN: "postgres://localhost/db"}, false},
+		{"sql sqlserver custom table", Config{Store: "sql", Driver: "sqlserver", DSN: "sqlserver://sa@localhost", Table: "cdc.checkpoints"}, false},
+		{"sql unknown driver", Config{Store: "sql", Driver: "oracle", DSN: "x"}, true},
+		{"sql dialect not linked", Config{Store: "sql", Driver: "mysql", DSN: "x"}, true},
+		{"sql without dsn", Config{Store: "sql", Driver: "postgres"}, true},
+		{"sql unsafe table", Config{Store: "sql", Driver: "postgres", DSN: "x", Table: "t; DROP TABLE x"}, true},
+		{"unknown store", Config{Store: "redis"}, true},
+		{"mode always", Config{Store: "file", File: "cp.json", Mode: "always"}, false},
+		{"mode unknown", Config{Mode: "sometimes"}, true},
+		{"negative interval", Config{Interval: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDialects(t *testing.T) {
+	assert.Equal(t, []string{"postgres", "sqlserver"}, Dialects())
+
+	err := Config{Store: "sql", Driver: "mysql", DSN: "x"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected postgres, sqlserver")
+}
+
+func TestConfig_AdvanceOnError(t *testing.T) {
+	assert.False(t, Config{Mode: "always"}.AdvanceOnError(), "mode is ignored without a store")
+	assert.False(t, Config{Store: "file", File: "cp.json"}.AdvanceOnError())
+	assert.True(t, Config{Store: "file", File: "cp.json", Mode: "always"}.AdvanceOnError())
+}
+
+func TestCheckpointer_Interval(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	cp := NewCheckpointer(store, "k", 3, log.RootLogger())
+
+	cp.Advance(ctx, "1")
+	cp.Advance(ctx, "2")
+	assert.Equal(t, 0, store.saves, "no commit before the interval is reached")
+
+	cp.Advance(ctx, "3")
+	assert.Equal(t, 1, store.saves)
+	assert.Equal(t, "3", store.positions["k"])
+
+	cp.Advance(ctx, "4")
+	cp.Flush(ctx)
+	assert.Equal(t, 2, store.saves)
+	assert.Equal(t, "4", store.positions["k"])
+
+	cp.Flush(ctx)
+	assert.Equal(t, 2, store.saves, "flush without pending advances is a no-op")
+}
+
+func TestCheckpointer_RetriesFailedSave(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	cp := NewCheckpointer(store, "k", 1, log.RootLogger())
+
+	store.failSaves = true
+	cp.Advance(ctx, "1")
+	assert.Empty(t, store.positions["k"])
+
+	store.failSaves = false
+	cp.Flush(ctx)
+	assert.Equal(t, "1", store.positions["k"], "failed commit is retried on flush")
+}
+
+func TestCheckpointer_LoadAndNil(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	store.positions["k"] = "stored"
+
+	cp := NewCheckpointer(store, "k", 0, log.RootLogger())
+	pos, err := cp.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "stored", pos)
+
+	// Re-advancing to the loaded position does not rewrite it.
+	cp.Advance(ctx, "stored")
+	assert.Equal(t, 0, store.saves)
+
+	var disabled *Checkpointer
+	assert.Nil(t, NewCheckpointer(nil, "k", 1, log.RootLogger()))
+	pos, err = disabled.Load(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pos)
+	disabled.Advance(ctx, "x")
+	disabled.Flush(ctx)
+}
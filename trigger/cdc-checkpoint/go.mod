@@ -0,0 +1,17 @@
+module github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint
+
+go 1.22
+
+require (
+	github.com/project-flogo/core v1.6.9
+	github.com/stretchr/testify v1.4.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
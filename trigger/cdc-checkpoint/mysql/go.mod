@@ -0,0 +1,17 @@
+module github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/mysql
+
+go 1.22
+
+require (
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/project-flogo/core v1.6.9 // indirect
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+)
+
+replace github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint => ../
@@ -0,0 +1,21 @@
+// Package mysql registers the MySQL checkpoint table and loads the
+// go-sql-driver/mysql driver. Import it for its side effect.
+package mysql
+
+import (
+	_ "github.com/go-sql-driver/mysql"
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+)
+
+func init() {
+	cdccheckpoint.RegisterDialect("mysql", cdccheckpoint.Dialect{
+		Driver: "mysql",
+		Create: `CREATE TABLE IF NOT EXISTS %s (
+	checkpoint_key VARCHAR(255) NOT NULL PRIMARY KEY,
+	position TEXT NOT NULL,
+	updated_at DATETIME(6) NOT NULL)`,
+		Load: `SELECT position FROM %s WHERE checkpoint_key = ?`,
+		Upsert: `INSERT INTO %s (checkpoint_key, position, updated_at) VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE position = VALUES(position), updated_at = VALUES(updated_at)`,
+	})
+}
@@ -0,0 +1,21 @@
+// Package postgres registers the PostgreSQL checkpoint table and loads the
+// lib/pq driver. Import it for its side effect.
+package postgres
+
+import (
+	_ "github.com/lib/pq"
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+)
+
+func init() {
+	cdccheckpoint.RegisterDialect("postgres", cdccheckpoint.Dialect{
+		Driver: "postgres",
+		Create: `CREATE TABLE IF NOT EXISTS %s (
+	checkpoint_key VARCHAR(255) PRIMARY KEY,
+	position TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL)`,
+		Load: `SELECT position FROM %s WHERE checkpoint_key = $1`,
+		Upsert: `INSERT INTO %s (checkpoint_key, position, updated_at) VALUES ($1, $2, $3)
+ON CONFLICT (checkpoint_key) DO UPDATE SET position = EXCLUDED.position, updated_at = EXCLUDED.updated_at`,
+	})
+}
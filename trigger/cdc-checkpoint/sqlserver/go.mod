@@ -0,0 +1,22 @@
+module github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/sqlserver
+
+go 1.23.0
+
+require (
+	github.com/microsoft/go-mssqldb v1.9.3
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/golang-sql/civil v0.0.0-20220223132316-b832511892a9 // indirect
+	github.com/golang-sql/sqlexp v0.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/project-flogo/core v1.6.9 // indirect
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+)
+
+replace github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint => ../
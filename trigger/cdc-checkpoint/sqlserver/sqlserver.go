@@ -0,0 +1,23 @@
+// Package sqlserver registers the SQL Server checkpoint table and loads the
+// go-mssqldb driver. Import it for its side effect.
+package sqlserver
+
+import (
+	_ "github.com/microsoft/go-mssqldb"
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+)
+
+func init() {
+	cdccheckpoint.RegisterDialect("sqlserver", cdccheckpoint.Dialect{
+		Driver: "sqlserver",
+		Create: `IF OBJECT_ID(N'%[1]s', N'U') IS NULL CREATE TABLE %[1]s (
+	checkpoint_key NVARCHAR(255) NOT NULL PRIMARY KEY,
+	position NVARCHAR(MAX) NOT NULL,
+	updated_at DATETIME2 NOT NULL)`,
+		Load: `SELECT position FROM %s WHERE checkpoint_key = @p1`,
+		Upsert: `MERGE %s WITH (HOLDLOCK) AS target
+USING (SELECT @p1 AS checkpoint_key) AS source ON target.checkpoint_key = source.checkpoint_key
+WHEN MATCHED THEN UPDATE SET position = @p2, updated_at = @p3
+WHEN NOT MATCHED THEN INSERT (checkpoint_key, position, updated_at) VALUES (@p1, @p2, @p3);`,
+	})
+}
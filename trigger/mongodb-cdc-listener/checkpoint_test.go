@@ -0,0 +1,35 @@
+package mongodbcdclistener
+
+import (
+	"testing"
+
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestResumeTokenCheckpointRoundTrip(t *testing.T) {
+	raw, err := bson.Marshal(bson.M{"_data": "8265F0A1B2000000012B022C0100296E5A1004"})
+	require.NoError(t, err)
+
+	position := encodeResumeToken(bson.Raw(raw))
+	token, err := decodeResumeToken(position)
+	require.NoError(t, err)
+	assert.Equal(t, bson.Raw(raw), token)
+
+	_, err = decodeResumeToken("not-hex")
+	assert.Error(t, err)
+	_, err = decodeResumeToken("0102")
+	assert.Error(t, err, "truncated documents are rejected")
+	assert.Empty(t, encodeResumeToken(nil))
+}
+
+func TestCheckpointDrivers(t *testing.T) {
+	for _, driver := range []string{"postgres"} {
+		cfg := cdccheckpoint.Config{Store: "sql", Driver: driver, DSN: "dsn"}
+		assert.NoError(t, cfg.Validate(), driver)
+	}
+	cfg := cdccheckpoint.Config{Store: "sql", Driver: "mysql", DSN: "dsn"}
+	assert.Error(t, cfg.Validate(), "the MySQL driver is not linked into this trigger")
+}
@@ -3,6 +3,8 @@ module github.com/mpandav-tibco/flogo-custom-extensions/trigger/mongodb-cdc-list
 go 1.23
 
 require (
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint v0.0.0-00010101000000-000000000000
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres v0.0.0-00010101000000-000000000000
 	github.com/project-flogo/core v1.6.20
 	github.com/stretchr/testify v1.4.0
 	go.mongodb.org/mongo-driver v1.17.4
@@ -13,6 +15,7 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/klauspost/compress v1.16.7 // indirect
+	github.com/lib/pq v1.10.9 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
@@ -27,3 +30,8 @@ require (
 	golang.org/x/text v0.17.0 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
+
+replace (
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint => ../cdc-checkpoint
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres => ../cdc-checkpoint/postgres
+)
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
 	"github.com/project-flogo/core/support/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -48,6 +49,7 @@ type MongoDBCDCListener struct {
 	client      *mongo.Client
 	stream      *mongo.ChangeStream
 	resumeToken bson.Raw
+	checkpoint  *cdccheckpoint.Checkpointer // nil when no durable checkpoint store is configured
 }
 
 // NewMongoDBCDCListener creates a listener from validated settings.
@@ -252,6 +254,7 @@ func (l *MongoDBCDCListener) Stream(ctx context.Context, handler EventHandler) e
 		// Advance the resume token only after the event has been handled
 		// successfully (at-least-once delivery).
 		l.resumeToken = stream.ResumeToken()
+		l.checkpoint.Advance(ctx, encodeResumeToken(l.resumeToken))
 	}
 
 	if err := stream.Err(); err != nil && ctx.Err() == nil {
@@ -341,6 +344,33 @@ func (l *MongoDBCDCListener) SeedResumeToken(token bson.Raw) {
 	}
 }
 
+// SetCheckpointer attaches the durable checkpoint that handled resume tokens
+// are committed to.
+func (l *MongoDBCDCListener) SetCheckpointer(cp *cdccheckpoint.Checkpointer) {
+	l.checkpoint = cp
+}
+
+// encodeResumeToken renders a resume token as a checkpoint position.
+func encodeResumeToken(token bson.Raw) string {
+	if token == nil {
+		return ""
+	}
+	return hex.EncodeToString(token)
+}
+
+// decodeResumeToken parses a checkpoint position back into a resume token.
+func decodeResumeToken(position string) (bson.Raw, error) {
+	b, err := hex.DecodeString(position)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resume token checkpoint: %w", err)
+	}
+	token := bson.Raw(b)
+	if err := token.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid resume token checkpoint: %w", err)
+	}
+	return token, nil
+}
+
 // mapOperationType normalizes a MongoDB operationType to an uppercase event type.
 func mapOperationType(op string) string {
 	switch strings.ToLower(op) {
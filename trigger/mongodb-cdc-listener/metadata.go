@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
 	"github.com/project-flogo/core/data/coerce"
 )
 
@@ -29,6 +30,15 @@ type Settings struct {
 	ConnectionTimeout int    `md:"connectionTimeout"` // Connection timeout in seconds (default 30)
 	MaxRetryAttempts  int    `md:"maxRetryAttempts"`  // Max reconnect attempts (default 5, negative = infinite)
 	RetryDelay        string `md:"retryDelay"`        // Delay between reconnects (default "5s")
+
+	// Checkpointing — durable resume position across restarts
+	CheckpointStore    string `md:"checkpointStore"`    // none (default), file, sql
+	CheckpointFile     string `md:"checkpointFile"`     // Checkpoint file path (file store)
+	CheckpointDriver   string `md:"checkpointDriver"`   // postgres (sql store)
+	CheckpointDSN      string `md:"checkpointDSN"`      // Connection string for the checkpoint database (sql store)
+	CheckpointTable    string `md:"checkpointTable"`    // Checkpoint table (default flogo_cdc_checkpoints)
+	CheckpointInterval int    `md:"checkpointInterval"` // Commit the position every N events (default 1)
+	CheckpointMode     string `md:"checkpointMode"`     // onSuccess (default) or always
 }
 
 // HandlerSettings contains per-handler change stream configuration.
@@ -40,6 +50,7 @@ type HandlerSettings struct {
 	FullDocumentBeforeChange string `md:"fullDocumentBeforeChange"` // off, whenAvailable, required (pre-image; MongoDB 6.0+)
 	BatchSize                int    `md:"batchSize"`                // Change stream cursor batch size (0 = server default)
 	MaxAwaitTime             string `md:"maxAwaitTime"`             // Max time the server waits for new changes (Go duration)
+	CheckpointKey            string `md:"checkpointKey"`            // Checkpoint key (default mongodb:<database>.<collection>)
 }
 
 // Output represents a single change event delivered to a Flogo flow.
@@ -66,7 +77,20 @@ func (s *Settings) Validate() error {
 	if s.ConnectionURI == "" && s.Port <= 0 {
 		return fmt.Errorf("port must be a positive integer when host is used")
 	}
-	return nil
+	return s.CheckpointConfig().Validate()
+}
+
+// CheckpointConfig returns the checkpoint store configuration.
+func (s *Settings) CheckpointConfig() cdccheckpoint.Config {
+	return cdccheckpoint.Config{
+		Store:    s.CheckpointStore,
+		File:     s.CheckpointFile,
+		Driver:   s.CheckpointDriver,
+		DSN:      s.CheckpointDSN,
+		Table:    s.CheckpointTable,
+		Interval: s.CheckpointInterval,
+		Mode:     s.CheckpointMode,
+	}
 }
 
 // Validate checks the per-handler settings.
@@ -87,6 +111,23 @@ func (h *HandlerSettings) Validate() error {
 	return nil
 }
 
+// ResolvedCheckpointKey returns the key the handler's position is stored under.
+// Set checkpointKey explicitly when several triggers watch the same namespace
+// on different deployments and share one checkpoint store.
+func (h *HandlerSettings) ResolvedCheckpointKey() string {
+	if k := strings.TrimSpace(h.CheckpointKey); k != "" {
+		return k
+	}
+	db, coll := h.Database, h.Collection
+	if db == "" {
+		db = "*"
+	}
+	if coll == "" {
+		coll = "*"
+	}
+	return "mongodb:" + db + "." + coll
+}
+
 // OperationTypeSet returns the set of enabled operation types.
 // An empty result or a set containing "ALL" means all operation types are enabled.
 func (h *HandlerSettings) OperationTypeSet() map[string]bool {
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+	_ "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres"
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
 	"github.com/project-flogo/core/support/trace"
@@ -29,12 +31,15 @@ type Trigger struct {
 	wg        sync.WaitGroup
 	mutex     sync.Mutex
 	isStarted bool
+
+	checkpoints cdccheckpoint.Store // nil when checkpointStore is none
 }
 
 type handlerContext struct {
-	runner   trigger.Handler
-	settings *HandlerSettings
-	cursor   bson.Raw
+	runner     trigger.Handler
+	settings   *HandlerSettings
+	cursor     bson.Raw
+	checkpoint *cdccheckpoint.Checkpointer
 }
 
 // Factory creates MongoDB CDC triggers.
@@ -67,7 +72,6 @@ func (t *Trigger) Metadata() *trigger.Metadata {
 
 // Initialize parses handler settings and prepares one listener per handler.
 func (t *Trigger) Initialize(ctx trigger.InitContext) error {
-	t.ctx, t.cancel = context.WithCancel(context.Background())
 	if ctx.Logger() != nil {
 		t.logger = ctx.Logger()
 	}
@@ -102,6 +106,20 @@ func (t *Trigger) Start() error {
 		return fmt.Errorf("trigger already started")
 	}
 
+	// The checkpoint store is opened per run: Stop closes it, so a restarted
+	// trigger needs a fresh one.
+	openCtx, cancel := context.WithTimeout(context.Background(), cdccheckpoint.OpenTimeout)
+	store, err := cdccheckpoint.Open(openCtx, t.settings.CheckpointConfig())
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+	t.checkpoints = store
+	for _, hc := range t.handlers {
+		hc.checkpoint = cdccheckpoint.NewCheckpointer(store, hc.settings.ResolvedCheckpointKey(), t.settings.CheckpointInterval, t.logger)
+	}
+
+	t.ctx, t.cancel = context.WithCancel(context.Background())
 	for _, hc := range t.handlers {
 		t.wg.Add(1)
 		go t.runHandler(hc)
@@ -125,9 +143,29 @@ func (t *Trigger) runHandler(hc *handlerContext) {
 		maxAttempts = 5
 	}
 
-	flogoHandler := &flogoEventHandler{runner: hc.runner, logger: t.logger}
+	flogoHandler := &flogoEventHandler{
+		runner:         hc.runner,
+		logger:         t.logger,
+		advanceOnError: t.settings.CheckpointConfig().AdvanceOnError(),
+	}
 	attempt := 0
 
+	// Resume from the durable checkpoint left by a previous run, if any.
+	if position, err := hc.checkpoint.Load(t.ctx); err != nil {
+		t.logger.Errorf("MongoDB CDC: failed to load checkpoint %q, starting without it: %v",
+			hc.settings.ResolvedCheckpointKey(), err)
+	} else if position != "" {
+		token, err := decodeResumeToken(position)
+		if err != nil {
+			t.logger.Errorf("MongoDB CDC: ignoring checkpoint %q: %v", hc.settings.ResolvedCheckpointKey(), err)
+		} else {
+			hc.cursor = token
+			t.logger.Infof("MongoDB CDC: resuming database=%q collection=%q from checkpoint %q",
+				hc.settings.Database, hc.settings.Collection, hc.settings.ResolvedCheckpointKey())
+		}
+	}
+	defer hc.checkpoint.Flush(context.Background())
+
 	for {
 		select {
 		case <-t.ctx.Done():
@@ -139,6 +177,7 @@ func (t *Trigger) runHandler(hc *handlerContext) {
 		// Resume from the last token reached before the previous disconnect so
 		// no changes are missed across reconnects (at-least-once delivery).
 		listener.SeedResumeToken(hc.cursor)
+		listener.SetCheckpointer(hc.checkpoint)
 
 		err := func() error {
 			if err := listener.Prepare(t.ctx); err != nil {
@@ -147,6 +186,7 @@ func (t *Trigger) runHandler(hc *handlerContext) {
 			return listener.Stream(t.ctx, flogoHandler)
 		}()
 		hc.cursor = listener.ResumeToken()
+		hc.checkpoint.Flush(context.Background())
 		listener.Close(context.Background())
 
 		if t.ctx.Err() != nil {
@@ -188,6 +228,12 @@ func (t *Trigger) Stop() error {
 	// cancelling the context unblocks them and Wait joins them here. Closing the
 	// listener from this goroutine would race the still-running change stream.
 	t.wg.Wait()
+	if t.checkpoints != nil {
+		if err := t.checkpoints.Close(); err != nil {
+			t.logger.Warnf("Error closing checkpoint store: %v", err)
+		}
+		t.checkpoints = nil
+	}
 	t.isStarted = false
 	t.logger.Info("MongoDB CDC trigger stopped")
 	return nil
@@ -197,6 +243,9 @@ func (t *Trigger) Stop() error {
 type flogoEventHandler struct {
 	runner trigger.Handler
 	logger log.Logger
+	// advanceOnError swallows flow errors so the stream moves past failed
+	// events (checkpointMode=always).
+	advanceOnError bool
 }
 
 // HandleEvent dispatches a change event to the associated Flogo flow.
@@ -248,6 +297,11 @@ func (h *flogoEventHandler) HandleEvent(ctx context.Context, event *ChangeEvent)
 		}
 	}
 	if err != nil {
+		if h.advanceOnError {
+			h.logger.Warnf("MongoDB CDC: flow failed for %s on %s.%s, advancing past it (checkpointMode=always): %v",
+				event.Type, event.Database, event.Collection, err)
+			return nil
+		}
 		return fmt.Errorf("failed to execute Flogo flow: %w", err)
 	}
 	return nil
@@ -38,9 +38,13 @@ var MongoDBCDCListenerHandler = function (e) {
         };
 
         // TLS field visibility: tlsConfig boolean reveals the certificate fields.
+        // Checkpoint field visibility: checkpointStore reveals the fields of the
+        // selected backend plus the shared interval/mode fields.
         n.validate = function (fieldName, settings) {
             var tlsField = settings.getField("tlsConfig");
             var tlsEnabled = tlsField && (tlsField.value === true || tlsField.value === "true");
+            var storeField = settings.getField("checkpointStore");
+            var store = storeField && storeField.value ? storeField.value : "none";
 
             switch (fieldName) {
                 case "tlsCAFile":
@@ -48,6 +52,18 @@ var MongoDBCDCListenerHandler = function (e) {
                 case "tlsInsecure":
                     return validation_1.ValidationResult.newValidationResult().setVisible(!!tlsEnabled);
 
+                case "checkpointFile":
+                    return validation_1.ValidationResult.newValidationResult().setVisible(store === "file");
+
+                case "checkpointDriver":
+                case "checkpointDSN":
+                case "checkpointTable":
+                    return validation_1.ValidationResult.newValidationResult().setVisible(store === "sql");
+
+                case "checkpointInterval":
+                case "checkpointMode":
+                    return validation_1.ValidationResult.newValidationResult().setVisible(store !== "none");
+
                 default:
                     return null;
             }
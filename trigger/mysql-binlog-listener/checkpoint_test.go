@@ -0,0 +1,116 @@
+package mysqlbinloglistener
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCheckpointStore is an in-memory cdccheckpoint.Store that records saves.
+type memoryCheckpointStore struct {
+	positions map[string]string
+	saves     int
+	failSaves bool
+}
+
+func newMemoryCheckpointStore() *memoryCheckpointStore {
+	return &memoryCheckpointStore{positions: make(map[string]string)}
+}
+
+func (m *memoryCheckpointStore) Load(_ context.Context, key string) (string, error) {
+	return m.positions[key], nil
+}
+
+func (m *memoryCheckpointStore) Save(_ context.Context, key, position string) error {
+	if m.failSaves {
+		return errors.New("store unavailable")
+	}
+	m.positions[key] = position
+	m.saves++
+	return nil
+}
+
+func (m *memoryCheckpointStore) Close() error { return nil }
+
+func TestBinlogPositionRoundTrip(t *testing.T) {
+	pos := mysql.Position{Name: "mysql-bin.000042", Pos: 1337}
+	parsed, err := parseBinlogPosition(formatBinlogPosition(pos))
+	require.NoError(t, err)
+	assert.Equal(t, pos, parsed)
+
+	for _, bad := range []string{"", "mysql-bin.000042", ":4", "mysql-bin.000042:", "mysql-bin.000042:abc", "f:99999999999"} {
+		_, err := parseBinlogPosition(bad)
+		assert.Error(t, err, "expected %q to be rejected", bad)
+	}
+}
+
+func TestBinlogCheckpoint_CommitsAtTransactionBoundaries(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryCheckpointStore()
+	listener := NewMySQLBinlogListener(&Settings{Host: "localhost", Port: 3306}, log.RootLogger())
+	listener.SetCheckpointer(cdccheckpoint.NewCheckpointer(store, "k", 1, log.RootLogger()))
+	listener.currentBinlogFile = "mysql-bin.000007"
+
+	failing := false
+	handler := &MockEventHandler{
+		handleFunc: func(ctx context.Context, event *BinlogEvent) error {
+			if failing {
+				return errors.New("flow failed")
+			}
+			return nil
+		},
+	}
+	hs := &HandlerSettings{ServerID: 1001, EventTypes: "ALL"}
+	insert := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.WRITE_ROWS_EVENTv2, LogPos: logPos},
+			Event: &replication.RowsEvent{
+				Table: &replication.TableMapEvent{Schema: []byte("testdb"), Table: []byte("users")},
+				Rows:  [][]interface{}{{1, "alice"}},
+			},
+		}
+	}
+	xid := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{Header: &replication.EventHeader{LogPos: logPos}, Event: &replication.XIDEvent{}}
+	}
+	query := func(logPos uint32, q string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{Header: &replication.EventHeader{LogPos: logPos}, Event: &replication.QueryEvent{Query: []byte(q)}}
+	}
+
+	// Rows alone do not commit; the XID that ends the transaction does.
+	require.NoError(t, listener.processBinlogEvent(query(100, "BEGIN"), nil, nil, hs, handler, ctx))
+	require.NoError(t, listener.processBinlogEvent(insert(200), nil, nil, hs, handler, ctx))
+	assert.Empty(t, store.positions["k"])
+	require.NoError(t, listener.processBinlogEvent(xid(231), nil, nil, hs, handler, ctx))
+	assert.Equal(t, "mysql-bin.000007:231", store.positions["k"])
+
+	// DDL statements are boundaries too.
+	require.NoError(t, listener.processBinlogEvent(query(400, "ALTER TABLE users ADD COLUMN age INT"), nil, nil, hs, handler, ctx))
+	assert.Equal(t, "mysql-bin.000007:400", store.positions["k"])
+
+	// A failed flow stops processing and leaves the checkpoint at the last boundary.
+	failing = true
+	require.NoError(t, listener.processBinlogEvent(query(500, "BEGIN"), nil, nil, hs, handler, ctx))
+	assert.Error(t, listener.processBinlogEvent(insert(600), nil, nil, hs, handler, ctx))
+	assert.Equal(t, "mysql-bin.000007:400", store.positions["k"])
+	assert.Equal(t, mysql.Position{Name: "mysql-bin.000007", Pos: 400}, listener.committedPos)
+}
+
+func TestCheckpointDrivers(t *testing.T) {
+	base := Settings{Host: "localhost", Port: 3306, User: "u", CheckpointStore: "sql", CheckpointDSN: "dsn"}
+	for _, driver := range []string{"mysql", "postgres"} {
+		s := base
+		s.CheckpointDriver = driver
+		assert.NoError(t, s.CheckpointConfig().Validate(), driver)
+	}
+	s := base
+	s.CheckpointDriver = "sqlserver"
+	assert.Error(t, s.CheckpointConfig().Validate(), "the SQL Server driver is not linked into this trigger")
+}
@@ -0,0 +1,229 @@
+package mysqlbinloglistener
+
+import (
+	"strings"
+)
+
+// DDLStatement describes a schema change parsed from a binlog query event.
+type DDLStatement struct {
+	Type     string // Statement type, e.g. "CREATE TABLE", "ALTER TABLE", "DROP DATABASE"
+	Database string // Affected database (the session's default database when unqualified)
+	Table    string // Affected table, view or index table (empty for database statements)
+	Query    string // Raw SQL as logged by the server
+}
+
+// ddlObjects are the object kinds reported as "<VERB> <OBJECT>" statements.
+// SCHEMA is reported as DATABASE, its MySQL synonym.
+var ddlObjects = map[string]string{
+	"TABLE":    "TABLE",
+	"VIEW":     "VIEW",
+	"INDEX":    "INDEX",
+	"DATABASE": "DATABASE",
+	"SCHEMA":   "DATABASE",
+}
+
+// ddlModifiers are the words that may appear between the verb and the object
+// kind (CREATE OR REPLACE ALGORITHM=MERGE DEFINER=x SQL SECURITY INVOKER VIEW,
+// CREATE UNIQUE INDEX, DROP TEMPORARY TABLE, ALTER ONLINE IGNORE TABLE).
+var ddlModifiers = map[string]bool{
+	"OR": true, "REPLACE": true, "TEMPORARY": true, "UNIQUE": true, "FULLTEXT": true,
+	"SPATIAL": true, "ONLINE": true, "OFFLINE": true, "IGNORE": true, "SQL": true,
+	"SECURITY": true, "DEFINER": true, "INVOKER": true, "ALGORITHM": true,
+	"UNDEFINED": true, "MERGE": true, "TEMPTABLE": true,
+}
+
+// parseDDL recognises the table, view, index and database statements of a
+// binlog query event. It returns false for anything else — transaction control
+// (BEGIN/COMMIT), statement-based DML, and DDL on other objects (triggers,
+// routines, users) that cannot change how row events are decoded.
+func parseDDL(query, defaultDatabase string) (*DDLStatement, bool) {
+	s := &ddlScanner{src: query}
+	verb := strings.ToUpper(s.word())
+	switch verb {
+	case "CREATE", "ALTER", "DROP":
+	case "RENAME":
+		if !strings.EqualFold(s.word(), "TABLE") {
+			return nil, false
+		}
+		return s.tableStatement("RENAME TABLE", query, defaultDatabase)
+	case "TRUNCATE":
+		// TABLE is optional in TRUNCATE [TABLE] t.
+		mark := s.pos
+		if !strings.EqualFold(s.word(), "TABLE") {
+			s.pos = mark
+		}
+		return s.tableStatement("TRUNCATE TABLE", query, defaultDatabase)
+	default:
+		return nil, false
+	}
+
+	var object string
+	for {
+		w := strings.ToUpper(s.word())
+		if w == "" {
+			return nil, false
+		}
+		if o, ok := ddlObjects[w]; ok {
+			object = o
+			break
+		}
+		if !ddlModifiers[w] {
+			return nil, false
+		}
+		if w == "DEFINER" || w == "ALGORITHM" {
+			// DEFINER = user@host / ALGORITHM = MERGE
+			s.skipAssignment()
+		}
+	}
+	stmtType := verb + " " + object
+	s.skipIfExists()
+
+	switch object {
+	case "DATABASE":
+		name := s.identifier()
+		if name == "" {
+			name = defaultDatabase
+		}
+		return &DDLStatement{Type: stmtType, Database: name, Query: query}, true
+	case "INDEX":
+		// CREATE INDEX idx ON t (...) / DROP INDEX idx ON t
+		s.identifier()
+		if !strings.EqualFold(s.word(), "ON") {
+			return &DDLStatement{Type: stmtType, Database: defaultDatabase, Query: query}, true
+		}
+	}
+	return s.tableStatement(stmtType, query, defaultDatabase)
+}
+
+// ddlScanner walks the words and identifiers of a SQL statement, skipping
+// whitespace and comments.
+type ddlScanner struct {
+	src string
+	pos int
+}
+
+// tableStatement reads a possibly database-qualified table name and returns
+// the statement for it.
+func (s *ddlScanner) tableStatement(stmtType, query, defaultDatabase string) (*DDLStatement, bool) {
+	database, table := defaultDatabase, s.identifier()
+	if table == "" {
+		return nil, false
+	}
+	if s.peek() == '.' {
+		s.pos++
+		database, table = table, s.identifier()
+	}
+	return &DDLStatement{Type: stmtType, Database: database, Table: table, Query: query}, true
+}
+
+// skipIfExists skips an IF [NOT] EXISTS clause.
+func (s *ddlScanner) skipIfExists() {
+	mark := s.pos
+	if !strings.EqualFold(s.word(), "IF") {
+		s.pos = mark
+		return
+	}
+	mark = s.pos
+	if !strings.EqualFold(s.word(), "NOT") {
+		s.pos = mark
+	}
+	s.word() // EXISTS
+}
+
+// skipAssignment skips "= value", where value may be a quoted user@host.
+func (s *ddlScanner) skipAssignment() {
+	if s.peek() != '=' {
+		return
+	}
+	s.pos++
+	for s.skipSpace(); s.pos < len(s.src) && !isSQLSpace(s.src[s.pos]); s.pos++ {
+		if q := s.src[s.pos]; q == '`' || q == '\'' || q == '"' {
+			s.quoted(q)
+			s.pos--
+		}
+	}
+}
+
+// word returns the next bare word (letters, digits, '_' and '$').
+func (s *ddlScanner) word() string {
+	s.skipSpace()
+	start := s.pos
+	for s.pos < len(s.src) && isSQLWordChar(s.src[s.pos]) {
+		s.pos++
+	}
+	return s.src[start:s.pos]
+}
+
+// identifier returns the next identifier, unquoting `backtick` and "double"
+// quoted names.
+func (s *ddlScanner) identifier() string {
+	s.skipSpace()
+	if s.pos < len(s.src) && (s.src[s.pos] == '`' || s.src[s.pos] == '"') {
+		return s.quoted(s.src[s.pos])
+	}
+	return s.word()
+}
+
+// quoted reads a quoted name starting at s.pos; a doubled quote character
+// stands for itself.
+func (s *ddlScanner) quoted(q byte) string {
+	var sb strings.Builder
+	for s.pos++; s.pos < len(s.src); s.pos++ {
+		if s.src[s.pos] == q {
+			if s.pos+1 < len(s.src) && s.src[s.pos+1] == q {
+				sb.WriteByte(q)
+				s.pos++
+				continue
+			}
+			s.pos++
+			break
+		}
+		sb.WriteByte(s.src[s.pos])
+	}
+	return sb.String()
+}
+
+// peek returns the next non-space byte without consuming it, or 0 at the end.
+func (s *ddlScanner) peek() byte {
+	s.skipSpace()
+	if s.pos < len(s.src) {
+		return s.src[s.pos]
+	}
+	return 0
+}
+
+// skipSpace skips whitespace and /* */, -- and # comments. MySQL executable
+// comments (/*!50100 ... */) are skipped too; they never hold the parts of a
+// statement parsed here.
+func (s *ddlScanner) skipSpace() {
+	for s.pos < len(s.src) {
+		switch {
+		case isSQLSpace(s.src[s.pos]):
+			s.pos++
+		case strings.HasPrefix(s.src[s.pos:], "/*"):
+			end := strings.Index(s.src[s.pos+2:], "*/")
+			if end < 0 {
+				s.pos = len(s.src)
+				return
+			}
+			s.pos += end + 4
+		case strings.HasPrefix(s.src[s.pos:], "-- "), s.src[s.pos] == '#':
+			end := strings.IndexByte(s.src[s.pos:], '\n')
+			if end < 0 {
+				s.pos = len(s.src)
+				return
+			}
+			s.pos += end + 1
+		default:
+			return
+		}
+	}
+}
+
+func isSQLSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+func isSQLWordChar(b byte) bool {
+	return b == '_' || b == '$' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b >= 0x80
+}
@@ -0,0 +1,121 @@
+package mysqlbinloglistener
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDDL(t *testing.T) {
+	cases := []struct {
+		query          string
+		typ, db, table string
+		notDDL         bool
+	}{
+		{query: "CREATE TABLE orders (id INT PRIMARY KEY)", typ: "CREATE TABLE", db: "shop", table: "orders"},
+		{query: "create temporary table if not exists `tmp``x` (id int)", typ: "CREATE TABLE", db: "shop", table: "tmp`x"},
+		{query: "ALTER TABLE `sales`.`orders` ADD COLUMN discount DECIMAL(5,2)", typ: "ALTER TABLE", db: "sales", table: "orders"},
+		{query: "ALTER ONLINE IGNORE TABLE orders DROP COLUMN note", typ: "ALTER TABLE", db: "shop", table: "orders"},
+		{query: "DROP TABLE IF EXISTS `orders` /* generated by server */", typ: "DROP TABLE", db: "shop", table: "orders"},
+		{query: "RENAME TABLE orders TO orders_old", typ: "RENAME TABLE", db: "shop", table: "orders"},
+		{query: "TRUNCATE orders", typ: "TRUNCATE TABLE", db: "shop", table: "orders"},
+		{query: "TRUNCATE TABLE sales.orders", typ: "TRUNCATE TABLE", db: "sales", table: "orders"},
+		{query: "CREATE UNIQUE INDEX idx_email ON users (email)", typ: "CREATE INDEX", db: "shop", table: "users"},
+		{query: "DROP INDEX idx_email ON `users`", typ: "DROP INDEX", db: "shop", table: "users"},
+		{query: "CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v_orders` AS select 1", typ: "CREATE VIEW", db: "shop", table: "v_orders"},
+		{query: "CREATE OR REPLACE VIEW v AS SELECT 1", typ: "CREATE VIEW", db: "shop", table: "v"},
+		{query: "CREATE DATABASE IF NOT EXISTS analytics", typ: "CREATE DATABASE", db: "analytics"},
+		{query: "DROP SCHEMA analytics", typ: "DROP DATABASE", db: "analytics"},
+		{query: "/* ApplicationName=cli */ -- note\n  ALTER TABLE orders ADD INDEX (customer_id)", typ: "ALTER TABLE", db: "shop", table: "orders"},
+		{query: "BEGIN", notDDL: true},
+		{query: "COMMIT", notDDL: true},
+		{query: "INSERT INTO orders VALUES (1)", notDDL: true},
+		{query: "CREATE DEFINER=`root`@`localhost` TRIGGER trg BEFORE INSERT ON orders FOR EACH ROW SET @x = 1", notDDL: true},
+		{query: "CREATE USER 'app'@'%' IDENTIFIED BY 'secret'", notDDL: true},
+		{query: "", notDDL: true},
+	}
+	for _, tc := range cases {
+		ddl, ok := parseDDL(tc.query, "shop")
+		if tc.notDDL {
+			assert.False(t, ok, tc.query)
+			continue
+		}
+		if assert.True(t, ok, tc.query) {
+			assert.Equal(t, tc.typ, ddl.Type, tc.query)
+			assert.Equal(t, tc.db, ddl.Database, tc.query)
+			assert.Equal(t, tc.table, ddl.Table, tc.query)
+			assert.Equal(t, tc.query, ddl.Query)
+		}
+	}
+}
+
+func TestDDLEvents(t *testing.T) {
+	var events []*BinlogEvent
+	handler := &MockEventHandler{
+		handleFunc: func(ctx context.Context, event *BinlogEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	}
+	listener := NewMySQLBinlogListener(&Settings{DatabaseName: "shop"}, log.RootLogger())
+	listener.schemaCache["shop.orders"] = map[string]interface{}{"column_names": []string{"id"}}
+	ctx := context.Background()
+	query := func(q string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{LogPos: 400},
+			Event:  &replication.QueryEvent{Schema: []byte("shop"), Query: []byte(q)},
+		}
+	}
+
+	// Without includeDDL the statement only refreshes the schema cache.
+	hs := &HandlerSettings{ServerID: 1001}
+	require.NoError(t, listener.processBinlogEvent(query("ALTER TABLE orders ADD COLUMN note TEXT"), nil, nil, hs, handler, ctx))
+	assert.Empty(t, events)
+	assert.Empty(t, listener.schemaCache)
+
+	hs.IncludeDDL = true
+	require.NoError(t, listener.processBinlogEvent(query("BEGIN"), nil, nil, hs, handler, ctx))
+	require.NoError(t, listener.processBinlogEvent(query("ALTER TABLE orders ADD COLUMN note TEXT"), nil, nil, hs, handler, ctx))
+	require.Len(t, events, 1)
+	assert.Equal(t, "DDL", events[0].Type)
+	assert.Equal(t, "ALTER TABLE", events[0].DDLType)
+	assert.Equal(t, "shop", events[0].Database)
+	assert.Equal(t, "orders", events[0].Table)
+	assert.Equal(t, "ALTER TABLE orders ADD COLUMN note TEXT", events[0].Query)
+
+	// The table filter applies to the affected table and excludes
+	// database-level statements.
+	filter := map[string]bool{"users": true}
+	require.NoError(t, listener.processBinlogEvent(query("DROP TABLE orders"), filter, nil, hs, handler, ctx))
+	require.NoError(t, listener.processBinlogEvent(query("DROP DATABASE old"), filter, nil, hs, handler, ctx))
+	require.NoError(t, listener.processBinlogEvent(query("CREATE INDEX idx ON users (email)"), filter, nil, hs, handler, ctx))
+	require.Len(t, events, 2)
+	assert.Equal(t, "CREATE INDEX", events[1].DDLType)
+	assert.Equal(t, "users", events[1].Table)
+}
+
+func TestDDLEventFailureKeepsCheckpoint(t *testing.T) {
+	store := newMemoryCheckpointStore()
+	listener := NewMySQLBinlogListener(&Settings{DatabaseName: "shop"}, log.RootLogger())
+	listener.currentBinlogFile = "mysql-bin.000007"
+	listener.SetCheckpointer(cdccheckpoint.NewCheckpointer(store, "k", 1, log.RootLogger()))
+	handler := &MockEventHandler{
+		handleFunc: func(ctx context.Context, event *BinlogEvent) error {
+			return errors.New("flow failed")
+		},
+	}
+	ddl := &replication.BinlogEvent{
+		Header: &replication.EventHeader{LogPos: 400},
+		Event:  &replication.QueryEvent{Schema: []byte("shop"), Query: []byte("DROP TABLE orders")},
+	}
+
+	err := listener.processBinlogEvent(ddl, nil, nil, &HandlerSettings{ServerID: 1001, IncludeDDL: true}, handler, context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, store.positions["k"])
+}
@@ -7,6 +7,9 @@ toolchain go1.24.3
 require (
 	github.com/go-mysql-org/go-mysql v1.12.0
 	github.com/go-sql-driver/mysql v1.7.1
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint v0.0.0-00010101000000-000000000000
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/mysql v0.0.0-00010101000000-000000000000
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres v0.0.0-00010101000000-000000000000
 	github.com/project-flogo/core v1.6.9
 	github.com/stretchr/testify v1.10.0
 )
@@ -19,6 +22,7 @@ require (
 	github.com/goccy/go-json v0.10.2 // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/klauspost/compress v1.17.8 // indirect
+	github.com/lib/pq v1.10.9 // indirect
 	github.com/pingcap/errors v0.11.5-0.20240311024730-e056997136bb // indirect
 	github.com/pingcap/log v1.1.1-0.20230317032135-a0d097d16e22 // indirect
 	github.com/pingcap/tidb/pkg/parser v0.0.0-20241118164214-4f047be191be // indirect
@@ -32,3 +36,9 @@ require (
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace (
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint => ../cdc-checkpoint
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/mysql => ../cdc-checkpoint/mysql
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres => ../cdc-checkpoint/postgres
+)
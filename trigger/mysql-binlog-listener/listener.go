@@ -9,6 +9,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +19,7 @@ import (
 	"github.com/go-mysql-org/go-mysql/mysql"
 	"github.com/go-mysql-org/go-mysql/replication"
 	mysqldriver "github.com/go-sql-driver/mysql"
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
 	"github.com/project-flogo/core/support/log"
 	"github.com/project-flogo/core/support/trace"
 )
@@ -39,6 +41,8 @@ type MySQLBinlogListener struct {
 	isMySQL8Plus      bool
 	currentBinlogFile string
 	lastPos           uint32                            // Last processed binlog position, for resume-on-reconnect
+	committedPos      mysql.Position                    // Last transaction boundary, for resume with checkpointing
+	checkpoint        *cdccheckpoint.Checkpointer       // Durable position store (nil when checkpointStore is none)
 	schemaCache       map[string]map[string]interface{} // Cache for table schemas: "db.table" -> schema info
 	schemaMutex       sync.RWMutex                      // Protects schema cache access
 }
@@ -287,6 +291,12 @@ func (m *MySQLBinlogListener) StartListening(ctx context.Context, handlerSetting
 	return nil
 }
 
+// SetCheckpointer attaches the durable checkpoint that transaction boundaries
+// are committed to. Must be called before StartListening.
+func (m *MySQLBinlogListener) SetCheckpointer(cp *cdccheckpoint.Checkpointer) {
+	m.checkpoint = cp
+}
+
 // Stop gracefully shuts down the MySQL binlog listener
 func (m *MySQLBinlogListener) Stop() error {
 	m.mutex.Lock()
@@ -376,9 +386,15 @@ func (m *MySQLBinlogListener) streamBinlog(ctx context.Context, handlerSettings
 
 	// Determine starting position. The syncer itself is created lazily by
 	// openStreamer inside the reconnect loop, so there is no premature syncer to
-	// allocate and immediately discard here.
+	// allocate and immediately discard here. A committed checkpoint takes
+	// precedence over the configured binlogFile/binlogPos.
 	var pos mysql.Position
-	if handlerSettings.BinlogFile != "" {
+	checkpointPos, hasCheckpoint := m.loadCheckpoint(ctx)
+	if hasCheckpoint {
+		pos = checkpointPos
+		m.currentBinlogFile = pos.Name
+		m.logger.Infof("Starting from checkpoint position: %s:%d", pos.Name, pos.Pos)
+	} else if handlerSettings.BinlogFile != "" {
 		pos = mysql.Position{
 			Name: handlerSettings.BinlogFile,
 			Pos:  uint32(handlerSettings.BinlogPos),
@@ -396,9 +412,12 @@ func (m *MySQLBinlogListener) streamBinlog(ctx context.Context, handlerSettings
 		m.logger.Infof("Starting from current position: %s:%d", pos.Name, pos.Pos)
 	}
 	m.lastPos = pos.Pos
+	m.committedPos = pos
 
-	// Close the active syncer when streaming ends (normal stop or give-up).
+	// Close the active syncer when streaming ends (normal stop or give-up), and
+	// commit any pending checkpoint.
 	defer m.closeSyncer()
+	defer m.checkpoint.Flush(context.Background())
 
 	// Create table filter
 	var tableFilter map[string]bool
@@ -438,6 +457,14 @@ func (m *MySQLBinlogListener) streamBinlog(ctx context.Context, handlerSettings
 		}
 
 		resumePos := mysql.Position{Name: m.currentBinlogFile, Pos: m.lastPos}
+		if m.checkpoint != nil {
+			// Rewind to the last transaction boundary: a transaction whose flow
+			// failed is redelivered in full, and the stream never restarts in
+			// the middle of a transaction.
+			resumePos = m.committedPos
+			m.currentBinlogFile, m.lastPos = resumePos.Name, resumePos.Pos
+			m.checkpoint.Flush(ctx)
+		}
 		streamer, err := m.openStreamer(cfg, resumePos)
 		if err != nil {
 			if ctx.Err() != nil {
@@ -460,6 +487,7 @@ func (m *MySQLBinlogListener) streamBinlog(ctx context.Context, handlerSettings
 		m.binlogStreamer = streamer
 		m.logger.Infof("Binlog streaming started from %s:%d", resumePos.Name, resumePos.Pos)
 
+		startCommitted := m.committedPos
 		processed, streamErr := m.consumeEvents(ctx, streamer, tableFilter, eventTypeFilter, handlerSettings, eventHandler)
 		if ctx.Err() != nil {
 			return
@@ -470,7 +498,9 @@ func (m *MySQLBinlogListener) streamBinlog(ctx context.Context, handlerSettings
 		}
 
 		// A healthy streaming period resets the consecutive-failure counter.
-		if processed > 0 {
+		// With checkpointing only committed progress counts, so a transaction
+		// whose flow keeps failing eventually exhausts maxRetryAttempts.
+		if (m.checkpoint == nil && processed > 0) || m.committedPos != startCommitted {
 			attempt = 0
 		}
 		attempt++
@@ -548,6 +578,11 @@ func (m *MySQLBinlogListener) consumeEvents(ctx context.Context, streamer *repli
 
 		// Process the binlog event
 		if err := m.processBinlogEvent(ev, tableFilter, eventTypeFilter, handlerSettings, eventHandler, ctx); err != nil {
+			if m.checkpoint != nil {
+				// Stop here; the caller reconnects from the last committed
+				// transaction boundary so the failed change is redelivered.
+				return processed, err
+			}
 			m.logger.Errorf("Error processing binlog event: %v", err)
 		}
 	}
@@ -671,105 +706,51 @@ func (m *MySQLBinlogListener) processBinlogEvent(ev *replication.BinlogEvent, ta
 			return nil
 		}
 
+		// Fetch the table schema once for all rows of the event
+		var schemaInfo map[string]interface{}
+		if handlerSettings.IncludeSchema {
+			schema, err := m.getTableSchema(databaseName, tableName)
+			if err != nil {
+				m.logger.Warnf("Failed to get schema for table %s.%s: %v, falling back to column indices",
+					databaseName, tableName, err)
+			} else {
+				schemaInfo = schema
+			}
+		}
+
 		// Process each row in the event
 		if eventType == "UPDATE" {
 			// For UPDATE events, rows come in pairs: [before, after, before, after, ...]
-			// We only want to process the "after" rows (odd indices: 1, 3, 5, ...)
+			// Each pair becomes one event carrying the after image in data and
+			// the before image in oldData.
 			for i := 1; i < len(e.Rows); i += 2 {
-				row := e.Rows[i] // This is the "after" row
-				var rowData map[string]interface{}
-				var schemaInfo map[string]interface{}
-
-				// Handle schema information if enabled
-				if handlerSettings.IncludeSchema {
-					schema, err := m.getTableSchema(databaseName, tableName)
-					if err != nil {
-						m.logger.Warnf("Failed to get schema for table %s.%s: %v, falling back to column indices",
-							databaseName, tableName, err)
-						// Fallback to column indices
-						rowData = formatRowDataByIndex(row)
-					} else {
-						// Use column names from schema
-						rowData = m.formatRowDataWithSchema(row, schema)
-						schemaInfo = schema
-					}
-				} else {
-					// Convert row data to map with column indices (default behavior)
-					rowData = formatRowDataByIndex(row)
-				}
-
-				// Create and send the event
-				binlogEvent := &BinlogEvent{
-					ID:            m.generateCorrelationID(),
-					Type:          eventType,
-					Database:      databaseName,
-					Table:         tableName,
-					Timestamp:     time.Unix(int64(ev.Header.Timestamp), 0),
-					Data:          rowData,
-					Schema:        schemaInfo,          // Will be nil if includeSchema is false
-					BinlogFile:    m.currentBinlogFile, // Use tracked binlog filename
-					BinlogPos:     ev.Header.LogPos,
-					ServerID:      ev.Header.ServerID,
-					CorrelationID: m.generateCorrelationID(),
-				}
+				before, after := e.Rows[i-1], e.Rows[i]
 
-				// Add GTID if enabled
-				if handlerSettings.IncludeGTID {
-					// GTID handling would go here if needed
-					binlogEvent.GTID = ""
-				}
+				binlogEvent := m.newRowEvent(ev, eventType, databaseName, tableName, m.formatRow(after, schemaInfo), schemaInfo, handlerSettings)
+				binlogEvent.OldData = m.formatRow(before, schemaInfo)
+				binlogEvent.ChangedColumns = changedColumns(before, after,
+					skippedColumns(e, i-1), skippedColumns(e, i), schemaColumnNames(schemaInfo))
 
-				m.logger.Debugf("Generated UPDATE binlog event: ID=%s, Type=%s, Table=%s.%s (after row)",
-					binlogEvent.ID, binlogEvent.Type, binlogEvent.Database, binlogEvent.Table)
+				m.logger.Debugf("Generated UPDATE binlog event: ID=%s, Type=%s, Table=%s.%s, changed=%v",
+					binlogEvent.ID, binlogEvent.Type, binlogEvent.Database, binlogEvent.Table, binlogEvent.ChangedColumns)
 
 				// Convert to output format and trigger handler
 				if err := eventHandler.HandleEvent(ctx, binlogEvent); err != nil {
+					if m.checkpoint != nil {
+						return fmt.Errorf("error handling binlog event at %s:%d: %w", m.currentBinlogFile, ev.Header.LogPos, err)
+					}
 					m.logger.Errorf("Error handling binlog event: %v", err)
 				}
 			}
 		} else {
 			// For INSERT and DELETE events, process each row normally
 			for _, row := range e.Rows {
-				var rowData map[string]interface{}
-				var schemaInfo map[string]interface{}
-
-				// Handle schema information if enabled
-				if handlerSettings.IncludeSchema {
-					schema, err := m.getTableSchema(databaseName, tableName)
-					if err != nil {
-						m.logger.Warnf("Failed to get schema for table %s.%s: %v, falling back to column indices",
-							databaseName, tableName, err)
-						// Fallback to column indices
-						rowData = formatRowDataByIndex(row)
-					} else {
-						// Use column names from schema
-						rowData = m.formatRowDataWithSchema(row, schema)
-						schemaInfo = schema
-					}
-				} else {
-					// Convert row data to map with column indices (default behavior)
-					rowData = formatRowDataByIndex(row)
-				}
-
-				// Create binlog event
-				binlogEvent := &BinlogEvent{
-					ID:            m.generateCorrelationID(),
-					Type:          eventType,
-					Database:      databaseName,
-					Table:         tableName,
-					Timestamp:     time.Unix(int64(ev.Header.Timestamp), 0),
-					Data:          rowData,
-					Schema:        schemaInfo,          // Will be nil if includeSchema is false
-					BinlogFile:    m.currentBinlogFile, // Use tracked binlog filename
-					BinlogPos:     ev.Header.LogPos,
-					ServerID:      ev.Header.ServerID,
-					CorrelationID: m.generateCorrelationID(),
-				}
-
-				// Add GTID if enabled
-				if handlerSettings.IncludeGTID {
-					// GTID handling would go here if needed
-					binlogEvent.GTID = ""
+				rowData := m.formatRow(row, schemaInfo)
+				binlogEvent := m.newRowEvent(ev, eventType, databaseName, tableName, rowData, schemaInfo, handlerSettings)
+				if eventType == "DELETE" {
+					// The deleted row is the before image; data keeps carrying it
+					// for flows written before oldData existed.
+					binlogEvent.OldData = rowData
 				}
 
 				m.logger.Debugf("Generated INSERT/DELETE binlog event: ID=%s, Type=%s, Table=%s.%s",
@@ -777,25 +758,48 @@ func (m *MySQLBinlogListener) processBinlogEvent(ev *replication.BinlogEvent, ta
 
 				// Convert to output format and trigger handler
 				if err := eventHandler.HandleEvent(ctx, binlogEvent); err != nil {
+					if m.checkpoint != nil {
+						return fmt.Errorf("error handling binlog event at %s:%d: %w", m.currentBinlogFile, ev.Header.LogPos, err)
+					}
 					m.logger.Errorf("Error handling binlog event: %v", err)
 				}
 			}
 		}
 
+	case *replication.XIDEvent:
+		// Transaction commit: every row of the transaction has been handled.
+		m.commitPosition(ctx, ev.Header.LogPos)
+
 	case *replication.QueryEvent:
 		// Handle DDL events (CREATE, ALTER, DROP, etc.)
 		query := string(e.Query)
 		databaseName := string(e.Schema)
 
 		m.logger.Debugf("Query event: %s on database %s", query, databaseName)
-		// A DDL statement may alter a table's structure, so drop the cached
-		// schemas; subsequent rows are then decoded with fresh column metadata.
-		m.schemaMutex.Lock()
-		if len(m.schemaCache) > 0 {
-			m.schemaCache = make(map[string]map[string]interface{})
-			m.logger.Debugf("Cleared table schema cache after DDL: %s", query)
+
+		// BEGIN opens a transaction; anything else (the COMMIT of a
+		// non-transactional table, or a DDL statement) ends one.
+		if strings.EqualFold(strings.TrimSpace(query), "BEGIN") {
+			return nil
 		}
-		m.schemaMutex.Unlock()
+
+		if ddl, ok := parseDDL(query, databaseName); ok {
+			// A DDL statement may alter a table's structure, so drop the cached
+			// schemas; subsequent rows are then decoded with fresh column metadata.
+			m.schemaMutex.Lock()
+			if len(m.schemaCache) > 0 {
+				m.schemaCache = make(map[string]map[string]interface{})
+				m.logger.Debugf("Cleared table schema cache after DDL: %s", query)
+			}
+			m.schemaMutex.Unlock()
+
+			if err := m.handleDDL(ctx, ev, ddl, tableFilter, handlerSettings, eventHandler); err != nil {
+				// Leave the checkpoint before the statement so it is redelivered.
+				return err
+			}
+		}
+
+		m.commitPosition(ctx, ev.Header.LogPos)
 
 	default:
 		// Skip other event types (format description, etc.)
@@ -806,6 +810,104 @@ func (m *MySQLBinlogListener) processBinlogEvent(ev *replication.BinlogEvent, ta
 	return nil
 }
 
+// handleDDL emits a DDL event for a parsed schema change when the handler
+// has includeDDL enabled. Statements on a table outside the table filter are
+// skipped, as are database-level statements when a table filter is set.
+func (m *MySQLBinlogListener) handleDDL(ctx context.Context, ev *replication.BinlogEvent, ddl *DDLStatement, tableFilter map[string]bool, handlerSettings *HandlerSettings, eventHandler EventHandler) error {
+	if !handlerSettings.IncludeDDL {
+		return nil
+	}
+	if tableFilter != nil && !tableFilter[ddl.Table] {
+		m.logger.Debugf("Skipping DDL %s on %q (not in filter)", ddl.Type, ddl.Table)
+		return nil
+	}
+
+	binlogEvent := m.newRowEvent(ev, "DDL", ddl.Database, ddl.Table, nil, nil, handlerSettings)
+	binlogEvent.DDLType = ddl.Type
+	binlogEvent.Query = ddl.Query
+
+	m.logger.Debugf("Generated DDL binlog event: ID=%s, DDL=%s, Table=%s.%s",
+		binlogEvent.ID, ddl.Type, binlogEvent.Database, binlogEvent.Table)
+
+	if err := eventHandler.HandleEvent(ctx, binlogEvent); err != nil {
+		if m.checkpoint != nil {
+			return fmt.Errorf("error handling DDL event at %s:%d: %w", m.currentBinlogFile, ev.Header.LogPos, err)
+		}
+		m.logger.Errorf("Error handling DDL event: %v", err)
+	}
+	return nil
+}
+
+// newRowEvent creates a binlog event for the position and time of ev.
+func (m *MySQLBinlogListener) newRowEvent(ev *replication.BinlogEvent, eventType, database, table string, data, schema map[string]interface{}, handlerSettings *HandlerSettings) *BinlogEvent {
+	binlogEvent := &BinlogEvent{
+		ID:            m.generateCorrelationID(),
+		Type:          eventType,
+		Database:      database,
+		Table:         table,
+		Timestamp:     time.Unix(int64(ev.Header.Timestamp), 0),
+		Data:          data,
+		Schema:        schema,              // Will be nil if includeSchema is false
+		BinlogFile:    m.currentBinlogFile, // Use tracked binlog filename
+		BinlogPos:     ev.Header.LogPos,
+		ServerID:      ev.Header.ServerID,
+		CorrelationID: m.generateCorrelationID(),
+	}
+
+	// Add GTID if enabled
+	if handlerSettings.IncludeGTID {
+		// GTID handling would go here if needed
+		binlogEvent.GTID = ""
+	}
+	return binlogEvent
+}
+
+// commitPosition records the transaction boundary ending at logPos in the
+// current binlog file and advances the durable checkpoint to it.
+func (m *MySQLBinlogListener) commitPosition(ctx context.Context, logPos uint32) {
+	if logPos == 0 {
+		return
+	}
+	m.committedPos = mysql.Position{Name: m.currentBinlogFile, Pos: logPos}
+	m.checkpoint.Advance(ctx, formatBinlogPosition(m.committedPos))
+}
+
+// loadCheckpoint returns the committed binlog position, if one is stored.
+func (m *MySQLBinlogListener) loadCheckpoint(ctx context.Context) (mysql.Position, bool) {
+	position, err := m.checkpoint.Load(ctx)
+	if err != nil {
+		m.logger.Errorf("Failed to load binlog checkpoint, falling back to the configured position: %v", err)
+		return mysql.Position{}, false
+	}
+	if position == "" {
+		return mysql.Position{}, false
+	}
+	pos, err := parseBinlogPosition(position)
+	if err != nil {
+		m.logger.Errorf("Ignoring binlog checkpoint: %v", err)
+		return mysql.Position{}, false
+	}
+	return pos, true
+}
+
+// formatBinlogPosition renders a binlog position as a checkpoint position.
+func formatBinlogPosition(pos mysql.Position) string {
+	return fmt.Sprintf("%s:%d", pos.Name, pos.Pos)
+}
+
+// parseBinlogPosition parses a "file:pos" checkpoint position.
+func parseBinlogPosition(position string) (mysql.Position, error) {
+	i := strings.LastIndex(position, ":")
+	if i <= 0 || i == len(position)-1 {
+		return mysql.Position{}, fmt.Errorf("invalid binlog position %q (expected file:pos)", position)
+	}
+	p, err := strconv.ParseUint(position[i+1:], 10, 32)
+	if err != nil {
+		return mysql.Position{}, fmt.Errorf("invalid binlog position %q: %v", position, err)
+	}
+	return mysql.Position{Name: position[:i], Pos: uint32(p)}, nil
+}
+
 // buildConnectionString creates a MySQL connection string
 func (m *MySQLBinlogListener) buildConnectionString() (string, error) {
 	m.logger.Debug("Building MySQL connection string")
@@ -1211,6 +1313,62 @@ func (m *MySQLBinlogListener) formatRowDataWithSchema(row []interface{}, schema
 	return rowData
 }
 
+// formatRow maps a row image to column names when schema is available and to
+// col_N keys otherwise.
+func (m *MySQLBinlogListener) formatRow(row []interface{}, schema map[string]interface{}) map[string]interface{} {
+	if schema == nil {
+		return formatRowDataByIndex(row)
+	}
+	return m.formatRowDataWithSchema(row, schema)
+}
+
+// schemaColumnNames returns the column names of a cached table schema, or nil.
+func schemaColumnNames(schema map[string]interface{}) []string {
+	names, _ := schema["column_names"].([]string)
+	return names
+}
+
+// columnKey returns the data key of column i: its name when known, col_i
+// otherwise (matching formatRowDataWithSchema and formatRowDataByIndex).
+func columnKey(i int, columnNames []string) string {
+	if i < len(columnNames) {
+		return columnNames[i]
+	}
+	return fmt.Sprintf("col_%d", i)
+}
+
+// skippedColumns returns the columns missing from row image n of a rows event,
+// which happens with binlog_row_image=MINIMAL or NOBLOB.
+func skippedColumns(e *replication.RowsEvent, n int) map[int]bool {
+	if n >= len(e.SkippedColumns) || len(e.SkippedColumns[n]) == 0 {
+		return nil
+	}
+	skipped := make(map[int]bool, len(e.SkippedColumns[n]))
+	for _, c := range e.SkippedColumns[n] {
+		skipped[c] = true
+	}
+	return skipped
+}
+
+// changedColumns returns the data keys of the columns whose value differs
+// between the before and after image of an UPDATE, in column order. A column
+// missing from the after image was not changed; one missing only from the
+// before image (binlog_row_image=MINIMAL logs just the key there) is reported
+// as changed.
+func changedColumns(before, after []interface{}, beforeSkipped, afterSkipped map[int]bool, columnNames []string) []string {
+	changed := make([]string, 0)
+	for i := range after {
+		if afterSkipped[i] {
+			continue
+		}
+		if i >= len(before) || beforeSkipped[i] ||
+			!reflect.DeepEqual(normalizeRowValue(before[i]), normalizeRowValue(after[i])) {
+			changed = append(changed, columnKey(i, columnNames))
+		}
+	}
+	return changed
+}
+
 // formatRowDataByIndex maps row values to col_0, col_1, ... keys. It is used
 // when schema information is unavailable or disabled.
 func formatRowDataByIndex(row []interface{}) map[string]interface{} {
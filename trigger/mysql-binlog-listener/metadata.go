@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
 	"github.com/project-flogo/core/data/coerce"
 )
 
@@ -31,6 +32,15 @@ type Settings struct {
 	SSLCert       string `md:"sslCert"`       // SSL certificate file path
 	SSLKey        string `md:"sslKey"`        // SSL private key file path
 	SkipSSLVerify bool   `md:"skipSSLVerify"` // Skip SSL certificate verification (only for require mode)
+
+	// Checkpointing — durable binlog position across restarts
+	CheckpointStore    string `md:"checkpointStore"`    // none (default), file, sql
+	CheckpointFile     string `md:"checkpointFile"`     // Checkpoint file path (file store)
+	CheckpointDriver   string `md:"checkpointDriver"`   // postgres, mysql (sql store)
+	CheckpointDSN      string `md:"checkpointDSN"`      // Connection string for the checkpoint database (sql store)
+	CheckpointTable    string `md:"checkpointTable"`    // Checkpoint table (default flogo_cdc_checkpoints)
+	CheckpointInterval int    `md:"checkpointInterval"` // Commit the position every N transactions (default 1)
+	CheckpointMode     string `md:"checkpointMode"`     // onSuccess (default) or always
 }
 
 // HandlerSettings contains the configuration for each binlog stream handler
@@ -41,40 +51,50 @@ type HandlerSettings struct {
 	Tables        []string `md:"tables"`            // Tables to monitor (optional, monitors all if empty)
 	IncludeGTID   bool     `md:"includeGtid"`       // Include GTID information in events
 	IncludeSchema bool     `md:"includeSchema"`     // Include schema information (column names, types) in events
+	IncludeDDL    bool     `md:"includeDDL"`        // Emit DDL events for table, view, index and database statements
 	EventTypes    string   `md:"eventTypes"`        // Event types to capture: ALL, INSERT, UPDATE, DELETE
+	CheckpointKey string   `md:"checkpointKey"`     // Checkpoint key (default mysql:<host>:<port>/<serverID>)
 }
 
 // Output represents the event data sent to Flogo flows
 type Output struct {
-	EventID       string                 `md:"eventID"`       // Unique event identifier
-	EventType     string                 `md:"eventType"`     // INSERT, UPDATE, DELETE
-	Database      string                 `md:"database"`      // Source database name
-	Table         string                 `md:"table"`         // Source table name
-	Timestamp     string                 `md:"timestamp"`     // Event timestamp (ISO format)
-	Data          map[string]interface{} `md:"data"`          // Row data (column_index -> value or column_name -> value)
-	Schema        map[string]interface{} `md:"schema"`        // Schema information (when includeSchema=true)
-	BinlogFile    string                 `md:"binlogFile"`    // Binlog file name
-	BinlogPos     int                    `md:"binlogPos"`     // Binlog position
-	ServerID      int                    `md:"serverID"`      // MySQL server ID
-	GTID          string                 `md:"gtid"`          // GTID (if enabled)
-	CorrelationID string                 `md:"correlationID"` // Correlation ID for tracing
+	EventID        string                 `md:"eventID"`        // Unique event identifier
+	EventType      string                 `md:"eventType"`      // INSERT, UPDATE, DELETE, DDL
+	Database       string                 `md:"database"`       // Source database name
+	Table          string                 `md:"table"`          // Source table name
+	Timestamp      string                 `md:"timestamp"`      // Event timestamp (ISO format)
+	Data           map[string]interface{} `md:"data"`           // Row data (column_index -> value or column_name -> value)
+	OldData        map[string]interface{} `md:"oldData"`        // Previous row image (UPDATE/DELETE)
+	ChangedColumns []string               `md:"changedColumns"` // Columns whose value changed (UPDATE)
+	DDLType        string                 `md:"ddlType"`        // Statement type, e.g. ALTER TABLE (DDL)
+	Query          string                 `md:"query"`          // Raw SQL of the statement (DDL)
+	Schema         map[string]interface{} `md:"schema"`         // Schema information (when includeSchema=true)
+	BinlogFile     string                 `md:"binlogFile"`     // Binlog file name
+	BinlogPos      int                    `md:"binlogPos"`      // Binlog position
+	ServerID       int                    `md:"serverID"`       // MySQL server ID
+	GTID           string                 `md:"gtid"`           // GTID (if enabled)
+	CorrelationID  string                 `md:"correlationID"`  // Correlation ID for tracing
 }
 
 // ToMap converts Output to map[string]interface{} for Flogo compatibility
 func (o *Output) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"eventID":       o.EventID,
-		"eventType":     o.EventType,
-		"database":      o.Database,
-		"table":         o.Table,
-		"timestamp":     o.Timestamp,
-		"data":          o.Data,
-		"schema":        o.Schema,
-		"binlogFile":    o.BinlogFile,
-		"binlogPos":     o.BinlogPos,
-		"serverID":      o.ServerID,
-		"gtid":          o.GTID,
-		"correlationID": o.CorrelationID,
+		"eventID":        o.EventID,
+		"eventType":      o.EventType,
+		"database":       o.Database,
+		"table":          o.Table,
+		"timestamp":      o.Timestamp,
+		"data":           o.Data,
+		"oldData":        o.OldData,
+		"changedColumns": o.ChangedColumns,
+		"ddlType":        o.DDLType,
+		"query":          o.Query,
+		"schema":         o.Schema,
+		"binlogFile":     o.BinlogFile,
+		"binlogPos":      o.BinlogPos,
+		"serverID":       o.ServerID,
+		"gtid":           o.GTID,
+		"correlationID":  o.CorrelationID,
 	}
 }
 
@@ -123,6 +143,41 @@ func (o *Output) FromMap(values map[string]interface{}) error {
 		}
 	}
 
+	if val, ok := values["oldData"]; ok {
+		if oldMap, ok := val.(map[string]interface{}); ok {
+			o.OldData = oldMap
+		}
+	}
+
+	if val, ok := values["changedColumns"]; ok {
+		columns, err := coerce.ToArray(val)
+		if err != nil {
+			return err
+		}
+		o.ChangedColumns = nil
+		for _, c := range columns {
+			name, err := coerce.ToString(c)
+			if err != nil {
+				return err
+			}
+			o.ChangedColumns = append(o.ChangedColumns, name)
+		}
+	}
+
+	if val, ok := values["ddlType"]; ok {
+		o.DDLType, err = coerce.ToString(val)
+		if err != nil {
+			return err
+		}
+	}
+
+	if val, ok := values["query"]; ok {
+		o.Query, err = coerce.ToString(val)
+		if err != nil {
+			return err
+		}
+	}
+
 	if val, ok := values["schema"]; ok {
 		if schemaMap, ok := val.(map[string]interface{}); ok {
 			o.Schema = schemaMap
@@ -169,18 +224,22 @@ func (o *Output) FromMap(values map[string]interface{}) error {
 
 // BinlogEvent represents a MySQL binlog event
 type BinlogEvent struct {
-	ID            string                 `json:"id"`
-	Type          string                 `json:"type"`        // INSERT, UPDATE, DELETE
-	Database      string                 `json:"database"`    // Database name
-	Table         string                 `json:"table"`       // Table name
-	Timestamp     time.Time              `json:"timestamp"`   // Event timestamp
-	Data          map[string]interface{} `json:"data"`        // Row data
-	Schema        map[string]interface{} `json:"schema"`      // Schema information (when includeSchema=true)
-	BinlogFile    string                 `json:"binlog_file"` // Binlog file
-	BinlogPos     uint32                 `json:"binlog_pos"`  // Binlog position (keep as uint32 for internal use)
-	ServerID      uint32                 `json:"server_id"`   // MySQL server ID (keep as uint32 for internal use)
-	GTID          string                 `json:"gtid"`        // GTID information
-	CorrelationID string                 `json:"correlation_id"`
+	ID             string                 `json:"id"`
+	Type           string                 `json:"type"`            // INSERT, UPDATE, DELETE, DDL
+	Database       string                 `json:"database"`        // Database name
+	Table          string                 `json:"table"`           // Table name
+	Timestamp      time.Time              `json:"timestamp"`       // Event timestamp
+	Data           map[string]interface{} `json:"data"`            // Row data
+	OldData        map[string]interface{} `json:"old_data"`        // Before image (UPDATE/DELETE)
+	ChangedColumns []string               `json:"changed_columns"` // Columns changed by an UPDATE
+	DDLType        string                 `json:"ddl_type"`        // Parsed statement type (DDL)
+	Query          string                 `json:"query"`           // Raw SQL (DDL)
+	Schema         map[string]interface{} `json:"schema"`          // Schema information (when includeSchema=true)
+	BinlogFile     string                 `json:"binlog_file"`     // Binlog file
+	BinlogPos      uint32                 `json:"binlog_pos"`      // Binlog position (keep as uint32 for internal use)
+	ServerID       uint32                 `json:"server_id"`       // MySQL server ID (keep as uint32 for internal use)
+	GTID           string                 `json:"gtid"`            // GTID information
+	CorrelationID  string                 `json:"correlation_id"`
 }
 
 // EventHandler defines how binlog events are processed
@@ -260,7 +319,20 @@ func (s *Settings) Validate() error {
 		}
 	}
 
-	return nil
+	return s.CheckpointConfig().Validate()
+}
+
+// CheckpointConfig returns the checkpoint store configuration
+func (s *Settings) CheckpointConfig() cdccheckpoint.Config {
+	return cdccheckpoint.Config{
+		Store:    s.CheckpointStore,
+		File:     s.CheckpointFile,
+		Driver:   s.CheckpointDriver,
+		DSN:      s.CheckpointDSN,
+		Table:    s.CheckpointTable,
+		Interval: s.CheckpointInterval,
+		Mode:     s.CheckpointMode,
+	}
 }
 
 // Validate validates the handler settings
@@ -290,6 +362,15 @@ func (h *HandlerSettings) Validate() error {
 	return nil
 }
 
+// ResolvedCheckpointKey returns the key the handler's binlog position is stored
+// under, defaulting to mysql:<host>:<port>/<serverID>
+func (h *HandlerSettings) ResolvedCheckpointKey(s *Settings) string {
+	if k := strings.TrimSpace(h.CheckpointKey); k != "" {
+		return k
+	}
+	return fmt.Sprintf("mysql:%s:%d/%d", s.Host, s.Port, h.ServerID)
+}
+
 // EventTypeSet returns the set of enabled event types, expanding "ALL" (or an
 // empty value) to INSERT/UPDATE/DELETE and supporting a comma-separated list.
 func (h *HandlerSettings) EventTypeSet() map[string]bool {
@@ -7,6 +7,9 @@ import (
 	"sync"
 	"time"
 
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+	_ "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/mysql"
+	_ "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres"
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
 	"github.com/project-flogo/core/support/trace"
@@ -27,6 +30,8 @@ type Trigger struct {
 	isStarted bool
 	mutex     sync.Mutex
 	startTime time.Time // Trigger start time for metrics
+
+	checkpoints cdccheckpoint.Store // Durable position store (nil when checkpointStore is none)
 }
 
 // Handler represents a binlog stream handler
@@ -39,6 +44,9 @@ type Handler struct {
 type FlogoEventHandler struct {
 	runner trigger.Handler
 	logger log.Logger
+	// advanceOnError swallows flow errors so the checkpoint moves past failed
+	// events (checkpointMode=always)
+	advanceOnError bool
 }
 
 // NewFlogoEventHandler creates a new Flogo event handler
@@ -112,18 +120,22 @@ func (h *FlogoEventHandler) HandleEvent(ctx context.Context, event *BinlogEvent)
 	// Convert BinlogEvent to Flogo Output
 	// Ensure all values are JSON-serializable and Flogo-compatible
 	output := &Output{
-		EventID:       event.ID,
-		EventType:     event.Type,
-		Database:      event.Database,
-		Table:         event.Table,
-		Timestamp:     event.Timestamp.Format(time.RFC3339), // Convert time.Time to string in ISO format
-		Data:          event.Data,
-		Schema:        event.Schema, // Include schema information when available
-		BinlogFile:    event.BinlogFile,
-		BinlogPos:     int(event.BinlogPos), // Convert uint32 to int for Flogo output
-		ServerID:      int(event.ServerID),  // Convert uint32 to int for Flogo output
-		GTID:          event.GTID,
-		CorrelationID: event.CorrelationID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Database:       event.Database,
+		Table:          event.Table,
+		Timestamp:      event.Timestamp.Format(time.RFC3339), // Convert time.Time to string in ISO format
+		Data:           event.Data,
+		OldData:        event.OldData,
+		ChangedColumns: event.ChangedColumns,
+		DDLType:        event.DDLType,
+		Query:          event.Query,
+		Schema:         event.Schema, // Include schema information when available
+		BinlogFile:     event.BinlogFile,
+		BinlogPos:      int(event.BinlogPos), // Convert uint32 to int for Flogo output
+		ServerID:       int(event.ServerID),  // Convert uint32 to int for Flogo output
+		GTID:           event.GTID,
+		CorrelationID:  event.CorrelationID,
 	}
 
 	h.logger.Debugf("Processing MySQL binlog event: %s on %s.%s (TraceID: %s)",
@@ -170,6 +182,10 @@ func (h *FlogoEventHandler) HandleEvent(ctx context.Context, event *BinlogEvent)
 	}
 
 	if err != nil {
+		if h.advanceOnError {
+			h.logger.Warnf("Flogo flow failed for event %s, advancing past it (checkpointMode=always): %v", event.ID, err)
+			return nil
+		}
 		return fmt.Errorf("failed to execute Flogo flow: %v", err)
 	}
 
@@ -255,6 +271,19 @@ func (t *Trigger) Start() error {
 		return fmt.Errorf("MySQL binlog trigger is already started")
 	}
 
+	// Open the durable checkpoint store, if configured. It is opened per run:
+	// Stop closes it, so a restarted trigger needs a fresh one.
+	openCtx, cancel := context.WithTimeout(context.Background(), cdccheckpoint.OpenTimeout)
+	store, err := cdccheckpoint.Open(openCtx, t.settings.CheckpointConfig())
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %v", err)
+	}
+	t.checkpoints = store
+	if t.ctx.Err() != nil {
+		t.ctx, t.cancel = context.WithCancel(context.Background())
+	}
+
 	// Record start time for metrics
 	t.startTime = time.Now()
 
@@ -290,6 +319,11 @@ func (t *Trigger) Start() error {
 	// Start binlog streaming for each handler
 	for _, h := range t.handlers {
 		eventHandler := NewFlogoEventHandler(h.runner, t.logger)
+		eventHandler.advanceOnError = t.settings.CheckpointConfig().AdvanceOnError()
+
+		// Commit this handler's binlog position to the checkpoint store
+		t.listener.SetCheckpointer(cdccheckpoint.NewCheckpointer(t.checkpoints, h.settings.ResolvedCheckpointKey(t.settings),
+			t.settings.CheckpointInterval, t.logger))
 
 		// Start listening with this handler's settings
 		if err := t.listener.StartListening(t.ctx, h.settings, eventHandler); err != nil {
@@ -297,6 +331,10 @@ func (t *Trigger) Start() error {
 			if tracingCtx != nil {
 				trace.GetTracer().FinishTrace(tracingCtx, err)
 			}
+			if t.checkpoints != nil {
+				t.checkpoints.Close()
+				t.checkpoints = nil
+			}
 			return fmt.Errorf("failed to start binlog streaming for handler: %v", err)
 		}
 	}
@@ -351,6 +389,14 @@ func (t *Trigger) Stop() error {
 	// Wait for monitoring goroutines to finish
 	t.wg.Wait()
 
+	// Close the checkpoint store after the listener committed its last position
+	if t.checkpoints != nil {
+		if err := t.checkpoints.Close(); err != nil {
+			t.logger.Warnf("Error closing checkpoint store: %v", err)
+		}
+		t.checkpoints = nil
+	}
+
 	t.isStarted = false
 	t.logger.Info("MySQL binlog trigger stopped successfully")
 
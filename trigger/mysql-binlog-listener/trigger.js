@@ -50,6 +50,11 @@ var MySQLBinlogListenerHandler = function (e) {
         // Heartbeat:
         //   heartbeatInterval — only when enableHeartbeat=true
         //
+        // Checkpoint:
+        //   checkpointFile — only when checkpointStore=file
+        //   checkpointDriver/checkpointDSN/checkpointTable — only when checkpointStore=sql
+        //   checkpointInterval/checkpointMode — whenever a checkpoint store is selected
+        //
         // Handler:
         //   binlogPos — only when binlogFile is non-empty
         n.validate = function (fieldName, settings) {
@@ -86,6 +91,20 @@ var MySQLBinlogListenerHandler = function (e) {
                     return validation_1.ValidationResult.newValidationResult()
                         .setVisible(!!enabled);
                 }
+                case "checkpointFile":
+                case "checkpointDriver":
+                case "checkpointDSN":
+                case "checkpointTable":
+                case "checkpointInterval":
+                case "checkpointMode": {
+                    var storeField = settings.getField("checkpointStore");
+                    var store = storeField && storeField.value ? storeField.value : "none";
+                    var visible = fieldName === "checkpointFile" ? store === "file"
+                        : (fieldName === "checkpointInterval" || fieldName === "checkpointMode") ? store !== "none"
+                        : store === "sql";
+                    return validation_1.ValidationResult.newValidationResult()
+                        .setVisible(visible);
+                }
                 case "binlogPos": {
                     var bf = settings.getField("binlogFile");
                     var hasFile = bf && bf.value != null && bf.value !== "";
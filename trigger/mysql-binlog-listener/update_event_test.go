@@ -76,6 +76,111 @@ func TestUpdateEventHandling(t *testing.T) {
 	assert.Equal(t, 1, eventCount, "UPDATE operation should generate exactly one event, got %d", eventCount)
 }
 
+// TestUpdateEventBeforeAfterImages verifies that UPDATE events carry the before
+// image in OldData and list the columns whose value changed
+func TestUpdateEventBeforeAfterImages(t *testing.T) {
+	var events []*BinlogEvent
+	handler := &MockEventHandler{
+		handleFunc: func(ctx context.Context, event *BinlogEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	}
+	listener := NewMySQLBinlogListener(&Settings{DatabaseName: "testdb"}, log.RootLogger())
+
+	updateEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.UPDATE_ROWS_EVENTv2, LogPos: 1000},
+		Event: &replication.RowsEvent{
+			Table: &replication.TableMapEvent{Schema: []byte("testdb"), Table: []byte("users")},
+			Rows: [][]interface{}{
+				{1, []byte("john"), "john@example.com", nil},
+				{1, []byte("john"), "john.new@example.com", 42},
+				{2, "jane", "jane@example.com", 7},
+				{2, "janet", "jane@example.com", 7},
+			},
+		},
+	}
+
+	err := listener.processBinlogEvent(updateEvent, nil, nil, &HandlerSettings{ServerID: 1001}, handler, context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "john@example.com", events[0].OldData["col_2"])
+		assert.Equal(t, "john.new@example.com", events[0].Data["col_2"])
+		assert.Equal(t, []string{"col_2", "col_3"}, events[0].ChangedColumns)
+		assert.Equal(t, []string{"col_1"}, events[1].ChangedColumns)
+	}
+}
+
+// TestUpdateEventMinimalRowImage verifies changedColumns with
+// binlog_row_image=MINIMAL, where the before image only holds the key and the
+// after image only the assigned columns
+func TestUpdateEventMinimalRowImage(t *testing.T) {
+	var got *BinlogEvent
+	handler := &MockEventHandler{
+		handleFunc: func(ctx context.Context, event *BinlogEvent) error {
+			got = event
+			return nil
+		},
+	}
+	listener := NewMySQLBinlogListener(&Settings{DatabaseName: "testdb"}, log.RootLogger())
+
+	updateEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.UPDATE_ROWS_EVENTv2, LogPos: 1000},
+		Event: &replication.RowsEvent{
+			Table: &replication.TableMapEvent{Schema: []byte("testdb"), Table: []byte("users")},
+			Rows: [][]interface{}{
+				{1, nil, nil},
+				{nil, nil, "new@example.com"},
+			},
+			SkippedColumns: [][]int{{1, 2}, {0, 1}},
+		},
+	}
+
+	err := listener.processBinlogEvent(updateEvent, nil, nil, &HandlerSettings{ServerID: 1001}, handler, context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, []string{"col_2"}, got.ChangedColumns)
+	}
+}
+
+// TestDeleteEventOldData verifies that DELETE events expose the deleted row as
+// OldData while keeping it in Data
+func TestDeleteEventOldData(t *testing.T) {
+	var got *BinlogEvent
+	handler := &MockEventHandler{
+		handleFunc: func(ctx context.Context, event *BinlogEvent) error {
+			got = event
+			return nil
+		},
+	}
+	listener := NewMySQLBinlogListener(&Settings{DatabaseName: "testdb"}, log.RootLogger())
+
+	deleteEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.DELETE_ROWS_EVENTv2, LogPos: 1000},
+		Event: &replication.RowsEvent{
+			Table: &replication.TableMapEvent{Schema: []byte("testdb"), Table: []byte("users")},
+			Rows:  [][]interface{}{{1, "john"}},
+		},
+	}
+
+	err := listener.processBinlogEvent(deleteEvent, nil, nil, &HandlerSettings{ServerID: 1001}, handler, context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "DELETE", got.Type)
+		assert.Equal(t, map[string]interface{}{"col_0": 1, "col_1": "john"}, got.OldData)
+		assert.Equal(t, got.OldData, got.Data)
+		assert.Nil(t, got.ChangedColumns)
+	}
+}
+
+func TestChangedColumnsUsesColumnNames(t *testing.T) {
+	changed := changedColumns(
+		[]interface{}{1, "a", []byte("x")},
+		[]interface{}{1, "b", []byte("x"), "extra"},
+		nil, nil, []string{"id", "name", "note"})
+	assert.Equal(t, []string{"name", "col_3"}, changed)
+}
+
 // MockEventHandler for testing
 type MockEventHandler struct {
 	handleFunc func(ctx context.Context, event *BinlogEvent) error
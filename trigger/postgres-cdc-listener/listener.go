@@ -64,6 +64,10 @@ type PostgresCDCListener struct {
 	recordLSN      pglogrepl.LSN
 	standbyTimeout time.Duration
 
+	// snapshot is set when Prepare created the slot with an exported snapshot
+	// that Stream must read before it starts replication.
+	snapshot *slotSnapshot
+
 	mu      sync.Mutex
 	running bool
 	cancel  context.CancelFunc
@@ -221,18 +225,38 @@ func (l *PostgresCDCListener) ensureSlot(ctx context.Context) error {
 	}
 	if exists {
 		l.logger.Infof("PostgreSQL CDC: using existing replication slot %q", l.handler.SlotName)
+		if l.handler.SnapshotEnabled() {
+			// The snapshot is only taken together with the slot; an existing
+			// slot means it already ran (or the slot was created elsewhere).
+			l.logger.Infof("PostgreSQL CDC: skipping initial snapshot, slot %q already exists", l.handler.SlotName)
+		}
 		return nil
 	}
 	if !l.handler.CreateSlotIfNotExists {
 		return fmt.Errorf("replication slot %q does not exist (enable createSlotIfNotExists or create it manually)", l.handler.SlotName)
 	}
 
-	l.logger.Infof("PostgreSQL CDC: creating replication slot %q (temporary=%t)", l.handler.SlotName, l.handler.TemporarySlot)
-	_, err = pglogrepl.CreateReplicationSlot(ctx, l.replConn, l.handler.SlotName, outputPlugin,
-		pglogrepl.CreateReplicationSlotOptions{Temporary: l.handler.TemporarySlot})
+	snapshotEnabled := l.handler.SnapshotEnabled()
+	opts := pglogrepl.CreateReplicationSlotOptions{Temporary: l.handler.TemporarySlot}
+	if snapshotEnabled {
+		opts.SnapshotAction = "EXPORT_SNAPSHOT"
+	}
+	l.logger.Infof("PostgreSQL CDC: creating replication slot %q (temporary=%t snapshot=%t)",
+		l.handler.SlotName, l.handler.TemporarySlot, snapshotEnabled)
+	result, err := pglogrepl.CreateReplicationSlot(ctx, l.replConn, l.handler.SlotName, outputPlugin, opts)
 	if err != nil {
 		return fmt.Errorf("failed to create replication slot: %w", err)
 	}
+	if snapshotEnabled {
+		consistentPoint, err := pglogrepl.ParseLSN(result.ConsistentPoint)
+		if err != nil {
+			return fmt.Errorf("failed to parse slot consistent point %q: %w", result.ConsistentPoint, err)
+		}
+		if result.SnapshotName == "" {
+			return fmt.Errorf("replication slot %q was created without an exported snapshot", l.handler.SlotName)
+		}
+		l.snapshot = &slotSnapshot{name: result.SnapshotName, consistentPoint: consistentPoint}
+	}
 	return nil
 }
 
@@ -255,6 +279,21 @@ func (l *PostgresCDCListener) Stream(ctx context.Context, handler EventHandler)
 		l.mu.Unlock()
 	}()
 
+	// Deliver the existing rows first, then stream from the consistent point
+	// the snapshot was taken at.
+	if l.snapshot != nil {
+		if err := l.runSnapshot(streamCtx, l.snapshot, handler); err != nil {
+			l.dropSlotAfterFailedSnapshot()
+			if streamCtx.Err() != nil {
+				l.logger.Info("PostgreSQL CDC: stream context cancelled during initial snapshot, stopping")
+				return nil
+			}
+			return fmt.Errorf("initial snapshot failed: %w", err)
+		}
+		l.clientXLogPos = l.snapshot.consistentPoint
+		l.snapshot = nil
+	}
+
 	pluginArgs := []string{
 		"proto_version '1'",
 		fmt.Sprintf("publication_names '%s'", l.handler.PublicationName),
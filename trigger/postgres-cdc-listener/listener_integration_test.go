@@ -145,6 +145,87 @@ func TestPostgresCDCIntegration(t *testing.T) {
 	}
 }
 
+// TestPostgresCDCSnapshotIntegration checks that snapshotMode "initial" emits the
+// rows that existed before the slot was created as READ events, then streams
+// changes committed afterwards from the slot's consistent point.
+func TestPostgresCDCSnapshotIntegration(t *testing.T) {
+	if os.Getenv("PG_CDC_IT") == "" {
+		t.Skip("set PG_CDC_IT=1 to run the live PostgreSQL CDC integration test")
+	}
+
+	settings := testSettings()
+	logger := log.RootLogger()
+	ctx := context.Background()
+
+	suffix := fmt.Sprintf("%d", time.Now().Unix())
+	handler := &HandlerSettings{
+		SlotName:                     "flogo_it_snap_slot_" + suffix,
+		PublicationName:              "flogo_it_snap_pub_" + suffix,
+		CreateSlotIfNotExists:        true,
+		CreatePublicationIfNotExists: true,
+		Tables:                       []string{"public.cdc_snap_items"},
+		EventTypes:                   "INSERT",
+		SnapshotMode:                 SnapshotModeInitial,
+		SnapshotChunkSize:            2, // force several FETCH round trips
+	}
+
+	setupConn := mustConnect(t, ctx, settings, false)
+	defer setupConn.Close(ctx)
+
+	execSQL(t, ctx, setupConn, `DROP TABLE IF EXISTS cdc_snap_items`)
+	execSQL(t, ctx, setupConn, `CREATE TABLE cdc_snap_items (id int PRIMARY KEY, name text)`)
+	execSQL(t, ctx, setupConn, `INSERT INTO cdc_snap_items VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e')`)
+
+	listener := NewPostgresCDCListener(settings, handler, logger)
+	require.NoError(t, listener.Prepare(ctx), "Prepare should create the slot with a snapshot")
+
+	defer func() {
+		listener.Close(context.Background())
+		cleanupConn := mustConnect(t, context.Background(), settings, false)
+		defer cleanupConn.Close(context.Background())
+		_ = cleanupConn.Exec(context.Background(),
+			fmt.Sprintf("SELECT pg_drop_replication_slot('%s')", handler.SlotName)).Close()
+		_ = cleanupConn.Exec(context.Background(),
+			fmt.Sprintf("DROP PUBLICATION IF EXISTS %s", handler.PublicationName)).Close()
+		_ = cleanupConn.Exec(context.Background(), "DROP TABLE IF EXISTS cdc_snap_items").Close()
+	}()
+
+	// Committed after the slot's consistent point: must arrive from the stream,
+	// not from the snapshot, even though the snapshot has not been read yet.
+	execSQL(t, ctx, setupConn, `INSERT INTO cdc_snap_items VALUES (6, 'f')`)
+
+	coll := &collector{ch: make(chan *ChangeEvent, 32)}
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	defer cancelStream()
+	streamErr := make(chan error, 1)
+	go func() { streamErr <- listener.Stream(streamCtx, coll) }()
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 5; i++ {
+		ev := waitForEvent(t, coll, 10*time.Second)
+		require.Equal(t, SnapshotEventType, ev.Type, "snapshot rows come first")
+		assert.Equal(t, "cdc_snap_items", ev.Table)
+		id, ok := ev.Data["id"].(int64)
+		require.True(t, ok, "id should decode as int64, got %T", ev.Data["id"])
+		seen[id] = true
+	}
+	assert.Len(t, seen, 5)
+	assert.False(t, seen[6], "rows after the consistent point are not part of the snapshot")
+
+	ins := waitForEvent(t, coll, 10*time.Second)
+	assert.Equal(t, "INSERT", ins.Type)
+	assert.Equal(t, int64(6), ins.Data["id"])
+	assert.Equal(t, "f", ins.Data["name"])
+
+	cancelStream()
+	select {
+	case err := <-streamErr:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not stop after cancel")
+	}
+}
+
 func mustConnect(t *testing.T, ctx context.Context, s *Settings, replication bool) *pgconn.PgConn {
 	t.Helper()
 	l := NewPostgresCDCListener(s, &HandlerSettings{}, log.RootLogger())
@@ -40,12 +40,14 @@ type HandlerSettings struct {
 	Tables                       []string `md:"tables"`                       // schema.table list for auto CREATE PUBLICATION (empty = FOR ALL TABLES)
 	EventTypes                   string   `md:"eventTypes"`                   // ALL, INSERT, UPDATE, DELETE (comma separated)
 	IncludeTransactionMarkers    bool     `md:"includeTransactionMarkers"`    // Emit BEGIN/COMMIT events in addition to row events
+	SnapshotMode                 string   `md:"snapshotMode"`                 // never (default) or initial: read existing rows when the slot is created
+	SnapshotChunkSize            int      `md:"snapshotChunkSize"`            // Rows fetched per snapshot round trip (default 1000)
 }
 
 // Output represents a single change event delivered to a Flogo flow.
 type Output struct {
 	EventID       string                 `md:"eventID"`       // Unique event identifier
-	EventType     string                 `md:"eventType"`     // INSERT, UPDATE, DELETE, TRUNCATE, BEGIN, COMMIT
+	EventType     string                 `md:"eventType"`     // INSERT, UPDATE, DELETE, TRUNCATE, BEGIN, COMMIT, READ (snapshot)
 	Database      string                 `md:"database"`      // Source database name
 	Schema        string                 `md:"schema"`        // Source schema (namespace)
 	Table         string                 `md:"table"`         // Source table name
@@ -95,9 +97,22 @@ func (h *HandlerSettings) Validate() error {
 	if !isValidPGIdentifier(h.PublicationName) {
 		return fmt.Errorf("publicationName %q must contain only lowercase letters, digits, and underscores", h.PublicationName)
 	}
+	switch strings.ToLower(strings.TrimSpace(h.SnapshotMode)) {
+	case "", SnapshotModeNever, SnapshotModeInitial:
+	default:
+		return fmt.Errorf("invalid snapshotMode %q (expected never or initial)", h.SnapshotMode)
+	}
+	if h.SnapshotChunkSize < 0 {
+		return fmt.Errorf("snapshotChunkSize must not be negative")
+	}
 	return nil
 }
 
+// SnapshotEnabled reports whether existing rows are read when the slot is created.
+func (h *HandlerSettings) SnapshotEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(h.SnapshotMode), SnapshotModeInitial)
+}
+
 // EventTypeSet returns the set of enabled row event types (INSERT/UPDATE/DELETE).
 // An empty result or a set containing "ALL" means all event types are enabled.
 func (h *HandlerSettings) EventTypeSet() map[string]bool {
@@ -0,0 +1,206 @@
+package postgrescdclistener
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Snapshot modes.
+const (
+	SnapshotModeNever   = "never"   // stream WAL changes only (default)
+	SnapshotModeInitial = "initial" // read existing rows when the slot is created, then stream
+
+	// SnapshotEventType is the event type of rows read during the snapshot phase.
+	SnapshotEventType = "READ"
+
+	defaultSnapshotChunkSize = 1000
+	snapshotCursorName       = "flogo_cdc_snapshot"
+)
+
+// slotSnapshot is the snapshot exported by CREATE_REPLICATION_SLOT. Every row
+// visible in it was committed before the slot's consistent point, and every
+// change after that point is decoded from the slot, so reading the snapshot
+// and then streaming from the consistent point yields each change exactly once.
+type slotSnapshot struct {
+	name            string
+	consistentPoint pglogrepl.LSN
+}
+
+// snapshotTable is one table of the publication.
+type snapshotTable struct {
+	schema string
+	name   string
+}
+
+// runSnapshot reads every table in the publication through the exported
+// snapshot and dispatches each row as a READ event. The snapshot transaction
+// runs on a separate SQL connection; the replication connection must stay idle
+// until it completes, because running another command there (including
+// START_REPLICATION) invalidates the exported snapshot.
+func (l *PostgresCDCListener) runSnapshot(ctx context.Context, snap *slotSnapshot, handler EventHandler) error {
+	conn, err := pgconn.Connect(ctx, l.buildConnString(false))
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot connection: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	begin := fmt.Sprintf("BEGIN ISOLATION LEVEL REPEATABLE READ, READ ONLY; SET TRANSACTION SNAPSHOT %s",
+		quoteLiteral(snap.name))
+	if _, err := conn.Exec(ctx, begin).ReadAll(); err != nil {
+		return fmt.Errorf("failed to import snapshot %q: %w", snap.name, err)
+	}
+
+	tables, err := l.publicationTables(ctx, conn)
+	if err != nil {
+		return err
+	}
+	l.logger.Infof("PostgreSQL CDC: snapshot %q started for %d table(s) in publication %q (consistent point %s)",
+		snap.name, len(tables), l.handler.PublicationName, snap.consistentPoint)
+
+	startedAt := time.Now().UTC()
+	total := 0
+	for _, tbl := range tables {
+		n, err := l.snapshotTable(ctx, conn, tbl, snap, startedAt, handler)
+		if err != nil {
+			return err
+		}
+		l.logger.Infof("PostgreSQL CDC: snapshot of %s.%s complete (%d rows)", tbl.schema, tbl.name, n)
+		total += n
+	}
+
+	if _, err := conn.Exec(ctx, "COMMIT").ReadAll(); err != nil {
+		return fmt.Errorf("failed to end snapshot transaction: %w", err)
+	}
+	l.logger.Infof("PostgreSQL CDC: snapshot complete (%d rows); streaming from %s", total, snap.consistentPoint)
+	return nil
+}
+
+// publicationTables lists the tables covered by the handler's publication.
+func (l *PostgresCDCListener) publicationTables(ctx context.Context, conn *pgconn.PgConn) ([]snapshotTable, error) {
+	// PublicationName is validated to a safe identifier.
+	query := fmt.Sprintf(`SELECT schemaname, tablename FROM pg_publication_tables
+WHERE pubname = '%s' ORDER BY schemaname, tablename`, l.handler.PublicationName)
+	results, err := conn.Exec(ctx, query).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list publication tables: %w", err)
+	}
+	var tables []snapshotTable
+	for _, r := range results {
+		for _, row := range r.Rows {
+			if len(row) < 2 {
+				continue
+			}
+			tables = append(tables, snapshotTable{schema: string(row[0]), name: string(row[1])})
+		}
+	}
+	return tables, nil
+}
+
+// snapshotTable streams one table through a cursor, chunkSize rows at a time,
+// so arbitrarily large tables are read with bounded memory.
+func (l *PostgresCDCListener) snapshotTable(ctx context.Context, conn *pgconn.PgConn, tbl snapshotTable, snap *slotSnapshot, startedAt time.Time, handler EventHandler) (int, error) {
+	chunkSize := l.handler.SnapshotChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultSnapshotChunkSize
+	}
+
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR SELECT * FROM %s.%s",
+		snapshotCursorName, quoteIdent(tbl.schema), quoteIdent(tbl.name))
+	if _, err := conn.Exec(ctx, declare).ReadAll(); err != nil {
+		return 0, fmt.Errorf("failed to open snapshot cursor for %s.%s: %w", tbl.schema, tbl.name, err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", chunkSize, snapshotCursorName)
+	count := 0
+	for {
+		results, err := conn.Exec(ctx, fetch).ReadAll()
+		if err != nil {
+			return count, fmt.Errorf("failed to read snapshot of %s.%s: %w", tbl.schema, tbl.name, err)
+		}
+		fetched := 0
+		for _, r := range results {
+			for _, row := range r.Rows {
+				ev := l.snapshotEvent(tbl, snap, startedAt)
+				ev.Data = decodeRow(r.FieldDescriptions, row)
+				if err := handler.HandleEvent(ctx, ev); err != nil {
+					return count, err
+				}
+				fetched++
+				count++
+			}
+		}
+		if fetched < chunkSize {
+			break
+		}
+		l.logger.Debugf("PostgreSQL CDC: snapshot of %s.%s at %d rows", tbl.schema, tbl.name, count)
+	}
+
+	if _, err := conn.Exec(ctx, "CLOSE "+snapshotCursorName).ReadAll(); err != nil {
+		return count, fmt.Errorf("failed to close snapshot cursor for %s.%s: %w", tbl.schema, tbl.name, err)
+	}
+	return count, nil
+}
+
+func (l *PostgresCDCListener) snapshotEvent(tbl snapshotTable, snap *slotSnapshot, startedAt time.Time) *ChangeEvent {
+	return &ChangeEvent{
+		ID:            generateID(),
+		Type:          SnapshotEventType,
+		Database:      l.settings.DatabaseName,
+		Schema:        tbl.schema,
+		Table:         tbl.name,
+		Timestamp:     startedAt,
+		LSN:           snap.consistentPoint.String(),
+		CorrelationID: generateID(),
+	}
+}
+
+// dropSlotAfterFailedSnapshot removes a slot whose snapshot did not complete,
+// so the next attempt recreates it and takes a fresh snapshot instead of
+// silently streaming from a slot whose pre-existing rows were never delivered.
+func (l *PostgresCDCListener) dropSlotAfterFailedSnapshot() {
+	if l.replConn == nil || l.handler.TemporarySlot {
+		// Temporary slots are dropped with the replication connection.
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := pglogrepl.DropReplicationSlot(ctx, l.replConn, l.handler.SlotName, pglogrepl.DropReplicationSlotOptions{}); err != nil {
+		l.logger.Errorf("PostgreSQL CDC: failed to drop slot %q after an incomplete snapshot; drop it manually to re-run the snapshot: %v",
+			l.handler.SlotName, err)
+		return
+	}
+	l.logger.Warnf("PostgreSQL CDC: dropped slot %q after an incomplete snapshot; the snapshot restarts on the next attempt",
+		l.handler.SlotName)
+}
+
+// decodeRow converts a text-format result row into a column-name keyed map
+// using the same type decoding as WAL tuples.
+func decodeRow(fields []pgconn.FieldDescription, row [][]byte) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for i, f := range fields {
+		if i >= len(row) {
+			break
+		}
+		if row[i] == nil {
+			result[f.Name] = nil
+			continue
+		}
+		result[f.Name] = decodeValue(f.DataTypeOID, string(row[i]))
+	}
+	return result
+}
+
+// quoteIdent double-quotes an identifier read from the catalog.
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// quoteLiteral single-quotes a string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
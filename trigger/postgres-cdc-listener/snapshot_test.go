@@ -0,0 +1,298 @@
+package postgrescdclistener
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePostgres is a minimal PostgreSQL wire-protocol server for unit tests.
+// It accepts any startup, records every simple query, and answers each with
+// the messages returned by respond (CommandComplete when respond returns nil).
+type fakePostgres struct {
+	ln      net.Listener
+	respond func(query string) []pgproto3.BackendMessage
+
+	mu      sync.Mutex
+	queries []string
+}
+
+func newFakePostgres(t *testing.T, respond func(query string) []pgproto3.BackendMessage) *fakePostgres {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakePostgres{ln: ln, respond: respond}
+	go f.serve()
+	t.Cleanup(func() { ln.Close() })
+	return f
+}
+
+func (f *fakePostgres) port() int {
+	return f.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakePostgres) Queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+func (f *fakePostgres) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakePostgres) handle(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		q, ok := msg.(*pgproto3.Query)
+		if !ok {
+			return // Terminate or an unsupported extended-protocol message
+		}
+		f.mu.Lock()
+		f.queries = append(f.queries, q.String)
+		f.mu.Unlock()
+
+		var reply []pgproto3.BackendMessage
+		if f.respond != nil {
+			reply = f.respond(q.String)
+		}
+		if reply == nil {
+			reply = []pgproto3.BackendMessage{&pgproto3.CommandComplete{CommandTag: []byte("OK")}}
+		}
+		for _, m := range reply {
+			backend.Send(m)
+		}
+		backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		if err := backend.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// rowsReply renders a result set of text-format rows.
+func rowsReply(fields []pgproto3.FieldDescription, rows ...[][]byte) []pgproto3.BackendMessage {
+	msgs := []pgproto3.BackendMessage{&pgproto3.RowDescription{Fields: fields}}
+	for _, r := range rows {
+		msgs = append(msgs, &pgproto3.DataRow{Values: r})
+	}
+	return append(msgs, &pgproto3.CommandComplete{CommandTag: []byte("SELECT")})
+}
+
+func textField(name string, oid uint32) pgproto3.FieldDescription {
+	return pgproto3.FieldDescription{Name: []byte(name), DataTypeOID: oid, DataTypeSize: -1, TypeModifier: -1}
+}
+
+// recordingHandler collects events and fails once failAfter events were handled.
+type recordingHandler struct {
+	events    []*ChangeEvent
+	failAfter int
+}
+
+func (h *recordingHandler) HandleEvent(_ context.Context, event *ChangeEvent) error {
+	if h.failAfter > 0 && len(h.events) >= h.failAfter {
+		return errors.New("flow failed")
+	}
+	h.events = append(h.events, event)
+	return nil
+}
+
+func newSnapshotListener(port int, handler *HandlerSettings) *PostgresCDCListener {
+	settings := &Settings{Host: "127.0.0.1", Port: port, User: "flogo", Password: "secret", DatabaseName: "shop", ConnectionTimeout: 5}
+	return NewPostgresCDCListener(settings, handler, log.RootLogger())
+}
+
+// snapshotServer serves one publication table, orders, holding three rows.
+func snapshotServer(t *testing.T) *fakePostgres {
+	orderFields := []pgproto3.FieldDescription{
+		textField("id", pgtype.Int4OID),
+		textField("paid", pgtype.BoolOID),
+		textField("note", pgtype.TextOID),
+	}
+	fetches := 0
+	return newFakePostgres(t, func(q string) []pgproto3.BackendMessage {
+		switch {
+		case strings.Contains(q, "pg_publication_tables"):
+			return rowsReply([]pgproto3.FieldDescription{textField("schemaname", pgtype.NameOID), textField("tablename", pgtype.NameOID)},
+				[][]byte{[]byte("public"), []byte("orders")})
+		case strings.HasPrefix(q, "FETCH"):
+			fetches++
+			if fetches == 1 {
+				return rowsReply(orderFields,
+					[][]byte{[]byte("1"), []byte("t"), []byte("first")},
+					[][]byte{[]byte("2"), []byte("f"), nil})
+			}
+			return rowsReply(orderFields, [][]byte{[]byte("3"), []byte("t"), []byte("last")})
+		}
+		return nil
+	})
+}
+
+func TestDecodeRow(t *testing.T) {
+	fields := []pgconn.FieldDescription{
+		{Name: "id", DataTypeOID: pgtype.Int8OID},
+		{Name: "active", DataTypeOID: pgtype.BoolOID},
+		{Name: "price", DataTypeOID: pgtype.Float8OID},
+		{Name: "name", DataTypeOID: pgtype.TextOID},
+		{Name: "deleted_at", DataTypeOID: pgtype.TimestamptzOID},
+	}
+	row := [][]byte{[]byte("42"), []byte("t"), []byte("9.5"), []byte("widget"), nil}
+
+	assert.Equal(t, map[string]interface{}{
+		"id":         int64(42),
+		"active":     true,
+		"price":      9.5,
+		"name":       "widget",
+		"deleted_at": nil,
+	}, decodeRow(fields, row))
+
+	// Columns beyond the row are left out rather than reported as NULL.
+	assert.Equal(t, map[string]interface{}{"id": int64(42)}, decodeRow(fields, row[:1]))
+	assert.Empty(t, decodeRow(nil, row))
+}
+
+func TestQuoteIdent(t *testing.T) {
+	assert.Equal(t, `"orders"`, quoteIdent("orders"))
+	assert.Equal(t, `"Order Items"`, quoteIdent("Order Items"))
+	assert.Equal(t, `"a""b"`, quoteIdent(`a"b`))
+	assert.Equal(t, `""`, quoteIdent(""))
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	assert.Equal(t, `'00000003-00000002-1'`, quoteLiteral("00000003-00000002-1"))
+	assert.Equal(t, `'it''s'`, quoteLiteral("it's"))
+}
+
+func TestSnapshotEvent(t *testing.T) {
+	l := newSnapshotListener(5432, &HandlerSettings{SlotName: "flogo_slot", PublicationName: "flogo_pub"})
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	snap := &slotSnapshot{name: "00000003-00000002-1", consistentPoint: 0x16B3748}
+
+	ev := l.snapshotEvent(snapshotTable{schema: "public", name: "orders"}, snap, startedAt)
+	assert.Equal(t, SnapshotEventType, ev.Type)
+	assert.Equal(t, "shop", ev.Database)
+	assert.Equal(t, "public", ev.Schema)
+	assert.Equal(t, "orders", ev.Table)
+	assert.Equal(t, startedAt, ev.Timestamp)
+	assert.Equal(t, "0/16B3748", ev.LSN, "snapshot rows carry the consistent point")
+	assert.NotEmpty(t, ev.ID)
+	assert.NotEmpty(t, ev.CorrelationID)
+
+	other := l.snapshotEvent(snapshotTable{schema: "public", name: "orders"}, snap, startedAt)
+	assert.NotEqual(t, ev.ID, other.ID, "every row gets its own event ID")
+}
+
+func TestRunSnapshot(t *testing.T) {
+	server := snapshotServer(t)
+	l := newSnapshotListener(server.port(), &HandlerSettings{
+		SlotName: "flogo_slot", PublicationName: "flogo_pub", SnapshotMode: SnapshotModeInitial, SnapshotChunkSize: 2,
+	})
+	snap := &slotSnapshot{name: "00000003-00000002-1", consistentPoint: 0x16B3748}
+	handler := &recordingHandler{}
+
+	require.NoError(t, l.runSnapshot(context.Background(), snap, handler))
+
+	require.Len(t, handler.events, 3)
+	for _, ev := range handler.events {
+		assert.Equal(t, SnapshotEventType, ev.Type)
+		assert.Equal(t, "public", ev.Schema)
+		assert.Equal(t, "orders", ev.Table)
+		assert.Equal(t, "0/16B3748", ev.LSN)
+	}
+	assert.Equal(t, map[string]interface{}{"id": int64(1), "paid": true, "note": "first"}, handler.events[0].Data)
+	assert.Equal(t, map[string]interface{}{"id": int64(2), "paid": false, "note": nil}, handler.events[1].Data)
+	assert.Equal(t, int64(3), handler.events[2].Data["id"])
+
+	assert.Equal(t, []string{
+		"BEGIN ISOLATION LEVEL REPEATABLE READ, READ ONLY; SET TRANSACTION SNAPSHOT '00000003-00000002-1'",
+		"SELECT schemaname, tablename FROM pg_publication_tables\nWHERE pubname = 'flogo_pub' ORDER BY schemaname, tablename",
+		`DECLARE flogo_cdc_snapshot NO SCROLL CURSOR FOR SELECT * FROM "public"."orders"`,
+		"FETCH FORWARD 2 FROM flogo_cdc_snapshot",
+		"FETCH FORWARD 2 FROM flogo_cdc_snapshot",
+		"CLOSE flogo_cdc_snapshot",
+		"COMMIT",
+	}, server.Queries(), "a short chunk ends the table")
+}
+
+func TestStream_DropsSlotWhenSnapshotFails(t *testing.T) {
+	server := snapshotServer(t)
+	l := newSnapshotListener(server.port(), &HandlerSettings{
+		SlotName: "flogo_slot", PublicationName: "flogo_pub", SnapshotMode: SnapshotModeInitial,
+	})
+	replConn, err := pgconn.Connect(context.Background(), l.buildConnString(false))
+	require.NoError(t, err)
+	defer replConn.Close(context.Background())
+	l.replConn = replConn
+	l.snapshot = &slotSnapshot{name: "00000003-00000002-1", consistentPoint: 0x16B3748}
+
+	err = l.Stream(context.Background(), &recordingHandler{failAfter: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "initial snapshot failed")
+	assert.Contains(t, server.Queries(), "DROP_REPLICATION_SLOT flogo_slot ",
+		"a slot whose snapshot was not delivered must be recreated on the next attempt")
+	assert.NotContains(t, server.Queries(), "COMMIT")
+}
+
+func TestDropSlotAfterFailedSnapshot_KeepsTemporarySlot(t *testing.T) {
+	server := newFakePostgres(t, nil)
+	l := newSnapshotListener(server.port(), &HandlerSettings{SlotName: "flogo_slot", TemporarySlot: true})
+	replConn, err := pgconn.Connect(context.Background(), l.buildConnString(false))
+	require.NoError(t, err)
+	defer replConn.Close(context.Background())
+	l.replConn = replConn
+
+	l.dropSlotAfterFailedSnapshot()
+	assert.Empty(t, server.Queries(), "temporary slots go away with the replication connection")
+
+	// Without a replication connection there is nothing to drop.
+	l = newSnapshotListener(server.port(), &HandlerSettings{SlotName: "flogo_slot"})
+	l.dropSlotAfterFailedSnapshot()
+	assert.Empty(t, server.Queries())
+}
+
+func TestHandlerSettings_SnapshotValidation(t *testing.T) {
+	base := HandlerSettings{SlotName: "flogo_slot", PublicationName: "flogo_pub"}
+
+	h := base
+	require.NoError(t, h.Validate())
+	assert.False(t, h.SnapshotEnabled(), "snapshots are off by default")
+
+	h.SnapshotMode = "Initial"
+	require.NoError(t, h.Validate())
+	assert.True(t, h.SnapshotEnabled())
+
+	h.SnapshotMode = "always"
+	assert.Error(t, h.Validate())
+
+	h = base
+	h.SnapshotChunkSize = -1
+	assert.Error(t, h.Validate())
+}
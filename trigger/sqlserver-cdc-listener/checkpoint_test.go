@@ -0,0 +1,52 @@
+package sqlservercdclistener
+
+import (
+	"testing"
+
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+	"github.com/project-flogo/core/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCDCPositionRoundTrip(t *testing.T) {
+	l := NewSQLServerCDCListener(&Settings{}, &HandlerSettings{Table: "orders"}, log.RootLogger())
+	assert.Empty(t, l.Position(), "no position before the first poll")
+	require.NoError(t, l.SeedPosition(""))
+
+	l.fromLSN = []byte{0x00, 0x00, 0x00, 0x2a, 0x00, 0x00, 0x01, 0x10, 0x00, 0x03}
+	l.resumeAfter = &changeRowKey{
+		lsn:       []byte{0x00, 0x00, 0x00, 0x2a, 0x00, 0x00, 0x01, 0x20, 0x00, 0x01},
+		seqVal:    []byte{0x00, 0x00, 0x00, 0x2a, 0x00, 0x00, 0x01, 0x20, 0x00, 0x02},
+		operation: 4,
+	}
+	position := l.Position()
+
+	restored := NewSQLServerCDCListener(&Settings{}, &HandlerSettings{Table: "orders"}, log.RootLogger())
+	require.NoError(t, restored.SeedPosition(position))
+	assert.Equal(t, l.fromLSN, restored.fromLSN)
+	require.NotNil(t, restored.resumeAfter)
+	assert.Equal(t, 0, restored.resumeAfter.compare(*l.resumeAfter))
+
+	assert.Error(t, restored.SeedPosition("{not json"))
+	assert.Error(t, restored.SeedPosition(`{"fromLSN":"zz"}`))
+}
+
+func TestChangeRowKey_Compare(t *testing.T) {
+	base := changeRowKey{lsn: []byte{0x01}, seqVal: []byte{0x05}, operation: 3}
+	assert.Equal(t, 0, base.compare(base))
+	assert.Less(t, base.compare(changeRowKey{lsn: []byte{0x02}, seqVal: []byte{0x00}, operation: 1}), 0)
+	assert.Less(t, base.compare(changeRowKey{lsn: []byte{0x01}, seqVal: []byte{0x06}, operation: 1}), 0)
+	assert.Less(t, base.compare(changeRowKey{lsn: []byte{0x01}, seqVal: []byte{0x05}, operation: 4}), 0,
+		"the update before-image sorts ahead of its after-image")
+	assert.Greater(t, base.compare(changeRowKey{lsn: []byte{0x01}, seqVal: []byte{0x04}, operation: 4}), 0)
+}
+
+func TestCheckpointDrivers(t *testing.T) {
+	for _, driver := range []string{"postgres", "sqlserver"} {
+		cfg := cdccheckpoint.Config{Store: "sql", Driver: driver, DSN: "dsn"}
+		assert.NoError(t, cfg.Validate(), driver)
+	}
+	cfg := cdccheckpoint.Config{Store: "sql", Driver: "mysql", DSN: "dsn"}
+	assert.Error(t, cfg.Validate(), "the MySQL driver is not linked into this trigger")
+}
@@ -4,6 +4,9 @@ go 1.23.0
 
 require (
 	github.com/microsoft/go-mssqldb v1.9.3
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint v0.0.0-00010101000000-000000000000
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres v0.0.0-00010101000000-000000000000
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/sqlserver v0.0.0-00010101000000-000000000000
 	github.com/project-flogo/core v1.6.20
 	github.com/stretchr/testify v1.10.0
 )
@@ -14,6 +17,7 @@ require (
 	github.com/golang-sql/civil v0.0.0-20220223132316-b832511892a9 // indirect
 	github.com/golang-sql/sqlexp v0.1.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
+	github.com/lib/pq v1.10.9 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	go.uber.org/atomic v1.6.0 // indirect
 	go.uber.org/multierr v1.5.0 // indirect
@@ -22,3 +26,9 @@ require (
 	golang.org/x/text v0.25.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace (
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint => ../cdc-checkpoint
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres => ../cdc-checkpoint/postgres
+	github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/sqlserver => ../cdc-checkpoint/sqlserver
+)
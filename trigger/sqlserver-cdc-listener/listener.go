@@ -6,12 +6,14 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
 	_ "github.com/microsoft/go-mssqldb"
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
 	"github.com/project-flogo/core/support/log"
 )
 
@@ -45,7 +47,36 @@ type SQLServerCDCListener struct {
 
 	db           *sql.DB
 	pollInterval time.Duration
-	fromLSN      []byte // next LSN to read from (nil until first poll)
+	fromLSN      []byte                      // next LSN to read from (nil until first poll)
+	resumeAfter  *changeRowKey               // last row handled in the unfinished batch starting at fromLSN
+	checkpoint   *cdccheckpoint.Checkpointer // nil when no durable checkpoint store is configured
+}
+
+// changeRowKey identifies a change row within a capture instance; rows are
+// totally ordered by (start LSN, seqval, operation).
+type changeRowKey struct {
+	lsn       []byte
+	seqVal    []byte
+	operation int
+}
+
+// compare orders k against other like the change query's ORDER BY.
+func (k changeRowKey) compare(other changeRowKey) int {
+	if c := bytes.Compare(k.lsn, other.lsn); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(k.seqVal, other.seqVal); c != 0 {
+		return c
+	}
+	return k.operation - other.operation
+}
+
+// cdcPosition is the persisted form of the listener position.
+type cdcPosition struct {
+	FromLSN       string `json:"fromLSN"`
+	LastLSN       string `json:"lastLSN,omitempty"`
+	LastSeqVal    string `json:"lastSeqVal,omitempty"`
+	LastOperation int    `json:"lastOperation,omitempty"`
 }
 
 // NewSQLServerCDCListener creates a listener from validated settings.
@@ -157,6 +188,9 @@ func (l *SQLServerCDCListener) Stream(ctx context.Context, handler EventHandler)
 			}
 			return err
 		}
+		// Commit the batch boundary, including the start position chosen on
+		// the first poll, so a restart never skips changes made while down.
+		l.checkpoint.Advance(ctx, l.Position())
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -196,7 +230,10 @@ func (l *SQLServerCDCListener) poll(ctx context.Context, instance string, handle
 
 	// Clamp the cursor to the minimum retained LSN (changes may have aged out).
 	if minLSN != nil && bytes.Compare(l.fromLSN, minLSN) < 0 {
+		l.logger.Warnf("SQL Server CDC: resume LSN %s for capture_instance=%q is older than the retained minimum %s; changes in between were purged",
+			hexLSN(l.fromLSN), instance, hexLSN(minLSN))
 		l.fromLSN = minLSN
+		l.resumeAfter = nil
 	}
 
 	// Nothing new to read.
@@ -214,6 +251,7 @@ func (l *SQLServerCDCListener) poll(ctx context.Context, instance string, handle
 		return err
 	}
 	l.fromLSN = next
+	l.resumeAfter = nil
 	return nil
 }
 
@@ -281,6 +319,13 @@ ORDER BY __$start_lsn, __$seqval, __$operation`, fn)
 			}
 		}
 
+		// Rows up to the last handled one were delivered before a reconnect or
+		// restart; skip them so the batch resumes mid-way.
+		key := changeRowKey{lsn: startLSN, seqVal: seqVal, operation: operation}
+		if l.resumeAfter != nil && key.compare(*l.resumeAfter) <= 0 {
+			continue
+		}
+
 		switch operation {
 		case 3: // update: before image
 			pendingBefore = data
@@ -299,6 +344,7 @@ ORDER BY __$start_lsn, __$seqval, __$operation`, fn)
 				// re-read on the next poll (at-least-once delivery).
 				return fmt.Errorf("handler error: %w", err)
 			}
+			l.markHandled(ctx, key)
 		case 2: // insert
 			if !enabled["ALL"] && !enabled["INSERT"] {
 				continue
@@ -310,6 +356,7 @@ ORDER BY __$start_lsn, __$seqval, __$operation`, fn)
 				// re-read on the next poll (at-least-once delivery).
 				return fmt.Errorf("handler error: %w", err)
 			}
+			l.markHandled(ctx, key)
 		case 1: // delete
 			if !enabled["ALL"] && !enabled["DELETE"] {
 				continue
@@ -321,6 +368,7 @@ ORDER BY __$start_lsn, __$seqval, __$operation`, fn)
 				// re-read on the next poll (at-least-once delivery).
 				return fmt.Errorf("handler error: %w", err)
 			}
+			l.markHandled(ctx, key)
 		}
 	}
 
@@ -376,18 +424,67 @@ func (l *SQLServerCDCListener) Close(_ context.Context) error {
 	return nil
 }
 
-// Cursor returns the next LSN to read from, so the trigger can persist the
-// position reached before a reconnect.
-func (l *SQLServerCDCListener) Cursor() []byte {
-	return l.fromLSN
+// markHandled records key as the last delivered row and advances the checkpoint.
+func (l *SQLServerCDCListener) markHandled(ctx context.Context, key changeRowKey) {
+	l.resumeAfter = &key
+	l.checkpoint.Advance(ctx, l.Position())
 }
 
-// SeedCursor sets the LSN to resume from. A nil cursor preserves the first-poll
-// behaviour (start from beginning or after the current max LSN).
-func (l *SQLServerCDCListener) SeedCursor(cursor []byte) {
-	if cursor != nil {
-		l.fromLSN = cursor
+// Position returns the encoded position reached so far — the next LSN to read
+// from plus, mid-batch, the last row already handled — so the trigger can
+// resume from it after a reconnect or restart. It is "" before the first poll.
+func (l *SQLServerCDCListener) Position() string {
+	if l.fromLSN == nil {
+		return ""
+	}
+	pos := cdcPosition{FromLSN: hex.EncodeToString(l.fromLSN)}
+	if l.resumeAfter != nil {
+		pos.LastLSN = hex.EncodeToString(l.resumeAfter.lsn)
+		pos.LastSeqVal = hex.EncodeToString(l.resumeAfter.seqVal)
+		pos.LastOperation = l.resumeAfter.operation
 	}
+	b, err := json.Marshal(pos)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// SeedPosition sets the position to resume from. An empty position preserves
+// the first-poll behaviour (start from beginning or after the current max LSN).
+func (l *SQLServerCDCListener) SeedPosition(position string) error {
+	if position == "" {
+		return nil
+	}
+	var pos cdcPosition
+	if err := json.Unmarshal([]byte(position), &pos); err != nil {
+		return fmt.Errorf("invalid CDC position: %w", err)
+	}
+	fromLSN, err := hex.DecodeString(pos.FromLSN)
+	if err != nil || len(fromLSN) == 0 {
+		return fmt.Errorf("invalid CDC position: bad fromLSN %q", pos.FromLSN)
+	}
+	var resumeAfter *changeRowKey
+	if pos.LastLSN != "" {
+		lsn, err := hex.DecodeString(pos.LastLSN)
+		if err != nil {
+			return fmt.Errorf("invalid CDC position: bad lastLSN %q", pos.LastLSN)
+		}
+		seqVal, err := hex.DecodeString(pos.LastSeqVal)
+		if err != nil {
+			return fmt.Errorf("invalid CDC position: bad lastSeqVal %q", pos.LastSeqVal)
+		}
+		resumeAfter = &changeRowKey{lsn: lsn, seqVal: seqVal, operation: pos.LastOperation}
+	}
+	l.fromLSN = fromLSN
+	l.resumeAfter = resumeAfter
+	return nil
+}
+
+// SetCheckpointer attaches the durable checkpoint that handled positions are
+// committed to.
+func (l *SQLServerCDCListener) SetCheckpointer(cp *cdccheckpoint.Checkpointer) {
+	l.checkpoint = cp
 }
 
 // generateID returns a random hex identifier for events/correlation.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
 	"github.com/project-flogo/core/data/coerce"
 )
 
@@ -26,6 +27,15 @@ type Settings struct {
 	PollInterval      string `md:"pollInterval"`      // How often to poll for new changes (default "2s")
 	MaxRetryAttempts  int    `md:"maxRetryAttempts"`  // Max reconnect attempts (default 5, negative = infinite)
 	RetryDelay        string `md:"retryDelay"`        // Delay between reconnects (default "5s")
+
+	// Checkpointing — durable resume position across restarts
+	CheckpointStore    string `md:"checkpointStore"`    // none (default), file, sql
+	CheckpointFile     string `md:"checkpointFile"`     // Checkpoint file path (file store)
+	CheckpointDriver   string `md:"checkpointDriver"`   // postgres, sqlserver (sql store)
+	CheckpointDSN      string `md:"checkpointDSN"`      // Connection string for the checkpoint database (sql store)
+	CheckpointTable    string `md:"checkpointTable"`    // Checkpoint table (default flogo_cdc_checkpoints)
+	CheckpointInterval int    `md:"checkpointInterval"` // Commit the position every N events (default 1)
+	CheckpointMode     string `md:"checkpointMode"`     // onSuccess (default) or always
 }
 
 // HandlerSettings contains per-handler CDC capture configuration.
@@ -35,6 +45,7 @@ type HandlerSettings struct {
 	CaptureInstance    string `md:"captureInstance"`    // CDC capture instance (default <schema>_<table>)
 	EventTypes         string `md:"eventTypes"`         // ALL, INSERT, UPDATE, DELETE (comma separated)
 	StartFromBeginning bool   `md:"startFromBeginning"` // Replay all retained changes (from min LSN) on start
+	CheckpointKey      string `md:"checkpointKey"`      // Checkpoint key (default sqlserver:<database>.<captureInstance>)
 }
 
 // Output represents a single change event delivered to a Flogo flow.
@@ -67,7 +78,20 @@ func (s *Settings) Validate() error {
 	if s.DatabaseName == "" {
 		return fmt.Errorf("databaseName is required")
 	}
-	return nil
+	return s.CheckpointConfig().Validate()
+}
+
+// CheckpointConfig returns the checkpoint store configuration.
+func (s *Settings) CheckpointConfig() cdccheckpoint.Config {
+	return cdccheckpoint.Config{
+		Store:    s.CheckpointStore,
+		File:     s.CheckpointFile,
+		Driver:   s.CheckpointDriver,
+		DSN:      s.CheckpointDSN,
+		Table:    s.CheckpointTable,
+		Interval: s.CheckpointInterval,
+		Mode:     s.CheckpointMode,
+	}
 }
 
 // Validate checks the per-handler settings.
@@ -103,6 +127,15 @@ func (h *HandlerSettings) ResolvedCaptureInstance() string {
 	return fmt.Sprintf("%s_%s", h.ResolvedSchema(), h.Table)
 }
 
+// ResolvedCheckpointKey returns the key the handler's position is stored under,
+// defaulting to sqlserver:<database>.<captureInstance>.
+func (h *HandlerSettings) ResolvedCheckpointKey(database string) string {
+	if k := strings.TrimSpace(h.CheckpointKey); k != "" {
+		return k
+	}
+	return fmt.Sprintf("sqlserver:%s.%s", database, h.ResolvedCaptureInstance())
+}
+
 // EventTypeSet returns the set of enabled event types (INSERT/UPDATE/DELETE).
 // An empty result or a set containing "ALL" means all event types are enabled.
 func (h *HandlerSettings) EventTypeSet() map[string]bool {
@@ -6,6 +6,9 @@ import (
 	"sync"
 	"time"
 
+	cdccheckpoint "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint"
+	_ "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/postgres"
+	_ "github.com/mpandav-tibco/flogo-custom-extensions/trigger/cdc-checkpoint/sqlserver"
 	"github.com/project-flogo/core/data/metadata"
 	"github.com/project-flogo/core/support/log"
 	"github.com/project-flogo/core/support/trace"
@@ -28,12 +31,15 @@ type Trigger struct {
 	wg        sync.WaitGroup
 	mutex     sync.Mutex
 	isStarted bool
+
+	checkpoints cdccheckpoint.Store // nil when checkpointStore is none
 }
 
 type handlerContext struct {
-	runner   trigger.Handler
-	settings *HandlerSettings
-	cursor   []byte
+	runner     trigger.Handler
+	settings   *HandlerSettings
+	position   string
+	checkpoint *cdccheckpoint.Checkpointer
 }
 
 // Factory creates SQL Server CDC triggers.
@@ -66,7 +72,6 @@ func (t *Trigger) Metadata() *trigger.Metadata {
 
 // Initialize parses handler settings and prepares one listener per handler.
 func (t *Trigger) Initialize(ctx trigger.InitContext) error {
-	t.ctx, t.cancel = context.WithCancel(context.Background())
 	if ctx.Logger() != nil {
 		t.logger = ctx.Logger()
 	}
@@ -101,6 +106,20 @@ func (t *Trigger) Start() error {
 		return fmt.Errorf("trigger already started")
 	}
 
+	// The checkpoint store is opened per run: Stop closes it, so a restarted
+	// trigger needs a fresh one.
+	openCtx, cancel := context.WithTimeout(context.Background(), cdccheckpoint.OpenTimeout)
+	store, err := cdccheckpoint.Open(openCtx, t.settings.CheckpointConfig())
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+	t.checkpoints = store
+	for _, hc := range t.handlers {
+		hc.checkpoint = cdccheckpoint.NewCheckpointer(store, hc.settings.ResolvedCheckpointKey(t.settings.DatabaseName), t.settings.CheckpointInterval, t.logger)
+	}
+
+	t.ctx, t.cancel = context.WithCancel(context.Background())
 	for _, hc := range t.handlers {
 		t.wg.Add(1)
 		go t.runHandler(hc)
@@ -124,9 +143,24 @@ func (t *Trigger) runHandler(hc *handlerContext) {
 		maxAttempts = 5
 	}
 
-	flogoHandler := &flogoEventHandler{runner: hc.runner, logger: t.logger}
+	flogoHandler := &flogoEventHandler{
+		runner:         hc.runner,
+		logger:         t.logger,
+		advanceOnError: t.settings.CheckpointConfig().AdvanceOnError(),
+	}
 	attempt := 0
 
+	// Resume from the durable checkpoint left by a previous run, if any.
+	key := hc.settings.ResolvedCheckpointKey(t.settings.DatabaseName)
+	if position, err := hc.checkpoint.Load(t.ctx); err != nil {
+		t.logger.Errorf("SQL Server CDC: failed to load checkpoint %q, starting without it: %v", key, err)
+	} else if position != "" {
+		hc.position = position
+		t.logger.Infof("SQL Server CDC: resuming capture_instance=%q from checkpoint %q",
+			hc.settings.ResolvedCaptureInstance(), key)
+	}
+	defer hc.checkpoint.Flush(context.Background())
+
 	for {
 		select {
 		case <-t.ctx.Done():
@@ -135,9 +169,14 @@ func (t *Trigger) runHandler(hc *handlerContext) {
 		}
 
 		listener := NewSQLServerCDCListener(t.settings, hc.settings, t.logger)
-		// Resume from the LSN reached before the previous disconnect so no
-		// changes are missed across reconnects (at-least-once delivery).
-		listener.SeedCursor(hc.cursor)
+		// Resume from the position reached before the previous disconnect so
+		// no changes are missed across reconnects (at-least-once delivery).
+		if err := listener.SeedPosition(hc.position); err != nil {
+			t.logger.Errorf("SQL Server CDC: ignoring stored position for capture_instance=%q: %v",
+				hc.settings.ResolvedCaptureInstance(), err)
+			hc.position = ""
+		}
+		listener.SetCheckpointer(hc.checkpoint)
 
 		err := func() error {
 			if err := listener.Prepare(t.ctx); err != nil {
@@ -145,7 +184,8 @@ func (t *Trigger) runHandler(hc *handlerContext) {
 			}
 			return listener.Stream(t.ctx, flogoHandler)
 		}()
-		hc.cursor = listener.Cursor()
+		hc.position = listener.Position()
+		hc.checkpoint.Flush(context.Background())
 		listener.Close(context.Background())
 
 		if t.ctx.Err() != nil {
@@ -187,6 +227,12 @@ func (t *Trigger) Stop() error {
 	// cancelling the context unblocks them and Wait joins them here. Closing the
 	// listener from this goroutine would race the still-running poll loop.
 	t.wg.Wait()
+	if t.checkpoints != nil {
+		if err := t.checkpoints.Close(); err != nil {
+			t.logger.Warnf("Error closing checkpoint store: %v", err)
+		}
+		t.checkpoints = nil
+	}
 	t.isStarted = false
 	t.logger.Info("SQL Server CDC trigger stopped")
 	return nil
@@ -196,6 +242,9 @@ func (t *Trigger) Stop() error {
 type flogoEventHandler struct {
 	runner trigger.Handler
 	logger log.Logger
+	// advanceOnError swallows flow errors so polling moves past failed
+	// changes (checkpointMode=always).
+	advanceOnError bool
 }
 
 // HandleEvent dispatches a change event to the associated Flogo flow.
@@ -248,6 +297,11 @@ func (h *flogoEventHandler) HandleEvent(ctx context.Context, event *ChangeEvent)
 		}
 	}
 	if err != nil {
+		if h.advanceOnError {
+			h.logger.Warnf("SQL Server CDC: flow failed for %s on %s.%s (lsn=%s), advancing past it (checkpointMode=always): %v",
+				event.Type, event.Schema, event.Table, event.LSN, err)
+			return nil
+		}
 		return fmt.Errorf("failed to execute Flogo flow: %w", err)
 	}
 	return nil
@@ -39,16 +39,32 @@ var SQLServerCDCListenerHandler = function (e) {
 
         // Certificate field visibility: only relevant when encryption is enabled
         // (encrypt = true or strict).
+        // Checkpoint field visibility: checkpointStore reveals the fields of the
+        // selected backend plus the shared interval/mode fields.
         n.validate = function (fieldName, settings) {
             var encField = settings.getField("encrypt");
             var enc = encField ? String(encField.value) : "disable";
             var tlsEnabled = (enc === "true" || enc === "strict");
+            var storeField = settings.getField("checkpointStore");
+            var store = storeField && storeField.value ? storeField.value : "none";
 
             switch (fieldName) {
                 case "trustServerCertificate":
                 case "certificate":
                     return validation_1.ValidationResult.newValidationResult().setVisible(tlsEnabled);
 
+                case "checkpointFile":
+                    return validation_1.ValidationResult.newValidationResult().setVisible(store === "file");
+
+                case "checkpointDriver":
+                case "checkpointDSN":
+                case "checkpointTable":
+                    return validation_1.ValidationResult.newValidationResult().setVisible(store === "sql");
+
+                case "checkpointInterval":
+                case "checkpointMode":
+                    return validation_1.ValidationResult.newValidationResult().setVisible(store !== "none");
+
                 default:
                     return null;
             }